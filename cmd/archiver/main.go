@@ -96,6 +96,13 @@ var (
 		Envar("STREAM_ONLY_MODE").
 		Bool()
 
+	retentionDryRun = app.Flag(
+		"retention-dry-run",
+		"Archiver only reports the jobs which retention policies would delete").
+		Default("false").
+		Envar("RETENTION_DRY_RUN").
+		Bool()
+
 	archiveInterval = app.Flag(
 		"archive-interval",
 		"Archive interval duration in h/m/s (archiver.archive_interval override) (set $ARCHIVE_INTERVAL to override)").
@@ -187,6 +194,10 @@ func main() {
 		cfg.Archiver.StreamOnlyMode = *streamOnlyMode
 	}
 
+	if *retentionDryRun {
+		cfg.Archiver.RetentionDryRun = *retentionDryRun
+	}
+
 	if *podEventsCleanup {
 		cfg.Archiver.PodEventsCleanup = *podEventsCleanup
 	}
@@ -19,6 +19,7 @@ import (
 	"os"
 	"time"
 
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
@@ -256,6 +257,9 @@ func main() {
 	podClient := podsvc.NewPodServiceYARPCClient(
 		dispatcher.ClientConfig(common.PelotonJobManager))
 
+	cronClient := cronsvc.NewCronServiceYARPCClient(
+		dispatcher.ClientConfig(common.PelotonJobManager))
+
 	respoolClient := respool.NewResourceManagerYARPCClient(
 		dispatcher.ClientConfig(common.PelotonResourceManager))
 
@@ -304,6 +308,7 @@ func main() {
 		rootScope,
 		jobClient,
 		podClient,
+		cronClient,
 		respoolLoader,
 		bridgecommon.RandomImpl{},
 	)
@@ -106,6 +106,12 @@ var (
 		Envar("BASIC_AUTH_CONFIG").
 		String()
 
+	authToken = app.Flag(
+		"authToken",
+		"bearer token, such as a JWT, used for auth instead of basic auth (set $AUTH_TOKEN to override)").
+		Envar("AUTH_TOKEN").
+		String()
+
 	timeout = app.Flag(
 		"timeout",
 		"default RPC timeout (set $TIMEOUT to override)").
@@ -213,6 +219,10 @@ var (
 	watchPodJobID    = watchPod.Arg("job", "job identifier").String()
 	watchPodPodNames = watchPod.Arg("pod", "pod name").Strings()
 	watchLabels      = watchPod.Flag("labels", "filter on labels (key:value pairs)").Strings()
+	watchPodRevision = watchPod.Flag("revision", "revision to resume the watch from").Default("0").Uint64()
+
+	watchJob       = watch.Command("job", "watch stateless job state and workflow changes")
+	watchJobJobIDs = watchJob.Arg("job", "job identifier, watch all jobs if not set").Strings()
 
 	watchCancel        = watch.Command("cancel", "cancel watch")
 	watchCancelWatchID = watchCancel.Arg("id", "watch id").Required().String()
@@ -293,6 +303,17 @@ var (
 		"start the update with best effort in-place update").Default("false").Bool()
 	statelessStartPods = statelessReplace.Flag("start-pods",
 		"start pods affected by the update if they are not running").Default("false").Bool()
+	statelessReplaceReadinessTimeout = statelessReplace.Flag("readiness-timeout",
+		"maximum time for an updated pod to pass its readiness check before it is "+
+			"counted as a failed instance. If the value is 0, there is no timeout.").Default("0s").Duration()
+	statelessReplaceCanaryInstances = statelessReplace.Flag("canary-instances",
+		"number of pods to update in the canary phase before the rest of the pods. "+
+			"If the value is 0, the update does not have a canary phase.").Default("0").Uint32()
+	statelessReplaceCanaryBake = statelessReplace.Flag("canary-bake",
+		"time to hold the update after the canary pods are updated and running").Default("0s").Duration()
+	statelessReplaceCanaryMaxFailures = statelessReplace.Flag("canary-max-failures",
+		"maximum number of failures tolerated on the canary pods before "+
+			"the update is rolled back").Default("0").Uint32()
 
 	statelessListJobs = stateless.Command("list", "list all jobs")
 
@@ -376,6 +397,9 @@ var (
 	podGetCache        = pod.Command("cache", "get pod status from cache")
 	podGetCachePodName = podGetCache.Arg("name", "pod name").Required().String()
 
+	podGetPendingReason        = pod.Command("pending-reason", "get the reason why a pod is not placed yet")
+	podGetPendingReasonPodName = podGetPendingReason.Arg("name", "pod name").Required().String()
+
 	podGetEventsV1Alpha        = pod.Command("events-v1alpha", "get pod events")
 	podGetEventsV1AlphaPodName = podGetEventsV1Alpha.Arg("name", "pod name").Required().String()
 	podGetEventsV1AlphaPodID   = podGetEventsV1Alpha.Flag("id", "pod identifier").Short('p').String()
@@ -508,6 +532,12 @@ var (
 		"resource pool starting from the root").Required().String()
 	respoolUpdateConfig = respoolUpdate.Arg("config", "YAML Resource Pool configuration").Required().ExistingFile()
 
+	resPoolSimulate = resPool.Command("simulate", "simulate the entitlement "+
+		"of all resource pools if an existing resource pool is updated")
+	resPoolSimulatePath = resPoolSimulate.Arg("respool", "complete path of the "+
+		"resource pool starting from the root").Required().String()
+	resPoolSimulateConfig = resPoolSimulate.Arg("config", "YAML Resource Pool configuration").Required().ExistingFile()
+
 	resPoolDump = resPool.Command(
 		"dump",
 		"Dump all resource pool(s)",
@@ -531,9 +561,76 @@ var (
 	hostMaintenanceComplete          = hostMaintenance.Command("complete", "complete host maintenance on a list of hosts")
 	hostMaintenanceCompleteHostnames = hostMaintenanceComplete.Arg("hostnames", "comma separated hostnames").Required().String()
 
+	hostMaintenanceSchedule          = hostMaintenance.Command("schedule", "schedule a maintenance window for a list of hosts")
+	hostMaintenanceScheduleHostnames = hostMaintenanceSchedule.Arg("hostnames", "comma separated hostnames").Required().String()
+	hostMaintenanceScheduleStart     = hostMaintenanceSchedule.Flag("start", "time when the hosts start draining in RFC3339 format").Required().String()
+	hostMaintenanceScheduleDeadline  = hostMaintenanceSchedule.Flag("deadline", "time by which the hosts are expected to be DOWN in RFC3339 format").Required().String()
+
+	hostMaintenanceWindows = hostMaintenance.Command("windows", "list the maintenance windows")
+
+	hostMaintenanceCancel         = hostMaintenance.Command("cancel", "cancel a maintenance window")
+	hostMaintenanceCancelWindowID = hostMaintenanceCancel.Arg("window", "maintenance window identifier").Required().String()
+
 	hostQuery       = host.Command("query", "query hosts by state(s)")
 	hostQueryStates = hostQuery.Flag("states", "host state(s) to filter").Default("").Short('s').String()
 
+	hostWatch          = host.Command("watch", "watch the state changes of hosts")
+	hostWatchHostnames = hostWatch.Arg("hostnames", "comma separated hostnames, all hosts are watched if not specified").Default("").String()
+
+	// Top level audit command
+	audit = app.Command("audit", "query the audit log of calls to mutating APIs")
+
+	auditQuery      = audit.Command("query", "query the audit records by job, user and time range")
+	auditQueryJobID = auditQuery.Flag("job", "only return the records of this job").Default("").String()
+	auditQueryUser  = auditQuery.Flag("user", "only return the records of this user").Default("").String()
+	auditQueryFrom  = auditQuery.Flag("from", "start of the time range in RFC3339 format, defaults to one day before the end").Default("").String()
+	auditQueryTo    = auditQuery.Flag("to", "end of the time range in RFC3339 format, defaults to now").Default("").String()
+	auditQueryLimit = auditQuery.Flag("limit", "maximum number of records to return").Default("100").Uint32()
+
+	// Top level cron command
+	cron = app.Command("cron", "manage cron jobs which create batch jobs on a schedule")
+
+	cronCreate                = cron.Command("create", "create a cron job")
+	cronCreateName            = cronCreate.Arg("name", "cron job name").Required().String()
+	cronCreateResPoolPath     = cronCreate.Arg("respool", "complete path of the resource pool starting from the root").Required().String()
+	cronCreateConfig          = cronCreate.Arg("config", "YAML config of the batch jobs created by the cron job").Required().ExistingFile()
+	cronCreateSchedule        = cronCreate.Flag("schedule", "5 field cron expression in UTC, or one of @hourly, @daily, @weekly, @monthly and @yearly").Required().String()
+	cronCreateCollisionPolicy = cronCreate.Flag("collision-policy", "what to do when a previous run is still active: skip, kill_previous or run_concurrently").Default("skip").String()
+
+	cronReplace                = cron.Command("replace", "replace the config of a cron job")
+	cronReplaceName            = cronReplace.Arg("name", "cron job name").Required().String()
+	cronReplaceResPoolPath     = cronReplace.Arg("respool", "complete path of the resource pool starting from the root").Required().String()
+	cronReplaceConfig          = cronReplace.Arg("config", "YAML config of the batch jobs created by the cron job").Required().ExistingFile()
+	cronReplaceSchedule        = cronReplace.Flag("schedule", "5 field cron expression in UTC, or one of @hourly, @daily, @weekly, @monthly and @yearly").Required().String()
+	cronReplaceCollisionPolicy = cronReplace.Flag("collision-policy", "what to do when a previous run is still active: skip, kill_previous or run_concurrently").Default("skip").String()
+
+	cronDelete     = cron.Command("delete", "delete a cron job, jobs of previous runs are not stopped")
+	cronDeleteName = cronDelete.Arg("name", "cron job name").Required().String()
+
+	cronGet         = cron.Command("get", "get a cron job and its most recent runs")
+	cronGetName     = cronGet.Arg("name", "cron job name").Required().String()
+	cronGetRunLimit = cronGet.Flag("limit", "maximum number of runs to return").Default("10").Uint32()
+
+	cronList = cron.Command("list", "list all cron jobs")
+
+	cronStart     = cron.Command("start", "trigger a run of a cron job outside of its schedule")
+	cronStartName = cronStart.Arg("name", "cron job name").Required().String()
+
+	// Top level dag command
+	dag = app.Command("dag", "manage DAGs of batch jobs which run once their upstream jobs are done")
+
+	dagSubmit            = dag.Command("submit", "submit a DAG of batch jobs")
+	dagSubmitResPoolPath = dagSubmit.Arg("respool", "complete path of the resource pool starting from the root").Required().String()
+	dagSubmitSpec        = dagSubmit.Arg("spec", "YAML spec of the nodes and edges of the DAG").Required().ExistingFile()
+
+	dagGet   = dag.Command("get", "get a DAG and the status of its nodes")
+	dagGetID = dagGet.Arg("id", "DAG identifier").Required().String()
+
+	dagList = dag.Command("list", "list the DAGs submitted in the last 30 days")
+
+	dagCancel   = dag.Command("cancel", "cancel a DAG, killing its running jobs and skipping its pending nodes")
+	dagCancelID = dagCancel.Arg("id", "DAG identifier").Required().String()
+
 	// Top level volume command
 	volume = app.Command("volume", "manage persistent volume")
 
@@ -600,6 +697,38 @@ var (
 	updateResumeOpaqueData = updateResume.Flag("opaque-data",
 		"opaque data provided by the user").Default("").String()
 
+	// Top level placement command
+	placement = app.Command("placement", "offline placement tools")
+
+	placementSnapshot = placement.Command("snapshot", "capture the hosts, "+
+		"offers, running tasks and pending gangs of the cluster to a file")
+	placementSnapshotFile = placementSnapshot.Arg("file",
+		"file to write the snapshot to").Required().String()
+	placementSnapshotTaskType = placementSnapshot.Flag("task-type",
+		"type of the running tasks to capture").Default("batch").String()
+	placementSnapshotRespoolID = placementSnapshot.Flag("respool",
+		"resource pool identifier to capture the pending gangs of").Default("").String()
+	placementSnapshotLimit = placementSnapshot.Flag("limit",
+		"maximum number of pending gangs to capture").Default("100").Uint32()
+
+	placementSimulate = placement.Command("simulate", "run a placement "+
+		"strategy offline on a snapshot and report placement rate, "+
+		"fragmentation and failures per constraint")
+	placementSimulateFile = placementSimulate.Flag("snapshot",
+		"snapshot file, a snapshot is generated if not provided").Default("").String()
+	placementSimulateStrategy = placementSimulate.Flag("strategy",
+		"placement strategy, batch or mimir").Default("batch").String()
+	placementSimulateTaskType = placementSimulate.Flag("task-type",
+		"task type the strategy is placing").Default("batch").String()
+	placementSimulateRounds = placementSimulate.Flag("rounds",
+		"maximum number of placement rounds").Default("3").Int()
+	placementSimulateHosts = placementSimulate.Flag("hosts",
+		"number of hosts of a generated snapshot").Default("100").Int()
+	placementSimulateGangs = placementSimulate.Flag("gangs",
+		"number of pending gangs of a generated snapshot").Default("500").Int()
+	placementSimulateSeed = placementSimulate.Flag("seed",
+		"seed of a generated snapshot").Default("1").Int64()
+
 	// Top level hostmgr command
 	hostmgr = app.Command("hostmgr", "top level command for hostmgr")
 
@@ -761,7 +890,7 @@ func main() {
 		basicAuthConfigPtr = &basicAuthConfig
 	}
 
-	client, err := pc.New(discovery, *timeout, basicAuthConfigPtr, *jsonFormat)
+	client, err := pc.New(discovery, *timeout, basicAuthConfigPtr, *authToken, *jsonFormat)
 	if err != nil {
 		app.FatalIfError(err, "Fail to initialize client")
 	}
@@ -827,8 +956,60 @@ func main() {
 		err = client.HostMaintenanceStartAction(*hostMaintenanceStartHostnames)
 	case hostMaintenanceComplete.FullCommand():
 		err = client.HostMaintenanceCompleteAction(*hostMaintenanceCompleteHostnames)
+	case hostMaintenanceSchedule.FullCommand():
+		err = client.HostMaintenanceScheduleAction(
+			*hostMaintenanceScheduleHostnames,
+			*hostMaintenanceScheduleStart,
+			*hostMaintenanceScheduleDeadline,
+		)
+	case hostMaintenanceWindows.FullCommand():
+		err = client.HostMaintenanceWindowsAction()
+	case hostMaintenanceCancel.FullCommand():
+		err = client.HostMaintenanceCancelAction(*hostMaintenanceCancelWindowID)
+	case auditQuery.FullCommand():
+		err = client.AuditQueryAction(
+			*auditQueryJobID,
+			*auditQueryUser,
+			*auditQueryFrom,
+			*auditQueryTo,
+			*auditQueryLimit,
+		)
+	case cronCreate.FullCommand():
+		err = client.CronCreateAction(
+			*cronCreateName,
+			*cronCreateResPoolPath,
+			*cronCreateSchedule,
+			*cronCreateCollisionPolicy,
+			*cronCreateConfig,
+		)
+	case cronReplace.FullCommand():
+		err = client.CronReplaceAction(
+			*cronReplaceName,
+			*cronReplaceResPoolPath,
+			*cronReplaceSchedule,
+			*cronReplaceCollisionPolicy,
+			*cronReplaceConfig,
+		)
+	case cronDelete.FullCommand():
+		err = client.CronDeleteAction(*cronDeleteName)
+	case cronGet.FullCommand():
+		err = client.CronGetAction(*cronGetName, *cronGetRunLimit)
+	case cronList.FullCommand():
+		err = client.CronListAction()
+	case cronStart.FullCommand():
+		err = client.CronStartAction(*cronStartName)
+	case dagSubmit.FullCommand():
+		err = client.DagSubmitAction(*dagSubmitResPoolPath, *dagSubmitSpec)
+	case dagGet.FullCommand():
+		err = client.DagGetAction(*dagGetID)
+	case dagList.FullCommand():
+		err = client.DagListAction()
+	case dagCancel.FullCommand():
+		err = client.DagCancelAction(*dagCancelID)
 	case hostQuery.FullCommand():
 		err = client.HostQueryAction(*hostQueryStates)
+	case hostWatch.FullCommand():
+		err = client.HostWatchAction(*hostWatchHostnames)
 	case resMgrActiveTasks.FullCommand():
 		err = client.ResMgrGetActiveTasks(*resMgrActiveTasksGetJobName, *resMgrActiveTasksGetRespoolID, *resMgrActiveTasksGetStates)
 	case resMgrPendingTasks.FullCommand():
@@ -838,6 +1019,8 @@ func main() {
 		err = client.ResPoolCreateAction(*resPoolCreatePath, *resPoolCreateConfig)
 	case respoolUpdate.FullCommand():
 		err = client.ResPoolUpdateAction(*respoolUpdatePath, *respoolUpdateConfig)
+	case resPoolSimulate.FullCommand():
+		err = client.ResPoolSimulateAction(*resPoolSimulatePath, *resPoolSimulateConfig)
 	case resPoolDump.FullCommand():
 		err = client.ResPoolDumpAction(*resPoolDumpFormat)
 	case resPoolDelete.FullCommand():
@@ -873,6 +1056,15 @@ func main() {
 		err = client.UpdatePauseAction(*updatePauseID, *updatePauseOpaqueData)
 	case updateResume.FullCommand():
 		err = client.UpdateResumeAction(*updateResumeID, *updateResumeOpaqueData)
+	case placementSnapshot.FullCommand():
+		err = client.PlacementSnapshotAction(*placementSnapshotFile,
+			*placementSnapshotTaskType, *placementSnapshotRespoolID,
+			*placementSnapshotLimit)
+	case placementSimulate.FullCommand():
+		err = client.PlacementSimulateAction(*placementSimulateFile,
+			*placementSimulateStrategy, *placementSimulateTaskType,
+			*placementSimulateRounds, *placementSimulateHosts,
+			*placementSimulateGangs, *placementSimulateSeed)
 	case offers.FullCommand():
 		err = client.OffersGetAction()
 	case getHosts.FullCommand():
@@ -883,6 +1075,8 @@ func main() {
 		err = client.PodGetEventsAction(*podGetEventsJobName, *podGetEventsInstanceID, *podGetEventsRunID, *podGetEventsLimit)
 	case podGetCache.FullCommand():
 		err = client.PodGetCacheAction(*podGetCachePodName)
+	case podGetPendingReason.FullCommand():
+		err = client.PodGetPendingReasonAction(*podGetPendingReasonPodName)
 	case podGetEventsV1Alpha.FullCommand():
 		err = client.PodGetEventsV1AlphaAction(*podGetEventsV1AlphaPodName, *podGetEventsV1AlphaPodID)
 	case podRefresh.FullCommand():
@@ -934,6 +1128,10 @@ func main() {
 			*statelessReplaceOpaqueData,
 			*statelessReplaceInPlace,
 			*statelessStartPods,
+			*statelessReplaceReadinessTimeout,
+			*statelessReplaceCanaryInstances,
+			*statelessReplaceCanaryBake,
+			*statelessReplaceCanaryMaxFailures,
 		)
 	case statelessReplaceJobDiff.FullCommand():
 		err = client.StatelessReplaceJobDiffAction(
@@ -1007,7 +1205,9 @@ func main() {
 			*statelessDeleteForce,
 		)
 	case watchPod.FullCommand():
-		err = client.WatchPod(*watchPodJobID, *watchPodPodNames, *watchLabels)
+		err = client.WatchPod(*watchPodJobID, *watchPodPodNames, *watchLabels, *watchPodRevision)
+	case watchJob.FullCommand():
+		err = client.WatchJob(*watchJobJobIDs)
 	case watchCancel.FullCommand():
 		err = client.CancelWatch(*watchCancelWatchID)
 	default:
@@ -48,6 +48,7 @@ import (
 	"github.com/uber/peloton/pkg/hostmgr/task"
 	"github.com/uber/peloton/pkg/middleware/inbound"
 	"github.com/uber/peloton/pkg/middleware/outbound"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 	"github.com/uber/peloton/pkg/storage/stores"
 
 	log "github.com/sirupsen/logrus"
@@ -342,6 +343,7 @@ func main() {
 	rootScope.Counter("boot").Inc(1)
 
 	store := stores.MustCreateStore(&cfg.Storage, rootScope)
+	ormStore := stores.MustCreateORMStore(&cfg.Storage, rootScope)
 
 	authHeader, err := mesos.GetAuthHeader(&cfg.Mesos, *mesosSecretFile)
 	if err != nil {
@@ -420,6 +422,12 @@ func main() {
 
 	authInboundMiddleware := inbound.NewAuthInboundMiddleware(securityManager)
 
+	// Record the calls to mutating APIs in the audit log
+	auditInboundMiddleware := inbound.NewAuditInboundMiddleware(
+		common.PelotonHostManager,
+		ormobjects.NewAuditLogOps(ormStore),
+	)
+
 	securityClient, err := auth_impl.CreateNewSecurityClient(&cfg.Auth)
 	if err != nil {
 		log.WithError(err).
@@ -436,8 +444,8 @@ func main() {
 			Tally: rootScope,
 		},
 		InboundMiddleware: yarpc.InboundMiddleware{
-			Unary:  authInboundMiddleware,
-			Oneway: authInboundMiddleware,
+			Unary:  inbound.UnaryChain(authInboundMiddleware, auditInboundMiddleware),
+			Oneway: inbound.OnewayChain(authInboundMiddleware, auditInboundMiddleware),
 			Stream: authInboundMiddleware,
 		},
 		OutboundMiddleware: yarpc.OutboundMiddleware{
@@ -591,6 +599,9 @@ func main() {
 		masterOperatorClient,
 		maintenanceQueue,
 		maintenanceHostInfoMap,
+		ormStore,
+		backgroundManager,
+		cfg.HostManager.MaintenanceWindowPeriod,
 	)
 
 	// Register background worker to start mesos task status update counter.
@@ -20,7 +20,9 @@ import (
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	"github.com/uber/peloton/pkg/auth"
 	auth_impl "github.com/uber/peloton/pkg/auth/impl"
@@ -29,13 +31,18 @@ import (
 	"github.com/uber/peloton/pkg/common/buildversion"
 	"github.com/uber/peloton/pkg/common/config"
 	"github.com/uber/peloton/pkg/common/health"
+	"github.com/uber/peloton/pkg/common/keyring"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
 	"github.com/uber/peloton/pkg/common/metrics"
 	"github.com/uber/peloton/pkg/common/rpc"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/peer"
 	"github.com/uber/peloton/pkg/jobmgr"
+	"github.com/uber/peloton/pkg/jobmgr/auditsvc"
+	"github.com/uber/peloton/pkg/jobmgr/authz"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
+	"github.com/uber/peloton/pkg/jobmgr/cronsvc"
+	"github.com/uber/peloton/pkg/jobmgr/dagsvc"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc/stateless"
@@ -161,6 +168,13 @@ var (
 		Envar("PELOTON_SECRET_FILE").
 		String()
 
+	secretKeyringFile = app.Flag(
+		"secret-keyring-file",
+		"Keyring file containing the master keys to encrypt job secrets with").
+		Default("").
+		Envar("SECRET_KEYRING_FILE").
+		String()
+
 	mesosAgentWorkDir = app.Flag(
 		"mesos-agent-work-dir", "Mesos agent work dir").
 		Default("/var/lib/mesos/agent").
@@ -304,6 +318,20 @@ func main() {
 			secretsCfg.CassandraPassword
 	}
 
+	// Load the master keys to encrypt job secrets with
+	var secretKeyring *keyring.Keyring
+	if *secretKeyringFile != "" {
+		var err error
+		secretKeyring, err = keyring.Load(*secretKeyringFile)
+		if err != nil {
+			log.WithError(err).
+				WithField("secret_keyring_file", *secretKeyringFile).
+				Fatal("Cannot load secret keyring")
+		}
+	} else if cfg.JobManager.JobSvcCfg.EnableSecrets {
+		log.Warn("No secret keyring file, job secrets are stored unencrypted")
+	}
+
 	// Parse and setup peloton auth
 	if len(*authType) != 0 {
 		cfg.Auth.AuthType = auth.Type(*authType)
@@ -329,12 +357,7 @@ func main() {
 	// store implements JobStore, TaskStore, VolumeStore, UpdateStore
 	// and FrameworkInfoStore
 	store := stores.MustCreateStore(&cfg.Storage, rootScope)
-	ormStore, ormErr := ormobjects.NewCassandraStore(
-		&cfg.Storage.Cassandra,
-		rootScope)
-	if ormErr != nil {
-		log.WithError(ormErr).Fatal("Failed to create ORM store for Cassandra")
-	}
+	ormStore := stores.MustCreateORMStore(&cfg.Storage, rootScope)
 
 	// Create both HTTP and GRPC inbounds
 	inbounds := rpc.NewInbounds(
@@ -396,6 +419,12 @@ func main() {
 
 	authInboundMiddleware := inbound.NewAuthInboundMiddleware(securityManager)
 
+	// Record the calls to mutating APIs in the audit log
+	auditInboundMiddleware := inbound.NewAuditInboundMiddleware(
+		common.PelotonJobManager,
+		ormobjects.NewAuditLogOps(ormStore),
+	)
+
 	securityClient, err := auth_impl.CreateNewSecurityClient(&cfg.Auth)
 	if err != nil {
 		log.WithError(err).
@@ -412,9 +441,9 @@ func main() {
 			Tally: rootScope,
 		},
 		InboundMiddleware: yarpc.InboundMiddleware{
-			Unary:  authInboundMiddleware,
+			Unary:  inbound.UnaryChain(authInboundMiddleware, auditInboundMiddleware),
 			Stream: authInboundMiddleware,
-			Oneway: authInboundMiddleware,
+			Oneway: inbound.OnewayChain(authInboundMiddleware, auditInboundMiddleware),
 		},
 		OutboundMiddleware: yarpc.OutboundMiddleware{
 			Unary:  authOutboundMiddleware,
@@ -423,6 +452,21 @@ func main() {
 		},
 	})
 
+	// Authorize users restricted to a subset of resource pools
+	// or owners on the jobs targeted by their requests
+	authInboundMiddleware.SetResourceResolver(
+		authz.NewResourceResolver(
+			store, // store implements UpdateStore
+			store, // store implements PersistentVolumeStore
+			ormobjects.NewJobIndexOps(ormStore),
+			ormobjects.NewCronJobOps(ormStore),
+			ormobjects.NewDagOps(ormStore),
+			respool.NewResourceManagerYARPCClient(
+				dispatcher.ClientConfig(common.PelotonResourceManager),
+			),
+		),
+	)
+
 	// Declare background works
 	backgroundManager := background.NewManager()
 
@@ -463,6 +507,7 @@ func main() {
 		store, // store implements TaskStore
 		store, // store implements VolumeStore
 		ormStore,
+		secretKeyring,
 		rootScope,
 	)
 
@@ -550,7 +595,7 @@ func main() {
 		log.Fatalf("Unable to create leader candidate: %v", err)
 	}
 
-	jobsvc.InitServiceHandler(
+	jobHandler := jobsvc.InitServiceHandler(
 		dispatcher,
 		rootScope,
 		store, // store implements JobStore
@@ -561,6 +606,7 @@ func main() {
 		candidate,
 		common.PelotonResourceManager, // TODO: to be removed
 		cfg.JobManager.JobSvcCfg,
+		secretKeyring,
 	)
 
 	stateless.InitV1AlphaJobServiceHandler(
@@ -573,10 +619,11 @@ func main() {
 		goalStateDriver,
 		candidate,
 		cfg.JobManager.JobSvcCfg,
+		secretKeyring,
 		activeJobCache,
 	)
 
-	tasksvc.InitServiceHandler(
+	taskHandler := tasksvc.InitServiceHandler(
 		dispatcher,
 		rootScope,
 		store, // store implements JobStore
@@ -603,6 +650,7 @@ func main() {
 		logmanager.NewLogManager(&http.Client{Timeout: _httpClientTimeout}),
 		*mesosAgentWorkDir,
 		hostsvc.NewInternalHostServiceYARPCClient(dispatcher.ClientConfig(common.PelotonHostManager)),
+		resmgrsvc.NewResourceManagerServiceYARPCClient(dispatcher.ClientConfig(common.PelotonResourceManager)),
 	)
 
 	volumesvc.InitServiceHandler(
@@ -622,6 +670,30 @@ func main() {
 		jobFactory,
 	)
 
+	auditsvc.InitServiceHandler(dispatcher, ormStore)
+
+	// Run the cron jobs on their schedule while leader
+	cronsvc.InitServiceHandler(
+		dispatcher,
+		rootScope,
+		ormStore,
+		jobHandler,
+		taskHandler,
+		candidate,
+		backgroundManager,
+		cfg.JobManager.CronSvcCfg,
+	)
+
+	// Run the DAGs of batch jobs from the goal state engine
+	dagsvc.InitServiceHandler(
+		dispatcher,
+		rootScope,
+		ormStore,
+		goalStateDriver,
+		candidate,
+		cfg.JobManager.JobSvcCfg,
+	)
+
 	// Start dispatch loop
 	if err := dispatcher.Start(); err != nil {
 		log.Fatalf("Could not start rpc server: %v", err)
@@ -24,6 +24,7 @@ import (
 	"github.com/uber/peloton/pkg/common/buildversion"
 	common_config "github.com/uber/peloton/pkg/common/config"
 	"github.com/uber/peloton/pkg/common/health"
+	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/logging"
 	"github.com/uber/peloton/pkg/common/metrics"
 	"github.com/uber/peloton/pkg/common/rpc"
@@ -32,6 +33,7 @@ import (
 	"github.com/uber/peloton/pkg/middleware/outbound"
 	"github.com/uber/peloton/pkg/placement"
 	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/defrag"
 	"github.com/uber/peloton/pkg/placement/hosts"
 	tally_metrics "github.com/uber/peloton/pkg/placement/metrics"
 	"github.com/uber/peloton/pkg/placement/offers"
@@ -41,6 +43,8 @@ import (
 	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
 	"github.com/uber/peloton/pkg/placement/tasks"
 
+	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -296,6 +300,28 @@ func main() {
 		},
 	}
 
+	if cfg.Placement.Defrag.Enabled {
+		log.Info("Connecting to JobManager")
+		jobmgrPeerChooser, err := peer.NewSmartChooser(
+			cfg.Election,
+			rootScope,
+			common.JobManagerRole,
+			t,
+		)
+		if err != nil {
+			log.WithFields(
+				log.Fields{
+					"error": err,
+					"role":  common.JobManagerRole},
+			).Fatal("Could not create smart peer chooser for job manager")
+		}
+		defer jobmgrPeerChooser.Stop()
+
+		outbounds[common.PelotonJobManager] = transport.Outbounds{
+			Unary: t.NewOutbound(jobmgrPeerChooser),
+		}
+	}
+
 	securityManager, err := auth_impl.CreateNewSecurityManager(&cfg.Auth)
 	if err != nil {
 		log.WithError(err).
@@ -374,6 +400,38 @@ func main() {
 	}, nil)
 	pool.Start()
 
+	var defragmenter defrag.Defragmenter
+	if cfg.Placement.Defrag.Enabled {
+		defragmenter = defrag.NewDefragmenter(
+			leader.NewID(cfg.Placement.HTTPPort, cfg.Placement.GRPCPort),
+			tallyMetrics,
+			&cfg.Placement,
+			hostsService,
+			podsvc.NewPodServiceYARPCClient(
+				dispatcher.ClientConfig(common.PelotonJobManager)),
+			statelesssvc.NewJobServiceYARPCClient(
+				dispatcher.ClientConfig(common.PelotonJobManager)),
+			resourceManager,
+			algorithms.NewRelocator(4, 300),
+		)
+
+		// Only the leader of the defragmenter role runs the
+		// defragmenter, such that tasks are not moved twice.
+		candidate, err := leader.NewCandidate(
+			cfg.Election,
+			rootScope,
+			common.PlacementDefragRole,
+			defragmenter,
+		)
+		if err != nil {
+			log.Fatalf("Unable to create leader candidate: %v", err)
+		}
+		if err = candidate.Start(); err != nil {
+			log.Fatalf("Unable to start leader candidate: %v", err)
+		}
+		defer candidate.Stop()
+	}
+
 	engine := placement.New(
 		rootScope,
 		&cfg.Placement,
@@ -33,12 +33,14 @@ import (
 	"github.com/uber/peloton/pkg/middleware/inbound"
 	"github.com/uber/peloton/pkg/middleware/outbound"
 	"github.com/uber/peloton/pkg/resmgr"
+	"github.com/uber/peloton/pkg/resmgr/authz"
 	"github.com/uber/peloton/pkg/resmgr/entitlement"
 	maintenance "github.com/uber/peloton/pkg/resmgr/host"
 	"github.com/uber/peloton/pkg/resmgr/preemption"
 	"github.com/uber/peloton/pkg/resmgr/respool"
 	"github.com/uber/peloton/pkg/resmgr/respool/respoolsvc"
 	"github.com/uber/peloton/pkg/resmgr/task"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 	"github.com/uber/peloton/pkg/storage/stores"
 
 	log "github.com/sirupsen/logrus"
@@ -270,6 +272,7 @@ func main() {
 	mux.HandleFunc(buildversion.Get, buildversion.Handler(version))
 
 	store := stores.MustCreateStore(&cfg.Storage, rootScope)
+	ormStore := stores.MustCreateORMStore(&cfg.Storage, rootScope)
 
 	// Create both HTTP and GRPC inbounds
 	inbounds := rpc.NewInbounds(
@@ -314,6 +317,12 @@ func main() {
 
 	authInboundMiddleware := inbound.NewAuthInboundMiddleware(securityManager)
 
+	// Record the calls to mutating APIs in the audit log
+	auditInboundMiddleware := inbound.NewAuditInboundMiddleware(
+		common.PelotonResourceManager,
+		ormobjects.NewAuditLogOps(ormStore),
+	)
+
 	securityClient, err := auth_impl.CreateNewSecurityClient(&cfg.Auth)
 	if err != nil {
 		log.WithError(err).
@@ -330,8 +339,8 @@ func main() {
 			Tally: rootScope,
 		},
 		InboundMiddleware: yarpc.InboundMiddleware{
-			Unary:  authInboundMiddleware,
-			Oneway: authInboundMiddleware,
+			Unary:  inbound.UnaryChain(authInboundMiddleware, auditInboundMiddleware),
+			Oneway: inbound.OnewayChain(authInboundMiddleware, auditInboundMiddleware),
 			Stream: authInboundMiddleware,
 		},
 		OutboundMiddleware: yarpc.OutboundMiddleware{
@@ -354,6 +363,12 @@ func main() {
 		store, // store implements TaskStore
 		*cfg.ResManager.PreemptionConfig)
 
+	// Authorize users restricted to a subset of resource pools
+	// on the resource pools targeted by their requests
+	authInboundMiddleware.SetResourceResolver(
+		authz.NewResourceResolver(tree),
+	)
+
 	// Initialize resource pool service handlers
 	respoolHandler := respoolsvc.NewServiceHandler(
 		dispatcher,
@@ -393,12 +408,15 @@ func main() {
 	)
 
 	// Initializing the task preemptor
-	preemptor := preemption.NewPreemptor(
+	preemptor, err := preemption.NewPreemptor(
 		rootScope,
 		cfg.ResManager.PreemptionConfig,
 		task.GetTracker(),
 		tree,
 	)
+	if err != nil {
+		log.WithError(err).Fatal("Cannot create task preemptor")
+	}
 
 	// Initializing the host drainer
 	drainer := maintenance.NewDrainer(
@@ -416,6 +434,7 @@ func main() {
 		tree,
 		preemptor,
 		hostmgrClient,
+		calculator,
 		cfg.ResManager,
 	)
 
@@ -0,0 +1,25 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	storage "github.com/uber/peloton/pkg/storage/config"
+)
+
+// Config holds all config to run peloton-secretrotator. The config files
+// of peloton-jobmgr can be used as is.
+type Config struct {
+	Storage storage.Config `yaml:"storage"`
+}
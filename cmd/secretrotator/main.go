@@ -0,0 +1,109 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/uber/peloton/pkg/common/config"
+	"github.com/uber/peloton/pkg/common/keyring"
+	"github.com/uber/peloton/pkg/jobmgr/secrets"
+	"github.com/uber/peloton/pkg/storage/stores"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	version string
+	app     = kingpin.New(
+		"peloton-secretrotator",
+		"Wraps the data keys of the job secrets with the current master key "+
+			"of the keyring, while the job managers are running")
+
+	cfgFiles = app.Flag(
+		"config",
+		"YAML config files (can be provided multiple times to merge configs)").
+		Short('c').
+		Required().
+		ExistingFiles()
+
+	secretKeyringFile = app.Flag(
+		"secret-keyring-file",
+		"Keyring file containing the master keys to encrypt job secrets with").
+		Required().
+		Envar("SECRET_KEYRING_FILE").
+		ExistingFile()
+
+	pelotonSecretFile = app.Flag(
+		"peloton-secret-file",
+		"Secret file containing all Peloton secrets").
+		Default("").
+		Envar("PELOTON_SECRET_FILE").
+		String()
+)
+
+func main() {
+	app.Version(version)
+	app.HelpFlag.Short('h')
+	kingpin.MustParse(app.Parse(os.Args[1:]))
+
+	log.SetFormatter(&log.JSONFormatter{})
+
+	var cfg Config
+	if err := config.Parse(&cfg, *cfgFiles...); err != nil {
+		log.WithError(err).Fatal("Cannot parse yaml config")
+	}
+
+	if *pelotonSecretFile != "" {
+		var secretsCfg config.PelotonSecretsConfig
+		if err := config.Parse(&secretsCfg, *pelotonSecretFile); err != nil {
+			log.WithError(err).
+				WithField("peloton_secret_file", *pelotonSecretFile).
+				Fatal("Cannot parse secret config")
+		}
+		cfg.Storage.Cassandra.CassandraConn.Username =
+			secretsCfg.CassandraUsername
+		cfg.Storage.Cassandra.CassandraConn.Password =
+			secretsCfg.CassandraPassword
+	}
+
+	secretKeyring, err := keyring.Load(*secretKeyringFile)
+	if err != nil {
+		log.WithError(err).
+			WithField("secret_keyring_file", *secretKeyringFile).
+			Fatal("Cannot load secret keyring")
+	}
+
+	store := stores.MustCreateStore(&cfg.Storage, tally.NoopScope)
+	ormStore := stores.MustCreateORMStore(&cfg.Storage, tally.NoopScope)
+
+	log.WithField("key_version", secretKeyring.CurrentVersion()).
+		Info("Rotating job secrets")
+	result, err := secrets.NewRotator(store, ormStore, secretKeyring).
+		Rotate(context.Background())
+	if err != nil {
+		log.WithError(err).Fatal("Failed to rotate job secrets")
+	}
+
+	log.WithField("rotated", result.Rotated).
+		WithField("failed", result.Failed).
+		Info("Rotated job secrets")
+	if result.Failed > 0 {
+		log.Fatal("Some job secrets failed to be rotated, run again to retry")
+	}
+}
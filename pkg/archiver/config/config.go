@@ -15,6 +15,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/uber/peloton/pkg/auth"
@@ -105,6 +106,41 @@ type ArchiverConfig struct {
 
 	// Kafka topic used by archiver to stream jobs via filebeat
 	KafkaTopic string `yaml:"kafka_topic"`
+
+	// Retention policies of the terminal batch jobs, which override
+	// ArchiveAge for the jobs they match. A job is only governed by the
+	// first policy it matches, so policies should be ordered from the
+	// most specific to the least specific.
+	RetentionPolicies []RetentionPolicy `yaml:"retention_policies"`
+
+	// Only report the jobs which the retention policies would delete,
+	// without deleting them. Jobs are archived after ArchiveAge
+	// regardless of the retention policies in the meantime.
+	RetentionDryRun bool `yaml:"retention_dry_run"`
+}
+
+// RetentionPolicy sets how long the terminal batch jobs of a resource
+// pool and/or with some labels are kept after they complete, by the
+// terminal state of the jobs. A TTL which is not set defaults to
+// ArchiveAge.
+type RetentionPolicy struct {
+	// Name of the policy, used in logs
+	Name string `yaml:"name"`
+
+	// ID of the resource pool of the jobs, any resource pool if empty
+	RespoolID string `yaml:"respool_id"`
+
+	// Labels that the jobs must all have, any labels if empty
+	Labels map[string]string `yaml:"labels"`
+
+	// How long to keep the jobs which succeeded, ex: 72h
+	SucceededTTL time.Duration `yaml:"succeeded_ttl"`
+
+	// How long to keep the jobs which failed, ex: 2160h
+	FailedTTL time.Duration `yaml:"failed_ttl"`
+
+	// How long to keep the jobs which were killed
+	KilledTTL time.Duration `yaml:"killed_ttl"`
 }
 
 // Normalize configuration by setting unassigned fields to default values.
@@ -133,4 +169,22 @@ func (c *ArchiverConfig) Normalize() {
 	if c.BootstrapDelay == 0 {
 		c.BootstrapDelay = _defaultBootstrapDelay
 	}
+	for i := range c.RetentionPolicies {
+		c.RetentionPolicies[i].normalize(i, c.ArchiveAge)
+	}
+}
+
+func (p *RetentionPolicy) normalize(index int, archiveAge time.Duration) {
+	if p.Name == "" {
+		p.Name = fmt.Sprintf("policy-%d", index)
+	}
+	if p.SucceededTTL == 0 {
+		p.SucceededTTL = archiveAge
+	}
+	if p.FailedTTL == 0 {
+		p.FailedTTL = archiveAge
+	}
+	if p.KilledTTL == 0 {
+		p.KilledTTL = archiveAge
+	}
 }
@@ -16,6 +16,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -34,3 +35,32 @@ func TestConfigNormalize(t *testing.T) {
 	assert.Equal(t, _defaultRetryIntervalJobQuery, c.RetryIntervalJobQuery)
 	assert.Equal(t, _defaultBootstrapDelay, c.BootstrapDelay)
 }
+
+func TestConfigNormalizeRetentionPolicies(t *testing.T) {
+	c := ArchiverConfig{
+		RetentionPolicies: []RetentionPolicy{
+			{
+				Name:         "ci",
+				SucceededTTL: 72 * time.Hour,
+			},
+			{
+				FailedTTL: 2160 * time.Hour,
+			},
+		},
+	}
+
+	c.Normalize()
+
+	assert.Equal(t, RetentionPolicy{
+		Name:         "ci",
+		SucceededTTL: 72 * time.Hour,
+		FailedTTL:    _defaultArchiveAge,
+		KilledTTL:    _defaultArchiveAge,
+	}, c.RetentionPolicies[0])
+	assert.Equal(t, RetentionPolicy{
+		Name:         "policy-1",
+		SucceededTTL: _defaultArchiveAge,
+		FailedTTL:    2160 * time.Hour,
+		KilledTTL:    _defaultArchiveAge,
+	}, c.RetentionPolicies[1])
+}
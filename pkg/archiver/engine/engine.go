@@ -93,6 +93,8 @@ type engine struct {
 	metrics *Metrics
 	// Archiver backoff/retry policy
 	retryPolicy backoff.RetryPolicy
+	// Completion times covered by the retention passes so far
+	retentionWindows map[retentionKey]*retentionWindow
 }
 
 // New creates a new Archiver Engine.
@@ -167,7 +169,8 @@ func New(
 
 // Start starts archiver with actions such as
 // 1) archive terminal batch jobs
-// 2) constraint pod events for RUNNING stateless jobs.
+// 2) enforce the retention policies of terminal batch jobs
+// 3) constraint pod events for RUNNING stateless jobs.
 // Actions are iterated sequentially to minimize the load on
 // Cassandra cluster to not impact real-time workload.
 func (e *engine) Start() error {
@@ -214,10 +217,23 @@ func (e *engine) Start() error {
 					Spec:        &spec,
 					SummaryOnly: true,
 				},
-				e.archiveJobs); err != nil {
+				func(ctx context.Context, results []*job.JobSummary) {
+					e.archiveJobs(ctx, e.filterRetainedJobs(results))
+				}); err != nil {
 				return err
 			}
 
+			// Jobs are not deleted in stream only mode, the same jobs
+			// would be streamed again by each run.
+			if !e.config.Archiver.StreamOnlyMode {
+				if err := e.enforceRetentionPolicies(
+					context.Background(), startTime.UTC()); err != nil {
+					// The windows which failed are retried by the next run.
+					log.WithError(err).
+						Error("Failed to enforce retention policies")
+				}
+			}
+
 			e.metrics.ArchiverRunDuration.Record(time.Since(startTime))
 			maxTime = minTime
 			minTime = minTime.Add(-e.config.Archiver.ArchiveStepSize)
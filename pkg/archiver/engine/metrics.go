@@ -31,6 +31,9 @@ type Metrics struct {
 	PodDeleteEventsFail    tally.Counter
 	PodDeleteEventsSuccess tally.Counter
 
+	RetentionExpiredJobs tally.Counter
+	RetentionDryRunJobs  tally.Counter
+
 	ArchiverRunDuration tally.Timer
 }
 
@@ -46,6 +49,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		ArchiverNoJobsInTimerange: scope.Counter("archiver_no_jobs_in_timerange"),
 		PodDeleteEventsSuccess:    scope.Counter("pod_delete_events_success"),
 		PodDeleteEventsFail:       scope.Counter("pod_delete_events_fail"),
+		RetentionExpiredJobs:      scope.Counter("retention_expired_jobs"),
+		RetentionDryRunJobs:       scope.Counter("retention_dry_run_jobs"),
 
 		ArchiverRunDuration: scope.Timer("archiver_run_duration"),
 	}
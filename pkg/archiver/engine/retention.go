@@ -0,0 +1,271 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/query"
+	"github.com/uber/peloton/pkg/archiver/config"
+	"github.com/uber/peloton/pkg/common/backoff"
+
+	"github.com/golang/protobuf/ptypes"
+	log "github.com/sirupsen/logrus"
+)
+
+// terminal states of the jobs governed by the retention policies
+var _retentionJobStates = []job.JobState{
+	job.JobState_SUCCEEDED,
+	job.JobState_FAILED,
+	job.JobState_KILLED,
+}
+
+// retentionKey identifies the jobs in a terminal state governed by a
+// retention policy, by the index of the policy.
+type retentionKey struct {
+	policy int
+	state  job.JobState
+}
+
+// retentionWindow is the range of completion times of the expired jobs
+// of a retention key which the retention passes have covered so far.
+type retentionWindow struct {
+	// completion time of the most recently expired jobs covered
+	newest time.Time
+	// completion time of the oldest expired jobs covered
+	oldest time.Time
+}
+
+// enforceRetentionPolicies deletes the terminal batch jobs which
+// completed longer than the TTL of their retention policy ago. In dry run
+// mode, the jobs are only reported.
+//
+// Job Query only supports bounded completion time ranges, so each pass
+// queries the jobs which expired since the last pass, and the jobs which
+// had already expired before the first pass one ArchiveStepSize older
+// per pass, in the same way as the archiver walks back in time.
+func (e *engine) enforceRetentionPolicies(
+	ctx context.Context,
+	now time.Time) error {
+	if e.retentionWindows == nil {
+		e.retentionWindows = make(map[retentionKey]*retentionWindow)
+	}
+	step := e.config.Archiver.ArchiveStepSize
+
+	for i := range e.config.Archiver.RetentionPolicies {
+		policy := &e.config.Archiver.RetentionPolicies[i]
+		for _, state := range _retentionJobStates {
+			top := now.Add(-retentionTTL(policy, state))
+			key := retentionKey{policy: i, state: state}
+			window, ok := e.retentionWindows[key]
+			if !ok {
+				window = &retentionWindow{newest: top, oldest: top}
+				e.retentionWindows[key] = window
+			}
+
+			// jobs which expired since the last pass
+			if top.After(window.newest) {
+				full, err := e.enforceRetentionWindow(
+					ctx, policy, state, window.newest, top)
+				if err != nil {
+					return err
+				}
+				if !full {
+					window.newest = top
+				}
+			}
+
+			// jobs which expired before the first pass
+			min := window.oldest.Add(-step)
+			full, err := e.enforceRetentionWindow(
+				ctx, policy, state, min, window.oldest)
+			if err != nil {
+				return err
+			}
+			if !full {
+				window.oldest = min
+			}
+		}
+	}
+	return nil
+}
+
+// enforceRetentionWindow deletes the jobs in the terminal state which
+// completed in [min, max] and are governed by the retention policy. It
+// returns true if the query returned as many jobs as it may and some
+// were deleted, in which case the window has to be queried again.
+func (e *engine) enforceRetentionWindow(
+	ctx context.Context,
+	policy *config.RetentionPolicy,
+	state job.JobState,
+	minTime time.Time,
+	maxTime time.Time) (bool, error) {
+	min, err := ptypes.TimestampProto(minTime)
+	if err != nil {
+		return false, err
+	}
+	max, err := ptypes.TimestampProto(maxTime)
+	if err != nil {
+		return false, err
+	}
+
+	req := &job.QueryRequest{
+		Spec: &job.QuerySpec{
+			Labels:              retentionLabels(policy),
+			JobStates:           []job.JobState{state},
+			CompletionTimeRange: &peloton.TimeRange{Min: min, Max: max},
+			Pagination: &query.PaginationSpec{
+				Offset:   0,
+				Limit:    uint32(e.config.Archiver.MaxArchiveEntries),
+				MaxLimit: uint32(e.config.Archiver.MaxArchiveEntries),
+			},
+		},
+		SummaryOnly: true,
+	}
+	if policy.RespoolID != "" {
+		req.RespoolID = &peloton.ResourcePoolID{Value: policy.RespoolID}
+	}
+
+	resp, err := e.queryJobs(ctx, req, backoff.NewRetrier(e.retryPolicy))
+	if err != nil {
+		e.metrics.ArchiverJobQueryFail.Inc(1)
+		return false, err
+	}
+
+	// The query also returns the jobs governed by a previous
+	// policy, which they match first.
+	var expired []*job.JobSummary
+	for _, summary := range resp.GetResults() {
+		if summary.GetType() == job.JobType_BATCH &&
+			e.retentionPolicyOf(summary) == policy {
+			expired = append(expired, summary)
+		}
+	}
+
+	e.reportExpiredJobs(policy, state, expired)
+	if len(expired) == 0 || e.config.Archiver.RetentionDryRun {
+		return false, nil
+	}
+	e.archiveJobs(ctx, expired)
+	return len(resp.GetResults()) >= e.config.Archiver.MaxArchiveEntries, nil
+}
+
+// reportExpiredJobs logs the jobs which expired under a retention policy.
+func (e *engine) reportExpiredJobs(
+	policy *config.RetentionPolicy,
+	state job.JobState,
+	expired []*job.JobSummary) {
+	logFields := log.Fields{
+		"policy":  policy.Name,
+		"state":   state.String(),
+		"ttl":     retentionTTL(policy, state).String(),
+		"expired": len(expired),
+		"dry_run": e.config.Archiver.RetentionDryRun,
+	}
+
+	if e.config.Archiver.RetentionDryRun {
+		e.metrics.RetentionDryRunJobs.Inc(int64(len(expired)))
+		for _, summary := range expired {
+			log.WithFields(logFields).
+				WithField("job_id", summary.GetId().GetValue()).
+				WithField("completion_time", summary.GetRuntime().GetCompletionTime()).
+				Info("Job would be deleted by retention policy")
+		}
+	}
+	e.metrics.RetentionExpiredJobs.Inc(int64(len(expired)))
+	log.WithFields(logFields).Info("Retention policy summary")
+}
+
+// filterRetainedJobs removes the jobs which are retained longer than
+// ArchiveAge by their retention policy, unless in dry run mode.
+func (e *engine) filterRetainedJobs(
+	results []*job.JobSummary) []*job.JobSummary {
+	if e.config.Archiver.RetentionDryRun {
+		return results
+	}
+
+	var filtered []*job.JobSummary
+	for _, summary := range results {
+		policy := e.retentionPolicyOf(summary)
+		if policy != nil && retentionTTL(
+			policy, summary.GetRuntime().GetState()) > e.config.Archiver.ArchiveAge {
+			continue
+		}
+		filtered = append(filtered, summary)
+	}
+	return filtered
+}
+
+// retentionPolicyOf returns the first retention policy the job matches,
+// or nil if the job matches none.
+func (e *engine) retentionPolicyOf(
+	summary *job.JobSummary) *config.RetentionPolicy {
+	for i := range e.config.Archiver.RetentionPolicies {
+		policy := &e.config.Archiver.RetentionPolicies[i]
+		if policy.RespoolID != "" &&
+			policy.RespoolID != summary.GetRespoolID().GetValue() {
+			continue
+		}
+		if !hasLabels(summary.GetLabels(), policy.Labels) {
+			continue
+		}
+		return policy
+	}
+	return nil
+}
+
+// retentionTTL returns the TTL of the jobs in the given terminal state
+// under the retention policy.
+func retentionTTL(
+	policy *config.RetentionPolicy,
+	state job.JobState) time.Duration {
+	switch state {
+	case job.JobState_SUCCEEDED:
+		return policy.SucceededTTL
+	case job.JobState_FAILED:
+		return policy.FailedTTL
+	default:
+		return policy.KilledTTL
+	}
+}
+
+// retentionLabels returns the labels of the retention policy.
+func retentionLabels(policy *config.RetentionPolicy) []*peloton.Label {
+	var labels []*peloton.Label
+	for key, value := range policy.Labels {
+		labels = append(labels, &peloton.Label{Key: key, Value: value})
+	}
+	return labels
+}
+
+// hasLabels returns true if the job labels contain all the given labels.
+func hasLabels(jobLabels []*peloton.Label, labels map[string]string) bool {
+	for key, value := range labels {
+		found := false
+		for _, label := range jobLabels {
+			if label.GetKey() == key && label.GetValue() == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,298 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	job_mocks "github.com/uber/peloton/.gen/peloton/api/v0/job/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/pkg/archiver/config"
+	"github.com/uber/peloton/pkg/common/backoff"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+const (
+	_ciRespoolID = "ci-respool"
+	_ciTTL       = 72 * time.Hour
+	_failedTTL   = 2160 * time.Hour
+	_archiveAge  = 720 * time.Hour
+)
+
+type retentionTestSuite struct {
+	suite.Suite
+
+	mockCtrl      *gomock.Controller
+	mockJobClient *job_mocks.MockJobManagerYARPCClient
+	now           time.Time
+	e             *engine
+}
+
+func TestRetention(t *testing.T) {
+	suite.Run(t, new(retentionTestSuite))
+}
+
+func (suite *retentionTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockJobClient = job_mocks.NewMockJobManagerYARPCClient(suite.mockCtrl)
+	suite.now = time.Date(2019, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	cfg := config.Config{
+		Archiver: config.ArchiverConfig{
+			MaxArchiveEntries: 10,
+			ArchiveAge:        _archiveAge,
+			RetentionPolicies: []config.RetentionPolicy{
+				{
+					Name:         "ci",
+					RespoolID:    _ciRespoolID,
+					Labels:       map[string]string{"team": "ci"},
+					SucceededTTL: _ciTTL,
+				},
+				{
+					Name:      "failed",
+					FailedTTL: _failedTTL,
+				},
+			},
+		},
+	}
+	cfg.Archiver.Normalize()
+
+	suite.e = &engine{
+		jobClient:   suite.mockJobClient,
+		config:      cfg,
+		metrics:     NewMetrics(tally.NoopScope),
+		retryPolicy: backoff.NewRetryPolicy(1, time.Millisecond),
+	}
+}
+
+func (suite *retentionTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+// newJobSummary creates the summary of a terminal batch job
+func newJobSummary(
+	id string,
+	respoolID string,
+	state job.JobState,
+	labels ...*peloton.Label) *job.JobSummary {
+	return &job.JobSummary{
+		Id:        &peloton.JobID{Value: id},
+		Type:      job.JobType_BATCH,
+		RespoolID: &peloton.ResourcePoolID{Value: respoolID},
+		Labels:    labels,
+		Runtime:   &job.RuntimeInfo{State: state},
+	}
+}
+
+// expectQuery expects the Job Query of the first retention pass, of the
+// jobs in the state which completed in the step before the TTL
+func (suite *retentionTestSuite) expectQuery(
+	respoolID string,
+	state job.JobState,
+	ttl time.Duration,
+	results ...*job.JobSummary) *gomock.Call {
+	max := suite.now.Add(-ttl)
+	return suite.expectWindowQuery(
+		respoolID,
+		state,
+		max.Add(-suite.e.config.Archiver.ArchiveStepSize),
+		max,
+		results...)
+}
+
+// expectWindowQuery expects a Job Query of the jobs in the state which
+// completed in [min, max]
+func (suite *retentionTestSuite) expectWindowQuery(
+	respoolID string,
+	state job.JobState,
+	minTime time.Time,
+	maxTime time.Time,
+	results ...*job.JobSummary) *gomock.Call {
+	min, err := ptypes.TimestampProto(minTime)
+	suite.NoError(err)
+	max, err := ptypes.TimestampProto(maxTime)
+	suite.NoError(err)
+
+	return suite.mockJobClient.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *job.QueryRequest) {
+			suite.Equal(respoolID, req.GetRespoolID().GetValue())
+			suite.Equal([]job.JobState{state}, req.GetSpec().GetJobStates())
+			suite.Equal(min, req.GetSpec().GetCompletionTimeRange().GetMin())
+			suite.Equal(max, req.GetSpec().GetCompletionTimeRange().GetMax())
+			suite.True(req.GetSummaryOnly())
+		}).
+		Return(&job.QueryResponse{Results: results}, nil)
+}
+
+// TestEnforceRetentionPolicies tests deleting the jobs expired under
+// their retention policy
+func (suite *retentionTestSuite) TestEnforceRetentionPolicies() {
+	ciLabel := &peloton.Label{Key: "team", Value: "ci"}
+	ciJob := newJobSummary("ci-job", _ciRespoolID, job.JobState_SUCCEEDED, ciLabel)
+	ciService := newJobSummary("ci-service", _ciRespoolID, job.JobState_SUCCEEDED, ciLabel)
+	ciService.Type = job.JobType_SERVICE
+	failedCIJob := newJobSummary("failed-ci-job", _ciRespoolID, job.JobState_FAILED, ciLabel)
+	failedJob := newJobSummary("failed-job", "other", job.JobState_FAILED)
+
+	gomock.InOrder(
+		suite.expectQuery(_ciRespoolID, job.JobState_SUCCEEDED, _ciTTL,
+			ciJob, ciService),
+		suite.mockJobClient.EXPECT().
+			Delete(gomock.Any(), &job.DeleteRequest{Id: ciJob.GetId()}).
+			Return(&job.DeleteResponse{}, nil),
+		suite.expectQuery(_ciRespoolID, job.JobState_FAILED, _archiveAge,
+			failedCIJob),
+		suite.mockJobClient.EXPECT().
+			Delete(gomock.Any(), &job.DeleteRequest{Id: failedCIJob.GetId()}).
+			Return(&job.DeleteResponse{}, nil),
+		suite.expectQuery(_ciRespoolID, job.JobState_KILLED, _archiveAge),
+		suite.expectQuery("", job.JobState_SUCCEEDED, _archiveAge),
+		// the failed job of the ci policy is governed by the ci policy
+		suite.expectQuery("", job.JobState_FAILED, _failedTTL,
+			failedCIJob, failedJob),
+		suite.mockJobClient.EXPECT().
+			Delete(gomock.Any(), &job.DeleteRequest{Id: failedJob.GetId()}).
+			Return(nil, fmt.Errorf("Job Delete failed")),
+		suite.expectQuery("", job.JobState_KILLED, _archiveAge),
+	)
+
+	suite.NoError(suite.e.enforceRetentionPolicies(context.Background(), suite.now))
+}
+
+// TestEnforceRetentionPoliciesWindows tests that each retention pass
+// queries the jobs which expired since the last pass and one step older
+// jobs, and queries a window again while it returns a full page
+func (suite *retentionTestSuite) TestEnforceRetentionPoliciesWindows() {
+	suite.e.config.Archiver.MaxArchiveEntries = 1
+	suite.e.config.Archiver.RetentionPolicies =
+		suite.e.config.Archiver.RetentionPolicies[1:]
+	step := suite.e.config.Archiver.ArchiveStepSize
+	failedJob := newJobSummary("failed-job", "other", job.JobState_FAILED)
+
+	// windows covered by the first pass
+	succeededTop := suite.now.Add(-_archiveAge)
+	failedTop := suite.now.Add(-_failedTTL)
+	gomock.InOrder(
+		suite.expectQuery("", job.JobState_SUCCEEDED, _archiveAge),
+		suite.expectQuery("", job.JobState_FAILED, _failedTTL),
+		suite.expectQuery("", job.JobState_KILLED, _archiveAge),
+	)
+	suite.NoError(suite.e.enforceRetentionPolicies(context.Background(), suite.now))
+
+	// the second pass covers the jobs which expired in the last hour and
+	// one step older jobs
+	later := suite.now.Add(time.Hour)
+	gomock.InOrder(
+		suite.expectWindowQuery("", job.JobState_SUCCEEDED,
+			succeededTop, succeededTop.Add(time.Hour)),
+		suite.expectWindowQuery("", job.JobState_SUCCEEDED,
+			succeededTop.Add(-2*step), succeededTop.Add(-step)),
+		suite.expectWindowQuery("", job.JobState_FAILED,
+			failedTop, failedTop.Add(time.Hour)),
+		suite.expectWindowQuery("", job.JobState_FAILED,
+			failedTop.Add(-2*step), failedTop.Add(-step), failedJob),
+		suite.mockJobClient.EXPECT().
+			Delete(gomock.Any(), &job.DeleteRequest{Id: failedJob.GetId()}).
+			Return(&job.DeleteResponse{}, nil),
+		suite.expectWindowQuery("", job.JobState_KILLED,
+			succeededTop, succeededTop.Add(time.Hour)),
+		suite.expectWindowQuery("", job.JobState_KILLED,
+			succeededTop.Add(-2*step), succeededTop.Add(-step)),
+	)
+	suite.NoError(suite.e.enforceRetentionPolicies(context.Background(), later))
+
+	// the full window of failed jobs is queried again
+	gomock.InOrder(
+		suite.expectWindowQuery("", job.JobState_SUCCEEDED,
+			succeededTop.Add(-3*step), succeededTop.Add(-2*step)),
+		suite.expectWindowQuery("", job.JobState_FAILED,
+			failedTop.Add(-2*step), failedTop.Add(-step)),
+		suite.expectWindowQuery("", job.JobState_KILLED,
+			succeededTop.Add(-3*step), succeededTop.Add(-2*step)),
+	)
+	suite.NoError(suite.e.enforceRetentionPolicies(context.Background(), later))
+}
+
+// TestEnforceRetentionPoliciesDryRun tests that jobs are not deleted
+// in dry run mode
+func (suite *retentionTestSuite) TestEnforceRetentionPoliciesDryRun() {
+	suite.e.config.Archiver.RetentionDryRun = true
+	ciJob := newJobSummary("ci-job", _ciRespoolID, job.JobState_SUCCEEDED,
+		&peloton.Label{Key: "team", Value: "ci"})
+
+	suite.mockJobClient.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Return(&job.QueryResponse{
+			Results: []*job.JobSummary{ciJob},
+		}, nil).
+		Times(6)
+
+	suite.NoError(suite.e.enforceRetentionPolicies(context.Background(), suite.now))
+}
+
+// TestEnforceRetentionPoliciesQueryFail tests the failure of Job Query
+func (suite *retentionTestSuite) TestEnforceRetentionPoliciesQueryFail() {
+	suite.mockJobClient.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("Job Query failed"))
+
+	suite.Error(suite.e.enforceRetentionPolicies(context.Background(), suite.now))
+}
+
+// TestFilterRetainedJobs tests that archiving after ArchiveAge skips the
+// jobs retained longer by their retention policy
+func (suite *retentionTestSuite) TestFilterRetainedJobs() {
+	ciJob := newJobSummary("ci-job", _ciRespoolID, job.JobState_SUCCEEDED,
+		&peloton.Label{Key: "team", Value: "ci"})
+	failedJob := newJobSummary("failed-job", "other", job.JobState_FAILED)
+	succeededJob := newJobSummary("succeeded-job", "other", job.JobState_SUCCEEDED)
+	results := []*job.JobSummary{ciJob, failedJob, succeededJob}
+
+	suite.Equal(
+		[]*job.JobSummary{ciJob, succeededJob},
+		suite.e.filterRetainedJobs(results))
+
+	suite.e.config.Archiver.RetentionDryRun = true
+	suite.Equal(results, suite.e.filterRetainedJobs(results))
+}
+
+// TestRetentionPolicyOf tests matching jobs with retention policies
+func (suite *retentionTestSuite) TestRetentionPolicyOf() {
+	policies := suite.e.config.Archiver.RetentionPolicies
+
+	suite.Equal(&policies[0], suite.e.retentionPolicyOf(
+		newJobSummary("job", _ciRespoolID, job.JobState_SUCCEEDED,
+			&peloton.Label{Key: "owner", Value: "me"},
+			&peloton.Label{Key: "team", Value: "ci"})))
+	suite.Equal(&policies[1], suite.e.retentionPolicyOf(
+		newJobSummary("job", _ciRespoolID, job.JobState_SUCCEEDED,
+			&peloton.Label{Key: "team", Value: "web"})))
+	suite.Equal(&policies[1], suite.e.retentionPolicyOf(
+		newJobSummary("job", "other", job.JobState_SUCCEEDED,
+			&peloton.Label{Key: "team", Value: "ci"})))
+
+	suite.e.config.Archiver.RetentionPolicies = policies[:1]
+	suite.Nil(suite.e.retentionPolicyOf(
+		newJobSummary("job", "other", job.JobState_SUCCEEDED)))
+}
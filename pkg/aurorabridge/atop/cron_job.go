@@ -0,0 +1,90 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atop
+
+import (
+	"fmt"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/uber/peloton/pkg/jobmgr/util/handler"
+)
+
+// NewCronJobConfig creates a new CronJobConfig, whose runs create batch
+// jobs from the task config of the Aurora job.
+func NewCronJobConfig(
+	c *api.JobConfiguration,
+	respoolID *peloton.ResourcePoolID,
+	tc ThermosExecutorConfig,
+) (*cron.CronJobConfig, error) {
+
+	if !c.IsSetKey() {
+		return nil, fmt.Errorf("key is not set in job configuration")
+	}
+	if !c.IsSetTaskConfig() {
+		return nil, fmt.Errorf("task config is not set in job configuration")
+	}
+	if c.GetCronSchedule() == "" {
+		return nil, fmt.Errorf("cron schedule is not set in job configuration")
+	}
+
+	// The job key of the task config is optional for cron jobs,
+	// while it is needed to build the labels of the job.
+	t := *c.GetTaskConfig()
+	t.Job = c.GetKey()
+
+	jobSpec, err := NewJobSpecFromJobUpdateRequest(
+		&api.JobUpdateRequest{
+			TaskConfig:    &t,
+			InstanceCount: c.InstanceCount,
+		},
+		respoolID,
+		tc,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("new job spec: %s", err)
+	}
+
+	jobConfig, err := handler.ConvertJobSpecToJobConfig(jobSpec)
+	if err != nil {
+		return nil, fmt.Errorf("convert job spec: %s", err)
+	}
+	jobConfig.Type = job.JobType_BATCH
+
+	return &cron.CronJobConfig{
+		Name:            NewJobName(c.GetKey()),
+		Schedule:        c.GetCronSchedule(),
+		CollisionPolicy: NewCronCollisionPolicy(c.CronCollisionPolicy),
+		JobConfig:       jobConfig,
+	}, nil
+}
+
+// NewCronCollisionPolicy creates a new CollisionPolicy. Aurora defaults
+// to KILL_EXISTING if the policy is not set, and treats the deprecated
+// RUN_OVERLAP the same as CANCEL_NEW.
+func NewCronCollisionPolicy(p *api.CronCollisionPolicy) cron.CollisionPolicy {
+	if p == nil {
+		return cron.CollisionPolicy_KILL_PREVIOUS
+	}
+	switch *p {
+	case api.CronCollisionPolicyCancelNew, api.CronCollisionPolicyRunOverlap:
+		return cron.CollisionPolicy_SKIP
+	default:
+		return cron.CollisionPolicy_KILL_PREVIOUS
+	}
+}
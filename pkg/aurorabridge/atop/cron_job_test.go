@@ -0,0 +1,104 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package atop
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+	"github.com/uber/peloton/pkg/aurorabridge/common"
+	"github.com/uber/peloton/pkg/aurorabridge/fixture"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/thriftrw/ptr"
+)
+
+// Ensures that the cron job config is created from the Aurora job
+// configuration, with a batch job template.
+func TestNewCronJobConfig(t *testing.T) {
+	k := fixture.AuroraJobKey()
+	respoolID := fixture.PelotonResourcePoolID()
+
+	c, err := NewCronJobConfig(
+		&api.JobConfiguration{
+			Key:           k,
+			CronSchedule:  ptr.String("@daily"),
+			TaskConfig:    &api.TaskConfig{},
+			InstanceCount: ptr.Int32(3),
+		},
+		respoolID,
+		ThermosExecutorConfig{},
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, NewJobName(k), c.GetName())
+	assert.Equal(t, "@daily", c.GetSchedule())
+	assert.Equal(t, cron.CollisionPolicy_KILL_PREVIOUS, c.GetCollisionPolicy())
+	assert.Equal(t, job.JobType_BATCH, c.GetJobConfig().GetType())
+	assert.Equal(t, NewJobName(k), c.GetJobConfig().GetName())
+	assert.Equal(t, uint32(3), c.GetJobConfig().GetInstanceCount())
+	assert.Equal(t, respoolID.GetValue(), c.GetJobConfig().GetRespoolID().GetValue())
+
+	var bridgeLabel bool
+	for _, l := range c.GetJobConfig().GetLabels() {
+		if l.GetKey() == common.BridgeJobLabel.GetKey() {
+			bridgeLabel = true
+		}
+	}
+	assert.True(t, bridgeLabel)
+}
+
+// Ensures that invalid Aurora job configurations are rejected.
+func TestNewCronJobConfig_Invalid(t *testing.T) {
+	for _, c := range []*api.JobConfiguration{
+		{
+			CronSchedule: ptr.String("@daily"),
+			TaskConfig:   &api.TaskConfig{},
+		},
+		{
+			Key:          fixture.AuroraJobKey(),
+			CronSchedule: ptr.String("@daily"),
+		},
+		{
+			Key:        fixture.AuroraJobKey(),
+			TaskConfig: &api.TaskConfig{},
+		},
+	} {
+		_, err := NewCronJobConfig(
+			c,
+			fixture.PelotonResourcePoolID(),
+			ThermosExecutorConfig{},
+		)
+		assert.Error(t, err)
+	}
+}
+
+// Ensures that Aurora cron collision policies are translated.
+func TestNewCronCollisionPolicy(t *testing.T) {
+	tests := []struct {
+		policy *api.CronCollisionPolicy
+		want   cron.CollisionPolicy
+	}{
+		{nil, cron.CollisionPolicy_KILL_PREVIOUS},
+		{api.CronCollisionPolicyKillExisting.Ptr(), cron.CollisionPolicy_KILL_PREVIOUS},
+		{api.CronCollisionPolicyCancelNew.Ptr(), cron.CollisionPolicy_SKIP},
+		{api.CronCollisionPolicyRunOverlap.Ptr(), cron.CollisionPolicy_SKIP},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, NewCronCollisionPolicy(test.policy))
+	}
+}
@@ -31,11 +31,31 @@ import (
 	"go.uber.org/thriftrw/ptr"
 )
 
+const (
+	// _healthPortName is the named port thermos http health checkers query.
+	_healthPortName = "health"
+
+	// _healthPortEnvName is the environment variable the health port is
+	// exported as.
+	_healthPortEnvName = "AURORA_HEALTH_PORT"
+
+	// _defaultHealthEndpoint is the endpoint thermos http health checkers
+	// query by default.
+	_defaultHealthEndpoint = "/health"
+)
+
 // NewPodSpec creates a new PodSpec.
 func NewPodSpec(
 	t *api.TaskConfig,
 	c ThermosExecutorConfig,
 ) (*pod.PodSpec, error) {
+	// Read the readiness check before encodeTaskConfig rewrites the
+	// executor data.
+	readinessCheck, err := newReadinessCheck(t.GetExecutorConfig())
+	if err != nil {
+		return nil, fmt.Errorf("new readiness check: %s", err)
+	}
+
 	// Taking aurora TaskConfig struct from JobUpdateRequest, and
 	// serialize it using Thrift binary protocol. The resulting
 	// byte array will be attached to ExecutorInfo.Data.
@@ -75,7 +95,7 @@ func NewPodSpec(
 			Command:        NewThermosCommandInfo(c),
 			Executor:       NewThermosExecutorInfo(c, executorData),
 			LivenessCheck:  nil, // TODO(codyg): Figure this default.
-			ReadinessCheck: nil, // Unused.
+			ReadinessCheck: readinessCheck,
 			Ports:          newPortSpecs(t.GetResources()),
 		}},
 		Constraint:             constraint,
@@ -116,12 +136,98 @@ func newPortSpecs(rs []*api.Resource) []*pod.PortSpec {
 	var result []*pod.PortSpec
 	for _, r := range rs {
 		if r.IsSetNamedPort() {
-			result = append(result, &pod.PortSpec{Name: r.GetNamedPort()})
+			spec := &pod.PortSpec{Name: r.GetNamedPort()}
+			if spec.Name == _healthPortName {
+				// Export the health port so that the readiness check
+				// command can reach the health endpoint.
+				spec.EnvName = _healthPortEnvName
+			}
+			result = append(result, spec)
 		}
 	}
 	return result
 }
 
+// thermosHealthCheckConfig is the health_check_config section of the
+// thermos executor config which Aurora clients attach to the task.
+type thermosHealthCheckConfig struct {
+	HealthChecker struct {
+		HTTP *struct {
+			Endpoint             string `json:"endpoint"`
+			ExpectedResponseCode int    `json:"expected_response_code"`
+		} `json:"http"`
+		Shell *struct {
+			ShellCommand string `json:"shell_command"`
+		} `json:"shell"`
+	} `json:"health_checker"`
+	InitialIntervalSecs    float64 `json:"initial_interval_secs"`
+	IntervalSecs           float64 `json:"interval_secs"`
+	MaxConsecutiveFailures float64 `json:"max_consecutive_failures"`
+	TimeoutSecs            float64 `json:"timeout_secs"`
+}
+
+// newReadinessCheck converts the thermos health check of an Aurora task
+// into a readiness check, so that updates wait for instances to pass
+// it. Returns nil if the task does not configure a health check.
+func newReadinessCheck(ec *api.ExecutorConfig) (*pod.HealthCheckSpec, error) {
+	if len(ec.GetData()) == 0 {
+		return nil, nil
+	}
+
+	var data struct {
+		HealthCheckConfig *thermosHealthCheckConfig `json:"health_check_config"`
+	}
+	if err := json.Unmarshal([]byte(ec.GetData()), &data); err != nil {
+		return nil, fmt.Errorf("unmarshal executor data: %s", err)
+	}
+	hc := data.HealthCheckConfig
+	if hc == nil {
+		return nil, nil
+	}
+
+	var command string
+	switch {
+	case hc.HealthChecker.Shell != nil:
+		command = hc.HealthChecker.Shell.ShellCommand
+	case hc.HealthChecker.HTTP != nil:
+		command = newHTTPCheckCommand(
+			hc.HealthChecker.HTTP.Endpoint,
+			hc.HealthChecker.HTTP.ExpectedResponseCode,
+		)
+	default:
+		return nil, nil
+	}
+
+	return &pod.HealthCheckSpec{
+		Enabled:                true,
+		InitialIntervalSecs:    uint32(hc.InitialIntervalSecs),
+		IntervalSecs:           uint32(hc.IntervalSecs),
+		MaxConsecutiveFailures: uint32(hc.MaxConsecutiveFailures),
+		TimeoutSecs:            uint32(hc.TimeoutSecs),
+		Type:                   pod.HealthCheckSpec_HEALTH_CHECK_TYPE_COMMAND,
+		CommandCheck: &pod.HealthCheckSpec_CommandCheck{
+			Command: command,
+		},
+	}, nil
+}
+
+// newHTTPCheckCommand returns the command which queries the health
+// endpoint of a thermos http health checker. The health port is
+// dynamically allocated, so it is read from the environment instead
+// of using a http check.
+func newHTTPCheckCommand(endpoint string, expectedCode int) string {
+	if endpoint == "" {
+		endpoint = _defaultHealthEndpoint
+	}
+	url := fmt.Sprintf("http://localhost:${%s}%s", _healthPortEnvName, endpoint)
+	if expectedCode == 0 {
+		return fmt.Sprintf("curl --silent --fail --output /dev/null %s", url)
+	}
+	return fmt.Sprintf(
+		"test \"$(curl --silent --output /dev/null --write-out '%%{http_code}' %s)\" = %d",
+		url, expectedCode)
+}
+
 func newMesosContainerInfo(c *api.Container) *mesos_v1.ContainerInfo {
 	if c == nil {
 		return nil
@@ -17,6 +17,7 @@ package atop
 import (
 	"testing"
 
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/thrift/aurora/api"
 
 	"github.com/stretchr/testify/assert"
@@ -67,6 +68,120 @@ func TestNewPodSpec_ContainersResource(t *testing.T) {
 	assert.Len(t, p.GetLabels(), 3)
 }
 
+// Ensures that the thermos health check is converted into the
+// readiness check of the PodSpec.
+func TestNewPodSpec_ReadinessCheck(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		command string
+	}{
+		{
+			name:    "no executor data",
+			data:    "",
+			command: "",
+		},
+		{
+			name:    "no health check",
+			data:    `{"name": "test"}`,
+			command: "",
+		},
+		{
+			name: "shell health check",
+			data: `{"health_check_config": {
+				"health_checker": {"shell": {"shell_command": "ready.sh"}},
+				"initial_interval_secs": 15.0,
+				"interval_secs": 10.0,
+				"max_consecutive_failures": 3,
+				"timeout_secs": 1.0
+			}}`,
+			command: "ready.sh",
+		},
+		{
+			name: "http health check",
+			data: `{"health_check_config": {
+				"health_checker": {"http": {
+					"endpoint": "/ready",
+					"expected_response_code": 0
+				}},
+				"initial_interval_secs": 15.0,
+				"interval_secs": 10.0,
+				"max_consecutive_failures": 3,
+				"timeout_secs": 1.0
+			}}`,
+			command: "curl --silent --fail --output /dev/null " +
+				"http://localhost:${AURORA_HEALTH_PORT}/ready",
+		},
+		{
+			name: "http health check with expected response code",
+			data: `{"health_check_config": {
+				"health_checker": {"http": {"expected_response_code": 200}},
+				"initial_interval_secs": 15.0,
+				"interval_secs": 10.0,
+				"max_consecutive_failures": 3,
+				"timeout_secs": 1.0
+			}}`,
+			command: "test \"$(curl --silent --output /dev/null " +
+				"--write-out '%{http_code}' " +
+				"http://localhost:${AURORA_HEALTH_PORT}/health)\" = 200",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := NewPodSpec(
+				&api.TaskConfig{
+					ExecutorConfig: &api.ExecutorConfig{
+						Name: ptr.String("AuroraExecutor"),
+						Data: ptr.String(tc.data),
+					},
+					Resources: []*api.Resource{
+						{NamedPort: ptr.String("health")},
+						{NamedPort: ptr.String("http")},
+					},
+				},
+				ThermosExecutorConfig{},
+			)
+			assert.NoError(t, err)
+
+			c := p.Containers[0]
+			assert.Equal(t, []*pod.PortSpec{
+				{Name: "health", EnvName: "AURORA_HEALTH_PORT"},
+				{Name: "http"},
+			}, c.GetPorts())
+
+			if tc.command == "" {
+				assert.Nil(t, c.GetReadinessCheck())
+				return
+			}
+			assert.Equal(t, &pod.HealthCheckSpec{
+				Enabled:                true,
+				InitialIntervalSecs:    15,
+				IntervalSecs:           10,
+				MaxConsecutiveFailures: 3,
+				TimeoutSecs:            1,
+				Type:                   pod.HealthCheckSpec_HEALTH_CHECK_TYPE_COMMAND,
+				CommandCheck: &pod.HealthCheckSpec_CommandCheck{
+					Command: tc.command,
+				},
+			}, c.GetReadinessCheck())
+		})
+	}
+}
+
+// Ensures that invalid executor data fails the PodSpec conversion.
+func TestNewPodSpec_InvalidExecutorData(t *testing.T) {
+	_, err := NewPodSpec(
+		&api.TaskConfig{
+			ExecutorConfig: &api.ExecutorConfig{
+				Data: ptr.String("{"),
+			},
+		},
+		ThermosExecutorConfig{},
+	)
+	assert.Error(t, err)
+}
+
 // TestEncodeTaskConfig_Consistency make sure encodeTaskConfig generated
 // byte arrays are consistent across TaskConfigs whose some of the fields
 // are different by order.
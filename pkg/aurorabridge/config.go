@@ -28,6 +28,7 @@ type ServiceHandlerConfig struct {
 	GetTasksWithoutConfigsWorkers int `yaml:"get_tasks_without_configs_workers"`
 	StopPodWorkers                int `yaml:"stop_pod_workers"`
 	CreateJobSpecForUpdateWorkers int `yaml:"create_job_spec_for_update_workers"`
+	GetPendingReasonWorkers       int `yaml:"get_pending_reason_workers"`
 
 	// getTasksWithoutConfigs task querying depth. It limits the number
 	// of pods to be included in the return result - return pods from
@@ -55,6 +56,9 @@ func (c *ServiceHandlerConfig) normalize() {
 	if c.CreateJobSpecForUpdateWorkers == 0 {
 		c.CreateJobSpecForUpdateWorkers = 25
 	}
+	if c.GetPendingReasonWorkers == 0 {
+		c.GetPendingReasonWorkers = 25
+	}
 	if c.PodRunsDepth <= 0 {
 		c.PodRunsDepth = 1
 	}
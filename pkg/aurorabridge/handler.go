@@ -21,6 +21,7 @@ import (
 	"strings"
 	"sync"
 
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
 	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
@@ -54,6 +55,7 @@ type ServiceHandler struct {
 	metrics       *Metrics
 	jobClient     statelesssvc.JobServiceYARPCClient
 	podClient     podsvc.PodServiceYARPCClient
+	cronClient    cronsvc.CronServiceYARPCClient
 	respoolLoader RespoolLoader
 	random        common.Random
 }
@@ -64,6 +66,7 @@ func NewServiceHandler(
 	parent tally.Scope,
 	jobClient statelesssvc.JobServiceYARPCClient,
 	podClient podsvc.PodServiceYARPCClient,
+	cronClient cronsvc.CronServiceYARPCClient,
 	respoolLoader RespoolLoader,
 	random common.Random,
 ) (*ServiceHandler, error) {
@@ -78,6 +81,7 @@ func NewServiceHandler(
 		metrics:       NewMetrics(parent.SubScope("aurorabridge").SubScope("api")),
 		jobClient:     jobClient,
 		podClient:     podClient,
+		cronClient:    cronClient,
 		respoolLoader: respoolLoader,
 		random:        random,
 	}, nil
@@ -258,6 +262,125 @@ func (h *ServiceHandler) getTasksWithoutConfigs(
 	}, nil
 }
 
+// GetPendingReason returns user-friendly reasons for the tasks matching the
+// query which are in PENDING state.
+func (h *ServiceHandler) GetPendingReason(
+	ctx context.Context,
+	query *api.TaskQuery,
+) (*api.Response, error) {
+
+	result, err := h.getPendingReason(ctx, query)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"query": query,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("GetPendingReason error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"query": query,
+			},
+			"result": result,
+		}).Debug("GetPendingReason success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) getPendingReason(
+	ctx context.Context,
+	query *api.TaskQuery,
+) (*api.Result, *auroraError) {
+
+	jobIDs, err := h.getJobIDsFromTaskQuery(ctx, query)
+	if err != nil {
+		return nil, auroraErrorf("get job ids from task query: %s", err)
+	}
+
+	var inputs []interface{}
+	for _, jobID := range jobIDs {
+		jobSummary, err := h.getJobInfoSummary(ctx, jobID)
+		if err != nil {
+			if yarpcerrors.IsNotFound(err) {
+				continue
+			}
+			return nil, auroraErrorf("get job info for job id %q: %s",
+				jobID.GetValue(), err)
+		}
+
+		pods, err := h.queryPods(
+			ctx,
+			jobID,
+			jobSummary.GetInstanceCount(),
+		)
+		if err != nil {
+			return nil, auroraErrorf(
+				"query pods for job id %q: %s", jobID.GetValue(), err)
+		}
+
+		for _, p := range pods {
+			s, err := ptoa.NewScheduleStatus(p.GetStatus().GetState())
+			if err != nil {
+				return nil, auroraErrorf("new schedule status: %s", err)
+			}
+			if *s == api.ScheduleStatusPending {
+				inputs = append(inputs, p)
+			}
+		}
+	}
+
+	f := func(ctx context.Context, input interface{}) (interface{}, error) {
+		p := input.(*pod.PodInfo)
+		resp, err := h.podClient.GetPodPendingReason(
+			ctx,
+			&podsvc.GetPodPendingReasonRequest{
+				PodName: p.GetSpec().GetPodName(),
+			})
+		if err != nil {
+			if yarpcerrors.IsNotFound(err) {
+				// the pod has been placed since it was queried
+				return nil, nil
+			}
+			return nil, fmt.Errorf(
+				"get pending reason for pod %q: %s",
+				p.GetSpec().GetPodName().GetValue(), err)
+		}
+		return &api.PendingReason{
+			TaskId: ptr.String(p.GetStatus().GetPodId().GetValue()),
+			Reason: ptr.String(resp.GetReason().GetMessage()),
+		}, nil
+	}
+
+	outputs, err := concurrency.Map(
+		ctx,
+		concurrency.MapperFunc(f),
+		inputs,
+		h.config.GetPendingReasonWorkers)
+	if err != nil {
+		return nil, auroraErrorf("get pending reasons: %s", err)
+	}
+
+	reasons := []*api.PendingReason{}
+	for _, o := range outputs {
+		r := o.(*api.PendingReason)
+		if r == nil {
+			continue
+		}
+		reasons = append(reasons, r)
+	}
+
+	return &api.Result{
+		GetPendingReasonResult: &api.GetPendingReasonResult{
+			Reasons: reasons,
+		},
+	}, nil
+}
+
 type taskFilter struct {
 	statuses map[api.ScheduleStatus]struct{}
 }
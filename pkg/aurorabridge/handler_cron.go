@@ -0,0 +1,258 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aurorabridge
+
+import (
+	"context"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/uber/peloton/pkg/aurorabridge/atop"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// ScheduleCronJob creates a cron job, or replaces the template of the
+// cron job if it already exists.
+func (h *ServiceHandler) ScheduleCronJob(
+	ctx context.Context,
+	description *api.JobConfiguration,
+) (*api.Response, error) {
+
+	result, err := h.scheduleCronJob(ctx, description)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"description": description,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("ScheduleCronJob error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"description": description,
+			},
+		}).Info("ScheduleCronJob success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) scheduleCronJob(
+	ctx context.Context,
+	description *api.JobConfiguration,
+) (*api.Result, *auroraError) {
+
+	config, aerr := h.newCronJobConfig(ctx, description)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	_, err := h.cronClient.CreateCronJob(
+		ctx,
+		&cronsvc.CreateCronJobRequest{Config: config},
+	)
+	if err == nil {
+		return &api.Result{}, nil
+	}
+	if !yarpcerrors.IsAlreadyExists(err) {
+		return nil, newCronJobError("create cron job", err)
+	}
+
+	_, err = h.cronClient.ReplaceCronJob(
+		ctx,
+		&cronsvc.ReplaceCronJobRequest{Config: config},
+	)
+	if err != nil {
+		return nil, newCronJobError("replace cron job", err)
+	}
+	return &api.Result{}, nil
+}
+
+// DescheduleCronJob deletes a cron job. Jobs created by previous runs
+// of the cron job keep running.
+func (h *ServiceHandler) DescheduleCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Response, error) {
+
+	result, err := h.descheduleCronJob(ctx, job)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"job": job,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("DescheduleCronJob error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"job": job,
+			},
+		}).Info("DescheduleCronJob success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) descheduleCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Result, *auroraError) {
+
+	_, err := h.cronClient.DeleteCronJob(
+		ctx,
+		&cronsvc.DeleteCronJobRequest{Name: atop.NewJobName(job)},
+	)
+	if err != nil {
+		return nil, newCronJobError("delete cron job", err)
+	}
+	return &api.Result{}, nil
+}
+
+// StartCronJob triggers a run of a cron job outside of its schedule.
+func (h *ServiceHandler) StartCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Response, error) {
+
+	result, err := h.startCronJob(ctx, job)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"job": job,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("StartCronJob error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"job": job,
+			},
+		}).Info("StartCronJob success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) startCronJob(
+	ctx context.Context,
+	job *api.JobKey,
+) (*api.Result, *auroraError) {
+
+	_, err := h.cronClient.StartCronJob(
+		ctx,
+		&cronsvc.StartCronJobRequest{Name: atop.NewJobName(job)},
+	)
+	if err != nil {
+		return nil, newCronJobError("start cron job", err)
+	}
+	return &api.Result{}, nil
+}
+
+// ReplaceCronTemplate replaces the template of an existing cron job.
+func (h *ServiceHandler) ReplaceCronTemplate(
+	ctx context.Context,
+	config *api.JobConfiguration,
+) (*api.Response, error) {
+
+	result, err := h.replaceCronTemplate(ctx, config)
+	defer func() {
+		if err != nil {
+			log.WithFields(log.Fields{
+				"params": log.Fields{
+					"config": config,
+				},
+				"code":  err.responseCode,
+				"error": err.msg,
+			}).Error("ReplaceCronTemplate error")
+			return
+		}
+
+		log.WithFields(log.Fields{
+			"params": log.Fields{
+				"config": config,
+			},
+		}).Info("ReplaceCronTemplate success")
+	}()
+	return newResponse(result, err), nil
+}
+
+func (h *ServiceHandler) replaceCronTemplate(
+	ctx context.Context,
+	config *api.JobConfiguration,
+) (*api.Result, *auroraError) {
+
+	cronConfig, aerr := h.newCronJobConfig(ctx, config)
+	if aerr != nil {
+		return nil, aerr
+	}
+
+	_, err := h.cronClient.ReplaceCronJob(
+		ctx,
+		&cronsvc.ReplaceCronJobRequest{Config: cronConfig},
+	)
+	if err != nil {
+		return nil, newCronJobError("replace cron job", err)
+	}
+	return &api.Result{}, nil
+}
+
+// newCronJobConfig converts the configuration of an Aurora cron job
+// into the config of a Peloton cron job.
+func (h *ServiceHandler) newCronJobConfig(
+	ctx context.Context,
+	config *api.JobConfiguration,
+) (*cron.CronJobConfig, *auroraError) {
+
+	respoolID, err := h.respoolLoader.Load(ctx)
+	if err != nil {
+		return nil, auroraErrorf("load respool: %s", err)
+	}
+
+	cronConfig, err := atop.NewCronJobConfig(
+		config,
+		respoolID,
+		h.config.ThermosExecutor,
+	)
+	if err != nil {
+		return nil, auroraErrorf("new cron job config: %s", err).
+			code(api.ResponseCodeInvalidRequest)
+	}
+	return cronConfig, nil
+}
+
+// newCronJobError converts an error of the cron service into an
+// Aurora error. Invalid and unknown cron jobs are invalid requests
+// in Aurora.
+func newCronJobError(msg string, err error) *auroraError {
+	aerr := auroraErrorf("%s: %s", msg, err)
+	if yarpcerrors.IsInvalidArgument(err) || yarpcerrors.IsNotFound(err) {
+		aerr.code(api.ResponseCodeInvalidRequest)
+	}
+	return aerr
+}
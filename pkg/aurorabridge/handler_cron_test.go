@@ -0,0 +1,199 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aurorabridge
+
+import (
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/thrift/aurora/api"
+
+	"github.com/uber/peloton/pkg/aurorabridge/atop"
+	"github.com/uber/peloton/pkg/aurorabridge/fixture"
+
+	"github.com/golang/mock/gomock"
+	"go.uber.org/thriftrw/ptr"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+func (suite *ServiceHandlerTestSuite) newCronJobConfiguration() *api.JobConfiguration {
+	return &api.JobConfiguration{
+		Key:                 fixture.AuroraJobKey(),
+		CronSchedule:        ptr.String("0 2 * * *"),
+		CronCollisionPolicy: api.CronCollisionPolicyCancelNew.Ptr(),
+		TaskConfig:          &api.TaskConfig{},
+		InstanceCount:       ptr.Int32(2),
+	}
+}
+
+// Ensures that ScheduleCronJob creates a cron job from the Aurora
+// job configuration.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJob() {
+	config := suite.newCronJobConfiguration()
+	respoolID := fixture.PelotonResourcePoolID()
+
+	suite.respoolLoader.EXPECT().Load(suite.ctx).Return(respoolID, nil)
+	suite.cronClient.EXPECT().
+		CreateCronJob(suite.ctx, gomock.Any()).
+		Do(func(_ interface{}, req *cronsvc.CreateCronJobRequest) {
+			c := req.GetConfig()
+			suite.Equal(atop.NewJobName(config.GetKey()), c.GetName())
+			suite.Equal("0 2 * * *", c.GetSchedule())
+			suite.Equal(cron.CollisionPolicy_SKIP, c.GetCollisionPolicy())
+			suite.Equal(uint32(2), c.GetJobConfig().GetInstanceCount())
+			suite.Equal(
+				respoolID.GetValue(),
+				c.GetJobConfig().GetRespoolID().GetValue(),
+			)
+		}).
+		Return(&cronsvc.CreateCronJobResponse{}, nil)
+
+	resp, err := suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that ScheduleCronJob replaces the template of an existing
+// cron job.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJobReplace() {
+	config := suite.newCronJobConfiguration()
+
+	suite.respoolLoader.EXPECT().
+		Load(suite.ctx).
+		Return(fixture.PelotonResourcePoolID(), nil)
+	suite.cronClient.EXPECT().
+		CreateCronJob(suite.ctx, gomock.Any()).
+		Return(nil, yarpcerrors.AlreadyExistsErrorf("cron job exists"))
+	suite.cronClient.EXPECT().
+		ReplaceCronJob(suite.ctx, gomock.Any()).
+		Return(&cronsvc.ReplaceCronJobResponse{}, nil)
+
+	resp, err := suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+}
+
+// Ensures that ScheduleCronJob fails for invalid job configurations
+// and failures of the cron service.
+func (suite *ServiceHandlerTestSuite) TestScheduleCronJobFailure() {
+	// no cron schedule
+	config := suite.newCronJobConfiguration()
+	config.CronSchedule = nil
+	suite.respoolLoader.EXPECT().
+		Load(suite.ctx).
+		Return(fixture.PelotonResourcePoolID(), nil).
+		Times(3)
+
+	resp, err := suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+
+	// schedule rejected by the cron service
+	config = suite.newCronJobConfiguration()
+	suite.cronClient.EXPECT().
+		CreateCronJob(suite.ctx, gomock.Any()).
+		Return(nil, yarpcerrors.InvalidArgumentErrorf("invalid schedule"))
+
+	resp, err = suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+
+	// cron service unavailable
+	suite.cronClient.EXPECT().
+		CreateCronJob(suite.ctx, gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("not leader"))
+
+	resp, err = suite.handler.ScheduleCronJob(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
+}
+
+// Ensures that DescheduleCronJob deletes the cron job, and fails
+// if the job is not scheduled.
+func (suite *ServiceHandlerTestSuite) TestDescheduleCronJob() {
+	jobKey := fixture.AuroraJobKey()
+	req := &cronsvc.DeleteCronJobRequest{Name: atop.NewJobName(jobKey)}
+
+	suite.cronClient.EXPECT().
+		DeleteCronJob(suite.ctx, req).
+		Return(&cronsvc.DeleteCronJobResponse{}, nil)
+
+	resp, err := suite.handler.DescheduleCronJob(suite.ctx, jobKey)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	suite.cronClient.EXPECT().
+		DeleteCronJob(suite.ctx, req).
+		Return(nil, yarpcerrors.NotFoundErrorf("cron job not found"))
+
+	resp, err = suite.handler.DescheduleCronJob(suite.ctx, jobKey)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+
+	suite.cronClient.EXPECT().
+		DeleteCronJob(suite.ctx, req).
+		Return(nil, yarpcerrors.UnavailableErrorf("not leader"))
+
+	resp, err = suite.handler.DescheduleCronJob(suite.ctx, jobKey)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
+}
+
+// Ensures that StartCronJob triggers a run of the cron job.
+func (suite *ServiceHandlerTestSuite) TestStartCronJob() {
+	jobKey := fixture.AuroraJobKey()
+	req := &cronsvc.StartCronJobRequest{Name: atop.NewJobName(jobKey)}
+
+	suite.cronClient.EXPECT().
+		StartCronJob(suite.ctx, req).
+		Return(&cronsvc.StartCronJobResponse{}, nil)
+
+	resp, err := suite.handler.StartCronJob(suite.ctx, jobKey)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	suite.cronClient.EXPECT().
+		StartCronJob(suite.ctx, req).
+		Return(nil, yarpcerrors.NotFoundErrorf("cron job not found"))
+
+	resp, err = suite.handler.StartCronJob(suite.ctx, jobKey)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
+
+// Ensures that ReplaceCronTemplate replaces the template of the
+// cron job, and fails if the job is not scheduled.
+func (suite *ServiceHandlerTestSuite) TestReplaceCronTemplate() {
+	config := suite.newCronJobConfiguration()
+
+	suite.respoolLoader.EXPECT().
+		Load(suite.ctx).
+		Return(fixture.PelotonResourcePoolID(), nil).
+		Times(2)
+	suite.cronClient.EXPECT().
+		ReplaceCronJob(suite.ctx, gomock.Any()).
+		Return(&cronsvc.ReplaceCronJobResponse{}, nil)
+
+	resp, err := suite.handler.ReplaceCronTemplate(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+
+	suite.cronClient.EXPECT().
+		ReplaceCronJob(suite.ctx, gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf("cron job not found"))
+
+	resp, err = suite.handler.ReplaceCronTemplate(suite.ctx, config)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeInvalidRequest, resp.GetResponseCode())
+}
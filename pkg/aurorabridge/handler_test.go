@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/pborman/uuid"
+	cronmocks "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc/mocks"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	jobmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc/mocks"
@@ -62,6 +63,7 @@ type ServiceHandlerTestSuite struct {
 	jobClient      *jobmocks.MockJobServiceYARPCClient
 	listPodsStream *jobmocks.MockJobServiceServiceListPodsYARPCClient
 	podClient      *podmocks.MockPodServiceYARPCClient
+	cronClient     *cronmocks.MockCronServiceYARPCClient
 	respoolLoader  *aurorabridgemocks.MockRespoolLoader
 	random         *commonmocks.MockRandom
 
@@ -78,6 +80,7 @@ func (suite *ServiceHandlerTestSuite) SetupTest() {
 	suite.jobClient = jobmocks.NewMockJobServiceYARPCClient(suite.ctrl)
 	suite.listPodsStream = jobmocks.NewMockJobServiceServiceListPodsYARPCClient(suite.ctrl)
 	suite.podClient = podmocks.NewMockPodServiceYARPCClient(suite.ctrl)
+	suite.cronClient = cronmocks.NewMockCronServiceYARPCClient(suite.ctrl)
 	suite.respoolLoader = aurorabridgemocks.NewMockRespoolLoader(suite.ctrl)
 	suite.random = commonmocks.NewMockRandom(suite.ctrl)
 
@@ -90,6 +93,7 @@ func (suite *ServiceHandlerTestSuite) SetupTest() {
 		GetJobUpdateWorkers:           25,
 		GetTasksWithoutConfigsWorkers: 25,
 		StopPodWorkers:                25,
+		GetPendingReasonWorkers:       25,
 		PodRunsDepth:                  2,
 		ThermosExecutor: atop.ThermosExecutorConfig{
 			Path: "/usr/share/aurora/bin/thermos_executor.pex",
@@ -100,6 +104,7 @@ func (suite *ServiceHandlerTestSuite) SetupTest() {
 		tally.NoopScope,
 		suite.jobClient,
 		suite.podClient,
+		suite.cronClient,
 		suite.respoolLoader,
 		suite.random,
 	)
@@ -1192,6 +1197,93 @@ func (suite *ServiceHandlerTestSuite) TestGetTasksWithoutConfigs_ParallelismSucc
 	suite.Len(resp.GetResult().GetScheduleStatusResult().GetTasks(), 1000)
 }
 
+// TestGetPendingReason tests getting the pending reasons of the tasks
+// which are in PENDING state
+func (suite *ServiceHandlerTestSuite) TestGetPendingReason() {
+	query := fixture.AuroraTaskQuery()
+	jobKey := query.GetJobKeys()[0]
+	jobID := fixture.PelotonJobID()
+
+	suite.expectGetJobSummary(jobKey, jobID, 3)
+
+	var pods []*pod.PodInfo
+	for i, state := range []pod.PodState{
+		pod.PodState_POD_STATE_RUNNING,
+		pod.PodState_POD_STATE_PENDING,
+		pod.PodState_POD_STATE_PLACING,
+	} {
+		podName := &peloton.PodName{
+			Value: util.CreatePelotonTaskID(jobID.GetValue(), uint32(i)),
+		}
+		pods = append(pods, &pod.PodInfo{
+			Spec: &pod.PodSpec{PodName: podName},
+			Status: &pod.PodStatus{
+				PodId: &peloton.PodID{Value: podName.GetValue() + "-1"},
+				State: state,
+			},
+		})
+	}
+	suite.jobClient.EXPECT().
+		QueryPods(suite.ctx, gomock.Any()).
+		Return(&statelesssvc.QueryPodsResponse{Pods: pods}, nil)
+
+	suite.podClient.EXPECT().
+		GetPodPendingReason(gomock.Any(), &podsvc.GetPodPendingReasonRequest{
+			PodName: pods[1].GetSpec().GetPodName(),
+		}).
+		Return(&podsvc.GetPodPendingReasonResponse{
+			Reason: &pod.PendingReason{
+				Message: "waiting for admission",
+			},
+		}, nil)
+	// the pod has been placed since the pods were queried
+	suite.podClient.EXPECT().
+		GetPodPendingReason(gomock.Any(), &podsvc.GetPodPendingReasonRequest{
+			PodName: pods[2].GetSpec().GetPodName(),
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf("pod not found"))
+
+	resp, err := suite.handler.GetPendingReason(suite.ctx, query)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeOk, resp.GetResponseCode())
+	reasons := resp.GetResult().GetGetPendingReasonResult().GetReasons()
+	suite.Len(reasons, 1)
+	suite.Equal(pods[1].GetStatus().GetPodId().GetValue(), reasons[0].GetTaskId())
+	suite.Equal("waiting for admission", reasons[0].GetReason())
+}
+
+// TestGetPendingReasonFailure tests the failure to get the pending reason
+// of a task
+func (suite *ServiceHandlerTestSuite) TestGetPendingReasonFailure() {
+	query := fixture.AuroraTaskQuery()
+	jobKey := query.GetJobKeys()[0]
+	jobID := fixture.PelotonJobID()
+
+	suite.expectGetJobSummary(jobKey, jobID, 1)
+
+	podName := &peloton.PodName{
+		Value: util.CreatePelotonTaskID(jobID.GetValue(), 0),
+	}
+	suite.jobClient.EXPECT().
+		QueryPods(suite.ctx, gomock.Any()).
+		Return(&statelesssvc.QueryPodsResponse{
+			Pods: []*pod.PodInfo{{
+				Spec: &pod.PodSpec{PodName: podName},
+				Status: &pod.PodStatus{
+					PodId: &peloton.PodID{Value: podName.GetValue() + "-1"},
+					State: pod.PodState_POD_STATE_PENDING,
+				},
+			}},
+		}, nil)
+	suite.podClient.EXPECT().
+		GetPodPendingReason(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.InternalErrorf("test error"))
+
+	resp, err := suite.handler.GetPendingReason(suite.ctx, query)
+	suite.NoError(err)
+	suite.Equal(api.ResponseCodeError, resp.GetResponseCode())
+}
+
 // TestGetTasksWithoutConfigs_ParallelismFailure tests parallelism for
 // GetTasksWithoutConfig failure scenario
 func (suite *ServiceHandlerTestSuite) TestGetTasksWithoutConfigs_ParallelismFailure() {
@@ -0,0 +1,33 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import "context"
+
+type userContextKey struct{}
+
+// ContextWithUser returns a copy of the context carrying the
+// authenticated user, so that handlers and later middleware can
+// identify who made the request
+func ContextWithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+// UserFromContext returns the authenticated user carried by the
+// context, or nil if the request was not authenticated
+func UserFromContext(ctx context.Context) User {
+	user, _ := ctx.Value(userContextKey{}).(User)
+	return user
+}
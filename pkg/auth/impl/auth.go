@@ -17,6 +17,7 @@ package impl
 import (
 	"github.com/uber/peloton/pkg/auth"
 	"github.com/uber/peloton/pkg/auth/impl/basic"
+	"github.com/uber/peloton/pkg/auth/impl/jwt"
 	"github.com/uber/peloton/pkg/auth/impl/noop"
 
 	"go.uber.org/yarpc/yarpcerrors"
@@ -29,6 +30,8 @@ func CreateNewSecurityManager(config *auth.Config) (auth.SecurityManager, error)
 		return noop.NewNoopSecurityManager(), nil
 	case auth.BASIC:
 		return basic.NewBasicSecurityManager(config.Path)
+	case auth.JWT:
+		return jwt.NewJWTSecurityManager(config.Path)
 	default:
 		return nil,
 			yarpcerrors.InvalidArgumentErrorf("unknown security type provided: %s", config.AuthType)
@@ -42,6 +45,8 @@ func CreateNewSecurityClient(config *auth.Config) (auth.SecurityClient, error) {
 		return noop.NewNoopSecurityClient(), nil
 	case auth.BASIC:
 		return basic.NewBasicSecurityClient(config.Path)
+	case auth.JWT:
+		return jwt.NewJWTSecurityClient(config.Path)
 	default:
 		return nil,
 			yarpcerrors.InvalidArgumentErrorf("unknown security type provided: %s", config.AuthType)
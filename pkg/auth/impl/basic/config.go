@@ -16,7 +16,7 @@ package basic
 
 type authConfig struct {
 	Users        []*userConfig
-	Roles        []*roleConfig
+	Roles        []*RoleConfig
 	InternalUser string `yaml:"internal_user"`
 }
 
@@ -26,8 +26,20 @@ type userConfig struct {
 	Password string
 }
 
-type roleConfig struct {
-	Role   string
+// RoleConfig is the config of a role
+type RoleConfig struct {
+	// Role is the name of the role
+	Role string
+	// Accept are the rules, in the format of service:method,
+	// of the procedures the role can call
 	Accept []string
+	// Reject are the rules of the procedures the role cannot call,
+	// even if they are accepted
 	Reject []string
+	// Respools are the paths of the resource pools, including their
+	// descendants, the role can act on. All resource pools if empty.
+	Respools []string
+	// Owners are the owners or owning teams of the resources
+	// the role can act on. All owners if empty.
+	Owners []string
 }
@@ -27,8 +27,9 @@ import (
 const (
 	_ruleSeparator = ":"
 	// rule that matches all methods under all services
-	_matchAllRule       = "*"
-	_procedureSeparator = "::"
+	_matchAllRule         = "*"
+	_procedureSeparator   = "::"
+	_respoolPathSeparator = "/"
 
 	// expected fields passed by token
 	_usernameHeaderKey = "username"
@@ -59,9 +60,28 @@ type role struct {
 	accepts map[string][]string
 	// service -> methods
 	rejects map[string][]string
+	// resource pool paths the role is restricted to
+	respools []string
+	// owners or owning teams the role is restricted to
+	owners []string
+}
+
+// Role includes the authorization methods of a role. A user having
+// the role is permitted the same procedures and resources.
+type Role interface {
+	// IsPermitted returns whether role can
+	// access the specified procedure
+	IsPermitted(procedure string) bool
+	// IsResourceScoped returns whether role is restricted
+	// to act on a subset of resources
+	IsResourceScoped() bool
+	// IsPermittedOnResource returns whether role can
+	// access the specified procedure on the resource
+	IsPermittedOnResource(procedure string, resource *auth.Resource) bool
 }
 
 var _ auth.SecurityManager = &SecurityManager{}
+var _ Role = &role{}
 
 // Authenticate authenticates a user,
 // it expects to Accept UsernamePasswordToken
@@ -99,22 +119,101 @@ func (m *SecurityManager) Authenticate(token auth.Token) (auth.User, error) {
 	return user, nil
 }
 
+// GetUsername returns the name of user
+func (u *user) GetUsername() string {
+	return u.username
+}
+
 // IsPermitted returns if a procedure is permitted for user
 func (u *user) IsPermitted(procedure string) bool {
+	return u.role.IsPermitted(procedure)
+}
+
+// IsResourceScoped returns if the role of user is restricted
+// to a subset of resource pools or owners
+func (u *user) IsResourceScoped() bool {
+	return u.role.IsResourceScoped()
+}
+
+// IsPermittedOnResource returns if a procedure is permitted for user
+// on the resource
+func (u *user) IsPermittedOnResource(
+	procedure string,
+	resource *auth.Resource,
+) bool {
+	return u.role.IsPermittedOnResource(procedure, resource)
+}
+
+// IsPermitted returns if a procedure is permitted for role
+func (r *role) IsPermitted(procedure string) bool {
 	// procedure is permitted if it is accepted by
 	// the role and is not rejected
 	results := strings.Split(procedure, _procedureSeparator)
 	service := results[0]
 	method := results[1]
 
-	if matchRules(service, method, u.role.accepts) &&
-		!matchRules(service, method, u.role.rejects) {
+	if matchRules(service, method, r.accepts) &&
+		!matchRules(service, method, r.rejects) {
 		return true
 	}
 
 	return false
 }
 
+// IsResourceScoped returns if the role is restricted
+// to a subset of resource pools or owners
+func (r *role) IsResourceScoped() bool {
+	return len(r.respools) != 0 || len(r.owners) != 0
+}
+
+// IsPermittedOnResource returns if a procedure is permitted for role
+// on the resource
+func (r *role) IsPermittedOnResource(
+	procedure string,
+	resource *auth.Resource,
+) bool {
+	if !r.IsPermitted(procedure) {
+		return false
+	}
+
+	// procedure does not target a specific resource
+	if resource == nil {
+		return true
+	}
+
+	return matchRespools(resource.RespoolPath, r.respools) &&
+		matchOwners(resource, r.owners)
+}
+
+func matchRespools(path string, respools []string) bool {
+	if len(respools) == 0 {
+		return true
+	}
+
+	for _, respool := range respools {
+		// a respool matches itself and all of its descendants
+		prefix := strings.TrimSuffix(respool, _respoolPathSeparator) +
+			_respoolPathSeparator
+		if path == respool || strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchOwners(resource *auth.Resource, owners []string) bool {
+	if len(owners) == 0 {
+		return true
+	}
+
+	for _, owner := range owners {
+		if owner == resource.Owner || owner == resource.OwningTeam {
+			return true
+		}
+	}
+	return false
+}
+
 func matchRules(service, method string, rules map[string][]string) bool {
 	// _matchAllRule is set, all services and methods are matched
 	if _, ok := rules[_matchAllRule]; ok {
@@ -146,6 +245,21 @@ func matchRule(method string, rule string) bool {
 	return rule == method
 }
 
+// NewRoles validates the role configs and returns the roles keyed by
+// name, so that other security managers can authorize their users by
+// role.
+func NewRoles(configs []*RoleConfig) (map[string]Role, error) {
+	if _, err := validateRoles(configs); err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]Role)
+	for name, role := range constructRoles(configs) {
+		result[name] = role
+	}
+	return result, nil
+}
+
 // NewBasicSecurityManager returns SecurityManager
 func NewBasicSecurityManager(configPath string) (*SecurityManager, error) {
 	mConfig, err := parseConfig(configPath)
@@ -162,7 +276,7 @@ func newBasicSecurityManager(mConfig *authConfig) (*SecurityManager, error) {
 		return nil, err
 	}
 
-	defaultUser, users, err := constructUsers(mConfig, constructRoles(mConfig))
+	defaultUser, users, err := constructUsers(mConfig, constructRoles(mConfig.Roles))
 	if err != nil {
 		return nil, err
 	}
@@ -182,27 +296,9 @@ func parseConfig(configPath string) (*authConfig, error) {
 }
 
 func validateConfig(config *authConfig) error {
-	roleConfigs := make(map[string]*roleConfig)
-	// check if rules are valid
-	for _, roleConfig := range config.Roles {
-		for _, acceptRule := range roleConfig.Accept {
-			if err := validateRule(acceptRule); err != nil {
-				return err
-			}
-		}
-		for _, rejectRule := range roleConfig.Reject {
-			if err := validateRule(rejectRule); err != nil {
-				return err
-			}
-		}
-
-		if _, ok := roleConfigs[roleConfig.Role]; ok {
-			return yarpcerrors.InvalidArgumentErrorf(
-				"same Role defined more than once. Role:%s",
-				roleConfig.Role,
-			)
-		}
-		roleConfigs[roleConfig.Role] = roleConfig
+	roleConfigs, err := validateRoles(config.Roles)
+	if err != nil {
+		return err
 	}
 
 	var defaultUserCount int
@@ -260,13 +356,59 @@ func validateConfig(config *authConfig) error {
 
 	if !isRootRole(internalUserRoleConfig) {
 		return yarpcerrors.InvalidArgumentErrorf(
-			"role for internal user must accept * and reject no method")
+			"role for internal user must accept * and reject no method, " +
+				"and must not be restricted to respools or owners")
 	}
 
 	return nil
 }
 
-func isRootRole(config *roleConfig) bool {
+// validateRoles checks if the role configs are valid,
+// and returns them keyed by role name
+func validateRoles(configs []*RoleConfig) (map[string]*RoleConfig, error) {
+	roleConfigs := make(map[string]*RoleConfig)
+	// check if rules are valid
+	for _, roleConfig := range configs {
+		for _, acceptRule := range roleConfig.Accept {
+			if err := validateRule(acceptRule); err != nil {
+				return nil, err
+			}
+		}
+		for _, rejectRule := range roleConfig.Reject {
+			if err := validateRule(rejectRule); err != nil {
+				return nil, err
+			}
+		}
+		for _, respool := range roleConfig.Respools {
+			if !strings.HasPrefix(respool, _respoolPathSeparator) {
+				return nil, yarpcerrors.InvalidArgumentErrorf(
+					"respool: %s is not an absolute path",
+					respool,
+				)
+			}
+		}
+		for _, owner := range roleConfig.Owners {
+			if len(owner) == 0 {
+				return nil, yarpcerrors.InvalidArgumentErrorf(
+					"empty owner specified for Role:%s",
+					roleConfig.Role,
+				)
+			}
+		}
+
+		if _, ok := roleConfigs[roleConfig.Role]; ok {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"same Role defined more than once. Role:%s",
+				roleConfig.Role,
+			)
+		}
+		roleConfigs[roleConfig.Role] = roleConfig
+	}
+
+	return roleConfigs, nil
+}
+
+func isRootRole(config *RoleConfig) bool {
 	if !(len(config.Accept) == 1 && config.Accept[0] == _matchAllRule) {
 		return false
 	}
@@ -275,6 +417,10 @@ func isRootRole(config *roleConfig) bool {
 		return false
 	}
 
+	if len(config.Respools) != 0 || len(config.Owners) != 0 {
+		return false
+	}
+
 	return true
 }
 
@@ -342,9 +488,9 @@ func isValidMethod(method string) bool {
 	return true
 }
 
-func constructRoles(mConfig *authConfig) map[string]*role {
+func constructRoles(configs []*RoleConfig) map[string]*role {
 	result := make(map[string]*role)
-	for _, roleConfig := range mConfig.Roles {
+	for _, roleConfig := range configs {
 		accepts := make(map[string][]string)
 		rejects := make(map[string][]string)
 
@@ -368,9 +514,11 @@ func constructRoles(mConfig *authConfig) map[string]*role {
 		}
 
 		result[roleConfig.Role] = &role{
-			role:    roleConfig.Role,
-			accepts: accepts,
-			rejects: rejects,
+			role:     roleConfig.Role,
+			accepts:  accepts,
+			rejects:  rejects,
+			respools: roleConfig.Respools,
+			owners:   roleConfig.Owners,
 		}
 	}
 
@@ -17,6 +17,8 @@ package basic
 import (
 	"testing"
 
+	"github.com/uber/peloton/pkg/auth"
+
 	"github.com/stretchr/testify/suite"
 )
 
@@ -35,11 +37,11 @@ func (suite *SecurityManagerTestSuite) SetupTest() {
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerSuccess() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role:   "admin",
 		Accept: []string{_matchAllRule},
 	}
-	role2 := &roleConfig{
+	role2 := &RoleConfig{
 		Role: "default",
 	}
 
@@ -59,7 +61,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerSuccess() {
 
 	config := &authConfig{
 		Users:        []*userConfig{user1, user2, user3},
-		Roles:        []*roleConfig{role1, role2},
+		Roles:        []*RoleConfig{role1, role2},
 		InternalUser: user1.Username,
 	}
 
@@ -69,7 +71,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerSuccess() {
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMultiDefaultUserErr() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role: "default",
 	}
 
@@ -82,7 +84,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMultiDefaul
 
 	config := &authConfig{
 		Users: []*userConfig{user1, user2},
-		Roles: []*roleConfig{role1},
+		Roles: []*RoleConfig{role1},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -91,10 +93,10 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMultiDefaul
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedRolesErr() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role: "admin",
 	}
-	role2 := &roleConfig{
+	role2 := &RoleConfig{
 		Role: "admin",
 	}
 
@@ -109,7 +111,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedR
 
 	config := &authConfig{
 		Users: []*userConfig{user1, user2},
-		Roles: []*roleConfig{role1, role2},
+		Roles: []*RoleConfig{role1, role2},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -118,7 +120,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedR
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedUsersErr() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role: "admin",
 	}
 
@@ -135,7 +137,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedU
 
 	config := &authConfig{
 		Users: []*userConfig{user1, user2},
-		Roles: []*roleConfig{role1},
+		Roles: []*RoleConfig{role1},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -144,7 +146,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerDuplicatedU
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerUndefinedRoleErr() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role: "admin",
 	}
 
@@ -161,7 +163,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerUndefinedRo
 
 	config := &authConfig{
 		Users: []*userConfig{user1, user2},
-		Roles: []*roleConfig{role1},
+		Roles: []*RoleConfig{role1},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -170,7 +172,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerUndefinedRo
 }
 
 func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMissingUserInfoErr() {
-	role := &roleConfig{
+	role := &RoleConfig{
 		Role: "admin",
 	}
 
@@ -181,7 +183,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMissingUser
 	}
 	config := &authConfig{
 		Users: []*userConfig{user},
-		Roles: []*roleConfig{role},
+		Roles: []*RoleConfig{role},
 	}
 
 	m, err := newBasicSecurityManager(config)
@@ -195,7 +197,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMissingUser
 	}
 	config = &authConfig{
 		Users: []*userConfig{user},
-		Roles: []*roleConfig{role},
+		Roles: []*RoleConfig{role},
 	}
 
 	m, err = newBasicSecurityManager(config)
@@ -209,7 +211,7 @@ func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerMissingUser
 	}
 	config = &authConfig{
 		Users: []*userConfig{user},
-		Roles: []*roleConfig{role},
+		Roles: []*RoleConfig{role},
 	}
 
 	m, err = newBasicSecurityManager(config)
@@ -259,12 +261,13 @@ func (suite *SecurityManagerTestSuite) TestAuthenticateUser() {
 		} else {
 			suite.NotNil(u)
 			suite.NoError(err)
+			suite.Equal(test.username, u.GetUsername())
 		}
 	}
 }
 
 func (suite *SecurityManagerTestSuite) TestAuthenticateDefaultUserWhenNonDefinedErr() {
-	role1 := &roleConfig{
+	role1 := &RoleConfig{
 		Role:   "admin",
 		Accept: []string{_matchAllRule},
 	}
@@ -282,7 +285,7 @@ func (suite *SecurityManagerTestSuite) TestAuthenticateDefaultUserWhenNonDefined
 
 	config := &authConfig{
 		Users:        []*userConfig{user1, user2},
-		Roles:        []*roleConfig{role1},
+		Roles:        []*RoleConfig{role1},
 		InternalUser: user1.Username,
 	}
 
@@ -346,6 +349,147 @@ func (suite *SecurityManagerTestSuite) TestNormalUserPermission() {
 	}
 }
 
+func (suite *SecurityManagerTestSuite) TestResourceScopedUserPermission() {
+	tests := []struct {
+		procedureName string
+		resource      *auth.Resource
+		isPermitted   bool
+	}{
+		{
+			procedureName: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			resource:      &auth.Resource{RespoolPath: "/team1", Owner: "user3"},
+			isPermitted:   true,
+		},
+		{
+			procedureName: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			resource:      &auth.Resource{RespoolPath: "/team1/child", OwningTeam: "team1"},
+			isPermitted:   true,
+		},
+		{
+			procedureName: "peloton.api.v1alpha.job.stateless.svc.JobService::ListJobs",
+			resource:      nil,
+			isPermitted:   true,
+		},
+		{
+			procedureName: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			resource:      &auth.Resource{RespoolPath: "/team2", Owner: "user3"},
+			isPermitted:   false,
+		},
+		{
+			procedureName: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			resource:      &auth.Resource{RespoolPath: "/team10", Owner: "user3"},
+			isPermitted:   false,
+		},
+		{
+			procedureName: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			resource:      &auth.Resource{RespoolPath: "/team1", Owner: "user4", OwningTeam: "team2"},
+			isPermitted:   false,
+		},
+		{
+			procedureName: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+			resource:      &auth.Resource{},
+			isPermitted:   false,
+		},
+		{
+			procedureName: "peloton.api.v1alpha.pod.svc.PodService::StopPod",
+			resource:      &auth.Resource{RespoolPath: "/team1", Owner: "user3"},
+			isPermitted:   false,
+		},
+	}
+
+	u, err := suite.m.Authenticate(
+		&testToken{username: "user3", password: "password3"},
+	)
+	suite.NoError(err)
+	suite.True(u.IsResourceScoped())
+
+	for _, test := range tests {
+		suite.Equal(
+			test.isPermitted,
+			u.IsPermittedOnResource(test.procedureName, test.resource),
+			test.procedureName,
+		)
+	}
+
+	// users without resource restriction are permitted on all resources
+	u, err = suite.m.Authenticate(
+		&testToken{username: "user2", password: "password2"},
+	)
+	suite.NoError(err)
+	suite.False(u.IsResourceScoped())
+	suite.True(u.IsPermittedOnResource(
+		"peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+		&auth.Resource{RespoolPath: "/team2", Owner: "user4"},
+	))
+}
+
+func (suite *SecurityManagerTestSuite) TestCreateBasicSecurityManagerInvalidResourceErr() {
+	root := &RoleConfig{
+		Role:   "root",
+		Accept: []string{_matchAllRule},
+	}
+	internalUser := &userConfig{
+		Role:     root.Role,
+		Username: "internal",
+		Password: "password",
+	}
+
+	tests := []*RoleConfig{
+		{Role: "role", Respools: []string{"team1"}},
+		{Role: "role", Owners: []string{""}},
+	}
+	for _, test := range tests {
+		m, err := newBasicSecurityManager(&authConfig{
+			Users:        []*userConfig{internalUser},
+			Roles:        []*RoleConfig{root, test},
+			InternalUser: internalUser.Username,
+		})
+		suite.Nil(m)
+		suite.Error(err)
+	}
+
+	// internal user cannot be restricted to resources
+	root.Respools = []string{"/"}
+	m, err := newBasicSecurityManager(&authConfig{
+		Users:        []*userConfig{internalUser},
+		Roles:        []*RoleConfig{root},
+		InternalUser: internalUser.Username,
+	})
+	suite.Nil(m)
+	suite.Error(err)
+}
+
+func (suite *SecurityManagerTestSuite) TestNewRoles() {
+	roles, err := NewRoles([]*RoleConfig{
+		{
+			Role:   "reader",
+			Accept: []string{"peloton.api.v1alpha.job.stateless.svc.JobService:Get*"},
+		},
+		{
+			Role:     "team1",
+			Accept:   []string{_matchAllRule},
+			Respools: []string{"/team1"},
+		},
+	})
+	suite.NoError(err)
+	suite.Len(roles, 2)
+
+	suite.True(roles["reader"].IsPermitted(
+		"peloton.api.v1alpha.job.stateless.svc.JobService::GetJob"))
+	suite.False(roles["reader"].IsPermitted(
+		"peloton.api.v1alpha.job.stateless.svc.JobService::StopJob"))
+	suite.False(roles["reader"].IsResourceScoped())
+
+	suite.True(roles["team1"].IsResourceScoped())
+	suite.False(roles["team1"].IsPermittedOnResource(
+		"peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+		&auth.Resource{RespoolPath: "/team2"},
+	))
+
+	_, err = NewRoles([]*RoleConfig{{Role: "role", Accept: []string{"invalid"}}})
+	suite.Error(err)
+}
+
 func (suite *SecurityManagerTestSuite) TestValidateRule() {
 	tests := []struct {
 		rule      string
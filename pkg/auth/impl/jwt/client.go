@@ -0,0 +1,126 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// SecurityClient returns token which authenticates internal
+// communication when jwt auth is enabled. The token is read
+// from a file, which is reloaded before the token expires.
+type SecurityClient struct {
+	sync.Mutex
+
+	path          string
+	refreshBefore time.Duration
+
+	token  *jwtToken
+	expiry time.Time
+
+	// now returns the current time, overridden in tests
+	now func() time.Time
+}
+
+// GetToken returns a token for jwt auth
+func (c *SecurityClient) GetToken() auth.Token {
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.expiry.IsZero() && !c.now().Add(c.refreshBefore).Before(c.expiry) {
+		// keep using the current token if the file cannot be
+		// reloaded, the server rejects it once it is expired
+		if err := c.load(); err != nil {
+			log.WithError(err).
+				WithField("path", c.path).
+				Warn("failed to reload internal token")
+		}
+	}
+
+	return c.token
+}
+
+// load reads the token from file
+func (c *SecurityClient) load() error {
+	data, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+
+	raw := strings.TrimSpace(string(data))
+	t, err := parseToken(raw)
+	if err != nil {
+		return errors.Wrap(err, "invalid internal token")
+	}
+
+	c.token = &jwtToken{
+		items: map[string]string{
+			_authorizationHeaderKey: _bearerPrefix + raw,
+		},
+	}
+	c.expiry = t.expiry()
+	return nil
+}
+
+type jwtToken struct {
+	items map[string]string
+}
+
+func (t *jwtToken) Get(k string) (string, bool) {
+	result, ok := t.items[k]
+	return result, ok
+}
+
+func (t *jwtToken) Items() map[string]string {
+	return t.items
+}
+
+// NewJWTSecurityClient returns SecurityClient
+func NewJWTSecurityClient(configPath string) (*SecurityClient, error) {
+	cConfig, err := parseConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return newJWTSecurityClient(cConfig)
+}
+
+// helper method to create SecurityClient which makes test easier
+func newJWTSecurityClient(cConfig *authConfig) (*SecurityClient, error) {
+	cConfig.normalize()
+
+	if len(cConfig.InternalTokenFile) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf("no internal token file specified")
+	}
+
+	c := &SecurityClient{
+		path:          cConfig.InternalTokenFile,
+		refreshBefore: cConfig.InternalTokenRefreshBefore,
+		now:           time.Now,
+	}
+	if err := c.load(); err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"failed to load internal token: %v", err)
+	}
+	return c, nil
+}
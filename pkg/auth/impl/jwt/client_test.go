@@ -0,0 +1,125 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type SecurityClientTestSuite struct {
+	suite.Suite
+
+	dir       string
+	tokenFile string
+	key       *ecdsa.PrivateKey
+	now       time.Time
+}
+
+func (suite *SecurityClientTestSuite) SetupTest() {
+	var err error
+	suite.dir, err = ioutil.TempDir("", "jwt")
+	suite.NoError(err)
+	suite.tokenFile = filepath.Join(suite.dir, "token")
+
+	suite.key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.NoError(err)
+	suite.now = time.Now()
+}
+
+func (suite *SecurityClientTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func TestSecurityClientTestSuite(t *testing.T) {
+	suite.Run(t, new(SecurityClientTestSuite))
+}
+
+// writeToken writes a token expiring at exp to the token file
+func (suite *SecurityClientTestSuite) writeToken(exp time.Time) string {
+	raw := signToken(suite.T(), "ES256", "", suite.key, map[string]interface{}{
+		"sub": "peloton",
+		"exp": exp.Unix(),
+	})
+	// trailing new line is ignored
+	suite.NoError(ioutil.WriteFile(suite.tokenFile, []byte(raw+"\n"), 0600))
+	return raw
+}
+
+func (suite *SecurityClientTestSuite) newClient() *SecurityClient {
+	c, err := newJWTSecurityClient(&authConfig{
+		InternalTokenFile: suite.tokenFile,
+	})
+	suite.NoError(err)
+	c.now = func() time.Time { return suite.now }
+	return c
+}
+
+func (suite *SecurityClientTestSuite) TestJWTSecurityClientGetToken() {
+	raw := suite.writeToken(suite.now.Add(time.Hour))
+	c := suite.newClient()
+
+	t := c.GetToken()
+	authorization, ok := t.Get(_authorizationHeaderKey)
+	suite.True(ok)
+	suite.Equal(_bearerPrefix+raw, authorization)
+	suite.Len(t.Items(), 1)
+}
+
+func (suite *SecurityClientTestSuite) TestJWTSecurityClientRefreshToken() {
+	raw := suite.writeToken(suite.now.Add(time.Hour))
+	c := suite.newClient()
+
+	// token is not reloaded before it is about to expire
+	newRaw := suite.writeToken(suite.now.Add(2 * time.Hour))
+	authorization, _ := c.GetToken().Get(_authorizationHeaderKey)
+	suite.Equal(_bearerPrefix+raw, authorization)
+
+	suite.now = suite.now.Add(time.Hour - _defaultRefreshBefore)
+	authorization, _ = c.GetToken().Get(_authorizationHeaderKey)
+	suite.Equal(_bearerPrefix+newRaw, authorization)
+
+	// current token is kept if the file cannot be reloaded
+	suite.now = suite.now.Add(2 * time.Hour)
+	suite.NoError(os.Remove(suite.tokenFile))
+	authorization, _ = c.GetToken().Get(_authorizationHeaderKey)
+	suite.Equal(_bearerPrefix+newRaw, authorization)
+}
+
+func (suite *SecurityClientTestSuite) TestCreateJWTSecurityClientFailure() {
+	// no token file configured
+	c, err := newJWTSecurityClient(&authConfig{})
+	suite.Nil(c)
+	suite.Error(err)
+
+	// token file does not exist
+	c, err = newJWTSecurityClient(&authConfig{InternalTokenFile: suite.tokenFile})
+	suite.Nil(c)
+	suite.Error(err)
+
+	// token file does not contain a token
+	suite.NoError(ioutil.WriteFile(suite.tokenFile, []byte("token"), 0600))
+	c, err = newJWTSecurityClient(&authConfig{InternalTokenFile: suite.tokenFile})
+	suite.Nil(c)
+	suite.Error(err)
+}
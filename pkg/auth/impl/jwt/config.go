@@ -0,0 +1,94 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"time"
+
+	"github.com/uber/peloton/pkg/auth/impl/basic"
+)
+
+const (
+	_defaultUsernameClaim = "sub"
+	_defaultRoleClaim     = "roles"
+	_defaultClockSkew     = time.Minute
+	_defaultRefreshBefore = 5 * time.Minute
+
+	_defaultJWKSRefreshInterval = time.Minute
+)
+
+type authConfig struct {
+	// PublicKeys are the paths to the PEM encoded public keys
+	// or certificates used to verify the signature of tokens
+	PublicKeys []string `yaml:"public_keys"`
+	// JWKSFile is the path to a JSON Web Key Set used to
+	// verify the signature of tokens. The file is reloaded
+	// when it changes, so that the keys can be rotated.
+	JWKSFile string `yaml:"jwks_file"`
+	// JWKSRefreshInterval is how often the JWKS file is
+	// checked for changes
+	JWKSRefreshInterval time.Duration `yaml:"jwks_refresh_interval"`
+
+	// Issuer is the expected iss claim, not checked if empty
+	Issuer string `yaml:"issuer"`
+	// Audience is the expected aud claim, not checked if empty
+	Audience string `yaml:"audience"`
+	// ClockSkew is the clock skew allowed when checking
+	// the exp and nbf claims
+	ClockSkew time.Duration `yaml:"clock_skew"`
+
+	// UsernameClaim is the claim containing the username
+	UsernameClaim string `yaml:"username_claim"`
+	// RoleClaim is the claim containing the roles or groups of
+	// the user, either a string or a list of strings
+	RoleClaim string `yaml:"role_claim"`
+	// RoleMappings maps the values of the role claim to roles.
+	// A value which is not mapped is used as the role name.
+	RoleMappings map[string]string `yaml:"role_mappings"`
+	// DefaultRole is the role of requests without a token,
+	// such requests are rejected if it is empty
+	DefaultRole string `yaml:"default_role"`
+	// Roles are the roles users can be mapped to, in the
+	// same format as basic auth
+	Roles []*basic.RoleConfig
+
+	// InternalTokenFile is the path to the token used for
+	// inter-component communication. The token must map to a
+	// role which accepts all procedures. The file is reloaded
+	// before the token expires, so it can be rotated.
+	InternalTokenFile string `yaml:"internal_token_file"`
+	// InternalTokenRefreshBefore is how long before the internal
+	// token expires the file is reloaded
+	InternalTokenRefreshBefore time.Duration `yaml:"internal_token_refresh_before"`
+}
+
+// normalize sets the default values of unset fields
+func (c *authConfig) normalize() {
+	if len(c.UsernameClaim) == 0 {
+		c.UsernameClaim = _defaultUsernameClaim
+	}
+	if len(c.RoleClaim) == 0 {
+		c.RoleClaim = _defaultRoleClaim
+	}
+	if c.ClockSkew == 0 {
+		c.ClockSkew = _defaultClockSkew
+	}
+	if c.JWKSRefreshInterval == 0 {
+		c.JWKSRefreshInterval = _defaultJWKSRefreshInterval
+	}
+	if c.InternalTokenRefreshBefore == 0 {
+		c.InternalTokenRefreshBefore = _defaultRefreshBefore
+	}
+}
@@ -0,0 +1,207 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// keySet is the set of public keys used to verify tokens
+type keySet struct {
+	// keys identified by key id, from the JWKS file
+	byID map[string]crypto.PublicKey
+	// all keys, which are tried in order if
+	// a token does not specify a known key id
+	all []crypto.PublicKey
+}
+
+// jwks is a JSON Web Key Set as defined in RFC 7517
+type jwks struct {
+	Keys []*jwk `json:"keys"`
+}
+
+// jwk is a JSON Web Key as defined in RFC 7517,
+// only RSA and EC public keys are supported
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	// RSA public key
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC public key
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// loadKeySet loads the public keys from PEM files and a JWKS file
+func loadKeySet(pemFiles []string, jwksFile string) (*keySet, error) {
+	keys := &keySet{byID: make(map[string]crypto.PublicKey)}
+
+	for _, path := range pemFiles {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read public key %s", path)
+		}
+		key, err := parsePEMPublicKey(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse public key %s", path)
+		}
+		keys.all = append(keys.all, key)
+	}
+
+	if len(jwksFile) != 0 {
+		data, err := ioutil.ReadFile(jwksFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read JWKS %s", jwksFile)
+		}
+		if err := keys.addJWKS(data); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse JWKS %s", jwksFile)
+		}
+	}
+
+	if len(keys.all) == 0 {
+		return nil, errors.New("no public key configured")
+	}
+	return keys, nil
+}
+
+// addJWKS adds the signature keys of a JWKS to the key set
+func (s *keySet) addJWKS(data []byte) error {
+	var set jwks
+	if err := json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+
+	for _, k := range set.Keys {
+		// skip keys which are not used for signatures
+		if len(k.Use) != 0 && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.publicKey()
+		if err != nil {
+			return errors.Wrapf(err, "invalid key %s", k.Kid)
+		}
+
+		if len(k.Kid) != 0 {
+			s.byID[k.Kid] = key
+		}
+		s.all = append(s.all, key)
+	}
+	return nil
+}
+
+// candidates returns the keys to try for a token signed with key id
+func (s *keySet) candidates(kid string) []crypto.PublicKey {
+	if key, ok := s.byID[kid]; ok {
+		return []crypto.PublicKey{key}
+	}
+	return s.all
+}
+
+// publicKey converts the JWK into a public key
+func (k *jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, errors.Errorf("unsupported key type %s", k.Kty)
+}
+
+// parsePEMPublicKey parses a PEM encoded public key or certificate
+func parsePEMPublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	var key crypto.PublicKey
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key = cert.PublicKey
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		var err error
+		if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+			return nil, err
+		}
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return key, nil
+	}
+	return nil, errors.Errorf("unsupported public key type %T", key)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,287 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/auth/impl/basic"
+	"github.com/uber/peloton/pkg/common/config"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// expected field passed by token, in the format
+	// of "Bearer <token>"
+	_authorizationHeaderKey = "authorization"
+	_bearerPrefix           = "Bearer "
+)
+
+// SecurityManager authenticates users with signed JSON Web Tokens,
+// and authorizes them by the roles mapped from the token claims
+type SecurityManager struct {
+	config *authConfig
+
+	// keysLock protects the keys, which are reloaded
+	// when the JWKS file changes
+	keysLock sync.RWMutex
+	keys     *keySet
+	// jwksData is the content of the JWKS file the keys were loaded from
+	jwksData []byte
+	// jwksCheckTime is when the JWKS file was last checked for changes
+	jwksCheckTime time.Time
+
+	roles       map[string]basic.Role
+	defaultUser *user
+
+	// now returns the current time, overridden in tests
+	now func() time.Time
+}
+
+// all fields are immutable after init,
+// need lock protection if the assumption breaks
+type user struct {
+	username string
+	// user is permitted what any of its roles is permitted
+	roles []basic.Role
+}
+
+var _ auth.SecurityManager = &SecurityManager{}
+
+// Authenticate authenticates a user,
+// it expects a bearer token in the authorization field
+func (m *SecurityManager) Authenticate(token auth.Token) (auth.User, error) {
+	authorization, _ := token.Get(_authorizationHeaderKey)
+
+	// no token provided, return default user
+	if len(authorization) == 0 {
+		if m.defaultUser == nil {
+			return nil, yarpcerrors.UnauthenticatedErrorf("no token provided")
+		}
+		return m.defaultUser, nil
+	}
+
+	if len(authorization) <= len(_bearerPrefix) ||
+		!strings.EqualFold(authorization[:len(_bearerPrefix)], _bearerPrefix) {
+		return nil, yarpcerrors.UnauthenticatedErrorf("expect bearer token")
+	}
+
+	t, err := m.verify(authorization[len(_bearerPrefix):])
+	if err != nil {
+		log.WithError(err).Debug("failed to verify token")
+		return nil, yarpcerrors.UnauthenticatedErrorf("invalid token")
+	}
+
+	u := &user{username: t.claims.string(m.config.UsernameClaim)}
+	if len(u.username) == 0 {
+		return nil, yarpcerrors.UnauthenticatedErrorf(
+			"no %s claim in token", m.config.UsernameClaim)
+	}
+
+	for _, value := range t.claims.strings(m.config.RoleClaim) {
+		name, ok := m.config.RoleMappings[value]
+		if !ok {
+			name = value
+		}
+		if role, ok := m.roles[name]; ok {
+			u.roles = append(u.roles, role)
+		}
+	}
+
+	return u, nil
+}
+
+// verify parses the token and checks its signature and claims
+func (m *SecurityManager) verify(raw string) (*token, error) {
+	t, err := parseToken(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.verify(m.getKeys()); err != nil {
+		return nil, err
+	}
+
+	if err := t.validate(m.config, m.now()); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// getKeys returns the keys to verify tokens with. The keys are
+// reloaded if the JWKS file changed since it was last checked.
+// The current keys are kept if the file cannot be reloaded.
+func (m *SecurityManager) getKeys() *keySet {
+	now := m.now()
+
+	m.keysLock.RLock()
+	keys, checkTime := m.keys, m.jwksCheckTime
+	m.keysLock.RUnlock()
+	if len(m.config.JWKSFile) == 0 ||
+		now.Sub(checkTime) < m.config.JWKSRefreshInterval {
+		return keys
+	}
+
+	m.keysLock.Lock()
+	defer m.keysLock.Unlock()
+
+	// the file may have been checked by another request
+	if now.Sub(m.jwksCheckTime) < m.config.JWKSRefreshInterval {
+		return m.keys
+	}
+	m.jwksCheckTime = now
+	if err := m.reloadKeys(); err != nil {
+		log.WithError(err).
+			WithField("path", m.config.JWKSFile).
+			Warn("failed to reload JWKS")
+	}
+	return m.keys
+}
+
+// reloadKeys loads the keys again if the content of the JWKS file
+// changed, it must be called with keysLock held
+func (m *SecurityManager) reloadKeys() error {
+	data, err := ioutil.ReadFile(m.config.JWKSFile)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, m.jwksData) {
+		return nil
+	}
+
+	keys, err := loadKeySet(m.config.PublicKeys, m.config.JWKSFile)
+	if err != nil {
+		return err
+	}
+	m.keys = keys
+	m.jwksData = data
+	log.WithField("path", m.config.JWKSFile).Info("JWKS reloaded")
+	return nil
+}
+
+// GetUsername returns the name of user
+func (u *user) GetUsername() string {
+	return u.username
+}
+
+// IsPermitted returns if a procedure is permitted for user
+func (u *user) IsPermitted(procedure string) bool {
+	for _, role := range u.roles {
+		if role.IsPermitted(procedure) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsResourceScoped returns if any role of user is restricted
+// to a subset of resource pools or owners
+func (u *user) IsResourceScoped() bool {
+	for _, role := range u.roles {
+		if role.IsResourceScoped() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPermittedOnResource returns if a procedure is permitted for user
+// on the resource
+func (u *user) IsPermittedOnResource(
+	procedure string,
+	resource *auth.Resource,
+) bool {
+	for _, role := range u.roles {
+		if role.IsPermittedOnResource(procedure, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewJWTSecurityManager returns SecurityManager
+func NewJWTSecurityManager(configPath string) (*SecurityManager, error) {
+	mConfig, err := parseConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return newJWTSecurityManager(mConfig)
+}
+
+// helper method to create SecurityManager which makes test easier
+func newJWTSecurityManager(mConfig *authConfig) (*SecurityManager, error) {
+	mConfig.normalize()
+
+	keys, err := loadKeySet(mConfig.PublicKeys, mConfig.JWKSFile)
+	if err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf("%v", err)
+	}
+
+	var jwksData []byte
+	if len(mConfig.JWKSFile) != 0 {
+		if jwksData, err = ioutil.ReadFile(mConfig.JWKSFile); err != nil {
+			return nil, yarpcerrors.InvalidArgumentErrorf("%v", err)
+		}
+	}
+
+	roles, err := basic.NewRoles(mConfig.Roles)
+	if err != nil {
+		return nil, err
+	}
+
+	for value, name := range mConfig.RoleMappings {
+		if _, ok := roles[name]; !ok {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"%s is mapped to undefined Role: %s", value, name)
+		}
+	}
+
+	var defaultUser *user
+	if len(mConfig.DefaultRole) != 0 {
+		role, ok := roles[mConfig.DefaultRole]
+		if !ok {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"undefined default Role: %s", mConfig.DefaultRole)
+		}
+		defaultUser = &user{roles: []basic.Role{role}}
+	}
+
+	return &SecurityManager{
+		config:        mConfig,
+		keys:          keys,
+		jwksData:      jwksData,
+		jwksCheckTime: time.Now(),
+		roles:         roles,
+		defaultUser:   defaultUser,
+		now:           time.Now,
+	}, nil
+}
+
+func parseConfig(configPath string) (*authConfig, error) {
+	mConfig := &authConfig{}
+	if err := config.Parse(mConfig, configPath); err != nil {
+		return nil, err
+	}
+	return mConfig, nil
+}
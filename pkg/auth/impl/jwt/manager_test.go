@@ -0,0 +1,383 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/auth/impl/basic"
+
+	"github.com/stretchr/testify/suite"
+)
+
+const (
+	_stopJob = "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob"
+	_getJob  = "peloton.api.v1alpha.job.stateless.svc.JobService::GetJob"
+)
+
+type SecurityManagerTestSuite struct {
+	suite.Suite
+
+	dir    string
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+	now    time.Time
+	config *authConfig
+	m      *SecurityManager
+}
+
+func (suite *SecurityManagerTestSuite) SetupTest() {
+	var err error
+	suite.dir, err = ioutil.TempDir("", "jwt")
+	suite.NoError(err)
+
+	suite.rsaKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	suite.NoError(err)
+	suite.ecKey, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.NoError(err)
+
+	// the rsa key is configured as PEM file,
+	// and the ec key is in the JWKS file
+	der, err := x509.MarshalPKIXPublicKey(&suite.rsaKey.PublicKey)
+	suite.NoError(err)
+	pemFile := filepath.Join(suite.dir, "key.pem")
+	suite.NoError(ioutil.WriteFile(
+		pemFile,
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+		0600,
+	))
+
+	jwksFile := filepath.Join(suite.dir, "jwks.json")
+	suite.writeJWKS(jwksFile, "ec1", suite.ecKey)
+
+	suite.config = &authConfig{
+		PublicKeys: []string{pemFile},
+		JWKSFile:   jwksFile,
+		Issuer:     "sso",
+		Audience:   "peloton",
+		RoleMappings: map[string]string{
+			"peloton-admins": "admin",
+		},
+		DefaultRole: "reader",
+		Roles: []*basic.RoleConfig{
+			{
+				Role:   "admin",
+				Accept: []string{"*"},
+			},
+			{
+				Role:   "reader",
+				Accept: []string{"peloton.api.v1alpha.job.stateless.svc.JobService:Get*"},
+			},
+			{
+				Role:     "team1",
+				Accept:   []string{"peloton.api.v1alpha.job.stateless.svc.JobService:*"},
+				Respools: []string{"/team1"},
+			},
+		},
+	}
+
+	suite.now = time.Now()
+	suite.m, err = newJWTSecurityManager(suite.config)
+	suite.NoError(err)
+	suite.m.now = func() time.Time { return suite.now }
+}
+
+// writeJWKS writes a JWKS file containing the ec key
+func (suite *SecurityManagerTestSuite) writeJWKS(
+	path string,
+	kid string,
+	key *ecdsa.PrivateKey,
+) {
+	jwksData, err := json.Marshal(&jwks{Keys: []*jwk{{
+		Kty: "EC",
+		Kid: kid,
+		Use: "sig",
+		Crv: "P-256",
+		X:   base64.RawURLEncoding.EncodeToString(key.X.Bytes()),
+		Y:   base64.RawURLEncoding.EncodeToString(key.Y.Bytes()),
+	}}})
+	suite.NoError(err)
+	suite.NoError(ioutil.WriteFile(path, jwksData, 0600))
+}
+
+func (suite *SecurityManagerTestSuite) TearDownTest() {
+	os.RemoveAll(suite.dir)
+}
+
+func TestSecurityManagerTestSuite(t *testing.T) {
+	suite.Run(t, new(SecurityManagerTestSuite))
+}
+
+// validClaims returns claims accepted by the security manager
+func (suite *SecurityManagerTestSuite) validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub": "user1",
+		"iss": "sso",
+		"aud": []string{"peloton", "other"},
+		"exp": suite.now.Add(time.Hour).Unix(),
+		"nbf": suite.now.Add(-time.Hour).Unix(),
+	}
+}
+
+func (suite *SecurityManagerTestSuite) authenticate(raw string) (auth.User, error) {
+	return suite.m.Authenticate(&jwtToken{items: map[string]string{
+		_authorizationHeaderKey: _bearerPrefix + raw,
+	}})
+}
+
+func (suite *SecurityManagerTestSuite) TestAuthenticateRSA() {
+	claims := suite.validClaims()
+	claims["roles"] = []string{"peloton-admins"}
+
+	for _, alg := range []string{"RS256", "PS384"} {
+		u, err := suite.authenticate(
+			signToken(suite.T(), alg, "", suite.rsaKey, claims))
+		suite.NoError(err, alg)
+		suite.Equal("user1", u.GetUsername())
+		suite.True(u.IsPermitted(_stopJob))
+		suite.False(u.IsResourceScoped())
+	}
+}
+
+func (suite *SecurityManagerTestSuite) TestAuthenticateECDSA() {
+	claims := suite.validClaims()
+	claims["roles"] = "reader"
+
+	u, err := suite.authenticate(
+		signToken(suite.T(), "ES256", "ec1", suite.ecKey, claims))
+	suite.NoError(err)
+	suite.True(u.IsPermitted(_getJob))
+	suite.False(u.IsPermitted(_stopJob))
+}
+
+func (suite *SecurityManagerTestSuite) TestAuthenticateMultipleRoles() {
+	claims := suite.validClaims()
+	claims["roles"] = []string{"reader", "team1", "unknown"}
+
+	u, err := suite.authenticate(
+		signToken(suite.T(), "RS256", "", suite.rsaKey, claims))
+	suite.NoError(err)
+	suite.True(u.IsPermitted(_stopJob))
+	suite.True(u.IsResourceScoped())
+	suite.True(u.IsPermittedOnResource(
+		_stopJob, &auth.Resource{RespoolPath: "/team1/pool"}))
+	suite.False(u.IsPermittedOnResource(
+		_stopJob, &auth.Resource{RespoolPath: "/team2"}))
+	// reader role is not scoped
+	suite.True(u.IsPermittedOnResource(
+		_getJob, &auth.Resource{RespoolPath: "/team2"}))
+}
+
+func (suite *SecurityManagerTestSuite) TestAuthenticateNoRole() {
+	u, err := suite.authenticate(
+		signToken(suite.T(), "RS256", "", suite.rsaKey, suite.validClaims()))
+	suite.NoError(err)
+	suite.False(u.IsPermitted(_getJob))
+}
+
+func (suite *SecurityManagerTestSuite) TestAuthenticateDefaultUser() {
+	u, err := suite.m.Authenticate(&jwtToken{})
+	suite.NoError(err)
+	suite.True(u.IsPermitted(_getJob))
+	suite.False(u.IsPermitted(_stopJob))
+
+	// requests without token are rejected without default role
+	suite.config.DefaultRole = ""
+	m, err := newJWTSecurityManager(suite.config)
+	suite.NoError(err)
+	_, err = m.Authenticate(&jwtToken{})
+	suite.Error(err)
+}
+
+func (suite *SecurityManagerTestSuite) TestAuthenticateInvalidToken() {
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.NoError(err)
+
+	expired := suite.validClaims()
+	expired["exp"] = suite.now.Add(-time.Hour).Unix()
+	notValidYet := suite.validClaims()
+	notValidYet["nbf"] = suite.now.Add(time.Hour).Unix()
+	wrongIssuer := suite.validClaims()
+	wrongIssuer["iss"] = "other"
+	wrongAudience := suite.validClaims()
+	wrongAudience["aud"] = "other"
+	noSubject := suite.validClaims()
+	delete(noSubject, "sub")
+	noExpiry := suite.validClaims()
+	delete(noExpiry, "exp")
+
+	valid := signToken(suite.T(), "RS256", "", suite.rsaKey, suite.validClaims())
+
+	tests := map[string]string{
+		"unknown key":    signToken(suite.T(), "ES256", "", otherKey, suite.validClaims()),
+		"symmetric alg":  encodeSegments(suite.T(), "HS256", "", suite.validClaims()) + ".c2lnbmF0dXJl",
+		"expired":        signToken(suite.T(), "RS256", "", suite.rsaKey, expired),
+		"not valid yet":  signToken(suite.T(), "RS256", "", suite.rsaKey, notValidYet),
+		"wrong issuer":   signToken(suite.T(), "RS256", "", suite.rsaKey, wrongIssuer),
+		"wrong audience": signToken(suite.T(), "RS256", "", suite.rsaKey, wrongAudience),
+		"no subject":     signToken(suite.T(), "RS256", "", suite.rsaKey, noSubject),
+		"no expiry":      signToken(suite.T(), "RS256", "", suite.rsaKey, noExpiry),
+		"tampered":       valid[:len(valid)-4] + "AAAA",
+		"none algorithm": unsignedToken(suite.T(), suite.validClaims()),
+		"malformed":      "token",
+	}
+
+	for name, raw := range tests {
+		_, err := suite.authenticate(raw)
+		suite.Error(err, name)
+	}
+
+	// token which is not a bearer token
+	_, err = suite.m.Authenticate(&jwtToken{items: map[string]string{
+		_authorizationHeaderKey: "Basic dXNlcjpwYXNzd29yZA==",
+	}})
+	suite.Error(err)
+}
+
+// TestReloadJWKS tests that the keys are reloaded when the
+// JWKS file changes
+func (suite *SecurityManagerTestSuite) TestReloadJWKS() {
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	suite.NoError(err)
+	newToken := signToken(
+		suite.T(), "ES256", "ec2", newKey, suite.validClaims())
+	oldToken := signToken(
+		suite.T(), "ES256", "ec1", suite.ecKey, suite.validClaims())
+
+	// the file is not checked before the refresh interval passes
+	suite.writeJWKS(suite.config.JWKSFile, "ec2", newKey)
+	_, err = suite.authenticate(newToken)
+	suite.Error(err)
+	_, err = suite.authenticate(oldToken)
+	suite.NoError(err)
+
+	suite.now = suite.now.Add(_defaultJWKSRefreshInterval)
+	_, err = suite.authenticate(newToken)
+	suite.NoError(err)
+	_, err = suite.authenticate(oldToken)
+	suite.Error(err)
+
+	// the current keys are kept if the file cannot be reloaded
+	suite.NoError(ioutil.WriteFile(
+		suite.config.JWKSFile, []byte("invalid"), 0600))
+	suite.now = suite.now.Add(_defaultJWKSRefreshInterval)
+	_, err = suite.authenticate(newToken)
+	suite.NoError(err)
+}
+
+func (suite *SecurityManagerTestSuite) TestCreateJWTSecurityManagerErr() {
+	// undefined mapped role
+	suite.config.RoleMappings["group"] = "undefined"
+	_, err := newJWTSecurityManager(suite.config)
+	suite.Error(err)
+	delete(suite.config.RoleMappings, "group")
+
+	// undefined default role
+	suite.config.DefaultRole = "undefined"
+	_, err = newJWTSecurityManager(suite.config)
+	suite.Error(err)
+	suite.config.DefaultRole = ""
+
+	// no public keys
+	suite.config.PublicKeys = nil
+	suite.config.JWKSFile = ""
+	_, err = newJWTSecurityManager(suite.config)
+	suite.Error(err)
+}
+
+// signToken creates a token with the claims, signed by the private key
+func signToken(
+	t *testing.T,
+	alg string,
+	kid string,
+	key crypto.Signer,
+	claims map[string]interface{},
+) string {
+	signingInput := encodeSegments(t, alg, kid, claims)
+
+	hash := map[string]crypto.Hash{
+		"RS256": crypto.SHA256, "PS256": crypto.SHA256, "ES256": crypto.SHA256,
+		"PS384": crypto.SHA384,
+	}[alg]
+	h := hash.New()
+	h.Write([]byte(signingInput))
+	digest := h.Sum(nil)
+
+	var signature []byte
+	var err error
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		if alg[:2] == "PS" {
+			signature, err = rsa.SignPSS(rand.Reader, k, hash, digest, nil)
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, k, hash, digest)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, signErr := ecdsa.Sign(rand.Reader, k, digest)
+		if signErr != nil {
+			t.Fatal(signErr)
+		}
+		// the signature is r and s concatenated, each
+		// padded to the size of the curve
+		size := (k.Curve.Params().BitSize + 7) / 8
+		signature = make([]byte, 2*size)
+		copy(signature[size-len(r.Bytes()):size], r.Bytes())
+		copy(signature[2*size-len(s.Bytes()):], s.Bytes())
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signingInput + _tokenSeparator +
+		base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// unsignedToken creates a token with the "none" algorithm
+func unsignedToken(t *testing.T, claims map[string]interface{}) string {
+	return encodeSegments(t, "none", "", claims) + _tokenSeparator
+}
+
+func encodeSegments(
+	t *testing.T,
+	alg string,
+	kid string,
+	claims map[string]interface{},
+) string {
+	headerBytes, err := json.Marshal(&header{Alg: alg, Kid: kid, Typ: "JWT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimsBytes, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(headerBytes) +
+		_tokenSeparator +
+		base64.RawURLEncoding.EncodeToString(claimsBytes)
+}
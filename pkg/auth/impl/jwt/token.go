@@ -0,0 +1,235 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwt
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	// register the hash functions used by the signing algorithms
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+
+	"github.com/pkg/errors"
+)
+
+const _tokenSeparator = "."
+
+// header is the JOSE header of a token
+type header struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// claims are the claims of a token
+type claims map[string]interface{}
+
+// token is a parsed JSON Web Token, signed with JWS compact serialization
+type token struct {
+	header header
+	claims claims
+	// signingInput is the encoded header and payload covered by the signature
+	signingInput string
+	signature    []byte
+}
+
+// parseToken parses a token without verifying it
+func parseToken(raw string) (*token, error) {
+	parts := strings.Split(raw, _tokenSeparator)
+	if len(parts) != 3 {
+		return nil, errors.New("token must have 3 parts")
+	}
+
+	t := &token{signingInput: parts[0] + _tokenSeparator + parts[1]}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid header encoding")
+	}
+	if err := json.Unmarshal(headerBytes, &t.header); err != nil {
+		return nil, errors.Wrap(err, "invalid header")
+	}
+
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid payload encoding")
+	}
+	// decode numbers as json.Number so that large
+	// timestamps do not lose precision
+	decoder := json.NewDecoder(bytes.NewReader(claimsBytes))
+	decoder.UseNumber()
+	if err := decoder.Decode(&t.claims); err != nil {
+		return nil, errors.Wrap(err, "invalid payload")
+	}
+
+	if t.signature, err = base64.RawURLEncoding.DecodeString(parts[2]); err != nil {
+		return nil, errors.Wrap(err, "invalid signature encoding")
+	}
+
+	return t, nil
+}
+
+// verify checks the signature of the token with the keys
+func (t *token) verify(keys *keySet) error {
+	hash, ok := map[string]crypto.Hash{
+		"RS256": crypto.SHA256, "PS256": crypto.SHA256, "ES256": crypto.SHA256,
+		"RS384": crypto.SHA384, "PS384": crypto.SHA384, "ES384": crypto.SHA384,
+		"RS512": crypto.SHA512, "PS512": crypto.SHA512, "ES512": crypto.SHA512,
+	}[t.header.Alg]
+	// "none" and symmetric algorithms are not accepted, since
+	// only the token issuer may be able to sign tokens
+	if !ok {
+		return errors.Errorf("unsupported signing algorithm %q", t.header.Alg)
+	}
+
+	h := hash.New()
+	h.Write([]byte(t.signingInput))
+	digest := h.Sum(nil)
+
+	for _, key := range keys.candidates(t.header.Kid) {
+		if verifySignature(t.header.Alg, key, hash, digest, t.signature) {
+			return nil
+		}
+	}
+	return errors.New("invalid signature")
+}
+
+func verifySignature(
+	alg string,
+	key crypto.PublicKey,
+	hash crypto.Hash,
+	digest []byte,
+	signature []byte,
+) bool {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		switch alg[:2] {
+		case "RS":
+			return rsa.VerifyPKCS1v15(k, hash, digest, signature) == nil
+		case "PS":
+			return rsa.VerifyPSS(k, hash, digest, signature, nil) == nil
+		}
+	case *ecdsa.PublicKey:
+		if alg[:2] != "ES" {
+			return false
+		}
+		// the signature is r and s concatenated,
+		// each of the size of the curve
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(k, digest, r, s)
+	}
+	return false
+}
+
+// validate checks the registered claims of the token. Tokens
+// must expire, so that a leaked token is not valid forever.
+func (t *token) validate(config *authConfig, now time.Time) error {
+	if exp, ok, err := t.claims.time("exp"); err != nil {
+		return err
+	} else if !ok {
+		return errors.New("token has no exp claim")
+	} else if !now.Before(exp.Add(config.ClockSkew)) {
+		return errors.New("token is expired")
+	}
+
+	if nbf, ok, err := t.claims.time("nbf"); err != nil {
+		return err
+	} else if ok && now.Add(config.ClockSkew).Before(nbf) {
+		return errors.New("token is not valid yet")
+	}
+
+	if len(config.Issuer) != 0 && t.claims.string("iss") != config.Issuer {
+		return errors.New("unexpected token issuer")
+	}
+
+	if len(config.Audience) != 0 {
+		var found bool
+		for _, aud := range t.claims.strings("aud") {
+			if aud == config.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("unexpected token audience")
+		}
+	}
+
+	return nil
+}
+
+// expiry returns the expiration time of the token, or zero
+// time if the token does not expire
+func (t *token) expiry() time.Time {
+	exp, _, _ := t.claims.time("exp")
+	return exp
+}
+
+// string returns a string claim, or empty string if it is not set
+func (c claims) string(name string) string {
+	s, _ := c[name].(string)
+	return s
+}
+
+// strings returns a claim which is either a string or a list of strings
+func (c claims) strings(name string) []string {
+	switch v := c[name].(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var result []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// time returns a claim in seconds since epoch as time, and
+// whether the claim is set
+func (c claims) time(name string) (time.Time, bool, error) {
+	v, ok := c[name]
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	n, ok := v.(json.Number)
+	if !ok {
+		return time.Time{}, false, errors.Errorf("invalid %s claim", name)
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return time.Time{}, false, errors.Errorf("invalid %s claim", name)
+	}
+	sec := int64(f)
+	nsec := int64((f - float64(sec)) * float64(time.Second))
+	return time.Unix(sec, nsec), true, nil
+}
@@ -28,11 +28,29 @@ func (m *SecurityManager) Authenticate(token auth.Token) (auth.User, error) {
 
 type noopUser struct{}
 
+// GetUsername always return empty username
+func (u *noopUser) GetUsername() string {
+	return ""
+}
+
 // IsPermitted always return true
 func (u *noopUser) IsPermitted(procedure string) bool {
 	return true
 }
 
+// IsResourceScoped always return false
+func (u *noopUser) IsResourceScoped() bool {
+	return false
+}
+
+// IsPermittedOnResource always return true
+func (u *noopUser) IsPermittedOnResource(
+	procedure string,
+	resource *auth.Resource,
+) bool {
+	return true
+}
+
 // NewNoopSecurityManager returns SecurityManager
 func NewNoopSecurityManager() *SecurityManager {
 	return &SecurityManager{}
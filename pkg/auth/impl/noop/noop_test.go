@@ -17,6 +17,8 @@ package noop
 import (
 	"testing"
 
+	"github.com/uber/peloton/pkg/auth"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -29,6 +31,13 @@ func TestNoopSecurityManager(t *testing.T) {
 	assert.True(t, u.IsPermitted("peloton.api.v1alpha.job.stateless.svc.JobService::CreateJob"))
 	// even if the procedure name is not valid, still should pass permit check
 	assert.True(t, u.IsPermitted(""))
+
+	assert.Empty(t, u.GetUsername())
+	assert.False(t, u.IsResourceScoped())
+	assert.True(t, u.IsPermittedOnResource(
+		"peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+		&auth.Resource{RespoolPath: "/team1", Owner: "user1"},
+	))
 }
 
 func TestNoopSecurityClient(t *testing.T) {
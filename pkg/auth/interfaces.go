@@ -24,6 +24,8 @@ const (
 	NOOP = Type("NOOP")
 	// BASIC would use username and password for auth
 	BASIC = Type("BASIC")
+	// JWT would use signed JSON Web Tokens for auth
+	JWT = Type("JWT")
 )
 
 // Token is used by SecurityManager to authenticate a user
@@ -42,11 +44,35 @@ type SecurityManager interface {
 	Authenticate(token Token) (User, error)
 }
 
+// Resource is the target of a procedure call, such as a job.
+// It is used to scope authorization to the resources a user
+// may act on.
+type Resource struct {
+	// RespoolPath is the path of the resource pool
+	// the resource belongs to
+	RespoolPath string
+	// Owner is the owner of the resource
+	Owner string
+	// OwningTeam is the team owning the resource
+	OwningTeam string
+}
+
 // User includes authorization related methods
 type User interface {
+	// GetUsername returns the name of the user,
+	// which is empty for anonymous users
+	GetUsername() string
 	// IsPermitted returns whether user can
 	// access the specified procedure
 	IsPermitted(procedure string) bool
+	// IsResourceScoped returns whether user is restricted
+	// to act on a subset of resources, in which case
+	// IsPermittedOnResource needs to be checked for
+	// procedures which target a resource
+	IsResourceScoped() bool
+	// IsPermittedOnResource returns whether user can
+	// access the specified procedure on the resource
+	IsPermittedOnResource(procedure string, resource *Resource) bool
 }
 
 // SecurityClient is the internal client used by each of
@@ -0,0 +1,87 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+)
+
+const (
+	auditRecordFormatHeader = "Time\tComponent\tUser\tProcedure\tSummary\tResult\tLatency(ms)\tMessage\n"
+	auditRecordFormatBody   = "%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n"
+)
+
+// AuditQueryAction is the action for querying the audit records of calls
+// to mutating APIs by job, user and time range
+func (c *Client) AuditQueryAction(
+	jobID string,
+	username string,
+	startTime string,
+	endTime string,
+	limit uint32,
+) error {
+	request := &auditsvc.QueryAuditRecordsRequest{
+		Username:  username,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     limit,
+	}
+	if len(jobID) != 0 {
+		request.JobId = &peloton.JobID{Value: jobID}
+	}
+
+	response, err := c.auditClient.QueryAuditRecords(c.ctx, request)
+	if err != nil {
+		return err
+	}
+
+	printAuditQueryResponse(response, c.Debug)
+	return nil
+}
+
+func printAuditQueryResponse(
+	r *auditsvc.QueryAuditRecordsResponse,
+	debug bool) {
+	defer tabWriter.Flush()
+
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+
+	if len(r.GetRecords()) == 0 {
+		fmt.Fprintf(tabWriter, "No audit records found\n")
+		return
+	}
+
+	fmt.Fprintf(tabWriter, auditRecordFormatHeader)
+	for _, record := range r.GetRecords() {
+		fmt.Fprintf(
+			tabWriter,
+			auditRecordFormatBody,
+			record.GetTime(),
+			record.GetComponent(),
+			record.GetUsername(),
+			record.GetProcedure(),
+			record.GetSummary(),
+			record.GetResult(),
+			record.GetLatencyMs(),
+			record.GetMessage(),
+		)
+	}
+}
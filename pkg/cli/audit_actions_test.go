@@ -0,0 +1,112 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+	auditmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type auditActionsTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	client Client
+
+	ctrl        *gomock.Controller
+	auditClient *auditmocks.MockAuditServiceYARPCClient
+}
+
+func (suite *auditActionsTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.auditClient = auditmocks.NewMockAuditServiceYARPCClient(suite.ctrl)
+	suite.ctx = context.Background()
+	suite.client = Client{
+		Debug:       false,
+		auditClient: suite.auditClient,
+		dispatcher:  nil,
+		ctx:         suite.ctx,
+	}
+}
+
+func (suite *auditActionsTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestAuditActions(t *testing.T) {
+	suite.Run(t, new(auditActionsTestSuite))
+}
+
+// TestAuditQueryAction tests querying the audit records of a job
+func (suite *auditActionsTestSuite) TestAuditQueryAction() {
+	resp := &auditsvc.QueryAuditRecordsResponse{
+		Records: []*audit.AuditRecord{
+			{
+				Time:      "2019-01-02T01:00:00Z",
+				Component: "peloton-jobmgr",
+				Username:  "user1",
+				Procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+				JobId:     &peloton.JobID{Value: testJobID},
+				Summary:   "job_id=" + testJobID,
+				Result:    "OK",
+				LatencyMs: 10,
+			},
+		},
+	}
+	suite.auditClient.EXPECT().
+		QueryAuditRecords(gomock.Any(), &auditsvc.QueryAuditRecordsRequest{
+			JobId:     &peloton.JobID{Value: testJobID},
+			Username:  "user1",
+			StartTime: "2019-01-02T00:00:00Z",
+			Limit:     10,
+		}).
+		Return(resp, nil).
+		Times(2)
+
+	suite.NoError(suite.client.AuditQueryAction(
+		testJobID, "user1", "2019-01-02T00:00:00Z", "", 10))
+
+	suite.client.Debug = true
+	suite.NoError(suite.client.AuditQueryAction(
+		testJobID, "user1", "2019-01-02T00:00:00Z", "", 10))
+}
+
+// TestAuditQueryActionNoRecords tests querying without any
+// matching records
+func (suite *auditActionsTestSuite) TestAuditQueryActionNoRecords() {
+	suite.auditClient.EXPECT().
+		QueryAuditRecords(gomock.Any(), &auditsvc.QueryAuditRecordsRequest{}).
+		Return(&auditsvc.QueryAuditRecordsResponse{}, nil)
+
+	suite.NoError(suite.client.AuditQueryAction("", "", "", "", 0))
+}
+
+// TestAuditQueryActionFail tests the failure case of querying
+// the audit records
+func (suite *auditActionsTestSuite) TestAuditQueryActionFail() {
+	suite.auditClient.EXPECT().
+		QueryAuditRecords(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.InvalidArgumentErrorf("test error"))
+
+	suite.Error(suite.client.AuditQueryAction("", "", "yesterday", "", 0))
+}
@@ -23,12 +23,16 @@ import (
 	"go.uber.org/yarpc/api/transport"
 	"go.uber.org/yarpc/transport/grpc"
 
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
 	hostsvc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	updatesvc "github.com/uber/peloton/.gen/peloton/api/v0/update/svc"
 	volume_svc "github.com/uber/peloton/.gen/peloton/api/v0/volume/svc"
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+	v1alphahostsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc"
 	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
 	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
 	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
@@ -47,12 +51,16 @@ type Client struct {
 	podClient       podsvc.PodServiceYARPCClient
 	statelessClient statelesssvc.JobServiceYARPCClient
 	watchClient     watchsvc.WatchServiceYARPCClient
+	auditClient     auditsvc.AuditServiceYARPCClient
+	cronClient      cronsvc.CronServiceYARPCClient
+	dagClient       dagsvc.DagServiceYARPCClient
 	resClient       respool.ResourceManagerYARPCClient
 	resMgrClient    resmgrsvc.ResourceManagerServiceYARPCClient
 	updateClient    updatesvc.UpdateServiceYARPCClient
 	volumeClient    volume_svc.VolumeServiceYARPCClient
 	hostMgrClient   hostmgr_svc.InternalHostServiceYARPCClient
 	hostClient      hostsvc.HostServiceYARPCClient
+	hostWatchClient v1alphahostsvc.HostServiceYARPCClient
 	dispatcher      *yarpc.Dispatcher
 	ctx             context.Context
 	cancelFunc      context.CancelFunc
@@ -60,11 +68,13 @@ type Client struct {
 	Debug bool
 }
 
-// New returns a new RPC client given a framework URL and timeout and error
+// New returns a new RPC client given a framework URL and timeout and error.
+// If authToken is set, it is sent as bearer token instead of basic auth.
 func New(
 	discovery leader.Discovery,
 	timeout time.Duration,
 	authConfig *middleware.BasicAuthConfig,
+	authToken string,
 	debug bool) (*Client, error) {
 
 	jobmgrURL, err := discovery.GetAppURL(common.JobManagerRole)
@@ -84,7 +94,10 @@ func New(
 
 	t := grpc.NewTransport()
 
-	authMiddleware := middleware.NewBasicAuthOutboundMiddleware(authConfig)
+	var authMiddleware middleware.OutboundMiddleware = middleware.NewBasicAuthOutboundMiddleware(authConfig)
+	if len(authToken) != 0 {
+		authMiddleware = middleware.NewBearerAuthOutboundMiddleware(authToken)
+	}
 
 	dispatcher := yarpc.NewDispatcher(yarpc.Config{
 		Name: common.PelotonCLI,
@@ -97,7 +110,8 @@ func New(
 				Unary: t.NewSingleOutbound(resmgrURL.Host),
 			},
 			common.PelotonHostManager: transport.Outbounds{
-				Unary: t.NewSingleOutbound(hostmgrURL.Host),
+				Unary:  t.NewSingleOutbound(hostmgrURL.Host),
+				Stream: t.NewSingleOutbound(hostmgrURL.Host),
 			},
 		},
 		OutboundMiddleware: yarpc.OutboundMiddleware{
@@ -137,6 +151,9 @@ func New(
 		hostClient: hostsvc.NewHostServiceYARPCClient(
 			dispatcher.ClientConfig(common.PelotonHostManager),
 		),
+		hostWatchClient: v1alphahostsvc.NewHostServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonHostManager),
+		),
 		podClient: podsvc.NewPodServiceYARPCClient(
 			dispatcher.ClientConfig(common.PelotonJobManager),
 		),
@@ -146,6 +163,15 @@ func New(
 		watchClient: watchsvc.NewWatchServiceYARPCClient(
 			dispatcher.ClientConfig(common.PelotonJobManager),
 		),
+		auditClient: auditsvc.NewAuditServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager),
+		),
+		cronClient: cronsvc.NewCronServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager),
+		),
+		dagClient: dagsvc.NewDagServiceYARPCClient(
+			dispatcher.ClientConfig(common.PelotonJobManager),
+		),
 		dispatcher: dispatcher,
 		ctx:        ctx,
 		cancelFunc: cancelFunc,
@@ -0,0 +1,254 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	cronJobFormatHeader = "Name\tSchedule\tCollision Policy\tLast Schedule Time\tNext Schedule Time\tActive Jobs\n"
+	cronJobFormatBody   = "%s\t%s\t%s\t%s\t%s\t%d\n"
+	cronRunFormatHeader = "Time\tTrigger\tResult\tJob ID\tMessage\n"
+	cronRunFormatBody   = "%s\t%s\t%s\t%s\t%s\n"
+)
+
+// newCronJobConfig builds the config of a cron job from a schedule,
+// a collision policy and the config file of the batch job template
+func (c *Client) newCronJobConfig(
+	name, respoolPath, schedule, collisionPolicy, cfg string,
+) (*cron.CronJobConfig, error) {
+	policy, ok := cron.CollisionPolicy_value[strings.ToUpper(collisionPolicy)]
+	if !ok {
+		return nil, fmt.Errorf("invalid collision policy: %s", collisionPolicy)
+	}
+
+	respoolID, err := c.LookupResourcePoolID(respoolPath)
+	if err != nil {
+		return nil, err
+	}
+	if respoolID == nil {
+		return nil, fmt.Errorf("unable to find resource pool ID for "+
+			":%s", respoolPath)
+	}
+
+	var jobConfig job.JobConfig
+	buffer, err := ioutil.ReadFile(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open file %s: %v", cfg, err)
+	}
+	if err := yaml.Unmarshal(buffer, &jobConfig); err != nil {
+		return nil, fmt.Errorf("unable to parse file %s: %v", cfg, err)
+	}
+	jobConfig.RespoolID = respoolID
+
+	return &cron.CronJobConfig{
+		Name:            name,
+		Schedule:        schedule,
+		CollisionPolicy: cron.CollisionPolicy(policy),
+		JobConfig:       &jobConfig,
+	}, nil
+}
+
+// CronCreateAction is the action for creating a cron job which creates
+// a batch job from the given config each time its schedule is due
+func (c *Client) CronCreateAction(
+	name, respoolPath, schedule, collisionPolicy, cfg string,
+) error {
+	config, err := c.newCronJobConfig(
+		name, respoolPath, schedule, collisionPolicy, cfg)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.cronClient.CreateCronJob(
+		c.ctx,
+		&cronsvc.CreateCronJobRequest{Config: config},
+	)
+	if err != nil {
+		return err
+	}
+
+	printCronJobInfo(response.GetInfo(), c.Debug)
+	return nil
+}
+
+// CronReplaceAction is the action for replacing the config of a cron job
+func (c *Client) CronReplaceAction(
+	name, respoolPath, schedule, collisionPolicy, cfg string,
+) error {
+	config, err := c.newCronJobConfig(
+		name, respoolPath, schedule, collisionPolicy, cfg)
+	if err != nil {
+		return err
+	}
+
+	response, err := c.cronClient.ReplaceCronJob(
+		c.ctx,
+		&cronsvc.ReplaceCronJobRequest{Config: config},
+	)
+	if err != nil {
+		return err
+	}
+
+	printCronJobInfo(response.GetInfo(), c.Debug)
+	return nil
+}
+
+// CronDeleteAction is the action for deleting a cron job. The jobs
+// created by its previous runs are not stopped.
+func (c *Client) CronDeleteAction(name string) error {
+	_, err := c.cronClient.DeleteCronJob(
+		c.ctx,
+		&cronsvc.DeleteCronJobRequest{Name: name},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Cron job %s deleted\n", name)
+	return nil
+}
+
+// CronGetAction is the action for getting a cron job and its most
+// recent runs
+func (c *Client) CronGetAction(name string, runLimit uint32) error {
+	response, err := c.cronClient.GetCronJob(
+		c.ctx,
+		&cronsvc.GetCronJobRequest{Name: name, RunLimit: runLimit},
+	)
+	if err != nil {
+		return err
+	}
+
+	if c.Debug {
+		printResponseJSON(response)
+		return nil
+	}
+
+	printCronJobInfo(response.GetInfo(), false)
+	fmt.Println()
+	printCronRuns(response.GetRuns())
+	return nil
+}
+
+// CronListAction is the action for listing all the cron jobs
+func (c *Client) CronListAction() error {
+	response, err := c.cronClient.ListCronJobs(
+		c.ctx,
+		&cronsvc.ListCronJobsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printCronListResponse(response, c.Debug)
+	return nil
+}
+
+// CronStartAction is the action for triggering a run of a cron job
+// outside of its schedule
+func (c *Client) CronStartAction(name string) error {
+	response, err := c.cronClient.StartCronJob(
+		c.ctx,
+		&cronsvc.StartCronJobRequest{Name: name},
+	)
+	if err != nil {
+		return err
+	}
+
+	if c.Debug {
+		printResponseJSON(response)
+		return nil
+	}
+
+	printCronRuns([]*cron.CronRun{response.GetRun()})
+	return nil
+}
+
+func printCronJobInfo(info *cron.CronJobInfo, debug bool) {
+	if debug {
+		printResponseJSON(info)
+		return
+	}
+
+	defer tabWriter.Flush()
+	fmt.Fprintf(tabWriter, cronJobFormatHeader)
+	printCronJobRow(info)
+}
+
+func printCronListResponse(
+	r *cronsvc.ListCronJobsResponse,
+	debug bool) {
+	defer tabWriter.Flush()
+
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+
+	if len(r.GetInfos()) == 0 {
+		fmt.Fprintf(tabWriter, "No cron jobs found\n")
+		return
+	}
+
+	fmt.Fprintf(tabWriter, cronJobFormatHeader)
+	for _, info := range r.GetInfos() {
+		printCronJobRow(info)
+	}
+}
+
+func printCronJobRow(info *cron.CronJobInfo) {
+	fmt.Fprintf(
+		tabWriter,
+		cronJobFormatBody,
+		info.GetConfig().GetName(),
+		info.GetConfig().GetSchedule(),
+		info.GetConfig().GetCollisionPolicy(),
+		info.GetLastScheduleTime(),
+		info.GetNextScheduleTime(),
+		len(info.GetActiveJobs()),
+	)
+}
+
+func printCronRuns(runs []*cron.CronRun) {
+	defer tabWriter.Flush()
+
+	if len(runs) == 0 {
+		fmt.Fprintf(tabWriter, "No cron runs found\n")
+		return
+	}
+
+	fmt.Fprintf(tabWriter, cronRunFormatHeader)
+	for _, run := range runs {
+		fmt.Fprintf(
+			tabWriter,
+			cronRunFormatBody,
+			run.GetTime(),
+			run.GetTrigger(),
+			run.GetResult(),
+			run.GetJobId().GetValue(),
+			run.GetMessage(),
+		)
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	cronmocks "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	testCronJobName  = "nightly"
+	testCronSchedule = "0 2 * * *"
+	testCronRespool  = "/DefaultResPool"
+)
+
+type cronActionsTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	client Client
+
+	ctrl       *gomock.Controller
+	cronClient *cronmocks.MockCronServiceYARPCClient
+	resClient  *respoolmocks.MockResourceManagerYARPCClient
+}
+
+func (suite *cronActionsTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.cronClient = cronmocks.NewMockCronServiceYARPCClient(suite.ctrl)
+	suite.resClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.ctx = context.Background()
+	suite.client = Client{
+		Debug:      false,
+		cronClient: suite.cronClient,
+		resClient:  suite.resClient,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+}
+
+func (suite *cronActionsTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestCronActions(t *testing.T) {
+	suite.Run(t, new(cronActionsTestSuite))
+}
+
+func (suite *cronActionsTestSuite) expectRespoolLookup() *peloton.ResourcePoolID {
+	respoolID := &peloton.ResourcePoolID{Value: "respool1"}
+	suite.resClient.EXPECT().
+		LookupResourcePoolID(gomock.Any(), &respool.LookupRequest{
+			Path: &respool.ResourcePoolPath{Value: testCronRespool},
+		}).
+		Return(&respool.LookupResponse{Id: respoolID}, nil)
+	return respoolID
+}
+
+func (suite *cronActionsTestSuite) testInfo() *cron.CronJobInfo {
+	return &cron.CronJobInfo{
+		Config: &cron.CronJobConfig{
+			Name:     testCronJobName,
+			Schedule: testCronSchedule,
+		},
+		CreateTime:       "2019-01-01T00:00:00Z",
+		NextScheduleTime: "2019-01-02T02:00:00Z",
+	}
+}
+
+// TestCronCreateAction tests creating a cron job from a job config file
+func (suite *cronActionsTestSuite) TestCronCreateAction() {
+	respoolID := suite.expectRespoolLookup()
+	suite.cronClient.EXPECT().
+		CreateCronJob(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *cronsvc.CreateCronJobRequest) {
+			config := req.GetConfig()
+			suite.Equal(testCronJobName, config.GetName())
+			suite.Equal(testCronSchedule, config.GetSchedule())
+			suite.Equal(
+				cron.CollisionPolicy_KILL_PREVIOUS,
+				config.GetCollisionPolicy(),
+			)
+			suite.Equal(respoolID, config.GetJobConfig().GetRespoolID())
+			suite.Equal(uint32(10), config.GetJobConfig().GetInstanceCount())
+		}).
+		Return(&cronsvc.CreateCronJobResponse{Info: suite.testInfo()}, nil)
+
+	suite.NoError(suite.client.CronCreateAction(
+		testCronJobName,
+		testCronRespool,
+		testCronSchedule,
+		"kill_previous",
+		testJobConfig,
+	))
+}
+
+// TestCronCreateActionInvalidInput tests creating a cron job with an
+// invalid collision policy or config file
+func (suite *cronActionsTestSuite) TestCronCreateActionInvalidInput() {
+	suite.Error(suite.client.CronCreateAction(
+		testCronJobName,
+		testCronRespool,
+		testCronSchedule,
+		"wait",
+		testJobConfig,
+	))
+
+	suite.expectRespoolLookup()
+	suite.Error(suite.client.CronCreateAction(
+		testCronJobName,
+		testCronRespool,
+		testCronSchedule,
+		"skip",
+		"does-not-exist.yaml",
+	))
+
+	suite.resClient.EXPECT().
+		LookupResourcePoolID(gomock.Any(), gomock.Any()).
+		Return(&respool.LookupResponse{}, nil)
+	suite.Error(suite.client.CronCreateAction(
+		testCronJobName,
+		testCronRespool,
+		testCronSchedule,
+		"skip",
+		testJobConfig,
+	))
+}
+
+// TestCronReplaceAction tests replacing the config of a cron job
+func (suite *cronActionsTestSuite) TestCronReplaceAction() {
+	suite.expectRespoolLookup()
+	suite.cronClient.EXPECT().
+		ReplaceCronJob(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *cronsvc.ReplaceCronJobRequest) {
+			suite.Equal(
+				cron.CollisionPolicy_SKIP,
+				req.GetConfig().GetCollisionPolicy(),
+			)
+		}).
+		Return(nil, yarpcerrors.NotFoundErrorf("cron job not found"))
+
+	suite.Error(suite.client.CronReplaceAction(
+		testCronJobName,
+		testCronRespool,
+		testCronSchedule,
+		"skip",
+		testJobConfig,
+	))
+}
+
+// TestCronDeleteAction tests deleting a cron job
+func (suite *cronActionsTestSuite) TestCronDeleteAction() {
+	suite.cronClient.EXPECT().
+		DeleteCronJob(gomock.Any(), &cronsvc.DeleteCronJobRequest{
+			Name: testCronJobName,
+		}).
+		Return(&cronsvc.DeleteCronJobResponse{}, nil)
+	suite.NoError(suite.client.CronDeleteAction(testCronJobName))
+
+	suite.cronClient.EXPECT().
+		DeleteCronJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("test error"))
+	suite.Error(suite.client.CronDeleteAction(testCronJobName))
+}
+
+// TestCronGetAction tests getting a cron job and its runs
+func (suite *cronActionsTestSuite) TestCronGetAction() {
+	suite.cronClient.EXPECT().
+		GetCronJob(gomock.Any(), &cronsvc.GetCronJobRequest{
+			Name:     testCronJobName,
+			RunLimit: 5,
+		}).
+		Return(&cronsvc.GetCronJobResponse{
+			Info: suite.testInfo(),
+			Runs: []*cron.CronRun{
+				{
+					Time:   "2019-01-01T02:00:00Z",
+					Result: cron.RunResult_CREATED,
+					JobId:  &peloton.JobID{Value: testJobID},
+				},
+				{
+					Time:    "2019-01-01T01:00:00Z",
+					Result:  cron.RunResult_SKIPPED,
+					Message: "previous run is still active",
+				},
+			},
+		}, nil).
+		Times(2)
+
+	suite.NoError(suite.client.CronGetAction(testCronJobName, 5))
+
+	suite.client.Debug = true
+	suite.NoError(suite.client.CronGetAction(testCronJobName, 5))
+
+	suite.cronClient.EXPECT().
+		GetCronJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf("cron job not found"))
+	suite.Error(suite.client.CronGetAction(testCronJobName, 5))
+}
+
+// TestCronListAction tests listing the cron jobs
+func (suite *cronActionsTestSuite) TestCronListAction() {
+	suite.cronClient.EXPECT().
+		ListCronJobs(gomock.Any(), &cronsvc.ListCronJobsRequest{}).
+		Return(&cronsvc.ListCronJobsResponse{
+			Infos: []*cron.CronJobInfo{suite.testInfo()},
+		}, nil)
+	suite.NoError(suite.client.CronListAction())
+
+	suite.cronClient.EXPECT().
+		ListCronJobs(gomock.Any(), gomock.Any()).
+		Return(&cronsvc.ListCronJobsResponse{}, nil)
+	suite.NoError(suite.client.CronListAction())
+
+	suite.cronClient.EXPECT().
+		ListCronJobs(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("test error"))
+	suite.Error(suite.client.CronListAction())
+}
+
+// TestCronStartAction tests triggering a run of a cron job
+func (suite *cronActionsTestSuite) TestCronStartAction() {
+	suite.cronClient.EXPECT().
+		StartCronJob(gomock.Any(), &cronsvc.StartCronJobRequest{
+			Name: testCronJobName,
+		}).
+		Return(&cronsvc.StartCronJobResponse{
+			Run: &cron.CronRun{
+				Time:    "2019-01-01T03:00:00Z",
+				Trigger: cron.RunTrigger_MANUAL,
+				Result:  cron.RunResult_CREATED,
+				JobId:   &peloton.JobID{Value: testJobID},
+			},
+		}, nil)
+	suite.NoError(suite.client.CronStartAction(testCronJobName))
+
+	suite.cronClient.EXPECT().
+		StartCronJob(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("test error"))
+	suite.Error(suite.client.CronStartAction(testCronJobName))
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	dagFormatHeader     = "ID\tName\tState\tGoal State\tNodes\tCreation Time\tCompletion Time\n"
+	dagFormatBody       = "%s\t%s\t%s\t%s\t%d\t%s\t%s\n"
+	dagNodeFormatHeader = "Node\tState\tJob ID\tMessage\n"
+	dagNodeFormatBody   = "%s\t%s\t%s\t%s\n"
+)
+
+// DagSubmitAction is the action for submitting a DAG of batch jobs
+// from a spec file. All the jobs of the DAG run in the given
+// resource pool.
+func (c *Client) DagSubmitAction(respoolPath, specFile string) error {
+	respoolID, err := c.LookupResourcePoolID(respoolPath)
+	if err != nil {
+		return err
+	}
+	if respoolID == nil {
+		return fmt.Errorf("unable to find resource pool ID for "+
+			":%s", respoolPath)
+	}
+
+	var spec dag.DagSpec
+	buffer, err := ioutil.ReadFile(specFile)
+	if err != nil {
+		return fmt.Errorf("unable to open file %s: %v", specFile, err)
+	}
+	if err := yaml.Unmarshal(buffer, &spec); err != nil {
+		return fmt.Errorf("unable to parse file %s: %v", specFile, err)
+	}
+	for _, node := range spec.GetNodes() {
+		if node.GetJobConfig() != nil {
+			node.JobConfig.RespoolID = respoolID
+		}
+	}
+
+	response, err := c.dagClient.SubmitDag(
+		c.ctx,
+		&dagsvc.SubmitDagRequest{Spec: &spec},
+	)
+	if err != nil {
+		return err
+	}
+
+	if c.Debug {
+		printResponseJSON(response)
+		return nil
+	}
+
+	fmt.Printf("DAG %s submitted\n", response.GetId().GetValue())
+	return nil
+}
+
+// DagGetAction is the action for getting a DAG and the status
+// of its nodes
+func (c *Client) DagGetAction(dagID string) error {
+	response, err := c.dagClient.GetDag(
+		c.ctx,
+		&dagsvc.GetDagRequest{Id: &dag.DagID{Value: dagID}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if c.Debug {
+		printResponseJSON(response)
+		return nil
+	}
+
+	printDagInfos([]*dag.DagInfo{response.GetInfo()})
+	fmt.Println()
+	printDagNodes(response.GetInfo().GetNodes())
+	return nil
+}
+
+// DagListAction is the action for listing the DAGs submitted
+// in the last 30 days
+func (c *Client) DagListAction() error {
+	response, err := c.dagClient.ListDags(
+		c.ctx,
+		&dagsvc.ListDagsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	if c.Debug {
+		printResponseJSON(response)
+		return nil
+	}
+
+	printDagInfos(response.GetInfos())
+	return nil
+}
+
+// DagCancelAction is the action for canceling a DAG. Its running
+// jobs are killed and its pending nodes are skipped.
+func (c *Client) DagCancelAction(dagID string) error {
+	_, err := c.dagClient.CancelDag(
+		c.ctx,
+		&dagsvc.CancelDagRequest{Id: &dag.DagID{Value: dagID}},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("DAG %s canceled\n", dagID)
+	return nil
+}
+
+func printDagInfos(infos []*dag.DagInfo) {
+	defer tabWriter.Flush()
+
+	if len(infos) == 0 {
+		fmt.Fprintf(tabWriter, "No DAGs found\n")
+		return
+	}
+
+	fmt.Fprintf(tabWriter, dagFormatHeader)
+	for _, info := range infos {
+		fmt.Fprintf(
+			tabWriter,
+			dagFormatBody,
+			info.GetId().GetValue(),
+			info.GetSpec().GetName(),
+			info.GetState(),
+			info.GetGoalState(),
+			len(info.GetNodes()),
+			info.GetCreationTime(),
+			info.GetCompletionTime(),
+		)
+	}
+}
+
+func printDagNodes(nodes []*dag.NodeStatus) {
+	defer tabWriter.Flush()
+
+	fmt.Fprintf(tabWriter, dagNodeFormatHeader)
+	for _, node := range nodes {
+		fmt.Fprintf(
+			tabWriter,
+			dagNodeFormatBody,
+			node.GetName(),
+			node.GetState(),
+			node.GetJobId().GetValue(),
+			node.GetMessage(),
+		)
+	}
+}
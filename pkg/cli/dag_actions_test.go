@@ -0,0 +1,199 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	dagmocks "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	testDagID   = "8e5bb9d6-5a49-4c1c-9e1e-3b0f4f0b6a4e"
+	testDagSpec = "../../example/testdag.yaml"
+)
+
+type dagActionsTestSuite struct {
+	suite.Suite
+	ctx    context.Context
+	client Client
+
+	ctrl      *gomock.Controller
+	dagClient *dagmocks.MockDagServiceYARPCClient
+	resClient *respoolmocks.MockResourceManagerYARPCClient
+}
+
+func (suite *dagActionsTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.dagClient = dagmocks.NewMockDagServiceYARPCClient(suite.ctrl)
+	suite.resClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.ctx = context.Background()
+	suite.client = Client{
+		Debug:      false,
+		dagClient:  suite.dagClient,
+		resClient:  suite.resClient,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+}
+
+func (suite *dagActionsTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestDagActions(t *testing.T) {
+	suite.Run(t, new(dagActionsTestSuite))
+}
+
+func (suite *dagActionsTestSuite) testInfo() *dag.DagInfo {
+	return &dag.DagInfo{
+		Id:           &dag.DagID{Value: testDagID},
+		Spec:         &dag.DagSpec{Name: "HelloWorldDag"},
+		State:        dag.DagState_DAG_RUNNING,
+		GoalState:    dag.DagState_DAG_SUCCEEDED,
+		CreationTime: "2019-01-01T00:00:00Z",
+		Nodes: []*dag.NodeStatus{
+			{
+				Name:  "extract",
+				State: dag.NodeState_NODE_RUNNING,
+				JobId: &peloton.JobID{Value: testJobID},
+			},
+			{
+				Name:  "load",
+				State: dag.NodeState_NODE_PENDING,
+			},
+		},
+	}
+}
+
+// TestDagSubmitAction tests submitting a DAG from a spec file
+func (suite *dagActionsTestSuite) TestDagSubmitAction() {
+	respoolID := &peloton.ResourcePoolID{Value: "respool1"}
+	suite.resClient.EXPECT().
+		LookupResourcePoolID(gomock.Any(), &respool.LookupRequest{
+			Path: &respool.ResourcePoolPath{Value: testCronRespool},
+		}).
+		Return(&respool.LookupResponse{Id: respoolID}, nil)
+	suite.dagClient.EXPECT().
+		SubmitDag(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *dagsvc.SubmitDagRequest) {
+			spec := req.GetSpec()
+			suite.Equal("HelloWorldDag", spec.GetName())
+			suite.Len(spec.GetNodes(), 3)
+			for _, node := range spec.GetNodes() {
+				suite.Equal(respoolID, node.GetJobConfig().GetRespoolID())
+			}
+			suite.Len(spec.GetEdges(), 2)
+			suite.Equal(
+				dag.EdgeCondition_ON_FAILURE,
+				spec.GetEdges()[1].GetCondition(),
+			)
+		}).
+		Return(&dagsvc.SubmitDagResponse{
+			Id: &dag.DagID{Value: testDagID},
+		}, nil)
+
+	suite.NoError(suite.client.DagSubmitAction(testCronRespool, testDagSpec))
+}
+
+// TestDagSubmitActionFail tests failures to submit a DAG
+func (suite *dagActionsTestSuite) TestDagSubmitActionFail() {
+	// resource pool not found
+	suite.resClient.EXPECT().
+		LookupResourcePoolID(gomock.Any(), gomock.Any()).
+		Return(&respool.LookupResponse{}, nil)
+	suite.Error(suite.client.DagSubmitAction(testCronRespool, testDagSpec))
+
+	// spec file not found
+	suite.resClient.EXPECT().
+		LookupResourcePoolID(gomock.Any(), gomock.Any()).
+		Return(&respool.LookupResponse{
+			Id: &peloton.ResourcePoolID{Value: "respool1"},
+		}, nil).
+		Times(2)
+	suite.Error(suite.client.DagSubmitAction(
+		testCronRespool, "does-not-exist.yaml"))
+
+	// invalid DAG
+	suite.dagClient.EXPECT().
+		SubmitDag(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.InvalidArgumentErrorf("DAG has a cycle"))
+	suite.Error(suite.client.DagSubmitAction(testCronRespool, testDagSpec))
+}
+
+// TestDagGetAction tests getting a DAG and the status of its nodes
+func (suite *dagActionsTestSuite) TestDagGetAction() {
+	suite.dagClient.EXPECT().
+		GetDag(gomock.Any(), &dagsvc.GetDagRequest{
+			Id: &dag.DagID{Value: testDagID},
+		}).
+		Return(&dagsvc.GetDagResponse{Info: suite.testInfo()}, nil).
+		Times(2)
+
+	suite.NoError(suite.client.DagGetAction(testDagID))
+
+	suite.client.Debug = true
+	suite.NoError(suite.client.DagGetAction(testDagID))
+
+	suite.dagClient.EXPECT().
+		GetDag(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf("DAG not found"))
+	suite.Error(suite.client.DagGetAction(testDagID))
+}
+
+// TestDagListAction tests listing the DAGs
+func (suite *dagActionsTestSuite) TestDagListAction() {
+	suite.dagClient.EXPECT().
+		ListDags(gomock.Any(), &dagsvc.ListDagsRequest{}).
+		Return(&dagsvc.ListDagsResponse{
+			Infos: []*dag.DagInfo{suite.testInfo()},
+		}, nil)
+	suite.NoError(suite.client.DagListAction())
+
+	suite.dagClient.EXPECT().
+		ListDags(gomock.Any(), gomock.Any()).
+		Return(&dagsvc.ListDagsResponse{}, nil)
+	suite.NoError(suite.client.DagListAction())
+
+	suite.dagClient.EXPECT().
+		ListDags(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("test error"))
+	suite.Error(suite.client.DagListAction())
+}
+
+// TestDagCancelAction tests canceling a DAG
+func (suite *dagActionsTestSuite) TestDagCancelAction() {
+	suite.dagClient.EXPECT().
+		CancelDag(gomock.Any(), &dagsvc.CancelDagRequest{
+			Id: &dag.DagID{Value: testDagID},
+		}).
+		Return(&dagsvc.CancelDagResponse{}, nil)
+	suite.NoError(suite.client.DagCancelAction(testDagID))
+
+	suite.dagClient.EXPECT().
+		CancelDag(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.FailedPreconditionErrorf("DAG is done"))
+	suite.Error(suite.client.DagCancelAction(testDagID))
+}
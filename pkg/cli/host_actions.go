@@ -16,12 +16,14 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 
 	host "github.com/uber/peloton/.gen/peloton/api/v0/host"
 	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
 	pb_task "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	v1alpha_host_svc "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 
 	"github.com/uber/peloton/pkg/hostmgr/scalar"
@@ -33,6 +35,9 @@ const (
 	hostSeparator         = ","
 	getHostsFormatHeader  = "Hostname\tCPU\tGPU\tMEM\tDisk\tState\t\n"
 	getHostsFormatBody    = "%s\t%.2f\t%.2f\t%.2f MB\t%.2f MB\t%s\t\n"
+
+	maintenanceWindowFormatHeader = "ID\tHosts\tStart Time\tDeadline\tState\tOverdue Hosts\n"
+	maintenanceWindowFormatBody   = "%s\t%s\t%s\t%s\t%s\t%s\n"
 )
 
 // HostMaintenanceStartAction is the action for starting host maintenance. StartMaintenance puts the host(s)
@@ -82,6 +87,65 @@ func (c *Client) HostMaintenanceCompleteAction(hosts string) error {
 	return nil
 }
 
+// HostMaintenanceScheduleAction is the action for scheduling host maintenance.
+// The host(s) start draining when the maintenance window opens at startTime,
+// and the hosts which are not DOWN at the deadline are reported in the window.
+// The times are in RFC3339 format.
+func (c *Client) HostMaintenanceScheduleAction(
+	hosts string,
+	startTime string,
+	deadline string) error {
+	hostnames, err := c.ExtractHostnames(hosts, hostSeparator)
+	if err != nil {
+		return err
+	}
+
+	request := &host_svc.ScheduleMaintenanceRequest{
+		Hostnames: hostnames,
+		StartTime: startTime,
+		Deadline:  deadline,
+	}
+	response, err := c.hostClient.ScheduleMaintenance(c.ctx, request)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(tabWriter, "Maintenance window %s scheduled\n",
+		response.GetWindowId())
+	tabWriter.Flush()
+	return nil
+}
+
+// HostMaintenanceWindowsAction is the action for listing the host
+// maintenance windows
+func (c *Client) HostMaintenanceWindowsAction() error {
+	response, err := c.hostClient.GetMaintenanceWindows(
+		c.ctx,
+		&host_svc.GetMaintenanceWindowsRequest{})
+	if err != nil {
+		return err
+	}
+
+	printMaintenanceWindowsResponse(response, c.Debug)
+	return nil
+}
+
+// HostMaintenanceCancelAction is the action for cancelling a host maintenance
+// window. Hosts of a window which has already opened stay in maintenance.
+func (c *Client) HostMaintenanceCancelAction(windowID string) error {
+	request := &host_svc.CancelMaintenanceWindowRequest{
+		WindowId: windowID,
+	}
+	_, err := c.hostClient.CancelMaintenanceWindow(c.ctx, request)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(tabWriter, "Maintenance window %s cancelled\n", windowID)
+	tabWriter.Flush()
+	return nil
+}
+
 // HostQueryAction is the action for querying hosts by states. This can be to used to monitor the state of the host(s)
 // Eg. When a list of hosts are put into maintenance (`host maintenance start`).
 // A host, at any given time, will be in one of the following states
@@ -110,6 +174,51 @@ func (c *Client) HostQueryAction(states string) error {
 	return nil
 }
 
+// HostWatchAction is the action for watching the state transitions of hosts,
+// e.g. to follow the progress of hosts put into maintenance. All the hosts are
+// watched if no host is specified. The changes are printed till the stream ends.
+func (c *Client) HostWatchAction(hosts string) error {
+	var hostnames []string
+	var err error
+
+	if len(hosts) > 0 {
+		hostnames, err = c.ExtractHostnames(hosts, hostSeparator)
+		if err != nil {
+			return err
+		}
+	}
+
+	stream, err := c.hostWatchClient.WatchHosts(
+		c.ctx,
+		&v1alpha_host_svc.WatchHostsRequest{
+			Hostnames: hostnames,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			fmt.Println("watch stream has ended")
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		out, err := marshallResponse(defaultResponseFormat, resp)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("%v\n", string(out))
+		tabWriter.Flush()
+	}
+}
+
 func printHostQueryResponse(r *host_svc.QueryHostsResponse, debug bool) {
 	if debug {
 		printResponseJSON(r)
@@ -132,6 +241,33 @@ func printHostQueryResponse(r *host_svc.QueryHostsResponse, debug bool) {
 	tabWriter.Flush()
 }
 
+func printMaintenanceWindowsResponse(
+	r *host_svc.GetMaintenanceWindowsResponse,
+	debug bool) {
+	if debug {
+		printResponseJSON(r)
+	} else {
+		if len(r.GetWindows()) == 0 {
+			fmt.Fprintf(tabWriter, "No maintenance windows found\n")
+			return
+		}
+		fmt.Fprintf(tabWriter, maintenanceWindowFormatHeader)
+		for _, w := range r.GetWindows() {
+			fmt.Fprintf(
+				tabWriter,
+				maintenanceWindowFormatBody,
+				w.GetId(),
+				strings.Join(w.GetHostnames(), hostSeparator),
+				w.GetStartTime(),
+				w.GetDeadline(),
+				w.GetState(),
+				strings.Join(w.GetOverdueHosts(), hostSeparator),
+			)
+		}
+	}
+	tabWriter.Flush()
+}
+
 // HostsGetAction prints all the hosts based on resource requirement
 // passed in.
 func (c *Client) HostsGetAction(
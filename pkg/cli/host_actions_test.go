@@ -17,6 +17,7 @@ package cli
 import (
 	"context"
 	"fmt"
+	"io"
 	"testing"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
@@ -24,6 +25,9 @@ import (
 	hostsvc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
 	hostmocks "github.com/uber/peloton/.gen/peloton/api/v0/host/svc/mocks"
 	pb_task "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	v1alphahost "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	v1alphahostsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc"
+	v1alphahostmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc/mocks"
 	hostmgrsvc "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	hostmgrMocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
 
@@ -128,6 +132,103 @@ func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceCompleteAction()
 	suite.Error(err)
 }
 
+func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceScheduleAction() {
+	c := Client{
+		Debug:      false,
+		hostClient: suite.mockHostmgr,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+
+	startTime := "2019-01-01T10:00:00Z"
+	deadline := "2019-01-01T12:00:00Z"
+
+	suite.mockHostmgr.EXPECT().
+		ScheduleMaintenance(gomock.Any(), &hostsvc.ScheduleMaintenanceRequest{
+			Hostnames: []string{"host1", "host2"},
+			StartTime: startTime,
+			Deadline:  deadline,
+		}).
+		Return(&hostsvc.ScheduleMaintenanceResponse{WindowId: "window1"}, nil)
+	err := c.HostMaintenanceScheduleAction("host1,host2", startTime, deadline)
+	suite.NoError(err)
+
+	// Test ScheduleMaintenance error
+	suite.mockHostmgr.EXPECT().
+		ScheduleMaintenance(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake ScheduleMaintenance error"))
+	err = c.HostMaintenanceScheduleAction("host1", startTime, deadline)
+	suite.Error(err)
+
+	// Test empty hostname error
+	err = c.HostMaintenanceScheduleAction("", startTime, deadline)
+	suite.Error(err)
+}
+
+func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceWindowsAction() {
+	c := Client{
+		Debug:      false,
+		hostClient: suite.mockHostmgr,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+
+	resp := &hostsvc.GetMaintenanceWindowsResponse{
+		Windows: []*host.MaintenanceWindow{
+			{
+				Id:           "window1",
+				Hostnames:    []string{"host1", "host2"},
+				StartTime:    "2019-01-01T10:00:00Z",
+				Deadline:     "2019-01-01T12:00:00Z",
+				State:        host.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+				OverdueHosts: []string{"host2"},
+			},
+		},
+	}
+
+	for _, debug := range []bool{false, true} {
+		c.Debug = debug
+		suite.mockHostmgr.EXPECT().
+			GetMaintenanceWindows(gomock.Any(), gomock.Any()).
+			Return(resp, nil)
+		suite.NoError(c.HostMaintenanceWindowsAction())
+	}
+
+	// Test no maintenance windows
+	suite.mockHostmgr.EXPECT().
+		GetMaintenanceWindows(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetMaintenanceWindowsResponse{}, nil)
+	suite.NoError(c.HostMaintenanceWindowsAction())
+
+	// Test GetMaintenanceWindows error
+	suite.mockHostmgr.EXPECT().
+		GetMaintenanceWindows(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake GetMaintenanceWindows error"))
+	suite.Error(c.HostMaintenanceWindowsAction())
+}
+
+func (suite *hostmgrActionsTestSuite) TestClientHostMaintenanceCancelAction() {
+	c := Client{
+		Debug:      false,
+		hostClient: suite.mockHostmgr,
+		dispatcher: nil,
+		ctx:        suite.ctx,
+	}
+
+	suite.mockHostmgr.EXPECT().
+		CancelMaintenanceWindow(gomock.Any(), &hostsvc.CancelMaintenanceWindowRequest{
+			WindowId: "window1",
+		}).
+		Return(&hostsvc.CancelMaintenanceWindowResponse{}, nil)
+	suite.NoError(c.HostMaintenanceCancelAction("window1"))
+
+	// Test CancelMaintenanceWindow error
+	suite.mockHostmgr.EXPECT().
+		CancelMaintenanceWindow(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake CancelMaintenanceWindow error"))
+	suite.Error(c.HostMaintenanceCancelAction("window1"))
+}
+
 func (suite *hostmgrActionsTestSuite) TestClientHostQueryAction() {
 	c := Client{
 		Debug:      false,
@@ -179,6 +280,61 @@ func (suite *hostmgrActionsTestSuite) TestClientHostQueryAction() {
 	}
 }
 
+func (suite *hostmgrActionsTestSuite) TestClientHostWatchAction() {
+	hostWatchClient := v1alphahostmocks.NewMockHostServiceYARPCClient(suite.mockCtrl)
+	stream := v1alphahostmocks.NewMockHostServiceServiceWatchHostsYARPCClient(suite.mockCtrl)
+	c := Client{
+		Debug:           false,
+		hostWatchClient: hostWatchClient,
+		dispatcher:      nil,
+		ctx:             suite.ctx,
+	}
+
+	resps := []*v1alphahostsvc.WatchHostsResponse{
+		{
+			WatchId: "watch-id",
+		},
+		{
+			WatchId: "watch-id",
+			Changes: []*v1alphahost.HostStateChange{
+				{
+					HostInfo: &v1alphahost.HostInfo{
+						Hostname: "host1",
+						State:    v1alphahost.HostState_HOST_STATE_DRAINING,
+					},
+					PrevState: v1alphahost.HostState_HOST_STATE_UP,
+				},
+			},
+		},
+	}
+
+	hostWatchClient.EXPECT().
+		WatchHosts(gomock.Any(), &v1alphahostsvc.WatchHostsRequest{
+			Hostnames: []string{"host1", "host2"},
+		}).
+		Return(stream, nil)
+
+	var calls []*gomock.Call
+	for _, resp := range resps {
+		calls = append(calls, stream.EXPECT().Recv().Return(resp, nil))
+	}
+	calls = append(calls, stream.EXPECT().Recv().Return(nil, io.EOF))
+	gomock.InOrder(calls...)
+
+	suite.NoError(c.HostWatchAction("host2,host1"))
+
+	// error on the watch stream is returned
+	hostWatchClient.EXPECT().
+		WatchHosts(gomock.Any(), &v1alphahostsvc.WatchHostsRequest{}).
+		Return(stream, nil)
+	stream.EXPECT().Recv().Return(nil, fmt.Errorf("fake Recv error"))
+
+	suite.Error(c.HostWatchAction(""))
+
+	// invalid hostnames
+	suite.Error(c.HostWatchAction("host1,host1"))
+}
+
 type hostmgrActionsInternalTestSuite struct {
 	suite.Suite
 	mockCtrl    *gomock.Controller
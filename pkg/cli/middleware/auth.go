@@ -22,17 +22,22 @@ import (
 )
 
 const (
-	_usernameHeader = "username"
-	_passwordHeader = "password"
+	_usernameHeader      = "username"
+	_passwordHeader      = "password"
+	_authorizationHeader = "authorization"
+	_bearerPrefix        = "Bearer "
 )
 
-type outboundMiddleware interface {
+// OutboundMiddleware is an outbound middleware for
+// unary, oneway and stream requests
+type OutboundMiddleware interface {
 	middleware.UnaryOutbound
 	middleware.OnewayOutbound
 	middleware.StreamOutbound
 }
 
-var _ outboundMiddleware = &BasicAuthOutboundMiddleware{}
+var _ OutboundMiddleware = &BasicAuthOutboundMiddleware{}
+var _ OutboundMiddleware = &BearerAuthOutboundMiddleware{}
 
 // BasicAuthConfig is the config for basic auth
 type BasicAuthConfig struct {
@@ -85,3 +90,42 @@ func (m *BasicAuthOutboundMiddleware) addAuthToHeader(headers transport.Headers)
 
 	return headers
 }
+
+// BearerAuthOutboundMiddleware provides bearer token auth,
+// such as JWT, support for all outbound requests
+type BearerAuthOutboundMiddleware struct {
+	token string
+}
+
+// NewBearerAuthOutboundMiddleware creates BearerAuthOutboundMiddleware
+func NewBearerAuthOutboundMiddleware(token string) *BearerAuthOutboundMiddleware {
+	return &BearerAuthOutboundMiddleware{
+		token: token,
+	}
+}
+
+// Call adds auth info to yarpc request header and relay the request
+func (m *BearerAuthOutboundMiddleware) Call(ctx context.Context, request *transport.Request, out transport.UnaryOutbound) (*transport.Response, error) {
+	request.Headers = m.addAuthToHeader(request.Headers)
+	return out.Call(ctx, request)
+}
+
+// CallOneway adds auth info to yarpc request header and relay the request
+func (m *BearerAuthOutboundMiddleware) CallOneway(ctx context.Context, request *transport.Request, out transport.OnewayOutbound) (transport.Ack, error) {
+	request.Headers = m.addAuthToHeader(request.Headers)
+	return out.CallOneway(ctx, request)
+}
+
+// CallStream adds auth info to yarpc request header and relay the request
+func (m *BearerAuthOutboundMiddleware) CallStream(ctx context.Context, request *transport.StreamRequest, out transport.StreamOutbound) (*transport.ClientStream, error) {
+	request.Meta.Headers = m.addAuthToHeader(request.Meta.Headers)
+	return out.CallStream(ctx, request)
+}
+
+func (m *BearerAuthOutboundMiddleware) addAuthToHeader(headers transport.Headers) transport.Headers {
+	if len(m.token) == 0 {
+		return headers
+	}
+
+	return headers.With(_authorizationHeader, _bearerPrefix+m.token)
+}
@@ -0,0 +1,282 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	hostmgr_svc "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/common/util"
+	taskutil "github.com/uber/peloton/pkg/common/util/task"
+	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/plugins"
+	"github.com/uber/peloton/pkg/placement/plugins/batch"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/generation"
+	"github.com/uber/peloton/pkg/placement/simulator"
+)
+
+const (
+	placementReportFormatHeader = "Tasks\tPlaced\tRate\tRounds\tHosts\t" +
+		"Free Hosts\tFragmentation\t\n"
+	placementReportFormatBody    = "%d\t%d\t%.3f\t%d\t%d\t%d\t%.3f\t\n"
+	placementFailureFormatHeader = "Unplaced\tConstraint\t\n"
+	placementFailureFormatBody   = "%d\t%s\t\n"
+
+	// _simulatorOfferDequeueLimit is the offer dequeue limit of the mimir
+	// strategy when simulating, same as the default of the engine.
+	_simulatorOfferDequeueLimit = 10
+)
+
+// PlacementSnapshotAction captures the hosts, their outstanding offers and
+// running tasks from host manager and resource manager, along with the
+// pending gangs of a resource pool if given, and writes them to a file.
+func (c *Client) PlacementSnapshotAction(
+	snapshotFile string,
+	taskType string,
+	respoolID string,
+	limit uint32) error {
+	tt, err := parseTaskType(taskType)
+	if err != nil {
+		return err
+	}
+
+	hostsResponse, err := c.hostMgrClient.GetHosts(
+		c.ctx,
+		&hostmgr_svc.GetHostsRequest{Filter: &hostmgr_svc.HostFilter{}})
+	if err != nil {
+		return err
+	}
+	if hostsResponse.GetError() != nil {
+		return errors.New(hostsResponse.GetError().String())
+	}
+
+	offersResponse, err := c.hostMgrClient.GetOutstandingOffers(
+		c.ctx,
+		&hostmgr_svc.GetOutstandingOffersRequest{})
+	if err != nil {
+		return err
+	}
+
+	var hostnames []string
+	for _, host := range hostsResponse.GetHosts() {
+		hostnames = append(hostnames, host.GetHostname())
+	}
+	tasksResponse, err := c.resMgrClient.GetTasksByHosts(
+		c.ctx,
+		&resmgrsvc.GetTasksByHostsRequest{
+			Type:      tt,
+			Hostnames: hostnames,
+		})
+	if err != nil {
+		return err
+	}
+
+	snapshot := &simulator.Snapshot{}
+	for _, host := range hostsResponse.GetHosts() {
+		offer := &hostmgr_svc.HostOffer{
+			Hostname:   host.GetHostname(),
+			AgentId:    host.GetAgentId(),
+			Attributes: host.GetAttributes(),
+		}
+		// The outstanding offers of the host are its free resources.
+		for _, o := range offersResponse.GetOffers() {
+			if o.GetHostname() == host.GetHostname() {
+				offer.Resources = append(offer.Resources, o.GetResources()...)
+			}
+		}
+		snapshot.Hosts = append(snapshot.Hosts, &simulator.Host{
+			Offer: offer,
+			Tasks: tasksResponse.GetHostTasksMap()[host.GetHostname()].GetTasks(),
+		})
+	}
+
+	if len(respoolID) > 0 {
+		snapshot.Gangs, err = c.getPendingGangs(respoolID, limit)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := snapshot.Save(snapshotFile); err != nil {
+		return err
+	}
+	fmt.Printf("Saved %d hosts and %d pending tasks to %s\n",
+		len(snapshot.Hosts), snapshot.NumTasks(), snapshotFile)
+	return nil
+}
+
+// getPendingGangs returns the pending gangs of the resource pool, converting
+// the tasks from job manager the same way as when they are enqueued.
+func (c *Client) getPendingGangs(
+	respoolID string,
+	limit uint32) ([]*resmgrsvc.Gang, error) {
+	pendingResponse, err := c.resMgrClient.GetPendingTasks(
+		c.ctx,
+		&resmgrsvc.GetPendingTasksRequest{
+			RespoolID: &peloton.ResourcePoolID{Value: respoolID},
+			Limit:     limit,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	var queues []string
+	for queue := range pendingResponse.GetPendingGangsByQueue() {
+		queues = append(queues, queue)
+	}
+	sort.Strings(queues)
+
+	jobConfigs := map[string]*job.JobConfig{}
+	var gangs []*resmgrsvc.Gang
+	for _, queue := range queues {
+		pendingGangs := pendingResponse.GetPendingGangsByQueue()[queue]
+		for _, pendingGang := range pendingGangs.GetPendingGangs() {
+			gang := &resmgrsvc.Gang{}
+			for _, taskID := range pendingGang.GetTaskIDs() {
+				jobID, instanceID, err := util.ParseTaskID(taskID)
+				if err != nil {
+					return nil, err
+				}
+				jobConfig, ok := jobConfigs[jobID]
+				if !ok {
+					jobResponse, err := c.jobGet(jobID)
+					if err != nil {
+						return nil, err
+					}
+					jobConfig = jobResponse.GetJobInfo().GetConfig()
+					jobConfigs[jobID] = jobConfig
+				}
+				taskResponse, err := c.taskClient.Get(c.ctx, &task.GetRequest{
+					JobId:      &peloton.JobID{Value: jobID},
+					InstanceId: instanceID,
+				})
+				if err != nil {
+					return nil, err
+				}
+				gang.Tasks = append(gang.Tasks, taskutil.ConvertTaskToResMgrTask(
+					taskResponse.GetResult(), jobConfig))
+			}
+			gangs = append(gangs, gang)
+		}
+	}
+	return gangs, nil
+}
+
+// PlacementSimulateAction runs a placement strategy offline on a snapshot,
+// either read from snapshotFile or generated if no file is given, and
+// prints the placement rate, fragmentation and failures per constraint.
+func (c *Client) PlacementSimulateAction(
+	snapshotFile string,
+	strategyName string,
+	taskType string,
+	rounds int,
+	hosts int,
+	gangs int,
+	seed int64) error {
+	tt, err := parseTaskType(taskType)
+	if err != nil {
+		return err
+	}
+	strategy, err := newPlacementStrategy(strategyName, tt)
+	if err != nil {
+		return err
+	}
+
+	var snapshot *simulator.Snapshot
+	if len(snapshotFile) > 0 {
+		snapshot, err = simulator.LoadSnapshot(snapshotFile)
+		if err != nil {
+			return err
+		}
+	} else {
+		snapshot = simulator.Generate(
+			simulator.NewGeneratorConfig(hosts, gangs, tt),
+			generation.NewRandom(seed))
+	}
+
+	printPlacementReport(simulator.Simulate(strategy, snapshot, rounds), c.Debug)
+	return nil
+}
+
+func parseTaskType(taskType string) (resmgr.TaskType, error) {
+	tt, ok := resmgr.TaskType_value[strings.ToUpper(taskType)]
+	if !ok {
+		return resmgr.TaskType_UNKNOWN,
+			fmt.Errorf("invalid task type %s", taskType)
+	}
+	return resmgr.TaskType(tt), nil
+}
+
+func newPlacementStrategy(
+	strategyName string,
+	taskType resmgr.TaskType) (plugins.Strategy, error) {
+	switch config.PlacementStrategy(strategyName) {
+	case config.Batch:
+		return batch.New(), nil
+	case config.Mimir:
+		return mimir.New(
+			algorithms.NewPlacer(4, 300),
+			&config.PlacementConfig{
+				TaskType:          taskType,
+				OfferDequeueLimit: _simulatorOfferDequeueLimit,
+			}), nil
+	}
+	return nil, fmt.Errorf("invalid placement strategy %s", strategyName)
+}
+
+func printPlacementReport(report *simulator.Report, debug bool) {
+	if debug {
+		printResponseJSON(report)
+		return
+	}
+	fmt.Fprint(tabWriter, placementReportFormatHeader)
+	fmt.Fprintf(
+		tabWriter,
+		placementReportFormatBody,
+		report.Tasks,
+		report.Placed,
+		report.PlacementRate,
+		report.Rounds,
+		report.Hosts,
+		report.FreeHosts,
+		report.Fragmentation)
+	if len(report.Failures) > 0 {
+		var keys []string
+		for key := range report.Failures {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Fprint(tabWriter, placementFailureFormatHeader)
+		for _, key := range keys {
+			fmt.Fprintf(
+				tabWriter,
+				placementFailureFormatBody,
+				report.Failures[key],
+				key)
+		}
+	}
+	tabWriter.Flush()
+}
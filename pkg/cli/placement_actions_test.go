@@ -0,0 +1,212 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	jobmocks "github.com/uber/peloton/.gen/peloton/api/v0/job/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	taskmocks "github.com/uber/peloton/.gen/peloton/api/v0/task/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	hostmgrMocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+	resmocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
+
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/generation"
+	"github.com/uber/peloton/pkg/placement/simulator"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type placementActionsTestSuite struct {
+	suite.Suite
+	ctx         context.Context
+	mockCtrl    *gomock.Controller
+	mockHostMgr *hostmgrMocks.MockInternalHostServiceYARPCClient
+	mockResMgr  *resmocks.MockResourceManagerServiceYARPCClient
+	mockJob     *jobmocks.MockJobManagerYARPCClient
+	mockTask    *taskmocks.MockTaskManagerYARPCClient
+	client      Client
+	dir         string
+}
+
+func TestPlacementActions(t *testing.T) {
+	suite.Run(t, new(placementActionsTestSuite))
+}
+
+func (suite *placementActionsTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockHostMgr = hostmgrMocks.NewMockInternalHostServiceYARPCClient(suite.mockCtrl)
+	suite.mockResMgr = resmocks.NewMockResourceManagerServiceYARPCClient(suite.mockCtrl)
+	suite.mockJob = jobmocks.NewMockJobManagerYARPCClient(suite.mockCtrl)
+	suite.mockTask = taskmocks.NewMockTaskManagerYARPCClient(suite.mockCtrl)
+	suite.client = Client{
+		hostMgrClient: suite.mockHostMgr,
+		resMgrClient:  suite.mockResMgr,
+		jobClient:     suite.mockJob,
+		taskClient:    suite.mockTask,
+		ctx:           suite.ctx,
+	}
+	dir, err := ioutil.TempDir("", "placement")
+	suite.NoError(err)
+	suite.dir = dir
+}
+
+func (suite *placementActionsTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+	os.RemoveAll(suite.dir)
+}
+
+// TestPlacementSnapshotAction tests capturing hosts, their offers, their
+// running tasks and the pending gangs of a resource pool
+func (suite *placementActionsTestSuite) TestPlacementSnapshotAction() {
+	path := filepath.Join(suite.dir, "snapshot.json")
+	hostname := "host-1"
+	jobID := uuid.New()
+	taskID := jobID + "-0"
+	running := &resmgr.Task{Id: &peloton.TaskID{Value: jobID + "-1"}}
+
+	suite.mockHostMgr.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostsResponse{
+			Hosts: []*hostsvc.HostInfo{{Hostname: hostname}},
+		}, nil)
+	suite.mockHostMgr.EXPECT().
+		GetOutstandingOffers(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetOutstandingOffersResponse{
+			Offers: []*mesos.Offer{
+				{
+					Hostname: &hostname,
+					Resources: util.CreateMesosScalarResources(
+						map[string]float64{"cpus": 4}, "*"),
+				},
+			},
+		}, nil)
+	suite.mockResMgr.EXPECT().
+		GetTasksByHosts(gomock.Any(), &resmgrsvc.GetTasksByHostsRequest{
+			Type:      resmgr.TaskType_BATCH,
+			Hostnames: []string{hostname},
+		}).
+		Return(&resmgrsvc.GetTasksByHostsResponse{
+			HostTasksMap: map[string]*resmgrsvc.TaskList{
+				hostname: {Tasks: []*resmgr.Task{running}},
+			},
+		}, nil)
+	suite.mockResMgr.EXPECT().
+		GetPendingTasks(gomock.Any(), gomock.Any()).
+		Return(&resmgrsvc.GetPendingTasksResponse{
+			PendingGangsByQueue: map[string]*resmgrsvc.GetPendingTasksResponse_PendingGangs{
+				"pending": {
+					PendingGangs: []*resmgrsvc.GetPendingTasksResponse_PendingGang{
+						{TaskIDs: []string{taskID}},
+					},
+				},
+			},
+		}, nil)
+	suite.mockJob.EXPECT().
+		Get(gomock.Any(), gomock.Any()).
+		Return(&job.GetResponse{
+			JobInfo: &job.JobInfo{
+				Config: &job.JobConfig{Type: job.JobType_BATCH},
+			},
+		}, nil)
+	suite.mockTask.EXPECT().
+		Get(gomock.Any(), &task.GetRequest{
+			JobId:      &peloton.JobID{Value: jobID},
+			InstanceId: 0,
+		}).
+		Return(&task.GetResponse{
+			Result: &task.TaskInfo{
+				JobId:  &peloton.JobID{Value: jobID},
+				Config: &task.TaskConfig{Resource: &task.ResourceConfig{CpuLimit: 1}},
+			},
+		}, nil)
+
+	suite.NoError(suite.client.PlacementSnapshotAction(path, "batch", "respool", 10))
+
+	snapshot, err := simulator.LoadSnapshot(path)
+	suite.NoError(err)
+	suite.Len(snapshot.Hosts, 1)
+	suite.Equal(hostname, snapshot.Hosts[0].Offer.GetHostname())
+	suite.Len(snapshot.Hosts[0].Offer.GetResources(), 1)
+	suite.Equal(running, snapshot.Hosts[0].Tasks[0])
+	suite.Len(snapshot.Gangs, 1)
+	suite.Equal(taskID, snapshot.Gangs[0].GetTasks()[0].GetId().GetValue())
+	suite.Equal(1.0, snapshot.Gangs[0].GetTasks()[0].GetResource().GetCpuLimit())
+}
+
+// TestPlacementSnapshotActionErrors tests errors when capturing a snapshot
+func (suite *placementActionsTestSuite) TestPlacementSnapshotActionErrors() {
+	path := filepath.Join(suite.dir, "snapshot.json")
+
+	suite.Error(suite.client.PlacementSnapshotAction(path, "invalid", "", 0))
+
+	suite.mockHostMgr.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("error"))
+	suite.Error(suite.client.PlacementSnapshotAction(path, "batch", "", 0))
+
+	suite.mockHostMgr.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetHostsResponse{
+			Error: &hostsvc.GetHostsResponse_Error{
+				InvalidHostFilter: &hostsvc.InvalidHostFilter{},
+			},
+		}, nil)
+	suite.Error(suite.client.PlacementSnapshotAction(path, "batch", "", 0))
+}
+
+// TestPlacementSimulateAction tests simulating a generated and a saved
+// snapshot with both strategies
+func (suite *placementActionsTestSuite) TestPlacementSimulateAction() {
+	suite.NoError(suite.client.PlacementSimulateAction(
+		"", "batch", "batch", 3, 10, 20, 1))
+
+	suite.client.Debug = true
+	suite.NoError(suite.client.PlacementSimulateAction(
+		"", "mimir", "stateless", 3, 10, 20, 1))
+
+	path := filepath.Join(suite.dir, "snapshot.json")
+	suite.NoError(simulator.Generate(
+		simulator.NewGeneratorConfig(2, 2, resmgr.TaskType_BATCH),
+		generation.NewRandom(1),
+	).Save(path))
+	suite.NoError(suite.client.PlacementSimulateAction(
+		path, "batch", "batch", 3, 0, 0, 0))
+}
+
+// TestPlacementSimulateActionErrors tests errors when simulating
+func (suite *placementActionsTestSuite) TestPlacementSimulateActionErrors() {
+	suite.Error(suite.client.PlacementSimulateAction(
+		"", "invalid", "batch", 3, 10, 20, 1))
+	suite.Error(suite.client.PlacementSimulateAction(
+		"", "batch", "invalid", 3, 10, 20, 1))
+	suite.Error(suite.client.PlacementSimulateAction(
+		filepath.Join(suite.dir, "missing.json"), "batch", "batch", 3, 0, 0, 0))
+}
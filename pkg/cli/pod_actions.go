@@ -169,6 +169,53 @@ func printPodGetEventsV1AlphaResponse(r *podsvc.GetPodEventsResponse, debug bool
 	}
 }
 
+// PodGetPendingReasonAction is the action for getting the reason why a pod
+// is pending
+func (c *Client) PodGetPendingReasonAction(podName string) error {
+	resp, err := c.podClient.GetPodPendingReason(
+		c.ctx,
+		&podsvc.GetPodPendingReasonRequest{
+			PodName: &v1alphapeloton.PodName{Value: podName},
+		})
+	if err != nil {
+		return err
+	}
+
+	printPodGetPendingReasonResponse(resp, c.Debug)
+	return nil
+}
+
+func printPodGetPendingReasonResponse(
+	r *podsvc.GetPodPendingReasonResponse,
+	debug bool) {
+	defer tabWriter.Flush()
+
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+
+	reason := r.GetReason()
+	fmt.Fprintf(tabWriter, "State:\t%s\n", reason.GetState())
+	fmt.Fprintf(tabWriter, "Resource Pool:\t%s\n", reason.GetRespoolPath())
+	if reason.GetAdmissionCheck() != "" {
+		fmt.Fprintf(tabWriter, "Queue:\t%s\n", reason.GetQueue())
+		fmt.Fprintf(tabWriter, "Admission Check:\t%s\n",
+			reason.GetAdmissionCheck())
+		fmt.Fprintf(tabWriter, "Admission Message:\t%s\n",
+			reason.GetAdmissionMessage())
+		fmt.Fprintf(tabWriter, "Admission Time:\t%s\n",
+			reason.GetAdmissionTime())
+	}
+	if reason.GetPlacementMessage() != "" {
+		fmt.Fprintf(tabWriter, "Placement Message:\t%s\n",
+			reason.GetPlacementMessage())
+		fmt.Fprintf(tabWriter, "Placement Time:\t%s\n",
+			reason.GetPlacementTime())
+	}
+	fmt.Fprintf(tabWriter, "Reason:\t%s\n", reason.GetMessage())
+}
+
 // PodStartAction is the action for starting the pod
 func (c *Client) PodStartAction(podName string) error {
 	resp, err := c.podClient.StartPod(
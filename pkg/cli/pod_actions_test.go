@@ -80,6 +80,42 @@ func (suite *podActionsTestSuite) TestClientPodGetCacheFail() {
 	suite.Error(suite.client.PodGetCacheAction(testPodName))
 }
 
+// TestClientPodGetPendingReason tests getting the pending reason of a pod
+func (suite *podActionsTestSuite) TestClientPodGetPendingReason() {
+	resp := &podsvc.GetPodPendingReasonResponse{
+		Reason: &pod.PendingReason{
+			State:            pod.PodState_POD_STATE_PENDING,
+			RespoolPath:      "/respool1",
+			Queue:            "pending",
+			AdmissionCheck:   "entitlement",
+			AdmissionMessage: "allocation exceeds entitlement",
+			PlacementMessage: "no hosts",
+			Message:          "waiting for admission",
+		},
+	}
+	suite.podClient.EXPECT().
+		GetPodPendingReason(gomock.Any(), &podsvc.GetPodPendingReasonRequest{
+			PodName: &peloton.PodName{Value: testPodName},
+		}).
+		Return(resp, nil).
+		Times(2)
+
+	suite.NoError(suite.client.PodGetPendingReasonAction(testPodName))
+
+	suite.client.Debug = true
+	suite.NoError(suite.client.PodGetPendingReasonAction(testPodName))
+}
+
+// TestClientPodGetPendingReasonFail tests the failure case of getting the
+// pending reason of a pod
+func (suite *podActionsTestSuite) TestClientPodGetPendingReasonFail() {
+	suite.podClient.EXPECT().
+		GetPodPendingReason(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.NotFoundErrorf("test error"))
+
+	suite.Error(suite.client.PodGetPendingReasonAction(testPodName))
+}
+
 // TestPodGetEventsV1AlphaAction tests PodGetEventsV1AlphaAction
 func (suite *podActionsTestSuite) TestPodGetEventsV1AlphaAction() {
 	podname := &peloton.PodName{
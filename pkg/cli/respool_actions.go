@@ -23,12 +23,19 @@ import (
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 )
 
 // ResourcePoolPathDelim is the resource pool path delimiter
 const ResourcePoolPathDelim = "/"
 
+const (
+	resPoolSimulateFormatHeader = "Resource Pool\tResource\tCurrent\tSimulated\tDelta\n"
+	resPoolSimulateFormatBody   = "%s\t%s\t%.2f\t%.2f\t%+.2f\n"
+)
+
 // ResPoolCreateAction is the action for creating a resource pool
 func (c *Client) ResPoolCreateAction(respoolPath string, cfgFile string) error {
 	if respoolPath == ResourcePoolPathDelim {
@@ -80,28 +87,81 @@ func (c *Client) ResPoolUpdateAction(respoolPath string, cfgFile string) error {
 		return errors.New("cannot update root resource pool")
 	}
 
-	respoolConfig, err := readResourcePoolConfig(cfgFile)
+	respoolID, respoolConfig, err := c.resolveResPoolConfig(
+		respoolPath, cfgFile)
+	if err != nil {
+		return err
+	}
+	var request = &respool.UpdateRequest{
+		Id:     respoolID,
+		Config: respoolConfig,
+	}
+	response, err := c.resClient.UpdateResourcePool(c.ctx, request)
+	if err != nil {
+		return err
+	}
+	printResPoolUpdateResponse(response, respoolPath, c.Debug)
+	return nil
+}
+
+// ResPoolSimulateAction is the action for simulating the entitlement of
+// all resource pools if an existing resource pool is updated
+func (c *Client) ResPoolSimulateAction(respoolPath string, cfgFile string) error {
+	if respoolPath == ResourcePoolPathDelim {
+		return errors.New("cannot simulate root resource pool")
+	}
+
+	respoolID, respoolConfig, err := c.resolveResPoolConfig(
+		respoolPath, cfgFile)
+	if err != nil {
+		return err
+	}
+
+	var request = &resmgrsvc.SimulateEntitlementRequest{
+		Changes: []*resmgrsvc.SimulateEntitlementRequest_ResourcePoolConfigChange{
+			{
+				RespoolID: respoolID,
+				Config:    respoolConfig,
+			},
+		},
+	}
+	response, err := c.resMgrClient.SimulateEntitlement(c.ctx, request)
 	if err != nil {
 		return err
 	}
+	printResPoolSimulateResponse(response, c.Debug)
+	return nil
+}
+
+// resolveResPoolConfig reads the config of an existing resource pool from
+// cfgFile, sets its parent and looks up the ID of the resource pool
+func (c *Client) resolveResPoolConfig(
+	respoolPath string,
+	cfgFile string,
+) (*peloton.ResourcePoolID, *respool.ResourcePoolConfig, error) {
+	respoolConfig, err := readResourcePoolConfig(cfgFile)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	if respoolConfig.GetParent() != nil {
-		return errors.New("parent should not be supplied in the config")
+		return nil, nil, errors.New(
+			"parent should not be supplied in the config")
 	}
 
 	respoolName := parseRespoolName(respoolPath)
 	if respoolName != respoolConfig.Name {
-		return fmt.Errorf("resource pool name in path:%s and "+
+		return nil, nil, fmt.Errorf("resource pool name in path:%s and "+
 			"config:%s don't match", respoolName, respoolConfig.Name)
 	}
 
 	parentPath := parseParentPath(respoolPath)
 	parentID, err := c.LookupResourcePoolID(parentPath)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	if parentID == nil {
-		return errors.Errorf("unable to find resource pool ID "+
+		return nil, nil, errors.Errorf("unable to find resource pool ID "+
 			"for parent:%s", parentPath)
 	}
 	// set parent ID
@@ -109,21 +169,12 @@ func (c *Client) ResPoolUpdateAction(respoolPath string, cfgFile string) error {
 
 	respoolID, err := c.LookupResourcePoolID(respoolPath)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	if respoolID == nil {
-		return errors.Errorf("unable to lookup resource pool ID")
+		return nil, nil, errors.Errorf("unable to lookup resource pool ID")
 	}
-	var request = &respool.UpdateRequest{
-		Id:     respoolID,
-		Config: &respoolConfig,
-	}
-	response, err := c.resClient.UpdateResourcePool(c.ctx, request)
-	if err != nil {
-		return err
-	}
-	printResPoolUpdateResponse(response, respoolPath, c.Debug)
-	return nil
+	return respoolID, &respoolConfig, nil
 }
 
 // ResPoolDeleteAction is the action for deleting a resource pool
@@ -305,3 +356,28 @@ func printResPoolDeleteResponse(r *respool.DeleteResponse, respoolPath string, d
 		tabWriter.Flush()
 	}
 }
+
+func printResPoolSimulateResponse(
+	r *resmgrsvc.SimulateEntitlementResponse,
+	debug bool) {
+	if debug {
+		printResponseJSON(r)
+		return
+	}
+
+	fmt.Fprint(tabWriter, resPoolSimulateFormatHeader)
+	for _, e := range r.GetEntitlements() {
+		for _, res := range e.GetResources() {
+			fmt.Fprintf(
+				tabWriter,
+				resPoolSimulateFormatBody,
+				e.GetPath(),
+				res.GetKind(),
+				res.GetCurrent(),
+				res.GetSimulated(),
+				res.GetSimulated()-res.GetCurrent(),
+			)
+		}
+	}
+	tabWriter.Flush()
+}
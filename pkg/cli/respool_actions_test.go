@@ -20,9 +20,11 @@ import (
 	"testing"
 
 	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	resmocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
@@ -37,12 +39,14 @@ type resPoolActions struct {
 	suite.Suite
 	mockCtrl    *gomock.Controller
 	mockRespool *respoolmocks.MockResourceManagerYARPCClient
+	mockResMgr  *resmocks.MockResourceManagerServiceYARPCClient
 	ctx         context.Context
 }
 
 func (suite *resPoolActions) SetupSuite() {
 	suite.mockCtrl = gomock.NewController(suite.T())
 	suite.mockRespool = respoolmocks.NewMockResourceManagerYARPCClient(suite.mockCtrl)
+	suite.mockResMgr = resmocks.NewMockResourceManagerServiceYARPCClient(suite.mockCtrl)
 	suite.ctx = context.Background()
 }
 
@@ -753,6 +757,91 @@ func (suite *resPoolActions) TestClientResPoolUpdateParentErrors() {
 	}
 }
 
+func (suite *resPoolActions) TestClientResPoolSimulateAction() {
+	c := Client{
+		Debug:        false,
+		resClient:    suite.mockRespool,
+		resMgrClient: suite.mockResMgr,
+		dispatcher:   nil,
+		ctx:          suite.ctx,
+	}
+
+	respoolID := &peloton.ResourcePoolID{Value: uuid.New()}
+	parentID := &peloton.ResourcePoolID{Value: uuid.New()}
+	path := "/DefaultResPool"
+	config := suite.getConfig()
+	config.Parent = parentID
+
+	request := &resmgrsvc.SimulateEntitlementRequest{
+		Changes: []*resmgrsvc.SimulateEntitlementRequest_ResourcePoolConfigChange{
+			{
+				RespoolID: respoolID,
+				Config:    config,
+			},
+		},
+	}
+	response := &resmgrsvc.SimulateEntitlementResponse{
+		Entitlements: []*resmgrsvc.SimulateEntitlementResponse_ResourcePoolEntitlement{
+			{
+				RespoolID: respoolID,
+				Path:      path,
+				Resources: []*resmgrsvc.SimulateEntitlementResponse_ResourceEntitlement{
+					{
+						Kind:      "cpu",
+						Current:   10,
+						Simulated: 20,
+					},
+				},
+			},
+		},
+	}
+
+	tt := []struct {
+		debug bool
+		err   error
+	}{
+		{
+			debug: false,
+		},
+		{
+			debug: true,
+		},
+		{
+			err: errors.New("cannot simulate entitlement"),
+		},
+	}
+
+	for _, t := range tt {
+		c.Debug = t.debug
+		suite.withMockResourcePoolLookup(
+			&respool.LookupRequest{
+				Path: &respool.ResourcePoolPath{Value: "/"},
+			},
+			&respool.LookupResponse{Id: parentID},
+			nil)
+		suite.withMockResourcePoolLookup(
+			&respool.LookupRequest{
+				Path: &respool.ResourcePoolPath{Value: path},
+			},
+			&respool.LookupResponse{Id: respoolID},
+			nil)
+		suite.mockResMgr.EXPECT().
+			SimulateEntitlement(suite.ctx, gomock.Eq(request)).
+			Return(response, t.err)
+
+		err := c.ResPoolSimulateAction(path, _defaultResPoolConfig)
+		if t.err != nil {
+			suite.EqualError(err, t.err.Error())
+		} else {
+			suite.NoError(err)
+		}
+	}
+
+	// root resource pool can't be simulated
+	suite.Error(c.ResPoolSimulateAction(ResourcePoolPathDelim,
+		_defaultResPoolConfig))
+}
+
 func (suite *resPoolActions) TestClientResPoolDeleteAction() {
 	c := Client{
 		Debug:      false,
@@ -284,6 +284,10 @@ func (c *Client) StatelessReplaceJobAction(
 	opaqueData string,
 	inPlace bool,
 	startPods bool,
+	readinessTimeout time.Duration,
+	canaryInstances uint32,
+	canaryBake time.Duration,
+	canaryMaxFailures uint32,
 ) error {
 	// TODO: implement cli override check and get entity version
 	// form job after stateless.Get is ready
@@ -316,6 +320,15 @@ func (c *Client) StatelessReplaceJobAction(
 		opaque = &v1alphapeloton.OpaqueData{Data: opaqueData}
 	}
 
+	var canary *stateless.CanarySpec
+	if canaryInstances > 0 {
+		canary = &stateless.CanarySpec{
+			InstanceCount: canaryInstances,
+			BakeTimeSecs:  uint32(canaryBake.Seconds()),
+			MaxFailures:   canaryMaxFailures,
+		}
+	}
+
 	req := &statelesssvc.ReplaceJobRequest{
 		JobId:   &v1alphapeloton.JobID{Value: jobID},
 		Version: &v1alphapeloton.EntityVersion{Value: entityVersion},
@@ -328,6 +341,8 @@ func (c *Client) StatelessReplaceJobAction(
 			StartPaused:                  startPaused,
 			InPlace:                      inPlace,
 			StartPods:                    startPods,
+			ReadinessTimeoutSecs:         uint32(readinessTimeout.Seconds()),
+			Canary:                       canary,
 		},
 		OpaqueData: opaque,
 	}
@@ -326,6 +326,10 @@ func (suite *statelessActionsTestSuite) TestStatelessReplaceJobActionSuccess() {
 	inPlace := false
 	startPods := false
 	opaque := "test"
+	readinessTimeout := 2 * time.Minute
+	canaryInstances := uint32(2)
+	canaryBake := 5 * time.Minute
+	canaryMaxFailures := uint32(1)
 
 	suite.resClient.EXPECT().
 		LookupResourcePoolID(gomock.Any(), &respool.LookupRequest{
@@ -339,6 +343,12 @@ func (suite *statelessActionsTestSuite) TestStatelessReplaceJobActionSuccess() {
 
 	suite.statelessClient.EXPECT().
 		ReplaceJob(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *svc.ReplaceJobRequest) {
+			suite.Equal(uint32(120), req.GetUpdateSpec().GetReadinessTimeoutSecs())
+			suite.Equal(canaryInstances, req.GetUpdateSpec().GetCanary().GetInstanceCount())
+			suite.Equal(uint32(300), req.GetUpdateSpec().GetCanary().GetBakeTimeSecs())
+			suite.Equal(canaryMaxFailures, req.GetUpdateSpec().GetCanary().GetMaxFailures())
+		}).
 		Return(&svc.ReplaceJobResponse{
 			Version: &v1alphapeloton.EntityVersion{Value: testEntityVersion},
 		}, nil)
@@ -357,6 +367,10 @@ func (suite *statelessActionsTestSuite) TestStatelessReplaceJobActionSuccess() {
 		opaque,
 		inPlace,
 		startPods,
+		readinessTimeout,
+		canaryInstances,
+		canaryBake,
+		canaryMaxFailures,
 	))
 }
 
@@ -394,6 +408,10 @@ func (suite *statelessActionsTestSuite) TestStatelessReplaceJobActionLookupResou
 		"",
 		inPlace,
 		startPods,
+		0,
+		0,
+		0,
+		0,
 	))
 }
 
@@ -27,8 +27,14 @@ import (
 )
 
 // WatchPod is the action for starting a watch stream for pod, specified
-// by job id and pod names.
-func (c *Client) WatchPod(jobID string, podNames []string, labels []string) error {
+// by job id and pod names. If startRevision is non-zero, the changes since
+// that revision are streamed first.
+func (c *Client) WatchPod(
+	jobID string,
+	podNames []string,
+	labels []string,
+	startRevision uint64,
+) error {
 	var j *peloton.JobID
 	if jobID != "" {
 		j = &peloton.JobID{
@@ -58,6 +64,7 @@ func (c *Client) WatchPod(jobID string, podNames []string, labels []string) erro
 	stream, err := c.watchClient.Watch(
 		c.ctx,
 		&watchsvc.WatchRequest{
+			StartRevision: startRevision,
 			PodFilter: &watch.PodFilter{
 				JobId:    j,
 				PodNames: ps,
@@ -69,6 +76,40 @@ func (c *Client) WatchPod(jobID string, podNames []string, labels []string) erro
 		return nil
 	}
 
+	return printWatchResponses(stream)
+}
+
+// WatchJob is the action for starting a watch stream for stateless job
+// state and workflow changes, specified by job ids. All the jobs are
+// watched if no job id is given.
+func (c *Client) WatchJob(jobIDs []string) error {
+	var ids []*peloton.JobID
+	for _, jobID := range jobIDs {
+		ids = append(ids, &peloton.JobID{
+			Value: jobID,
+		})
+	}
+
+	stream, err := c.watchClient.Watch(
+		c.ctx,
+		&watchsvc.WatchRequest{
+			StatelessJobFilter: &watch.StatelessJobFilter{
+				JobIds: ids,
+			},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	return printWatchResponses(stream)
+}
+
+// printWatchResponses prints the responses of a watch stream till the
+// stream ends.
+func printWatchResponses(
+	stream watchsvc.WatchServiceServiceWatchYARPCClient,
+) error {
 	for {
 		resp, err := stream.Recv()
 		if err == io.EOF {
@@ -19,6 +19,7 @@ import (
 	"io"
 	"testing"
 
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
@@ -27,6 +28,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 type watchActionsTestSuite struct {
@@ -81,6 +83,9 @@ func (suite *watchActionsTestSuite) TestWatchPod() {
 
 	suite.watchClient.EXPECT().
 		Watch(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *watchsvc.WatchRequest) {
+			suite.Equal(uint64(10), req.GetStartRevision())
+		}).
 		Return(stream, nil)
 
 	var calls []*gomock.Call
@@ -91,7 +96,7 @@ func (suite *watchActionsTestSuite) TestWatchPod() {
 
 	gomock.InOrder(calls...)
 
-	suite.NoError(suite.client.WatchPod(jobID, podNames, labels))
+	suite.NoError(suite.client.WatchPod(jobID, podNames, labels, 10))
 }
 
 func (suite *watchActionsTestSuite) TestWatchPodLabelError() {
@@ -102,7 +107,53 @@ func (suite *watchActionsTestSuite) TestWatchPodLabelError() {
 	label1 := "key1:value1:value2"
 	labels = append(labels, label1)
 
-	suite.Error(suite.client.WatchPod(jobID, podNames, labels))
+	suite.Error(suite.client.WatchPod(jobID, podNames, labels, 0))
+}
+
+func (suite *watchActionsTestSuite) TestWatchJob() {
+	jobIDs := []string{"test-job-1", "test-job-2"}
+	watchID := uuid.New()
+
+	stream := mocks.NewMockWatchServiceServiceWatchYARPCClient(suite.ctrl)
+	resps := []*watchsvc.WatchResponse{
+		{WatchId: watchID},
+	}
+	for _, jobID := range jobIDs {
+		resps = append(resps, &watchsvc.WatchResponse{
+			WatchId: watchID,
+			StatelessJobs: []*stateless.JobSummary{
+				{
+					JobId: &peloton.JobID{Value: jobID},
+				},
+			},
+		})
+	}
+
+	suite.watchClient.EXPECT().
+		Watch(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *watchsvc.WatchRequest) {
+			suite.Nil(req.GetPodFilter())
+			suite.Len(req.GetStatelessJobFilter().GetJobIds(), 2)
+		}).
+		Return(stream, nil)
+
+	var calls []*gomock.Call
+	for _, resp := range resps {
+		calls = append(calls, stream.EXPECT().Recv().Return(resp, nil))
+	}
+	calls = append(calls, stream.EXPECT().Recv().Return(nil, io.EOF))
+
+	gomock.InOrder(calls...)
+
+	suite.NoError(suite.client.WatchJob(jobIDs))
+}
+
+func (suite *watchActionsTestSuite) TestWatchJobError() {
+	suite.watchClient.EXPECT().
+		Watch(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("test error"))
+
+	suite.Error(suite.client.WatchJob(nil))
 }
 
 func (suite *watchActionsTestSuite) TestCancelWatch() {
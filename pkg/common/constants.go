@@ -47,6 +47,9 @@ const (
 
 	// PlacementRole is the leadership election role for placement engine
 	PlacementRole = "placement"
+	// PlacementDefragRole is the leadership election role for the
+	// defragmenter of the placement engines
+	PlacementDefragRole = "placement/defrag"
 	// HostManagerRole is the leadership election role for hostmgr
 	HostManagerRole = "hostmanager"
 	// JobManagerRole is the leadership election role for jobmgr
@@ -88,6 +88,12 @@ func (e evaluator) Evaluate(
 	case task.Constraint_LABEL_CONSTRAINT:
 		return e.evaluateLabelConstraint(
 			constraint.GetLabelConstraint(), labelValues)
+	case task.Constraint_SPREAD_CONSTRAINT:
+		// Spread constraints depend on the placement of the other tasks
+		// of the job over all hosts, so they cannot be evaluated on the
+		// labels of a single host. They are handled by the placement
+		// strategies instead.
+		return EvaluateResultNotApplicable, nil
 	}
 
 	log.WithField("type", constraint.GetType()).
@@ -206,3 +212,32 @@ func IsNonExclusiveConstraint(constraint *task.Constraint) bool {
 	}
 	return true
 }
+
+// GetSpreadConstraints returns the spread constraints in the constraint
+// specification. Spread constraints are only honored at the top level or
+// inside an AND constraint, since the placement strategies cannot choose
+// between the alternatives of an OR constraint.
+func GetSpreadConstraints(constraint *task.Constraint) []*task.SpreadConstraint {
+	switch constraint.GetType() {
+	case task.Constraint_SPREAD_CONSTRAINT:
+		if constraint.GetSpreadConstraint().GetHostAttribute() == "" {
+			return nil
+		}
+		return []*task.SpreadConstraint{constraint.GetSpreadConstraint()}
+	case task.Constraint_AND_CONSTRAINT:
+		var result []*task.SpreadConstraint
+		for _, c := range constraint.GetAndConstraint().GetConstraints() {
+			result = append(result, GetSpreadConstraints(c)...)
+		}
+		return result
+	}
+	return nil
+}
+
+// GetMaxSkew returns the maximum skew allowed by the spread constraint.
+func GetMaxSkew(spread *task.SpreadConstraint) uint32 {
+	if spread.GetMaxSkew() == 0 {
+		return 1
+	}
+	return spread.GetMaxSkew()
+}
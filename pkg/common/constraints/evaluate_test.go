@@ -285,6 +285,19 @@ func (suite *EvaluatorTestSuite) TestKindEvaluator() {
 		},
 	}
 
+	spreadNotApplicable := testCase{
+		expected:    EvaluateResultNotApplicable,
+		msg:         "Spread constraint is not applicable to a single host",
+		labelValues: hostLabels1,
+		constraint: &task.Constraint{
+			Type: task.Constraint_SPREAD_CONSTRAINT,
+			SpreadConstraint: &task.SpreadConstraint{
+				HostAttribute: _rackLabel,
+				MaxSkew:       1,
+			},
+		},
+	}
+
 	table := []testCase{
 		kindMismatch,
 		hostAffinityMatch,
@@ -301,6 +314,7 @@ func (suite *EvaluatorTestSuite) TestKindEvaluator() {
 		orNotApplicable,
 		unknownConditionEnum,
 		unknownConstraintTypeEnum,
+		spreadNotApplicable,
 	}
 	e := NewEvaluator(task.LabelConstraint_HOST)
 
@@ -425,6 +439,91 @@ func (suite *EvaluatorTestSuite) TestIsNonExclusiveConstraint() {
 	}
 }
 
+// TestGetSpreadConstraints tests the function GetSpreadConstraints
+func (suite *EvaluatorTestSuite) TestGetSpreadConstraints() {
+	rackSpread := &task.Constraint{
+		Type: task.Constraint_SPREAD_CONSTRAINT,
+		SpreadConstraint: &task.SpreadConstraint{
+			HostAttribute: _rackLabel,
+		},
+	}
+	zoneSpread := &task.Constraint{
+		Type: task.Constraint_SPREAD_CONSTRAINT,
+		SpreadConstraint: &task.SpreadConstraint{
+			HostAttribute: "zone",
+			MaxSkew:       2,
+		},
+	}
+	label := &task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind: task.LabelConstraint_HOST,
+		},
+	}
+
+	testTable := []struct {
+		msg        string
+		constraint *task.Constraint
+		expected   []*task.SpreadConstraint
+	}{
+		{
+			msg: "nil constraint",
+		},
+		{
+			msg:        "label constraint",
+			constraint: label,
+		},
+		{
+			msg:        "spread constraint",
+			constraint: rackSpread,
+			expected: []*task.SpreadConstraint{
+				rackSpread.GetSpreadConstraint(),
+			},
+		},
+		{
+			msg: "spread constraint without host attribute",
+			constraint: &task.Constraint{
+				Type:             task.Constraint_SPREAD_CONSTRAINT,
+				SpreadConstraint: &task.SpreadConstraint{},
+			},
+		},
+		{
+			msg: "And constraint with spread constraints",
+			constraint: &task.Constraint{
+				Type: task.Constraint_AND_CONSTRAINT,
+				AndConstraint: &task.AndConstraint{
+					Constraints: []*task.Constraint{
+						rackSpread, label, zoneSpread,
+					},
+				},
+			},
+			expected: []*task.SpreadConstraint{
+				rackSpread.GetSpreadConstraint(),
+				zoneSpread.GetSpreadConstraint(),
+			},
+		},
+		{
+			msg: "Or constraint with spread constraint",
+			constraint: &task.Constraint{
+				Type: task.Constraint_OR_CONSTRAINT,
+				OrConstraint: &task.OrConstraint{
+					Constraints: []*task.Constraint{rackSpread, label},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testTable {
+		suite.Equal(
+			tc.expected,
+			GetSpreadConstraints(tc.constraint),
+			tc.msg)
+	}
+
+	suite.Equal(uint32(1), GetMaxSkew(rackSpread.GetSpreadConstraint()))
+	suite.Equal(uint32(2), GetMaxSkew(zoneSpread.GetSpreadConstraint()))
+}
+
 func TestEvaluatorTestSuite(t *testing.T) {
 	suite.Run(t, new(EvaluatorTestSuite))
 }
@@ -0,0 +1,286 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package keyring provides envelope encryption of secrets. Each secret is
+// encrypted with its own random data key, and the data key is wrapped by a
+// versioned master key of a keyring loaded from a file. The ID of the secret
+// is authenticated along with both, so that an envelope cannot be swapped
+// between secrets, nor its data key between master key versions.
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"sort"
+
+	"github.com/uber/peloton/pkg/common/config"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// size in bytes of the master keys and the data keys, for AES-256
+	_keySize = 32
+)
+
+var (
+	errNoKeys              = errors.New("keyring has no master keys")
+	errInvalidVersion      = errors.New("master key version must be positive")
+	errShortCiphertext     = errors.New("ciphertext is too short")
+	errUnencryptedEnvelope = errors.New("envelope is not encrypted")
+)
+
+// File is the content of a keyring file, for example:
+//
+//	current_version: 2
+//	keys:
+//	  1: <base64 encoded 32 bytes key>
+//	  2: <base64 encoded 32 bytes key>
+//
+// Master keys of previous versions are kept in the file until all the
+// data keys wrapped by them are re-wrapped with the current master key.
+type File struct {
+	// Version of the master key used to wrap new data keys
+	CurrentVersion uint32 `yaml:"current_version"`
+
+	// Base64 encoded master keys by version
+	Keys map[uint32]string `yaml:"keys"`
+}
+
+// Envelope is a secret encrypted with a data key, along with the data key
+// wrapped by a master key.
+type Envelope struct {
+	// Version of the master key which wraps the data key
+	KeyVersion uint32
+	// Data key wrapped by the master key
+	DataKey []byte
+	// Secret encrypted with the data key
+	Ciphertext []byte
+}
+
+// Keyring holds the versioned master keys.
+type Keyring struct {
+	currentVersion uint32
+	masterKeys     map[uint32]cipher.AEAD
+}
+
+// Load reads a keyring from the given file.
+func Load(path string) (*Keyring, error) {
+	var f File
+	if err := config.Parse(&f, path); err != nil {
+		return nil, errors.Wrap(err, "failed to parse keyring file")
+	}
+
+	keys := make(map[uint32][]byte)
+	for version, encoded := range f.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, errors.Wrapf(err,
+				"failed to decode master key of version %d", version)
+		}
+		keys[version] = key
+	}
+	return New(f.CurrentVersion, keys)
+}
+
+// New creates a keyring from master keys by version. The master key of
+// the current version is used to wrap new data keys.
+func New(currentVersion uint32, keys map[uint32][]byte) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errNoKeys
+	}
+
+	k := &Keyring{
+		currentVersion: currentVersion,
+		masterKeys:     make(map[uint32]cipher.AEAD),
+	}
+	for version, key := range keys {
+		if version == 0 {
+			return nil, errInvalidVersion
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, errors.Wrapf(err,
+				"invalid master key of version %d", version)
+		}
+		k.masterKeys[version] = aead
+	}
+
+	if _, ok := k.masterKeys[currentVersion]; !ok {
+		return nil, errors.Errorf(
+			"no master key of current version %d", currentVersion)
+	}
+	return k, nil
+}
+
+// CurrentVersion returns the version of the master key used to wrap new
+// data keys.
+func (k *Keyring) CurrentVersion() uint32 {
+	return k.currentVersion
+}
+
+// Versions returns the versions of the master keys, in increasing order.
+func (k *Keyring) Versions() []uint32 {
+	var versions []uint32
+	for version := range k.masterKeys {
+		versions = append(versions, version)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] < versions[j]
+	})
+	return versions
+}
+
+// Encrypt encrypts the plaintext of the secret with a new data key, and
+// wraps the data key with the current master key.
+func (k *Keyring) Encrypt(secretID string, plaintext []byte) (*Envelope, error) {
+	dataKey := make([]byte, _keySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, errors.Wrap(err, "failed to generate data key")
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(dataAEAD, plaintext, []byte(secretID))
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := k.wrap(secretID, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		KeyVersion: k.currentVersion,
+		DataKey:    wrappedKey,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+// Decrypt unwraps the data key of the envelope and decrypts the secret.
+func (k *Keyring) Decrypt(secretID string, e *Envelope) ([]byte, error) {
+	dataKey, err := k.unwrap(secretID, e)
+	if err != nil {
+		return nil, err
+	}
+
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(dataAEAD, e.Ciphertext, []byte(secretID))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt secret")
+	}
+	return plaintext, nil
+}
+
+// Rewrap wraps the data key of the envelope with the current master key.
+// The secret itself is not decrypted, so rotating the master key does not
+// expose the secrets.
+func (k *Keyring) Rewrap(secretID string, e *Envelope) (*Envelope, error) {
+	dataKey, err := k.unwrap(secretID, e)
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := k.wrap(secretID, dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Envelope{
+		KeyVersion: k.currentVersion,
+		DataKey:    wrappedKey,
+		Ciphertext: e.Ciphertext,
+	}, nil
+}
+
+// wrap wraps the data key of the secret with the current master key.
+func (k *Keyring) wrap(secretID string, dataKey []byte) ([]byte, error) {
+	return seal(
+		k.masterKeys[k.currentVersion],
+		dataKey,
+		dataKeyAdditionalData(secretID, k.currentVersion))
+}
+
+// unwrap returns the data key of the envelope of the secret.
+func (k *Keyring) unwrap(secretID string, e *Envelope) ([]byte, error) {
+	if e.KeyVersion == 0 {
+		return nil, errUnencryptedEnvelope
+	}
+
+	masterKey, ok := k.masterKeys[e.KeyVersion]
+	if !ok {
+		return nil, errors.Errorf(
+			"no master key of version %d", e.KeyVersion)
+	}
+
+	dataKey, err := open(
+		masterKey,
+		e.DataKey,
+		dataKeyAdditionalData(secretID, e.KeyVersion))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap data key")
+	}
+	return dataKey, nil
+}
+
+// newAEAD creates an AES-256-GCM cipher with the given key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != _keySize {
+		return nil, errors.Errorf(
+			"key size is %d bytes instead of %d", len(key), _keySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// dataKeyAdditionalData returns the additional data authenticated along
+// with the data key of a secret wrapped by the master key of a version.
+func dataKeyAdditionalData(secretID string, keyVersion uint32) []byte {
+	data := make([]byte, 4, 4+len(secretID))
+	binary.BigEndian.PutUint32(data, keyVersion)
+	return append(data, secretID...)
+}
+
+// seal encrypts the plaintext with a random nonce, which is prepended to
+// the returned ciphertext, and authenticates the additional data.
+func seal(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// open decrypts a ciphertext created by seal with the same additional data.
+func open(aead cipher.AEAD, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, errShortCiphertext
+	}
+	nonce := ciphertext[:aead.NonceSize()]
+	return aead.Open(nil, nonce, ciphertext[aead.NonceSize():], additionalData)
+}
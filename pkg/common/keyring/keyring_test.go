@@ -0,0 +1,195 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package keyring
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type KeyringTestSuite struct {
+	suite.Suite
+
+	key1 []byte
+	key2 []byte
+}
+
+func TestKeyring(t *testing.T) {
+	suite.Run(t, new(KeyringTestSuite))
+}
+
+func (suite *KeyringTestSuite) SetupTest() {
+	suite.key1 = bytes.Repeat([]byte{1}, _keySize)
+	suite.key2 = bytes.Repeat([]byte{2}, _keySize)
+}
+
+// TestEncryptDecrypt tests encrypting and decrypting a secret
+func (suite *KeyringTestSuite) TestEncryptDecrypt() {
+	k, err := New(1, map[uint32][]byte{1: suite.key1})
+	suite.NoError(err)
+	suite.Equal(uint32(1), k.CurrentVersion())
+
+	secret := []byte("some secret")
+	e, err := k.Encrypt("secret-1", secret)
+	suite.NoError(err)
+	suite.Equal(uint32(1), e.KeyVersion)
+	suite.False(bytes.Contains(e.Ciphertext, secret))
+
+	// every secret has its own data key
+	other, err := k.Encrypt("secret-1", secret)
+	suite.NoError(err)
+	suite.NotEqual(e.DataKey, other.DataKey)
+	suite.NotEqual(e.Ciphertext, other.Ciphertext)
+
+	plaintext, err := k.Decrypt("secret-1", e)
+	suite.NoError(err)
+	suite.Equal(secret, plaintext)
+}
+
+// TestAdditionalData tests that an envelope can only be decrypted as the
+// secret it was encrypted for, with the master key version it was
+// wrapped with
+func (suite *KeyringTestSuite) TestAdditionalData() {
+	k, err := New(2, map[uint32][]byte{1: suite.key1, 2: suite.key1})
+	suite.NoError(err)
+	e, err := k.Encrypt("secret-1", []byte("some secret"))
+	suite.NoError(err)
+
+	_, err = k.Decrypt("secret-2", e)
+	suite.Error(err)
+
+	_, err = k.Rewrap("secret-2", e)
+	suite.Error(err)
+
+	// both versions have the same master key, but the data key was
+	// wrapped for version 2
+	_, err = k.Decrypt("secret-1", &Envelope{
+		KeyVersion: 1,
+		DataKey:    e.DataKey,
+		Ciphertext: e.Ciphertext,
+	})
+	suite.Error(err)
+
+	// the ciphertext of another secret is rejected even with its data key
+	other, err := k.Encrypt("secret-2", []byte("other secret"))
+	suite.NoError(err)
+	_, err = k.Decrypt("secret-1", &Envelope{
+		KeyVersion: 2,
+		DataKey:    e.DataKey,
+		Ciphertext: other.Ciphertext,
+	})
+	suite.Error(err)
+}
+
+// TestRewrap tests rotating the master key of an envelope
+func (suite *KeyringTestSuite) TestRewrap() {
+	oldKeyring, err := New(1, map[uint32][]byte{1: suite.key1})
+	suite.NoError(err)
+	e, err := oldKeyring.Encrypt("secret-1", []byte("some secret"))
+	suite.NoError(err)
+
+	k, err := New(2, map[uint32][]byte{1: suite.key1, 2: suite.key2})
+	suite.NoError(err)
+	rewrapped, err := k.Rewrap("secret-1", e)
+	suite.NoError(err)
+	suite.Equal(uint32(2), rewrapped.KeyVersion)
+	suite.Equal(e.Ciphertext, rewrapped.Ciphertext)
+
+	// the master key of version 1 is not needed anymore
+	newKeyring, err := New(2, map[uint32][]byte{2: suite.key2})
+	suite.NoError(err)
+	plaintext, err := newKeyring.Decrypt("secret-1", rewrapped)
+	suite.NoError(err)
+	suite.Equal([]byte("some secret"), plaintext)
+
+	_, err = newKeyring.Decrypt("secret-1", e)
+	suite.Error(err)
+}
+
+// TestDecryptFailure tests the failures to decrypt an envelope
+func (suite *KeyringTestSuite) TestDecryptFailure() {
+	k, err := New(1, map[uint32][]byte{1: suite.key1})
+	suite.NoError(err)
+	e, err := k.Encrypt("secret-1", []byte("some secret"))
+	suite.NoError(err)
+
+	_, err = k.Decrypt("secret-1", &Envelope{Ciphertext: e.Ciphertext})
+	suite.Equal(errUnencryptedEnvelope, err)
+
+	_, err = k.Decrypt("secret-1", &Envelope{
+		KeyVersion: 2,
+		DataKey:    e.DataKey,
+		Ciphertext: e.Ciphertext,
+	})
+	suite.Error(err)
+
+	_, err = k.Decrypt("secret-1", &Envelope{
+		KeyVersion: 1,
+		DataKey:    e.DataKey[:4],
+		Ciphertext: e.Ciphertext,
+	})
+	suite.Error(err)
+
+	tampered := append([]byte{}, e.Ciphertext...)
+	tampered[len(tampered)-1] ^= 0xff
+	_, err = k.Decrypt("secret-1", &Envelope{
+		KeyVersion: 1,
+		DataKey:    e.DataKey,
+		Ciphertext: tampered,
+	})
+	suite.Error(err)
+}
+
+// TestNewInvalidKeys tests creating a keyring with invalid master keys
+func (suite *KeyringTestSuite) TestNewInvalidKeys() {
+	_, err := New(1, nil)
+	suite.Equal(errNoKeys, err)
+
+	_, err = New(0, map[uint32][]byte{0: suite.key1})
+	suite.Equal(errInvalidVersion, err)
+
+	_, err = New(1, map[uint32][]byte{1: suite.key1[:16]})
+	suite.Error(err)
+
+	_, err = New(2, map[uint32][]byte{1: suite.key1})
+	suite.Error(err)
+}
+
+// TestLoad tests loading a keyring from a file
+func (suite *KeyringTestSuite) TestLoad() {
+	f, err := ioutil.TempFile("", "keyring")
+	suite.NoError(err)
+	defer os.Remove(f.Name())
+
+	_, err = fmt.Fprintf(f, "current_version: 2\nkeys:\n  1: %s\n  2: %s\n",
+		base64.StdEncoding.EncodeToString(suite.key1),
+		base64.StdEncoding.EncodeToString(suite.key2))
+	suite.NoError(err)
+	suite.NoError(f.Close())
+
+	k, err := Load(f.Name())
+	suite.NoError(err)
+	suite.Equal(uint32(2), k.CurrentVersion())
+	suite.Equal([]uint32{1, 2}, k.Versions())
+
+	_, err = Load("/does/not/exist")
+	suite.Error(err)
+}
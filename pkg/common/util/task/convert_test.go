@@ -107,8 +107,9 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 				Ports: []*task.PortConfig{{Name: "http", Value: 0}},
 			},
 			Runtime: &task.RuntimeInfo{
-				State: task.TaskState_FAILED,
-				Host:  "hostname",
+				State:     task.TaskState_FAILED,
+				Host:      "hostname",
+				AvoidHost: "hostname",
 			},
 		},
 		{
@@ -116,6 +117,13 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 			JobId:      &jobID,
 			Config: &task.TaskConfig{
 				Ports: []*task.PortConfig{{Name: "http", Value: 0}},
+				PlacementPreferences: []*task.PlacementPreference{
+					{
+						Kind:   task.PlacementPreference_HOST,
+						Label:  &peloton.Label{Key: "zone", Value: "zone1"},
+						Weight: 1,
+					},
+				},
 			},
 			Runtime: &task.RuntimeInfo{
 				State: task.TaskState_SUCCEEDED,
@@ -125,11 +133,18 @@ func TestConvertTaskToResMgrTask(t *testing.T) {
 	}
 
 	jobConfig := &job.JobConfig{
-		SLA: &job.SlaConfig{},
+		SLA: &job.SlaConfig{
+			MaximumUnavailableInstances: 2,
+		},
+		Owner: "owner",
 	}
 	for _, taskInfo := range taskInfos {
 		rmTask := ConvertTaskToResMgrTask(taskInfo, jobConfig)
 		assert.Equal(t, taskInfo.JobId.Value, rmTask.JobId.Value)
+		assert.Equal(t, uint32(2), rmTask.GetMaximumUnavailableInstances())
+		assert.Equal(t, taskInfo.Config.PlacementPreferences, rmTask.PlacementPreferences)
+		assert.Equal(t, taskInfo.GetRuntime().GetAvoidHost(), rmTask.GetAvoidHost())
+		assert.Equal(t, "owner", rmTask.GetOwner())
 		assert.Equal(t, uint32(len(taskInfo.Config.Ports)), rmTask.NumPorts)
 		taskState := taskInfo.Runtime.GetState()
 		if taskState == task.TaskState_LAUNCHED ||
@@ -29,6 +29,7 @@ import (
 	"go.uber.org/yarpc/yarpcerrors"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
@@ -337,6 +338,18 @@ func IsPelotonJobStateTerminal(state job.JobState) bool {
 	}
 }
 
+// IsDagStateTerminal returns true if the state of a DAG of batch jobs
+// is terminal otherwise false
+func IsDagStateTerminal(state dag.DagState) bool {
+	switch state {
+	case dag.DagState_DAG_SUCCEEDED, dag.DagState_DAG_FAILED,
+		dag.DagState_DAG_CANCELED:
+		return true
+	default:
+		return false
+	}
+}
+
 // IsTaskHasValidVolume returns true if a task is stateful and has a valid volume
 func IsTaskHasValidVolume(taskInfo *task.TaskInfo) bool {
 	if taskInfo.GetConfig().GetVolume() != nil &&
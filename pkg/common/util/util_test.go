@@ -25,6 +25,7 @@ import (
 	"go.uber.org/yarpc/yarpcerrors"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
@@ -498,6 +499,19 @@ func TestJobTerminalState(t *testing.T) {
 	}
 }
 
+// Test check for DAG state being terminal
+func TestDagTerminalState(t *testing.T) {
+	dagTerminalStates := map[dag.DagState]bool{
+		dag.DagState_DAG_SUCCEEDED: true,
+		dag.DagState_DAG_FAILED:    true,
+		dag.DagState_DAG_CANCELED:  true,
+	}
+	for s := range dag.DagState_name {
+		_, isTerm := dagTerminalStates[dag.DagState(s)]
+		assert.Equal(t, isTerm, IsDagStateTerminal(dag.DagState(s)))
+	}
+}
+
 // Test check for task state being terminal
 func TestTaskTerminalState(t *testing.T) {
 	taskTerminalStates := map[task.TaskState]bool{
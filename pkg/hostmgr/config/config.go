@@ -68,6 +68,10 @@ type Config struct {
 	// Host Drainer Period
 	HostDrainerPeriod time.Duration `yaml:"host_drainer_period"`
 
+	// Period to open scheduled maintenance windows and track the
+	// hosts of the open windows
+	MaintenanceWindowPeriod time.Duration `yaml:"maintenance_window_period"`
+
 	// Represents scarce resource types such as GPU.
 	ScarceResourceTypes []string `yaml:"scarce_resource_types"`
 
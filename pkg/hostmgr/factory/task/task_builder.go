@@ -278,6 +278,7 @@ func (tb *Builder) Build(
 	tb.populateLabels(mesosTask, taskConfig.GetLabels(), jobID, instanceID)
 
 	tb.populateHealthCheck(mesosTask, taskConfig.GetHealthCheck())
+	tb.populateReadinessCheck(mesosTask, taskConfig.GetReadinessCheck())
 
 	return mesosTask, nil
 }
@@ -619,6 +620,76 @@ func (tb *Builder) populateHealthCheck(
 	mesosTask.HealthCheck = mh
 }
 
+// populateReadinessCheck sets up the check part of a Mesos task from the
+// readiness check config. Mesos checks never kill the task, and their
+// results are reported back in the check status of task status updates.
+func (tb *Builder) populateReadinessCheck(
+	mesosTask *mesos.TaskInfo, readiness *task.HealthCheckConfig) {
+	if readiness == nil || !readiness.GetEnabled() {
+		return
+	}
+
+	mc := &mesos.CheckInfo{}
+
+	if t := readiness.GetInitialIntervalSecs(); t > 0 {
+		tmp := float64(t)
+		mc.DelaySeconds = &tmp
+	}
+
+	if t := readiness.GetIntervalSecs(); t > 0 {
+		tmp := float64(t)
+		mc.IntervalSeconds = &tmp
+	}
+
+	if t := readiness.GetTimeoutSecs(); t > 0 {
+		tmp := float64(t)
+		mc.TimeoutSeconds = &tmp
+	}
+
+	switch readiness.GetType() {
+	case task.HealthCheckConfig_COMMAND:
+		cc := readiness.GetCommandCheck()
+		t := mesos.CheckInfo_COMMAND
+		mc.Type = &t
+		shell := true
+		value := cc.GetCommand()
+		cmd := &mesos.CommandInfo{
+			Shell: &shell,
+			Value: &value,
+		}
+		if !cc.GetUnshareEnvironments() {
+			// Custom executors carry the task environment on the
+			// executor command.
+			env := mesosTask.GetCommand().GetEnvironment()
+			if mesosTask.GetExecutor() != nil {
+				env = mesosTask.GetExecutor().GetCommand().GetEnvironment()
+			}
+			cmd.Environment = proto.Clone(env).(*mesos.Environment)
+		}
+		mc.Command = &mesos.CheckInfo_Command{Command: cmd}
+	case task.HealthCheckConfig_HTTP:
+		cc := readiness.GetHttpCheck()
+		t := mesos.CheckInfo_HTTP
+		mc.Type = &t
+		port := cc.GetPort()
+		path := cc.GetPath()
+		mc.Http = &mesos.CheckInfo_Http{
+			Port: &port,
+			Path: &path,
+		}
+	default:
+		log.WithField("type", readiness.GetType()).
+			Warn("Unknown readiness check type")
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"readiness": mc,
+		"task":      mesosTask.GetTaskId(),
+	}).Debug("Populated readiness check for mesos task")
+	mesosTask.Check = mc
+}
+
 // extractScalarResources takes necessary scalar resources from cached resources
 // of this instance to construct a task, and returns error if not enough
 // resources are left.
@@ -643,6 +643,113 @@ func (suite *BuilderTestSuite) TestPopulateHealthCheck() {
 	}
 }
 
+// TestPopulateReadinessCheck tests populating the mesos check from the
+// readiness check config.
+func (suite *BuilderTestSuite) TestPopulateReadinessCheck() {
+	cmdType := mesos.CheckInfo_COMMAND
+	httpType := mesos.CheckInfo_HTTP
+	command := "ready.sh"
+	tmpTrue := true
+	port := uint32(8080)
+	path := "/ready"
+	intervalSeconds := float64(3)
+
+	var testCases = []struct {
+		input  *task.HealthCheckConfig
+		output *mesos.CheckInfo
+	}{
+		// no readiness check
+		{
+			input:  nil,
+			output: nil,
+		},
+		// disabled readiness check
+		{
+			input: &task.HealthCheckConfig{
+				Type: task.HealthCheckConfig_COMMAND,
+				CommandCheck: &task.HealthCheckConfig_CommandCheck{
+					Command: command,
+				},
+			},
+			output: nil,
+		},
+		// command readiness check
+		{
+			input: &task.HealthCheckConfig{
+				Enabled: true,
+				Type:    task.HealthCheckConfig_COMMAND,
+				CommandCheck: &task.HealthCheckConfig_CommandCheck{
+					Command: command,
+				},
+				IntervalSecs: uint32(intervalSeconds),
+			},
+			output: &mesos.CheckInfo{
+				Type: &cmdType,
+				Command: &mesos.CheckInfo_Command{
+					Command: &mesos.CommandInfo{
+						Shell: &tmpTrue,
+						Value: &command,
+					},
+				},
+				IntervalSeconds: &intervalSeconds,
+			},
+		},
+		// http readiness check
+		{
+			input: &task.HealthCheckConfig{
+				Enabled: true,
+				Type:    task.HealthCheckConfig_HTTP,
+				HttpCheck: &task.HealthCheckConfig_HTTPCheck{
+					Scheme: "http",
+					Port:   port,
+					Path:   path,
+				},
+			},
+			output: &mesos.CheckInfo{
+				Type: &httpType,
+				Http: &mesos.CheckInfo_Http{
+					Port: &port,
+					Path: &path,
+				},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		builder := NewBuilder(nil)
+		taskInfo := &mesos.TaskInfo{}
+		builder.populateReadinessCheck(taskInfo, tt.input)
+		suite.Equal(tt.output, taskInfo.GetCheck())
+	}
+}
+
+// TestPopulateReadinessCheckCustomExecutor tests that command readiness
+// checks of custom executor tasks share the executor command environment.
+func (suite *BuilderTestSuite) TestPopulateReadinessCheckCustomExecutor() {
+	envName := "AURORA_HEALTH_PORT"
+	envValue := "31000"
+	env := &mesos.Environment{
+		Variables: []*mesos.Environment_Variable{
+			{Name: &envName, Value: &envValue},
+		},
+	}
+
+	builder := NewBuilder(nil)
+	taskInfo := &mesos.TaskInfo{
+		Executor: &mesos.ExecutorInfo{
+			Command: &mesos.CommandInfo{Environment: env},
+		},
+	}
+	builder.populateReadinessCheck(taskInfo, &task.HealthCheckConfig{
+		Enabled: true,
+		Type:    task.HealthCheckConfig_COMMAND,
+		CommandCheck: &task.HealthCheckConfig_CommandCheck{
+			Command: "ready.sh",
+		},
+	})
+	suite.Equal(env, taskInfo.GetCheck().GetCommand().GetCommand().GetEnvironment())
+}
+
 // TestPopulateReservationVolumeInfo tests populateReservationInfo.
 func (suite *BuilderTestSuite) TestPopulateReservationVolumeInfo() {
 	numTasks := 1
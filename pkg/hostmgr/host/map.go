@@ -178,6 +178,13 @@ type MaintenanceHostInfoMap interface {
 	// ClearAndFillMap clears the content of the
 	// map and fills the map with the given host infos
 	ClearAndFillMap(hostInfos []*host.HostInfo)
+	// Watch creates a watch client for the state changes of the
+	// specified hosts. All hosts are watched if hostFilter is empty.
+	// Returns the watch id and the watch client.
+	Watch(hostFilter []string) (string, *WatchClient, error)
+	// StopWatch stops a watch client. Returns "not-found" error if the
+	// corresponding watch client is not found.
+	StopWatch(watchID string) error
 }
 
 // maintenanceHostInfoMap implements MaintenanceHostInfoMap interface
@@ -186,6 +193,7 @@ type maintenanceHostInfoMap struct {
 	metrics       *Metrics
 	drainingHosts map[string]*host.HostInfo
 	downHosts     map[string]*host.HostInfo
+	watchClients  map[string]*WatchClient
 }
 
 // NewMaintenanceHostInfoMap returns a new MaintenanceHostInfoMap
@@ -194,6 +202,7 @@ func NewMaintenanceHostInfoMap(scope tally.Scope) MaintenanceHostInfoMap {
 		metrics:       NewMetrics(scope.SubScope("maintenance_map")),
 		drainingHosts: make(map[string]*host.HostInfo),
 		downHosts:     make(map[string]*host.HostInfo),
+		watchClients:  make(map[string]*WatchClient),
 	}
 }
 
@@ -248,12 +257,16 @@ func (m *maintenanceHostInfoMap) AddHostInfos(
 	defer m.lock.Unlock()
 
 	for _, hostInfo := range hostInfos {
+		prevState := m.getHostState(hostInfo.GetHostname())
 		switch hostInfo.State {
 		case host.HostState_HOST_STATE_DRAINING:
 			m.drainingHosts[hostInfo.GetHostname()] = hostInfo
 		case host.HostState_HOST_STATE_DOWN:
 			m.downHosts[hostInfo.GetHostname()] = hostInfo
+		default:
+			continue
 		}
+		m.notifyStateChange(hostInfo, prevState, hostInfo.State)
 	}
 
 	m.metrics.DrainingHosts.Update(float64(len(m.drainingHosts)))
@@ -266,9 +279,19 @@ func (m *maintenanceHostInfoMap) RemoveHostInfos(hosts []string) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	for _, host := range hosts {
-		delete(m.drainingHosts, host)
-		delete(m.downHosts, host)
+	for _, hostname := range hosts {
+		hostInfo, ok := m.drainingHosts[hostname]
+		if !ok {
+			hostInfo, ok = m.downHosts[hostname]
+		}
+		if !ok {
+			continue
+		}
+
+		prevState := m.getHostState(hostname)
+		delete(m.drainingHosts, hostname)
+		delete(m.downHosts, hostname)
+		m.notifyStateChange(hostInfo, prevState, host.HostState_HOST_STATE_UP)
 	}
 
 	m.metrics.DrainingHosts.Update(float64(len(m.drainingHosts)))
@@ -322,6 +345,7 @@ func (m *maintenanceHostInfoMap) UpdateHostState(
 	case host.HostState_HOST_STATE_DOWN:
 		m.downHosts[hostname] = hostInfo
 	}
+	m.notifyStateChange(hostInfo, from, to)
 
 	m.metrics.DrainingHosts.Update(float64(len(m.drainingHosts)))
 	m.metrics.DownHosts.Update(float64(len(m.downHosts)))
@@ -332,11 +356,14 @@ func (m *maintenanceHostInfoMap) ClearAndFillMap(hostInfos []*host.HostInfo) {
 	m.lock.Lock()
 	defer m.lock.Unlock()
 
-	for hostname := range m.drainingHosts {
+	prevHostInfos := make(map[string]*host.HostInfo)
+	for hostname, hostInfo := range m.drainingHosts {
+		prevHostInfos[hostname] = copyHostInfo(hostInfo, hostInfo.GetState())
 		delete(m.drainingHosts, hostname)
 	}
 
-	for hostname := range m.downHosts {
+	for hostname, hostInfo := range m.downHosts {
+		prevHostInfos[hostname] = copyHostInfo(hostInfo, hostInfo.GetState())
 		delete(m.downHosts, hostname)
 	}
 
@@ -349,6 +376,34 @@ func (m *maintenanceHostInfoMap) ClearAndFillMap(hostInfos []*host.HostInfo) {
 		}
 	}
 
+	// notify the state changes found on reconciliation
+	for hostname, prevHostInfo := range prevHostInfos {
+		if state := m.getHostState(hostname); state == host.HostState_HOST_STATE_UP {
+			m.notifyStateChange(prevHostInfo, prevHostInfo.GetState(), state)
+		}
+	}
+	for _, hostInfo := range hostInfos {
+		prevState := host.HostState_HOST_STATE_UP
+		if prevHostInfo, ok := prevHostInfos[hostInfo.GetHostname()]; ok {
+			prevState = prevHostInfo.GetState()
+		}
+		if state := m.getHostState(hostInfo.GetHostname()); state == hostInfo.GetState() {
+			m.notifyStateChange(hostInfo, prevState, state)
+		}
+	}
+
 	m.metrics.DrainingHosts.Update(float64(len(m.drainingHosts)))
 	m.metrics.DownHosts.Update(float64(len(m.downHosts)))
 }
+
+// getHostState returns the state of the host in the map, or UP if the
+// host is not in maintenance. It must be called with lock held.
+func (m *maintenanceHostInfoMap) getHostState(hostname string) host.HostState {
+	if _, ok := m.drainingHosts[hostname]; ok {
+		return host.HostState_HOST_STATE_DRAINING
+	}
+	if _, ok := m.downHosts[hostname]; ok {
+		return host.HostState_HOST_STATE_DOWN
+	}
+	return host.HostState_HOST_STATE_UP
+}
@@ -24,6 +24,9 @@ type Metrics struct {
 
 	DrainingHosts tally.Gauge
 	DownHosts     tally.Gauge
+
+	WatchClients  tally.Gauge
+	WatchOverflow tally.Counter
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics
@@ -34,5 +37,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 
 		DrainingHosts: scope.Gauge("draining_hosts"),
 		DownHosts:     scope.Gauge("down_hosts"),
+
+		WatchClients:  scope.Gauge("watch_clients"),
+		WatchOverflow: scope.Counter("watch_overflow"),
 	}
 }
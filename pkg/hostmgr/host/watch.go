@@ -0,0 +1,229 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/host"
+
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// _watchBufferSize is the number of host state changes which can be
+	// buffered for a watch client before the watch is aborted
+	_watchBufferSize = 1000
+	// _maxWatchClients is the max number of concurrent watch clients
+	_maxWatchClients = 100
+)
+
+// WatchSignal is sent through the Signal channel of a watch client
+// when the watch is stopped.
+type WatchSignal int
+
+const (
+	// WatchSignalUnknown indicates a unspecified WatchSignal.
+	WatchSignalUnknown WatchSignal = iota
+	// WatchSignalCancel indicates the watch is cancelled.
+	WatchSignalCancel
+	// WatchSignalOverflow indicates the watch is aborted since the client
+	// does not keep up with the host state changes.
+	WatchSignalOverflow
+)
+
+// String returns a user-friendly name for the specific WatchSignal
+func (s WatchSignal) String() string {
+	switch s {
+	case WatchSignalCancel:
+		return "cancel"
+	case WatchSignalOverflow:
+		return "overflow"
+	default:
+		return "unknown"
+	}
+}
+
+// HostStateChange is a transition of a host from one state to another.
+type HostStateChange struct {
+	// HostInfo of the host with the state after the transition
+	HostInfo *host.HostInfo
+	// PrevState is the state of the host before the transition
+	PrevState host.HostState
+	// Time of the transition
+	Time time.Time
+}
+
+// WatchClient receives the state changes of the watched hosts.
+type WatchClient struct {
+	// HostInfos of the watched hosts which were in maintenance when
+	// the watch was created
+	HostInfos []*host.HostInfo
+	Input     chan *HostStateChange
+	Signal    chan WatchSignal
+
+	// hostnames to watch, all hosts are watched if empty
+	hosts map[string]struct{}
+}
+
+// matchHost returns true if the host is watched by the client
+func (c *WatchClient) matchHost(hostname string) bool {
+	if len(c.hosts) == 0 {
+		return true
+	}
+	_, ok := c.hosts[hostname]
+	return ok
+}
+
+// Watch creates a watch client for the state changes of the specified
+// hosts. All hosts are watched if hostFilter is empty. Returns
+// "resource-exhausted" error if the max number of watch clients is
+// reached.
+func (m *maintenanceHostInfoMap) Watch(
+	hostFilter []string,
+) (string, *WatchClient, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if len(m.watchClients) >= _maxWatchClients {
+		return "", nil, yarpcerrors.ResourceExhaustedErrorf(
+			"max watch client reached")
+	}
+
+	c := &WatchClient{
+		Input: make(chan *HostStateChange, _watchBufferSize),
+		// Make buffer size 1 so that sender is not blocked when sending
+		// the Signal
+		Signal: make(chan WatchSignal, 1),
+		hosts:  make(map[string]struct{}),
+	}
+	for _, hostname := range hostFilter {
+		c.hosts[hostname] = struct{}{}
+	}
+
+	// The current states are taken under the same lock as the client is
+	// registered, so that no state change is missed by the client.
+	for _, hostInfos := range []map[string]*host.HostInfo{
+		m.drainingHosts,
+		m.downHosts,
+	} {
+		for hostname, hostInfo := range hostInfos {
+			if c.matchHost(hostname) {
+				c.HostInfos = append(c.HostInfos, copyHostInfo(
+					hostInfo,
+					hostInfo.GetState(),
+				))
+			}
+		}
+	}
+
+	watchID := uuid.New()
+	m.watchClients[watchID] = c
+	m.metrics.WatchClients.Update(float64(len(m.watchClients)))
+
+	log.WithFields(log.Fields{
+		"watch_id": watchID,
+		"hosts":    hostFilter,
+	}).Info("host watch client created")
+	return watchID, c, nil
+}
+
+// StopWatch stops a watch client. Returns "not-found" error if the
+// corresponding watch client is not found.
+func (m *maintenanceHostInfoMap) StopWatch(watchID string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.stopWatch(watchID, WatchSignalCancel)
+}
+
+// stopWatch stops a watch client, it must be called with lock held.
+func (m *maintenanceHostInfoMap) stopWatch(
+	watchID string,
+	signal WatchSignal,
+) error {
+	c, ok := m.watchClients[watchID]
+	if !ok {
+		return yarpcerrors.NotFoundErrorf(
+			"watch_id %s not exist for host watch client", watchID)
+	}
+
+	log.WithFields(log.Fields{
+		"watch_id": watchID,
+		"signal":   signal,
+	}).Info("stopping host watch client")
+
+	c.Signal <- signal
+	delete(m.watchClients, watchID)
+	m.metrics.WatchClients.Update(float64(len(m.watchClients)))
+
+	return nil
+}
+
+// notifyStateChange sends a host state transition to the watch clients
+// interested in the host, it must be called with lock held.
+func (m *maintenanceHostInfoMap) notifyStateChange(
+	hostInfo *host.HostInfo,
+	from host.HostState,
+	to host.HostState,
+) {
+	if from == to || len(m.watchClients) == 0 {
+		return
+	}
+
+	// A host is put DOWN only after it is drained. DRAINED is not kept
+	// in the map since the host is put DOWN right away, but it is still
+	// reported to the watch clients.
+	if from == host.HostState_HOST_STATE_DRAINING &&
+		to == host.HostState_HOST_STATE_DOWN {
+		m.notifyStateChange(hostInfo, from, host.HostState_HOST_STATE_DRAINED)
+		from = host.HostState_HOST_STATE_DRAINED
+	}
+
+	change := &HostStateChange{
+		HostInfo:  copyHostInfo(hostInfo, to),
+		PrevState: from,
+		Time:      time.Now(),
+	}
+
+	for watchID, c := range m.watchClients {
+		if !c.matchHost(hostInfo.GetHostname()) {
+			continue
+		}
+
+		select {
+		case c.Input <- change:
+		default:
+			log.WithField("watch_id", watchID).
+				Warn("event overflow for host watch client")
+			m.metrics.WatchOverflow.Inc(1)
+			m.stopWatch(watchID, WatchSignalOverflow)
+		}
+	}
+}
+
+// copyHostInfo returns a copy of the HostInfo with the given state
+func copyHostInfo(
+	hostInfo *host.HostInfo,
+	state host.HostState,
+) *host.HostInfo {
+	return &host.HostInfo{
+		Hostname: hostInfo.GetHostname(),
+		Ip:       hostInfo.GetIp(),
+		State:    state,
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package host
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/host"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type HostWatchTestSuite struct {
+	suite.Suite
+
+	hostInfoMap MaintenanceHostInfoMap
+}
+
+func (suite *HostWatchTestSuite) SetupTest() {
+	suite.hostInfoMap = NewMaintenanceHostInfoMap(tally.NoopScope)
+}
+
+func TestHostWatch(t *testing.T) {
+	suite.Run(t, new(HostWatchTestSuite))
+}
+
+func newHostInfo(hostname string, state host.HostState) *host.HostInfo {
+	return &host.HostInfo{
+		Hostname: hostname,
+		Ip:       "0.0.0.0",
+		State:    state,
+	}
+}
+
+// receiveChanges reads all the buffered state changes of a watch client
+func receiveChanges(c *WatchClient) []*HostStateChange {
+	var changes []*HostStateChange
+	for {
+		select {
+		case change := <-c.Input:
+			changes = append(changes, change)
+		default:
+			return changes
+		}
+	}
+}
+
+// checkChanges verifies the hostname, previous and new state of the
+// state changes
+func (suite *HostWatchTestSuite) checkChanges(
+	changes []*HostStateChange,
+	hostname string,
+	states ...host.HostState,
+) {
+	suite.Len(changes, len(states)-1)
+	for i, change := range changes {
+		suite.Equal(hostname, change.HostInfo.GetHostname())
+		suite.Equal(states[i], change.PrevState)
+		suite.Equal(states[i+1], change.HostInfo.GetState())
+		suite.False(change.Time.IsZero())
+	}
+}
+
+// TestWatchMaintenanceLifecycle tests that a watch client receives all the
+// state transitions of a host going through maintenance
+func (suite *HostWatchTestSuite) TestWatchMaintenanceLifecycle() {
+	watchID, c, err := suite.hostInfoMap.Watch(nil)
+	suite.NoError(err)
+	suite.NotEmpty(watchID)
+	suite.Empty(c.HostInfos)
+
+	suite.hostInfoMap.AddHostInfos([]*host.HostInfo{
+		newHostInfo("host1", host.HostState_HOST_STATE_DRAINING),
+	})
+	suite.NoError(suite.hostInfoMap.UpdateHostState(
+		"host1",
+		host.HostState_HOST_STATE_DRAINING,
+		host.HostState_HOST_STATE_DOWN))
+	suite.hostInfoMap.RemoveHostInfos([]string{"host1"})
+
+	suite.checkChanges(
+		receiveChanges(c),
+		"host1",
+		host.HostState_HOST_STATE_UP,
+		host.HostState_HOST_STATE_DRAINING,
+		host.HostState_HOST_STATE_DRAINED,
+		host.HostState_HOST_STATE_DOWN,
+		host.HostState_HOST_STATE_UP,
+	)
+
+	// removing a host not in maintenance is not a state change
+	suite.hostInfoMap.RemoveHostInfos([]string{"host1"})
+	suite.Empty(receiveChanges(c))
+
+	suite.NoError(suite.hostInfoMap.StopWatch(watchID))
+	suite.Equal(WatchSignalCancel, <-c.Signal)
+	err = suite.hostInfoMap.StopWatch(watchID)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestWatchHostFilter tests that a watch client only receives the state
+// changes of the watched hosts, along with their states on creation
+func (suite *HostWatchTestSuite) TestWatchHostFilter() {
+	suite.hostInfoMap.AddHostInfos([]*host.HostInfo{
+		newHostInfo("host1", host.HostState_HOST_STATE_DRAINING),
+		newHostInfo("host2", host.HostState_HOST_STATE_DOWN),
+	})
+
+	_, c, err := suite.hostInfoMap.Watch([]string{"host2", "host3"})
+	suite.NoError(err)
+	suite.Len(c.HostInfos, 1)
+	suite.Equal("host2", c.HostInfos[0].GetHostname())
+	suite.Equal(host.HostState_HOST_STATE_DOWN, c.HostInfos[0].GetState())
+
+	suite.hostInfoMap.RemoveHostInfos([]string{"host1", "host2"})
+	suite.hostInfoMap.AddHostInfos([]*host.HostInfo{
+		newHostInfo("host3", host.HostState_HOST_STATE_DRAINING),
+	})
+
+	changes := receiveChanges(c)
+	suite.Len(changes, 2)
+	suite.checkChanges(
+		changes[:1],
+		"host2",
+		host.HostState_HOST_STATE_DOWN,
+		host.HostState_HOST_STATE_UP,
+	)
+	suite.checkChanges(
+		changes[1:],
+		"host3",
+		host.HostState_HOST_STATE_UP,
+		host.HostState_HOST_STATE_DRAINING,
+	)
+}
+
+// TestWatchReconcile tests that the state changes found on reconciliation
+// with Mesos master are sent to the watch clients
+func (suite *HostWatchTestSuite) TestWatchReconcile() {
+	suite.hostInfoMap.AddHostInfos([]*host.HostInfo{
+		newHostInfo("host1", host.HostState_HOST_STATE_DRAINING),
+		newHostInfo("host2", host.HostState_HOST_STATE_DOWN),
+	})
+
+	_, c, err := suite.hostInfoMap.Watch(nil)
+	suite.NoError(err)
+	suite.Len(c.HostInfos, 2)
+
+	suite.hostInfoMap.ClearAndFillMap([]*host.HostInfo{
+		newHostInfo("host1", host.HostState_HOST_STATE_DOWN),
+		newHostInfo("host3", host.HostState_HOST_STATE_DRAINING),
+	})
+
+	changes := make(map[string][]*HostStateChange)
+	for _, change := range receiveChanges(c) {
+		hostname := change.HostInfo.GetHostname()
+		changes[hostname] = append(changes[hostname], change)
+	}
+	suite.Len(changes, 3)
+	suite.checkChanges(
+		changes["host1"],
+		"host1",
+		host.HostState_HOST_STATE_DRAINING,
+		host.HostState_HOST_STATE_DRAINED,
+		host.HostState_HOST_STATE_DOWN,
+	)
+	suite.checkChanges(
+		changes["host2"],
+		"host2",
+		host.HostState_HOST_STATE_DOWN,
+		host.HostState_HOST_STATE_UP,
+	)
+	suite.checkChanges(
+		changes["host3"],
+		"host3",
+		host.HostState_HOST_STATE_UP,
+		host.HostState_HOST_STATE_DRAINING,
+	)
+
+	// no change on reconciliation with the same states
+	suite.hostInfoMap.ClearAndFillMap([]*host.HostInfo{
+		newHostInfo("host1", host.HostState_HOST_STATE_DOWN),
+		newHostInfo("host3", host.HostState_HOST_STATE_DRAINING),
+	})
+	suite.Empty(receiveChanges(c))
+}
+
+// TestWatchMaxClientReached tests an error will be thrown when creating
+// a new watch client if max number of clients is reached
+func (suite *HostWatchTestSuite) TestWatchMaxClientReached() {
+	for i := 0; i < _maxWatchClients; i++ {
+		_, _, err := suite.hostInfoMap.Watch(nil)
+		suite.NoError(err)
+	}
+
+	_, _, err := suite.hostInfoMap.Watch(nil)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsResourceExhausted(err))
+}
+
+// TestWatchOverflow tests that the watch client is stopped with overflow
+// signal if the client buffer is overflown
+func (suite *HostWatchTestSuite) TestWatchOverflow() {
+	watchID, c, err := suite.hostInfoMap.Watch(nil)
+	suite.NoError(err)
+
+	// each host goes from UP to DRAINING and back to UP
+	for i := 0; i < _watchBufferSize/2; i++ {
+		suite.hostInfoMap.AddHostInfos([]*host.HostInfo{
+			newHostInfo("host1", host.HostState_HOST_STATE_DRAINING),
+		})
+		suite.hostInfoMap.RemoveHostInfos([]string{"host1"})
+	}
+	suite.Len(c.Signal, 0)
+
+	suite.hostInfoMap.AddHostInfos([]*host.HostInfo{
+		newHostInfo("host1", host.HostState_HOST_STATE_DRAINING),
+	})
+	suite.Equal(WatchSignalOverflow, <-c.Signal)
+	suite.Error(suite.hostInfoMap.StopWatch(watchID))
+}
@@ -17,18 +17,22 @@ package hostsvc
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	mesos "github.com/uber/peloton/.gen/mesos/v1"
 	mesos_maintenance "github.com/uber/peloton/.gen/mesos/v1/maintenance"
 	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
 	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	v1alpha_host_svc "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc"
 
+	"github.com/uber/peloton/pkg/common/background"
 	"github.com/uber/peloton/pkg/common/stringset"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/hostmgr/host"
 	"github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb"
 	"github.com/uber/peloton/pkg/hostmgr/queue"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
@@ -41,6 +45,13 @@ type serviceHandler struct {
 	metrics                *Metrics
 	operatorMasterClient   mpb.MasterOperatorClient
 	maintenanceHostInfoMap host.MaintenanceHostInfoMap
+
+	// maintenanceWindowLock serializes the changes to maintenance windows
+	maintenanceWindowLock sync.Mutex
+	maintenanceWindowOps  ormobjects.MaintenanceWindowOps
+	// lastMaintenanceWindowPurge is the last time the completed
+	// maintenance windows were purged
+	lastMaintenanceWindowPurge time.Time
 }
 
 // InitServiceHandler initializes the HostService
@@ -49,14 +60,27 @@ func InitServiceHandler(
 	parent tally.Scope,
 	operatorMasterClient mpb.MasterOperatorClient,
 	maintenanceQueue queue.MaintenanceQueue,
-	hostInfoMap host.MaintenanceHostInfoMap) {
+	hostInfoMap host.MaintenanceHostInfoMap,
+	ormStore *ormobjects.Store,
+	backgroundManager background.Manager,
+	maintenanceWindowPeriod time.Duration) {
 	handler := &serviceHandler{
 		maintenanceQueue:       maintenanceQueue,
 		metrics:                NewMetrics(parent.SubScope("hostsvc")),
 		operatorMasterClient:   operatorMasterClient,
 		maintenanceHostInfoMap: hostInfoMap,
+		maintenanceWindowOps:   ormobjects.NewMaintenanceWindowOps(ormStore),
 	}
+	backgroundManager.RegisterWorks(
+		background.Work{
+			Name:   _maintenanceWindowWorkName,
+			Func:   handler.reconcileMaintenanceWindows,
+			Period: maintenanceWindowPeriod,
+		},
+	)
 	d.Register(host_svc.BuildHostServiceYARPCProcedures(handler))
+	d.Register(v1alpha_host_svc.BuildHostServiceYARPCProcedures(
+		&v1AlphaServiceHandler{serviceHandler: handler}))
 	log.Info("Hostsvc handler initialized")
 }
 
@@ -137,17 +161,27 @@ func (m *serviceHandler) StartMaintenance(
 ) (*host_svc.StartMaintenanceResponse, error) {
 	m.metrics.StartMaintenanceAPI.Inc(1)
 
-	machineIds, err := buildMachineIDsForHosts(request.GetHostnames())
-	if err != nil {
+	if err := m.startMaintenance(request.GetHostnames()); err != nil {
 		m.metrics.StartMaintenanceFail.Inc(1)
 		return nil, err
 	}
 
+	m.metrics.StartMaintenanceSuccess.Inc(1)
+	return &host_svc.StartMaintenanceResponse{}, nil
+}
+
+// startMaintenance posts a maintenance schedule for the hosts to Mesos
+// Master and enqueues them into the maintenance queue to be drained
+func (m *serviceHandler) startMaintenance(hostnames []string) error {
+	machineIds, err := buildMachineIDsForHosts(hostnames)
+	if err != nil {
+		return err
+	}
+
 	// Get current maintenance schedule
 	response, err := m.operatorMasterClient.GetMaintenanceSchedule()
 	if err != nil {
-		m.metrics.StartMaintenanceFail.Inc(1)
-		return nil, err
+		return err
 	}
 	schedule := response.GetSchedule()
 	// Set current time as the `start` of maintenance window
@@ -171,8 +205,7 @@ func (m *serviceHandler) StartMaintenance(
 
 	err = m.operatorMasterClient.UpdateMaintenanceSchedule(schedule)
 	if err != nil {
-		m.metrics.StartMaintenanceFail.Inc(1)
-		return nil, err
+		return err
 	}
 	log.WithField("maintenance_schedule", schedule).
 		Info("Maintenance Schedule posted to Mesos Master")
@@ -189,13 +222,7 @@ func (m *serviceHandler) StartMaintenance(
 	m.maintenanceHostInfoMap.AddHostInfos(hostInfos)
 	// Enqueue hostnames into maintenance queue to initiate
 	// the rescheduling of tasks running on these hosts
-	err = m.maintenanceQueue.Enqueue(request.GetHostnames())
-	if err != nil {
-		return nil, err
-	}
-
-	m.metrics.StartMaintenanceSuccess.Inc(1)
-	return &host_svc.StartMaintenanceResponse{}, nil
+	return m.maintenanceQueue.Enqueue(hostnames)
 }
 
 // CompleteMaintenance completes maintenance on the specified hosts. It brings
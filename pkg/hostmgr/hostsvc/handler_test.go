@@ -30,6 +30,7 @@ import (
 	hm "github.com/uber/peloton/pkg/hostmgr/host/mocks"
 	ym "github.com/uber/peloton/pkg/hostmgr/mesos/yarpc/encoding/mpb/mocks"
 	qm "github.com/uber/peloton/pkg/hostmgr/queue/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
 	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
@@ -50,6 +51,7 @@ type HostSvcHandlerTestSuite struct {
 	mockMasterOperatorClient *ym.MockMasterOperatorClient
 	mockMaintenanceQueue     *qm.MockMaintenanceQueue
 	mockMaintenanceMap       *hm.MockMaintenanceHostInfoMap
+	mockMaintenanceWindowOps *objectmocks.MockMaintenanceWindowOps
 }
 
 func (suite *HostSvcHandlerTestSuite) SetupSuite() {
@@ -118,7 +120,9 @@ func (suite *HostSvcHandlerTestSuite) SetupTest() {
 	suite.mockMaintenanceMap = hm.NewMockMaintenanceHostInfoMap(suite.mockCtrl)
 	suite.handler.operatorMasterClient = suite.mockMasterOperatorClient
 	suite.handler.maintenanceQueue = suite.mockMaintenanceQueue
+	suite.mockMaintenanceWindowOps = objectmocks.NewMockMaintenanceWindowOps(suite.mockCtrl)
 	suite.handler.maintenanceHostInfoMap = suite.mockMaintenanceMap
+	suite.handler.maintenanceWindowOps = suite.mockMaintenanceWindowOps
 
 	response := suite.makeAgentsResponse()
 	loader := &host.Loader{
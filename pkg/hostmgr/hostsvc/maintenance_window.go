@@ -0,0 +1,394 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostsvc
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+
+	"github.com/uber/peloton/pkg/common/stringset"
+
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/atomic"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// _maintenanceWindowWorkName is the name of the background work which
+	// opens and tracks the maintenance windows
+	_maintenanceWindowWorkName = "maintenancewindows"
+
+	// _maintenanceWindowTimeout is the timeout to read and write the
+	// maintenance windows in a single reconciliation
+	_maintenanceWindowTimeout = 30 * time.Second
+
+	// _maintenanceWindowPurgePeriod is the minimum time between two purges
+	// of the completed maintenance windows
+	_maintenanceWindowPurgePeriod = time.Hour
+
+	// _completedMaintenanceWindowRetention is how long a completed
+	// maintenance window is kept before it is purged
+	_completedMaintenanceWindowRetention = 7 * 24 * time.Hour
+)
+
+// _openMaintenanceWindowStates are the states of the maintenance windows
+// which are reconciled
+var _openMaintenanceWindowStates = []hpb.MaintenanceWindowState{
+	hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+	hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+}
+
+// ScheduleMaintenance persists a maintenance window for the hosts. The hosts
+// start draining when the window opens at the start time, and the hosts which
+// are not DOWN at the deadline are reported in the window.
+func (m *serviceHandler) ScheduleMaintenance(
+	ctx context.Context,
+	request *host_svc.ScheduleMaintenanceRequest,
+) (*host_svc.ScheduleMaintenanceResponse, error) {
+	m.metrics.ScheduleMaintenanceAPI.Inc(1)
+
+	window, err := newMaintenanceWindow(request, time.Now())
+	if err != nil {
+		m.metrics.ScheduleMaintenanceFail.Inc(1)
+		return nil, err
+	}
+
+	m.maintenanceWindowLock.Lock()
+	defer m.maintenanceWindowLock.Unlock()
+
+	if err := m.maintenanceWindowOps.Create(ctx, window); err != nil {
+		m.metrics.ScheduleMaintenanceFail.Inc(1)
+		return nil, err
+	}
+	log.WithField("maintenance_window", window).
+		Info("Maintenance window scheduled")
+
+	m.metrics.ScheduleMaintenanceSuccess.Inc(1)
+	return &host_svc.ScheduleMaintenanceResponse{
+		WindowId: window.GetId(),
+	}, nil
+}
+
+// GetMaintenanceWindows returns all the maintenance windows ordered by
+// start time
+func (m *serviceHandler) GetMaintenanceWindows(
+	ctx context.Context,
+	request *host_svc.GetMaintenanceWindowsRequest,
+) (*host_svc.GetMaintenanceWindowsResponse, error) {
+	m.metrics.GetMaintenanceWindowsAPI.Inc(1)
+
+	windows, err := m.maintenanceWindowOps.GetAll(ctx)
+	if err != nil {
+		m.metrics.GetMaintenanceWindowsFail.Inc(1)
+		return nil, err
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if windows[i].GetStartTime() != windows[j].GetStartTime() {
+			return windows[i].GetStartTime() < windows[j].GetStartTime()
+		}
+		return windows[i].GetId() < windows[j].GetId()
+	})
+
+	m.metrics.GetMaintenanceWindowsSuccess.Inc(1)
+	return &host_svc.GetMaintenanceWindowsResponse{
+		Windows: windows,
+	}, nil
+}
+
+// CancelMaintenanceWindow deletes a maintenance window. Hosts of a window
+// which has already opened stay in maintenance until CompleteMaintenance
+// is called for them.
+func (m *serviceHandler) CancelMaintenanceWindow(
+	ctx context.Context,
+	request *host_svc.CancelMaintenanceWindowRequest,
+) (*host_svc.CancelMaintenanceWindowResponse, error) {
+	m.metrics.CancelMaintenanceWindowAPI.Inc(1)
+
+	m.maintenanceWindowLock.Lock()
+	defer m.maintenanceWindowLock.Unlock()
+
+	windows, err := m.maintenanceWindowOps.GetAll(ctx)
+	if err != nil {
+		m.metrics.CancelMaintenanceWindowFail.Inc(1)
+		return nil, err
+	}
+
+	var window *hpb.MaintenanceWindow
+	for _, w := range windows {
+		if w.GetId() == request.GetWindowId() {
+			window = w
+			break
+		}
+	}
+	if window == nil {
+		m.metrics.CancelMaintenanceWindowFail.Inc(1)
+		return nil, yarpcerrors.NotFoundErrorf(
+			"maintenance window %s not found", request.GetWindowId())
+	}
+
+	if err := m.maintenanceWindowOps.Delete(ctx, window); err != nil {
+		m.metrics.CancelMaintenanceWindowFail.Inc(1)
+		return nil, err
+	}
+	log.WithField("maintenance_window", window).
+		Info("Maintenance window cancelled")
+
+	m.metrics.CancelMaintenanceWindowSuccess.Inc(1)
+	return &host_svc.CancelMaintenanceWindowResponse{}, nil
+}
+
+// reconcileMaintenanceWindows starts draining the hosts of the scheduled
+// maintenance windows which have opened, and tracks the hosts of the open
+// windows until all of them are DOWN. Completed windows are purged once
+// their retention period has passed.
+func (m *serviceHandler) reconcileMaintenanceWindows(_ *atomic.Bool) {
+	m.maintenanceWindowLock.Lock()
+	defer m.maintenanceWindowLock.Unlock()
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(),
+		_maintenanceWindowTimeout)
+	defer cancel()
+
+	now := time.Now()
+	if now.Sub(m.lastMaintenanceWindowPurge) >= _maintenanceWindowPurgePeriod {
+		m.purgeMaintenanceWindows(ctx, now)
+	}
+
+	var windows []*hpb.MaintenanceWindow
+	for _, state := range _openMaintenanceWindowStates {
+		stateWindows, err := m.maintenanceWindowOps.GetByState(ctx, state)
+		if err != nil {
+			m.metrics.MaintenanceWindowReconcileFail.Inc(1)
+			log.WithError(err).
+				WithField("state", state).
+				Warn("Failed to get maintenance windows")
+			return
+		}
+		windows = append(windows, stateWindows...)
+	}
+
+	downHosts := stringset.New()
+	for _, hostInfo := range m.maintenanceHostInfoMap.GetDownHostInfos(
+		[]string{}) {
+		downHosts.Add(hostInfo.GetHostname())
+	}
+
+	overdueHosts := 0
+	for _, window := range windows {
+		prevState := window.GetState()
+		if m.reconcileMaintenanceWindow(window, downHosts, now) {
+			if err := m.maintenanceWindowOps.Update(
+				ctx, window, prevState); err != nil {
+				m.metrics.MaintenanceWindowReconcileFail.Inc(1)
+				log.WithError(err).
+					WithField("maintenance_window", window).
+					Warn("Failed to update maintenance window")
+			}
+		}
+		overdueHosts += len(window.GetOverdueHosts())
+	}
+	m.metrics.MaintenanceWindowOverdueHosts.Update(float64(overdueHosts))
+}
+
+// purgeMaintenanceWindows deletes the maintenance windows which completed
+// more than the retention period ago
+func (m *serviceHandler) purgeMaintenanceWindows(
+	ctx context.Context,
+	now time.Time,
+) {
+	purged, err := m.maintenanceWindowOps.PurgeCompleted(
+		ctx,
+		now.Add(-_completedMaintenanceWindowRetention))
+	if err != nil {
+		m.metrics.MaintenanceWindowReconcileFail.Inc(1)
+		log.WithError(err).Warn("Failed to purge completed maintenance windows")
+		return
+	}
+
+	m.lastMaintenanceWindowPurge = now
+	if purged > 0 {
+		log.WithField("count", purged).
+			Info("Purged completed maintenance windows")
+	}
+}
+
+// reconcileMaintenanceWindow moves a maintenance window to its next state
+// and returns true if the window was changed
+func (m *serviceHandler) reconcileMaintenanceWindow(
+	window *hpb.MaintenanceWindow,
+	downHosts stringset.StringSet,
+	now time.Time,
+) bool {
+	// The times are validated when the window is scheduled
+	startTime, _ := time.Parse(time.RFC3339, window.GetStartTime())
+	deadline, _ := time.Parse(time.RFC3339, window.GetDeadline())
+
+	switch window.GetState() {
+	case hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED:
+		if now.Before(startTime) {
+			return false
+		}
+		if err := m.startMaintenance(window.GetHostnames()); err != nil {
+			// Retry in the next reconciliation, the hosts are reported
+			// once the deadline has passed
+			m.metrics.MaintenanceWindowOpenFail.Inc(1)
+			log.WithError(err).
+				WithField("maintenance_window", window).
+				Warn("Failed to open maintenance window")
+			changed := recordDrainedHosts(window, downHosts)
+			if !now.Before(deadline) && m.updateOverdueHosts(window) {
+				changed = true
+			}
+			return changed
+		}
+		window.State = hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS
+		recordDrainedHosts(window, downHosts)
+		m.metrics.MaintenanceWindowOpen.Inc(1)
+		log.WithField("maintenance_window", window).
+			Info("Maintenance window opened")
+		return true
+
+	case hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS:
+		changed := recordDrainedHosts(window, downHosts)
+		if pendingHosts(window) == nil {
+			window.State = hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_COMPLETED
+			window.OverdueHosts = nil
+			m.metrics.MaintenanceWindowComplete.Inc(1)
+			log.WithField("maintenance_window", window).
+				Info("Maintenance window completed")
+			return true
+		}
+		if !now.Before(deadline) && m.updateOverdueHosts(window) {
+			changed = true
+		}
+		return changed
+	}
+	return false
+}
+
+// recordDrainedHosts adds the hosts of the window which are DOWN to its
+// drained hosts, and returns true if the drained hosts changed. Hosts
+// brought back up after they were drained stay drained, so that the hosts
+// of a window can be drained one at a time.
+func recordDrainedHosts(
+	window *hpb.MaintenanceWindow,
+	downHosts stringset.StringSet,
+) bool {
+	drained := stringset.New()
+	for _, hostname := range window.GetDrainedHosts() {
+		drained.Add(hostname)
+	}
+
+	changed := false
+	for _, hostname := range window.GetHostnames() {
+		if downHosts.Contains(hostname) && !drained.Contains(hostname) {
+			window.DrainedHosts = append(window.DrainedHosts, hostname)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// updateOverdueHosts sets the hosts of the window which have not been
+// drained yet as overdue, and returns true if the overdue hosts changed
+func (m *serviceHandler) updateOverdueHosts(
+	window *hpb.MaintenanceWindow,
+) bool {
+	overdueHosts := pendingHosts(window)
+	if reflect.DeepEqual(overdueHosts, window.GetOverdueHosts()) {
+		return false
+	}
+
+	if len(window.GetOverdueHosts()) == 0 {
+		m.metrics.MaintenanceWindowOverdue.Inc(1)
+	}
+	window.OverdueHosts = overdueHosts
+	log.WithFields(log.Fields{
+		"maintenance_window": window.GetId(),
+		"overdue_hosts":      overdueHosts,
+	}).Warn("Hosts not drained before maintenance window deadline")
+	return true
+}
+
+// pendingHosts returns the hosts of the window which have not reached
+// DOWN since the window opened
+func pendingHosts(window *hpb.MaintenanceWindow) []string {
+	drained := stringset.New()
+	for _, hostname := range window.GetDrainedHosts() {
+		drained.Add(hostname)
+	}
+
+	var hosts []string
+	for _, hostname := range window.GetHostnames() {
+		if !drained.Contains(hostname) {
+			hosts = append(hosts, hostname)
+		}
+	}
+	return hosts
+}
+
+// newMaintenanceWindow validates the request and creates a scheduled
+// maintenance window from it
+func newMaintenanceWindow(
+	request *host_svc.ScheduleMaintenanceRequest,
+	now time.Time,
+) (*hpb.MaintenanceWindow, error) {
+	hostSet := stringset.New()
+	var hostnames []string
+	for _, hostname := range request.GetHostnames() {
+		if hostname == "" || hostSet.Contains(hostname) {
+			continue
+		}
+		hostSet.Add(hostname)
+		hostnames = append(hostnames, hostname)
+	}
+	if len(hostnames) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf("no hosts specified")
+	}
+
+	startTime, err := time.Parse(time.RFC3339, request.GetStartTime())
+	if err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"invalid start time: %v", err)
+	}
+	deadline, err := time.Parse(time.RFC3339, request.GetDeadline())
+	if err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"invalid deadline: %v", err)
+	}
+	if !deadline.After(startTime) {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"deadline must be after start time")
+	}
+	if !deadline.After(now) {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"deadline has already passed")
+	}
+
+	return &hpb.MaintenanceWindow{
+		Id:        uuid.New(),
+		Hostnames: hostnames,
+		StartTime: startTime.UTC().Format(time.RFC3339),
+		Deadline:  deadline.UTC().Format(time.RFC3339),
+		State:     hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+	}, nil
+}
@@ -0,0 +1,440 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostsvc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mesosmaintenance "github.com/uber/peloton/.gen/mesos/v1/maintenance"
+	mesosmaster "github.com/uber/peloton/.gen/mesos/v1/master"
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	"github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	"github.com/uber/peloton/pkg/common/stringset"
+
+	"github.com/golang/mock/gomock"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// TestScheduleMaintenance tests scheduling a maintenance window
+func (suite *HostSvcHandlerTestSuite) TestScheduleMaintenance() {
+	startTime := time.Now().Add(time.Hour)
+	deadline := startTime.Add(2 * time.Hour)
+
+	var window *hpb.MaintenanceWindow
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, w *hpb.MaintenanceWindow) {
+			window = w
+		}).
+		Return(nil)
+
+	resp, err := suite.handler.ScheduleMaintenance(suite.ctx,
+		&svcpb.ScheduleMaintenanceRequest{
+			Hostnames: []string{"host1", "host2", "host1"},
+			StartTime: startTime.Format(time.RFC3339),
+			Deadline:  deadline.Format(time.RFC3339),
+		})
+	suite.NoError(err)
+	suite.NotEmpty(resp.GetWindowId())
+	suite.Equal(resp.GetWindowId(), window.GetId())
+	suite.Equal([]string{"host1", "host2"}, window.GetHostnames())
+	suite.Equal(startTime.UTC().Format(time.RFC3339), window.GetStartTime())
+	suite.Equal(deadline.UTC().Format(time.RFC3339), window.GetDeadline())
+	suite.Equal(
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+		window.GetState())
+
+	// storage error
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("fake create error"))
+	_, err = suite.handler.ScheduleMaintenance(suite.ctx,
+		&svcpb.ScheduleMaintenanceRequest{
+			Hostnames: []string{"host1"},
+			StartTime: startTime.Format(time.RFC3339),
+			Deadline:  deadline.Format(time.RFC3339),
+		})
+	suite.Error(err)
+}
+
+// TestScheduleMaintenanceInvalidRequest tests that invalid maintenance
+// windows are rejected
+func (suite *HostSvcHandlerTestSuite) TestScheduleMaintenanceInvalidRequest() {
+	now := time.Now()
+	format := func(t time.Time) string {
+		return t.Format(time.RFC3339)
+	}
+
+	tt := []struct {
+		msg     string
+		request *svcpb.ScheduleMaintenanceRequest
+	}{
+		{
+			msg: "no hosts",
+			request: &svcpb.ScheduleMaintenanceRequest{
+				StartTime: format(now),
+				Deadline:  format(now.Add(time.Hour)),
+			},
+		},
+		{
+			msg: "invalid start time",
+			request: &svcpb.ScheduleMaintenanceRequest{
+				Hostnames: []string{"host1"},
+				StartTime: "tomorrow",
+				Deadline:  format(now.Add(time.Hour)),
+			},
+		},
+		{
+			msg: "invalid deadline",
+			request: &svcpb.ScheduleMaintenanceRequest{
+				Hostnames: []string{"host1"},
+				StartTime: format(now),
+			},
+		},
+		{
+			msg: "deadline before start time",
+			request: &svcpb.ScheduleMaintenanceRequest{
+				Hostnames: []string{"host1"},
+				StartTime: format(now.Add(2 * time.Hour)),
+				Deadline:  format(now.Add(time.Hour)),
+			},
+		},
+		{
+			msg: "deadline passed",
+			request: &svcpb.ScheduleMaintenanceRequest{
+				Hostnames: []string{"host1"},
+				StartTime: format(now.Add(-2 * time.Hour)),
+				Deadline:  format(now.Add(-time.Hour)),
+			},
+		},
+	}
+
+	for _, t := range tt {
+		_, err := suite.handler.ScheduleMaintenance(suite.ctx, t.request)
+		suite.True(yarpcerrors.IsInvalidArgument(err), t.msg)
+	}
+}
+
+// TestGetMaintenanceWindows tests that maintenance windows are returned
+// ordered by start time
+func (suite *HostSvcHandlerTestSuite) TestGetMaintenanceWindows() {
+	windows := []*hpb.MaintenanceWindow{
+		{Id: "window2", StartTime: "2019-01-02T10:00:00Z"},
+		{Id: "window1", StartTime: "2019-01-01T10:00:00Z"},
+	}
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(windows, nil)
+
+	resp, err := suite.handler.GetMaintenanceWindows(suite.ctx,
+		&svcpb.GetMaintenanceWindowsRequest{})
+	suite.NoError(err)
+	suite.Len(resp.GetWindows(), 2)
+	suite.Equal("window1", resp.GetWindows()[0].GetId())
+	suite.Equal("window2", resp.GetWindows()[1].GetId())
+
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, fmt.Errorf("fake get error"))
+	_, err = suite.handler.GetMaintenanceWindows(suite.ctx,
+		&svcpb.GetMaintenanceWindowsRequest{})
+	suite.Error(err)
+}
+
+// TestCancelMaintenanceWindow tests cancelling a maintenance window
+func (suite *HostSvcHandlerTestSuite) TestCancelMaintenanceWindow() {
+	window := &hpb.MaintenanceWindow{
+		Id:    "window1",
+		State: hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+	}
+	windows := []*hpb.MaintenanceWindow{window}
+
+	gomock.InOrder(
+		suite.mockMaintenanceWindowOps.EXPECT().
+			GetAll(gomock.Any()).
+			Return(windows, nil),
+		suite.mockMaintenanceWindowOps.EXPECT().
+			Delete(gomock.Any(), window).
+			Return(nil),
+	)
+	_, err := suite.handler.CancelMaintenanceWindow(suite.ctx,
+		&svcpb.CancelMaintenanceWindowRequest{WindowId: "window1"})
+	suite.NoError(err)
+
+	// unknown window
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(windows, nil)
+	_, err = suite.handler.CancelMaintenanceWindow(suite.ctx,
+		&svcpb.CancelMaintenanceWindowRequest{WindowId: "window2"})
+	suite.True(yarpcerrors.IsNotFound(err))
+
+	// storage error
+	gomock.InOrder(
+		suite.mockMaintenanceWindowOps.EXPECT().
+			GetAll(gomock.Any()).
+			Return(windows, nil),
+		suite.mockMaintenanceWindowOps.EXPECT().
+			Delete(gomock.Any(), window).
+			Return(fmt.Errorf("fake delete error")),
+	)
+	_, err = suite.handler.CancelMaintenanceWindow(suite.ctx,
+		&svcpb.CancelMaintenanceWindowRequest{WindowId: "window1"})
+	suite.Error(err)
+}
+
+// TestReconcileMaintenanceWindows tests opening maintenance windows and
+// tracking the hosts of open windows
+func (suite *HostSvcHandlerTestSuite) TestReconcileMaintenanceWindows() {
+	now := time.Now()
+	format := func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	}
+	upHost := suite.upMachines[0].GetHostname()
+	downHost := suite.downMachines[0].GetHostname()
+
+	windows := []*hpb.MaintenanceWindow{
+		{
+			// not opened yet
+			Id:        "future",
+			Hostnames: []string{upHost},
+			StartTime: format(now.Add(time.Hour)),
+			Deadline:  format(now.Add(2 * time.Hour)),
+			State:     hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+		},
+		{
+			// opens now
+			Id:        "open",
+			Hostnames: []string{upHost},
+			StartTime: format(now.Add(-time.Minute)),
+			Deadline:  format(now.Add(time.Hour)),
+			State:     hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+		},
+		{
+			// all hosts are down
+			Id:        "complete",
+			Hostnames: []string{downHost},
+			StartTime: format(now.Add(-time.Hour)),
+			Deadline:  format(now.Add(time.Hour)),
+			State:     hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+		},
+		{
+			// draining before the deadline
+			Id:        "draining",
+			Hostnames: []string{upHost, downHost},
+			StartTime: format(now.Add(-time.Hour)),
+			Deadline:  format(now.Add(time.Hour)),
+			State:     hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+		},
+		{
+			// draining after the deadline
+			Id:        "overdue",
+			Hostnames: []string{upHost, downHost},
+			StartTime: format(now.Add(-2 * time.Hour)),
+			Deadline:  format(now.Add(-time.Hour)),
+			State:     hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+		},
+	}
+
+	updated := make(map[string]*hpb.MaintenanceWindow)
+	prevStates := make(map[string]hpb.MaintenanceWindowState)
+	suite.mockMaintenanceWindowOps.EXPECT().
+		PurgeCompleted(gomock.Any(), gomock.Any()).
+		Return(1, nil)
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetByState(gomock.Any(),
+			hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED).
+		Return(windows[:2], nil)
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetByState(gomock.Any(),
+			hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS).
+		Return(windows[2:], nil)
+	suite.mockMaintenanceMap.EXPECT().
+		GetDownHostInfos(gomock.Any()).
+		Return([]*hpb.HostInfo{
+			{
+				Hostname: downHost,
+				State:    hpb.HostState_HOST_STATE_DOWN,
+			},
+		})
+	suite.mockMasterOperatorClient.EXPECT().GetMaintenanceSchedule().
+		Return(&mesosmaster.Response_GetMaintenanceSchedule{
+			Schedule: &mesosmaintenance.Schedule{},
+		}, nil)
+	suite.mockMasterOperatorClient.EXPECT().
+		UpdateMaintenanceSchedule(gomock.Any()).Return(nil)
+	suite.mockMaintenanceMap.EXPECT().AddHostInfos(gomock.Any())
+	suite.mockMaintenanceQueue.EXPECT().
+		Enqueue([]string{upHost}).Return(nil)
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Update(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(
+			_ context.Context,
+			w *hpb.MaintenanceWindow,
+			prevState hpb.MaintenanceWindowState,
+		) {
+			updated[w.GetId()] = w
+			prevStates[w.GetId()] = prevState
+		}).
+		Return(nil).
+		Times(4)
+
+	suite.handler.reconcileMaintenanceWindows(nil)
+
+	suite.Len(updated, 4)
+	suite.Equal(
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+		prevStates["open"])
+	suite.Equal(
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+		prevStates["complete"])
+	suite.Equal(
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+		updated["open"].GetState())
+	suite.Equal(
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_COMPLETED,
+		updated["complete"].GetState())
+	suite.Equal(
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+		updated["overdue"].GetState())
+	suite.Equal([]string{upHost}, updated["overdue"].GetOverdueHosts())
+	suite.Equal([]string{downHost}, updated["overdue"].GetDrainedHosts())
+	suite.Equal(
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+		updated["draining"].GetState())
+	suite.Equal([]string{downHost}, updated["draining"].GetDrainedHosts())
+	suite.Empty(updated["draining"].GetOverdueHosts())
+}
+
+// TestReconcileRollingMaintenanceWindow tests that a window completes when
+// its hosts are drained one at a time, and brought back up in between
+func (suite *HostSvcHandlerTestSuite) TestReconcileRollingMaintenanceWindow() {
+	now := time.Now()
+	window := &hpb.MaintenanceWindow{
+		Id:        "rolling",
+		Hostnames: []string{"host1", "host2", "host3"},
+		StartTime: now.Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+		Deadline:  now.Add(-time.Hour).UTC().Format(time.RFC3339),
+		State:     hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+	}
+	downHosts := func(hostnames ...string) stringset.StringSet {
+		hosts := stringset.New()
+		for _, hostname := range hostnames {
+			hosts.Add(hostname)
+		}
+		return hosts
+	}
+
+	// host1 is DOWN, the other hosts are overdue
+	suite.True(suite.handler.reconcileMaintenanceWindow(
+		window, downHosts("host1"), now))
+	suite.Equal([]string{"host1"}, window.GetDrainedHosts())
+	suite.Equal([]string{"host2", "host3"}, window.GetOverdueHosts())
+
+	// host1 is brought back up, and host2 is DOWN
+	suite.True(suite.handler.reconcileMaintenanceWindow(
+		window, downHosts("host2"), now))
+	suite.Equal([]string{"host1", "host2"}, window.GetDrainedHosts())
+	suite.Equal([]string{"host3"}, window.GetOverdueHosts())
+
+	// nothing changes while host2 is brought back up
+	suite.False(suite.handler.reconcileMaintenanceWindow(
+		window, downHosts(), now))
+
+	// the window completes once host3 is DOWN
+	suite.True(suite.handler.reconcileMaintenanceWindow(
+		window, downHosts("host3"), now))
+	suite.Equal(
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_COMPLETED,
+		window.GetState())
+	suite.Empty(window.GetOverdueHosts())
+	suite.Equal(
+		[]string{"host1", "host2", "host3"}, window.GetDrainedHosts())
+}
+
+// TestReconcileMaintenanceWindowsOpenFail tests that the hosts of a window
+// which fails to open are reported once its deadline has passed
+func (suite *HostSvcHandlerTestSuite) TestReconcileMaintenanceWindowsOpenFail() {
+	now := time.Now()
+	window := &hpb.MaintenanceWindow{
+		Id:        "window1",
+		Hostnames: []string{"unknown-host"},
+		StartTime: now.Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+		Deadline:  now.Add(-time.Hour).UTC().Format(time.RFC3339),
+		State:     hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+	}
+
+	suite.mockMaintenanceWindowOps.EXPECT().
+		PurgeCompleted(gomock.Any(), gomock.Any()).
+		Return(0, nil)
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetByState(gomock.Any(),
+			hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED).
+		Return([]*hpb.MaintenanceWindow{window}, nil)
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetByState(gomock.Any(),
+			hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS).
+		Return(nil, nil)
+	suite.mockMaintenanceMap.EXPECT().
+		GetDownHostInfos(gomock.Any()).
+		Return([]*hpb.HostInfo{})
+	suite.mockMaintenanceWindowOps.EXPECT().
+		Update(gomock.Any(), window,
+			hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED).
+		Return(nil)
+
+	suite.handler.reconcileMaintenanceWindows(nil)
+	suite.Equal(
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+		window.GetState())
+	suite.Equal([]string{"unknown-host"}, window.GetOverdueHosts())
+
+	// storage error, the windows were purged by the previous reconcile
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetByState(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("fake get error"))
+	suite.handler.reconcileMaintenanceWindows(nil)
+}
+
+// TestPurgeMaintenanceWindows tests that completed maintenance windows
+// are purged at most once per purge period
+func (suite *HostSvcHandlerTestSuite) TestPurgeMaintenanceWindows() {
+	now := time.Now()
+
+	// purge failures are retried in the next reconcile
+	suite.mockMaintenanceWindowOps.EXPECT().
+		PurgeCompleted(gomock.Any(), now.Add(-_completedMaintenanceWindowRetention)).
+		Return(0, fmt.Errorf("fake purge error"))
+	suite.handler.purgeMaintenanceWindows(suite.ctx, now)
+	suite.True(suite.handler.lastMaintenanceWindowPurge.IsZero())
+
+	suite.mockMaintenanceWindowOps.EXPECT().
+		PurgeCompleted(gomock.Any(), now.Add(-_completedMaintenanceWindowRetention)).
+		Return(2, nil)
+	suite.handler.purgeMaintenanceWindows(suite.ctx, now)
+	suite.Equal(now, suite.handler.lastMaintenanceWindowPurge)
+
+	// not purged again within the purge period
+	suite.mockMaintenanceWindowOps.EXPECT().
+		GetByState(gomock.Any(), gomock.Any()).
+		Return(nil, nil).
+		Times(2)
+	suite.mockMaintenanceMap.EXPECT().
+		GetDownHostInfos(gomock.Any()).
+		Return([]*hpb.HostInfo{})
+	suite.handler.reconcileMaintenanceWindows(nil)
+}
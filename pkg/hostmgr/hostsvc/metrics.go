@@ -29,6 +29,30 @@ type Metrics struct {
 	QueryHostsAPI     tally.Counter
 	QueryHostsSuccess tally.Counter
 	QueryHostsFail    tally.Counter
+
+	WatchHostsAPI      tally.Counter
+	WatchHostsFail     tally.Counter
+	WatchHostsCancel   tally.Counter
+	WatchHostsOverflow tally.Counter
+
+	ScheduleMaintenanceAPI     tally.Counter
+	ScheduleMaintenanceSuccess tally.Counter
+	ScheduleMaintenanceFail    tally.Counter
+
+	GetMaintenanceWindowsAPI     tally.Counter
+	GetMaintenanceWindowsSuccess tally.Counter
+	GetMaintenanceWindowsFail    tally.Counter
+
+	CancelMaintenanceWindowAPI     tally.Counter
+	CancelMaintenanceWindowSuccess tally.Counter
+	CancelMaintenanceWindowFail    tally.Counter
+
+	MaintenanceWindowOpen          tally.Counter
+	MaintenanceWindowOpenFail      tally.Counter
+	MaintenanceWindowComplete      tally.Counter
+	MaintenanceWindowOverdue       tally.Counter
+	MaintenanceWindowOverdueHosts  tally.Gauge
+	MaintenanceWindowReconcileFail tally.Counter
 }
 
 // NewMetrics returns a new instance of host.svc.Metrics
@@ -48,5 +72,29 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		QueryHostsAPI:     apiScope.Counter("query_hosts"),
 		QueryHostsSuccess: successScope.Counter("query_hosts"),
 		QueryHostsFail:    failScope.Counter("query_hosts"),
+
+		WatchHostsAPI:      apiScope.Counter("watch_hosts"),
+		WatchHostsFail:     failScope.Counter("watch_hosts"),
+		WatchHostsCancel:   scope.Counter("watch_hosts_cancel"),
+		WatchHostsOverflow: scope.Counter("watch_hosts_overflow"),
+
+		ScheduleMaintenanceAPI:     apiScope.Counter("schedule_maintenance"),
+		ScheduleMaintenanceSuccess: successScope.Counter("schedule_maintenance"),
+		ScheduleMaintenanceFail:    failScope.Counter("schedule_maintenance"),
+
+		GetMaintenanceWindowsAPI:     apiScope.Counter("get_maintenance_windows"),
+		GetMaintenanceWindowsSuccess: successScope.Counter("get_maintenance_windows"),
+		GetMaintenanceWindowsFail:    failScope.Counter("get_maintenance_windows"),
+
+		CancelMaintenanceWindowAPI:     apiScope.Counter("cancel_maintenance_window"),
+		CancelMaintenanceWindowSuccess: successScope.Counter("cancel_maintenance_window"),
+		CancelMaintenanceWindowFail:    failScope.Counter("cancel_maintenance_window"),
+
+		MaintenanceWindowOpen:          successScope.Counter("maintenance_window_open"),
+		MaintenanceWindowOpenFail:      failScope.Counter("maintenance_window_open"),
+		MaintenanceWindowComplete:      scope.Counter("maintenance_window_complete"),
+		MaintenanceWindowOverdue:       scope.Counter("maintenance_window_overdue"),
+		MaintenanceWindowOverdueHosts:  scope.Gauge("maintenance_window_overdue_hosts"),
+		MaintenanceWindowReconcileFail: failScope.Counter("maintenance_window_reconcile"),
 	}
 }
@@ -0,0 +1,190 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostsvc
+
+import (
+	"context"
+	"time"
+
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	host_svc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	v1alphahost "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	v1alpha_host_svc "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc"
+
+	"github.com/uber/peloton/pkg/hostmgr/host"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// v1AlphaServiceHandler implements peloton.api.v1alpha.host.svc.HostService.
+// The maintenance APIs are served by the v0 handler, the v1alpha and v0
+// host messages are identical.
+type v1AlphaServiceHandler struct {
+	*serviceHandler
+}
+
+// QueryHosts returns the hosts which are in one of the specified states.
+func (m *v1AlphaServiceHandler) QueryHosts(
+	ctx context.Context,
+	request *v1alpha_host_svc.QueryHostsRequest,
+) (*v1alpha_host_svc.QueryHostsResponse, error) {
+	var hostStates []hpb.HostState
+	for _, state := range request.GetHostStates() {
+		hostStates = append(hostStates, hpb.HostState(state))
+	}
+
+	response, err := m.serviceHandler.QueryHosts(
+		ctx,
+		&host_svc.QueryHostsRequest{HostStates: hostStates},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1alpha_host_svc.QueryHostsResponse{
+		HostInfos: convertHostInfos(response.GetHostInfos()),
+	}, nil
+}
+
+// StartMaintenance puts the host(s) into DRAINING state.
+func (m *v1AlphaServiceHandler) StartMaintenance(
+	ctx context.Context,
+	request *v1alpha_host_svc.StartMaintenanceRequest,
+) (*v1alpha_host_svc.StartMaintenanceResponse, error) {
+	if _, err := m.serviceHandler.StartMaintenance(
+		ctx,
+		&host_svc.StartMaintenanceRequest{Hostnames: request.GetHostnames()},
+	); err != nil {
+		return nil, err
+	}
+	return &v1alpha_host_svc.StartMaintenanceResponse{}, nil
+}
+
+// CompleteMaintenance brings UP the specified hosts which are in maintenance.
+func (m *v1AlphaServiceHandler) CompleteMaintenance(
+	ctx context.Context,
+	request *v1alpha_host_svc.CompleteMaintenanceRequest,
+) (*v1alpha_host_svc.CompleteMaintenanceResponse, error) {
+	if _, err := m.serviceHandler.CompleteMaintenance(
+		ctx,
+		&host_svc.CompleteMaintenanceRequest{Hostnames: request.GetHostnames()},
+	); err != nil {
+		return nil, err
+	}
+	return &v1alpha_host_svc.CompleteMaintenanceResponse{}, nil
+}
+
+// WatchHosts streams the state transitions of the specified hosts. The
+// first response contains the watched hosts which are in maintenance.
+func (m *v1AlphaServiceHandler) WatchHosts(
+	request *v1alpha_host_svc.WatchHostsRequest,
+	stream v1alpha_host_svc.HostServiceServiceWatchHostsYARPCServer,
+) error {
+	m.metrics.WatchHostsAPI.Inc(1)
+
+	watchID, watchClient, err := m.maintenanceHostInfoMap.Watch(
+		request.GetHostnames(),
+	)
+	if err != nil {
+		m.metrics.WatchHostsFail.Inc(1)
+		log.WithError(err).
+			Warn("failed to create host watch client")
+		return err
+	}
+
+	defer func() {
+		m.maintenanceHostInfoMap.StopWatch(watchID)
+	}()
+
+	initResp := &v1alpha_host_svc.WatchHostsResponse{
+		WatchId:   watchID,
+		HostInfos: convertHostInfos(watchClient.HostInfos),
+	}
+	if err := stream.Send(initResp); err != nil {
+		log.WithField("watch_id", watchID).
+			WithError(err).
+			Warn("failed to send initial response for host watch")
+		return err
+	}
+
+	for {
+		select {
+		case change := <-watchClient.Input:
+			resp := &v1alpha_host_svc.WatchHostsResponse{
+				WatchId: watchID,
+				Changes: []*v1alphahost.HostStateChange{
+					convertHostStateChange(change),
+				},
+			}
+			if err := stream.Send(resp); err != nil {
+				log.WithField("watch_id", watchID).
+					WithError(err).
+					Warn("failed to send response for host watch")
+				return err
+			}
+		case s := <-watchClient.Signal:
+			log.WithFields(log.Fields{
+				"watch_id": watchID,
+				"signal":   s,
+			}).Debug("received signal")
+
+			switch s {
+			case host.WatchSignalCancel:
+				m.metrics.WatchHostsCancel.Inc(1)
+				return yarpcerrors.CancelledErrorf("watch cancelled")
+			case host.WatchSignalOverflow:
+				m.metrics.WatchHostsOverflow.Inc(1)
+				err := yarpcerrors.InternalErrorf("event overflow")
+				log.WithField("watch_id", watchID).
+					WithError(err).
+					Warn("watch stopped due to signal")
+				return err
+			default:
+				return yarpcerrors.InternalErrorf("unexpected signal: %s", s)
+			}
+		}
+	}
+}
+
+// convertHostInfos converts v0 host infos to v1alpha host infos
+func convertHostInfos(hostInfos []*hpb.HostInfo) []*v1alphahost.HostInfo {
+	var result []*v1alphahost.HostInfo
+	for _, hostInfo := range hostInfos {
+		result = append(result, convertHostInfo(hostInfo))
+	}
+	return result
+}
+
+// convertHostInfo converts a v0 host info to a v1alpha host info
+func convertHostInfo(hostInfo *hpb.HostInfo) *v1alphahost.HostInfo {
+	return &v1alphahost.HostInfo{
+		Hostname: hostInfo.GetHostname(),
+		Ip:       hostInfo.GetIp(),
+		State:    v1alphahost.HostState(hostInfo.GetState()),
+	}
+}
+
+// convertHostStateChange converts a host state change of the maintenance
+// map to a v1alpha host state change
+func convertHostStateChange(
+	change *host.HostStateChange,
+) *v1alphahost.HostStateChange {
+	return &v1alphahost.HostStateChange{
+		HostInfo:   convertHostInfo(change.HostInfo),
+		PrevState:  v1alphahost.HostState(change.PrevState),
+		UpdateTime: change.Time.UTC().Format(time.RFC3339Nano),
+	}
+}
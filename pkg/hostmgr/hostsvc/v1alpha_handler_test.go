@@ -0,0 +1,256 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	v1alphahost "github.com/uber/peloton/.gen/peloton/api/v1alpha/host"
+	v1alpha_host_svc "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc"
+	v1alphamocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc/mocks"
+
+	"github.com/uber/peloton/pkg/hostmgr/host"
+	hm "github.com/uber/peloton/pkg/hostmgr/host/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type V1AlphaHostSvcHandlerTestSuite struct {
+	suite.Suite
+
+	ctx                context.Context
+	mockCtrl           *gomock.Controller
+	handler            *v1AlphaServiceHandler
+	mockMaintenanceMap *hm.MockMaintenanceHostInfoMap
+	watchServer        *v1alphamocks.MockHostServiceServiceWatchHostsYARPCServer
+}
+
+func (suite *V1AlphaHostSvcHandlerTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.mockMaintenanceMap = hm.NewMockMaintenanceHostInfoMap(suite.mockCtrl)
+	suite.watchServer = v1alphamocks.
+		NewMockHostServiceServiceWatchHostsYARPCServer(suite.mockCtrl)
+	suite.handler = &v1AlphaServiceHandler{
+		serviceHandler: &serviceHandler{
+			metrics:                NewMetrics(tally.NoopScope),
+			maintenanceHostInfoMap: suite.mockMaintenanceMap,
+		},
+	}
+}
+
+func (suite *V1AlphaHostSvcHandlerTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestV1AlphaHostSvcHandler(t *testing.T) {
+	suite.Run(t, new(V1AlphaHostSvcHandlerTestSuite))
+}
+
+// TestQueryHosts tests that the hosts are queried with the v0 states
+// and returned as v1alpha host infos
+func (suite *V1AlphaHostSvcHandlerTestSuite) TestQueryHosts() {
+	suite.mockMaintenanceMap.EXPECT().
+		GetDrainingHostInfos([]string{}).
+		Return([]*hpb.HostInfo{
+			{
+				Hostname: "host1",
+				Ip:       "172.17.0.5",
+				State:    hpb.HostState_HOST_STATE_DRAINING,
+			},
+		})
+	suite.mockMaintenanceMap.EXPECT().
+		GetDownHostInfos([]string{}).
+		Return([]*hpb.HostInfo{})
+
+	resp, err := suite.handler.QueryHosts(
+		suite.ctx,
+		&v1alpha_host_svc.QueryHostsRequest{
+			HostStates: []v1alphahost.HostState{
+				v1alphahost.HostState_HOST_STATE_DRAINING,
+			},
+		})
+	suite.NoError(err)
+	suite.Equal([]*v1alphahost.HostInfo{
+		{
+			Hostname: "host1",
+			Ip:       "172.17.0.5",
+			State:    v1alphahost.HostState_HOST_STATE_DRAINING,
+		},
+	}, resp.GetHostInfos())
+}
+
+// TestCompleteMaintenanceFailure tests that the error of the v0 handler
+// is returned
+func (suite *V1AlphaHostSvcHandlerTestSuite) TestCompleteMaintenanceFailure() {
+	suite.mockMaintenanceMap.EXPECT().
+		GetDownHostInfos([]string{}).
+		Return([]*hpb.HostInfo{})
+
+	resp, err := suite.handler.CompleteMaintenance(
+		suite.ctx,
+		&v1alpha_host_svc.CompleteMaintenanceRequest{
+			Hostnames: []string{"host1"},
+		})
+	suite.Error(err)
+	suite.Nil(resp)
+}
+
+// TestWatchHosts tests the host state changes are streamed till the
+// watch is cancelled
+func (suite *V1AlphaHostSvcHandlerTestSuite) TestWatchHosts() {
+	watchID := "watch-id"
+	watchClient := &host.WatchClient{
+		HostInfos: []*hpb.HostInfo{
+			{
+				Hostname: "host1",
+				Ip:       "172.17.0.5",
+				State:    hpb.HostState_HOST_STATE_DOWN,
+			},
+		},
+		// do not set buffer size for input to make sure the
+		// tests sends all the changes before sending stop
+		// signal
+		Input:  make(chan *host.HostStateChange),
+		Signal: make(chan host.WatchSignal, 1),
+	}
+	changeTime := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)
+	change := &host.HostStateChange{
+		HostInfo: &hpb.HostInfo{
+			Hostname: "host1",
+			Ip:       "172.17.0.5",
+			State:    hpb.HostState_HOST_STATE_UP,
+		},
+		PrevState: hpb.HostState_HOST_STATE_DOWN,
+		Time:      changeTime,
+	}
+
+	gomock.InOrder(
+		suite.mockMaintenanceMap.EXPECT().
+			Watch([]string{"host1"}).
+			Return(watchID, watchClient, nil),
+		suite.watchServer.EXPECT().
+			Send(&v1alpha_host_svc.WatchHostsResponse{
+				WatchId: watchID,
+				HostInfos: []*v1alphahost.HostInfo{
+					{
+						Hostname: "host1",
+						Ip:       "172.17.0.5",
+						State:    v1alphahost.HostState_HOST_STATE_DOWN,
+					},
+				},
+			}).
+			Return(nil),
+		suite.watchServer.EXPECT().
+			Send(&v1alpha_host_svc.WatchHostsResponse{
+				WatchId: watchID,
+				Changes: []*v1alphahost.HostStateChange{
+					{
+						HostInfo: &v1alphahost.HostInfo{
+							Hostname: "host1",
+							Ip:       "172.17.0.5",
+							State:    v1alphahost.HostState_HOST_STATE_UP,
+						},
+						PrevState:  v1alphahost.HostState_HOST_STATE_DOWN,
+						UpdateTime: "2019-01-01T00:00:00Z",
+					},
+				},
+			}).
+			Return(nil),
+		suite.mockMaintenanceMap.EXPECT().StopWatch(watchID),
+	)
+
+	go func() {
+		watchClient.Input <- change
+		watchClient.Signal <- host.WatchSignalCancel
+	}()
+
+	err := suite.handler.WatchHosts(
+		&v1alpha_host_svc.WatchHostsRequest{Hostnames: []string{"host1"}},
+		suite.watchServer,
+	)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsCancelled(err))
+}
+
+// TestWatchHostsMaxClientReached tests WatchHosts returns
+// resource-exhausted error when max watch client is reached
+func (suite *V1AlphaHostSvcHandlerTestSuite) TestWatchHostsMaxClientReached() {
+	suite.mockMaintenanceMap.EXPECT().
+		Watch(gomock.Any()).
+		Return("", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached"))
+
+	err := suite.handler.WatchHosts(
+		&v1alpha_host_svc.WatchHostsRequest{},
+		suite.watchServer,
+	)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsResourceExhausted(err))
+}
+
+// TestWatchHostsOverflow tests WatchHosts returns internal error when
+// the watch is aborted due to overflow
+func (suite *V1AlphaHostSvcHandlerTestSuite) TestWatchHostsOverflow() {
+	watchClient := &host.WatchClient{
+		Input:  make(chan *host.HostStateChange),
+		Signal: make(chan host.WatchSignal, 1),
+	}
+	watchClient.Signal <- host.WatchSignalOverflow
+
+	suite.mockMaintenanceMap.EXPECT().
+		Watch(gomock.Any()).
+		Return("watch-id", watchClient, nil)
+	suite.watchServer.EXPECT().
+		Send(&v1alpha_host_svc.WatchHostsResponse{WatchId: "watch-id"}).
+		Return(nil)
+	suite.mockMaintenanceMap.EXPECT().StopWatch("watch-id")
+
+	err := suite.handler.WatchHosts(
+		&v1alpha_host_svc.WatchHostsRequest{},
+		suite.watchServer,
+	)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsInternal(err))
+}
+
+// TestWatchHostsSendError tests the error of sending the initial
+// response is returned
+func (suite *V1AlphaHostSvcHandlerTestSuite) TestWatchHostsSendError() {
+	watchClient := &host.WatchClient{
+		Input:  make(chan *host.HostStateChange),
+		Signal: make(chan host.WatchSignal, 1),
+	}
+
+	suite.mockMaintenanceMap.EXPECT().
+		Watch(gomock.Any()).
+		Return("watch-id", watchClient, nil)
+	suite.watchServer.EXPECT().
+		Send(gomock.Any()).
+		Return(yarpcerrors.UnavailableErrorf("stream closed"))
+	suite.mockMaintenanceMap.EXPECT().StopWatch("watch-id")
+
+	err := suite.handler.WatchHosts(
+		&v1alpha_host_svc.WatchHostsRequest{},
+		suite.watchServer,
+	)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditsvc
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// _defaultQueryRange is the time range queried if no
+	// start time is provided
+	_defaultQueryRange = 24 * time.Hour
+	// _maxQueryRange bounds the number of day partitions
+	// read by a query
+	_maxQueryRange = 31 * 24 * time.Hour
+	// _defaultLimit is the number of records returned if
+	// no limit is provided
+	_defaultLimit = 100
+	// _maxLimit bounds the number of records returned by a query
+	_maxLimit = 10000
+)
+
+type serviceHandler struct {
+	auditLogOps ormobjects.AuditLogOps
+
+	// now returns the current time, overridden in tests
+	now func() time.Time
+}
+
+// InitServiceHandler initializes the Audit Service Handler
+func InitServiceHandler(
+	d *yarpc.Dispatcher,
+	ormStore *ormobjects.Store,
+) {
+	handler := &serviceHandler{
+		auditLogOps: ormobjects.NewAuditLogOps(ormStore),
+		now:         time.Now,
+	}
+	d.Register(svc.BuildAuditServiceYARPCProcedures(handler))
+}
+
+// QueryAuditRecords returns the audit records matching the job,
+// user and time range of the request
+func (h *serviceHandler) QueryAuditRecords(
+	ctx context.Context,
+	req *svc.QueryAuditRecordsRequest,
+) (resp *svc.QueryAuditRecordsResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("request", req).
+				WithError(err).
+				Warn("AuditSVC.QueryAuditRecords failed")
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("num_records", len(resp.GetRecords())).
+			Debug("AuditSVC.QueryAuditRecords succeeded")
+	}()
+
+	filter, err := h.newFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := h.auditLogOps.Query(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &svc.QueryAuditRecordsResponse{Records: records}, nil
+}
+
+// newFilter validates the request and applies the defaults
+// of the time range and limit
+func (h *serviceHandler) newFilter(
+	req *svc.QueryAuditRecordsRequest,
+) (*ormobjects.AuditLogFilter, error) {
+	filter := &ormobjects.AuditLogFilter{
+		To:       h.now(),
+		JobID:    req.GetJobId().GetValue(),
+		Username: req.GetUsername(),
+		Limit:    req.GetLimit(),
+	}
+
+	if len(req.GetEndTime()) != 0 {
+		endTime, err := time.Parse(time.RFC3339, req.GetEndTime())
+		if err != nil {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"invalid end time: %s", req.GetEndTime())
+		}
+		filter.To = endTime
+	}
+
+	filter.From = filter.To.Add(-_defaultQueryRange)
+	if len(req.GetStartTime()) != 0 {
+		startTime, err := time.Parse(time.RFC3339, req.GetStartTime())
+		if err != nil {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"invalid start time: %s", req.GetStartTime())
+		}
+		filter.From = startTime
+	}
+
+	if filter.From.After(filter.To) {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"start time is after end time")
+	}
+	if filter.To.Sub(filter.From) > _maxQueryRange {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"time range is longer than %s", _maxQueryRange)
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = _defaultLimit
+	}
+	if filter.Limit > _maxLimit {
+		filter.Limit = _maxLimit
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type auditHandlerTestSuite struct {
+	suite.Suite
+
+	ctrl        *gomock.Controller
+	auditLogOps *objectmocks.MockAuditLogOps
+	handler     *serviceHandler
+	now         time.Time
+}
+
+func (suite *auditHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.auditLogOps = objectmocks.NewMockAuditLogOps(suite.ctrl)
+	suite.now = time.Date(2019, 1, 2, 1, 0, 0, 0, time.UTC)
+	suite.handler = &serviceHandler{
+		auditLogOps: suite.auditLogOps,
+		now:         func() time.Time { return suite.now },
+	}
+}
+
+func (suite *auditHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestAuditServiceHandler(t *testing.T) {
+	suite.Run(t, new(auditHandlerTestSuite))
+}
+
+// TestQueryAuditRecordsDefaults tests querying the records of the
+// last day with the default limit
+func (suite *auditHandlerTestSuite) TestQueryAuditRecordsDefaults() {
+	records := []*audit.AuditRecord{{Username: "user1"}}
+	suite.auditLogOps.EXPECT().
+		Query(gomock.Any(), &ormobjects.AuditLogFilter{
+			From:  suite.now.Add(-_defaultQueryRange),
+			To:    suite.now,
+			Limit: _defaultLimit,
+		}).
+		Return(records, nil)
+
+	resp, err := suite.handler.QueryAuditRecords(
+		context.Background(),
+		&svc.QueryAuditRecordsRequest{},
+	)
+	suite.NoError(err)
+	suite.Equal(records, resp.GetRecords())
+}
+
+// TestQueryAuditRecordsFilter tests querying the records of a job
+// and user in a time range
+func (suite *auditHandlerTestSuite) TestQueryAuditRecordsFilter() {
+	suite.auditLogOps.EXPECT().
+		Query(gomock.Any(), &ormobjects.AuditLogFilter{
+			From:     suite.now.Add(-time.Hour),
+			To:       suite.now,
+			JobID:    "job1",
+			Username: "user1",
+			Limit:    _maxLimit,
+		}).
+		Return(nil, nil)
+
+	_, err := suite.handler.QueryAuditRecords(
+		context.Background(),
+		&svc.QueryAuditRecordsRequest{
+			JobId:     &v1alphapeloton.JobID{Value: "job1"},
+			Username:  "user1",
+			StartTime: "2019-01-02T00:00:00Z",
+			EndTime:   "2019-01-02T01:00:00Z",
+			Limit:     _maxLimit + 1,
+		},
+	)
+	suite.NoError(err)
+}
+
+// TestQueryAuditRecordsInvalidArgument tests invalid time ranges
+func (suite *auditHandlerTestSuite) TestQueryAuditRecordsInvalidArgument() {
+	requests := []*svc.QueryAuditRecordsRequest{
+		{StartTime: "yesterday"},
+		{EndTime: "today"},
+		{
+			StartTime: "2019-01-02T00:00:00Z",
+			EndTime:   "2019-01-01T00:00:00Z",
+		},
+		{
+			StartTime: "2018-01-01T00:00:00Z",
+			EndTime:   "2019-01-01T00:00:00Z",
+		},
+	}
+
+	for _, req := range requests {
+		_, err := suite.handler.QueryAuditRecords(context.Background(), req)
+		suite.True(yarpcerrors.IsInvalidArgument(err))
+	}
+}
+
+// TestQueryAuditRecordsFailure tests a failure to read the records
+func (suite *auditHandlerTestSuite) TestQueryAuditRecordsFailure() {
+	suite.auditLogOps.EXPECT().
+		Query(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("test error"))
+
+	_, err := suite.handler.QueryAuditRecords(
+		context.Background(),
+		&svc.QueryAuditRecordsRequest{},
+	)
+	suite.Error(err)
+}
@@ -0,0 +1,451 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	updatesvc "github.com/uber/peloton/.gen/peloton/api/v0/update/svc"
+	volumesvc "github.com/uber/peloton/.gen/peloton/api/v0/volume/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/middleware/inbound"
+	"github.com/uber/peloton/pkg/storage"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_statelessJobService = "peloton.api.v1alpha.job.stateless.svc.JobService::"
+	_podService          = "peloton.api.v1alpha.pod.svc.PodService::"
+	_jobManager          = "peloton.api.v0.job.JobManager::"
+	_taskManager         = "peloton.api.v0.task.TaskManager::"
+	_cronService         = "peloton.api.v0.cron.svc.CronService::"
+	_dagService          = "peloton.api.v0.dag.svc.DagService::"
+	_updateService       = "peloton.api.v0.update.svc.UpdateService::"
+	_volumeService       = "peloton.api.v0.volume.svc.VolumeService::"
+	_watchService        = "peloton.api.v1alpha.watch.svc.WatchService::"
+	_auditService        = "peloton.api.v1alpha.audit.svc.AuditService::"
+
+	_rootRespoolPath = "/"
+)
+
+// _requests maps the procedures which act on a job, or on a cron
+// job or DAG creating jobs, to a constructor of their request message.
+// Every mutating procedure served by job manager must be listed.
+var _requests = map[string]func() proto.Message{
+	_statelessJobService + "CreateJob":         func() proto.Message { return &svc.CreateJobRequest{} },
+	_statelessJobService + "ReplaceJob":        func() proto.Message { return &svc.ReplaceJobRequest{} },
+	_statelessJobService + "PatchJob":          func() proto.Message { return &svc.PatchJobRequest{} },
+	_statelessJobService + "RestartJob":        func() proto.Message { return &svc.RestartJobRequest{} },
+	_statelessJobService + "PauseJobWorkflow":  func() proto.Message { return &svc.PauseJobWorkflowRequest{} },
+	_statelessJobService + "ResumeJobWorkflow": func() proto.Message { return &svc.ResumeJobWorkflowRequest{} },
+	_statelessJobService + "AbortJobWorkflow":  func() proto.Message { return &svc.AbortJobWorkflowRequest{} },
+	_statelessJobService + "StartJob":          func() proto.Message { return &svc.StartJobRequest{} },
+	_statelessJobService + "StopJob":           func() proto.Message { return &svc.StopJobRequest{} },
+	_statelessJobService + "DeleteJob":         func() proto.Message { return &svc.DeleteJobRequest{} },
+	_statelessJobService + "RefreshJob":        func() proto.Message { return &svc.RefreshJobRequest{} },
+	_podService + "StartPod":                   func() proto.Message { return &podsvc.StartPodRequest{} },
+	_podService + "StopPod":                    func() proto.Message { return &podsvc.StopPodRequest{} },
+	_podService + "RestartPod":                 func() proto.Message { return &podsvc.RestartPodRequest{} },
+	_podService + "RefreshPod":                 func() proto.Message { return &podsvc.RefreshPodRequest{} },
+	_podService + "DeletePodEvents":            func() proto.Message { return &podsvc.DeletePodEventsRequest{} },
+	_jobManager + "Create":                     func() proto.Message { return &job.CreateRequest{} },
+	_jobManager + "Update":                     func() proto.Message { return &job.UpdateRequest{} },
+	_jobManager + "Delete":                     func() proto.Message { return &job.DeleteRequest{} },
+	_jobManager + "Restart":                    func() proto.Message { return &job.RestartRequest{} },
+	_jobManager + "Start":                      func() proto.Message { return &job.StartRequest{} },
+	_jobManager + "Stop":                       func() proto.Message { return &job.StopRequest{} },
+	_jobManager + "Refresh":                    func() proto.Message { return &job.RefreshRequest{} },
+	_taskManager + "Start":                     func() proto.Message { return &task.StartRequest{} },
+	_taskManager + "Stop":                      func() proto.Message { return &task.StopRequest{} },
+	_taskManager + "Restart":                   func() proto.Message { return &task.RestartRequest{} },
+	_taskManager + "Refresh":                   func() proto.Message { return &task.RefreshRequest{} },
+	_taskManager + "DeletePodEvents":           func() proto.Message { return &task.DeletePodEventsRequest{} },
+	_updateService + "CreateUpdate":            func() proto.Message { return &updatesvc.CreateUpdateRequest{} },
+	_updateService + "PauseUpdate":             func() proto.Message { return &updatesvc.PauseUpdateRequest{} },
+	_updateService + "ResumeUpdate":            func() proto.Message { return &updatesvc.ResumeUpdateRequest{} },
+	_updateService + "RollbackUpdate":          func() proto.Message { return &updatesvc.RollbackUpdateRequest{} },
+	_updateService + "AbortUpdate":             func() proto.Message { return &updatesvc.AbortUpdateRequest{} },
+	_volumeService + "DeleteVolume":            func() proto.Message { return &volumesvc.DeleteVolumeRequest{} },
+	_cronService + "CreateCronJob":             func() proto.Message { return &cronsvc.CreateCronJobRequest{} },
+	_cronService + "ReplaceCronJob":            func() proto.Message { return &cronsvc.ReplaceCronJobRequest{} },
+	_cronService + "DeleteCronJob":             func() proto.Message { return &cronsvc.DeleteCronJobRequest{} },
+	_cronService + "StartCronJob":              func() proto.Message { return &cronsvc.StartCronJobRequest{} },
+	_dagService + "SubmitDag":                  func() proto.Message { return &dagsvc.SubmitDagRequest{} },
+	_dagService + "CancelDag":                  func() proto.Message { return &dagsvc.CancelDagRequest{} },
+}
+
+// _unscopedProcedures are the read-only procedures served by job
+// manager, which users restricted to a subset of resources may
+// call on any resource. Procedures which are neither listed here
+// nor in _requests are denied to such users.
+var _unscopedProcedures = map[string]struct{}{
+	_statelessJobService + "GetJob":              {},
+	_statelessJobService + "GetJobIDFromJobName": {},
+	_statelessJobService + "GetWorkflowEvents":   {},
+	_statelessJobService + "ListPods":            {},
+	_statelessJobService + "QueryPods":           {},
+	_statelessJobService + "QueryJobs":           {},
+	_statelessJobService + "ListJobs":            {},
+	_statelessJobService + "ListJobWorkflows":    {},
+	_statelessJobService + "GetReplaceJobDiff":   {},
+	_statelessJobService + "GetJobCache":         {},
+	_podService + "GetPod":                       {},
+	_podService + "GetPodEvents":                 {},
+	_podService + "BrowsePodSandbox":             {},
+	_podService + "GetPodPendingReason":          {},
+	_podService + "GetPodCache":                  {},
+	_jobManager + "Get":                          {},
+	_jobManager + "Query":                        {},
+	_jobManager + "GetCache":                     {},
+	_jobManager + "GetActiveJobs":                {},
+	_taskManager + "Get":                         {},
+	_taskManager + "List":                        {},
+	_taskManager + "Query":                       {},
+	_taskManager + "BrowseSandbox":               {},
+	_taskManager + "GetCache":                    {},
+	_taskManager + "GetPodEvents":                {},
+	_updateService + "GetUpdate":                 {},
+	_updateService + "ListUpdates":               {},
+	_updateService + "GetUpdateCache":            {},
+	_volumeService + "ListVolumes":               {},
+	_volumeService + "GetVolume":                 {},
+	_watchService + "Watch":                      {},
+	_watchService + "Cancel":                     {},
+	_cronService + "GetCronJob":                  {},
+	_cronService + "ListCronJobs":                {},
+	_dagService + "GetDag":                       {},
+	_dagService + "ListDags":                     {},
+	_auditService + "QueryAuditRecords":          {},
+}
+
+// resolver resolves the job targeted by a job manager request
+// into the resource used for authorization
+type resolver struct {
+	updateStore   storage.UpdateStore
+	volumeStore   storage.PersistentVolumeStore
+	jobIndexOps   ormobjects.JobIndexOps
+	cronJobOps    ormobjects.CronJobOps
+	dagOps        ormobjects.DagOps
+	respoolClient respool.ResourceManagerYARPCClient
+}
+
+// NewResourceResolver returns a resolver for the jobs
+// targeted by job manager requests
+func NewResourceResolver(
+	updateStore storage.UpdateStore,
+	volumeStore storage.PersistentVolumeStore,
+	jobIndexOps ormobjects.JobIndexOps,
+	cronJobOps ormobjects.CronJobOps,
+	dagOps ormobjects.DagOps,
+	respoolClient respool.ResourceManagerYARPCClient,
+) inbound.ResourceResolver {
+	return &resolver{
+		updateStore:   updateStore,
+		volumeStore:   volumeStore,
+		jobIndexOps:   jobIndexOps,
+		cronJobOps:    cronJobOps,
+		dagOps:        dagOps,
+		respoolClient: respoolClient,
+	}
+}
+
+// Resolve returns the resources of the jobs targeted by the request,
+// or nil for read-only procedures. Procedures which are not known
+// to the resolver are denied.
+func (r *resolver) Resolve(
+	ctx context.Context,
+	procedure string,
+	encoding transport.Encoding,
+	body []byte,
+) ([]*auth.Resource, error) {
+	newRequest, ok := _requests[procedure]
+	if !ok {
+		if _, ok := _unscopedProcedures[procedure]; ok {
+			return nil, nil
+		}
+		return nil, yarpcerrors.PermissionDeniedErrorf(
+			"%s cannot be authorized on a resource", procedure)
+	}
+
+	request := newRequest()
+	if err := inbound.UnmarshalRequest(encoding, body, request); err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"failed to decode request of %s: %v", procedure, err)
+	}
+
+	// a cron job being replaced targets both the jobs it creates
+	// now and the jobs it creates after the replacement, which
+	// may belong to another resource pool or owner
+	if req, ok := request.(*cronsvc.ReplaceCronJobRequest); ok {
+		existing, err := r.resolveCronJob(ctx, req.GetConfig().GetName())
+		if err != nil {
+			return nil, err
+		}
+		replacement, err := r.newConfigResource(
+			ctx, req.GetConfig().GetJobConfig())
+		if err != nil {
+			return nil, err
+		}
+		return []*auth.Resource{existing, replacement}, nil
+	}
+
+	resource, err := r.resolveRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return []*auth.Resource{resource}, nil
+}
+
+// resolveRequest returns the resource of the job targeted by a request
+func (r *resolver) resolveRequest(
+	ctx context.Context,
+	request proto.Message,
+) (*auth.Resource, error) {
+	// jobs being created are resolved from the config in the request,
+	// and cron jobs and DAGs from the configs of the jobs they create
+	switch req := request.(type) {
+	case *svc.CreateJobRequest:
+		return r.newResource(
+			ctx,
+			&peloton.ResourcePoolID{Value: req.GetSpec().GetRespoolId().GetValue()},
+			req.GetSpec().GetOwner(),
+			req.GetSpec().GetOwningTeam(),
+		)
+	case *job.CreateRequest:
+		return r.newConfigResource(ctx, req.GetConfig())
+	case *cronsvc.CreateCronJobRequest:
+		return r.newConfigResource(ctx, req.GetConfig().GetJobConfig())
+	case *cronsvc.DeleteCronJobRequest:
+		return r.resolveCronJob(ctx, req.GetName())
+	case *cronsvc.StartCronJobRequest:
+		return r.resolveCronJob(ctx, req.GetName())
+	case *dagsvc.SubmitDagRequest:
+		return r.newDagResource(ctx, req.GetSpec())
+	case *dagsvc.CancelDagRequest:
+		return r.resolveDag(ctx, req.GetId())
+	}
+
+	jobID, err := r.getJobID(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := r.jobIndexOps.GetSummary(ctx, jobID)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, yarpcerrors.NotFoundErrorf(
+				"job:%s not found", jobID.GetValue())
+		}
+		return nil, errors.Wrap(err, "failed to get job summary from DB")
+	}
+
+	return r.newResource(
+		ctx,
+		summary.GetRespoolID(),
+		summary.GetOwner(),
+		summary.GetOwningTeam(),
+	)
+}
+
+// newResource creates the resource of a job, resolving
+// the path of its resource pool
+func (r *resolver) newResource(
+	ctx context.Context,
+	respoolID *peloton.ResourcePoolID,
+	owner string,
+	owningTeam string,
+) (*auth.Resource, error) {
+	resource := &auth.Resource{
+		Owner:      owner,
+		OwningTeam: owningTeam,
+	}
+
+	if len(respoolID.GetValue()) == 0 {
+		return resource, nil
+	}
+
+	if respoolID.GetValue() == common.RootResPoolID {
+		resource.RespoolPath = _rootRespoolPath
+		return resource, nil
+	}
+
+	resp, err := r.respoolClient.GetResourcePool(
+		ctx,
+		&respool.GetRequest{Id: respoolID},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetError() != nil {
+		return nil, yarpcerrors.NotFoundErrorf(
+			"resource pool:%s not found", respoolID.GetValue())
+	}
+
+	resource.RespoolPath = resp.GetPoolinfo().GetPath().GetValue()
+	return resource, nil
+}
+
+// newConfigResource creates the resource of a job from its config
+func (r *resolver) newConfigResource(
+	ctx context.Context,
+	config *job.JobConfig,
+) (*auth.Resource, error) {
+	return r.newResource(
+		ctx,
+		config.GetRespoolID(),
+		config.GetOwner(),
+		config.GetOwningTeam(),
+	)
+}
+
+// resolveCronJob returns the resource of the jobs created by
+// a cron job
+func (r *resolver) resolveCronJob(
+	ctx context.Context,
+	name string,
+) (*auth.Resource, error) {
+	info, err := r.cronJobOps.Get(ctx, name)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, yarpcerrors.NotFoundErrorf(
+				"cron job %s not found", name)
+		}
+		return nil, errors.Wrap(err, "failed to get cron job from DB")
+	}
+	return r.newConfigResource(ctx, info.GetConfig().GetJobConfig())
+}
+
+// newDagResource creates the resource of the jobs of a DAG. The jobs
+// of a DAG share the same resource pool and owner, so the DAG is
+// resolved from the config of its first node.
+func (r *resolver) newDagResource(
+	ctx context.Context,
+	spec *dag.DagSpec,
+) (*auth.Resource, error) {
+	if len(spec.GetNodes()) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf("DAG has no nodes")
+	}
+	return r.newConfigResource(ctx, spec.GetNodes()[0].GetJobConfig())
+}
+
+// resolveDag returns the resource of the jobs created by a DAG
+func (r *resolver) resolveDag(
+	ctx context.Context,
+	dagID *dag.DagID,
+) (*auth.Resource, error) {
+	info, err := r.dagOps.Get(ctx, dagID)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, yarpcerrors.NotFoundErrorf(
+				"DAG %s not found", dagID.GetValue())
+		}
+		return nil, errors.Wrap(err, "failed to get DAG from DB")
+	}
+	return r.newDagResource(ctx, info.GetSpec())
+}
+
+// getJobID returns the id of the job targeted by a request
+func (r *resolver) getJobID(
+	ctx context.Context,
+	request proto.Message,
+) (*peloton.JobID, error) {
+	// updates and volumes are resolved from DB into the job they belong to
+	switch req := request.(type) {
+	case interface {
+		GetUpdateId() *peloton.UpdateID
+	}:
+		return r.getUpdateJobID(ctx, req.GetUpdateId())
+	case *volumesvc.DeleteVolumeRequest:
+		return r.getVolumeJobID(ctx, req.GetId())
+	}
+
+	switch req := request.(type) {
+	case interface {
+		GetJobId() *v1alphapeloton.JobID
+	}:
+		return &peloton.JobID{Value: req.GetJobId().GetValue()}, nil
+	case interface {
+		GetPodName() *v1alphapeloton.PodName
+	}:
+		jobID, _, err := util.ParseTaskID(req.GetPodName().GetValue())
+		if err != nil {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"invalid pod name: %s", req.GetPodName().GetValue())
+		}
+		return &peloton.JobID{Value: jobID}, nil
+	case interface {
+		GetId() *peloton.JobID
+	}:
+		return req.GetId(), nil
+	case interface {
+		GetJobId() *peloton.JobID
+	}:
+		return req.GetJobId(), nil
+	}
+
+	return nil, yarpcerrors.InternalErrorf(
+		"cannot get job id from request %s", proto.MessageName(request))
+}
+
+// getUpdateJobID returns the id of the job an update belongs to
+func (r *resolver) getUpdateJobID(
+	ctx context.Context,
+	updateID *peloton.UpdateID,
+) (*peloton.JobID, error) {
+	updateModel, err := r.updateStore.GetUpdate(ctx, updateID)
+	if err != nil {
+		if yarpcerrors.IsNotFound(err) {
+			return nil, yarpcerrors.NotFoundErrorf(
+				"update:%s not found", updateID.GetValue())
+		}
+		return nil, errors.Wrap(err, "failed to get update from DB")
+	}
+	return updateModel.GetJobID(), nil
+}
+
+// getVolumeJobID returns the id of the job a volume belongs to
+func (r *resolver) getVolumeJobID(
+	ctx context.Context,
+	volumeID *peloton.VolumeID,
+) (*peloton.JobID, error) {
+	info, err := r.volumeStore.GetPersistentVolume(ctx, volumeID)
+	if err != nil {
+		if _, ok := err.(*storage.VolumeNotFoundError); ok {
+			return nil, yarpcerrors.NotFoundErrorf(
+				"volume:%s not found", volumeID.GetValue())
+		}
+		return nil, errors.Wrap(err, "failed to get volume from DB")
+	}
+	return info.GetJobId(), nil
+}
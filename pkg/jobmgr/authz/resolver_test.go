@@ -0,0 +1,641 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	updatesvc "github.com/uber/peloton/.gen/peloton/api/v0/update/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/volume"
+	volumesvc "github.com/uber/peloton/.gen/peloton/api/v0/volume/svc"
+	auditsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/audit/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+	watchsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/storage"
+	storage_mocks "github.com/uber/peloton/pkg/storage/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/encoding/protobuf"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type ResolverTestSuite struct {
+	suite.Suite
+
+	ctrl          *gomock.Controller
+	updateStore   *storage_mocks.MockUpdateStore
+	volumeStore   *storage_mocks.MockPersistentVolumeStore
+	jobIndexOps   *objectmocks.MockJobIndexOps
+	cronJobOps    *objectmocks.MockCronJobOps
+	dagOps        *objectmocks.MockDagOps
+	respoolClient *respoolmocks.MockResourceManagerYARPCClient
+	resolver      *resolver
+
+	jobID     *peloton.JobID
+	respoolID *peloton.ResourcePoolID
+}
+
+func (suite *ResolverTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.updateStore = storage_mocks.NewMockUpdateStore(suite.ctrl)
+	suite.volumeStore = storage_mocks.NewMockPersistentVolumeStore(suite.ctrl)
+	suite.jobIndexOps = objectmocks.NewMockJobIndexOps(suite.ctrl)
+	suite.cronJobOps = objectmocks.NewMockCronJobOps(suite.ctrl)
+	suite.dagOps = objectmocks.NewMockDagOps(suite.ctrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.resolver = NewResourceResolver(
+		suite.updateStore,
+		suite.volumeStore,
+		suite.jobIndexOps,
+		suite.cronJobOps,
+		suite.dagOps,
+		suite.respoolClient,
+	).(*resolver)
+
+	suite.jobID = &peloton.JobID{Value: uuid.New()}
+	suite.respoolID = &peloton.ResourcePoolID{Value: uuid.New()}
+}
+
+func (suite *ResolverTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestResolver(t *testing.T) {
+	suite.Run(t, new(ResolverTestSuite))
+}
+
+func (suite *ResolverTestSuite) marshal(request proto.Message) []byte {
+	body, err := proto.Marshal(request)
+	suite.NoError(err)
+	return body
+}
+
+func (suite *ResolverTestSuite) expectJobSummary() {
+	suite.jobIndexOps.EXPECT().
+		GetSummary(gomock.Any(), suite.jobID).
+		Return(&job.JobSummary{
+			Id:         suite.jobID,
+			Owner:      "user1",
+			OwningTeam: "team1",
+			RespoolID:  suite.respoolID,
+		}, nil)
+}
+
+func (suite *ResolverTestSuite) expectRespoolPath() {
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{Id: suite.respoolID}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id:   suite.respoolID,
+				Path: &respool.ResourcePoolPath{Value: "/team1/pool1"},
+			},
+		}, nil)
+}
+
+// TestResolveJob tests resolving the job targeted by
+// v1alpha and v0 requests
+func (suite *ResolverTestSuite) TestResolveJob() {
+	expected := &auth.Resource{
+		RespoolPath: "/team1/pool1",
+		Owner:       "user1",
+		OwningTeam:  "team1",
+	}
+
+	tests := []struct {
+		procedure string
+		request   proto.Message
+	}{
+		{
+			procedure: _statelessJobService + "StopJob",
+			request: &svc.StopJobRequest{
+				JobId: &v1alphapeloton.JobID{Value: suite.jobID.GetValue()},
+			},
+		},
+		{
+			procedure: _podService + "RestartPod",
+			request: &podsvc.RestartPodRequest{
+				PodName: &v1alphapeloton.PodName{
+					Value: suite.jobID.GetValue() + "-1",
+				},
+			},
+		},
+		{
+			procedure: _jobManager + "Delete",
+			request:   &job.DeleteRequest{Id: suite.jobID},
+		},
+		{
+			procedure: _taskManager + "DeletePodEvents",
+			request:   &task.DeletePodEventsRequest{JobId: suite.jobID},
+		},
+		{
+			procedure: _podService + "DeletePodEvents",
+			request: &podsvc.DeletePodEventsRequest{
+				PodName: &v1alphapeloton.PodName{
+					Value: suite.jobID.GetValue() + "-1",
+				},
+			},
+		},
+		{
+			procedure: _updateService + "CreateUpdate",
+			request:   &updatesvc.CreateUpdateRequest{JobId: suite.jobID},
+		},
+	}
+
+	for _, test := range tests {
+		suite.expectJobSummary()
+		suite.expectRespoolPath()
+
+		resources, err := suite.resolver.Resolve(
+			context.Background(),
+			test.procedure,
+			protobuf.Encoding,
+			suite.marshal(test.request),
+		)
+		suite.NoError(err)
+		suite.Equal([]*auth.Resource{expected}, resources, test.procedure)
+	}
+}
+
+// TestResolveUpdate tests resolving an update into the job it updates
+func (suite *ResolverTestSuite) TestResolveUpdate() {
+	updateID := &peloton.UpdateID{Value: uuid.New()}
+	requests := map[string]proto.Message{
+		_updateService + "PauseUpdate":    &updatesvc.PauseUpdateRequest{UpdateId: updateID},
+		_updateService + "ResumeUpdate":   &updatesvc.ResumeUpdateRequest{UpdateId: updateID},
+		_updateService + "RollbackUpdate": &updatesvc.RollbackUpdateRequest{UpdateId: updateID},
+		_updateService + "AbortUpdate":    &updatesvc.AbortUpdateRequest{UpdateId: updateID},
+	}
+
+	for procedure, request := range requests {
+		suite.updateStore.EXPECT().
+			GetUpdate(gomock.Any(), updateID).
+			Return(&models.UpdateModel{
+				UpdateID: updateID,
+				JobID:    suite.jobID,
+			}, nil)
+		suite.expectJobSummary()
+		suite.expectRespoolPath()
+
+		resources, err := suite.resolver.Resolve(
+			context.Background(),
+			procedure,
+			protobuf.Encoding,
+			suite.marshal(request),
+		)
+		suite.NoError(err)
+		suite.Len(resources, 1, procedure)
+		suite.Equal("/team1/pool1", resources[0].RespoolPath, procedure)
+	}
+
+	// update not found
+	suite.updateStore.EXPECT().
+		GetUpdate(gomock.Any(), updateID).
+		Return(nil, yarpcerrors.NotFoundErrorf("update not found"))
+	_, err := suite.resolver.Resolve(
+		context.Background(),
+		_updateService+"AbortUpdate",
+		protobuf.Encoding,
+		suite.marshal(&updatesvc.AbortUpdateRequest{UpdateId: updateID}),
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestResolveVolume tests resolving a volume into the job it belongs to
+func (suite *ResolverTestSuite) TestResolveVolume() {
+	volumeID := &peloton.VolumeID{Value: uuid.New()}
+	request := suite.marshal(&volumesvc.DeleteVolumeRequest{Id: volumeID})
+
+	suite.volumeStore.EXPECT().
+		GetPersistentVolume(gomock.Any(), volumeID).
+		Return(&volume.PersistentVolumeInfo{
+			Id:    volumeID,
+			JobId: suite.jobID,
+		}, nil)
+	suite.expectJobSummary()
+	suite.expectRespoolPath()
+
+	resources, err := suite.resolver.Resolve(
+		context.Background(),
+		_volumeService+"DeleteVolume",
+		protobuf.Encoding,
+		request,
+	)
+	suite.NoError(err)
+	suite.Len(resources, 1)
+	suite.Equal("/team1/pool1", resources[0].RespoolPath)
+
+	// volume not found
+	suite.volumeStore.EXPECT().
+		GetPersistentVolume(gomock.Any(), volumeID).
+		Return(nil, &storage.VolumeNotFoundError{VolumeID: volumeID})
+	_, err = suite.resolver.Resolve(
+		context.Background(),
+		_volumeService+"DeleteVolume",
+		protobuf.Encoding,
+		request,
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestResolveJSONEncoding tests resolving a json encoded request
+func (suite *ResolverTestSuite) TestResolveJSONEncoding() {
+	marshaler := &jsonpb.Marshaler{}
+	body, err := marshaler.MarshalToString(&svc.StopJobRequest{
+		JobId: &v1alphapeloton.JobID{Value: suite.jobID.GetValue()},
+	})
+	suite.NoError(err)
+
+	suite.expectJobSummary()
+	suite.expectRespoolPath()
+
+	resources, err := suite.resolver.Resolve(
+		context.Background(),
+		_statelessJobService+"StopJob",
+		protobuf.JSONEncoding,
+		[]byte(body),
+	)
+	suite.NoError(err)
+	suite.Len(resources, 1)
+	suite.Equal("/team1/pool1", resources[0].RespoolPath)
+}
+
+// TestResolveCreateJob tests resolving a job being created
+// from the spec in the request
+func (suite *ResolverTestSuite) TestResolveCreateJob() {
+	suite.expectRespoolPath()
+
+	resources, err := suite.resolver.Resolve(
+		context.Background(),
+		_statelessJobService+"CreateJob",
+		protobuf.Encoding,
+		suite.marshal(&svc.CreateJobRequest{
+			Spec: &stateless.JobSpec{
+				Owner:      "user2",
+				OwningTeam: "team2",
+				RespoolId: &v1alphapeloton.ResourcePoolID{
+					Value: suite.respoolID.GetValue(),
+				},
+			},
+		}),
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{{
+		RespoolPath: "/team1/pool1",
+		Owner:       "user2",
+		OwningTeam:  "team2",
+	}}, resources)
+
+	// root resource pool is resolved without a lookup
+	resources, err = suite.resolver.Resolve(
+		context.Background(),
+		_jobManager+"Create",
+		protobuf.Encoding,
+		suite.marshal(&job.CreateRequest{
+			Config: &job.JobConfig{
+				Owner:     "user2",
+				RespoolID: &peloton.ResourcePoolID{Value: common.RootResPoolID},
+			},
+		}),
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{{
+		RespoolPath: _rootRespoolPath,
+		Owner:       "user2",
+	}}, resources)
+}
+
+// TestResolveCronJob tests resolving cron jobs from the template
+// of the jobs they create
+func (suite *ResolverTestSuite) TestResolveCronJob() {
+	config := &cron.CronJobConfig{
+		Name: "nightly",
+		JobConfig: &job.JobConfig{
+			Owner:      "user1",
+			OwningTeam: "team1",
+			RespoolID:  suite.respoolID,
+		},
+	}
+	expected := &auth.Resource{
+		RespoolPath: "/team1/pool1",
+		Owner:       "user1",
+		OwningTeam:  "team1",
+	}
+
+	// the template is in the request
+	suite.expectRespoolPath()
+	resources, err := suite.resolver.Resolve(
+		context.Background(),
+		_cronService+"CreateCronJob",
+		protobuf.Encoding,
+		suite.marshal(&cronsvc.CreateCronJobRequest{Config: config}),
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{expected}, resources)
+
+	// the template is read from DB
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), "nightly").
+		Return(&cron.CronJobInfo{Config: config}, nil)
+	suite.expectRespoolPath()
+	resources, err = suite.resolver.Resolve(
+		context.Background(),
+		_cronService+"StartCronJob",
+		protobuf.Encoding,
+		suite.marshal(&cronsvc.StartCronJobRequest{Name: "nightly"}),
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{expected}, resources)
+
+	// cron job not found
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), "nightly").
+		Return(nil, gocql.ErrNotFound)
+	_, err = suite.resolver.Resolve(
+		context.Background(),
+		_cronService+"DeleteCronJob",
+		protobuf.Encoding,
+		suite.marshal(&cronsvc.DeleteCronJobRequest{Name: "nightly"}),
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestResolveReplaceCronJob tests resolving a cron job being replaced
+// into both its existing template and the template replacing it
+func (suite *ResolverTestSuite) TestResolveReplaceCronJob() {
+	otherRespoolID := &peloton.ResourcePoolID{Value: uuid.New()}
+	existing := &cron.CronJobConfig{
+		Name: "nightly",
+		JobConfig: &job.JobConfig{
+			Owner:      "user1",
+			OwningTeam: "team1",
+			RespoolID:  suite.respoolID,
+		},
+	}
+	replacement := &cron.CronJobConfig{
+		Name: "nightly",
+		JobConfig: &job.JobConfig{
+			Owner:      "user2",
+			OwningTeam: "team2",
+			RespoolID:  otherRespoolID,
+		},
+	}
+
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), "nightly").
+		Return(&cron.CronJobInfo{Config: existing}, nil)
+	suite.expectRespoolPath()
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{Id: otherRespoolID}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id:   otherRespoolID,
+				Path: &respool.ResourcePoolPath{Value: "/team2/pool1"},
+			},
+		}, nil)
+
+	resources, err := suite.resolver.Resolve(
+		context.Background(),
+		_cronService+"ReplaceCronJob",
+		protobuf.Encoding,
+		suite.marshal(&cronsvc.ReplaceCronJobRequest{Config: replacement}),
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{
+		{
+			RespoolPath: "/team1/pool1",
+			Owner:       "user1",
+			OwningTeam:  "team1",
+		},
+		{
+			RespoolPath: "/team2/pool1",
+			Owner:       "user2",
+			OwningTeam:  "team2",
+		},
+	}, resources)
+
+	// cron job being replaced not found
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), "nightly").
+		Return(nil, gocql.ErrNotFound)
+	_, err = suite.resolver.Resolve(
+		context.Background(),
+		_cronService+"ReplaceCronJob",
+		protobuf.Encoding,
+		suite.marshal(&cronsvc.ReplaceCronJobRequest{Config: replacement}),
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestResolveDag tests resolving DAGs from the config
+// of the jobs they create
+func (suite *ResolverTestSuite) TestResolveDag() {
+	dagID := &dag.DagID{Value: uuid.New()}
+	spec := &dag.DagSpec{
+		Name: "etl",
+		Nodes: []*dag.Node{
+			{
+				Name: "extract",
+				JobConfig: &job.JobConfig{
+					Owner:      "user1",
+					OwningTeam: "team1",
+					RespoolID:  suite.respoolID,
+				},
+			},
+		},
+	}
+	expected := &auth.Resource{
+		RespoolPath: "/team1/pool1",
+		Owner:       "user1",
+		OwningTeam:  "team1",
+	}
+
+	// the spec is in the request
+	suite.expectRespoolPath()
+	resources, err := suite.resolver.Resolve(
+		context.Background(),
+		_dagService+"SubmitDag",
+		protobuf.Encoding,
+		suite.marshal(&dagsvc.SubmitDagRequest{Spec: spec}),
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{expected}, resources)
+
+	// the spec is read from DB
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), dagID).
+		Return(&dag.DagInfo{Id: dagID, Spec: spec}, nil)
+	suite.expectRespoolPath()
+	resources, err = suite.resolver.Resolve(
+		context.Background(),
+		_dagService+"CancelDag",
+		protobuf.Encoding,
+		suite.marshal(&dagsvc.CancelDagRequest{Id: dagID}),
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{expected}, resources)
+
+	// DAG not found
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), dagID).
+		Return(nil, gocql.ErrNotFound)
+	_, err = suite.resolver.Resolve(
+		context.Background(),
+		_dagService+"CancelDag",
+		protobuf.Encoding,
+		suite.marshal(&dagsvc.CancelDagRequest{Id: dagID}),
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+
+	// DAG without nodes
+	_, err = suite.resolver.Resolve(
+		context.Background(),
+		_dagService+"SubmitDag",
+		protobuf.Encoding,
+		suite.marshal(&dagsvc.SubmitDagRequest{Spec: &dag.DagSpec{}}),
+	)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestResolveNoResource tests that procedures which do not
+// act on a job are not resolved
+func (suite *ResolverTestSuite) TestResolveNoResource() {
+	resources, err := suite.resolver.Resolve(
+		context.Background(),
+		_statelessJobService+"QueryJobs",
+		protobuf.Encoding,
+		nil,
+	)
+	suite.NoError(err)
+	suite.Nil(resources)
+}
+
+// TestResolveUnknownProcedure tests that procedures which are not
+// known to the resolver are denied
+func (suite *ResolverTestSuite) TestResolveUnknownProcedure() {
+	_, err := suite.resolver.Resolve(
+		context.Background(),
+		_statelessJobService+"NewMutatingProcedure",
+		protobuf.Encoding,
+		nil,
+	)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
+// TestAllProceduresResolvable walks every procedure registered by
+// job manager, and checks that it either has a resolver or is a
+// read-only procedure, so that no mutating procedure bypasses
+// resource-scoped authorization
+func (suite *ResolverTestSuite) TestAllProceduresResolvable() {
+	var procedures []transport.Procedure
+	procedures = append(procedures, job.BuildJobManagerYARPCProcedures(nil)...)
+	procedures = append(procedures, task.BuildTaskManagerYARPCProcedures(nil)...)
+	procedures = append(procedures, updatesvc.BuildUpdateServiceYARPCProcedures(nil)...)
+	procedures = append(procedures, volumesvc.BuildVolumeServiceYARPCProcedures(nil)...)
+	procedures = append(procedures, svc.BuildJobServiceYARPCProcedures(nil)...)
+	procedures = append(procedures, podsvc.BuildPodServiceYARPCProcedures(nil)...)
+	procedures = append(procedures, watchsvc.BuildWatchServiceYARPCProcedures(nil)...)
+	procedures = append(procedures, cronsvc.BuildCronServiceYARPCProcedures(nil)...)
+	procedures = append(procedures, dagsvc.BuildDagServiceYARPCProcedures(nil)...)
+	procedures = append(procedures, auditsvc.BuildAuditServiceYARPCProcedures(nil)...)
+
+	for _, procedure := range procedures {
+		_, resolved := _requests[procedure.Name]
+		_, unscoped := _unscopedProcedures[procedure.Name]
+		suite.True(resolved || unscoped,
+			"procedure %s has no resolver", procedure.Name)
+		suite.False(resolved && unscoped,
+			"procedure %s is both resolved and unscoped", procedure.Name)
+	}
+}
+
+// TestResolveFailure tests failures to resolve a request
+func (suite *ResolverTestSuite) TestResolveFailure() {
+	stopJob := suite.marshal(&svc.StopJobRequest{
+		JobId: &v1alphapeloton.JobID{Value: suite.jobID.GetValue()},
+	})
+
+	// invalid request body
+	_, err := suite.resolver.Resolve(
+		context.Background(),
+		_statelessJobService+"StopJob",
+		protobuf.JSONEncoding,
+		[]byte("{"),
+	)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// invalid pod name
+	_, err = suite.resolver.Resolve(
+		context.Background(),
+		_podService+"StopPod",
+		protobuf.Encoding,
+		suite.marshal(&podsvc.StopPodRequest{
+			PodName: &v1alphapeloton.PodName{Value: "pod"},
+		}),
+	)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// job not found
+	suite.jobIndexOps.EXPECT().
+		GetSummary(gomock.Any(), suite.jobID).
+		Return(nil, gocql.ErrNotFound)
+	_, err = suite.resolver.Resolve(
+		context.Background(),
+		_statelessJobService+"StopJob",
+		protobuf.Encoding,
+		stopJob,
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+
+	// resource pool not found
+	suite.expectJobSummary()
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), gomock.Any()).
+		Return(&respool.GetResponse{
+			Error: &respool.GetResponse_Error{
+				NotFound: &respool.ResourcePoolNotFound{Id: suite.respoolID},
+			},
+		}, nil)
+	_, err = suite.resolver.Resolve(
+		context.Background(),
+		_statelessJobService+"StopJob",
+		protobuf.Encoding,
+		stopJob,
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
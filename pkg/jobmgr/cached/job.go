@@ -286,6 +286,7 @@ type cachedConfig struct {
 	jobType           pbjob.JobType           // Job type (batch or service) in the job configuration
 	changeLog         *peloton.ChangeLog      // ChangeLog in the job configuration
 	respoolID         *peloton.ResourcePoolID // Resource Pool ID in the job configuration
+	owner             string                  // Owner of the job in the job configuration
 	hasControllerTask bool                    // if the job contains any task which is controller task
 }
 
@@ -1200,6 +1201,8 @@ func (j *job) populateJobConfigCache(config *pbjob.JobConfig) {
 		j.config.respoolID = config.GetRespoolID()
 	}
 
+	j.config.owner = config.GetOwner()
+
 	j.config.hasControllerTask = hasControllerTask(config)
 
 	j.config.jobType = config.GetType()
@@ -2171,6 +2174,10 @@ func (c *cachedConfig) GetChangeLog() *peloton.ChangeLog {
 	return &tmpChangeLog
 }
 
+func (c *cachedConfig) GetOwner() string {
+	return c.owner
+}
+
 func (c *cachedConfig) GetSLA() *pbjob.SlaConfig {
 	if c.sla == nil {
 		return nil
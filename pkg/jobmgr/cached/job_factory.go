@@ -21,6 +21,7 @@ import (
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 	"github.com/uber/peloton/pkg/storage"
 	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 
@@ -252,6 +253,16 @@ func (f *jobFactory) notifyJobRuntimeChanged(
 	}
 }
 
+func (f *jobFactory) notifyUpdateRuntimeChanged(
+	updateInfo *models.UpdateModel) {
+
+	if updateInfo != nil {
+		for _, l := range f.listeners {
+			l.UpdateRuntimeChanged(updateInfo.GetJobID(), updateInfo)
+		}
+	}
+}
+
 func (f *jobFactory) notifyTaskRuntimeChanged(
 	jobID *peloton.JobID,
 	instanceID uint32,
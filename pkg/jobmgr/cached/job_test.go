@@ -315,6 +315,7 @@ func (suite *JobTestSuite) TestJobSetAndFetchConfigAndRuntime() {
 		ChangeLog: &peloton.ChangeLog{
 			Version: 1,
 		},
+		Owner: "owner",
 	}
 	jobRuntime := &pbjob.RuntimeInfo{
 		State:                pbjob.JobState_RUNNING,
@@ -344,6 +345,7 @@ func (suite *JobTestSuite) TestJobSetAndFetchConfigAndRuntime() {
 	suite.Equal(maxRunningTime, actJobConfig.GetSLA().GetMaxRunningTime())
 	suite.Equal(pbjob.JobType_BATCH, actJobConfig.GetType())
 	suite.Equal(jobConfig.RespoolID.Value, actJobConfig.GetRespoolID().Value)
+	suite.Equal("owner", actJobConfig.GetOwner())
 	suite.Equal(jobRuntime.UpdateID.Value, actJobRuntime.GetUpdateID().Value)
 	suite.checkListenersNotCalled()
 }
@@ -18,12 +18,14 @@ import (
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 )
 
 type FakeJobListener struct {
 	jobID      *peloton.JobID
 	jobType    pbjob.JobType
 	jobRuntime *pbjob.RuntimeInfo
+	updateInfo *models.UpdateModel
 }
 
 func (l *FakeJobListener) Name() string {
@@ -47,9 +49,16 @@ func (l *FakeJobListener) TaskRuntimeChanged(
 	labels []*peloton.Label) {
 }
 
+func (l *FakeJobListener) UpdateRuntimeChanged(
+	jobID *peloton.JobID,
+	updateInfo *models.UpdateModel) {
+	l.updateInfo = updateInfo
+}
+
 func (l *FakeJobListener) Reset() {
 	l.jobID = nil
 	l.jobRuntime = nil
+	l.updateInfo = nil
 }
 
 type FakeTaskListener struct {
@@ -82,3 +91,8 @@ func (l *FakeTaskListener) TaskRuntimeChanged(
 	l.taskRuntime = runtime
 	l.labels = labels
 }
+
+func (l *FakeTaskListener) UpdateRuntimeChanged(
+	jobID *peloton.JobID,
+	updateInfo *models.UpdateModel) {
+}
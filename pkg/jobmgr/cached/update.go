@@ -248,6 +248,11 @@ func (u *update) Create(
 	workflowType models.WorkflowType,
 	updateConfig *pbupdate.UpdateConfig,
 	opaqueData *peloton.OpaqueData) error {
+	var updateInfo *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateRuntimeChanged(updateInfo)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -295,6 +300,7 @@ func (u *update) Create(
 	}
 
 	u.populateCache(updateModel)
+	updateInfo = u.getUpdateModel()
 
 	return nil
 }
@@ -304,6 +310,11 @@ func (u *update) Modify(
 	instancesAdded []uint32,
 	instancesUpdated []uint32,
 	instancesRemoved []uint32) error {
+	var updateInfo *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateRuntimeChanged(updateInfo)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -352,6 +363,7 @@ func (u *update) Modify(
 	u.instancesRemoved = instancesRemoved
 	u.instancesTotal = append(instancesUpdated, instancesAdded...)
 	u.instancesTotal = append(u.instancesTotal, instancesRemoved...)
+	updateInfo = u.getUpdateModel()
 
 	log.WithField("update_id", u.id.GetValue()).
 		WithField("instances_total", len(u.instancesTotal)).
@@ -368,6 +380,11 @@ func (u *update) WriteProgress(
 	instancesDone []uint32,
 	instancesFailed []uint32,
 	instancesCurrent []uint32) error {
+	var updateInfo *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateRuntimeChanged(updateInfo)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -386,17 +403,27 @@ func (u *update) WriteProgress(
 		state = pbupdate.State_PAUSED
 	}
 
-	return u.writeProgress(
+	if err := u.writeProgress(
 		ctx,
 		state,
 		instancesDone,
 		instancesFailed,
 		instancesCurrent,
 		nil,
-	)
+	); err != nil {
+		return err
+	}
+
+	updateInfo = u.getUpdateModel()
+	return nil
 }
 
 func (u *update) Pause(ctx context.Context, opaqueData *peloton.OpaqueData) error {
+	var updateInfo *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateRuntimeChanged(updateInfo)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -410,17 +437,27 @@ func (u *update) Pause(ctx context.Context, opaqueData *peloton.OpaqueData) erro
 		return nil
 	}
 
-	return u.writeProgress(
+	if err := u.writeProgress(
 		ctx,
 		pbupdate.State_PAUSED,
 		u.instancesDone,
 		u.instancesFailed,
 		u.instancesCurrent,
 		opaqueData,
-	)
+	); err != nil {
+		return err
+	}
+
+	updateInfo = u.getUpdateModel()
+	return nil
 }
 
 func (u *update) Resume(ctx context.Context, opaqueData *peloton.OpaqueData) error {
+	var updateInfo *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateRuntimeChanged(updateInfo)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -434,14 +471,19 @@ func (u *update) Resume(ctx context.Context, opaqueData *peloton.OpaqueData) err
 		return nil
 	}
 
-	return u.writeProgress(
+	if err := u.writeProgress(
 		ctx,
 		u.prevState,
 		u.instancesDone,
 		u.instancesFailed,
 		u.instancesCurrent,
 		opaqueData,
-	)
+	); err != nil {
+		return err
+	}
+
+	updateInfo = u.getUpdateModel()
+	return nil
 }
 
 // writeProgress write update progress into cache and db,
@@ -549,7 +591,7 @@ func (u *update) recover(ctx context.Context) error {
 		ctx,
 		u.jobID,
 		u.WorkflowStrategy,
-		u.updateConfig.GetMaxInstanceAttempts(),
+		u.updateConfig,
 		u.jobVersion,
 		u.instancesTotal,
 		u.instancesRemoved,
@@ -563,6 +605,11 @@ func (u *update) recover(ctx context.Context) error {
 }
 
 func (u *update) Cancel(ctx context.Context, opaqueData *peloton.OpaqueData) error {
+	var updateInfo *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateRuntimeChanged(updateInfo)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -571,14 +618,19 @@ func (u *update) Cancel(ctx context.Context, opaqueData *peloton.OpaqueData) err
 		return err
 	}
 
-	return u.writeProgress(
+	if err := u.writeProgress(
 		ctx,
 		pbupdate.State_ABORTED,
 		u.instancesDone,
 		u.instancesFailed,
 		u.instancesCurrent,
 		opaqueData,
-	)
+	); err != nil {
+		return err
+	}
+
+	updateInfo = u.getUpdateModel()
+	return nil
 }
 
 // Rollback rolls back the current update.
@@ -587,6 +639,11 @@ func (u *update) Rollback(
 	currentConfig *pbjob.JobConfig,
 	targetConfig *pbjob.JobConfig,
 ) error {
+	var updateInfo *models.UpdateModel
+	// notify listeners after dropping the lock
+	defer func() {
+		u.jobFactory.notifyUpdateRuntimeChanged(updateInfo)
+	}()
 	u.Lock()
 	defer u.Unlock()
 
@@ -658,6 +715,7 @@ func (u *update) Rollback(
 	u.instancesDone = []uint32{}
 	u.instancesFailed = []uint32{}
 	u.populateCache(updateModel)
+	updateInfo = u.getUpdateModel()
 
 	return nil
 }
@@ -816,6 +874,27 @@ func (u *update) populateCache(updateModel *models.UpdateModel) {
 	u.WorkflowStrategy = getWorkflowStrategy(updateModel.GetState(), updateModel.GetType())
 }
 
+// getUpdateModel returns the state and progress of the update in cache as
+// an update model, it must be called with lock held.
+func (u *update) getUpdateModel() *models.UpdateModel {
+	instancesCurrent := make([]uint32, len(u.instancesCurrent))
+	copy(instancesCurrent, u.instancesCurrent)
+
+	return &models.UpdateModel{
+		UpdateID:             u.id,
+		JobID:                u.jobID,
+		Type:                 u.workflowType,
+		State:                u.state,
+		PrevState:            u.prevState,
+		JobConfigVersion:     u.jobVersion,
+		PrevJobConfigVersion: u.jobPrevVersion,
+		InstancesTotal:       uint32(len(u.instancesTotal)),
+		InstancesDone:        uint32(len(u.instancesDone)),
+		InstancesFailed:      uint32(len(u.instancesFailed)),
+		InstancesCurrent:     instancesCurrent,
+	}
+}
+
 func (u *update) clearCache() {
 	u.state = pbupdate.State_INVALID
 	u.prevState = pbupdate.State_INVALID
@@ -844,7 +923,7 @@ func GetUpdateProgress(
 		ctx,
 		jobID,
 		cachedUpdate,
-		cachedUpdate.GetUpdateConfig().GetMaxInstanceAttempts(),
+		cachedUpdate.GetUpdateConfig(),
 		desiredConfigVersion,
 		instancesToCheck,
 		cachedUpdate.GetInstancesRemoved(),
@@ -858,7 +937,7 @@ func getUpdateProgress(
 	ctx context.Context,
 	jobID *peloton.JobID,
 	strategy WorkflowStrategy,
-	maxInstanceAttempts uint32,
+	updateConfig *pbupdate.UpdateConfig,
 	desiredConfigVersion uint64,
 	instancesToCheck []uint32,
 	instancesRemoved []uint32,
@@ -884,7 +963,7 @@ func getUpdateProgress(
 
 		if strategy.IsInstanceComplete(desiredConfigVersion, runtime) {
 			instancesDone = append(instancesDone, instID)
-		} else if strategy.IsInstanceFailed(runtime, maxInstanceAttempts) {
+		} else if strategy.IsInstanceFailed(runtime, updateConfig) {
 			instancesFailed = append(instancesFailed, instID)
 		} else if strategy.IsInstanceInProgress(desiredConfigVersion, runtime) {
 			// instances set to desired configuration, but has not entered RUNNING state
@@ -505,6 +505,53 @@ func (suite *UpdateTestSuite) TestPauseSuccess() {
 	)
 }
 
+// TestPauseNotifyListeners tests that listeners are notified with the
+// state and progress of the update after it is paused, and are not
+// notified if the update is not changed
+func (suite *UpdateTestSuite) TestPauseNotifyListeners() {
+	listener := new(FakeJobListener)
+	suite.update.jobFactory.listeners = []JobTaskListener{listener}
+	suite.update.jobID = suite.jobID
+	suite.update.workflowType = models.WorkflowType_UPDATE
+	suite.update.state = pbupdate.State_ROLLING_FORWARD
+	suite.update.jobVersion = 2
+	suite.update.jobPrevVersion = 1
+	suite.update.instancesTotal = []uint32{0, 1, 2}
+	suite.update.instancesDone = []uint32{0}
+	suite.update.instancesCurrent = []uint32{1}
+
+	suite.updateStore.EXPECT().
+		AddJobUpdateEvent(
+			gomock.Any(),
+			suite.updateID,
+			gomock.Any(),
+			pbupdate.State_PAUSED).
+		Return(nil)
+	suite.updateStore.EXPECT().
+		WriteUpdateProgress(gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	suite.NoError(suite.update.Pause(context.Background(), nil))
+
+	suite.Equal(suite.jobID, listener.updateInfo.GetJobID())
+	suite.Equal(suite.updateID, listener.updateInfo.GetUpdateID())
+	suite.Equal(models.WorkflowType_UPDATE, listener.updateInfo.GetType())
+	suite.Equal(pbupdate.State_PAUSED, listener.updateInfo.GetState())
+	suite.Equal(
+		pbupdate.State_ROLLING_FORWARD,
+		listener.updateInfo.GetPrevState())
+	suite.Equal(uint64(2), listener.updateInfo.GetJobConfigVersion())
+	suite.Equal(uint64(1), listener.updateInfo.GetPrevJobConfigVersion())
+	suite.Equal(uint32(3), listener.updateInfo.GetInstancesTotal())
+	suite.Equal(uint32(1), listener.updateInfo.GetInstancesDone())
+	suite.Equal([]uint32{1}, listener.updateInfo.GetInstancesCurrent())
+
+	// already paused, listeners are not notified
+	listener.Reset()
+	suite.NoError(suite.update.Pause(context.Background(), nil))
+	suite.Nil(listener.updateInfo)
+}
+
 // TestPauseRecoverFail tests the failure case of
 // pause an update due to recover failure
 func (suite *UpdateTestSuite) TestPauseRecoverFail() {
@@ -47,7 +47,9 @@ type WorkflowStrategy interface {
 	// TODO: now a task can both get true for IsInstanceInProgress and
 	// IsInstanceFailed, it should get true for only one of the func.
 	// Now the correctness of code is guarded by order of func call.
-	IsInstanceFailed(runtime *pbtask.RuntimeInfo, maxAttempts uint32) bool
+	IsInstanceFailed(
+		runtime *pbtask.RuntimeInfo,
+		updateConfig *pbupdate.UpdateConfig) bool
 	// GetRuntimeDiff accepts the current task runtime of an instance and the desired
 	// job config, it returns the RuntimeDiff to move the instance to the state desired
 	// by the workflow. Return nil if no action is needed.
@@ -84,11 +86,13 @@ func (s *updateStrategy) IsInstanceComplete(desiredConfigVersion uint64, runtime
 	// 1. runtime desired configuration is set to desiredConfigVersion
 	// 2. runtime configuration is set to desired configuration
 	// 3. healthy state is DISABLED or HEALTHY
+	// 4. readiness check has passed, if the task has one
 	if runtime.GetState() == pbtask.TaskState_RUNNING {
 		return runtime.GetDesiredConfigVersion() == desiredConfigVersion &&
 			runtime.GetConfigVersion() == runtime.GetDesiredConfigVersion() &&
 			(runtime.GetHealthy() == pbtask.HealthState_DISABLED ||
-				runtime.GetHealthy() == pbtask.HealthState_HEALTHY)
+				runtime.GetHealthy() == pbtask.HealthState_HEALTHY) &&
+			updateutil.IsTaskReady(runtime)
 	}
 
 	// for a terminated task, update is completed if:
@@ -105,8 +109,17 @@ func (s *updateStrategy) IsInstanceComplete(desiredConfigVersion uint64, runtime
 
 func (s *updateStrategy) IsInstanceFailed(
 	runtime *pbtask.RuntimeInfo,
-	maxAttempts uint32) bool {
-	return updateutil.HasFailedUpdate(runtime, maxAttempts)
+	updateConfig *pbupdate.UpdateConfig) bool {
+	if updateutil.HasFailedUpdate(
+		runtime, updateConfig.GetMaxInstanceAttempts()) {
+		return true
+	}
+
+	// an updated task which does not pass its readiness
+	// check in time is counted as failed
+	return runtime.GetConfigVersion() == runtime.GetDesiredConfigVersion() &&
+		updateutil.HasReadinessTimedOut(
+			runtime, updateConfig.GetReadinessTimeoutSecs())
 }
 
 func (s *updateStrategy) IsInstanceInProgress(desiredConfigVersion uint64, runtime *pbtask.RuntimeInfo) bool {
@@ -232,7 +245,7 @@ func (s *stopStrategy) IsInstanceInProgress(desiredConfigVersion uint64, runtime
 
 func (s *stopStrategy) IsInstanceFailed(
 	runtime *pbtask.RuntimeInfo,
-	maxAttempts uint32) bool {
+	updateConfig *pbupdate.UpdateConfig) bool {
 	return false
 }
 
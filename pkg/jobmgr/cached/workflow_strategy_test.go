@@ -16,10 +16,12 @@ package cached
 
 import (
 	"testing"
+	"time"
 
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 
 	"github.com/stretchr/testify/assert"
@@ -77,6 +79,30 @@ func TestUpdateStrategyIsInstanceComplete(t *testing.T) {
 			desiredConfigVersion: 2,
 			completed:            true,
 		},
+		{
+			taskRuntime: &pbtask.RuntimeInfo{
+				State:                pbtask.TaskState_RUNNING,
+				GoalState:            pbtask.TaskState_RUNNING,
+				ConfigVersion:        2,
+				DesiredConfigVersion: 2,
+				Healthy:              pbtask.HealthState_DISABLED,
+				Readiness:            pbtask.HealthState_HEALTH_UNKNOWN,
+			},
+			desiredConfigVersion: 2,
+			completed:            false,
+		},
+		{
+			taskRuntime: &pbtask.RuntimeInfo{
+				State:                pbtask.TaskState_RUNNING,
+				GoalState:            pbtask.TaskState_RUNNING,
+				ConfigVersion:        2,
+				DesiredConfigVersion: 2,
+				Healthy:              pbtask.HealthState_HEALTHY,
+				Readiness:            pbtask.HealthState_HEALTHY,
+			},
+			desiredConfigVersion: 2,
+			completed:            true,
+		},
 		{
 			taskRuntime: &pbtask.RuntimeInfo{
 				State:                pbtask.TaskState_PENDING,
@@ -233,12 +259,69 @@ func TestUpdateStrategyIsInstanceFailed(t *testing.T) {
 			strategy.IsInstanceFailed(
 				&pbtask.RuntimeInfo{
 					FailureCount: test.failureCount,
-				}, test.maxAttempts),
+				}, &pbupdate.UpdateConfig{
+					MaxInstanceAttempts: test.maxAttempts,
+				}),
 			test.result,
 			"test %d fails", id)
 	}
 }
 
+// TestUpdateStrategyIsInstanceFailedReadinessTimeout tests IsInstanceFailed
+// for updateStrategy when the readiness check does not pass in time
+func TestUpdateStrategyIsInstanceFailedReadinessTimeout(t *testing.T) {
+	startTime := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+	updateConfig := &pbupdate.UpdateConfig{ReadinessTimeoutSecs: 60}
+
+	tests := []struct {
+		taskRuntime *pbtask.RuntimeInfo
+		isFailed    bool
+	}{
+		{
+			// updated task is not ready after the timeout
+			taskRuntime: &pbtask.RuntimeInfo{
+				State:                pbtask.TaskState_RUNNING,
+				StartTime:            startTime,
+				Readiness:            pbtask.HealthState_UNHEALTHY,
+				ConfigVersion:        2,
+				DesiredConfigVersion: 2,
+			},
+			isFailed: true,
+		},
+		{
+			// updated task is ready
+			taskRuntime: &pbtask.RuntimeInfo{
+				State:                pbtask.TaskState_RUNNING,
+				StartTime:            startTime,
+				Readiness:            pbtask.HealthState_HEALTHY,
+				ConfigVersion:        2,
+				DesiredConfigVersion: 2,
+			},
+			isFailed: false,
+		},
+		{
+			// task is still running the previous config
+			taskRuntime: &pbtask.RuntimeInfo{
+				State:                pbtask.TaskState_RUNNING,
+				StartTime:            startTime,
+				Readiness:            pbtask.HealthState_UNHEALTHY,
+				ConfigVersion:        1,
+				DesiredConfigVersion: 2,
+			},
+			isFailed: false,
+		},
+	}
+
+	for id, test := range tests {
+		strategy := newUpdateStrategy()
+		assert.Equal(
+			t,
+			strategy.IsInstanceFailed(test.taskRuntime, updateConfig),
+			test.isFailed,
+			"test %d fails", id)
+	}
+}
+
 // TestUpdateStrategyGetRuntimeDiff tests GetRuntimeDiff
 // for updateStrategy
 func TestUpdateStrategyGetRuntimeDiff(t *testing.T) {
@@ -483,7 +566,9 @@ func TestRestartStrategyIsInstanceFailed(t *testing.T) {
 			strategy.IsInstanceFailed(
 				&pbtask.RuntimeInfo{
 					FailureCount: test.failureCount,
-				}, test.maxAttempts),
+				}, &pbupdate.UpdateConfig{
+					MaxInstanceAttempts: test.maxAttempts,
+				}),
 			test.result,
 			"test %d fails", id)
 	}
@@ -905,7 +990,9 @@ func TestStopStrategyIsInstanceFailed(t *testing.T) {
 		strategy := newStopStrategy()
 		assert.Equal(
 			t,
-			strategy.IsInstanceFailed(test.taskRuntime, 1),
+			strategy.IsInstanceFailed(
+				test.taskRuntime,
+				&pbupdate.UpdateConfig{MaxInstanceAttempts: 1}),
 			test.isFailed,
 			"test %d fails", id)
 	}
@@ -18,6 +18,7 @@ package common
 // update request. This list is maintained in sorted order.
 const (
 	AgentIDField              = "AgentID"
+	AvoidHostField            = "AvoidHost"
 	CompletionTimeField       = "CompletionTime"
 	ConfigVersionField        = "ConfigVersion"
 	DesiredConfigVersionField = "DesiredConfigVersion"
@@ -31,6 +32,7 @@ const (
 	MessageField              = "Message"
 	PortsField                = "Ports"
 	PrevMesosTaskIDField      = "PrevMesosTaskId"
+	ReadinessField            = "Readiness"
 	ReasonField               = "Reason"
 	ResourceUsageField        = "ResourceUsage"
 	RevisionField             = "Revision"
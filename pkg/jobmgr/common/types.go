@@ -41,6 +41,8 @@ type JobConfig interface {
 	GetSLA() *pbjob.SlaConfig
 	// GetChangeLog returns the changeLog in the job config stored in the cache
 	GetChangeLog() *peloton.ChangeLog
+	// GetOwner returns the owner of the job stored in the cache
+	GetOwner() string
 }
 
 // RuntimeDiff to be applied to the runtime struct.
@@ -17,6 +17,7 @@ package jobmgr
 import (
 	"time"
 
+	"github.com/uber/peloton/pkg/jobmgr/cronsvc"
 	"github.com/uber/peloton/pkg/jobmgr/goalstate"
 	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
 	"github.com/uber/peloton/pkg/jobmgr/task/deadline"
@@ -56,6 +57,9 @@ type Config struct {
 	// Watch API specific configuration
 	Watch watchsvc.Config `yaml:"watch"`
 
+	// Cron service specific configuration
+	CronSvcCfg cronsvc.Config `yaml:"cron_service"`
+
 	// Period in sec for updating active cache
 	ActiveTaskUpdatePeriod time.Duration `yaml:"active_task_update_period"`
 
@@ -0,0 +1,35 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronsvc
+
+import (
+	"time"
+)
+
+const (
+	_defaultSchedulePeriod = 10 * time.Second
+)
+
+// Config for cron service
+type Config struct {
+	// Period to check the schedule of the cron jobs
+	SchedulePeriod time.Duration `yaml:"schedule_period"`
+}
+
+func (c *Config) normalize() {
+	if c.SchedulePeriod == 0 {
+		c.SchedulePeriod = _defaultSchedulePeriod
+	}
+}
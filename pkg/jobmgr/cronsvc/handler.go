@@ -0,0 +1,633 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronsvc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common/background"
+	"github.com/uber/peloton/pkg/common/leader"
+	"github.com/uber/peloton/pkg/common/util"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/atomic"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// _defaultRunLimit is the number of runs returned by
+	// GetCronJob if no limit is provided
+	_defaultRunLimit = 10
+	// _maxRunLimit bounds the number of runs returned by GetCronJob
+	_maxRunLimit = 1000
+
+	// CronJobLabelKey is the key of the label added to the batch
+	// jobs created by a cron job, with the name of the cron job
+	// as value
+	CronJobLabelKey = "cron_job_name"
+)
+
+// serviceHandler implements peloton.api.v0.cron.svc.CronService and
+// runs the cron jobs on their schedule while the job manager is leader
+type serviceHandler struct {
+	// serializes the runs of the cron jobs with the changes
+	// to their configuration
+	sync.Mutex
+
+	cronJobOps    ormobjects.CronJobOps
+	cronJobRunOps ormobjects.CronJobRunOps
+	jobHandler    job.JobManagerYARPCServer
+	taskHandler   task.TaskManagerYARPCServer
+	candidate     leader.Candidate
+	metrics       *Metrics
+
+	// now returns the current time, overridden in tests
+	now func() time.Time
+}
+
+// InitServiceHandler initializes the Cron Service Handler, and
+// registers the background work which runs the cron jobs on their
+// schedule. Batch jobs are created and killed using the job and
+// task handlers of job manager.
+func InitServiceHandler(
+	d *yarpc.Dispatcher,
+	parent tally.Scope,
+	ormStore *ormobjects.Store,
+	jobHandler job.JobManagerYARPCServer,
+	taskHandler task.TaskManagerYARPCServer,
+	candidate leader.Candidate,
+	backgroundManager background.Manager,
+	cfg Config,
+) {
+	cfg.normalize()
+	handler := &serviceHandler{
+		cronJobOps:    ormobjects.NewCronJobOps(ormStore),
+		cronJobRunOps: ormobjects.NewCronJobRunOps(ormStore),
+		jobHandler:    jobHandler,
+		taskHandler:   taskHandler,
+		candidate:     candidate,
+		metrics:       NewMetrics(parent.SubScope("jobmgr").SubScope("cron")),
+		now:           time.Now,
+	}
+	d.Register(svc.BuildCronServiceYARPCProcedures(handler))
+
+	if err := backgroundManager.RegisterWorks(
+		background.Work{
+			Name: "CronScheduler",
+			Func: func(_ *atomic.Bool) {
+				handler.runSchedule(context.Background())
+			},
+			Period: cfg.SchedulePeriod,
+		},
+	); err != nil {
+		log.WithError(err).Fatal("Cannot register cron scheduler")
+	}
+}
+
+// CreateCronJob creates a new cron job
+func (h *serviceHandler) CreateCronJob(
+	ctx context.Context,
+	req *svc.CreateCronJobRequest,
+) (resp *svc.CreateCronJobResponse, err error) {
+	h.metrics.CronAPICreate.Inc(1)
+	defer func() {
+		if err != nil {
+			h.metrics.CronCreateFail.Inc(1)
+			log.WithField("request", req).
+				WithError(err).
+				Warn("CronSVC.CreateCronJob failed")
+			return
+		}
+		h.metrics.CronCreate.Inc(1)
+		log.WithField("name", req.GetConfig().GetName()).
+			Info("CronSVC.CreateCronJob succeeded")
+	}()
+
+	if err := h.checkLeader(); err != nil {
+		return nil, err
+	}
+
+	s, err := validateConfig(req.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	now := h.now()
+	info := &cron.CronJobInfo{
+		Config:           req.GetConfig(),
+		CreateTime:       now.UTC().Format(time.RFC3339),
+		NextScheduleTime: formatTime(s.next(now)),
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	if err := h.cronJobOps.Create(ctx, info); err != nil {
+		if yarpcerrors.IsAlreadyExists(err) {
+			return nil, yarpcerrors.AlreadyExistsErrorf(
+				"cron job %s already exists", info.GetConfig().GetName())
+		}
+		return nil, err
+	}
+
+	return &svc.CreateCronJobResponse{}, nil
+}
+
+// ReplaceCronJob replaces the configuration of a cron job
+func (h *serviceHandler) ReplaceCronJob(
+	ctx context.Context,
+	req *svc.ReplaceCronJobRequest,
+) (resp *svc.ReplaceCronJobResponse, err error) {
+	h.metrics.CronAPIReplace.Inc(1)
+	defer func() {
+		if err != nil {
+			h.metrics.CronReplaceFail.Inc(1)
+			log.WithField("request", req).
+				WithError(err).
+				Warn("CronSVC.ReplaceCronJob failed")
+			return
+		}
+		h.metrics.CronReplace.Inc(1)
+		log.WithField("name", req.GetConfig().GetName()).
+			Info("CronSVC.ReplaceCronJob succeeded")
+	}()
+
+	if err := h.checkLeader(); err != nil {
+		return nil, err
+	}
+
+	s, err := validateConfig(req.GetConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	info, err := h.getCronJob(ctx, req.GetConfig().GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	// the runs which are active and the time the previous
+	// schedule was due are kept
+	info.Config = req.GetConfig()
+	info.NextScheduleTime = formatTime(s.next(h.now()))
+	if err := h.cronJobOps.Update(ctx, info); err != nil {
+		return nil, err
+	}
+
+	return &svc.ReplaceCronJobResponse{}, nil
+}
+
+// DeleteCronJob deletes a cron job
+func (h *serviceHandler) DeleteCronJob(
+	ctx context.Context,
+	req *svc.DeleteCronJobRequest,
+) (resp *svc.DeleteCronJobResponse, err error) {
+	h.metrics.CronAPIDelete.Inc(1)
+	defer func() {
+		if err != nil {
+			h.metrics.CronDeleteFail.Inc(1)
+			log.WithField("request", req).
+				WithError(err).
+				Warn("CronSVC.DeleteCronJob failed")
+			return
+		}
+		h.metrics.CronDelete.Inc(1)
+		log.WithField("name", req.GetName()).
+			Info("CronSVC.DeleteCronJob succeeded")
+	}()
+
+	if err := h.checkLeader(); err != nil {
+		return nil, err
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	if _, err := h.getCronJob(ctx, req.GetName()); err != nil {
+		return nil, err
+	}
+
+	if err := h.cronJobOps.Delete(ctx, req.GetName()); err != nil {
+		return nil, err
+	}
+
+	return &svc.DeleteCronJobResponse{}, nil
+}
+
+// GetCronJob returns a cron job and its most recent runs
+func (h *serviceHandler) GetCronJob(
+	ctx context.Context,
+	req *svc.GetCronJobRequest,
+) (resp *svc.GetCronJobResponse, err error) {
+	h.metrics.CronAPIGet.Inc(1)
+	defer func() {
+		if err != nil {
+			h.metrics.CronGetFail.Inc(1)
+			log.WithField("request", req).
+				WithError(err).
+				Warn("CronSVC.GetCronJob failed")
+			return
+		}
+		h.metrics.CronGet.Inc(1)
+		log.WithField("request", req).
+			Debug("CronSVC.GetCronJob succeeded")
+	}()
+
+	info, err := h.getCronJob(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.GetRunLimit()
+	if limit == 0 {
+		limit = _defaultRunLimit
+	}
+	if limit > _maxRunLimit {
+		limit = _maxRunLimit
+	}
+
+	runs, err := h.cronJobRunOps.GetAll(ctx, req.GetName(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &svc.GetCronJobResponse{
+		Info: info,
+		Runs: runs,
+	}, nil
+}
+
+// ListCronJobs returns all the cron jobs
+func (h *serviceHandler) ListCronJobs(
+	ctx context.Context,
+	req *svc.ListCronJobsRequest,
+) (resp *svc.ListCronJobsResponse, err error) {
+	h.metrics.CronAPIList.Inc(1)
+	defer func() {
+		if err != nil {
+			h.metrics.CronListFail.Inc(1)
+			log.WithError(err).
+				Warn("CronSVC.ListCronJobs failed")
+			return
+		}
+		h.metrics.CronList.Inc(1)
+		log.WithField("num_cron_jobs", len(resp.GetInfos())).
+			Debug("CronSVC.ListCronJobs succeeded")
+	}()
+
+	infos, err := h.cronJobOps.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &svc.ListCronJobsResponse{Infos: infos}, nil
+}
+
+// StartCronJob triggers a run of a cron job now
+func (h *serviceHandler) StartCronJob(
+	ctx context.Context,
+	req *svc.StartCronJobRequest,
+) (resp *svc.StartCronJobResponse, err error) {
+	h.metrics.CronAPIStart.Inc(1)
+	defer func() {
+		if err != nil {
+			h.metrics.CronStartFail.Inc(1)
+			log.WithField("request", req).
+				WithError(err).
+				Warn("CronSVC.StartCronJob failed")
+			return
+		}
+		h.metrics.CronStart.Inc(1)
+		log.WithField("name", req.GetName()).
+			WithField("run", resp.GetRun()).
+			Info("CronSVC.StartCronJob succeeded")
+	}()
+
+	if err := h.checkLeader(); err != nil {
+		return nil, err
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	info, err := h.getCronJob(ctx, req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	run := h.run(ctx, info, cron.RunTrigger_MANUAL)
+	if err := h.cronJobOps.Update(ctx, info); err != nil {
+		return nil, err
+	}
+
+	return &svc.StartCronJobResponse{Run: run}, nil
+}
+
+// runSchedule triggers a run of each cron job whose schedule is due.
+// Runs which were missed, e.g. while there was no leader, are not
+// triggered again; only the most recent one is.
+func (h *serviceHandler) runSchedule(ctx context.Context) {
+	h.Lock()
+	defer h.Unlock()
+
+	infos, err := h.cronJobOps.GetAll(ctx)
+	if err != nil {
+		h.metrics.ScheduleFail.Inc(1)
+		log.WithError(err).Warn("Failed to get cron jobs from DB")
+		return
+	}
+
+	now := h.now()
+	for _, info := range infos {
+		name := info.GetConfig().GetName()
+		s, err := parseSchedule(info.GetConfig().GetSchedule())
+		if err != nil {
+			// schedules are validated when written, so this
+			// should never happen
+			h.metrics.ScheduleFail.Inc(1)
+			log.WithField("name", name).
+				WithError(err).
+				Error("Invalid schedule of cron job")
+			continue
+		}
+
+		if len(info.GetNextScheduleTime()) == 0 {
+			continue
+		}
+		nextScheduleTime, err := time.Parse(
+			time.RFC3339, info.GetNextScheduleTime())
+		if err != nil {
+			h.metrics.ScheduleFail.Inc(1)
+			log.WithField("name", name).
+				WithError(err).
+				Error("Invalid next schedule time of cron job")
+			continue
+		}
+		if now.Before(nextScheduleTime) {
+			continue
+		}
+
+		h.run(ctx, info, cron.RunTrigger_SCHEDULE)
+
+		// the run is for the most recent time the schedule was due
+		lastScheduleTime := nextScheduleTime
+		for t := s.next(lastScheduleTime); !t.IsZero() && !t.After(now); t = s.next(t) {
+			lastScheduleTime = t
+		}
+		info.LastScheduleTime = formatTime(lastScheduleTime)
+		info.NextScheduleTime = formatTime(s.next(now))
+		if err := h.cronJobOps.Update(ctx, info); err != nil {
+			h.metrics.ScheduleFail.Inc(1)
+			log.WithField("name", name).
+				WithError(err).
+				Warn("Failed to update cron job in DB")
+		}
+	}
+}
+
+// run triggers a run of a cron job, applying its collision policy
+// if jobs created by previous runs are still active. It updates the
+// active jobs of the cron job, which the caller needs to write to DB,
+// and records the run.
+func (h *serviceHandler) run(
+	ctx context.Context,
+	info *cron.CronJobInfo,
+	trigger cron.RunTrigger,
+) *cron.CronRun {
+	config := info.GetConfig()
+	run := &cron.CronRun{
+		Time:    h.now().UTC().Format(time.RFC3339Nano),
+		Trigger: trigger,
+	}
+
+	info.ActiveJobs = h.getActiveJobs(ctx, info.GetActiveJobs())
+	if len(info.GetActiveJobs()) != 0 {
+		switch config.GetCollisionPolicy() {
+		case cron.CollisionPolicy_SKIP:
+			run.Result = cron.RunResult_SKIPPED
+			run.Message = fmt.Sprintf(
+				"%d previous runs are still active", len(info.GetActiveJobs()))
+		case cron.CollisionPolicy_KILL_PREVIOUS:
+			if err := h.killJobs(ctx, info.GetActiveJobs()); err != nil {
+				run.Result = cron.RunResult_FAILED
+				run.Message = fmt.Sprintf(
+					"failed to kill previous runs: %v", err)
+			} else {
+				info.ActiveJobs = nil
+			}
+		}
+	}
+
+	if run.GetResult() == cron.RunResult_CREATED {
+		jobID, err := h.createJob(ctx, config)
+		if err != nil {
+			run.Result = cron.RunResult_FAILED
+			run.Message = err.Error()
+		} else {
+			run.JobId = jobID
+			info.ActiveJobs = append(info.ActiveJobs, jobID)
+		}
+	}
+
+	switch run.GetResult() {
+	case cron.RunResult_CREATED:
+		h.metrics.RunCreated.Inc(1)
+	case cron.RunResult_SKIPPED:
+		h.metrics.RunSkipped.Inc(1)
+	case cron.RunResult_FAILED:
+		h.metrics.RunFailed.Inc(1)
+	}
+
+	log.WithField("name", config.GetName()).
+		WithField("run", run).
+		Info("Cron job triggered")
+
+	// the run history is best effort, failing to record a run
+	// must not cause the job to be created again
+	if err := h.cronJobRunOps.Create(ctx, config.GetName(), run); err != nil {
+		log.WithField("name", config.GetName()).
+			WithError(err).
+			Warn("Failed to record run of cron job in DB")
+	}
+
+	return run
+}
+
+// getActiveJobs returns the jobs which are not terminal yet. Jobs
+// whose state cannot be read are assumed to be active.
+func (h *serviceHandler) getActiveJobs(
+	ctx context.Context,
+	jobIDs []*peloton.JobID,
+) []*peloton.JobID {
+	var active []*peloton.JobID
+	for _, jobID := range jobIDs {
+		resp, err := h.jobHandler.Get(ctx, &job.GetRequest{Id: jobID})
+		if err != nil {
+			log.WithField("job_id", jobID.GetValue()).
+				WithError(err).
+				Warn("Failed to get job of cron job run")
+			active = append(active, jobID)
+			continue
+		}
+		if resp.GetError().GetNotFound() != nil {
+			continue
+		}
+		if resp.GetError() != nil {
+			log.WithField("job_id", jobID.GetValue()).
+				WithField("error", resp.GetError()).
+				Warn("Failed to get job of cron job run")
+			active = append(active, jobID)
+			continue
+		}
+		if !util.IsPelotonJobStateTerminal(
+			resp.GetJobInfo().GetRuntime().GetState()) {
+			active = append(active, jobID)
+		}
+	}
+	return active
+}
+
+// killJobs kills all the tasks of the jobs. Jobs which
+// do not exist anymore are ignored.
+func (h *serviceHandler) killJobs(
+	ctx context.Context,
+	jobIDs []*peloton.JobID,
+) error {
+	for _, jobID := range jobIDs {
+		resp, err := h.taskHandler.Stop(ctx, &task.StopRequest{JobId: jobID})
+		if err != nil {
+			return err
+		}
+		if resp.GetError() != nil && resp.GetError().GetNotFound() == nil {
+			return fmt.Errorf("job %s: %v", jobID.GetValue(), resp.GetError())
+		}
+	}
+	return nil
+}
+
+// createJob creates a batch job from the template of a cron job
+func (h *serviceHandler) createJob(
+	ctx context.Context,
+	config *cron.CronJobConfig,
+) (*peloton.JobID, error) {
+	jobConfig := proto.Clone(config.GetJobConfig()).(*job.JobConfig)
+	if len(jobConfig.GetName()) == 0 {
+		jobConfig.Name = config.GetName()
+	}
+	jobConfig.Labels = append(jobConfig.Labels, &peloton.Label{
+		Key:   CronJobLabelKey,
+		Value: config.GetName(),
+	})
+
+	jobID := &peloton.JobID{Value: uuid.New()}
+	resp, err := h.jobHandler.Create(ctx, &job.CreateRequest{
+		Id:     jobID,
+		Config: jobConfig,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetError() != nil {
+		return nil, fmt.Errorf("failed to create job: %v", resp.GetError())
+	}
+	return jobID, nil
+}
+
+// getCronJob returns a cron job from DB, or a NotFound error
+func (h *serviceHandler) getCronJob(
+	ctx context.Context,
+	name string,
+) (*cron.CronJobInfo, error) {
+	info, err := h.cronJobOps.Get(ctx, name)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, yarpcerrors.NotFoundErrorf(
+				"cron job %s not found", name)
+		}
+		return nil, err
+	}
+	return info, nil
+}
+
+// checkLeader returns an Unavailable error if the job manager is not
+// the leader. Cron jobs are only written by the leader, as their runs
+// are triggered there.
+func (h *serviceHandler) checkLeader() error {
+	if !h.candidate.IsLeader() {
+		return yarpcerrors.UnavailableErrorf(
+			"Cron API not suppported on non-leader")
+	}
+	return nil
+}
+
+// validateConfig validates the configuration of a cron job and
+// returns its parsed schedule
+func validateConfig(config *cron.CronJobConfig) (*schedule, error) {
+	if len(config.GetName()) == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"cron job name is not provided")
+	}
+
+	s, err := parseSchedule(config.GetSchedule())
+	if err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf("%v", err)
+	}
+	if s.next(time.Now()).IsZero() {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"schedule %q is never due", config.GetSchedule())
+	}
+
+	if config.GetJobConfig() == nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"job config of cron job is not provided")
+	}
+	if config.GetJobConfig().GetType() != job.JobType_BATCH {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"cron jobs only support BATCH jobs")
+	}
+	if config.GetJobConfig().GetInstanceCount() == 0 {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"instance count of job config must be positive")
+	}
+
+	return s, nil
+}
+
+// formatTime formats a time in RFC3339 format, or returns an empty
+// string for the zero time
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,528 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronsvc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/errors"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	jobmocks "github.com/uber/peloton/.gen/peloton/api/v0/job/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	taskmocks "github.com/uber/peloton/.gen/peloton/api/v0/task/mocks"
+
+	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type cronHandlerTestSuite struct {
+	suite.Suite
+
+	ctrl          *gomock.Controller
+	cronJobOps    *objectmocks.MockCronJobOps
+	cronJobRunOps *objectmocks.MockCronJobRunOps
+	jobHandler    *jobmocks.MockJobManagerYARPCServer
+	taskHandler   *taskmocks.MockTaskManagerYARPCServer
+	candidate     *leadermocks.MockCandidate
+	handler       *serviceHandler
+
+	now    time.Time
+	config *cron.CronJobConfig
+}
+
+func (suite *cronHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.cronJobOps = objectmocks.NewMockCronJobOps(suite.ctrl)
+	suite.cronJobRunOps = objectmocks.NewMockCronJobRunOps(suite.ctrl)
+	suite.jobHandler = jobmocks.NewMockJobManagerYARPCServer(suite.ctrl)
+	suite.taskHandler = taskmocks.NewMockTaskManagerYARPCServer(suite.ctrl)
+	suite.candidate = leadermocks.NewMockCandidate(suite.ctrl)
+	suite.now = time.Date(2019, 1, 2, 1, 30, 0, 0, time.UTC)
+	suite.handler = &serviceHandler{
+		cronJobOps:    suite.cronJobOps,
+		cronJobRunOps: suite.cronJobRunOps,
+		jobHandler:    suite.jobHandler,
+		taskHandler:   suite.taskHandler,
+		candidate:     suite.candidate,
+		metrics:       NewMetrics(tally.NoopScope),
+		now:           func() time.Time { return suite.now },
+	}
+
+	suite.config = &cron.CronJobConfig{
+		Name:     "nightly",
+		Schedule: "0 2 * * *",
+		JobConfig: &job.JobConfig{
+			Type:          job.JobType_BATCH,
+			InstanceCount: 10,
+		},
+	}
+}
+
+func (suite *cronHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestCronServiceHandler(t *testing.T) {
+	suite.Run(t, new(cronHandlerTestSuite))
+}
+
+// newInfo returns the info of the cron job with the given active jobs,
+// due at 2am
+func (suite *cronHandlerTestSuite) newInfo(
+	activeJobs ...*peloton.JobID,
+) *cron.CronJobInfo {
+	return &cron.CronJobInfo{
+		Config:           suite.config,
+		CreateTime:       "2019-01-01T10:00:00Z",
+		LastScheduleTime: "2019-01-01T02:00:00Z",
+		NextScheduleTime: "2019-01-02T02:00:00Z",
+		ActiveJobs:       activeJobs,
+	}
+}
+
+// expectJobState expects the state of a job to be read
+func (suite *cronHandlerTestSuite) expectJobState(
+	jobID *peloton.JobID,
+	state job.JobState,
+) {
+	suite.jobHandler.EXPECT().
+		Get(gomock.Any(), &job.GetRequest{Id: jobID}).
+		Return(&job.GetResponse{
+			JobInfo: &job.JobInfo{
+				Id:      jobID,
+				Runtime: &job.RuntimeInfo{State: state},
+			},
+		}, nil)
+}
+
+// expectJobCreate expects a batch job to be created from the template
+func (suite *cronHandlerTestSuite) expectJobCreate() {
+	suite.jobHandler.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(
+			_ context.Context,
+			req *job.CreateRequest,
+		) (*job.CreateResponse, error) {
+			suite.NotNil(uuid.Parse(req.GetId().GetValue()))
+			suite.Equal("nightly", req.GetConfig().GetName())
+			suite.Equal(job.JobType_BATCH, req.GetConfig().GetType())
+			suite.Equal([]*peloton.Label{
+				{Key: CronJobLabelKey, Value: "nightly"},
+			}, req.GetConfig().GetLabels())
+			return &job.CreateResponse{JobId: req.GetId()}, nil
+		})
+}
+
+// expectRun expects a run of the cron job to be recorded
+func (suite *cronHandlerTestSuite) expectRun(
+	trigger cron.RunTrigger,
+	result cron.RunResult,
+) *cron.CronRun {
+	run := &cron.CronRun{}
+	suite.cronJobRunOps.EXPECT().
+		Create(gomock.Any(), "nightly", gomock.Any()).
+		Do(func(_ context.Context, _ string, r *cron.CronRun) {
+			suite.Equal(suite.now.Format(time.RFC3339Nano), r.GetTime())
+			suite.Equal(trigger, r.GetTrigger())
+			suite.Equal(result, r.GetResult())
+			*run = *r
+		}).
+		Return(nil)
+	return run
+}
+
+// TestCreateCronJob tests creating a cron job
+func (suite *cronHandlerTestSuite) TestCreateCronJob() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Create(gomock.Any(), &cron.CronJobInfo{
+			Config:           suite.config,
+			CreateTime:       "2019-01-02T01:30:00Z",
+			NextScheduleTime: "2019-01-02T02:00:00Z",
+		}).
+		Return(nil)
+
+	_, err := suite.handler.CreateCronJob(
+		context.Background(),
+		&svc.CreateCronJobRequest{Config: suite.config},
+	)
+	suite.NoError(err)
+}
+
+// TestCreateCronJobAlreadyExists tests creating a cron job whose
+// name is taken
+func (suite *cronHandlerTestSuite) TestCreateCronJobAlreadyExists() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Return(yarpcerrors.AlreadyExistsErrorf("item already exists"))
+
+	_, err := suite.handler.CreateCronJob(
+		context.Background(),
+		&svc.CreateCronJobRequest{Config: suite.config},
+	)
+	suite.True(yarpcerrors.IsAlreadyExists(err))
+}
+
+// TestCreateCronJobInvalid tests creating invalid cron jobs
+func (suite *cronHandlerTestSuite) TestCreateCronJobInvalid() {
+	tests := []*cron.CronJobConfig{
+		{Schedule: "@daily", JobConfig: suite.config.GetJobConfig()},
+		{Name: "nightly", Schedule: "0 2 * *", JobConfig: suite.config.GetJobConfig()},
+		{Name: "nightly", Schedule: "0 0 31 2 *", JobConfig: suite.config.GetJobConfig()},
+		{Name: "nightly", Schedule: "@daily"},
+		{
+			Name:     "nightly",
+			Schedule: "@daily",
+			JobConfig: &job.JobConfig{
+				Type:          job.JobType_SERVICE,
+				InstanceCount: 10,
+			},
+		},
+		{
+			Name:      "nightly",
+			Schedule:  "@daily",
+			JobConfig: &job.JobConfig{Type: job.JobType_BATCH},
+		},
+	}
+
+	for _, config := range tests {
+		suite.candidate.EXPECT().IsLeader().Return(true)
+		_, err := suite.handler.CreateCronJob(
+			context.Background(),
+			&svc.CreateCronJobRequest{Config: config},
+		)
+		suite.True(yarpcerrors.IsInvalidArgument(err), config.String())
+	}
+}
+
+// TestNonLeader tests that cron jobs are only changed on the leader
+func (suite *cronHandlerTestSuite) TestNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false).Times(4)
+	ctx := context.Background()
+
+	_, err := suite.handler.CreateCronJob(
+		ctx, &svc.CreateCronJobRequest{Config: suite.config})
+	suite.True(yarpcerrors.IsUnavailable(err))
+	_, err = suite.handler.ReplaceCronJob(
+		ctx, &svc.ReplaceCronJobRequest{Config: suite.config})
+	suite.True(yarpcerrors.IsUnavailable(err))
+	_, err = suite.handler.DeleteCronJob(
+		ctx, &svc.DeleteCronJobRequest{Name: "nightly"})
+	suite.True(yarpcerrors.IsUnavailable(err))
+	_, err = suite.handler.StartCronJob(
+		ctx, &svc.StartCronJobRequest{Name: "nightly"})
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestReplaceCronJob tests replacing the configuration of a cron job
+func (suite *cronHandlerTestSuite) TestReplaceCronJob() {
+	activeJob := &peloton.JobID{Value: uuid.New()}
+	config := &cron.CronJobConfig{
+		Name:            "nightly",
+		Schedule:        "0 * * * *",
+		CollisionPolicy: cron.CollisionPolicy_RUN_CONCURRENTLY,
+		JobConfig:       suite.config.GetJobConfig(),
+	}
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), "nightly").
+		Return(suite.newInfo(activeJob), nil)
+	suite.cronJobOps.EXPECT().
+		Update(gomock.Any(), &cron.CronJobInfo{
+			Config:           config,
+			CreateTime:       "2019-01-01T10:00:00Z",
+			LastScheduleTime: "2019-01-01T02:00:00Z",
+			NextScheduleTime: "2019-01-02T02:00:00Z",
+			ActiveJobs:       []*peloton.JobID{activeJob},
+		}).
+		Return(nil)
+
+	_, err := suite.handler.ReplaceCronJob(
+		context.Background(),
+		&svc.ReplaceCronJobRequest{Config: config},
+	)
+	suite.NoError(err)
+}
+
+// TestReplaceCronJobNotFound tests replacing a missing cron job
+func (suite *cronHandlerTestSuite) TestReplaceCronJobNotFound() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), "nightly").
+		Return(nil, gocql.ErrNotFound)
+
+	_, err := suite.handler.ReplaceCronJob(
+		context.Background(),
+		&svc.ReplaceCronJobRequest{Config: suite.config},
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestDeleteCronJob tests deleting a cron job
+func (suite *cronHandlerTestSuite) TestDeleteCronJob() {
+	suite.candidate.EXPECT().IsLeader().Return(true).Times(2)
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), "nightly").
+		Return(suite.newInfo(), nil)
+	suite.cronJobOps.EXPECT().
+		Delete(gomock.Any(), "nightly").
+		Return(nil)
+
+	_, err := suite.handler.DeleteCronJob(
+		context.Background(),
+		&svc.DeleteCronJobRequest{Name: "nightly"},
+	)
+	suite.NoError(err)
+
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), "nightly").
+		Return(nil, gocql.ErrNotFound)
+	_, err = suite.handler.DeleteCronJob(
+		context.Background(),
+		&svc.DeleteCronJobRequest{Name: "nightly"},
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestGetCronJob tests getting a cron job and its runs
+func (suite *cronHandlerTestSuite) TestGetCronJob() {
+	info := suite.newInfo()
+	runs := []*cron.CronRun{{Result: cron.RunResult_SKIPPED}}
+
+	suite.cronJobOps.EXPECT().
+		Get(gomock.Any(), "nightly").
+		Return(info, nil).
+		Times(2)
+	suite.cronJobRunOps.EXPECT().
+		GetAll(gomock.Any(), "nightly", uint32(_defaultRunLimit)).
+		Return(runs, nil)
+	suite.cronJobRunOps.EXPECT().
+		GetAll(gomock.Any(), "nightly", uint32(_maxRunLimit)).
+		Return(runs, nil)
+
+	resp, err := suite.handler.GetCronJob(
+		context.Background(),
+		&svc.GetCronJobRequest{Name: "nightly"},
+	)
+	suite.NoError(err)
+	suite.Equal(info, resp.GetInfo())
+	suite.Equal(runs, resp.GetRuns())
+
+	_, err = suite.handler.GetCronJob(
+		context.Background(),
+		&svc.GetCronJobRequest{Name: "nightly", RunLimit: _maxRunLimit + 1},
+	)
+	suite.NoError(err)
+}
+
+// TestListCronJobs tests listing the cron jobs
+func (suite *cronHandlerTestSuite) TestListCronJobs() {
+	infos := []*cron.CronJobInfo{suite.newInfo()}
+	suite.cronJobOps.EXPECT().GetAll(gomock.Any()).Return(infos, nil)
+
+	resp, err := suite.handler.ListCronJobs(
+		context.Background(),
+		&svc.ListCronJobsRequest{},
+	)
+	suite.NoError(err)
+	suite.Equal(infos, resp.GetInfos())
+}
+
+// TestStartCronJob tests triggering a run of a cron job, while
+// the jobs of previous runs are terminal
+func (suite *cronHandlerTestSuite) TestStartCronJob() {
+	succeededJob := &peloton.JobID{Value: uuid.New()}
+	deletedJob := &peloton.JobID{Value: uuid.New()}
+	info := suite.newInfo(succeededJob, deletedJob)
+
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.cronJobOps.EXPECT().Get(gomock.Any(), "nightly").Return(info, nil)
+	suite.expectJobState(succeededJob, job.JobState_SUCCEEDED)
+	suite.jobHandler.EXPECT().
+		Get(gomock.Any(), &job.GetRequest{Id: deletedJob}).
+		Return(&job.GetResponse{
+			Error: &job.GetResponse_Error{
+				NotFound: &errors.JobNotFound{Id: deletedJob},
+			},
+		}, nil)
+	suite.expectJobCreate()
+	run := suite.expectRun(cron.RunTrigger_MANUAL, cron.RunResult_CREATED)
+	suite.cronJobOps.EXPECT().Update(gomock.Any(), info).Return(nil)
+
+	resp, err := suite.handler.StartCronJob(
+		context.Background(),
+		&svc.StartCronJobRequest{Name: "nightly"},
+	)
+	suite.NoError(err)
+	suite.Equal(run, resp.GetRun())
+	suite.Equal([]*peloton.JobID{run.GetJobId()}, info.GetActiveJobs())
+	// the template is not modified
+	suite.Empty(suite.config.GetJobConfig().GetLabels())
+}
+
+// TestRunCollisionSkip tests that a run is skipped while the
+// previous run is active
+func (suite *cronHandlerTestSuite) TestRunCollisionSkip() {
+	activeJob := &peloton.JobID{Value: uuid.New()}
+	info := suite.newInfo(activeJob)
+
+	suite.expectJobState(activeJob, job.JobState_RUNNING)
+	suite.expectRun(cron.RunTrigger_SCHEDULE, cron.RunResult_SKIPPED)
+
+	run := suite.handler.run(
+		context.Background(), info, cron.RunTrigger_SCHEDULE)
+	suite.Equal(cron.RunResult_SKIPPED, run.GetResult())
+	suite.Nil(run.GetJobId())
+	suite.Equal([]*peloton.JobID{activeJob}, info.GetActiveJobs())
+}
+
+// TestRunCollisionKillPrevious tests that the previous run is
+// killed before the new run is started
+func (suite *cronHandlerTestSuite) TestRunCollisionKillPrevious() {
+	activeJob := &peloton.JobID{Value: uuid.New()}
+	suite.config.CollisionPolicy = cron.CollisionPolicy_KILL_PREVIOUS
+	info := suite.newInfo(activeJob)
+
+	suite.expectJobState(activeJob, job.JobState_PENDING)
+	gomock.InOrder(
+		suite.taskHandler.EXPECT().
+			Stop(gomock.Any(), &task.StopRequest{JobId: activeJob}).
+			Return(&task.StopResponse{}, nil),
+		suite.jobHandler.EXPECT().
+			Create(gomock.Any(), gomock.Any()).
+			Return(&job.CreateResponse{}, nil),
+	)
+	suite.expectRun(cron.RunTrigger_SCHEDULE, cron.RunResult_CREATED)
+
+	run := suite.handler.run(
+		context.Background(), info, cron.RunTrigger_SCHEDULE)
+	suite.Equal(cron.RunResult_CREATED, run.GetResult())
+	suite.Equal([]*peloton.JobID{run.GetJobId()}, info.GetActiveJobs())
+}
+
+// TestRunCollisionKillPreviousFail tests that the new run fails if
+// the previous run cannot be killed
+func (suite *cronHandlerTestSuite) TestRunCollisionKillPreviousFail() {
+	activeJob := &peloton.JobID{Value: uuid.New()}
+	suite.config.CollisionPolicy = cron.CollisionPolicy_KILL_PREVIOUS
+	info := suite.newInfo(activeJob)
+
+	suite.expectJobState(activeJob, job.JobState_RUNNING)
+	suite.taskHandler.EXPECT().
+		Stop(gomock.Any(), &task.StopRequest{JobId: activeJob}).
+		Return(nil, yarpcerrors.UnavailableErrorf("not leader"))
+	suite.expectRun(cron.RunTrigger_SCHEDULE, cron.RunResult_FAILED)
+
+	run := suite.handler.run(
+		context.Background(), info, cron.RunTrigger_SCHEDULE)
+	suite.Equal(cron.RunResult_FAILED, run.GetResult())
+	suite.Contains(run.GetMessage(), "failed to kill previous runs")
+	suite.Equal([]*peloton.JobID{activeJob}, info.GetActiveJobs())
+}
+
+// TestRunCollisionRunConcurrently tests that the new run is started
+// alongside the previous run
+func (suite *cronHandlerTestSuite) TestRunCollisionRunConcurrently() {
+	activeJob := &peloton.JobID{Value: uuid.New()}
+	suite.config.CollisionPolicy = cron.CollisionPolicy_RUN_CONCURRENTLY
+	info := suite.newInfo(activeJob)
+
+	// jobs whose state cannot be read are assumed to be active
+	suite.jobHandler.EXPECT().
+		Get(gomock.Any(), &job.GetRequest{Id: activeJob}).
+		Return(nil, yarpcerrors.InternalErrorf("db error"))
+	suite.expectJobCreate()
+	suite.expectRun(cron.RunTrigger_SCHEDULE, cron.RunResult_CREATED)
+
+	run := suite.handler.run(
+		context.Background(), info, cron.RunTrigger_SCHEDULE)
+	suite.Equal(cron.RunResult_CREATED, run.GetResult())
+	suite.Equal(
+		[]*peloton.JobID{activeJob, run.GetJobId()},
+		info.GetActiveJobs())
+}
+
+// TestRunCreateFail tests that a run fails if the job cannot be created
+func (suite *cronHandlerTestSuite) TestRunCreateFail() {
+	info := suite.newInfo()
+
+	suite.jobHandler.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Return(&job.CreateResponse{
+			Error: &job.CreateResponse_Error{
+				InvalidConfig: &job.InvalidJobConfig{Message: "invalid"},
+			},
+		}, nil)
+	// failing to record the run is ignored
+	suite.cronJobRunOps.EXPECT().
+		Create(gomock.Any(), "nightly", gomock.Any()).
+		Return(yarpcerrors.InternalErrorf("db error"))
+
+	run := suite.handler.run(
+		context.Background(), info, cron.RunTrigger_SCHEDULE)
+	suite.Equal(cron.RunResult_FAILED, run.GetResult())
+	suite.Empty(info.GetActiveJobs())
+}
+
+// TestRunSchedule tests that only the cron jobs which are due are run
+func (suite *cronHandlerTestSuite) TestRunSchedule() {
+	// due at 2am, missed runs are not triggered again
+	suite.now = time.Date(2019, 1, 4, 2, 0, 30, 0, time.UTC)
+	due := suite.newInfo()
+
+	notDue := suite.newInfo()
+	notDue.Config = &cron.CronJobConfig{
+		Name:      "later",
+		Schedule:  "0 3 * * *",
+		JobConfig: suite.config.GetJobConfig(),
+	}
+	notDue.NextScheduleTime = "2019-01-04T03:00:00Z"
+
+	suite.cronJobOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*cron.CronJobInfo{due, notDue}, nil)
+	suite.expectJobCreate()
+	suite.expectRun(cron.RunTrigger_SCHEDULE, cron.RunResult_CREATED)
+	suite.cronJobOps.EXPECT().Update(gomock.Any(), due).Return(nil)
+
+	suite.handler.runSchedule(context.Background())
+	suite.Equal("2019-01-04T02:00:00Z", due.GetLastScheduleTime())
+	suite.Equal("2019-01-05T02:00:00Z", due.GetNextScheduleTime())
+	suite.Len(due.GetActiveJobs(), 1)
+}
+
+// TestRunScheduleGetAllFail tests that nothing is run if the cron
+// jobs cannot be read
+func (suite *cronHandlerTestSuite) TestRunScheduleGetAllFail() {
+	suite.cronJobOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, yarpcerrors.InternalErrorf("db error"))
+
+	suite.handler.runSchedule(context.Background())
+}
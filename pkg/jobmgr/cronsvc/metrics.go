@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronsvc
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track
+// internal state of the cron service
+type Metrics struct {
+	CronAPICreate  tally.Counter
+	CronCreate     tally.Counter
+	CronCreateFail tally.Counter
+
+	CronAPIReplace  tally.Counter
+	CronReplace     tally.Counter
+	CronReplaceFail tally.Counter
+
+	CronAPIDelete  tally.Counter
+	CronDelete     tally.Counter
+	CronDeleteFail tally.Counter
+
+	CronAPIGet  tally.Counter
+	CronGet     tally.Counter
+	CronGetFail tally.Counter
+
+	CronAPIList  tally.Counter
+	CronList     tally.Counter
+	CronListFail tally.Counter
+
+	CronAPIStart  tally.Counter
+	CronStart     tally.Counter
+	CronStartFail tally.Counter
+
+	// Outcome of the runs of the cron jobs
+	RunCreated tally.Counter
+	RunSkipped tally.Counter
+	RunFailed  tally.Counter
+
+	// Failures to evaluate the schedule of the cron jobs
+	ScheduleFail tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+	apiScope := scope.SubScope("api")
+	runScope := scope.SubScope("run")
+
+	return &Metrics{
+		CronAPICreate:  apiScope.Counter("create"),
+		CronCreate:     successScope.Counter("create"),
+		CronCreateFail: failScope.Counter("create"),
+
+		CronAPIReplace:  apiScope.Counter("replace"),
+		CronReplace:     successScope.Counter("replace"),
+		CronReplaceFail: failScope.Counter("replace"),
+
+		CronAPIDelete:  apiScope.Counter("delete"),
+		CronDelete:     successScope.Counter("delete"),
+		CronDeleteFail: failScope.Counter("delete"),
+
+		CronAPIGet:  apiScope.Counter("get"),
+		CronGet:     successScope.Counter("get"),
+		CronGetFail: failScope.Counter("get"),
+
+		CronAPIList:  apiScope.Counter("list"),
+		CronList:     successScope.Counter("list"),
+		CronListFail: failScope.Counter("list"),
+
+		CronAPIStart:  apiScope.Counter("start"),
+		CronStart:     successScope.Counter("start"),
+		CronStartFail: failScope.Counter("start"),
+
+		RunCreated: runScope.Counter("created"),
+		RunSkipped: runScope.Counter("skipped"),
+		RunFailed:  runScope.Counter("failed"),
+
+		ScheduleFail: failScope.Counter("schedule"),
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronsvc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// _maxScheduleYears bounds the search for the next time a schedule
+// is due, for schedules which can never be due such as Feb 30th
+const _maxScheduleYears = 5
+
+// _macros are the supported shorthands of the 5 field cron expressions
+var _macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field describes the allowed values of a field of a cron expression
+type field struct {
+	name     string
+	min, max int
+	// names of the values, indexed from min
+	names []string
+}
+
+var (
+	_minuteField = field{name: "minute", min: 0, max: 59}
+	_hourField   = field{name: "hour", min: 0, max: 23}
+	_domField    = field{name: "day of month", min: 1, max: 31}
+	_monthField  = field{
+		name: "month",
+		min:  1,
+		max:  12,
+		names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN",
+			"JUL", "AUG", "SEP", "OCT", "NOV", "DEC"},
+	}
+	// 7 is accepted for Sunday, same as 0
+	_dowField = field{
+		name:  "day of week",
+		min:   0,
+		max:   7,
+		names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"},
+	}
+)
+
+// schedule is a parsed cron expression. Each field is a bitset of
+// the values it matches.
+type schedule struct {
+	minute, hour, dom, month, dow uint64
+	// the day of month and day of week fields are restricted,
+	// i.e. not '*'. If both are, a day matches if either matches.
+	domRestricted, dowRestricted bool
+}
+
+// parseSchedule parses a 5 field cron expression
+// (minute hour day-of-month month day-of-week) or one of its macros
+func parseSchedule(spec string) (*schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if expanded, ok := _macros[strings.ToLower(spec)]; ok {
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf(
+			"schedule %q must have 5 fields, got %d", spec, len(fields))
+	}
+
+	s := &schedule{
+		domRestricted: fields[2] != "*",
+		dowRestricted: fields[4] != "*",
+	}
+
+	var err error
+	if s.minute, err = _minuteField.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if s.hour, err = _hourField.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if s.dom, err = _domField.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if s.month, err = _monthField.parse(fields[3]); err != nil {
+		return nil, err
+	}
+	if s.dow, err = _dowField.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	// Sunday can be either 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+
+	return s, nil
+}
+
+// parse parses a comma separated list of values, ranges and steps,
+// such as "1,5-10,*/15", into a bitset
+func (f field) parse(expr string) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(expr, ",") {
+		low, high, step := f.min, f.max, 1
+
+		rangeExpr := part
+		if i := strings.Index(part, "/"); i >= 0 {
+			var err error
+			rangeExpr = part[:i]
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %q", f.name, part)
+			}
+		}
+
+		switch {
+		case rangeExpr == "*":
+		case strings.Contains(rangeExpr, "-"):
+			bounds := strings.SplitN(rangeExpr, "-", 2)
+			var err error
+			if low, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid range in %s field: %q", f.name, part)
+			}
+		default:
+			value, err := f.value(rangeExpr)
+			if err != nil {
+				return 0, err
+			}
+			low = value
+			// a single value with a step, such as 5/15, runs until max
+			if rangeExpr == part {
+				high = value
+			}
+		}
+
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// value parses a single number or name of the field
+func (f field) value(expr string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(expr, name) {
+			return f.min + i, nil
+		}
+	}
+
+	value, err := strconv.Atoi(expr)
+	if err != nil || value < f.min || value > f.max {
+		return 0, fmt.Errorf("invalid %s: %q", f.name, expr)
+	}
+	return value, nil
+}
+
+// dayMatches returns if the day of a time matches the schedule
+func (s *schedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domRestricted && s.dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// next returns the first time strictly after t at which the
+// schedule is due, in UTC. It returns the zero time if the schedule
+// is not due in the next few years.
+func (s *schedule) next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + _maxScheduleYears
+
+	// each time a field wraps around, the fields before it
+	// need to be checked again
+	for t.Year() <= yearLimit {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
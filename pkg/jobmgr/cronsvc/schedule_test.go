@@ -0,0 +1,106 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronsvc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type scheduleTestSuite struct {
+	suite.Suite
+}
+
+func TestSchedule(t *testing.T) {
+	suite.Run(t, new(scheduleTestSuite))
+}
+
+// TestNext tests the next time schedules are due
+func (suite *scheduleTestSuite) TestNext() {
+	// Wednesday
+	now := time.Date(2019, 1, 2, 10, 30, 15, 0, time.UTC)
+
+	tests := []struct {
+		spec     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2019, 1, 2, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2019, 1, 2, 10, 45, 0, 0, time.UTC)},
+		{"5/15 * * * *", time.Date(2019, 1, 2, 10, 35, 0, 0, time.UTC)},
+		{"0 2 * * *", time.Date(2019, 1, 3, 2, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2019, 1, 3, 10, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2019, 1, 2, 13, 0, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2019, 1, 15, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 0", time.Date(2019, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2019, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * mon-fri", time.Date(2019, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jun *", time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// day of month or day of week when both are restricted
+		{"0 0 20 * 5", time.Date(2019, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2019, 1, 2, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2019, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2019, 1, 6, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2019, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		s, err := parseSchedule(test.spec)
+		suite.NoError(err, test.spec)
+		suite.Equal(test.expected, s.next(now), test.spec)
+	}
+}
+
+// TestNextIsInUTC tests that schedules are evaluated in UTC
+func (suite *scheduleTestSuite) TestNextIsInUTC() {
+	s, err := parseSchedule("0 2 * * *")
+	suite.NoError(err)
+
+	now := time.Date(2019, 1, 2, 1, 0, 0, 0, time.FixedZone("PST", -8*3600))
+	suite.Equal(time.Date(2019, 1, 3, 2, 0, 0, 0, time.UTC), s.next(now))
+}
+
+// TestNextNeverDue tests that the zero time is returned for
+// schedules which are never due
+func (suite *scheduleTestSuite) TestNextNeverDue() {
+	s, err := parseSchedule("0 0 30 2 *")
+	suite.NoError(err)
+	suite.True(s.next(time.Now()).IsZero())
+}
+
+// TestParseScheduleInvalid tests parsing invalid schedules
+func (suite *scheduleTestSuite) TestParseScheduleInvalid() {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@every",
+	} {
+		_, err := parseSchedule(spec)
+		suite.Error(err, spec)
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dagsvc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/leader"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/jobmgr/goalstate"
+	jobconfig "github.com/uber/peloton/pkg/jobmgr/job/config"
+	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// serviceHandler implements peloton.api.v0.dag.svc.DagService. The
+// nodes of the DAGs are run by the goal state engine of job manager.
+type serviceHandler struct {
+	dagOps          ormobjects.DagOps
+	respoolClient   respool.ResourceManagerYARPCClient
+	goalStateDriver goalstate.Driver
+	candidate       leader.Candidate
+	jobSvcCfg       jobsvc.Config
+	metrics         *Metrics
+}
+
+// InitServiceHandler initializes the DAG Service Handler
+func InitServiceHandler(
+	d *yarpc.Dispatcher,
+	parent tally.Scope,
+	ormStore *ormobjects.Store,
+	goalStateDriver goalstate.Driver,
+	candidate leader.Candidate,
+	jobSvcCfg jobsvc.Config,
+) {
+	handler := &serviceHandler{
+		dagOps: ormobjects.NewDagOps(ormStore),
+		respoolClient: respool.NewResourceManagerYARPCClient(
+			d.ClientConfig(common.PelotonResourceManager)),
+		goalStateDriver: goalStateDriver,
+		candidate:       candidate,
+		jobSvcCfg:       jobSvcCfg,
+		metrics:         NewMetrics(parent.SubScope("jobmgr").SubScope("dag")),
+	}
+	d.Register(svc.BuildDagServiceYARPCProcedures(handler))
+}
+
+// SubmitDag submits a new DAG of batch jobs
+func (h *serviceHandler) SubmitDag(
+	ctx context.Context,
+	req *svc.SubmitDagRequest,
+) (resp *svc.SubmitDagResponse, err error) {
+	h.metrics.DagAPISubmit.Inc(1)
+	defer func() {
+		if err != nil {
+			h.metrics.DagSubmitFail.Inc(1)
+			log.WithField("request", req).
+				WithError(err).
+				Warn("DagSVC.SubmitDag failed")
+			return
+		}
+		h.metrics.DagSubmit.Inc(1)
+		log.WithField("dag_id", resp.GetId().GetValue()).
+			WithField("name", req.GetSpec().GetName()).
+			Info("DagSVC.SubmitDag succeeded")
+	}()
+
+	if err := h.checkLeader(); err != nil {
+		return nil, err
+	}
+
+	if err := h.validateSpec(ctx, req.GetSpec()); err != nil {
+		return nil, err
+	}
+
+	info := &dag.DagInfo{
+		Id:           &dag.DagID{Value: uuid.New()},
+		Spec:         req.GetSpec(),
+		State:        dag.DagState_DAG_RUNNING,
+		GoalState:    dag.DagState_DAG_SUCCEEDED,
+		CreationTime: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+	for _, node := range req.GetSpec().GetNodes() {
+		info.Nodes = append(info.Nodes, &dag.NodeStatus{
+			Name:  node.GetName(),
+			State: dag.NodeState_NODE_PENDING,
+		})
+	}
+
+	if err := h.dagOps.Create(ctx, info); err != nil {
+		return nil, err
+	}
+	h.goalStateDriver.EnqueueDag(info.GetId(), time.Now())
+
+	return &svc.SubmitDagResponse{Id: info.GetId()}, nil
+}
+
+// GetDag returns a DAG and the status of its nodes
+func (h *serviceHandler) GetDag(
+	ctx context.Context,
+	req *svc.GetDagRequest,
+) (resp *svc.GetDagResponse, err error) {
+	h.metrics.DagAPIGet.Inc(1)
+	defer func() {
+		if err != nil {
+			h.metrics.DagGetFail.Inc(1)
+			log.WithField("request", req).
+				WithError(err).
+				Warn("DagSVC.GetDag failed")
+			return
+		}
+		h.metrics.DagGet.Inc(1)
+		log.WithField("request", req).
+			Debug("DagSVC.GetDag succeeded")
+	}()
+
+	info, err := h.getDag(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &svc.GetDagResponse{Info: info}, nil
+}
+
+// ListDags returns all the DAGs submitted in the last 30 days
+func (h *serviceHandler) ListDags(
+	ctx context.Context,
+	req *svc.ListDagsRequest,
+) (resp *svc.ListDagsResponse, err error) {
+	h.metrics.DagAPIList.Inc(1)
+	defer func() {
+		if err != nil {
+			h.metrics.DagListFail.Inc(1)
+			log.WithError(err).
+				Warn("DagSVC.ListDags failed")
+			return
+		}
+		h.metrics.DagList.Inc(1)
+		log.WithField("num_dags", len(resp.GetInfos())).
+			Debug("DagSVC.ListDags succeeded")
+	}()
+
+	infos, err := h.dagOps.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &svc.ListDagsResponse{Infos: infos}, nil
+}
+
+// CancelDag cancels a DAG. Its running jobs are killed
+// and its remaining nodes are skipped.
+func (h *serviceHandler) CancelDag(
+	ctx context.Context,
+	req *svc.CancelDagRequest,
+) (resp *svc.CancelDagResponse, err error) {
+	h.metrics.DagAPICancel.Inc(1)
+	defer func() {
+		if err != nil {
+			h.metrics.DagCancelFail.Inc(1)
+			log.WithField("request", req).
+				WithError(err).
+				Warn("DagSVC.CancelDag failed")
+			return
+		}
+		h.metrics.DagCancel.Inc(1)
+		log.WithField("dag_id", req.GetId().GetValue()).
+			Info("DagSVC.CancelDag succeeded")
+	}()
+
+	if err := h.checkLeader(); err != nil {
+		return nil, err
+	}
+
+	info, err := h.getDag(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	if util.IsDagStateTerminal(info.GetState()) {
+		return nil, yarpcerrors.FailedPreconditionErrorf(
+			"DAG %s is already %s",
+			req.GetId().GetValue(), info.GetState().String())
+	}
+
+	if err := h.dagOps.UpdateGoalState(
+		ctx, req.GetId(), dag.DagState_DAG_CANCELED); err != nil {
+		return nil, err
+	}
+	h.goalStateDriver.EnqueueDag(req.GetId(), time.Now())
+
+	return &svc.CancelDagResponse{}, nil
+}
+
+// getDag returns a DAG from DB, or a NotFound error
+func (h *serviceHandler) getDag(
+	ctx context.Context,
+	id *dag.DagID,
+) (*dag.DagInfo, error) {
+	info, err := h.dagOps.Get(ctx, id)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return nil, yarpcerrors.NotFoundErrorf(
+				"DAG %s not found", id.GetValue())
+		}
+		return nil, err
+	}
+	return info, nil
+}
+
+// checkLeader returns an Unavailable error if the job manager is not
+// the leader. DAGs are only written by the leader, as their nodes
+// are run by its goal state engine.
+func (h *serviceHandler) checkLeader() error {
+	if !h.candidate.IsLeader() {
+		return yarpcerrors.UnavailableErrorf(
+			"DAG API not suppported on non-leader")
+	}
+	return nil
+}
+
+// validateSpec validates the nodes and edges of a DAG, and the
+// configuration of the jobs of its nodes
+func (h *serviceHandler) validateSpec(
+	ctx context.Context,
+	spec *dag.DagSpec,
+) error {
+	if len(spec.GetNodes()) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf("DAG has no nodes")
+	}
+
+	first := spec.GetNodes()[0].GetJobConfig()
+	nodes := make(map[string]bool)
+	for _, node := range spec.GetNodes() {
+		if len(node.GetName()) == 0 {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"name of DAG node is not provided")
+		}
+		if nodes[node.GetName()] {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"DAG node %s is not unique", node.GetName())
+		}
+		nodes[node.GetName()] = true
+
+		if err := h.validateJobConfig(node.GetJobConfig()); err != nil {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"job config of DAG node %s is invalid: %v",
+				node.GetName(), err)
+		}
+
+		// authorization of the DAG is based on its first node
+		config := node.GetJobConfig()
+		if config.GetRespoolID().GetValue() != first.GetRespoolID().GetValue() {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"DAG node %s is not in the same resource pool as node %s",
+				node.GetName(), spec.GetNodes()[0].GetName())
+		}
+		if config.GetOwner() != first.GetOwner() ||
+			config.GetOwningTeam() != first.GetOwningTeam() {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"DAG node %s does not have the same owner as node %s",
+				node.GetName(), spec.GetNodes()[0].GetName())
+		}
+	}
+
+	downstream := make(map[string][]string)
+	for _, edge := range spec.GetEdges() {
+		if !nodes[edge.GetFrom()] {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"DAG edge starts from unknown node %s", edge.GetFrom())
+		}
+		if !nodes[edge.GetTo()] {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"DAG edge ends at unknown node %s", edge.GetTo())
+		}
+		downstream[edge.GetFrom()] = append(
+			downstream[edge.GetFrom()], edge.GetTo())
+	}
+	if err := checkAcyclic(spec.GetNodes(), downstream); err != nil {
+		return err
+	}
+
+	return h.validateResourcePool(ctx, first.GetRespoolID())
+}
+
+// validateJobConfig validates the configuration of the job of a node
+func (h *serviceHandler) validateJobConfig(config *job.JobConfig) error {
+	if config == nil {
+		return fmt.Errorf("job config is not provided")
+	}
+	if config.GetType() != job.JobType_BATCH {
+		return fmt.Errorf("DAGs only support BATCH jobs")
+	}
+	// secrets are not supported, as they are created along with the job
+	if util.ConfigHasSecretVolumes(config.GetDefaultConfig()) {
+		return fmt.Errorf("secret volumes are not supported")
+	}
+	// validation may fill in defaults, the config of the job
+	// is copied when the job is created
+	return jobconfig.ValidateConfig(
+		proto.Clone(config).(*job.JobConfig),
+		h.jobSvcCfg.MaxTasksPerJob)
+}
+
+// validateResourcePool validates that the jobs of a DAG can be
+// submitted to a resource pool
+func (h *serviceHandler) validateResourcePool(
+	ctx context.Context,
+	respoolID *peloton.ResourcePoolID,
+) error {
+	if len(respoolID.GetValue()) == 0 {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"resource pool of DAG is not provided")
+	}
+	if respoolID.GetValue() == common.RootResPoolID {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"cannot submit jobs to the `root` resource pool")
+	}
+
+	resp, err := h.respoolClient.GetResourcePool(
+		ctx,
+		&respool.GetRequest{Id: respoolID})
+	if err != nil {
+		return err
+	}
+	if resp.GetError() != nil ||
+		resp.GetPoolinfo().GetId().GetValue() != respoolID.GetValue() {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"resource pool %s not found", respoolID.GetValue())
+	}
+	if len(resp.GetPoolinfo().GetChildren()) > 0 {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"cannot submit jobs to a non leaf resource pool")
+	}
+	return nil
+}
+
+// checkAcyclic returns an InvalidArgument error if the edges of a DAG
+// form a cycle. It removes the nodes without incoming edges until none
+// are left; the nodes which cannot be removed are part of a cycle.
+func checkAcyclic(
+	nodes []*dag.Node,
+	downstream map[string][]string,
+) error {
+	inDegree := make(map[string]int)
+	for _, to := range downstream {
+		for _, name := range to {
+			inDegree[name]++
+		}
+	}
+
+	var ready []string
+	for _, node := range nodes {
+		if inDegree[node.GetName()] == 0 {
+			ready = append(ready, node.GetName())
+		}
+	}
+
+	removed := 0
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		removed++
+		for _, to := range downstream[name] {
+			inDegree[to]--
+			if inDegree[to] == 0 {
+				ready = append(ready, to)
+			}
+		}
+	}
+
+	if removed != len(nodes) {
+		for _, node := range nodes {
+			if inDegree[node.GetName()] > 0 {
+				return yarpcerrors.InvalidArgumentErrorf(
+					"DAG has a cycle through node %s", node.GetName())
+			}
+		}
+	}
+	return nil
+}
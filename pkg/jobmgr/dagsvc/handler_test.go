@@ -0,0 +1,388 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dagsvc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/common"
+	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
+	goalstatemocks "github.com/uber/peloton/pkg/jobmgr/goalstate/mocks"
+	"github.com/uber/peloton/pkg/jobmgr/jobsvc"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type dagHandlerTestSuite struct {
+	suite.Suite
+
+	ctrl            *gomock.Controller
+	dagOps          *objectmocks.MockDagOps
+	respoolClient   *respoolmocks.MockResourceManagerYARPCClient
+	goalStateDriver *goalstatemocks.MockDriver
+	candidate       *leadermocks.MockCandidate
+	handler         *serviceHandler
+
+	dagID     *dag.DagID
+	respoolID *peloton.ResourcePoolID
+	spec      *dag.DagSpec
+}
+
+func (suite *dagHandlerTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.dagOps = objectmocks.NewMockDagOps(suite.ctrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.ctrl)
+	suite.candidate = leadermocks.NewMockCandidate(suite.ctrl)
+	suite.handler = &serviceHandler{
+		dagOps:          suite.dagOps,
+		respoolClient:   suite.respoolClient,
+		goalStateDriver: suite.goalStateDriver,
+		candidate:       suite.candidate,
+		jobSvcCfg:       jobsvc.Config{MaxTasksPerJob: 1000},
+		metrics:         NewMetrics(tally.NoopScope),
+	}
+
+	suite.dagID = &dag.DagID{Value: uuid.New()}
+	suite.respoolID = &peloton.ResourcePoolID{Value: uuid.New()}
+	command := "echo hello"
+	jobConfig := &job.JobConfig{
+		Type:          job.JobType_BATCH,
+		OwningTeam:    "team1",
+		RespoolID:     suite.respoolID,
+		InstanceCount: 2,
+		DefaultConfig: &task.TaskConfig{
+			Command: &mesos.CommandInfo{Value: &command},
+		},
+	}
+	suite.spec = &dag.DagSpec{
+		Name: "etl",
+		Nodes: []*dag.Node{
+			{Name: "extract", JobConfig: jobConfig},
+			{Name: "load", JobConfig: jobConfig},
+			{Name: "cleanup", JobConfig: jobConfig},
+		},
+		Edges: []*dag.Edge{
+			{From: "extract", To: "load"},
+			{
+				From:      "extract",
+				To:        "cleanup",
+				Condition: dag.EdgeCondition_ON_FAILURE,
+			},
+		},
+	}
+}
+
+func (suite *dagHandlerTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestDagServiceHandler(t *testing.T) {
+	suite.Run(t, new(dagHandlerTestSuite))
+}
+
+// expectRespool expects the resource pool of the DAG to be read
+func (suite *dagHandlerTestSuite) expectRespool(children int) {
+	poolInfo := &respool.ResourcePoolInfo{Id: suite.respoolID}
+	for i := 0; i < children; i++ {
+		poolInfo.Children = append(poolInfo.Children,
+			&peloton.ResourcePoolID{Value: uuid.New()})
+	}
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{Id: suite.respoolID}).
+		Return(&respool.GetResponse{Poolinfo: poolInfo}, nil)
+}
+
+// TestSubmitDag tests submitting a DAG
+func (suite *dagHandlerTestSuite) TestSubmitDag() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.expectRespool(0)
+	var id *dag.DagID
+	suite.dagOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, info *dag.DagInfo) {
+			id = info.GetId()
+			suite.Equal(suite.spec, info.GetSpec())
+			suite.Equal(dag.DagState_DAG_RUNNING, info.GetState())
+			suite.Equal(dag.DagState_DAG_SUCCEEDED, info.GetGoalState())
+			suite.Len(info.GetNodes(), 3)
+			for _, node := range info.GetNodes() {
+				suite.Equal(dag.NodeState_NODE_PENDING, node.GetState())
+			}
+		}).
+		Return(nil)
+	suite.goalStateDriver.EXPECT().EnqueueDag(gomock.Any(), gomock.Any())
+
+	resp, err := suite.handler.SubmitDag(
+		context.Background(),
+		&svc.SubmitDagRequest{Spec: suite.spec})
+	suite.NoError(err)
+	suite.Equal(id, resp.GetId())
+}
+
+// TestSubmitDagNonLeader tests that DAGs can only
+// be submitted to the leader
+func (suite *dagHandlerTestSuite) TestSubmitDagNonLeader() {
+	suite.candidate.EXPECT().IsLeader().Return(false)
+
+	_, err := suite.handler.SubmitDag(
+		context.Background(),
+		&svc.SubmitDagRequest{Spec: suite.spec})
+	suite.True(yarpcerrors.IsUnavailable(err))
+}
+
+// TestSubmitDagInvalidSpec tests submitting invalid DAGs
+func (suite *dagHandlerTestSuite) TestSubmitDagInvalidSpec() {
+	otherConfig := *suite.spec.Nodes[0].JobConfig
+	otherConfig.OwningTeam = "team2"
+	serviceConfig := *suite.spec.Nodes[0].JobConfig
+	serviceConfig.Type = job.JobType_SERVICE
+	noCommandConfig := *suite.spec.Nodes[0].JobConfig
+	noCommandConfig.DefaultConfig = nil
+
+	tests := map[string]func(spec *dag.DagSpec){
+		"no nodes": func(spec *dag.DagSpec) {
+			spec.Nodes = nil
+			spec.Edges = nil
+		},
+		"duplicate node": func(spec *dag.DagSpec) {
+			spec.Nodes[1].Name = "extract"
+		},
+		"unnamed node": func(spec *dag.DagSpec) {
+			spec.Nodes[1].Name = ""
+		},
+		"different owner": func(spec *dag.DagSpec) {
+			spec.Nodes[2].JobConfig = &otherConfig
+		},
+		"service job": func(spec *dag.DagSpec) {
+			spec.Nodes[2].JobConfig = &serviceConfig
+		},
+		"invalid job": func(spec *dag.DagSpec) {
+			spec.Nodes[2].JobConfig = &noCommandConfig
+		},
+		"unknown node": func(spec *dag.DagSpec) {
+			spec.Edges[0].To = "transform"
+		},
+		"cycle": func(spec *dag.DagSpec) {
+			spec.Edges = append(spec.Edges,
+				&dag.Edge{From: "load", To: "extract"})
+		},
+		"self loop": func(spec *dag.DagSpec) {
+			spec.Edges = append(spec.Edges,
+				&dag.Edge{From: "cleanup", To: "cleanup"})
+		},
+	}
+
+	for name, modify := range tests {
+		suite.SetupTest()
+		modify(suite.spec)
+		suite.candidate.EXPECT().IsLeader().Return(true)
+
+		_, err := suite.handler.SubmitDag(
+			context.Background(),
+			&svc.SubmitDagRequest{Spec: suite.spec})
+		suite.True(yarpcerrors.IsInvalidArgument(err), name)
+		suite.ctrl.Finish()
+	}
+}
+
+// TestSubmitDagInvalidRespool tests submitting DAGs
+// to resource pools which cannot have jobs
+func (suite *dagHandlerTestSuite) TestSubmitDagInvalidRespool() {
+	// non leaf resource pool
+	suite.candidate.EXPECT().IsLeader().Return(true).Times(3)
+	suite.expectRespool(1)
+	_, err := suite.handler.SubmitDag(
+		context.Background(),
+		&svc.SubmitDagRequest{Spec: suite.spec})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// resource pool not found
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), gomock.Any()).
+		Return(&respool.GetResponse{
+			Error: &respool.GetResponse_Error{
+				NotFound: &respool.ResourcePoolNotFound{Id: suite.respoolID},
+			},
+		}, nil)
+	_, err = suite.handler.SubmitDag(
+		context.Background(),
+		&svc.SubmitDagRequest{Spec: suite.spec})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	// root resource pool
+	for _, node := range suite.spec.GetNodes() {
+		node.JobConfig.RespoolID = &peloton.ResourcePoolID{
+			Value: common.RootResPoolID,
+		}
+	}
+	_, err = suite.handler.SubmitDag(
+		context.Background(),
+		&svc.SubmitDagRequest{Spec: suite.spec})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestSubmitDagCreateFail tests failing to write a DAG to DB
+func (suite *dagHandlerTestSuite) TestSubmitDagCreateFail() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.expectRespool(0)
+	suite.dagOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+
+	_, err := suite.handler.SubmitDag(
+		context.Background(),
+		&svc.SubmitDagRequest{Spec: suite.spec})
+	suite.EqualError(err, "create failed")
+}
+
+// TestGetDag tests getting a DAG
+func (suite *dagHandlerTestSuite) TestGetDag() {
+	info := &dag.DagInfo{Id: suite.dagID, Spec: suite.spec}
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(info, nil)
+
+	resp, err := suite.handler.GetDag(
+		context.Background(),
+		&svc.GetDagRequest{Id: suite.dagID})
+	suite.NoError(err)
+	suite.Equal(info, resp.GetInfo())
+
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(nil, gocql.ErrNotFound)
+	_, err = suite.handler.GetDag(
+		context.Background(),
+		&svc.GetDagRequest{Id: suite.dagID})
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestListDags tests listing the DAGs
+func (suite *dagHandlerTestSuite) TestListDags() {
+	infos := []*dag.DagInfo{{Id: suite.dagID, Spec: suite.spec}}
+	suite.dagOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(infos, nil)
+
+	resp, err := suite.handler.ListDags(
+		context.Background(),
+		&svc.ListDagsRequest{})
+	suite.NoError(err)
+	suite.Equal(infos, resp.GetInfos())
+
+	suite.dagOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+	_, err = suite.handler.ListDags(
+		context.Background(),
+		&svc.ListDagsRequest{})
+	suite.EqualError(err, "getall failed")
+}
+
+// TestCancelDag tests canceling a DAG
+func (suite *dagHandlerTestSuite) TestCancelDag() {
+	suite.candidate.EXPECT().IsLeader().Return(true)
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(&dag.DagInfo{
+			Id:    suite.dagID,
+			State: dag.DagState_DAG_RUNNING,
+		}, nil)
+	suite.dagOps.EXPECT().
+		UpdateGoalState(gomock.Any(), suite.dagID, dag.DagState_DAG_CANCELED).
+		Return(nil)
+	suite.goalStateDriver.EXPECT().EnqueueDag(suite.dagID, gomock.Any())
+
+	_, err := suite.handler.CancelDag(
+		context.Background(),
+		&svc.CancelDagRequest{Id: suite.dagID})
+	suite.NoError(err)
+}
+
+// TestCancelDagFail tests failures to cancel a DAG
+func (suite *dagHandlerTestSuite) TestCancelDagFail() {
+	suite.candidate.EXPECT().IsLeader().Return(true).Times(3)
+
+	// DAG not found
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(nil, gocql.ErrNotFound)
+	_, err := suite.handler.CancelDag(
+		context.Background(),
+		&svc.CancelDagRequest{Id: suite.dagID})
+	suite.True(yarpcerrors.IsNotFound(err))
+
+	// DAG already done
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(&dag.DagInfo{
+			Id:    suite.dagID,
+			State: dag.DagState_DAG_SUCCEEDED,
+		}, nil)
+	_, err = suite.handler.CancelDag(
+		context.Background(),
+		&svc.CancelDagRequest{Id: suite.dagID})
+	suite.True(yarpcerrors.IsFailedPrecondition(err))
+
+	// DB error
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(&dag.DagInfo{
+			Id:    suite.dagID,
+			State: dag.DagState_DAG_RUNNING,
+		}, nil)
+	suite.dagOps.EXPECT().
+		UpdateGoalState(gomock.Any(), suite.dagID, dag.DagState_DAG_CANCELED).
+		Return(errors.New("update failed"))
+	_, err = suite.handler.CancelDag(
+		context.Background(),
+		&svc.CancelDagRequest{Id: suite.dagID})
+	suite.EqualError(err, "update failed")
+}
+
+// TestCheckAcyclic tests the detection of cycles in DAGs
+func (suite *dagHandlerTestSuite) TestCheckAcyclic() {
+	nodes := []*dag.Node{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+
+	suite.NoError(checkAcyclic(nodes, nil))
+	// diamond shaped DAGs have no cycle
+	suite.NoError(checkAcyclic(nodes, map[string][]string{
+		"a": {"b", "c"},
+		"b": {"c"},
+	}))
+	suite.Error(checkAcyclic(nodes, map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"b"},
+	}))
+}
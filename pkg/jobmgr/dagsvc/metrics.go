@@ -0,0 +1,65 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dagsvc
+
+import (
+	"github.com/uber-go/tally"
+)
+
+// Metrics is the struct containing all the counters that track
+// internal state of the DAG service
+type Metrics struct {
+	DagAPISubmit  tally.Counter
+	DagSubmit     tally.Counter
+	DagSubmitFail tally.Counter
+
+	DagAPIGet  tally.Counter
+	DagGet     tally.Counter
+	DagGetFail tally.Counter
+
+	DagAPIList  tally.Counter
+	DagList     tally.Counter
+	DagListFail tally.Counter
+
+	DagAPICancel  tally.Counter
+	DagCancel     tally.Counter
+	DagCancelFail tally.Counter
+}
+
+// NewMetrics returns a new Metrics struct, with all metrics
+// initialized and rooted at the given tally.Scope
+func NewMetrics(scope tally.Scope) *Metrics {
+	successScope := scope.Tagged(map[string]string{"result": "success"})
+	failScope := scope.Tagged(map[string]string{"result": "fail"})
+	apiScope := scope.SubScope("api")
+
+	return &Metrics{
+		DagAPISubmit:  apiScope.Counter("submit"),
+		DagSubmit:     successScope.Counter("submit"),
+		DagSubmitFail: failScope.Counter("submit"),
+
+		DagAPIGet:  apiScope.Counter("get"),
+		DagGet:     successScope.Counter("get"),
+		DagGetFail: failScope.Counter("get"),
+
+		DagAPIList:  apiScope.Counter("list"),
+		DagList:     successScope.Counter("list"),
+		DagListFail: failScope.Counter("list"),
+
+		DagAPICancel:  apiScope.Counter("cancel"),
+		DagCancel:     successScope.Counter("cancel"),
+		DagCancelFail: failScope.Counter("cancel"),
+	}
+}
@@ -35,6 +35,8 @@ const (
 	// TODO determine the correct value of the number of
 	// parallel threads to run job updates.
 	_defaultUpdateWorkerThreads = 100
+	_defaultDagWorkerThreads    = 10
+	_defaultDagEvaluateInterval = 1 * time.Minute
 )
 
 // Config for the goalstate engine.
@@ -75,6 +77,15 @@ type Config struct {
 	// the maximum number of job updates which can be parallely processed
 	// by the goal state engine.
 	NumWorkerUpdateThreads int `yaml:"update_worker_thread_count"`
+	// NumWorkerDagThreads is the number of worker threads in the pool
+	// serving the DAG goal state engine. This number indicates
+	// the maximum number of DAGs which can be parallely processed
+	// by the goal state engine.
+	NumWorkerDagThreads int `yaml:"dag_worker_thread_count"`
+
+	// DagEvaluateInterval is the interval at which running DAGs are
+	// evaluated, in case the termination of the job of a node was missed.
+	DagEvaluateInterval time.Duration `yaml:"dag_evaluate_interval"`
 
 	// InitialTaskBackoff defines the initial back-off delay to recreate
 	// failed tasks. Back off is calculated as
@@ -132,6 +143,12 @@ func (c *Config) normalize() {
 	if c.NumWorkerUpdateThreads == 0 {
 		c.NumWorkerUpdateThreads = _defaultUpdateWorkerThreads
 	}
+	if c.NumWorkerDagThreads == 0 {
+		c.NumWorkerDagThreads = _defaultDagWorkerThreads
+	}
+	if c.DagEvaluateInterval == 0 {
+		c.DagEvaluateInterval = _defaultDagEvaluateInterval
+	}
 
 	if c.InitialTaskBackoff == 0 {
 		c.InitialTaskBackoff = _defaultInitialTaskBackoff
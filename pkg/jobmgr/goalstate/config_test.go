@@ -34,4 +34,6 @@ func TestConfigNormalize(t *testing.T) {
 	assert.Equal(t, _defaultJobWorkerThreads, c.NumWorkerJobThreads)
 	assert.Equal(t, _defaultTaskWorkerThreads, c.NumWorkerTaskThreads)
 	assert.Equal(t, _defaultUpdateWorkerThreads, c.NumWorkerUpdateThreads)
+	assert.Equal(t, _defaultDagWorkerThreads, c.NumWorkerDagThreads)
+	assert.Equal(t, _defaultDagEvaluateInterval, c.DagEvaluateInterval)
 }
@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+)
+
+// DagAction is a string for DAG actions.
+type DagAction string
+
+const (
+	// RunDagAction evaluates the nodes of a DAG, creating the jobs
+	// of the nodes which are ready to run
+	RunDagAction DagAction = "dag_run"
+)
+
+const (
+	// DagIDLabelKey is the key of the label added to the jobs created
+	// for the nodes of a DAG, with the DAG identifier as value.
+	DagIDLabelKey = "dag_id"
+	// DagNodeLabelKey is the key of the label added to the jobs created
+	// for the nodes of a DAG, with the name of the node as value.
+	DagNodeLabelKey = "dag_node"
+)
+
+// NewDagEntity implements the goal state Entity interface for DAGs.
+func NewDagEntity(id *dag.DagID, driver *driver) goalstate.Entity {
+	return &dagEntity{
+		id:     id,
+		driver: driver,
+	}
+}
+
+type dagEntity struct {
+	id     *dag.DagID // DAG identifier
+	driver *driver    // the goal state driver
+}
+
+func (d *dagEntity) GetID() string {
+	return d.id.GetValue()
+}
+
+// GetState returns nil, as DAGs are not cached. The state of the DAG
+// and of its nodes is read from DB when the DAG is run.
+func (d *dagEntity) GetState() interface{} {
+	return nil
+}
+
+// GetGoalState returns nil, as DAGs are not cached. The goal state
+// of the DAG is read from DB when the DAG is run.
+func (d *dagEntity) GetGoalState() interface{} {
+	return nil
+}
+
+func (d *dagEntity) GetActionList(
+	state interface{},
+	goalState interface{}) (
+	context.Context,
+	context.CancelFunc,
+	[]goalstate.Action) {
+	return context.Background(), nil, []goalstate.Action{
+		{
+			Name:    string(RunDagAction),
+			Execute: DagRun,
+		},
+	}
+}
+
+// isNodeStateTerminal returns true if the node of a DAG is done
+func isNodeStateTerminal(state dag.NodeState) bool {
+	switch state {
+	case dag.NodeState_NODE_SUCCEEDED,
+		dag.NodeState_NODE_FAILED,
+		dag.NodeState_NODE_KILLED,
+		dag.NodeState_NODE_SKIPPED:
+		return true
+	}
+	return false
+}
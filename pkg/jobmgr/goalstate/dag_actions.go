@@ -0,0 +1,380 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+	"github.com/uber/peloton/pkg/common/util"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	jobutil "github.com/uber/peloton/pkg/jobmgr/util/job"
+
+	"github.com/gocql/gocql"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pborman/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// DagRun evaluates a DAG. It refreshes the state of the nodes whose
+// job is running, creates the jobs of the nodes whose upstream nodes
+// are done, skips the nodes whose incoming edge conditions are not
+// met, and kills the running jobs if the DAG is canceled.
+// The DAG is written to DB before its new jobs are created, so that
+// the same job identifiers are used if creating the jobs fails.
+func DagRun(ctx context.Context, entity goalstate.Entity) error {
+	dagEnt := entity.(*dagEntity)
+	goalStateDriver := dagEnt.driver
+
+	info, err := goalStateDriver.dagOps.Get(ctx, dagEnt.id)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			// the DAG expired
+			goalStateDriver.DeleteDag(dagEnt.id)
+			return nil
+		}
+		goalStateDriver.mtx.dagMetrics.DagRunFail.Inc(1)
+		return err
+	}
+
+	if util.IsDagStateTerminal(info.GetState()) {
+		goalStateDriver.DeleteDag(dagEnt.id)
+		return nil
+	}
+
+	canceled := info.GetGoalState() == dag.DagState_DAG_CANCELED
+
+	// nodes whose job needs to be created
+	var newNodes []*dag.NodeStatus
+	for _, node := range info.GetNodes() {
+		if node.GetState() != dag.NodeState_NODE_RUNNING {
+			continue
+		}
+
+		// track the job before reading its state, so that the DAG is
+		// evaluated again if the job is untracked in the meantime
+		goalStateDriver.trackDagJob(node.GetJobId(), info.GetId())
+		jobRuntime, err := goalStateDriver.jobStore.GetJobRuntime(
+			ctx, node.GetJobId().GetValue())
+		if err != nil {
+			if !yarpcerrors.IsNotFound(err) {
+				goalStateDriver.mtx.dagMetrics.DagRunFail.Inc(1)
+				return err
+			}
+			// creating the job failed the last time the DAG was run
+			if canceled {
+				goalStateDriver.untrackDagJob(node.GetJobId())
+				skipNode(goalStateDriver, node, "DAG was canceled")
+			} else {
+				newNodes = append(newNodes, node)
+			}
+			continue
+		}
+
+		switch jobRuntime.GetState() {
+		case job.JobState_SUCCEEDED:
+			node.State = dag.NodeState_NODE_SUCCEEDED
+		case job.JobState_FAILED:
+			node.State = dag.NodeState_NODE_FAILED
+		case job.JobState_KILLED:
+			node.State = dag.NodeState_NODE_KILLED
+		default:
+			if canceled {
+				if err := killDagJob(
+					ctx, goalStateDriver, node.GetJobId()); err != nil {
+					goalStateDriver.mtx.dagMetrics.DagRunFail.Inc(1)
+					return err
+				}
+			}
+			continue
+		}
+		goalStateDriver.untrackDagJob(node.GetJobId())
+	}
+
+	if canceled {
+		for _, node := range info.GetNodes() {
+			if node.GetState() == dag.NodeState_NODE_PENDING {
+				skipNode(goalStateDriver, node, "DAG was canceled")
+			}
+		}
+	} else {
+		newNodes = append(newNodes, startDagNodes(goalStateDriver, info)...)
+	}
+
+	done := true
+	for _, node := range info.GetNodes() {
+		if !isNodeStateTerminal(node.GetState()) {
+			done = false
+			break
+		}
+	}
+	if done {
+		completeDag(goalStateDriver, info)
+	}
+
+	if err := goalStateDriver.dagOps.Update(ctx, info); err != nil {
+		goalStateDriver.mtx.dagMetrics.DagRunFail.Inc(1)
+		return err
+	}
+
+	for _, node := range newNodes {
+		if err := createDagJob(ctx, goalStateDriver, info, node); err != nil {
+			log.WithError(err).
+				WithField("dag_id", info.GetId().GetValue()).
+				WithField("node", node.GetName()).
+				Warn("failed to create job of DAG node")
+			goalStateDriver.mtx.dagMetrics.DagRunFail.Inc(1)
+			return err
+		}
+	}
+
+	goalStateDriver.mtx.dagMetrics.DagRun.Inc(1)
+
+	if done {
+		log.WithField("dag_id", info.GetId().GetValue()).
+			WithField("state", info.GetState().String()).
+			Info("DAG is done")
+		goalStateDriver.DeleteDag(info.GetId())
+		return nil
+	}
+
+	// the DAG is evaluated as soon as one of its jobs is untracked,
+	// evaluate it periodically as well in case that was missed
+	goalStateDriver.EnqueueDag(
+		info.GetId(),
+		time.Now().Add(goalStateDriver.cfg.DagEvaluateInterval))
+	return nil
+}
+
+// startDagNodes moves the pending nodes of a DAG whose upstream nodes
+// are done to running if the conditions of all their incoming edges
+// are met, and to skipped otherwise. It returns the nodes whose job
+// needs to be created.
+func startDagNodes(goalStateDriver *driver, info *dag.DagInfo) []*dag.NodeStatus {
+	nodes := make(map[string]*dag.NodeStatus)
+	for _, node := range info.GetNodes() {
+		nodes[node.GetName()] = node
+	}
+
+	var newNodes []*dag.NodeStatus
+	// skipping a node can make its downstream nodes ready,
+	// so iterate until no node changes
+	for changed := true; changed; {
+		changed = false
+		for _, node := range info.GetNodes() {
+			if node.GetState() != dag.NodeState_NODE_PENDING {
+				continue
+			}
+
+			ready := true
+			var unmet *dag.Edge
+			for _, edge := range info.GetSpec().GetEdges() {
+				if edge.GetTo() != node.GetName() {
+					continue
+				}
+				upstream := nodes[edge.GetFrom()]
+				if !isNodeStateTerminal(upstream.GetState()) {
+					ready = false
+					break
+				}
+				if unmet == nil && !isEdgeConditionMet(edge, upstream) {
+					unmet = edge
+				}
+			}
+			if !ready {
+				continue
+			}
+
+			changed = true
+			if unmet != nil {
+				skipNode(goalStateDriver, node, fmt.Sprintf(
+					"condition %s of the edge from node %s is not met, node is %s",
+					unmet.GetCondition().String(),
+					unmet.GetFrom(),
+					nodes[unmet.GetFrom()].GetState().String()))
+				continue
+			}
+			node.State = dag.NodeState_NODE_RUNNING
+			node.JobId = &peloton.JobID{Value: uuid.New()}
+			newNodes = append(newNodes, node)
+		}
+	}
+	return newNodes
+}
+
+// isEdgeConditionMet returns true if the outcome of the upstream node
+// of an edge lets its downstream node run
+func isEdgeConditionMet(edge *dag.Edge, upstream *dag.NodeStatus) bool {
+	switch edge.GetCondition() {
+	case dag.EdgeCondition_ON_SUCCESS:
+		return upstream.GetState() == dag.NodeState_NODE_SUCCEEDED
+	case dag.EdgeCondition_ON_FAILURE:
+		return upstream.GetState() == dag.NodeState_NODE_FAILED ||
+			upstream.GetState() == dag.NodeState_NODE_KILLED
+	}
+	return false
+}
+
+// skipNode moves a node of a DAG to skipped
+func skipNode(goalStateDriver *driver, node *dag.NodeStatus, message string) {
+	node.State = dag.NodeState_NODE_SKIPPED
+	node.Message = message
+	goalStateDriver.mtx.dagMetrics.DagNodeSkipped.Inc(1)
+}
+
+// completeDag sets the terminal state of a DAG whose nodes are all done
+func completeDag(goalStateDriver *driver, info *dag.DagInfo) {
+	info.State = dag.DagState_DAG_SUCCEEDED
+	if info.GetGoalState() == dag.DagState_DAG_CANCELED {
+		info.State = dag.DagState_DAG_CANCELED
+	} else {
+		for _, node := range info.GetNodes() {
+			if node.GetState() == dag.NodeState_NODE_FAILED ||
+				node.GetState() == dag.NodeState_NODE_KILLED {
+				info.State = dag.DagState_DAG_FAILED
+				break
+			}
+		}
+	}
+	info.CompletionTime = time.Now().UTC().Format(time.RFC3339Nano)
+
+	switch info.GetState() {
+	case dag.DagState_DAG_SUCCEEDED:
+		goalStateDriver.mtx.dagMetrics.DagSucceeded.Inc(1)
+	case dag.DagState_DAG_FAILED:
+		goalStateDriver.mtx.dagMetrics.DagFailed.Inc(1)
+	case dag.DagState_DAG_CANCELED:
+		goalStateDriver.mtx.dagMetrics.DagCanceled.Inc(1)
+	}
+}
+
+// createDagJob creates the batch job of a node of a DAG
+func createDagJob(
+	ctx context.Context,
+	goalStateDriver *driver,
+	info *dag.DagInfo,
+	node *dag.NodeStatus,
+) error {
+	var config *job.JobConfig
+	for _, specNode := range info.GetSpec().GetNodes() {
+		if specNode.GetName() == node.GetName() {
+			config = proto.Clone(specNode.GetJobConfig()).(*job.JobConfig)
+			break
+		}
+	}
+	if config == nil {
+		return fmt.Errorf("node %s not found in DAG spec", node.GetName())
+	}
+
+	if len(config.GetName()) == 0 {
+		config.Name = node.GetName()
+	}
+	config.Labels = append(config.Labels,
+		&peloton.Label{
+			Key:   DagIDLabelKey,
+			Value: info.GetId().GetValue(),
+		},
+		&peloton.Label{
+			Key:   DagNodeLabelKey,
+			Value: node.GetName(),
+		})
+
+	respoolPath, err := getRespoolPath(
+		ctx, goalStateDriver, config.GetRespoolID())
+	if err != nil {
+		return err
+	}
+
+	cachedJob := goalStateDriver.jobFactory.AddJob(node.GetJobId())
+	configAddOn := &models.ConfigAddOn{
+		SystemLabels: jobutil.ConstructSystemLabels(config, respoolPath),
+	}
+	err = cachedJob.Create(ctx, config, configAddOn)
+	// if err is not nil, still enqueue to goal state engine,
+	// because job may be partially created. Goal state engine
+	// knows if the job can be recovered
+	goalStateDriver.EnqueueJob(node.GetJobId(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	goalStateDriver.trackDagJob(node.GetJobId(), info.GetId())
+	goalStateDriver.mtx.dagMetrics.DagJobCreate.Inc(1)
+	return nil
+}
+
+// getRespoolPath returns the path of a resource pool
+func getRespoolPath(
+	ctx context.Context,
+	goalStateDriver *driver,
+	respoolID *peloton.ResourcePoolID,
+) (string, error) {
+	resp, err := goalStateDriver.respoolClient.GetResourcePool(
+		ctx,
+		&respool.GetRequest{Id: respoolID})
+	if err != nil {
+		return "", err
+	}
+	if resp.GetError() != nil {
+		return "", fmt.Errorf("failed to get resource pool %s: %v",
+			respoolID.GetValue(), resp.GetError())
+	}
+	return resp.GetPoolinfo().GetPath().GetValue(), nil
+}
+
+// killDagJob sets the goal state of the job of a node of a DAG to killed
+func killDagJob(
+	ctx context.Context,
+	goalStateDriver *driver,
+	jobID *peloton.JobID,
+) error {
+	cachedJob := goalStateDriver.jobFactory.AddJob(jobID)
+	for count := 0; ; count++ {
+		jobRuntime, err := cachedJob.GetRuntime(ctx)
+		if err != nil {
+			return err
+		}
+
+		if jobRuntime.GetGoalState() == job.JobState_KILLED {
+			return nil
+		}
+
+		jobRuntime.DesiredStateVersion++
+		jobRuntime.GoalState = job.JobState_KILLED
+
+		_, err = cachedJob.CompareAndSetRuntime(ctx, jobRuntime)
+		if err == nil {
+			break
+		}
+		// concurrency error; retry MaxConcurrencyErrorRetry times
+		if err != jobmgrcommon.UnexpectedVersionError ||
+			count+1 >= jobmgrcommon.MaxConcurrencyErrorRetry {
+			return err
+		}
+	}
+
+	goalStateDriver.EnqueueJob(jobID, time.Now())
+	goalStateDriver.mtx.dagMetrics.DagJobKill.Inc(1)
+	return nil
+}
@@ -0,0 +1,457 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	respoolmocks "github.com/uber/peloton/.gen/peloton/api/v0/respool/mocks"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/common/goalstate"
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
+	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type DagActionsTestSuite struct {
+	suite.Suite
+	ctrl               *gomock.Controller
+	dagGoalStateEngine *goalstatemocks.MockEngine
+	jobGoalStateEngine *goalstatemocks.MockEngine
+	jobFactory         *cachedmocks.MockJobFactory
+	cachedJob          *cachedmocks.MockJob
+	jobStore           *storemocks.MockJobStore
+	dagOps             *objectmocks.MockDagOps
+	respoolClient      *respoolmocks.MockResourceManagerYARPCClient
+	goalStateDriver    *driver
+	dagID              *dag.DagID
+	dagEnt             *dagEntity
+	respoolID          *peloton.ResourcePoolID
+	info               *dag.DagInfo
+}
+
+func TestDagActions(t *testing.T) {
+	suite.Run(t, new(DagActionsTestSuite))
+}
+
+func (suite *DagActionsTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.dagGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.jobGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.jobStore = storemocks.NewMockJobStore(suite.ctrl)
+	suite.dagOps = objectmocks.NewMockDagOps(suite.ctrl)
+	suite.respoolClient = respoolmocks.NewMockResourceManagerYARPCClient(suite.ctrl)
+	suite.goalStateDriver = &driver{
+		dagEngine:     suite.dagGoalStateEngine,
+		jobEngine:     suite.jobGoalStateEngine,
+		jobFactory:    suite.jobFactory,
+		jobStore:      suite.jobStore,
+		dagOps:        suite.dagOps,
+		respoolClient: suite.respoolClient,
+		dags:          make(map[string]*dag.DagID),
+		dagJobs:       make(map[string]*dag.DagID),
+		mtx:           NewMetrics(tally.NoopScope),
+		cfg:           &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+	suite.dagID = &dag.DagID{Value: uuid.NewRandom().String()}
+	suite.dagEnt = &dagEntity{
+		id:     suite.dagID,
+		driver: suite.goalStateDriver,
+	}
+	suite.respoolID = &peloton.ResourcePoolID{Value: uuid.NewRandom().String()}
+
+	// extract runs first, then load if it succeeds and
+	// cleanup if it fails
+	jobConfig := &job.JobConfig{
+		Type:          job.JobType_BATCH,
+		RespoolID:     suite.respoolID,
+		InstanceCount: 1,
+	}
+	suite.info = &dag.DagInfo{
+		Id: suite.dagID,
+		Spec: &dag.DagSpec{
+			Name: "etl",
+			Nodes: []*dag.Node{
+				{Name: "extract", JobConfig: jobConfig},
+				{Name: "load", JobConfig: jobConfig},
+				{Name: "cleanup", JobConfig: jobConfig},
+			},
+			Edges: []*dag.Edge{
+				{
+					From:      "extract",
+					To:        "load",
+					Condition: dag.EdgeCondition_ON_SUCCESS,
+				},
+				{
+					From:      "extract",
+					To:        "cleanup",
+					Condition: dag.EdgeCondition_ON_FAILURE,
+				},
+			},
+		},
+		State:     dag.DagState_DAG_RUNNING,
+		GoalState: dag.DagState_DAG_SUCCEEDED,
+		Nodes: []*dag.NodeStatus{
+			{Name: "extract"},
+			{Name: "load"},
+			{Name: "cleanup"},
+		},
+	}
+}
+
+func (suite *DagActionsTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// expectDagUpdate expects the DAG to be written to DB,
+// and returns the DAG info written
+func (suite *DagActionsTestSuite) expectDagUpdate() *dag.DagInfo {
+	updated := &dag.DagInfo{}
+	suite.dagOps.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, info *dag.DagInfo) {
+			*updated = *info
+		}).
+		Return(nil)
+	return updated
+}
+
+// expectJobCreate expects the job of a node to be created
+func (suite *DagActionsTestSuite) expectJobCreate(nodeName string) {
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), &respool.GetRequest{Id: suite.respoolID}).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id:   suite.respoolID,
+				Path: &respool.ResourcePoolPath{Value: "/team1/pool1"},
+			},
+		}, nil)
+	suite.jobFactory.EXPECT().
+		AddJob(gomock.Any()).
+		Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(
+			_ context.Context,
+			config *job.JobConfig,
+			configAddOn *models.ConfigAddOn) {
+			suite.Equal(nodeName, config.GetName())
+			suite.Contains(config.GetLabels(), &peloton.Label{
+				Key:   DagIDLabelKey,
+				Value: suite.dagID.GetValue(),
+			})
+			suite.Contains(config.GetLabels(), &peloton.Label{
+				Key:   DagNodeLabelKey,
+				Value: nodeName,
+			})
+			var respoolPaths []string
+			for _, label := range configAddOn.GetSystemLabels() {
+				respoolPaths = append(respoolPaths, label.GetValue())
+			}
+			suite.Contains(respoolPaths, "/team1/pool1")
+		}).
+		Return(nil)
+	suite.jobGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any())
+}
+
+// TestDagRunNotFound tests that a DAG which does not exist
+// anymore is deleted from the goal state engine
+func (suite *DagActionsTestSuite) TestDagRunNotFound() {
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(nil, gocql.ErrNotFound)
+	suite.dagGoalStateEngine.EXPECT().Delete(gomock.Any())
+
+	suite.NoError(DagRun(context.Background(), suite.dagEnt))
+}
+
+// TestDagRunGetFail tests failing to read a DAG from DB
+func (suite *DagActionsTestSuite) TestDagRunGetFail() {
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(nil, errors.New("get failed"))
+
+	suite.Error(DagRun(context.Background(), suite.dagEnt))
+}
+
+// TestDagRunStartRootNodes tests that the nodes without incoming
+// edges run first
+func (suite *DagActionsTestSuite) TestDagRunStartRootNodes() {
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(suite.info, nil)
+	updated := suite.expectDagUpdate()
+	suite.expectJobCreate("extract")
+	suite.dagGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(DagRun(context.Background(), suite.dagEnt))
+
+	suite.Equal(dag.DagState_DAG_RUNNING, updated.GetState())
+	suite.Equal(dag.NodeState_NODE_RUNNING, updated.GetNodes()[0].GetState())
+	suite.NotNil(updated.GetNodes()[0].GetJobId())
+	suite.Equal(dag.NodeState_NODE_PENDING, updated.GetNodes()[1].GetState())
+	suite.Equal(dag.NodeState_NODE_PENDING, updated.GetNodes()[2].GetState())
+	suite.Equal(
+		suite.dagID,
+		suite.goalStateDriver.dagJobs[updated.GetNodes()[0].GetJobId().GetValue()])
+}
+
+// TestDagRunUpstreamSucceeded tests that the downstream nodes whose
+// conditions are met run once the upstream job succeeds, and that
+// the other ones are skipped
+func (suite *DagActionsTestSuite) TestDagRunUpstreamSucceeded() {
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.info.Nodes[0].State = dag.NodeState_NODE_RUNNING
+	suite.info.Nodes[0].JobId = jobID
+
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(suite.info, nil)
+	suite.jobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), jobID.GetValue()).
+		Return(&job.RuntimeInfo{State: job.JobState_SUCCEEDED}, nil)
+	updated := suite.expectDagUpdate()
+	suite.expectJobCreate("load")
+	suite.dagGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(DagRun(context.Background(), suite.dagEnt))
+
+	suite.Equal(dag.DagState_DAG_RUNNING, updated.GetState())
+	suite.Equal(dag.NodeState_NODE_SUCCEEDED, updated.GetNodes()[0].GetState())
+	suite.Equal(dag.NodeState_NODE_RUNNING, updated.GetNodes()[1].GetState())
+	suite.Equal(dag.NodeState_NODE_SKIPPED, updated.GetNodes()[2].GetState())
+	suite.NotEmpty(updated.GetNodes()[2].GetMessage())
+	suite.Nil(suite.goalStateDriver.dagJobs[jobID.GetValue()])
+}
+
+// TestDagRunUpstreamRunning tests that the downstream nodes wait
+// for the upstream job to be done
+func (suite *DagActionsTestSuite) TestDagRunUpstreamRunning() {
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.info.Nodes[0].State = dag.NodeState_NODE_RUNNING
+	suite.info.Nodes[0].JobId = jobID
+
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(suite.info, nil)
+	suite.jobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), jobID.GetValue()).
+		Return(&job.RuntimeInfo{State: job.JobState_RUNNING}, nil)
+	updated := suite.expectDagUpdate()
+	suite.dagGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(DagRun(context.Background(), suite.dagEnt))
+
+	suite.Equal(dag.NodeState_NODE_RUNNING, updated.GetNodes()[0].GetState())
+	suite.Equal(dag.NodeState_NODE_PENDING, updated.GetNodes()[1].GetState())
+	suite.Equal(dag.NodeState_NODE_PENDING, updated.GetNodes()[2].GetState())
+	suite.Equal(suite.dagID, suite.goalStateDriver.dagJobs[jobID.GetValue()])
+}
+
+// TestDagRunFailed tests that a DAG fails once all its nodes are
+// done and one of them failed
+func (suite *DagActionsTestSuite) TestDagRunFailed() {
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.info.Nodes[0].State = dag.NodeState_NODE_FAILED
+	suite.info.Nodes[0].JobId = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.info.Nodes[1].State = dag.NodeState_NODE_SKIPPED
+	suite.info.Nodes[2].State = dag.NodeState_NODE_RUNNING
+	suite.info.Nodes[2].JobId = jobID
+
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(suite.info, nil)
+	suite.jobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), jobID.GetValue()).
+		Return(&job.RuntimeInfo{State: job.JobState_SUCCEEDED}, nil)
+	updated := suite.expectDagUpdate()
+	suite.dagGoalStateEngine.EXPECT().Delete(gomock.Any())
+
+	suite.NoError(DagRun(context.Background(), suite.dagEnt))
+
+	suite.Equal(dag.DagState_DAG_FAILED, updated.GetState())
+	suite.Equal(dag.NodeState_NODE_SUCCEEDED, updated.GetNodes()[2].GetState())
+	suite.NotEmpty(updated.GetCompletionTime())
+	suite.Empty(suite.goalStateDriver.dags)
+}
+
+// TestDagRunCanceled tests that the running jobs of a canceled DAG
+// are killed and its pending nodes are skipped
+func (suite *DagActionsTestSuite) TestDagRunCanceled() {
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.info.GoalState = dag.DagState_DAG_CANCELED
+	suite.info.Nodes[0].State = dag.NodeState_NODE_RUNNING
+	suite.info.Nodes[0].JobId = jobID
+
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(suite.info, nil)
+	suite.jobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), jobID.GetValue()).
+		Return(&job.RuntimeInfo{State: job.JobState_RUNNING}, nil)
+	suite.jobFactory.EXPECT().
+		AddJob(jobID).
+		Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&job.RuntimeInfo{
+			State:     job.JobState_RUNNING,
+			GoalState: job.JobState_SUCCEEDED,
+		}, nil).Times(2)
+	gomock.InOrder(
+		suite.cachedJob.EXPECT().
+			CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+			Return(nil, jobmgrcommon.UnexpectedVersionError),
+		suite.cachedJob.EXPECT().
+			CompareAndSetRuntime(gomock.Any(), gomock.Any()).
+			Do(func(_ context.Context, runtime *job.RuntimeInfo) {
+				suite.Equal(job.JobState_KILLED, runtime.GetGoalState())
+			}).
+			Return(nil, nil),
+	)
+	suite.jobGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+	updated := suite.expectDagUpdate()
+	suite.dagGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+
+	suite.NoError(DagRun(context.Background(), suite.dagEnt))
+
+	suite.Equal(dag.DagState_DAG_RUNNING, updated.GetState())
+	suite.Equal(dag.NodeState_NODE_RUNNING, updated.GetNodes()[0].GetState())
+	suite.Equal(dag.NodeState_NODE_SKIPPED, updated.GetNodes()[1].GetState())
+	suite.Equal(dag.NodeState_NODE_SKIPPED, updated.GetNodes()[2].GetState())
+
+	// once the job is killed, the DAG is canceled
+	suite.info.Nodes[0].State = dag.NodeState_NODE_RUNNING
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(suite.info, nil)
+	suite.jobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), jobID.GetValue()).
+		Return(&job.RuntimeInfo{State: job.JobState_KILLED}, nil)
+	updated = suite.expectDagUpdate()
+	suite.dagGoalStateEngine.EXPECT().Delete(gomock.Any())
+
+	suite.NoError(DagRun(context.Background(), suite.dagEnt))
+
+	suite.Equal(dag.DagState_DAG_CANCELED, updated.GetState())
+	suite.Equal(dag.NodeState_NODE_KILLED, updated.GetNodes()[0].GetState())
+}
+
+// TestDagRunRecreateJob tests that the job of a running node is
+// created again if creating it failed
+func (suite *DagActionsTestSuite) TestDagRunRecreateJob() {
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.info.Nodes[0].State = dag.NodeState_NODE_RUNNING
+	suite.info.Nodes[0].JobId = jobID
+
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(suite.info, nil).Times(2)
+	suite.jobStore.EXPECT().
+		GetJobRuntime(gomock.Any(), jobID.GetValue()).
+		Return(nil, yarpcerrors.NotFoundErrorf("job not found")).Times(2)
+	suite.dagOps.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		Return(nil).Times(2)
+
+	// creating the job fails
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("respool unavailable"))
+	suite.Error(DagRun(context.Background(), suite.dagEnt))
+
+	// the same job is created the next time the DAG is run
+	suite.respoolClient.EXPECT().
+		GetResourcePool(gomock.Any(), gomock.Any()).
+		Return(&respool.GetResponse{
+			Poolinfo: &respool.ResourcePoolInfo{
+				Id:   suite.respoolID,
+				Path: &respool.ResourcePoolPath{Value: "/team1/pool1"},
+			},
+		}, nil)
+	suite.jobFactory.EXPECT().
+		AddJob(jobID).
+		Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		Create(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil)
+	suite.jobGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+	suite.dagGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
+	suite.NoError(DagRun(context.Background(), suite.dagEnt))
+}
+
+// TestDagRunUpdateFail tests that no job is created if the DAG
+// cannot be written to DB
+func (suite *DagActionsTestSuite) TestDagRunUpdateFail() {
+	suite.dagOps.EXPECT().
+		Get(gomock.Any(), suite.dagID).
+		Return(suite.info, nil)
+	suite.dagOps.EXPECT().
+		Update(gomock.Any(), gomock.Any()).
+		Return(errors.New("update failed"))
+
+	suite.Error(DagRun(context.Background(), suite.dagEnt))
+}
+
+// TestJobUntrackEnqueuesDag tests that the DAG of a job
+// is evaluated once the job is untracked
+func (suite *DagActionsTestSuite) TestJobUntrackEnqueuesDag() {
+	jobID := &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.goalStateDriver.taskEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.goalStateDriver.trackDagJob(jobID, suite.dagID)
+
+	suite.jobFactory.EXPECT().
+		GetJob(jobID).
+		Return(suite.cachedJob)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&job.JobConfig{Type: job.JobType_BATCH}, nil)
+	suite.cachedJob.EXPECT().
+		GetAllTasks().
+		Return(nil)
+	suite.jobGoalStateEngine.EXPECT().Delete(gomock.Any())
+	suite.jobFactory.EXPECT().ClearJob(jobID)
+	suite.dagGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(entity goalstate.Entity, _ time.Time) {
+			suite.Equal(suite.dagID.GetValue(), entity.GetID())
+		})
+
+	suite.NoError(JobUntrack(
+		context.Background(),
+		NewJobEntity(jobID, suite.goalStateDriver)))
+	suite.Empty(suite.goalStateDriver.dagJobs)
+}
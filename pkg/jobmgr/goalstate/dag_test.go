@@ -0,0 +1,68 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type DagGoalStateTestSuite struct {
+	suite.Suite
+	goalStateDriver *driver
+	dagID           *dag.DagID
+	dagEnt          *dagEntity
+}
+
+func TestDagGoalState(t *testing.T) {
+	suite.Run(t, new(DagGoalStateTestSuite))
+}
+
+func (suite *DagGoalStateTestSuite) SetupTest() {
+	suite.goalStateDriver = &driver{
+		mtx: NewMetrics(tally.NoopScope),
+		cfg: &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+	suite.dagID = &dag.DagID{Value: uuid.NewRandom().String()}
+	suite.dagEnt = NewDagEntity(suite.dagID, suite.goalStateDriver).(*dagEntity)
+}
+
+// TestDagActionList tests that a DAG is always run, as its state
+// is read from DB by the action
+func (suite *DagGoalStateTestSuite) TestDagActionList() {
+	suite.Equal(suite.dagID.GetValue(), suite.dagEnt.GetID())
+	suite.Nil(suite.dagEnt.GetState())
+	suite.Nil(suite.dagEnt.GetGoalState())
+
+	_, _, actions := suite.dagEnt.GetActionList(nil, nil)
+	suite.Len(actions, 1)
+	suite.Equal(string(RunDagAction), actions[0].Name)
+}
+
+// TestNodeTerminalStates tests which states of nodes are terminal
+func (suite *DagGoalStateTestSuite) TestNodeTerminalStates() {
+	suite.False(isNodeStateTerminal(dag.NodeState_NODE_PENDING))
+	suite.False(isNodeStateTerminal(dag.NodeState_NODE_RUNNING))
+	suite.True(isNodeStateTerminal(dag.NodeState_NODE_SUCCEEDED))
+	suite.True(isNodeStateTerminal(dag.NodeState_NODE_FAILED))
+	suite.True(isNodeStateTerminal(dag.NodeState_NODE_KILLED))
+	suite.True(isNodeStateTerminal(dag.NodeState_NODE_SKIPPED))
+}
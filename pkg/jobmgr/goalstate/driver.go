@@ -20,8 +20,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -29,6 +31,7 @@ import (
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/goalstate"
 	"github.com/uber/peloton/pkg/common/recovery"
+	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	"github.com/uber/peloton/pkg/jobmgr/task/launcher"
 	"github.com/uber/peloton/pkg/storage"
@@ -115,12 +118,18 @@ type Driver interface {
 		updateID *peloton.UpdateID,
 		deadline time.Time,
 	)
+	// EnqueueDag is used to enqueue a DAG of batch jobs into the goal
+	// state. It takes the DAG identifier and the time at which the DAG
+	// should be evaluated by the goal state engine as inputs.
+	EnqueueDag(dagID *dag.DagID, deadline time.Time)
 	// DeleteJob deletes the job state from the goal state engine.
 	DeleteJob(jobID *peloton.JobID)
 	// DeleteTask deletes the task state from the goal state engine.
 	DeleteTask(jobID *peloton.JobID, instanceID uint32)
 	// DeleteUpdate deletes the job update state from the goal state engine.
 	DeleteUpdate(jobID *peloton.JobID, updateID *peloton.UpdateID)
+	// DeleteDag deletes the DAG state from the goal state engine.
+	DeleteDag(dagID *dag.DagID)
 	// IsScheduledTask is a helper function to check if a given task is scheduled
 	// for evaluation in the goal state engine.
 	IsScheduledTask(jobID *peloton.JobID, instanceID uint32) bool
@@ -151,6 +160,7 @@ func NewDriver(
 	scope := parentScope.SubScope("goalstate")
 	jobScope := scope.SubScope("job")
 	taskScope := scope.SubScope("task")
+	dagScope := scope.SubScope("dag")
 
 	return &driver{
 		jobEngine: goalstate.NewEngine(
@@ -168,16 +178,26 @@ func NewDriver(
 			cfg.FailureRetryDelay,
 			cfg.MaxRetryDelay,
 			jobScope),
+		dagEngine: goalstate.NewEngine(
+			cfg.NumWorkerDagThreads,
+			cfg.FailureRetryDelay,
+			cfg.MaxRetryDelay,
+			dagScope),
 		hostmgrClient: hostsvc.NewInternalHostServiceYARPCClient(
 			d.ClientConfig(common.PelotonHostManager)),
 		resmgrClient: resmgrsvc.NewResourceManagerServiceYARPCClient(
 			d.ClientConfig(common.PelotonResourceManager)),
+		respoolClient: respool.NewResourceManagerYARPCClient(
+			d.ClientConfig(common.PelotonResourceManager)),
 		jobStore:                      jobStore,
 		taskStore:                     taskStore,
 		volumeStore:                   volumeStore,
 		updateStore:                   updateStore,
 		jobConfigOps:                  ormobjects.NewJobConfigOps(ormStore),
 		jobIndexOps:                   ormobjects.NewJobIndexOps(ormStore),
+		dagOps:                        ormobjects.NewDagOps(ormStore),
+		dags:                          make(map[string]*dag.DagID),
+		dagJobs:                       make(map[string]*dag.DagID),
 		jobFactory:                    jobFactory,
 		taskLauncher:                  taskLauncher,
 		mtx:                           NewMetrics(scope),
@@ -219,10 +239,15 @@ type driver struct {
 	jobEngine    goalstate.Engine
 	taskEngine   goalstate.Engine
 	updateEngine goalstate.Engine
+	// dagEngine is the goal state engine for processing DAGs of batch jobs.
+	dagEngine goalstate.Engine
 
 	// hostmgrClient and resmgrClient are the host manager and resource manager clients.
 	hostmgrClient hostsvc.InternalHostServiceYARPCClient
 	resmgrClient  resmgrsvc.ResourceManagerServiceYARPCClient
+	// respoolClient is used to look up the resource pool path
+	// of the jobs created for DAGs.
+	respoolClient respool.ResourceManagerYARPCClient
 
 	// jobStore, taskStore and volumeStore are the objects to the storage interface.
 	jobStore     storage.JobStore
@@ -231,6 +256,15 @@ type driver struct {
 	updateStore  storage.UpdateStore
 	jobConfigOps ormobjects.JobConfigOps // DB ops for job_config table
 	jobIndexOps  ormobjects.JobIndexOps  // DB ops for job_index table
+	dagOps       ormobjects.DagOps       // DB ops for dags table
+
+	// dagLock protects dags and dagJobs
+	dagLock sync.Mutex
+	// dags are the DAGs enqueued in dagEngine, keyed by DAG identifier
+	dags map[string]*dag.DagID
+	// dagJobs maps the running jobs of DAGs to their DAG, so that the
+	// DAG is evaluated as soon as one of its jobs is untracked
+	dagJobs map[string]*dag.DagID
 
 	// jobFactory is the in-memory cache object fpr jobs and tasks
 	jobFactory cached.JobFactory
@@ -279,6 +313,19 @@ func (d *driver) EnqueueUpdate(
 	d.updateEngine.Enqueue(updateEntity, deadline)
 }
 
+func (d *driver) EnqueueDag(dagID *dag.DagID, deadline time.Time) {
+	dagEntity := NewDagEntity(dagID, d)
+
+	d.dagLock.Lock()
+	d.dags[dagID.GetValue()] = dagID
+	d.dagLock.Unlock()
+
+	d.RLock()
+	defer d.RUnlock()
+
+	d.dagEngine.Enqueue(dagEntity, deadline)
+}
+
 func (d *driver) DeleteJob(jobID *peloton.JobID) {
 	jobEntity := NewJobEntity(jobID, d)
 
@@ -306,6 +353,46 @@ func (d *driver) DeleteUpdate(jobID *peloton.JobID, updateID *peloton.UpdateID)
 	d.updateEngine.Delete(updateEntity)
 }
 
+func (d *driver) DeleteDag(dagID *dag.DagID) {
+	dagEntity := NewDagEntity(dagID, d)
+
+	d.dagLock.Lock()
+	delete(d.dags, dagID.GetValue())
+	for jobID, id := range d.dagJobs {
+		if id.GetValue() == dagID.GetValue() {
+			delete(d.dagJobs, jobID)
+		}
+	}
+	d.dagLock.Unlock()
+
+	d.RLock()
+	defer d.RUnlock()
+
+	d.dagEngine.Delete(dagEntity)
+}
+
+// trackDagJob records that a job runs a node of a DAG.
+func (d *driver) trackDagJob(jobID *peloton.JobID, dagID *dag.DagID) {
+	d.dagLock.Lock()
+	defer d.dagLock.Unlock()
+
+	d.dagJobs[jobID.GetValue()] = dagID
+}
+
+// untrackDagJob returns the DAG a job runs a node of, if any,
+// and stops tracking the job.
+func (d *driver) untrackDagJob(jobID *peloton.JobID) *dag.DagID {
+	d.dagLock.Lock()
+	defer d.dagLock.Unlock()
+
+	dagID, ok := d.dagJobs[jobID.GetValue()]
+	if !ok {
+		return nil
+	}
+	delete(d.dagJobs, jobID.GetValue())
+	return dagID
+}
+
 func (d *driver) IsScheduledTask(jobID *peloton.JobID, instanceID uint32) bool {
 	taskEntity := NewTaskEntity(jobID, instanceID, d)
 
@@ -428,6 +515,10 @@ func (d *driver) syncFromDB(ctx context.Context) error {
 		return err
 	}
 
+	if err := d.syncDagsFromDB(ctx); err != nil {
+		return err
+	}
+
 	log.WithField("time_spent", time.Since(startRecoveryTime)).
 		Info("syncing cache and goal state with db is finished")
 	d.mtx.jobMetrics.JobRecoveryDuration.Update(float64(time.Since(startRecoveryTime) / time.Millisecond))
@@ -435,6 +526,24 @@ func (d *driver) syncFromDB(ctx context.Context) error {
 	return nil
 }
 
+// syncDagsFromDB enqueues the DAGs in DB which are not done yet into
+// the goal state engine, when job manager instance gains leadership.
+func (d *driver) syncDagsFromDB(ctx context.Context) error {
+	infos, err := d.dagOps.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, info := range infos {
+		if util.IsDagStateTerminal(info.GetState()) {
+			continue
+		}
+		d.mtx.dagMetrics.DagRecovered.Inc(1)
+		d.EnqueueDag(info.GetId(), time.Now())
+	}
+	return nil
+}
+
 // runningState returns the running state of the driver
 // (1 is not running, 2 if runing and 0 is invalid).
 func (d *driver) runningState() int32 {
@@ -459,6 +568,7 @@ func (d *driver) Start() {
 	d.jobEngine.Start()
 	d.taskEngine.Start()
 	d.updateEngine.Start()
+	d.dagEngine.Start()
 	d.Unlock()
 
 	atomic.StoreInt32(&d.running, int32(running))
@@ -475,6 +585,7 @@ func (d *driver) Stop() {
 	}
 
 	d.Lock()
+	d.dagEngine.Stop()
 	d.updateEngine.Stop()
 	d.taskEngine.Stop()
 	d.jobEngine.Stop()
@@ -504,6 +615,17 @@ func (d *driver) Stop() {
 		}
 	}
 
+	// Cleanup DAGs from the goal state engine
+	d.dagLock.Lock()
+	var dagIDs []*dag.DagID
+	for _, dagID := range d.dags {
+		dagIDs = append(dagIDs, dagID)
+	}
+	d.dagLock.Unlock()
+	for _, dagID := range dagIDs {
+		d.DeleteDag(dagID)
+	}
+
 	atomic.StoreInt32(&d.running, int32(notRunning))
 	log.Info("goalstate driver stopped")
 }
@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
@@ -33,6 +34,7 @@ import (
 	launchermocks "github.com/uber/peloton/pkg/jobmgr/task/launcher/mocks"
 	storemocks "github.com/uber/peloton/pkg/storage/mocks"
 	ormStore "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
 	"github.com/golang/mock/gomock"
 	"github.com/pborman/uuid"
@@ -50,9 +52,11 @@ type DriverTestSuite struct {
 	jobGoalStateEngine    *goalstatemocks.MockEngine
 	updateGoalStateEngine *goalstatemocks.MockEngine
 	taskGoalStateEngine   *goalstatemocks.MockEngine
+	dagGoalStateEngine    *goalstatemocks.MockEngine
 	jobStore              *storemocks.MockJobStore
 	taskStore             *storemocks.MockTaskStore
 	jobFactory            *cachedmocks.MockJobFactory
+	dagOps                *objectmocks.MockDagOps
 	goalStateDriver       *driver
 	cachedJob             *cachedmocks.MockJob
 	jobID                 *peloton.JobID
@@ -72,13 +76,19 @@ func (suite *DriverTestSuite) SetupTest() {
 	suite.jobStore = storemocks.NewMockJobStore(suite.ctrl)
 	suite.taskStore = storemocks.NewMockTaskStore(suite.ctrl)
 	suite.jobFactory = cachedmocks.NewMockJobFactory(suite.ctrl)
+	suite.dagGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.dagOps = objectmocks.NewMockDagOps(suite.ctrl)
 	suite.goalStateDriver = &driver{
 		jobEngine:    suite.jobGoalStateEngine,
 		taskEngine:   suite.taskGoalStateEngine,
 		updateEngine: suite.updateGoalStateEngine,
+		dagEngine:    suite.dagGoalStateEngine,
 		jobStore:     suite.jobStore,
 		taskStore:    suite.taskStore,
 		jobFactory:   suite.jobFactory,
+		dagOps:       suite.dagOps,
+		dags:         make(map[string]*dag.DagID),
+		dagJobs:      make(map[string]*dag.DagID),
 		mtx:          NewMetrics(tally.NoopScope),
 		jobScope:     tally.NoopScope,
 		cfg: &Config{
@@ -211,6 +221,57 @@ func (suite *DriverTestSuite) TestDeleteUpdate() {
 	suite.goalStateDriver.DeleteUpdate(suite.jobID, suite.updateID)
 }
 
+// TestEnqueueDeleteDag tests enqueuing a DAG into and
+// deleting it from the goal state engine.
+func (suite *DriverTestSuite) TestEnqueueDeleteDag() {
+	dagID := &dag.DagID{Value: uuid.NewRandom().String()}
+	suite.goalStateDriver.trackDagJob(suite.jobID, dagID)
+
+	suite.dagGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(dagEntity goalstate.Entity, deadline time.Time) {
+			suite.Equal(dagID.GetValue(), dagEntity.GetID())
+		})
+	suite.goalStateDriver.EnqueueDag(dagID, time.Now())
+	suite.Equal(dagID, suite.goalStateDriver.dags[dagID.GetValue()])
+
+	suite.dagGoalStateEngine.EXPECT().
+		Delete(gomock.Any()).
+		Do(func(dagEntity goalstate.Entity) {
+			suite.Equal(dagID.GetValue(), dagEntity.GetID())
+		})
+	suite.goalStateDriver.DeleteDag(dagID)
+	suite.Empty(suite.goalStateDriver.dags)
+	suite.Empty(suite.goalStateDriver.dagJobs)
+}
+
+// TestSyncDagsFromDB tests that only the DAGs which
+// are not done are recovered.
+func (suite *DriverTestSuite) TestSyncDagsFromDB() {
+	dagID := &dag.DagID{Value: uuid.NewRandom().String()}
+	suite.dagOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*dag.DagInfo{
+			{Id: dagID, State: dag.DagState_DAG_RUNNING},
+			{
+				Id:    &dag.DagID{Value: uuid.NewRandom().String()},
+				State: dag.DagState_DAG_SUCCEEDED,
+			},
+		}, nil)
+	suite.dagGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(dagEntity goalstate.Entity, deadline time.Time) {
+			suite.Equal(dagID.GetValue(), dagEntity.GetID())
+		})
+
+	suite.NoError(suite.goalStateDriver.syncDagsFromDB(context.Background()))
+
+	suite.dagOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+	suite.Error(suite.goalStateDriver.syncDagsFromDB(context.Background()))
+}
+
 // TestIsScheduledTask tests determination oif whether a task
 // is scheduled in goal state engine.
 func (suite *DriverTestSuite) TestIsScheduledTask() {
@@ -341,6 +402,8 @@ func (suite *DriverTestSuite) TestSyncFromDBForBatchCluster() {
 	suite.cachedJob.EXPECT().
 		RecalculateResourceUsage(gomock.Any())
 
+	suite.dagOps.EXPECT().GetAll(gomock.Any()).Return(nil, nil)
+
 	suite.NoError(suite.goalStateDriver.syncFromDB(context.Background()))
 }
 
@@ -374,6 +437,8 @@ func (suite *DriverTestSuite) TestSyncFromDBForServiceCluster() {
 	suite.cachedJob.EXPECT().
 		RecalculateResourceUsage(gomock.Any())
 
+	suite.dagOps.EXPECT().GetAll(gomock.Any()).Return(nil, nil)
+
 	suite.NoError(suite.goalStateDriver.syncFromDB(context.Background()))
 }
 
@@ -461,6 +526,8 @@ func (suite *DriverTestSuite) TestSyncFromDBWithMaxRunningInstancesSLA() {
 	suite.cachedJob.EXPECT().
 		RecalculateResourceUsage(gomock.Any())
 
+	suite.dagOps.EXPECT().GetAll(gomock.Any()).Return(nil, nil)
+
 	suite.goalStateDriver.syncFromDB(context.Background())
 }
 
@@ -532,6 +599,8 @@ func (suite *DriverTestSuite) TestInitializedJobSyncFromDB() {
 	suite.cachedJob.EXPECT().
 		RecalculateResourceUsage(gomock.Any())
 
+	suite.dagOps.EXPECT().GetAll(gomock.Any()).Return(nil, nil)
+
 	suite.goalStateDriver.syncFromDB(context.Background())
 }
 
@@ -624,6 +693,8 @@ func (suite *DriverTestSuite) TestSyncFromDBRecoverUpdate() {
 	suite.cachedJob.EXPECT().
 		RecalculateResourceUsage(gomock.Any())
 
+	suite.dagOps.EXPECT().GetAll(gomock.Any()).Return(nil, nil)
+
 	suite.goalStateDriver.syncFromDB(context.Background())
 }
 
@@ -631,6 +702,7 @@ func (suite *DriverTestSuite) TestSyncFromDBRecoverUpdate() {
 func (suite *DriverTestSuite) TestEngineStartStop() {
 	cachedTask := cachedmocks.NewMockTask(suite.ctrl)
 	cachedUpdate := cachedmocks.NewMockUpdate(suite.ctrl)
+	dagID := &dag.DagID{Value: uuid.NewRandom().String()}
 
 	// Test start
 	var jobIDList []peloton.JobID
@@ -644,6 +716,11 @@ func (suite *DriverTestSuite) TestEngineStartStop() {
 	suite.jobStore.EXPECT().
 		GetActiveJobs(gomock.Any()).
 		Return([]*peloton.JobID{}, nil)
+	suite.dagGoalStateEngine.EXPECT().Start()
+	suite.dagOps.EXPECT().
+		GetAll(gomock.Any()).
+		Return([]*dag.DagInfo{{Id: dagID, State: dag.DagState_DAG_RUNNING}}, nil)
+	suite.dagGoalStateEngine.EXPECT().Enqueue(gomock.Any(), gomock.Any())
 
 	suite.goalStateDriver.Start()
 
@@ -666,6 +743,13 @@ func (suite *DriverTestSuite) TestEngineStartStop() {
 	suite.taskGoalStateEngine.EXPECT().Delete(gomock.Any())
 	suite.jobGoalStateEngine.EXPECT().Delete(gomock.Any())
 	suite.updateGoalStateEngine.EXPECT().Delete(gomock.Any())
+	suite.dagGoalStateEngine.EXPECT().Stop()
+	suite.dagGoalStateEngine.EXPECT().
+		Delete(gomock.Any()).
+		Do(func(dagEntity goalstate.Entity) {
+			suite.Equal(dagID.GetValue(), dagEntity.GetID())
+		})
 
 	suite.goalStateDriver.Stop()
+	suite.Empty(suite.goalStateDriver.dags)
 }
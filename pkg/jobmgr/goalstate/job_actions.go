@@ -64,6 +64,11 @@ func JobUntrack(ctx context.Context, entity goalstate.Entity) error {
 
 	// Next clean up from the cache
 	goalStateDriver.jobFactory.ClearJob(jobEnt.id)
+
+	// Evaluate the DAG the job runs a node of, if any
+	if dagID := goalStateDriver.untrackDagJob(jobEnt.id); dagID != nil {
+		goalStateDriver.EnqueueDag(dagID, time.Now())
+	}
 	return nil
 }
 
@@ -64,6 +64,22 @@ type UpdateMetrics struct {
 	UpdateRunFail           tally.Counter
 	UpdateWriteProgress     tally.Counter
 	UpdateWriteProgressFail tally.Counter
+	UpdateCanaryPromoted    tally.Counter
+	UpdateCanaryFailed      tally.Counter
+}
+
+// DagMetrics contains all counters to track
+// DAG metrics in the goal state.
+type DagMetrics struct {
+	DagRecovered   tally.Counter
+	DagRun         tally.Counter
+	DagRunFail     tally.Counter
+	DagJobCreate   tally.Counter
+	DagJobKill     tally.Counter
+	DagNodeSkipped tally.Counter
+	DagSucceeded   tally.Counter
+	DagFailed      tally.Counter
+	DagCanceled    tally.Counter
 }
 
 // Metrics is the struct containing all the counters that track job and task
@@ -72,6 +88,7 @@ type Metrics struct {
 	jobMetrics    *JobMetrics
 	taskMetrics   *TaskMetrics
 	updateMetrics *UpdateMetrics
+	dagMetrics    *DagMetrics
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics
@@ -80,6 +97,7 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	jobScope := scope.SubScope("job")
 	taskScope := scope.SubScope("task")
 	updateScope := scope.SubScope("update")
+	dagScope := scope.SubScope("dag")
 
 	jobMetrics := &JobMetrics{
 		JobCreate:                        jobScope.Counter("create"),
@@ -121,11 +139,26 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		UpdateRunFail:           updateScope.Counter("run_fail"),
 		UpdateWriteProgress:     updateScope.Counter("write_progress"),
 		UpdateWriteProgressFail: updateScope.Counter("write_progress_fail"),
+		UpdateCanaryPromoted:    updateScope.Counter("canary_promoted"),
+		UpdateCanaryFailed:      updateScope.Counter("canary_failed"),
+	}
+
+	dagMetrics := &DagMetrics{
+		DagRecovered:   dagScope.Counter("recovered"),
+		DagRun:         dagScope.Counter("run"),
+		DagRunFail:     dagScope.Counter("run_fail"),
+		DagJobCreate:   dagScope.Counter("job_create"),
+		DagJobKill:     dagScope.Counter("job_kill"),
+		DagNodeSkipped: dagScope.Counter("node_skipped"),
+		DagSucceeded:   dagScope.Counter("dag_succeeded"),
+		DagFailed:      dagScope.Counter("dag_failed"),
+		DagCanceled:    dagScope.Counter("dag_canceled"),
 	}
 
 	return &Metrics{
 		jobMetrics:    jobMetrics,
 		taskMetrics:   taskMetrics,
 		updateMetrics: updateMetrics,
+		dagMetrics:    dagMetrics,
 	}
 }
@@ -800,7 +800,7 @@ func (suite *UpdateActionsTestSuite) TestUpdateWriteProgressSuccess() {
 				Return(false)
 
 			suite.cachedUpdate.EXPECT().
-				IsInstanceFailed(runtime, updateConfig.GetMaxInstanceAttempts()).
+				IsInstanceFailed(runtime, updateConfig).
 				Return(true)
 		} else {
 			runtime := &pbtask.RuntimeInfo{
@@ -818,7 +818,7 @@ func (suite *UpdateActionsTestSuite) TestUpdateWriteProgressSuccess() {
 				Return(false)
 
 			suite.cachedUpdate.EXPECT().
-				IsInstanceFailed(runtime, updateConfig.GetMaxInstanceAttempts()).
+				IsInstanceFailed(runtime, updateConfig).
 				Return(false)
 
 			suite.cachedUpdate.EXPECT().
@@ -0,0 +1,228 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"time"
+
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// canaryPhase is the phase of the canary of an update in a run of UpdateRun
+type canaryPhase int
+
+const (
+	// canaryNone indicates the update does not have a canary phase,
+	// or the canary phase has already completed
+	canaryNone canaryPhase = iota
+	// canaryRunning indicates the canary instances are being updated
+	canaryRunning
+	// canaryBaking indicates all of the canary instances have been
+	// updated, and the update is held until the bake time passes
+	canaryBaking
+	// canaryPromoted indicates the canary instances have baked
+	// successfully, and the update can proceed to the rest of instances
+	canaryPromoted
+	// canaryFailed indicates the canary instances failed more
+	// than the update tolerates
+	canaryFailed
+)
+
+// getCanaryPhase returns the canary phase of the update given the
+// instances processed so far. The canary instances are the first instances
+// processed by the update. If the canary instances are baking, it also
+// returns the time at which the bake time passes.
+func getCanaryPhase(
+	ctx context.Context,
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+	updateConfig *pbupdate.UpdateConfig,
+	instancesCurrent []uint32,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+) (canaryPhase, time.Time, error) {
+	canary := updateConfig.GetCanary()
+	if canary.GetInstanceCount() == 0 {
+		return canaryNone, time.Time{}, nil
+	}
+
+	// canary only applies when rolling forward an update, and
+	// the canary instances must be a subset of the instances in the update
+	if cachedUpdate.GetWorkflowType() != models.WorkflowType_UPDATE ||
+		cachedUpdate.GetState().State != pbupdate.State_ROLLING_FORWARD ||
+		int(canary.GetInstanceCount()) >=
+			len(cachedUpdate.GetGoalState().Instances) {
+		return canaryNone, time.Time{}, nil
+	}
+
+	// more instances than the canary instances have been processed,
+	// which means the canary has been promoted
+	instancesCompleted := len(instancesDone) + len(instancesFailed)
+	if instancesCompleted+len(instancesCurrent) >
+		int(canary.GetInstanceCount()) {
+		return canaryNone, time.Time{}, nil
+	}
+
+	if instancesCompleted < int(canary.GetInstanceCount()) {
+		return canaryRunning, time.Time{}, nil
+	}
+
+	// all of the canary instances have completed, each failed
+	// instance counts as one failure, and each restart of
+	// the instances done counts as another one
+	failures := uint32(len(instancesFailed))
+	var bakeStartTime time.Time
+	for _, instID := range instancesDone {
+		cachedTask := cachedJob.GetTask(instID)
+		if cachedTask == nil {
+			continue
+		}
+
+		runtime, err := cachedTask.GetRuntime(ctx)
+		if yarpcerrors.IsNotFound(err) {
+			// instance removed in the update
+			continue
+		}
+		if err != nil {
+			return canaryNone, time.Time{}, err
+		}
+
+		failures += runtime.GetFailureCount()
+		startTime, err := time.Parse(time.RFC3339Nano, runtime.GetStartTime())
+		if err == nil && startTime.After(bakeStartTime) {
+			bakeStartTime = startTime
+		}
+	}
+
+	if failures > canary.GetMaxFailures() {
+		return canaryFailed, time.Time{}, nil
+	}
+
+	// every canary instance failed within the tolerated failures,
+	// no canary instance is running to bake
+	if bakeStartTime.IsZero() {
+		return canaryPromoted, time.Time{}, nil
+	}
+
+	bakeDeadline := bakeStartTime.Add(
+		time.Duration(canary.GetBakeTimeSecs()) * time.Second)
+	if time.Now().Before(bakeDeadline) {
+		return canaryBaking, bakeDeadline, nil
+	}
+
+	return canaryPromoted, time.Time{}, nil
+}
+
+// getBatchSizeForUpdateRun returns the number of instances which can be
+// processed at the same time in the given canary phase. It returns 0 if
+// there is no limit.
+func getBatchSizeForUpdateRun(
+	updateConfig *pbupdate.UpdateConfig,
+	phase canaryPhase,
+	instancesDone []uint32,
+	instancesFailed []uint32,
+) uint32 {
+	batchSize := updateConfig.GetBatchSize()
+	if phase != canaryRunning {
+		return batchSize
+	}
+
+	// do not process more instances than the canary instances
+	canaryRemaining := updateConfig.GetCanary().GetInstanceCount() -
+		uint32(len(instancesDone)+len(instancesFailed))
+	if batchSize == 0 || batchSize > canaryRemaining {
+		return canaryRemaining
+	}
+	return batchSize
+}
+
+// promoteCanary records that the canary instances of the update
+// have baked successfully, before the update proceeds to the rest
+// of instances. The promotion is recorded once, although the update
+// can be promoted by several runs until it proceeds.
+func promoteCanary(
+	ctx context.Context,
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+	driver *driver,
+) error {
+	events, err := driver.updateStore.GetJobUpdateEvents(
+		ctx, cachedUpdate.ID())
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if event.GetCanaryState() ==
+			stateless.CanaryState_CANARY_STATE_PROMOTED {
+			return nil
+		}
+	}
+
+	if err := driver.updateStore.AddJobUpdateCanaryEvent(
+		ctx,
+		cachedUpdate.ID(),
+		cachedUpdate.GetWorkflowType(),
+		pbupdate.State_ROLLING_FORWARD,
+		pbupdate.CanaryState_CANARY_STATE_PROMOTED,
+	); err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"update_id": cachedUpdate.ID().GetValue(),
+		"job_id":    cachedJob.ID().GetValue(),
+	}).Info("update canary promoted")
+	driver.mtx.updateMetrics.UpdateCanaryPromoted.Inc(1)
+	return nil
+}
+
+// processFailedCanary is called when the canary instances of the
+// update fail more than tolerated. The update is rolled back regardless
+// of RollbackOnFailure, and enqueued to goal state engine directly.
+func processFailedCanary(
+	ctx context.Context,
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+	driver *driver,
+) error {
+	// the event is recorded in the state the canary failed in, before
+	// the rollback records the update rolling backward, because the
+	// canary phase no longer applies once the update is rolling back
+	if err := driver.updateStore.AddJobUpdateCanaryEvent(
+		ctx,
+		cachedUpdate.ID(),
+		cachedUpdate.GetWorkflowType(),
+		cachedUpdate.GetState().State,
+		pbupdate.CanaryState_CANARY_STATE_FAILED,
+	); err != nil {
+		return err
+	}
+
+	if err := rollbackUpdate(ctx, cachedJob, cachedUpdate); err != nil {
+		return err
+	}
+
+	driver.mtx.updateMetrics.UpdateCanaryFailed.Inc(1)
+	driver.EnqueueUpdate(cachedJob.ID(), cachedUpdate.ID(), time.Now())
+	return nil
+}
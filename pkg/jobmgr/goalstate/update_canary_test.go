@@ -0,0 +1,451 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package goalstate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
+	pbupdate "github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	"github.com/uber/peloton/.gen/peloton/private/models"
+
+	goalstatemocks "github.com/uber/peloton/pkg/common/goalstate/mocks"
+	"github.com/uber/peloton/pkg/jobmgr/cached"
+	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
+	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type UpdateCanaryTestSuite struct {
+	suite.Suite
+	ctrl                  *gomock.Controller
+	updateGoalStateEngine *goalstatemocks.MockEngine
+	goalStateDriver       *driver
+	jobID                 *peloton.JobID
+	updateID              *peloton.UpdateID
+	cachedJob             *cachedmocks.MockJob
+	cachedUpdate          *cachedmocks.MockUpdate
+	cachedTask            *cachedmocks.MockTask
+	updateStore           *storemocks.MockUpdateStore
+	updateConfig          *pbupdate.UpdateConfig
+}
+
+func TestUpdateCanary(t *testing.T) {
+	suite.Run(t, new(UpdateCanaryTestSuite))
+}
+
+func (suite *UpdateCanaryTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.updateGoalStateEngine = goalstatemocks.NewMockEngine(suite.ctrl)
+	suite.updateStore = storemocks.NewMockUpdateStore(suite.ctrl)
+
+	suite.goalStateDriver = &driver{
+		updateEngine: suite.updateGoalStateEngine,
+		updateStore:  suite.updateStore,
+		mtx:          NewMetrics(tally.NoopScope),
+		cfg:          &Config{},
+	}
+	suite.goalStateDriver.cfg.normalize()
+
+	suite.jobID = &peloton.JobID{Value: uuid.NewRandom().String()}
+	suite.updateID = &peloton.UpdateID{Value: uuid.NewRandom().String()}
+	suite.cachedJob = cachedmocks.NewMockJob(suite.ctrl)
+	suite.cachedUpdate = cachedmocks.NewMockUpdate(suite.ctrl)
+	suite.cachedTask = cachedmocks.NewMockTask(suite.ctrl)
+
+	suite.updateConfig = &pbupdate.UpdateConfig{
+		BatchSize: 3,
+		Canary: &pbupdate.CanaryConfig{
+			InstanceCount: 2,
+			BakeTimeSecs:  60,
+			MaxFailures:   1,
+		},
+	}
+
+	suite.cachedJob.EXPECT().ID().Return(suite.jobID).AnyTimes()
+	suite.cachedUpdate.EXPECT().ID().Return(suite.updateID).AnyTimes()
+}
+
+func (suite *UpdateCanaryTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// expectRollingForward sets up the expectations of an update
+// rolling forward instances 0 to 4
+func (suite *UpdateCanaryTestSuite) expectRollingForward() {
+	suite.cachedUpdate.EXPECT().
+		GetWorkflowType().
+		Return(models.WorkflowType_UPDATE).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetState().
+		Return(&cached.UpdateStateVector{
+			State: pbupdate.State_ROLLING_FORWARD,
+		}).
+		AnyTimes()
+	suite.cachedUpdate.EXPECT().
+		GetGoalState().
+		Return(&cached.UpdateStateVector{
+			Instances: []uint32{0, 1, 2, 3, 4},
+		}).
+		AnyTimes()
+}
+
+// expectCanaryRuntimes sets up the task runtimes of the canary instances
+func (suite *UpdateCanaryTestSuite) expectCanaryRuntimes(
+	startTime time.Time,
+	failureCount uint32,
+) {
+	suite.cachedJob.EXPECT().
+		GetTask(gomock.Any()).
+		Return(suite.cachedTask).
+		Times(2)
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&pbtask.RuntimeInfo{
+			State:        pbtask.TaskState_RUNNING,
+			StartTime:    startTime.Format(time.RFC3339Nano),
+			FailureCount: failureCount,
+		}, nil).
+		Times(2)
+}
+
+// TestGetCanaryPhaseNoCanary tests that an update without
+// canary config does not have a canary phase
+func (suite *UpdateCanaryTestSuite) TestGetCanaryPhaseNoCanary() {
+	phase, _, err := getCanaryPhase(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		&pbupdate.UpdateConfig{BatchSize: 3},
+		nil,
+		nil,
+		nil,
+	)
+	suite.NoError(err)
+	suite.Equal(canaryNone, phase)
+}
+
+// TestGetCanaryPhaseRollingBackward tests that a rollback
+// does not have a canary phase
+func (suite *UpdateCanaryTestSuite) TestGetCanaryPhaseRollingBackward() {
+	suite.cachedUpdate.EXPECT().
+		GetWorkflowType().
+		Return(models.WorkflowType_UPDATE)
+	suite.cachedUpdate.EXPECT().
+		GetState().
+		Return(&cached.UpdateStateVector{
+			State: pbupdate.State_ROLLING_BACKWARD,
+		})
+
+	phase, _, err := getCanaryPhase(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.updateConfig,
+		nil,
+		nil,
+		nil,
+	)
+	suite.NoError(err)
+	suite.Equal(canaryNone, phase)
+}
+
+// TestGetCanaryPhaseRunning tests that the batch size is limited
+// to the canary instances when the canary instances are being updated
+func (suite *UpdateCanaryTestSuite) TestGetCanaryPhaseRunning() {
+	suite.expectRollingForward()
+
+	phase, _, err := getCanaryPhase(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.updateConfig,
+		nil,
+		[]uint32{0},
+		nil,
+	)
+	suite.NoError(err)
+	suite.Equal(canaryRunning, phase)
+	suite.Equal(uint32(1), getBatchSizeForUpdateRun(
+		suite.updateConfig, phase, []uint32{0}, nil))
+
+	suite.updateConfig.BatchSize = 0
+	suite.Equal(uint32(2), getBatchSizeForUpdateRun(
+		suite.updateConfig, phase, nil, nil))
+}
+
+// TestGetCanaryPhaseBaking tests that the update is held until
+// the bake time passes after the canary instances start running
+func (suite *UpdateCanaryTestSuite) TestGetCanaryPhaseBaking() {
+	suite.expectRollingForward()
+	startTime := time.Now().Add(-10 * time.Second)
+	suite.expectCanaryRuntimes(startTime, 0)
+
+	phase, bakeDeadline, err := getCanaryPhase(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.updateConfig,
+		nil,
+		[]uint32{0, 1},
+		nil,
+	)
+	suite.NoError(err)
+	suite.Equal(canaryBaking, phase)
+	suite.True(startTime.Add(60 * time.Second).Equal(bakeDeadline))
+}
+
+// TestGetCanaryPhasePromoted tests that the canary is promoted
+// once the bake time passes without too many failures
+func (suite *UpdateCanaryTestSuite) TestGetCanaryPhasePromoted() {
+	suite.expectRollingForward()
+	suite.expectCanaryRuntimes(time.Now().Add(-2*time.Minute), 0)
+
+	phase, _, err := getCanaryPhase(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.updateConfig,
+		nil,
+		[]uint32{0, 1},
+		nil,
+	)
+	suite.NoError(err)
+	suite.Equal(canaryPromoted, phase)
+	suite.Equal(uint32(3), getBatchSizeForUpdateRun(
+		suite.updateConfig, phase, []uint32{0, 1}, nil))
+}
+
+// TestGetCanaryPhaseFailed tests that the canary fails if the restarts
+// of the canary instances exceed the max failures
+func (suite *UpdateCanaryTestSuite) TestGetCanaryPhaseFailed() {
+	suite.expectRollingForward()
+	suite.expectCanaryRuntimes(time.Now(), 1)
+
+	phase, _, err := getCanaryPhase(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.updateConfig,
+		nil,
+		[]uint32{0, 1},
+		nil,
+	)
+	suite.NoError(err)
+	suite.Equal(canaryFailed, phase)
+}
+
+// TestGetCanaryPhaseAllCanaryFailed tests that the canary is promoted
+// without baking if every canary instance failed within the tolerated
+// failures
+func (suite *UpdateCanaryTestSuite) TestGetCanaryPhaseAllCanaryFailed() {
+	suite.expectRollingForward()
+	suite.updateConfig.Canary.MaxFailures = 2
+
+	phase, bakeDeadline, err := getCanaryPhase(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.updateConfig,
+		nil,
+		nil,
+		[]uint32{0, 1},
+	)
+	suite.NoError(err)
+	suite.Equal(canaryPromoted, phase)
+	suite.True(bakeDeadline.IsZero())
+}
+
+// TestGetCanaryPhaseGetRuntimeFail tests that an error is returned
+// if the runtime of a canary instance cannot be read
+func (suite *UpdateCanaryTestSuite) TestGetCanaryPhaseGetRuntimeFail() {
+	suite.expectRollingForward()
+	suite.cachedJob.EXPECT().
+		GetTask(uint32(0)).
+		Return(suite.cachedTask)
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(nil, fmt.Errorf("fake db error"))
+
+	_, _, err := getCanaryPhase(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.updateConfig,
+		nil,
+		[]uint32{0},
+		[]uint32{1},
+	)
+	suite.EqualError(err, "fake db error")
+}
+
+// TestGetCanaryPhaseAfterPromotion tests that the canary phase
+// does not apply once the update proceeds to the rest of instances
+func (suite *UpdateCanaryTestSuite) TestGetCanaryPhaseAfterPromotion() {
+	suite.expectRollingForward()
+
+	phase, _, err := getCanaryPhase(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.updateConfig,
+		[]uint32{2, 3},
+		[]uint32{0, 1},
+		nil,
+	)
+	suite.NoError(err)
+	suite.Equal(canaryNone, phase)
+}
+
+// TestPromoteCanary tests recording the canary promotion
+func (suite *UpdateCanaryTestSuite) TestPromoteCanary() {
+	suite.updateStore.EXPECT().
+		GetJobUpdateEvents(gomock.Any(), suite.updateID).
+		Return([]*stateless.WorkflowEvent{
+			{State: stateless.WorkflowState_WORKFLOW_STATE_ROLLING_FORWARD},
+		}, nil)
+	suite.cachedUpdate.EXPECT().
+		GetWorkflowType().
+		Return(models.WorkflowType_UPDATE)
+	suite.updateStore.EXPECT().
+		AddJobUpdateCanaryEvent(
+			gomock.Any(),
+			suite.updateID,
+			models.WorkflowType_UPDATE,
+			pbupdate.State_ROLLING_FORWARD,
+			pbupdate.CanaryState_CANARY_STATE_PROMOTED).
+		Return(nil)
+
+	suite.NoError(promoteCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.goalStateDriver,
+	))
+}
+
+// TestPromoteCanaryAlreadyPromoted tests that the canary promotion
+// is not recorded again by a later run of the update
+func (suite *UpdateCanaryTestSuite) TestPromoteCanaryAlreadyPromoted() {
+	suite.updateStore.EXPECT().
+		GetJobUpdateEvents(gomock.Any(), suite.updateID).
+		Return([]*stateless.WorkflowEvent{
+			{
+				State:       stateless.WorkflowState_WORKFLOW_STATE_ROLLING_FORWARD,
+				CanaryState: stateless.CanaryState_CANARY_STATE_PROMOTED,
+			},
+			{State: stateless.WorkflowState_WORKFLOW_STATE_ROLLING_FORWARD},
+		}, nil)
+
+	suite.NoError(promoteCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.goalStateDriver,
+	))
+}
+
+// TestPromoteCanaryGetEventsError tests that the canary promotion
+// fails if the update events cannot be read
+func (suite *UpdateCanaryTestSuite) TestPromoteCanaryGetEventsError() {
+	suite.updateStore.EXPECT().
+		GetJobUpdateEvents(gomock.Any(), suite.updateID).
+		Return(nil, fmt.Errorf("fake db error"))
+
+	suite.EqualError(promoteCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.goalStateDriver,
+	), "fake db error")
+}
+
+// TestProcessFailedCanary tests that the update is rolled back
+// when the canary fails, and that the canary result is recorded in
+// the state the canary failed in
+func (suite *UpdateCanaryTestSuite) TestProcessFailedCanary() {
+	suite.cachedUpdate.EXPECT().
+		GetWorkflowType().
+		Return(models.WorkflowType_UPDATE)
+	suite.cachedUpdate.EXPECT().
+		GetState().
+		Return(&cached.UpdateStateVector{
+			State: pbupdate.State_ROLLING_FORWARD,
+		})
+	suite.updateStore.EXPECT().
+		AddJobUpdateCanaryEvent(
+			gomock.Any(),
+			suite.updateID,
+			models.WorkflowType_UPDATE,
+			pbupdate.State_ROLLING_FORWARD,
+			pbupdate.CanaryState_CANARY_STATE_FAILED).
+		Return(nil)
+	suite.cachedJob.EXPECT().
+		RollbackWorkflow(gomock.Any()).
+		Return(nil)
+	suite.cachedJob.EXPECT().
+		GetConfig(gomock.Any()).
+		Return(&pbjob.JobConfig{InstanceCount: 5}, nil)
+	suite.cachedUpdate.EXPECT().
+		GetGoalState().
+		Return(&cached.UpdateStateVector{
+			Instances: []uint32{0, 1, 2, 3, 4},
+		})
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Return()
+
+	suite.NoError(processFailedCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.goalStateDriver,
+	))
+}
+
+// TestProcessFailedCanaryEventError tests that the update is not
+// rolled back if the canary event cannot be recorded
+func (suite *UpdateCanaryTestSuite) TestProcessFailedCanaryEventError() {
+	suite.cachedUpdate.EXPECT().
+		GetWorkflowType().
+		Return(models.WorkflowType_UPDATE)
+	suite.cachedUpdate.EXPECT().
+		GetState().
+		Return(&cached.UpdateStateVector{
+			State: pbupdate.State_ROLLING_FORWARD,
+		})
+	suite.updateStore.EXPECT().
+		AddJobUpdateCanaryEvent(
+			gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("fake db error"))
+
+	suite.EqualError(processFailedCanary(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		suite.goalStateDriver,
+	), "fake db error")
+}
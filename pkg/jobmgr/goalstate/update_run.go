@@ -30,6 +30,7 @@ import (
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
 	"github.com/uber/peloton/pkg/jobmgr/task"
+	updateutil "github.com/uber/peloton/pkg/jobmgr/util/update"
 
 	log "github.com/sirupsen/logrus"
 	"go.uber.org/yarpc/yarpcerrors"
@@ -100,9 +101,60 @@ func UpdateRun(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
-	instancesToAdd, instancesToUpdate, instancesToRemove :=
-		getInstancesForUpdateRun(
-			cachedWorkflow, instancesCurrent, instancesDone, instancesFailed)
+	updateConfig := cachedWorkflow.GetUpdateConfig()
+	phase, bakeDeadline, err := getCanaryPhase(
+		ctx,
+		cachedJob,
+		cachedWorkflow,
+		updateConfig,
+		instancesCurrent,
+		instancesDone,
+		instancesFailed,
+	)
+	if err != nil {
+		goalStateDriver.mtx.updateMetrics.UpdateRunFail.Inc(1)
+		return err
+	}
+
+	// canary instances failed, roll back the update and return directly
+	if phase == canaryFailed {
+		err := processFailedCanary(
+			ctx,
+			cachedJob,
+			cachedWorkflow,
+			goalStateDriver,
+		)
+		if err != nil {
+			goalStateDriver.mtx.updateMetrics.UpdateRunFail.Inc(1)
+		}
+		return err
+	}
+
+	if phase == canaryPromoted {
+		if err := promoteCanary(
+			ctx,
+			cachedJob,
+			cachedWorkflow,
+			goalStateDriver,
+		); err != nil {
+			goalStateDriver.mtx.updateMetrics.UpdateRunFail.Inc(1)
+			return err
+		}
+	}
+
+	// no new instance is updated while the canary instances are baking
+	var instancesToAdd, instancesToUpdate, instancesToRemove []uint32
+	if phase != canaryBaking {
+		instancesToAdd, instancesToUpdate, instancesToRemove =
+			getInstancesForUpdateRun(
+				cachedWorkflow,
+				getBatchSizeForUpdateRun(
+					updateConfig, phase, instancesDone, instancesFailed),
+				instancesCurrent,
+				instancesDone,
+				instancesFailed,
+			)
+	}
 
 	instancesToAdd, instancesToUpdate, instancesToRemove, instancesRemovedDone, err :=
 		confirmInstancesStatus(
@@ -161,6 +213,22 @@ func UpdateRun(ctx context.Context, entity goalstate.Entity) error {
 		return err
 	}
 
+	if err := enqueueUpdateAtReadinessDeadline(
+		ctx,
+		cachedJob,
+		cachedWorkflow,
+		instancesCurrent,
+		goalStateDriver); err != nil {
+		goalStateDriver.mtx.updateMetrics.UpdateRunFail.Inc(1)
+		return err
+	}
+
+	// evaluate the canary instances again once the bake time passes
+	if phase == canaryBaking {
+		goalStateDriver.EnqueueUpdate(
+			cachedJob.ID(), cachedWorkflow.ID(), bakeDeadline)
+	}
+
 	// TODO (varung):
 	// - Use len for instances current
 	// - Remove instances_added, instances_removed and instances_updated
@@ -196,43 +264,9 @@ func processFailedUpdate(
 	// the update itself is not a rollback
 	if cachedUpdate.GetUpdateConfig().RollbackOnFailure &&
 		!isUpdateRollback(cachedUpdate) {
-		if err := cachedJob.RollbackWorkflow(ctx); err != nil {
-			log.WithFields(log.Fields{
-				"update_id": cachedUpdate.ID().GetValue(),
-				"job_id":    cachedJob.ID().GetValue(),
-			}).WithError(err).
-				Info("fail to rollback update")
-			return err
-		}
-
-		cachedConfig, err := cachedJob.GetConfig(ctx)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"update_id": cachedUpdate.ID().GetValue(),
-				"job_id":    cachedJob.ID().GetValue(),
-			}).WithError(err).
-				Info("fail to get job config to rollback update")
+		if err := rollbackUpdate(ctx, cachedJob, cachedUpdate); err != nil {
 			return err
 		}
-
-		if err := handleUnchangedInstancesInUpdate(
-			ctx,
-			cachedUpdate,
-			cachedJob,
-			cachedConfig,
-		); err != nil {
-			log.WithFields(log.Fields{
-				"update_id": cachedUpdate.ID().GetValue(),
-				"job_id":    cachedJob.ID().GetValue(),
-			}).WithError(err).
-				Info("fail to update unchanged instances to rollback update")
-			return err
-		}
-
-		log.WithFields(log.Fields{
-			"update_id": cachedUpdate.ID().GetValue(),
-			"job_id":    cachedJob.ID().GetValue(),
-		}).Info("update rolling back")
 	} else {
 		if err := cachedUpdate.WriteProgress(
 			ctx,
@@ -249,6 +283,53 @@ func processFailedUpdate(
 	return nil
 }
 
+// rollbackUpdate rolls back the update to the previous job configuration
+func rollbackUpdate(
+	ctx context.Context,
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+) error {
+	if err := cachedJob.RollbackWorkflow(ctx); err != nil {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+		}).WithError(err).
+			Info("fail to rollback update")
+		return err
+	}
+
+	cachedConfig, err := cachedJob.GetConfig(ctx)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+		}).WithError(err).
+			Info("fail to get job config to rollback update")
+		return err
+	}
+
+	if err := handleUnchangedInstancesInUpdate(
+		ctx,
+		cachedUpdate,
+		cachedJob,
+		cachedConfig,
+	); err != nil {
+		log.WithFields(log.Fields{
+			"update_id": cachedUpdate.ID().GetValue(),
+			"job_id":    cachedJob.ID().GetValue(),
+		}).WithError(err).
+			Info("fail to update unchanged instances to rollback update")
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"update_id": cachedUpdate.ID().GetValue(),
+		"job_id":    cachedJob.ID().GetValue(),
+	}).Info("update rolling back")
+
+	return nil
+}
+
 // isUpdateRollback returns if an update is a rolling back to a
 // previous version
 func isUpdateRollback(cachedUpdate cached.Update) bool {
@@ -320,7 +401,51 @@ func postUpdateAction(
 func isTaskUpdateCompleted(cachedUpdate cached.Update, runtime *pbtask.RuntimeInfo) bool {
 	return runtime.GetState() == pbtask.TaskState_RUNNING &&
 		runtime.GetConfigVersion() == runtime.GetDesiredConfigVersion() &&
-		runtime.GetConfigVersion() == cachedUpdate.GetGoalState().JobVersion
+		runtime.GetConfigVersion() == cachedUpdate.GetGoalState().JobVersion &&
+		updateutil.IsTaskReady(runtime)
+}
+
+// enqueueUpdateAtReadinessDeadline enqueues the update at the earliest
+// readiness deadline of the instances being updated. Mesos only sends
+// task events when a check result changes, so without it an instance
+// which never becomes ready would block the update forever instead of
+// being counted as failed.
+func enqueueUpdateAtReadinessDeadline(
+	ctx context.Context,
+	cachedJob cached.Job,
+	cachedUpdate cached.Update,
+	instancesCurrent []uint32,
+	goalStateDriver Driver,
+) error {
+	timeoutSecs := cachedUpdate.GetUpdateConfig().GetReadinessTimeoutSecs()
+	if timeoutSecs == 0 {
+		return nil
+	}
+
+	var nextDeadline time.Time
+	for _, instanceID := range instancesCurrent {
+		cachedTask := cachedJob.GetTask(instanceID)
+		if cachedTask == nil {
+			continue
+		}
+		runtime, err := cachedTask.GetRuntime(ctx)
+		if err != nil {
+			return err
+		}
+		deadline, ok := updateutil.GetReadinessDeadline(runtime, timeoutSecs)
+		if !ok {
+			continue
+		}
+		if nextDeadline.IsZero() || deadline.Before(nextDeadline) {
+			nextDeadline = deadline
+		}
+	}
+
+	if !nextDeadline.IsZero() {
+		goalStateDriver.EnqueueUpdate(
+			cachedJob.ID(), cachedUpdate.ID(), nextDeadline)
+	}
+	return nil
 }
 
 // isTaskTerminated returns whether a task is terminated and would
@@ -740,9 +865,11 @@ func confirmInstancesStatus(
 }
 
 // getInstancesForUpdateRun returns the instances to update/add in
-// the given call of UpdateRun.
+// the given call of UpdateRun, with at most batchSize instances
+// being processed at the same time.
 func getInstancesForUpdateRun(
 	update cached.Update,
+	batchSize uint32,
 	instancesCurrent []uint32,
 	instancesDone []uint32,
 	instancesFailed []uint32,
@@ -756,15 +883,15 @@ func getInstancesForUpdateRun(
 		unprocessedInstancesToUpdate, unprocessedInstancesToRemove := getUnprocessedInstances(
 		update, instancesCurrent, instancesDone, instancesFailed)
 
-	// if batch size is 0 or updateConfig is nil, update all of the instances
-	if update.GetUpdateConfig().GetBatchSize() == 0 {
+	// if batch size is 0, update all of the instances
+	if batchSize == 0 {
 		return unprocessedInstancesToAdd,
 			unprocessedInstancesToUpdate,
 			unprocessedInstancesToRemove
 	}
 
 	maxNumOfInstancesToProcess :=
-		int(update.GetUpdateConfig().GetBatchSize()) - len(instancesCurrent)
+		int(batchSize) - len(instancesCurrent)
 	// if instances being updated are more than batch size, do not update anything
 	if maxNumOfInstancesToProcess <= 0 {
 		return nil, nil, nil
@@ -229,7 +229,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdate() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(3)
+		Times(4)
 
 	for _, instID := range instancesTotal {
 		suite.cachedJob.EXPECT().
@@ -258,7 +258,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdate() {
 		IsInstanceComplete(newJobConfigVer, runtimeRunning).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeRunning, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeRunning, updateConfig).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
 		IsInstanceInProgress(newJobConfigVer, runtimeRunning).
@@ -272,7 +272,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdate() {
 		IsInstanceComplete(newJobConfigVer, runtimeInitialized).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeInitialized, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeInitialized, updateConfig).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
 		IsInstanceInProgress(newJobConfigVer, runtimeInitialized).
@@ -282,7 +282,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdate() {
 		IsInstanceComplete(newJobConfigVer, runtimeNotReady).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeNotReady, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeNotReady, updateConfig).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
 		IsInstanceInProgress(newJobConfigVer, runtimeNotReady).
@@ -446,7 +446,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateWithStartTasksOn() {
 		IsInstanceComplete(newJobConfigVer, runtimeRunning).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeRunning, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeRunning, updateConfig).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
 		IsInstanceInProgress(newJobConfigVer, runtimeRunning).
@@ -456,7 +456,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateWithStartTasksOn() {
 		IsInstanceComplete(newJobConfigVer, runtimeKilled).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeKilled, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeKilled, updateConfig).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
 		IsInstanceInProgress(newJobConfigVer, runtimeKilled).
@@ -673,7 +673,7 @@ func (suite *UpdateRunTestSuite) TestRunningInPlaceUpdate() {
 		IsInstanceComplete(newJobConfigVer, runtimeRunning).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeRunning, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeRunning, updateConfig).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
 		IsInstanceInProgress(newJobConfigVer, runtimeRunning).
@@ -683,7 +683,7 @@ func (suite *UpdateRunTestSuite) TestRunningInPlaceUpdate() {
 		IsInstanceComplete(newJobConfigVer, runtimeRestarting).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeRestarting, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeRestarting, updateConfig).
 		Return(false)
 	suite.cachedUpdate.EXPECT().
 		IsInstanceInProgress(newJobConfigVer, runtimeRestarting).
@@ -844,7 +844,7 @@ func (suite *UpdateRunTestSuite) TestCompletedUpdate() {
 		Return(&pbupdate.UpdateConfig{
 			BatchSize: 0,
 		}).
-		Times(3)
+		Times(4)
 
 	suite.cachedJob.EXPECT().
 		ID().
@@ -1556,7 +1556,7 @@ func (suite *UpdateRunTestSuite) TestUpdateRunFullyRunningUpdateInstances() {
 			Return(false)
 
 		suite.cachedUpdate.EXPECT().
-			IsInstanceFailed(runtime, updateConfig.GetMaxInstanceAttempts()).
+			IsInstanceFailed(runtime, updateConfig).
 			Return(false)
 
 		suite.cachedUpdate.EXPECT().
@@ -2649,7 +2649,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateFailed() {
 		Return(false).
 		AnyTimes()
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeFailed, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeFailed, updateConfig).
 		Return(true).
 		AnyTimes()
 
@@ -2782,7 +2782,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateRolledBack() {
 		Return(false).
 		AnyTimes()
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeFailed, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeFailed, updateConfig).
 		Return(true).
 		AnyTimes()
 
@@ -2932,7 +2932,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdateRolledBackFail() {
 		Return(false).
 		AnyTimes()
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeFailed, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeFailed, updateConfig).
 		Return(true).
 		AnyTimes()
 
@@ -3060,7 +3060,7 @@ func (suite *UpdateRunTestSuite) TestUpdateRollingBackFailed() {
 		Return(false).
 		AnyTimes()
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeFailed, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeFailed, updateConfig).
 		Return(true).
 		AnyTimes()
 
@@ -3196,7 +3196,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdatePartialFailure() {
 	suite.cachedUpdate.EXPECT().
 		GetUpdateConfig().
 		Return(updateConfig).
-		Times(4)
+		Times(5)
 
 	for i, instID := range instancesTotal {
 		if uint32(i) < failedInstances {
@@ -3215,7 +3215,7 @@ func (suite *UpdateRunTestSuite) TestRunningUpdatePartialFailure() {
 		Return(false).
 		AnyTimes()
 	suite.cachedUpdate.EXPECT().
-		IsInstanceFailed(runtimeFailed, updateConfig.GetMaxInstanceAttempts()).
+		IsInstanceFailed(runtimeFailed, updateConfig).
 		Return(true).
 		AnyTimes()
 
@@ -3315,6 +3315,84 @@ func (suite *UpdateRunTestSuite) TestConfirmInstancesStatus() {
 	suite.Len(instancesDone, 1)
 }
 
+// TestEnqueueUpdateAtReadinessDeadline tests that the update is enqueued
+// at the earliest readiness deadline of the instances being updated
+func (suite *UpdateRunTestSuite) TestEnqueueUpdateAtReadinessDeadline() {
+	now := time.Now().UTC()
+	startTimes := map[uint32]time.Time{
+		0: now.Add(-30 * time.Second),
+		1: now.Add(-10 * time.Second),
+	}
+
+	suite.cachedUpdate.EXPECT().
+		GetUpdateConfig().
+		Return(&pbupdate.UpdateConfig{ReadinessTimeoutSecs: 60})
+	suite.cachedUpdate.EXPECT().
+		ID().
+		Return(suite.updateID).
+		AnyTimes()
+	suite.cachedJob.EXPECT().
+		ID().
+		Return(suite.jobID).
+		AnyTimes()
+
+	for instID, startTime := range startTimes {
+		suite.cachedJob.EXPECT().
+			GetTask(instID).
+			Return(suite.cachedTask)
+		suite.cachedTask.EXPECT().
+			GetRuntime(gomock.Any()).
+			Return(&pbtask.RuntimeInfo{
+				State:     pbtask.TaskState_RUNNING,
+				StartTime: startTime.Format(time.RFC3339Nano),
+				Readiness: pbtask.HealthState_HEALTH_UNKNOWN,
+			}, nil)
+	}
+
+	// instance 2 is ready and does not have a deadline
+	suite.cachedJob.EXPECT().
+		GetTask(uint32(2)).
+		Return(suite.cachedTask)
+	suite.cachedTask.EXPECT().
+		GetRuntime(gomock.Any()).
+		Return(&pbtask.RuntimeInfo{
+			State:     pbtask.TaskState_RUNNING,
+			StartTime: now.Add(-time.Hour).Format(time.RFC3339Nano),
+			Readiness: pbtask.HealthState_HEALTHY,
+		}, nil)
+
+	suite.updateGoalStateEngine.EXPECT().
+		Enqueue(gomock.Any(), gomock.Any()).
+		Do(func(entity goalstate.Entity, deadline time.Time) {
+			suite.Equal(suite.jobID.GetValue(), entity.GetID())
+			suite.True(startTimes[0].Add(60 * time.Second).Equal(deadline))
+		})
+
+	suite.NoError(enqueueUpdateAtReadinessDeadline(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		[]uint32{0, 1, 2},
+		suite.goalStateDriver,
+	))
+}
+
+// TestEnqueueUpdateAtReadinessDeadlineNoTimeout tests that nothing is
+// enqueued if the update does not have a readiness timeout
+func (suite *UpdateRunTestSuite) TestEnqueueUpdateAtReadinessDeadlineNoTimeout() {
+	suite.cachedUpdate.EXPECT().
+		GetUpdateConfig().
+		Return(&pbupdate.UpdateConfig{})
+
+	suite.NoError(enqueueUpdateAtReadinessDeadline(
+		context.Background(),
+		suite.cachedJob,
+		suite.cachedUpdate,
+		[]uint32{0, 1, 2},
+		suite.goalStateDriver,
+	))
+}
+
 func newSlice(start uint32, end uint32) []uint32 {
 	result := make([]uint32, 0, end-start)
 	for i := start; i < end; i++ {
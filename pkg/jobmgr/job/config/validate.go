@@ -54,6 +54,8 @@ var (
 		"Task preemption policy should be false for stateless job")
 	errIncorrectHealthCheck = yarpcerrors.InvalidArgumentErrorf(
 		"Batch job task should not set health check ")
+	errIncorrectReadinessCheck = yarpcerrors.InvalidArgumentErrorf(
+		"Batch job task should not set readiness check")
 	errIncorrectExecutor = yarpcerrors.InvalidArgumentErrorf(
 		"Batch job task should not include executor config")
 	errIncorrectExecutorType = yarpcerrors.InvalidArgumentErrorf(
@@ -314,6 +316,10 @@ func validateBatchTaskConfig(taskConfig *task.TaskConfig) error {
 	if taskConfig.GetHealthCheck() != nil {
 		return errIncorrectHealthCheck
 	}
+	// Readiness checks only gate rolling updates of stateless jobs
+	if taskConfig.GetReadinessCheck() != nil {
+		return errIncorrectReadinessCheck
+	}
 	// Batch jobs should not use custom executor (aurora thermos for now)
 	if taskConfig.GetExecutor() != nil {
 		return errIncorrectExecutor
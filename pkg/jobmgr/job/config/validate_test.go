@@ -567,6 +567,13 @@ func TestValidateBatchTaskConfig(t *testing.T) {
 		err := validateBatchTaskConfig(&taskConfig)
 		assert.Equal(t, err, errExp)
 	}
+
+	taskConfig := task.TaskConfig{
+		ReadinessCheck: &task.HealthCheckConfig{
+			Enabled: true,
+		},
+	}
+	assert.Equal(t, errIncorrectReadinessCheck, validateBatchTaskConfig(&taskConfig))
 }
 
 // TestValidateTaskConfigFailureBatchExecutorConfig tests validation of
@@ -25,6 +25,11 @@ type Config struct {
 
 	// Flag to enable handling peloton secrets
 	EnableSecrets bool `yaml:"enable_secrets"`
+
+	// Flag to query jobs from the job_index table and its secondary
+	// indexes, instead of the Lucene index which requires the
+	// Cassandra Lucene plugin
+	DisableLuceneQuery bool `yaml:"disable_lucene_query"`
 }
 
 func (c *Config) normalize() {
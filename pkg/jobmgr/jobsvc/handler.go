@@ -32,6 +32,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/keyring"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
@@ -60,7 +61,7 @@ var (
 		"resource pool")
 )
 
-// InitServiceHandler initializes the job manager
+// InitServiceHandler initializes the job manager and returns its handler
 func InitServiceHandler(
 	d *yarpc.Dispatcher,
 	parent tally.Scope,
@@ -71,15 +72,17 @@ func InitServiceHandler(
 	goalStateDriver goalstate.Driver,
 	candidate leader.Candidate,
 	clientName string,
-	jobSvcCfg Config) {
+	jobSvcCfg Config,
+	secretKeyring *keyring.Keyring) job.JobManagerYARPCServer {
 
 	jobSvcCfg.normalize()
 	handler := &serviceHandler{
 		jobStore:        jobStore,
 		taskStore:       taskStore,
 		jobIndexOps:     ormobjects.NewJobIndexOps(ormStore),
+		jobQueryOps:     ormobjects.NewJobQueryOps(ormStore),
 		jobConfigOps:    ormobjects.NewJobConfigOps(ormStore),
-		secretInfoOps:   ormobjects.NewSecretInfoOps(ormStore),
+		secretInfoOps:   ormobjects.NewSecretInfoOps(ormStore, secretKeyring),
 		respoolClient:   respool.NewResourceManagerYARPCClient(d.ClientConfig(clientName)),
 		resmgrClient:    resmgrsvc.NewResourceManagerServiceYARPCClient(d.ClientConfig(clientName)),
 		rootCtx:         context.Background(),
@@ -91,6 +94,38 @@ func InitServiceHandler(
 	}
 
 	d.Register(job.BuildJobManagerYARPCProcedures(handler))
+
+	// Jobs created before job_index_by_time table was added are
+	// indexed in the background, indexing a job again is a no-op.
+	if jobSvcCfg.DisableLuceneQuery {
+		go handler.backfillJobQueryIndex(handler.rootCtx)
+	}
+	return handler
+}
+
+// backfillJobQueryIndex indexes the jobs in all states for the job
+// queries which do not use the Lucene index
+func (h *serviceHandler) backfillJobQueryIndex(ctx context.Context) {
+	for value := range job.JobState_name {
+		state := job.JobState(value)
+		jobIDs, err := h.jobStore.GetJobsByStates(ctx, []job.JobState{state})
+		if err != nil {
+			log.WithError(err).
+				WithField("state", state.String()).
+				Error("Failed to get jobs to backfill job query index")
+			continue
+		}
+		if err := h.jobQueryOps.Backfill(ctx, jobIDs); err != nil {
+			log.WithError(err).
+				WithField("state", state.String()).
+				Error("Failed to backfill job query index")
+			continue
+		}
+		log.WithFields(log.Fields{
+			"state":    state.String(),
+			"num_jobs": len(jobIDs),
+		}).Info("Backfilled job query index")
+	}
 }
 
 // serviceHandler implements peloton.api.job.JobManager
@@ -98,6 +133,7 @@ type serviceHandler struct {
 	jobStore        storage.JobStore
 	taskStore       storage.TaskStore
 	jobIndexOps     ormobjects.JobIndexOps
+	jobQueryOps     ormobjects.JobQueryOps
 	jobConfigOps    ormobjects.JobConfigOps
 	secretInfoOps   ormobjects.SecretInfoOps
 	respoolClient   respool.ResourceManagerYARPCClient
@@ -471,7 +507,11 @@ func (h *serviceHandler) Query(ctx context.Context, req *job.QueryRequest) (*job
 	h.metrics.JobAPIQuery.Inc(1)
 	callStart := time.Now()
 
-	jobConfigs, jobSummary, total, err := h.jobStore.QueryJobs(ctx, req.GetRespoolID(), req.GetSpec(), req.GetSummaryOnly())
+	queryJobs := h.jobStore.QueryJobs
+	if h.jobSvcCfg.DisableLuceneQuery {
+		queryJobs = h.jobQueryOps.Query
+	}
+	jobConfigs, jobSummary, total, err := queryJobs(ctx, req.GetRespoolID(), req.GetSpec(), req.GetSummaryOnly())
 	if err != nil {
 		h.metrics.JobQueryFail.Inc(1)
 		log.WithError(err).Error("Query job failed with error")
@@ -1697,6 +1697,49 @@ func (suite *JobHandlerTestSuite) TestJobQueryFailure() {
 	suite.Equal(expectedErr, resp.GetError())
 }
 
+// TestJobQueryLuceneDisabled tests that Job Query API uses the job
+// query engine of the ORM store when lucene queries are disabled
+func (suite *JobHandlerTestSuite) TestJobQueryLuceneDisabled() {
+	mockedJobQueryOps := objectmocks.NewMockJobQueryOps(suite.ctrl)
+	suite.handler.jobQueryOps = mockedJobQueryOps
+	suite.handler.jobSvcCfg.DisableLuceneQuery = true
+
+	summaries := []*job.JobSummary{{Name: "test"}}
+	mockedJobQueryOps.EXPECT().Query(suite.context, nil, nil, false).
+		Return(nil, summaries, uint32(1), nil)
+	resp, err := suite.handler.Query(suite.context, &job.QueryRequest{})
+	suite.NoError(err)
+	suite.Nil(resp.GetError())
+	suite.Equal(summaries, resp.GetResults())
+	suite.Equal(uint32(1), resp.GetPagination().GetTotal())
+}
+
+// TestBackfillJobQueryIndex tests indexing the jobs in all states
+// for the job queries which do not use the Lucene index
+func (suite *JobHandlerTestSuite) TestBackfillJobQueryIndex() {
+	mockedJobQueryOps := objectmocks.NewMockJobQueryOps(suite.ctrl)
+	suite.handler.jobQueryOps = mockedJobQueryOps
+	jobIDs := []peloton.JobID{{Value: "my-job"}}
+
+	for value := range job.JobState_name {
+		state := job.JobState(value)
+		if state == job.JobState_FAILED {
+			suite.mockedJobStore.EXPECT().
+				GetJobsByStates(suite.context, []job.JobState{state}).
+				Return(nil, errors.New("get jobs failed"))
+			continue
+		}
+		suite.mockedJobStore.EXPECT().
+			GetJobsByStates(suite.context, []job.JobState{state}).
+			Return(jobIDs, nil)
+	}
+	mockedJobQueryOps.EXPECT().Backfill(suite.context, jobIDs).
+		Return(nil).
+		Times(len(job.JobState_name) - 1)
+
+	suite.handler.backfillJobQueryIndex(suite.context)
+}
+
 func (suite *JobHandlerTestSuite) TestJobDelete() {
 	id := &peloton.JobID{
 		Value: "my-job",
@@ -35,6 +35,7 @@ import (
 	"github.com/uber/peloton/pkg/common/concurrency"
 
 	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/common/keyring"
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/util"
 	versionutil "github.com/uber/peloton/pkg/common/util/entityversion"
@@ -63,6 +64,7 @@ type serviceHandler struct {
 	updateStore     storage.UpdateStore
 	taskStore       storage.TaskStore
 	jobIndexOps     ormobjects.JobIndexOps
+	jobQueryOps     ormobjects.JobQueryOps
 	jobConfigOps    ormobjects.JobConfigOps
 	jobNameToIDOps  ormobjects.JobNameToIDOps
 	secretInfoOps   ormobjects.SecretInfoOps
@@ -98,6 +100,7 @@ func InitV1AlphaJobServiceHandler(
 	goalStateDriver goalstate.Driver,
 	candidate leader.Candidate,
 	jobSvcCfg jobsvc.Config,
+	secretKeyring *keyring.Keyring,
 	activeRMTasks activermtask.ActiveRMTasks,
 ) {
 	handler := &serviceHandler{
@@ -105,9 +108,10 @@ func InitV1AlphaJobServiceHandler(
 		updateStore:    updateStore,
 		taskStore:      taskStore,
 		jobIndexOps:    ormobjects.NewJobIndexOps(ormStore),
+		jobQueryOps:    ormobjects.NewJobQueryOps(ormStore),
 		jobConfigOps:   ormobjects.NewJobConfigOps(ormStore),
 		jobNameToIDOps: ormobjects.NewJobNameToIDOps(ormStore),
-		secretInfoOps:  ormobjects.NewSecretInfoOps(ormStore),
+		secretInfoOps:  ormobjects.NewSecretInfoOps(ormStore, secretKeyring),
 		respoolClient: respool.NewResourceManagerYARPCClient(
 			d.ClientConfig(common.PelotonResourceManager),
 		),
@@ -1196,7 +1200,11 @@ func (h *serviceHandler) QueryJobs(
 	querySpec := handlerutil.ConvertStatelessQuerySpecToJobQuerySpec(req.GetSpec())
 	log.WithField("spec", querySpec).Debug("converted spec")
 
-	_, jobSummaries, total, err := h.jobStore.QueryJobs(
+	queryJobs := h.jobStore.QueryJobs
+	if h.jobSvcCfg.DisableLuceneQuery {
+		queryJobs = h.jobQueryOps.Query
+	}
+	_, jobSummaries, total, err := queryJobs(
 		ctx,
 		respoolID,
 		querySpec,
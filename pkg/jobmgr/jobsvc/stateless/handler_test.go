@@ -1002,6 +1002,48 @@ func (suite *statelessHandlerTestSuite) TestQueryJobsSuccess() {
 	suite.NoError(err)
 }
 
+// TestQueryJobsLuceneDisabled tests that query jobs uses the job query
+// engine of the ORM store when lucene queries are disabled
+func (suite *statelessHandlerTestSuite) TestQueryJobsLuceneDisabled() {
+	jobQueryOps := objectmocks.NewMockJobQueryOps(suite.ctrl)
+	suite.handler.jobQueryOps = jobQueryOps
+	suite.handler.jobSvcCfg.DisableLuceneQuery = true
+
+	spec := &stateless.QuerySpec{
+		Pagination: &v1alphaquery.PaginationSpec{
+			Limit:    10,
+			MaxLimit: 100,
+		},
+		Owner: "owner1",
+	}
+	jobSummary := &pbjob.JobSummary{
+		Name:  "test",
+		Owner: "owner1",
+		Runtime: &pbjob.RuntimeInfo{
+			State: pbjob.JobState_SUCCEEDED,
+		},
+	}
+
+	jobQueryOps.EXPECT().
+		Query(gomock.Any(), nil, gomock.Any(), true).
+		Return(nil, []*pbjob.JobSummary{jobSummary}, uint32(1), nil)
+
+	resp, err := suite.handler.QueryJobs(
+		context.Background(),
+		&statelesssvc.QueryJobsRequest{
+			Spec: spec,
+		},
+	)
+	suite.NoError(err)
+	suite.Len(resp.GetRecords(), 1)
+	suite.Equal(jobSummary.GetOwner(), resp.GetRecords()[0].GetOwner())
+	suite.Equal(
+		stateless.JobState_JOB_STATE_SUCCEEDED,
+		resp.GetRecords()[0].GetStatus().GetState(),
+	)
+	suite.Equal(uint32(1), resp.GetPagination().GetTotal())
+}
+
 // TestQueryJobsGetRespoolIDFail tests the failure case of query jobs
 // due to get respool id
 func (suite *statelessHandlerTestSuite) TestQueryJobsGetRespoolIdFail() {
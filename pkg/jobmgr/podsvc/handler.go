@@ -26,6 +26,7 @@ import (
 	pbpod "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
 	"github.com/uber/peloton/pkg/common/leader"
 	"github.com/uber/peloton/pkg/common/util"
@@ -61,6 +62,7 @@ type serviceHandler struct {
 	logManager         logmanager.LogManager
 	mesosAgentWorkDir  string
 	hostMgrClient      hostsvc.InternalHostServiceYARPCClient
+	resmgrClient       resmgrsvc.ResourceManagerServiceYARPCClient
 }
 
 // InitV1AlphaPodServiceHandler initializes the Pod Service Handler
@@ -75,6 +77,7 @@ func InitV1AlphaPodServiceHandler(
 	logManager logmanager.LogManager,
 	mesosAgentWorkDir string,
 	hostMgrClient hostsvc.InternalHostServiceYARPCClient,
+	resmgrClient resmgrsvc.ResourceManagerServiceYARPCClient,
 ) {
 	handler := &serviceHandler{
 		jobStore:           jobStore,
@@ -86,6 +89,7 @@ func InitV1AlphaPodServiceHandler(
 		logManager:         logManager,
 		mesosAgentWorkDir:  mesosAgentWorkDir,
 		hostMgrClient:      hostMgrClient,
+		resmgrClient:       resmgrClient,
 	}
 	d.Register(svc.BuildPodServiceYARPCProcedures(handler))
 }
@@ -360,6 +364,7 @@ func (h *serviceHandler) RestartPod(
 	runtimeDiff := make(map[uint32]jobmgrcommon.RuntimeDiff)
 	runtimeDiff[instanceID] = jobmgrcommon.RuntimeDiff{
 		jobmgrcommon.DesiredMesosTaskIDField: newPodID,
+		jobmgrcommon.AvoidHostField:          req.GetAvoidHost(),
 	}
 	err = cachedJob.PatchTasks(ctx, runtimeDiff)
 
@@ -579,6 +584,59 @@ func (h *serviceHandler) BrowsePodSandbox(
 	return resp, nil
 }
 
+func (h *serviceHandler) GetPodPendingReason(
+	ctx context.Context,
+	req *svc.GetPodPendingReasonRequest,
+) (resp *svc.GetPodPendingReasonResponse, err error) {
+	defer func() {
+		if err != nil {
+			log.WithField("request", req).
+				WithError(err).
+				Warn("PodSVC.GetPodPendingReason failed")
+			err = handlerutil.ConvertToYARPCError(err)
+			return
+		}
+
+		log.WithField("request", req).
+			WithField("response", resp).
+			Debug("PodSVC.GetPodPendingReason succeeded")
+	}()
+
+	podName := req.GetPodName().GetValue()
+	if _, _, err := util.ParseTaskID(podName); err != nil {
+		return nil, err
+	}
+
+	resmgrResp, err := h.resmgrClient.GetPendingReason(
+		ctx,
+		&resmgrsvc.GetPendingReasonRequest{
+			TaskIDs: []*v0peloton.TaskID{{Value: podName}},
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get pending reason from resmgr")
+	}
+
+	if len(resmgrResp.GetReasons()) == 0 {
+		return nil,
+			yarpcerrors.NotFoundErrorf("pod not found in resource manager")
+	}
+
+	reason := resmgrResp.GetReasons()[0]
+	return &svc.GetPodPendingReasonResponse{
+		Reason: &pbpod.PendingReason{
+			State:            handlerutil.ConvertTaskStateToPodState(reason.GetState()),
+			RespoolPath:      reason.GetRespoolPath(),
+			Queue:            reason.GetQueue(),
+			AdmissionCheck:   reason.GetAdmissionCheck(),
+			AdmissionMessage: reason.GetAdmissionMessage(),
+			AdmissionTime:    reason.GetAdmissionTime(),
+			PlacementMessage: reason.GetPlacementMessage(),
+			PlacementTime:    reason.GetPlacementTime(),
+			Message:          reason.GetMessage(),
+		},
+	}, nil
+}
+
 func (h *serviceHandler) RefreshPod(
 	ctx context.Context,
 	req *svc.RefreshPodRequest,
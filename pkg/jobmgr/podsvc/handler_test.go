@@ -31,6 +31,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	hostmocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
 	"github.com/uber/peloton/.gen/peloton/private/models"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+	resmocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
 
 	leadermocks "github.com/uber/peloton/pkg/common/leader/mocks"
 	"github.com/uber/peloton/pkg/common/util"
@@ -69,6 +71,7 @@ type podHandlerTestSuite struct {
 	goalStateDriver    *goalstatemocks.MockDriver
 	frameworkInfoStore *storemocks.MockFrameworkInfoStore
 	hostmgrClient      *hostmocks.MockInternalHostServiceYARPCClient
+	resmgrClient       *resmocks.MockResourceManagerServiceYARPCClient
 	logmanager         *logmanagermocks.MockLogManager
 	mesosAgentWorkDir  string
 }
@@ -83,6 +86,7 @@ func (suite *podHandlerTestSuite) SetupTest() {
 	suite.goalStateDriver = goalstatemocks.NewMockDriver(suite.ctrl)
 	suite.frameworkInfoStore = storemocks.NewMockFrameworkInfoStore(suite.ctrl)
 	suite.hostmgrClient = hostmocks.NewMockInternalHostServiceYARPCClient(suite.ctrl)
+	suite.resmgrClient = resmocks.NewMockResourceManagerServiceYARPCClient(suite.ctrl)
 	suite.logmanager = logmanagermocks.NewMockLogManager(suite.ctrl)
 	suite.mesosAgentWorkDir = "test"
 	suite.handler = &serviceHandler{
@@ -92,6 +96,7 @@ func (suite *podHandlerTestSuite) SetupTest() {
 		goalStateDriver:    suite.goalStateDriver,
 		frameworkInfoStore: suite.frameworkInfoStore,
 		hostMgrClient:      suite.hostmgrClient,
+		resmgrClient:       suite.resmgrClient,
 		logManager:         suite.logmanager,
 		mesosAgentWorkDir:  suite.mesosAgentWorkDir,
 	}
@@ -1026,6 +1031,7 @@ func (suite *podHandlerTestSuite) TestRestartPodSuccess() {
 	runtimeDiff[uint32(testInstanceID)] = jobmgrcommon.RuntimeDiff{
 		jobmgrcommon.DesiredMesosTaskIDField: util.CreateMesosTaskID(
 			jobID, uint32(testInstanceID), uint64(testRunID)+1),
+		jobmgrcommon.AvoidHostField: "host-1",
 	}
 
 	suite.cachedJob.EXPECT().
@@ -1055,7 +1061,8 @@ func (suite *podHandlerTestSuite) TestRestartPodSuccess() {
 	)
 
 	request := &svc.RestartPodRequest{
-		PodName: &v1alphapeloton.PodName{Value: testPodName},
+		PodName:   &v1alphapeloton.PodName{Value: testPodName},
+		AvoidHost: "host-1",
 	}
 	response, err := suite.handler.RestartPod(context.Background(), request)
 	suite.NoError(err)
@@ -1144,6 +1151,7 @@ func (suite *podHandlerTestSuite) TestRestartPodPatchTasksFailure() {
 	runtimeDiff[uint32(testInstanceID)] = jobmgrcommon.RuntimeDiff{
 		jobmgrcommon.DesiredMesosTaskIDField: util.CreateMesosTaskID(
 			jobID, uint32(testInstanceID), 1),
+		jobmgrcommon.AvoidHostField: "",
 	}
 
 	suite.cachedJob.EXPECT().
@@ -1985,3 +1993,78 @@ func (suite *podHandlerTestSuite) TestDeletePodEventsStoreError() {
 func TestPodServiceHandler(t *testing.T) {
 	suite.Run(t, new(podHandlerTestSuite))
 }
+
+// TestGetPodPendingReason tests getting the pending reason of a pod
+func (suite *podHandlerTestSuite) TestGetPodPendingReason() {
+	suite.resmgrClient.EXPECT().
+		GetPendingReason(gomock.Any(), &resmgrsvc.GetPendingReasonRequest{
+			TaskIDs: []*peloton.TaskID{{Value: testPodName}},
+		}).
+		Return(&resmgrsvc.GetPendingReasonResponse{
+			Reasons: []*resmgrsvc.GetPendingReasonResponse_PendingReason{
+				{
+					TaskID:           &peloton.TaskID{Value: testPodName},
+					State:            pbtask.TaskState_PENDING,
+					RespoolPath:      "/respool1",
+					Queue:            "pending",
+					AdmissionCheck:   "entitlement",
+					AdmissionMessage: "allocation exceeds entitlement",
+					Message:          "waiting for admission",
+				},
+			},
+		}, nil)
+
+	resp, err := suite.handler.GetPodPendingReason(
+		context.Background(),
+		&svc.GetPodPendingReasonRequest{
+			PodName: &v1alphapeloton.PodName{Value: testPodName},
+		})
+	suite.NoError(err)
+	reason := resp.GetReason()
+	suite.Equal(pod.PodState_POD_STATE_PENDING, reason.GetState())
+	suite.Equal("/respool1", reason.GetRespoolPath())
+	suite.Equal("pending", reason.GetQueue())
+	suite.Equal("entitlement", reason.GetAdmissionCheck())
+	suite.Equal("allocation exceeds entitlement", reason.GetAdmissionMessage())
+	suite.Equal("waiting for admission", reason.GetMessage())
+}
+
+// TestGetPodPendingReasonNotFound tests getting the pending reason of a pod
+// which is not known to the resource manager
+func (suite *podHandlerTestSuite) TestGetPodPendingReasonNotFound() {
+	suite.resmgrClient.EXPECT().
+		GetPendingReason(gomock.Any(), gomock.Any()).
+		Return(&resmgrsvc.GetPendingReasonResponse{
+			NotFound: []*peloton.TaskID{{Value: testPodName}},
+		}, nil)
+
+	_, err := suite.handler.GetPodPendingReason(
+		context.Background(),
+		&svc.GetPodPendingReasonRequest{
+			PodName: &v1alphapeloton.PodName{Value: testPodName},
+		})
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestGetPodPendingReasonFailure tests the failure cases of getting the
+// pending reason of a pod
+func (suite *podHandlerTestSuite) TestGetPodPendingReasonFailure() {
+	// invalid pod name
+	_, err := suite.handler.GetPodPendingReason(
+		context.Background(),
+		&svc.GetPodPendingReasonRequest{
+			PodName: &v1alphapeloton.PodName{Value: "invalid"},
+		})
+	suite.Error(err)
+
+	suite.resmgrClient.EXPECT().
+		GetPendingReason(gomock.Any(), gomock.Any()).
+		Return(nil, yarpcerrors.UnavailableErrorf("test error"))
+
+	_, err = suite.handler.GetPodPendingReason(
+		context.Background(),
+		&svc.GetPodPendingReasonRequest{
+			PodName: &v1alphapeloton.PodName{Value: testPodName},
+		})
+	suite.Error(err)
+}
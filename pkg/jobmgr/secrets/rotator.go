@@ -0,0 +1,158 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package secrets rotates the master key which wraps the data keys of the
+// job secrets.
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/pkg/common/keyring"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/storage"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// Rotator wraps the data keys of the job secrets with the current master
+// key of the keyring, and encrypts the secrets stored unencrypted.
+// Secrets are rotated online: the master keys of previous versions must
+// stay in the keyring of the job manager until the rotation is complete,
+// so that the secrets can be decrypted at launch time in the meantime.
+// A secret updated by a job update while it is rotated is not overwritten
+// by the rotation, as the update already wraps it with the current key.
+type Rotator struct {
+	jobStore      storage.JobStore
+	jobIndexOps   ormobjects.JobIndexOps
+	secretInfoOps ormobjects.SecretInfoOps
+	keyring       *keyring.Keyring
+}
+
+// Result of a rotation.
+type Result struct {
+	// Number of secrets wrapped by the current master key by the rotation
+	Rotated int
+	// Number of secrets which failed to be rotated
+	Failed int
+}
+
+// NewRotator creates a Rotator.
+func NewRotator(
+	jobStore storage.JobStore,
+	ormStore *ormobjects.Store,
+	k *keyring.Keyring) *Rotator {
+	return &Rotator{
+		jobStore:      jobStore,
+		jobIndexOps:   ormobjects.NewJobIndexOps(ormStore),
+		secretInfoOps: ormobjects.NewSecretInfoOps(ormStore, k),
+		keyring:       k,
+	}
+}
+
+// Rotate rotates the secrets wrapped by the master keys of previous
+// versions, and the unencrypted secrets of all the jobs. A secret
+// which fails to be rotated does not stop the rotation of the others,
+// and is rotated again by the next rotation.
+func (r *Rotator) Rotate(ctx context.Context) (*Result, error) {
+	var secretIDs []string
+	for _, version := range r.keyring.Versions() {
+		if version == r.keyring.CurrentVersion() {
+			continue
+		}
+		ids, err := r.secretInfoOps.GetSecretIDsByKeyVersion(ctx, version)
+		if err != nil {
+			return nil, errors.Wrapf(err,
+				"failed to get secrets of key version %d", version)
+		}
+		secretIDs = append(secretIDs, ids...)
+	}
+
+	// Secrets stored unencrypted are not indexed by key version,
+	// look them up from the config of all the jobs, including the
+	// terminated ones which can be restarted.
+	ids, err := r.getJobSecretIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	secretIDs = append(secretIDs, ids...)
+
+	result := &Result{}
+	for _, secretID := range secretIDs {
+		rotated, err := r.secretInfoOps.RotateSecret(ctx, secretID)
+		// every ORM connector returns gocql.ErrNotFound for a missing row
+		if err == gocql.ErrNotFound {
+			continue
+		}
+		if yarpcerrors.IsAborted(err) {
+			// the secret was updated concurrently with the
+			// current master key
+			log.WithField("secret_id", secretID).
+				Info("secret updated while rotated")
+			continue
+		}
+		if err != nil {
+			log.WithError(err).
+				WithField("secret_id", secretID).
+				Error("failed to rotate secret")
+			result.Failed++
+			continue
+		}
+		if rotated {
+			result.Rotated++
+		}
+	}
+	return result, nil
+}
+
+// getJobSecretIDs returns the IDs of the secrets of all the jobs in the
+// job index.
+func (r *Rotator) getJobSecretIDs(ctx context.Context) ([]string, error) {
+	jobSummaries, err := r.jobStore.GetAllJobsInJobIndex(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get jobs")
+	}
+
+	var secretIDs []string
+	for _, jobSummary := range jobSummaries {
+		jobID := jobSummary.GetId()
+		jobIndexObj, err := r.jobIndexOps.Get(ctx, jobID)
+		if err == gocql.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err,
+				"failed to get job %s", jobID.GetValue())
+		}
+
+		var config job.JobConfig
+		if err := json.Unmarshal([]byte(jobIndexObj.Config), &config); err != nil {
+			return nil, errors.Wrapf(err,
+				"failed to unmarshal config of job %s", jobID.GetValue())
+		}
+		for _, volume := range config.GetDefaultConfig().GetContainer().GetVolumes() {
+			if util.IsSecretVolume(volume) {
+				secretIDs = append(secretIDs,
+					string(volume.GetSource().GetSecret().GetValue().GetData()))
+			}
+		}
+	}
+	return secretIDs, nil
+}
@@ -0,0 +1,190 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	mesos "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/common/keyring"
+	"github.com/uber/peloton/pkg/common/util"
+	storemocks "github.com/uber/peloton/pkg/storage/mocks"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type rotatorTestSuite struct {
+	suite.Suite
+
+	ctx           context.Context
+	ctrl          *gomock.Controller
+	jobStore      *storemocks.MockJobStore
+	jobIndexOps   *objectmocks.MockJobIndexOps
+	secretInfoOps *objectmocks.MockSecretInfoOps
+	rotator       *Rotator
+}
+
+func TestRotator(t *testing.T) {
+	suite.Run(t, new(rotatorTestSuite))
+}
+
+func (suite *rotatorTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.jobStore = storemocks.NewMockJobStore(suite.ctrl)
+	suite.jobIndexOps = objectmocks.NewMockJobIndexOps(suite.ctrl)
+	suite.secretInfoOps = objectmocks.NewMockSecretInfoOps(suite.ctrl)
+
+	k, err := keyring.New(3, map[uint32][]byte{
+		1: bytes.Repeat([]byte{1}, 32),
+		2: bytes.Repeat([]byte{2}, 32),
+		3: bytes.Repeat([]byte{3}, 32),
+	})
+	suite.NoError(err)
+
+	suite.rotator = &Rotator{
+		jobStore:      suite.jobStore,
+		jobIndexOps:   suite.jobIndexOps,
+		secretInfoOps: suite.secretInfoOps,
+		keyring:       k,
+	}
+}
+
+func (suite *rotatorTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+// newJobIndexObject creates the job_index row of a job with secrets
+func (suite *rotatorTestSuite) newJobIndexObject(
+	secretIDs ...string) *ormobjects.JobIndexObject {
+	mesosContainerizer := mesos.ContainerInfo_MESOS
+	config := &job.JobConfig{
+		DefaultConfig: &task.TaskConfig{
+			Container: &mesos.ContainerInfo{
+				Type: &mesosContainerizer,
+			},
+		},
+	}
+	for _, secretID := range secretIDs {
+		config.DefaultConfig.Container.Volumes = append(
+			config.DefaultConfig.Container.Volumes,
+			util.CreateSecretVolume("/tmp/secret", secretID))
+	}
+	buffer, err := json.Marshal(config)
+	suite.NoError(err)
+	return &ormobjects.JobIndexObject{Config: string(buffer)}
+}
+
+// TestRotate tests rotating the secrets of previous key versions and
+// the unencrypted secrets of all the jobs
+func (suite *rotatorTestSuite) TestRotate() {
+	jobID1 := &peloton.JobID{Value: "job1"}
+	jobID2 := &peloton.JobID{Value: "job2"}
+	jobID3 := &peloton.JobID{Value: "job3"}
+
+	suite.secretInfoOps.EXPECT().
+		GetSecretIDsByKeyVersion(suite.ctx, uint32(1)).
+		Return([]string{"secret1"}, nil)
+	suite.secretInfoOps.EXPECT().
+		GetSecretIDsByKeyVersion(suite.ctx, uint32(2)).
+		Return([]string{"secret2", "deleted"}, nil)
+	suite.jobStore.EXPECT().
+		GetAllJobsInJobIndex(suite.ctx).
+		Return([]*job.JobSummary{
+			{Id: jobID1},
+			{Id: jobID2},
+			{Id: jobID3},
+		}, nil)
+	suite.jobIndexOps.EXPECT().
+		Get(suite.ctx, jobID1).
+		Return(suite.newJobIndexObject("plain1", "plain2"), nil)
+	suite.jobIndexOps.EXPECT().
+		Get(suite.ctx, jobID2).
+		Return(suite.newJobIndexObject("updated"), nil)
+	suite.jobIndexOps.EXPECT().
+		Get(suite.ctx, jobID3).
+		Return(nil, gocql.ErrNotFound)
+
+	suite.secretInfoOps.EXPECT().
+		RotateSecret(suite.ctx, "secret1").
+		Return(true, nil)
+	suite.secretInfoOps.EXPECT().
+		RotateSecret(suite.ctx, "secret2").
+		Return(false, errors.New("DB error"))
+	suite.secretInfoOps.EXPECT().
+		RotateSecret(suite.ctx, "deleted").
+		Return(false, gocql.ErrNotFound)
+	suite.secretInfoOps.EXPECT().
+		RotateSecret(suite.ctx, "plain1").
+		Return(true, nil)
+	suite.secretInfoOps.EXPECT().
+		RotateSecret(suite.ctx, "plain2").
+		Return(false, nil)
+	suite.secretInfoOps.EXPECT().
+		RotateSecret(suite.ctx, "updated").
+		Return(false, yarpcerrors.AbortedErrorf("update conditions not met"))
+
+	result, err := suite.rotator.Rotate(suite.ctx)
+	suite.NoError(err)
+	suite.Equal(&Result{Rotated: 2, Failed: 1}, result)
+}
+
+// TestRotateGetSecretIDsFailure tests the failure to get the secrets
+// of a previous key version
+func (suite *rotatorTestSuite) TestRotateGetSecretIDsFailure() {
+	suite.secretInfoOps.EXPECT().
+		GetSecretIDsByKeyVersion(suite.ctx, uint32(1)).
+		Return(nil, errors.New("DB error"))
+
+	_, err := suite.rotator.Rotate(suite.ctx)
+	suite.Error(err)
+}
+
+// TestRotateGetJobsFailure tests the failures to get the secrets
+// of the jobs
+func (suite *rotatorTestSuite) TestRotateGetJobsFailure() {
+	jobID := &peloton.JobID{Value: "job1"}
+	suite.secretInfoOps.EXPECT().
+		GetSecretIDsByKeyVersion(suite.ctx, gomock.Any()).
+		Return(nil, nil).
+		Times(4)
+
+	suite.jobStore.EXPECT().
+		GetAllJobsInJobIndex(suite.ctx).
+		Return(nil, errors.New("DB error"))
+	_, err := suite.rotator.Rotate(suite.ctx)
+	suite.Error(err)
+
+	suite.jobStore.EXPECT().
+		GetAllJobsInJobIndex(suite.ctx).
+		Return([]*job.JobSummary{{Id: jobID}}, nil)
+	suite.jobIndexOps.EXPECT().
+		Get(suite.ctx, jobID).
+		Return(nil, errors.New("DB error"))
+	_, err = suite.rotator.Rotate(suite.ctx)
+	suite.Error(err)
+}
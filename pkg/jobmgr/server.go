@@ -114,6 +114,7 @@ func (s *Server) LostLeadershipCallback() error {
 	s.goalstateDriver.Stop()
 	s.jobFactory.Stop()
 	s.watchProcessor.StopTaskClients()
+	s.watchProcessor.StopJobClients()
 
 	return nil
 }
@@ -131,6 +132,7 @@ func (s *Server) ShutDownCallback() error {
 	s.goalstateDriver.Stop()
 	s.jobFactory.Stop()
 	s.watchProcessor.StopTaskClients()
+	s.watchProcessor.StopJobClients()
 
 	return nil
 }
@@ -35,6 +35,9 @@ type Metrics struct {
 	TasksHealthyTotal   tally.Counter
 	TasksUnHealthyTotal tally.Counter
 
+	TasksReadyTotal    tally.Counter
+	TasksNotReadyTotal tally.Counter
+
 	TasksReconciledTotal tally.Counter
 
 	// metrics for in-place update/restart success rate
@@ -61,6 +64,9 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		TasksHealthyTotal:   scope.Counter("tasks_healthy_total"),
 		TasksUnHealthyTotal: scope.Counter("tasks_unhealthy_total"),
 
+		TasksReadyTotal:    scope.Counter("tasks_ready_total"),
+		TasksNotReadyTotal: scope.Counter("tasks_not_ready_total"),
+
 		TasksInPlacePlacementTotal:   scope.Counter("tasks_in_place_placement_total"),
 		TasksInPlacePlacementSuccess: scope.Counter("tasks_in_place_placement_success"),
 
@@ -203,6 +203,15 @@ func (p *statusUpdate) ProcessStatusUpdate(ctx context.Context, event *pb_events
 		p.persistHealthyField(updateEvent.state, reason, healthy, runtimeDiff)
 	}
 
+	// Persist readiness field if readiness check is enabled
+	if taskInfo.GetConfig().GetReadinessCheck().GetEnabled() {
+		p.persistReadinessField(
+			updateEvent.state,
+			taskInfo.GetRuntime(),
+			event.GetMesosTaskStatus(),
+			runtimeDiff)
+	}
+
 	// Update FailureCount
 	updateFailureCount(updateEvent.state, taskInfo.GetRuntime(), runtimeDiff)
 
@@ -320,6 +329,9 @@ func (p *statusUpdate) ProcessStatusUpdate(ctx context.Context, event *pb_events
 			// when task starts running, there is no need to keep desired host field around.
 			// it would be set again upon in-place update using the current running host.
 			runtimeDiff[jobmgrcommon.DesiredHostField] = ""
+			// the task has been moved away from the host to avoid, so it
+			// may be placed on that host again when it restarts.
+			runtimeDiff[jobmgrcommon.AvoidHostField] = ""
 		}
 
 	} else if util.IsPelotonStateTerminal(runtimeDiff[jobmgrcommon.StateField].(pb_task.TaskState)) {
@@ -609,6 +621,57 @@ func (p *statusUpdate) persistHealthyField(
 	}
 }
 
+// persistReadinessField updates the readiness field in runtimeDiff
+func (p *statusUpdate) persistReadinessField(
+	state pb_task.TaskState,
+	runtime *pb_task.RuntimeInfo,
+	status *mesos_v1.TaskStatus,
+	runtimeDiff map[string]interface{}) {
+
+	switch {
+	case util.IsPelotonStateTerminal(state):
+		// Set readiness to INVALID for all terminal state
+		runtimeDiff[jobmgrcommon.ReadinessField] = pb_task.HealthState_INVALID
+	case state == pb_task.TaskState_RUNNING:
+		if status.GetReason() == mesos_v1.TaskStatus_REASON_TASK_CHECK_STATUS_UPDATED {
+			readiness := getReadinessState(status.GetCheckStatus())
+			runtimeDiff[jobmgrcommon.ReadinessField] = readiness
+			if readiness == pb_task.HealthState_HEALTHY {
+				p.metrics.TasksReadyTotal.Inc(1)
+			} else {
+				p.metrics.TasksNotReadyTotal.Inc(1)
+			}
+		} else if runtime.GetReadiness() == pb_task.HealthState_INVALID ||
+			runtime.GetReadiness() == pb_task.HealthState_DISABLED {
+			// The task just started running, and is not ready
+			// until the first readiness check passes
+			runtimeDiff[jobmgrcommon.ReadinessField] = pb_task.HealthState_HEALTH_UNKNOWN
+		}
+	}
+}
+
+// getReadinessState returns the readiness of a task from the result
+// of its mesos check. Checks which have not completed yet are not ready.
+func getReadinessState(checkStatus *mesos_v1.CheckStatusInfo) pb_task.HealthState {
+	var ready bool
+	switch checkStatus.GetType() {
+	case mesos_v1.CheckInfo_COMMAND:
+		command := checkStatus.GetCommand()
+		ready = command != nil && command.ExitCode != nil &&
+			command.GetExitCode() == 0
+	case mesos_v1.CheckInfo_HTTP:
+		statusCode := checkStatus.GetHttp().GetStatusCode()
+		ready = statusCode >= 200 && statusCode < 400
+	case mesos_v1.CheckInfo_TCP:
+		ready = checkStatus.GetTcp().GetSucceeded()
+	}
+
+	if ready {
+		return pb_task.HealthState_HEALTHY
+	}
+	return pb_task.HealthState_UNHEALTHY
+}
+
 func updateFailureCount(
 	eventState pb_task.TaskState,
 	runtime *pb_task.RuntimeInfo,
@@ -649,6 +712,7 @@ func updateFailureCount(
 // 2. State is the same, that state is running, and health check is not configured.
 // 3. State is the same, that state is running, and the update is not due to health check result.
 // 4. State is the same, that state is running, the update is due to health check result and the task is healthy.
+// 5. State is the same, that state is running, the update is due to readiness check result and readiness is unchanged.
 //
 // Each unhealthy state needs to be logged into the pod events table.
 func isDuplicateStateUpdate(
@@ -668,6 +732,20 @@ func isDuplicateStateUpdate(
 		return true
 	}
 
+	if taskInfo.GetConfig().GetReadinessCheck().GetEnabled() &&
+		event.GetMesosTaskStatus().GetReason() ==
+			mesos_v1.TaskStatus_REASON_TASK_CHECK_STATUS_UPDATED {
+		readiness := getReadinessState(event.GetMesosTaskStatus().GetCheckStatus())
+		if readiness == taskInfo.GetRuntime().GetReadiness() {
+			log.WithFields(log.Fields{
+				"db_task_runtime":   taskInfo.GetRuntime(),
+				"task_status_event": event.GetMesosTaskStatus(),
+			}).Debug("skip same status update if readiness is unchanged")
+			return true
+		}
+		return false
+	}
+
 	if taskInfo.GetConfig().GetHealthCheck() == nil ||
 		!taskInfo.GetConfig().GetHealthCheck().GetEnabled() {
 		log.WithFields(log.Fields{
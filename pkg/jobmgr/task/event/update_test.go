@@ -60,6 +60,7 @@ var (
 	_mesosTaskID       = fmt.Sprintf("%s-%d-%s", _jobID, _instanceID, _uuidStr)
 	_mesosReason       = mesos.TaskStatus_REASON_COMMAND_EXECUTOR_FAILED
 	_healthCheckReason = mesos.TaskStatus_REASON_TASK_HEALTH_CHECK_STATUS_UPDATED
+	_checkReason       = mesos.TaskStatus_REASON_TASK_CHECK_STATUS_UPDATED
 	_pelotonTaskID     = fmt.Sprintf("%s-%d", _jobID, _instanceID)
 	_pelotonJobID      = &peloton.JobID{
 		Value: _jobID,
@@ -185,6 +186,29 @@ func createTestTaskUpdateHealthCheckEvent(
 	return event
 }
 
+func createTestTaskUpdateCheckEvent(
+	state mesos.TaskState, exitCode int32) *pb_eventstream.Event {
+	checkType := mesos.CheckInfo_COMMAND
+	taskStatus := &mesos.TaskStatus{
+		TaskId: &mesos.TaskID{
+			Value: &_mesosTaskID,
+		},
+		State:  &state,
+		Reason: &_checkReason,
+		CheckStatus: &mesos.CheckStatusInfo{
+			Type: &checkType,
+			Command: &mesos.CheckStatusInfo_Command{
+				ExitCode: &exitCode,
+			},
+		},
+	}
+	event := &pb_eventstream.Event{
+		MesosTaskStatus: taskStatus,
+		Type:            pb_eventstream.Event_MESOS_TASK_STATUS,
+	}
+	return event
+}
+
 func createTestTaskInfo(state task.TaskState) *task.TaskInfo {
 	taskInfo := &task.TaskInfo{
 		Runtime: &task.RuntimeInfo{
@@ -252,6 +276,7 @@ func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdate() {
 		jobmgrcommon.StartTimeField:      _currentTime,
 		jobmgrcommon.ReasonField:         "",
 		jobmgrcommon.DesiredHostField:    "",
+		jobmgrcommon.AvoidHostField:      "",
 	}
 	runtimeDiffs := make(map[uint32]jobmgrcommon.RuntimeDiff)
 	runtimeDiffs[_instanceID] = runtimeDiff
@@ -302,6 +327,7 @@ func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateInPlaceUpdateTask() {
 		jobmgrcommon.StartTimeField:      _currentTime,
 		jobmgrcommon.ReasonField:         "",
 		jobmgrcommon.DesiredHostField:    "",
+		jobmgrcommon.AvoidHostField:      "",
 	}
 	runtimeDiffs := make(map[uint32]jobmgrcommon.RuntimeDiff)
 	runtimeDiffs[_instanceID] = runtimeDiff
@@ -345,6 +371,7 @@ func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateInPlaceUpdateTask() {
 		jobmgrcommon.StartTimeField:      _currentTime,
 		jobmgrcommon.ReasonField:         "",
 		jobmgrcommon.DesiredHostField:    "",
+		jobmgrcommon.AvoidHostField:      "",
 	}
 	runtimeDiffs = make(map[uint32]jobmgrcommon.RuntimeDiff)
 	runtimeDiffs[_instanceID] = runtimeDiff
@@ -513,6 +540,197 @@ func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateHealthy() {
 	}
 }
 
+// Test processing status updates of a task with readiness check configured
+func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateReadiness() {
+	defer suite.ctrl.Finish()
+
+	tt := []struct {
+		previousState     task.TaskState
+		previousReadiness task.HealthState
+		event             *pb_eventstream.Event
+		readiness         task.HealthState
+
+		readyCounter    int64
+		notReadyCounter int64
+
+		msg string
+	}{
+		{
+			previousState:     task.TaskState_STARTING,
+			previousReadiness: task.HealthState_INVALID,
+			event:             createTestTaskUpdateEvent(mesos.TaskState_TASK_RUNNING),
+			readiness:         task.HealthState_HEALTH_UNKNOWN,
+
+			msg: "Before TASK STARTING, After TASK RUNNING + READINESS UNKNOWN",
+		},
+		{
+			previousState:     task.TaskState_RUNNING,
+			previousReadiness: task.HealthState_HEALTH_UNKNOWN,
+			event:             createTestTaskUpdateCheckEvent(mesos.TaskState_TASK_RUNNING, 0),
+			readiness:         task.HealthState_HEALTHY,
+			readyCounter:      1,
+
+			msg: "Before TASK RUNNING + READINESS UNKNOWN, " +
+				"After TASK RUNNING + READY",
+		},
+		{
+			previousState:     task.TaskState_RUNNING,
+			previousReadiness: task.HealthState_HEALTHY,
+			event:             createTestTaskUpdateCheckEvent(mesos.TaskState_TASK_RUNNING, 1),
+			readiness:         task.HealthState_UNHEALTHY,
+			readyCounter:      1,
+			notReadyCounter:   1,
+
+			msg: "Before TASK RUNNING + READY, " +
+				"After TASK RUNNING + NOT READY",
+		},
+		{
+			previousState:     task.TaskState_RUNNING,
+			previousReadiness: task.HealthState_HEALTHY,
+			event:             createTestTaskUpdateEvent(mesos.TaskState_TASK_FAILED),
+			readiness:         task.HealthState_INVALID,
+			readyCounter:      1,
+			notReadyCounter:   1,
+
+			msg: "Before TASK RUNNING + READY, After TASK FAILED",
+		},
+	}
+
+	for _, t := range tt {
+		cachedJob := cachedmocks.NewMockJob(suite.ctrl)
+
+		taskInfo := createTestTaskInfo(t.previousState)
+		taskInfo.Runtime.Readiness = t.previousReadiness
+		taskInfo.Config.ReadinessCheck = &task.HealthCheckConfig{
+			Enabled: true,
+			Type:    task.HealthCheckConfig_COMMAND,
+			CommandCheck: &task.HealthCheckConfig_CommandCheck{
+				Command: "ready.sh",
+			},
+		}
+
+		event := t.event
+		timeNow := float64(time.Now().UnixNano())
+		event.MesosTaskStatus.Timestamp = &timeNow
+
+		gomock.InOrder(
+			suite.mockTaskStore.EXPECT().
+				GetTaskByID(context.Background(), _pelotonTaskID).
+				Return(taskInfo, nil),
+			suite.jobFactory.EXPECT().AddJob(_pelotonJobID).Return(cachedJob),
+			cachedJob.EXPECT().SetTaskUpdateTime(event.MesosTaskStatus.Timestamp).Return(),
+			cachedJob.EXPECT().PatchTasks(gomock.Any(), gomock.Any()).Do(
+				func(_ context.Context, runtimeDiffs map[uint32]jobmgrcommon.RuntimeDiff) {
+					for _, runtimeDiff := range runtimeDiffs {
+						suite.Equal(t.readiness, runtimeDiff[jobmgrcommon.ReadinessField], t.msg)
+					}
+				}).Return(nil),
+		)
+		suite.goalStateDriver.EXPECT().
+			EnqueueTask(_pelotonJobID, _instanceID, gomock.Any()).
+			Return()
+		cachedJob.EXPECT().GetJobType().Return(job.JobType_SERVICE).AnyTimes()
+		suite.goalStateDriver.EXPECT().
+			JobRuntimeDuration(job.JobType_SERVICE).
+			Return(1 * time.Second).
+			AnyTimes()
+		suite.goalStateDriver.EXPECT().
+			EnqueueJob(_pelotonJobID, gomock.Any()).
+			Return().
+			AnyTimes()
+		cachedJob.EXPECT().UpdateResourceUsage(gomock.Any()).Return().AnyTimes()
+
+		suite.NoError(suite.updater.ProcessStatusUpdate(context.Background(), event))
+		suite.Equal(
+			t.readyCounter,
+			suite.testScope.Snapshot().Counters()["status_updater.tasks_ready_total+"].Value(),
+			t.msg)
+		suite.Equal(
+			t.notReadyCounter,
+			suite.testScope.Snapshot().Counters()["status_updater.tasks_not_ready_total+"].Value(),
+			t.msg)
+	}
+}
+
+// Test that readiness check results are skipped if readiness is unchanged
+func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateSkipSameReadiness() {
+	defer suite.ctrl.Finish()
+
+	taskInfo := createTestTaskInfo(task.TaskState_RUNNING)
+	taskInfo.Runtime.Readiness = task.HealthState_HEALTHY
+	taskInfo.Config.ReadinessCheck = &task.HealthCheckConfig{
+		Enabled: true,
+	}
+
+	suite.mockTaskStore.EXPECT().
+		GetTaskByID(context.Background(), _pelotonTaskID).
+		Return(taskInfo, nil)
+
+	event := createTestTaskUpdateCheckEvent(mesos.TaskState_TASK_RUNNING, 0)
+	suite.NoError(suite.updater.ProcessStatusUpdate(context.Background(), event))
+}
+
+// TestGetReadinessState tests converting mesos check results to readiness
+func (suite *TaskUpdaterTestSuite) TestGetReadinessState() {
+	commandType := mesos.CheckInfo_COMMAND
+	httpType := mesos.CheckInfo_HTTP
+	tcpType := mesos.CheckInfo_TCP
+	exitCode := int32(0)
+	statusCode := uint32(200)
+	badStatusCode := uint32(503)
+	succeeded := true
+
+	tt := []struct {
+		checkStatus *mesos.CheckStatusInfo
+		readiness   task.HealthState
+	}{
+		{
+			checkStatus: nil,
+			readiness:   task.HealthState_UNHEALTHY,
+		},
+		{
+			// command check has not completed yet
+			checkStatus: &mesos.CheckStatusInfo{
+				Type:    &commandType,
+				Command: &mesos.CheckStatusInfo_Command{},
+			},
+			readiness: task.HealthState_UNHEALTHY,
+		},
+		{
+			checkStatus: &mesos.CheckStatusInfo{
+				Type:    &commandType,
+				Command: &mesos.CheckStatusInfo_Command{ExitCode: &exitCode},
+			},
+			readiness: task.HealthState_HEALTHY,
+		},
+		{
+			checkStatus: &mesos.CheckStatusInfo{
+				Type: &httpType,
+				Http: &mesos.CheckStatusInfo_Http{StatusCode: &statusCode},
+			},
+			readiness: task.HealthState_HEALTHY,
+		},
+		{
+			checkStatus: &mesos.CheckStatusInfo{
+				Type: &httpType,
+				Http: &mesos.CheckStatusInfo_Http{StatusCode: &badStatusCode},
+			},
+			readiness: task.HealthState_UNHEALTHY,
+		},
+		{
+			checkStatus: &mesos.CheckStatusInfo{
+				Type: &tcpType,
+				Tcp:  &mesos.CheckStatusInfo_Tcp{Succeeded: &succeeded},
+			},
+			readiness: task.HealthState_HEALTHY,
+		},
+	}
+
+	for i, t := range tt {
+		suite.Equal(t.readiness, getReadinessState(t.checkStatus), "test %d fails", i)
+	}
+}
+
 // Test processing health check configured, configured but enabled or not
 func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateSkipSameStateWithHealthy() {
 	defer suite.ctrl.Finish()
@@ -1178,6 +1396,7 @@ func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateVolumeUponRunning() {
 		jobmgrcommon.CompletionTimeField: "",
 		jobmgrcommon.ReasonField:         "",
 		jobmgrcommon.DesiredHostField:    "",
+		jobmgrcommon.AvoidHostField:      "",
 	}
 	runtimeDiffs := make(map[uint32]jobmgrcommon.RuntimeDiff)
 	runtimeDiffs[_instanceID] = runtimeDiff
@@ -1236,6 +1455,7 @@ func (suite *TaskUpdaterTestSuite) TestProcessStatusUpdateSkipVolumeUponRunningI
 		jobmgrcommon.CompletionTimeField: "",
 		jobmgrcommon.ReasonField:         "",
 		jobmgrcommon.DesiredHostField:    "",
+		jobmgrcommon.AvoidHostField:      "",
 	}
 	runtimeDiffs := make(map[uint32]jobmgrcommon.RuntimeDiff)
 	runtimeDiffs[_instanceID] = runtimeDiff
@@ -34,6 +34,7 @@ import (
 
 	"github.com/uber/peloton/pkg/common"
 	"github.com/uber/peloton/pkg/common/backoff"
+	"github.com/uber/peloton/pkg/common/keyring"
 	"github.com/uber/peloton/pkg/common/util"
 	"github.com/uber/peloton/pkg/jobmgr/cached"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
@@ -133,6 +134,7 @@ type launcher struct {
 	taskStore     storage.TaskStore
 	volumeStore   storage.PersistentVolumeStore
 	secretInfoOps ormobjects.SecretInfoOps
+	keyring       *keyring.Keyring
 	metrics       *Metrics
 	retryPolicy   backoff.RetryPolicy
 }
@@ -148,6 +150,7 @@ const (
 var (
 	errEmptyTasks         = errors.New("empty tasks infos")
 	errLaunchInvalidOffer = errors.New("invalid offer to launch tasks")
+	errNoKeyring          = errors.New("no keyring to decrypt secrets with")
 )
 
 var taskLauncher *launcher
@@ -161,6 +164,7 @@ func InitTaskLauncher(
 	taskStore storage.TaskStore,
 	volumeStore storage.PersistentVolumeStore,
 	ormStore *ormobjects.Store,
+	secretKeyring *keyring.Keyring,
 	parent tally.Scope,
 ) {
 	onceInitTaskLauncher.Do(func() {
@@ -174,7 +178,8 @@ func InitTaskLauncher(
 			jobFactory:    jobFactory,
 			taskStore:     taskStore,
 			volumeStore:   volumeStore,
-			secretInfoOps: ormobjects.NewSecretInfoOps(ormStore),
+			secretInfoOps: ormobjects.NewSecretInfoOps(ormStore, secretKeyring),
+			keyring:       secretKeyring,
 			metrics:       NewMetrics(parent.SubScope("jobmgr").SubScope("task")),
 			// TODO: make launch retry policy config.
 			retryPolicy: backoff.NewRetryPolicy(3, 15*time.Second),
@@ -693,7 +698,12 @@ func (l *launcher) populateSecrets(
 				l.metrics.TaskPopulateSecretFail.Inc(1)
 				return err
 			}
-			secretStr, err := base64.StdEncoding.DecodeString(secretInfoObj.Data)
+			secretData, err := l.decryptSecret(secretInfoObj)
+			if err != nil {
+				l.metrics.TaskPopulateSecretFail.Inc(1)
+				return err
+			}
+			secretStr, err := base64.StdEncoding.DecodeString(secretData)
 			if err != nil {
 				l.metrics.TaskPopulateSecretFail.Inc(1)
 				return err
@@ -705,6 +715,29 @@ func (l *launcher) populateSecrets(
 	return nil
 }
 
+// decryptSecret returns the secret data of the secret object, decrypted
+// with the keyring unless the secret was stored unencrypted.
+func (l *launcher) decryptSecret(
+	secretInfoObj *ormobjects.SecretInfoObject) (string, error) {
+	if secretInfoObj.KeyVersion == 0 {
+		return secretInfoObj.Data, nil
+	}
+	if l.keyring == nil {
+		return "", errNoKeyring
+	}
+
+	envelope, err := secretInfoObj.ToEnvelope()
+	if err != nil {
+		return "", err
+	}
+	data, err := l.keyring.Decrypt(secretInfoObj.SecretID, envelope)
+	if err != nil {
+		return "", errors.Wrapf(err,
+			"failed to decrypt secret %s", secretInfoObj.SecretID)
+	}
+	return string(data), nil
+}
+
 // populateExecutorData transforms executor data in TaskConfig to data
 // usable by actual custom executor. Currently, it only supports aurora
 // thermos executor, in which case, it will pack the existing executor
@@ -15,6 +15,7 @@
 package launcher
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
@@ -43,6 +44,7 @@ import (
 	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
 
 	"github.com/uber/peloton/pkg/common/backoff"
+	"github.com/uber/peloton/pkg/common/keyring"
 	"github.com/uber/peloton/pkg/common/util"
 	cachedmocks "github.com/uber/peloton/pkg/jobmgr/cached/mocks"
 	jobmgrcommon "github.com/uber/peloton/pkg/jobmgr/common"
@@ -809,6 +811,56 @@ func (suite *LauncherTestSuite) TestCreateLaunchableTasks() {
 	suite.Equal(len(skippedTaskInfos), 1)
 }
 
+// TestPopulateSecretsEncrypted tests that populateSecrets decrypts the
+// secrets encrypted with the keyring
+func (suite *LauncherTestSuite) TestPopulateSecretsEncrypted() {
+	secretKeyring, err := keyring.New(1, map[uint32][]byte{
+		1: bytes.Repeat([]byte{1}, 32),
+	})
+	suite.NoError(err)
+	envelope, err := secretKeyring.Encrypt(
+		"secret-id",
+		[]byte(base64.StdEncoding.EncodeToString([]byte(testSecretStr))))
+	suite.NoError(err)
+	secretInfoObject := &objects.SecretInfoObject{
+		SecretID:   "secret-id",
+		JobID:      _testJobID,
+		Valid:      true,
+		Path:       testSecretPath,
+		KeyVersion: 1,
+		DataKey:    base64.StdEncoding.EncodeToString(envelope.DataKey),
+		Data:       base64.StdEncoding.EncodeToString(envelope.Ciphertext),
+	}
+
+	mesosContainerizer := mesos.ContainerInfo_MESOS
+	newTaskConfig := func() *task.TaskConfig {
+		return &task.TaskConfig{
+			Container: &mesos.ContainerInfo{
+				Type: &mesosContainerizer,
+				Volumes: []*mesos.Volume{
+					util.CreateSecretVolume(testSecretPath, "secret-id"),
+				},
+			},
+		}
+	}
+
+	suite.secretInfoOps.EXPECT().
+		GetSecret(gomock.Any(), "secret-id").
+		Return(secretInfoObject, nil).
+		Times(2)
+
+	// the secret cannot be decrypted without the keyring
+	err = suite.taskLauncher.populateSecrets(context.Background(), newTaskConfig())
+	suite.Equal(errNoKeyring, err)
+
+	suite.taskLauncher.keyring = secretKeyring
+	taskConfig := newTaskConfig()
+	err = suite.taskLauncher.populateSecrets(context.Background(), taskConfig)
+	suite.NoError(err)
+	suite.Equal([]byte(testSecretStr), taskConfig.GetContainer().GetVolumes()[0].
+		GetSource().GetSecret().GetValue().GetData())
+}
+
 // TestPopulateExecutorData tests populateExecutorData function to properly
 // fill out executor data in the launchable task, with the placement info
 // passed in.
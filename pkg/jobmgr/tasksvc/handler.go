@@ -60,7 +60,7 @@ var (
 	errEmptyFrameworkID = errors.New("framework id is empty")
 )
 
-// InitServiceHandler initializes the TaskManager
+// InitServiceHandler initializes the TaskManager and returns its handler
 func InitServiceHandler(
 	d *yarpc.Dispatcher,
 	parent tally.Scope,
@@ -74,7 +74,7 @@ func InitServiceHandler(
 	mesosAgentWorkDir string,
 	hostMgrClientName string,
 	logManager logmanager.LogManager,
-	activeRMTasks activermtask.ActiveRMTasks) {
+	activeRMTasks activermtask.ActiveRMTasks) task.TaskManagerYARPCServer {
 
 	handler := &serviceHandler{
 		taskStore:          taskStore,
@@ -93,6 +93,7 @@ func InitServiceHandler(
 		activeRMTasks:      activeRMTasks,
 	}
 	d.Register(task.BuildTaskManagerYARPCProcedures(handler))
+	return handler
 }
 
 // serviceHandler implements peloton.api.task.TaskManager
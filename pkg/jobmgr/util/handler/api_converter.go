@@ -147,6 +147,9 @@ func ConvertTaskRuntimeToPodStatus(runtime *task.RuntimeInfo) *pod.PodStatus {
 				Healthy: &pod.HealthStatus{
 					State: pod.HealthState(runtime.GetHealthy()),
 				},
+				Ready: &pod.HealthStatus{
+					State: pod.HealthState(runtime.GetReadiness()),
+				},
 				StartTime:      runtime.GetStartTime(),
 				CompletionTime: runtime.GetCompletionTime(),
 				Message:        runtime.GetMessage(),
@@ -194,6 +197,11 @@ func ConvertTaskConfigToPodSpec(taskConfig *task.TaskConfig, jobID string, insta
 		result.Constraint = ConvertTaskConstraintsToPodConstraints([]*task.Constraint{taskConfig.GetConstraint()})[0]
 	}
 
+	if len(taskConfig.GetPlacementPreferences()) != 0 {
+		result.PlacementPreferences = convertPlacementPreferencesToPod(
+			taskConfig.GetPlacementPreferences())
+	}
+
 	if taskConfig.GetVolume() != nil {
 		result.Volume = &pod.PersistentVolumeSpec{
 			ContainerPath: taskConfig.GetVolume().GetContainerPath(),
@@ -249,29 +257,13 @@ func ConvertTaskConfigToPodSpec(taskConfig *task.TaskConfig, jobID string, insta
 	}
 
 	if taskConfig.GetHealthCheck() != nil {
-		container.LivenessCheck = &pod.HealthCheckSpec{
-			Enabled:                taskConfig.GetHealthCheck().GetEnabled(),
-			InitialIntervalSecs:    taskConfig.GetHealthCheck().GetInitialIntervalSecs(),
-			IntervalSecs:           taskConfig.GetHealthCheck().GetIntervalSecs(),
-			MaxConsecutiveFailures: taskConfig.GetHealthCheck().GetMaxConsecutiveFailures(),
-			TimeoutSecs:            taskConfig.GetHealthCheck().GetTimeoutSecs(),
-			Type:                   pod.HealthCheckSpec_HealthCheckType(taskConfig.GetHealthCheck().GetType()),
-		}
-
-		if taskConfig.GetHealthCheck().GetCommandCheck() != nil {
-			container.LivenessCheck.CommandCheck = &pod.HealthCheckSpec_CommandCheck{
-				Command:             taskConfig.GetHealthCheck().GetCommandCheck().GetCommand(),
-				UnshareEnvironments: taskConfig.GetHealthCheck().GetCommandCheck().GetUnshareEnvironments(),
-			}
-		}
+		container.LivenessCheck = convertHealthCheckConfigToHealthCheckSpec(
+			taskConfig.GetHealthCheck())
+	}
 
-		if taskConfig.GetHealthCheck().GetHttpCheck() != nil {
-			container.LivenessCheck.HttpCheck = &pod.HealthCheckSpec_HTTPCheck{
-				Scheme: taskConfig.GetHealthCheck().GetHttpCheck().GetScheme(),
-				Port:   taskConfig.GetHealthCheck().GetHttpCheck().GetPort(),
-				Path:   taskConfig.GetHealthCheck().GetHttpCheck().GetPath(),
-			}
-		}
+	if taskConfig.GetReadinessCheck() != nil {
+		container.ReadinessCheck = convertHealthCheckConfigToHealthCheckSpec(
+			taskConfig.GetReadinessCheck())
 	}
 
 	if !reflect.DeepEqual(*container, pod.ContainerSpec{}) {
@@ -281,6 +273,38 @@ func ConvertTaskConfigToPodSpec(taskConfig *task.TaskConfig, jobID string, insta
 	return result
 }
 
+// convertHealthCheckConfigToHealthCheckSpec converts
+// v0 task.HealthCheckConfig to v1alpha pod.HealthCheckSpec
+func convertHealthCheckConfigToHealthCheckSpec(
+	healthCheck *task.HealthCheckConfig,
+) *pod.HealthCheckSpec {
+	result := &pod.HealthCheckSpec{
+		Enabled:                healthCheck.GetEnabled(),
+		InitialIntervalSecs:    healthCheck.GetInitialIntervalSecs(),
+		IntervalSecs:           healthCheck.GetIntervalSecs(),
+		MaxConsecutiveFailures: healthCheck.GetMaxConsecutiveFailures(),
+		TimeoutSecs:            healthCheck.GetTimeoutSecs(),
+		Type:                   pod.HealthCheckSpec_HealthCheckType(healthCheck.GetType()),
+	}
+
+	if healthCheck.GetCommandCheck() != nil {
+		result.CommandCheck = &pod.HealthCheckSpec_CommandCheck{
+			Command:             healthCheck.GetCommandCheck().GetCommand(),
+			UnshareEnvironments: healthCheck.GetCommandCheck().GetUnshareEnvironments(),
+		}
+	}
+
+	if healthCheck.GetHttpCheck() != nil {
+		result.HttpCheck = &pod.HealthCheckSpec_HTTPCheck{
+			Scheme: healthCheck.GetHttpCheck().GetScheme(),
+			Port:   healthCheck.GetHttpCheck().GetPort(),
+			Path:   healthCheck.GetHttpCheck().GetPath(),
+		}
+	}
+
+	return result
+}
+
 // ConvertLabels converts v0 peloton.Label array to
 // v1alpha peloton.Label array
 func ConvertLabels(labels []*peloton.Label) []*v1alphapeloton.Label {
@@ -334,11 +358,48 @@ func ConvertTaskConstraintsToPodConstraints(constraints []*task.Constraint) []*p
 			}
 		}
 
+		if constraint.GetSpreadConstraint() != nil {
+			podConstraint.SpreadConstraint = &pod.SpreadConstraint{
+				HostAttribute: constraint.GetSpreadConstraint().GetHostAttribute(),
+				MaxSkew:       constraint.GetSpreadConstraint().GetMaxSkew(),
+			}
+		}
+
 		podConstraints = append(podConstraints, podConstraint)
 	}
 	return podConstraints
 }
 
+// convertPlacementPreferencesToPod converts v0 task.PlacementPreference
+// array to v1alpha pod.PlacementPreference array
+func convertPlacementPreferencesToPod(
+	preferences []*task.PlacementPreference,
+) []*pod.PlacementPreference {
+	var result []*pod.PlacementPreference
+	for _, preference := range preferences {
+		podPreference := &pod.PlacementPreference{
+			Kind:   pod.PlacementPreference_Kind(preference.GetKind()),
+			Weight: preference.GetWeight(),
+		}
+
+		if preference.GetLabel() != nil {
+			podPreference.Label = &v1alphapeloton.Label{
+				Key:   preference.GetLabel().GetKey(),
+				Value: preference.GetLabel().GetValue(),
+			}
+		}
+
+		if preference.GetJobId() != nil {
+			podPreference.JobId = &v1alphapeloton.JobID{
+				Value: preference.GetJobId().GetValue(),
+			}
+		}
+
+		result = append(result, podPreference)
+	}
+	return result
+}
+
 // ConvertPortConfigsToPortSpecs converts v0 task.PortConfig array to
 // v1alpha pod.PortSpec array
 func ConvertPortConfigsToPortSpecs(ports []*task.PortConfig) []*pod.PortSpec {
@@ -554,6 +615,8 @@ func ConvertUpdateModelToWorkflowInfo(
 			MaxTolerableInstanceFailures: updateInfo.GetUpdateConfig().GetMaxFailureInstances(),
 			StartPaused:                  updateInfo.GetUpdateConfig().GetStartPaused(),
 			InPlace:                      updateInfo.GetUpdateConfig().GetInPlace(),
+			ReadinessTimeoutSecs:         updateInfo.GetUpdateConfig().GetReadinessTimeoutSecs(),
+			Canary:                       convertCanaryConfigToCanarySpec(updateInfo.GetUpdateConfig().GetCanary()),
 		}
 	} else if updateInfo.GetType() == models.WorkflowType_RESTART {
 		result.RestartSpec = &stateless.RestartSpec{
@@ -751,31 +814,13 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 	}
 
 	if mainContainer.GetLivenessCheck() != nil {
-		healthCheck := &task.HealthCheckConfig{
-			Enabled:                mainContainer.GetLivenessCheck().GetEnabled(),
-			InitialIntervalSecs:    mainContainer.GetLivenessCheck().GetInitialIntervalSecs(),
-			IntervalSecs:           mainContainer.GetLivenessCheck().GetIntervalSecs(),
-			MaxConsecutiveFailures: mainContainer.GetLivenessCheck().GetMaxConsecutiveFailures(),
-			TimeoutSecs:            mainContainer.GetLivenessCheck().GetTimeoutSecs(),
-			Type:                   task.HealthCheckConfig_Type(mainContainer.GetLivenessCheck().GetType()),
-		}
-
-		if mainContainer.GetLivenessCheck().GetCommandCheck() != nil {
-			healthCheck.CommandCheck = &task.HealthCheckConfig_CommandCheck{
-				Command:             mainContainer.GetLivenessCheck().GetCommandCheck().GetCommand(),
-				UnshareEnvironments: mainContainer.GetLivenessCheck().GetCommandCheck().GetUnshareEnvironments(),
-			}
-		}
-
-		if mainContainer.GetLivenessCheck().GetHttpCheck() != nil {
-			healthCheck.HttpCheck = &task.HealthCheckConfig_HTTPCheck{
-				Scheme: mainContainer.GetLivenessCheck().GetHttpCheck().GetScheme(),
-				Port:   mainContainer.GetLivenessCheck().GetHttpCheck().GetPort(),
-				Path:   mainContainer.GetLivenessCheck().GetHttpCheck().GetPath(),
-			}
-		}
+		result.HealthCheck = convertHealthCheckSpecToHealthCheckConfig(
+			mainContainer.GetLivenessCheck())
+	}
 
-		result.HealthCheck = healthCheck
+	if mainContainer.GetReadinessCheck() != nil {
+		result.ReadinessCheck = convertHealthCheckSpecToHealthCheckConfig(
+			mainContainer.GetReadinessCheck())
 	}
 
 	if len(mainContainer.GetPorts()) != 0 {
@@ -796,6 +841,11 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 		)[0]
 	}
 
+	if len(spec.GetPlacementPreferences()) != 0 {
+		result.PlacementPreferences = convertPlacementPreferencesToTask(
+			spec.GetPlacementPreferences())
+	}
+
 	if spec.GetRestartPolicy() != nil {
 		result.RestartPolicy = &task.RestartPolicy{
 			MaxFailures: spec.GetRestartPolicy().GetMaxFailures(),
@@ -823,6 +873,36 @@ func ConvertPodSpecToTaskConfig(spec *pod.PodSpec) (*task.TaskConfig, error) {
 	return result, nil
 }
 
+// convertPlacementPreferencesToTask converts v1alpha pod.PlacementPreference
+// array to v0 task.PlacementPreference array
+func convertPlacementPreferencesToTask(
+	preferences []*pod.PlacementPreference,
+) []*task.PlacementPreference {
+	var result []*task.PlacementPreference
+	for _, preference := range preferences {
+		taskPreference := &task.PlacementPreference{
+			Kind:   task.PlacementPreference_Kind(preference.GetKind()),
+			Weight: preference.GetWeight(),
+		}
+
+		if preference.GetLabel() != nil {
+			taskPreference.Label = &peloton.Label{
+				Key:   preference.GetLabel().GetKey(),
+				Value: preference.GetLabel().GetValue(),
+			}
+		}
+
+		if preference.GetJobId() != nil {
+			taskPreference.JobId = &peloton.JobID{
+				Value: preference.GetJobId().GetValue(),
+			}
+		}
+
+		result = append(result, taskPreference)
+	}
+	return result
+}
+
 // ConvertPodConstraintsToTaskConstraints converts pod constraints to task constraints
 func ConvertPodConstraintsToTaskConstraints(
 	constraints []*pod.Constraint,
@@ -866,22 +946,89 @@ func ConvertPodConstraintsToTaskConstraints(
 			}
 		}
 
+		if podConstraint.GetSpreadConstraint() != nil {
+			taskConstraint.SpreadConstraint = &task.SpreadConstraint{
+				HostAttribute: podConstraint.GetSpreadConstraint().GetHostAttribute(),
+				MaxSkew:       podConstraint.GetSpreadConstraint().GetMaxSkew(),
+			}
+		}
+
 		result = append(result, taskConstraint)
 	}
 
 	return result
 }
 
+// convertHealthCheckSpecToHealthCheckConfig converts
+// v1alpha pod.HealthCheckSpec to v0 task.HealthCheckConfig
+func convertHealthCheckSpecToHealthCheckConfig(
+	healthCheck *pod.HealthCheckSpec,
+) *task.HealthCheckConfig {
+	result := &task.HealthCheckConfig{
+		Enabled:                healthCheck.GetEnabled(),
+		InitialIntervalSecs:    healthCheck.GetInitialIntervalSecs(),
+		IntervalSecs:           healthCheck.GetIntervalSecs(),
+		MaxConsecutiveFailures: healthCheck.GetMaxConsecutiveFailures(),
+		TimeoutSecs:            healthCheck.GetTimeoutSecs(),
+		Type:                   task.HealthCheckConfig_Type(healthCheck.GetType()),
+	}
+
+	if healthCheck.GetCommandCheck() != nil {
+		result.CommandCheck = &task.HealthCheckConfig_CommandCheck{
+			Command:             healthCheck.GetCommandCheck().GetCommand(),
+			UnshareEnvironments: healthCheck.GetCommandCheck().GetUnshareEnvironments(),
+		}
+	}
+
+	if healthCheck.GetHttpCheck() != nil {
+		result.HttpCheck = &task.HealthCheckConfig_HTTPCheck{
+			Scheme: healthCheck.GetHttpCheck().GetScheme(),
+			Port:   healthCheck.GetHttpCheck().GetPort(),
+			Path:   healthCheck.GetHttpCheck().GetPath(),
+		}
+	}
+
+	return result
+}
+
 // ConvertUpdateSpecToUpdateConfig converts update spec to update config
 func ConvertUpdateSpecToUpdateConfig(spec *stateless.UpdateSpec) *update.UpdateConfig {
 	return &update.UpdateConfig{
-		BatchSize:           spec.GetBatchSize(),
-		RollbackOnFailure:   spec.GetRollbackOnFailure(),
-		MaxInstanceAttempts: spec.GetMaxInstanceRetries(),
-		MaxFailureInstances: spec.GetMaxTolerableInstanceFailures(),
-		StartPaused:         spec.GetStartPaused(),
-		InPlace:             spec.GetInPlace(),
-		StartTasks:          spec.GetStartPods(),
+		BatchSize:            spec.GetBatchSize(),
+		RollbackOnFailure:    spec.GetRollbackOnFailure(),
+		MaxInstanceAttempts:  spec.GetMaxInstanceRetries(),
+		MaxFailureInstances:  spec.GetMaxTolerableInstanceFailures(),
+		StartPaused:          spec.GetStartPaused(),
+		InPlace:              spec.GetInPlace(),
+		StartTasks:           spec.GetStartPods(),
+		ReadinessTimeoutSecs: spec.GetReadinessTimeoutSecs(),
+		Canary:               convertCanarySpecToCanaryConfig(spec.GetCanary()),
+	}
+}
+
+// convertCanarySpecToCanaryConfig converts canary spec to canary config
+func convertCanarySpecToCanaryConfig(spec *stateless.CanarySpec) *update.CanaryConfig {
+	if spec == nil {
+		return nil
+	}
+
+	return &update.CanaryConfig{
+		InstanceCount: spec.GetInstanceCount(),
+		BakeTimeSecs:  spec.GetBakeTimeSecs(),
+		MaxFailures:   spec.GetMaxFailures(),
+	}
+}
+
+// convertCanaryConfigToCanarySpec converts canary config to canary spec
+func convertCanaryConfigToCanarySpec(config *update.CanaryConfig) *stateless.CanarySpec {
+	if config == nil {
+		return nil
+	}
+
+	return &stateless.CanarySpec{
+		InstanceCount: config.GetInstanceCount(),
+		BakeTimeSecs:  config.GetBakeTimeSecs(),
+		MaxFailures:   config.GetMaxFailures(),
 	}
 }
 
@@ -155,6 +155,7 @@ func (suite *apiConverterTestSuite) TestConvertTaskRuntimeToPodStatus() {
 		},
 		ResourceUsage: resourceUsage,
 		Healthy:       task.HealthState_HEALTHY,
+		Readiness:     task.HealthState_UNHEALTHY,
 		DesiredMesosTaskId: &mesos.TaskID{
 			Value: &testMesosTaskID,
 		},
@@ -178,6 +179,9 @@ func (suite *apiConverterTestSuite) TestConvertTaskRuntimeToPodStatus() {
 				Healthy: &pod.HealthStatus{
 					State: pod.HealthState_HEALTH_STATE_HEALTHY,
 				},
+				Ready: &pod.HealthStatus{
+					State: pod.HealthState_HEALTH_STATE_UNHEALTHY,
+				},
 				Message:   message,
 				Reason:    reason,
 				StartTime: startTime,
@@ -267,6 +271,14 @@ func (suite *apiConverterTestSuite) TestConvertTaskConfigToPodSpecAndViceVersa()
 				Path:   "/health",
 			},
 		},
+		ReadinessCheck: &task.HealthCheckConfig{
+			Enabled: true,
+			Type:    task.HealthCheckConfig_COMMAND,
+			CommandCheck: &task.HealthCheckConfig_CommandCheck{
+				Command: "ready.sh",
+			},
+			IntervalSecs: 5,
+		},
 		Ports: []*task.PortConfig{
 			{
 				Name:  portName,
@@ -329,6 +341,14 @@ func (suite *apiConverterTestSuite) TestConvertTaskConfigToPodSpecAndViceVersa()
 						Path:   taskConfig.GetHealthCheck().GetHttpCheck().GetPath(),
 					},
 				},
+				ReadinessCheck: &pod.HealthCheckSpec{
+					Enabled: true,
+					Type:    pod.HealthCheckSpec_HEALTH_CHECK_TYPE_COMMAND,
+					CommandCheck: &pod.HealthCheckSpec_CommandCheck{
+						Command: "ready.sh",
+					},
+					IntervalSecs: 5,
+				},
 				Ports: []*pod.PortSpec{
 					{
 						Name:    taskConfig.GetPorts()[0].GetName(),
@@ -574,6 +594,13 @@ func (suite *apiConverterTestSuite) TestConvertTaskConstraintsToPodConstraintsAn
 				},
 			},
 		},
+		{
+			Type: task.Constraint_SPREAD_CONSTRAINT,
+			SpreadConstraint: &task.SpreadConstraint{
+				HostAttribute: "rack",
+				MaxSkew:       2,
+			},
+		},
 	}
 
 	podConstraints := []*pod.Constraint{
@@ -665,12 +692,61 @@ func (suite *apiConverterTestSuite) TestConvertTaskConstraintsToPodConstraintsAn
 				},
 			},
 		},
+		{
+			Type: pod.Constraint_CONSTRAINT_TYPE_SPREAD,
+			SpreadConstraint: &pod.SpreadConstraint{
+				HostAttribute: "rack",
+				MaxSkew:       2,
+			},
+		},
 	}
 
 	suite.Equal(podConstraints, ConvertTaskConstraintsToPodConstraints(taskConstraints))
 	suite.Equal(taskConstraints, ConvertPodConstraintsToTaskConstraints(podConstraints))
 }
 
+// TestConvertPlacementPreferences tests conversion between v0
+// task.PlacementPreference and v1alpha pod.PlacementPreference arrays
+func (suite *apiConverterTestSuite) TestConvertPlacementPreferences() {
+	taskPreferences := []*task.PlacementPreference{
+		{
+			Kind: task.PlacementPreference_HOST,
+			Label: &peloton.Label{
+				Key:   "zone",
+				Value: "zone1",
+			},
+			Weight: 2,
+		},
+		{
+			Kind:   task.PlacementPreference_JOB,
+			JobId:  &peloton.JobID{Value: "test-id"},
+			Weight: -1,
+		},
+	}
+	podPreferences := []*pod.PlacementPreference{
+		{
+			Kind: pod.PlacementPreference_PLACEMENT_PREFERENCE_KIND_HOST,
+			Label: &v1alphapeloton.Label{
+				Key:   "zone",
+				Value: "zone1",
+			},
+			Weight: 2,
+		},
+		{
+			Kind:   pod.PlacementPreference_PLACEMENT_PREFERENCE_KIND_JOB,
+			JobId:  &v1alphapeloton.JobID{Value: "test-id"},
+			Weight: -1,
+		},
+	}
+
+	suite.Equal(podPreferences, convertPlacementPreferencesToPod(taskPreferences))
+	suite.Equal(taskPreferences, convertPlacementPreferencesToTask(podPreferences))
+
+	podSpec := ConvertTaskConfigToPodSpec(
+		&task.TaskConfig{PlacementPreferences: taskPreferences}, "", 0)
+	suite.Equal(podPreferences, podSpec.GetPlacementPreferences())
+}
+
 // TestConvertContainerPorts tests conversion from v0 task.PortConfig array to
 // v1alpha pod.PortSpec array
 func (suite *apiConverterTestSuite) TestConvertContainerPorts() {
@@ -1173,6 +1249,11 @@ func (suite *apiConverterTestSuite) TestConvertUpdateModelToWorkflowInfo() {
 			RollbackOnFailure:   true,
 			MaxFailureInstances: 2,
 			MaxInstanceAttempts: 3,
+			Canary: &update.CanaryConfig{
+				InstanceCount: 2,
+				BakeTimeSecs:  300,
+				MaxFailures:   1,
+			},
 		},
 	}
 	runtime := &job.RuntimeInfo{
@@ -1200,6 +1281,9 @@ func (suite *apiConverterTestSuite) TestConvertUpdateModelToWorkflowInfo() {
 	suite.Equal(updateModel.GetUpdateConfig().GetMaxFailureInstances(), workflowInfo.GetUpdateSpec().GetMaxTolerableInstanceFailures())
 	suite.Equal(updateModel.GetUpdateConfig().GetMaxInstanceAttempts(), workflowInfo.GetUpdateSpec().GetMaxInstanceRetries())
 	suite.Equal(updateModel.GetUpdateConfig().GetStartPaused(), workflowInfo.GetUpdateSpec().GetStartPaused())
+	suite.Equal(uint32(2), workflowInfo.GetUpdateSpec().GetCanary().GetInstanceCount())
+	suite.Equal(uint32(300), workflowInfo.GetUpdateSpec().GetCanary().GetBakeTimeSecs())
+	suite.Equal(uint32(1), workflowInfo.GetUpdateSpec().GetCanary().GetMaxFailures())
 }
 
 // TestConvertUpdateModelToWorkflowInfoRestart tests conversion from
@@ -1345,6 +1429,12 @@ func (suite *apiConverterTestSuite) TestConvertUpdateSpecToUpdateConfig() {
 		MaxInstanceRetries:           3,
 		MaxTolerableInstanceFailures: 2,
 		StartPaused:                  true,
+		ReadinessTimeoutSecs:         120,
+		Canary: &stateless.CanarySpec{
+			InstanceCount: 2,
+			BakeTimeSecs:  300,
+			MaxFailures:   1,
+		},
 	}
 
 	config := ConvertUpdateSpecToUpdateConfig(spec)
@@ -1354,6 +1444,13 @@ func (suite *apiConverterTestSuite) TestConvertUpdateSpecToUpdateConfig() {
 	suite.Equal(spec.GetMaxInstanceRetries(), config.GetMaxInstanceAttempts())
 	suite.Equal(spec.GetMaxTolerableInstanceFailures(), config.GetMaxFailureInstances())
 	suite.Equal(spec.GetStartPaused(), config.GetStartPaused())
+	suite.Equal(spec.GetReadinessTimeoutSecs(), config.GetReadinessTimeoutSecs())
+	suite.Equal(spec.GetCanary().GetInstanceCount(), config.GetCanary().GetInstanceCount())
+	suite.Equal(spec.GetCanary().GetBakeTimeSecs(), config.GetCanary().GetBakeTimeSecs())
+	suite.Equal(spec.GetCanary().GetMaxFailures(), config.GetCanary().GetMaxFailures())
+
+	// update spec without canary
+	suite.Nil(ConvertUpdateSpecToUpdateConfig(&stateless.UpdateSpec{}).GetCanary())
 }
 
 // TestConvertInstanceIDListToInstanceRange tests conversion from
@@ -47,8 +47,8 @@ func GetInitialHealthState(taskConfig *task.TaskConfig) task.HealthState {
 }
 
 // RegenerateMesosTaskRuntime changes the runtime to INITIALIZED state
-// with correct initial health state and a regenerated mesos task id
-// and the previous mesos task id set to the current value.
+// with correct initial health state, a reset readiness, a regenerated
+// mesos task id and the previous mesos task id set to the current value.
 func RegenerateMesosTaskRuntime(
 	jobID *peloton.JobID,
 	instanceID uint32,
@@ -61,6 +61,7 @@ func RegenerateMesosTaskRuntime(
 	taskRuntime.MesosTaskId = mesosTaskID
 	taskRuntime.DesiredMesosTaskId = mesosTaskID
 	taskRuntime.Healthy = initHealthyField
+	taskRuntime.Readiness = task.HealthState_INVALID
 
 	taskRuntime.AgentID = nil
 	taskRuntime.StartTime = ""
@@ -74,7 +75,8 @@ func RegenerateMesosTaskRuntime(
 
 // RegenerateMesosTaskIDDiff returns a diff for patch with the previous mesos
 // task id set to the current mesos task id, a regenerated mesos task id, a
-// proper initial health state, and task state set to INITIALIZED.
+// proper initial health state, a reset readiness, and task state set to
+// INITIALIZED.
 func RegenerateMesosTaskIDDiff(
 	jobID *peloton.JobID,
 	instanceID uint32,
@@ -88,6 +90,7 @@ func RegenerateMesosTaskIDDiff(
 		jobmgrcommon.MesosTaskIDField:        mesosTaskID,
 		jobmgrcommon.DesiredMesosTaskIDField: mesosTaskID,
 		jobmgrcommon.HealthyField:            initHealthyField,
+		jobmgrcommon.ReadinessField:          task.HealthState_INVALID,
 
 		jobmgrcommon.AgentIDField:           nil,
 		jobmgrcommon.StartTimeField:         "",
@@ -65,6 +65,7 @@ func TestRegenerateMesosTaskRuntime(t *testing.T) {
 		runtime := &task.RuntimeInfo{
 			MesosTaskId:        &mesos.TaskID{Value: &tt.curMesosTaskID},
 			DesiredMesosTaskId: &mesos.TaskID{Value: &tt.desiredMesosTaskID},
+			Readiness:          task.HealthState_HEALTHY,
 		}
 		RegenerateMesosTaskRuntime(
 			&peloton.JobID{Value: tt.jobID},
@@ -78,6 +79,7 @@ func TestRegenerateMesosTaskRuntime(t *testing.T) {
 		assert.Equal(t, *runtime.MesosTaskId.Value, tt.newMesosTaskID)
 		assert.Equal(t, *runtime.DesiredMesosTaskId.Value, tt.newMesosTaskID)
 		assert.Equal(t, runtime.Healthy, tt.initHealthState)
+		assert.Equal(t, runtime.Readiness, task.HealthState_INVALID)
 
 		assert.Empty(t, runtime.AgentID)
 		assert.Empty(t, runtime.StartTime)
@@ -127,6 +129,7 @@ func TestRegenerateMesosTaskIDDiff(t *testing.T) {
 		runtime := &task.RuntimeInfo{
 			MesosTaskId:        &mesos.TaskID{Value: &tt.curMesosTaskID},
 			DesiredMesosTaskId: &mesos.TaskID{Value: &tt.desiredMesosTaskID},
+			Readiness:          task.HealthState_HEALTHY,
 		}
 		diff := RegenerateMesosTaskIDDiff(
 			&peloton.JobID{Value: tt.jobID},
@@ -143,6 +146,7 @@ func TestRegenerateMesosTaskIDDiff(t *testing.T) {
 		assert.Equal(t, *diff[jobmgrcommon.DesiredMesosTaskIDField].(*mesos.TaskID).Value,
 			tt.newMesosTaskID)
 		assert.Equal(t, diff[jobmgrcommon.HealthyField], tt.initHealthState)
+		assert.Equal(t, diff[jobmgrcommon.ReadinessField], task.HealthState_INVALID)
 
 		assert.Empty(t, diff[jobmgrcommon.AgentIDField])
 		assert.Empty(t, diff[jobmgrcommon.StartTimeField])
@@ -15,6 +15,8 @@
 package update
 
 import (
+	"time"
+
 	pbjob "github.com/uber/peloton/.gen/peloton/api/v0/job"
 	pbtask "github.com/uber/peloton/.gen/peloton/api/v0/task"
 )
@@ -37,3 +39,42 @@ func HasFailedUpdate(
 
 	return taskRuntime.GetFailureCount() >= maxAttempts
 }
+
+// IsTaskReady returns if a task has passed its readiness check.
+// Tasks without a readiness check are always ready.
+func IsTaskReady(taskRuntime *pbtask.RuntimeInfo) bool {
+	switch taskRuntime.GetReadiness() {
+	case pbtask.HealthState_HEALTH_UNKNOWN, pbtask.HealthState_UNHEALTHY:
+		return false
+	}
+	return true
+}
+
+// GetReadinessDeadline returns the time by which a running task
+// is expected to pass its readiness check. It returns false if the
+// task is not waiting on its readiness check or timeoutSecs is 0.
+func GetReadinessDeadline(
+	taskRuntime *pbtask.RuntimeInfo,
+	timeoutSecs uint32) (time.Time, bool) {
+	if timeoutSecs == 0 ||
+		taskRuntime.GetState() != pbtask.TaskState_RUNNING ||
+		IsTaskReady(taskRuntime) {
+		return time.Time{}, false
+	}
+
+	startTime, err := time.Parse(time.RFC3339Nano, taskRuntime.GetStartTime())
+	if err != nil {
+		return time.Time{}, false
+	}
+	return startTime.Add(time.Duration(timeoutSecs) * time.Second), true
+}
+
+// HasReadinessTimedOut returns if a running task has not passed its
+// readiness check within timeoutSecs of starting to run.
+// If timeoutSecs is 0, HasReadinessTimedOut always returns false.
+func HasReadinessTimedOut(
+	taskRuntime *pbtask.RuntimeInfo,
+	timeoutSecs uint32) bool {
+	deadline, ok := GetReadinessDeadline(taskRuntime, timeoutSecs)
+	return ok && !time.Now().Before(deadline)
+}
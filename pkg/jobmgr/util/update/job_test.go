@@ -16,6 +16,7 @@ package update
 
 import (
 	"testing"
+	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
@@ -63,3 +64,71 @@ func TestHasFailedUpdate(t *testing.T) {
 			"test %d fails", i)
 	}
 }
+
+func TestIsTaskReady(t *testing.T) {
+	isTaskReadyTests := []struct {
+		readiness task.HealthState
+		result    bool
+	}{
+		{task.HealthState_INVALID, true},
+		{task.HealthState_DISABLED, true},
+		{task.HealthState_HEALTHY, true},
+		{task.HealthState_HEALTH_UNKNOWN, false},
+		{task.HealthState_UNHEALTHY, false},
+	}
+
+	for i, test := range isTaskReadyTests {
+		assert.Equal(t,
+			IsTaskReady(&task.RuntimeInfo{Readiness: test.readiness}),
+			test.result,
+			"test %d fails", i)
+	}
+}
+
+func TestHasReadinessTimedOut(t *testing.T) {
+	recent := time.Now().UTC().Format(time.RFC3339Nano)
+	expired := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339Nano)
+
+	hasReadinessTimedOutTests := []struct {
+		runtime     *task.RuntimeInfo
+		timeoutSecs uint32
+		result      bool
+	}{
+		// no timeout configured
+		{&task.RuntimeInfo{
+			State:     task.TaskState_RUNNING,
+			StartTime: expired,
+			Readiness: task.HealthState_HEALTH_UNKNOWN,
+		}, 0, false},
+		// task is ready
+		{&task.RuntimeInfo{
+			State:     task.TaskState_RUNNING,
+			StartTime: expired,
+			Readiness: task.HealthState_HEALTHY,
+		}, 60, false},
+		// task is not running
+		{&task.RuntimeInfo{
+			State:     task.TaskState_STARTING,
+			Readiness: task.HealthState_HEALTH_UNKNOWN,
+		}, 60, false},
+		// task started recently
+		{&task.RuntimeInfo{
+			State:     task.TaskState_RUNNING,
+			StartTime: recent,
+			Readiness: task.HealthState_UNHEALTHY,
+		}, 60, false},
+		// task has not been ready within the timeout
+		{&task.RuntimeInfo{
+			State:     task.TaskState_RUNNING,
+			StartTime: expired,
+			Readiness: task.HealthState_UNHEALTHY,
+		}, 60, true},
+	}
+
+	for i, test := range hasReadinessTimedOutTests {
+		assert.Equal(t,
+			HasReadinessTimedOut(test.runtime, test.timeoutSecs),
+			test.result,
+			"test %d fails", i)
+	}
+}
@@ -17,6 +17,7 @@ package watchsvc
 const (
 	_defaultBufferSize int = 100
 	_defaultMaxClient  int = 1000
+	_defaultMaxHistory int = 10000
 )
 
 // Config for Watch API
@@ -26,6 +27,10 @@ type Config struct {
 
 	// Maximum number of concurrent watch clients
 	MaxClient int `yaml:"max_client"`
+
+	// Maximum number of most recent events kept to serve watches
+	// which resume from a start revision
+	MaxHistory int `yaml:"max_history"`
 }
 
 func (c *Config) normalize() {
@@ -35,4 +40,7 @@ func (c *Config) normalize() {
 	if c.MaxClient <= 0 {
 		c.MaxClient = _defaultMaxClient
 	}
+	if c.MaxHistory <= 0 {
+		c.MaxHistory = _defaultMaxHistory
+	}
 }
@@ -18,6 +18,7 @@ import (
 	"context"
 	"strings"
 
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch/svc"
 
@@ -72,7 +73,10 @@ func (h *ServiceHandler) Watch(
 		log.WithField("request", req).
 			Debug("starting new pod watch")
 
-		watchID, watchClient, err := h.processor.NewTaskClient(req.GetPodFilter())
+		watchID, watchClient, err := h.processor.NewTaskClient(
+			req.GetPodFilter(),
+			req.GetStartRevision(),
+		)
 		if err != nil {
 			log.WithError(err).
 				Warn("failed to create pod watch client")
@@ -84,7 +88,8 @@ func (h *ServiceHandler) Watch(
 		}()
 
 		initResp := &svc.WatchResponse{
-			WatchId: watchID,
+			WatchId:  watchID,
+			Revision: watchClient.Revision,
 		}
 		if err := stream.Send(initResp); err != nil {
 			log.WithField("watch_id", watchID).
@@ -95,10 +100,11 @@ func (h *ServiceHandler) Watch(
 
 		for {
 			select {
-			case p := <-watchClient.Input:
+			case e := <-watchClient.Input:
 				resp := &svc.WatchResponse{
-					WatchId: watchID,
-					Pods:    []*pod.PodSummary{p},
+					WatchId:  watchID,
+					Revision: e.Revision,
+					Pods:     []*pod.PodSummary{e.Pod},
 				}
 				if err := stream.Send(resp); err != nil {
 					log.WithField("watch_id", watchID).
@@ -134,9 +140,78 @@ func (h *ServiceHandler) Watch(
 
 	// Create watch for job
 	if req.GetStatelessJobFilter() != nil {
-		err := yarpcerrors.UnimplementedErrorf("job watch is not implemented")
-		log.Warn("job watch is not implemented")
-		return err
+		log.WithField("request", req).
+			Debug("starting new job watch")
+
+		// job changes are not kept, so a job watch cannot be resumed
+		// from a previous revision
+		if req.GetStartRevision() != 0 {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"start revision is not supported for job watch")
+		}
+
+		watchID, watchClient, err := h.processor.NewJobClient(
+			req.GetStatelessJobFilter(),
+		)
+		if err != nil {
+			log.WithError(err).
+				Warn("failed to create job watch client")
+			return err
+		}
+
+		defer func() {
+			h.processor.StopJobClient(watchID)
+		}()
+
+		initResp := &svc.WatchResponse{
+			WatchId:  watchID,
+			Revision: watchClient.Revision,
+		}
+		if err := stream.Send(initResp); err != nil {
+			log.WithField("watch_id", watchID).
+				WithError(err).
+				Warn("failed to send initial response for job watch")
+			return err
+		}
+
+		for {
+			select {
+			case e := <-watchClient.Input:
+				resp := &svc.WatchResponse{
+					WatchId:       watchID,
+					Revision:      e.Revision,
+					StatelessJobs: []*stateless.JobSummary{e.Job},
+				}
+				if err := stream.Send(resp); err != nil {
+					log.WithField("watch_id", watchID).
+						WithError(err).
+						Warn("failed to send response for job watch")
+					return err
+				}
+			case s := <-watchClient.Signal:
+				log.WithFields(log.Fields{
+					"watch_id": watchID,
+					"signal":   s,
+				}).Debug("received signal")
+
+				err := handleSignal(
+					watchID,
+					s,
+					map[StopSignal]tally.Counter{
+						StopSignalCancel:   h.metrics.WatchJobCancel,
+						StopSignalOverflow: h.metrics.WatchJobOverflow,
+					},
+				)
+
+				if !yarpcerrors.IsCancelled(err) {
+					log.WithField("watch_id", watchID).
+						WithError(err).
+						Warn("watch stopped due to signal")
+				}
+
+				return err
+			}
+		}
 	}
 
 	err := yarpcerrors.InvalidArgumentErrorf("not supported watch type")
@@ -190,6 +265,23 @@ func (h *ServiceHandler) Cancel(
 		return &svc.CancelResponse{}, nil
 	}
 
+	if strings.HasPrefix(watchID, ClientTypeJob.String()) {
+		err := h.processor.StopJobClient(watchID)
+		if err != nil {
+			if yarpcerrors.IsNotFound(err) {
+				h.metrics.CancelNotFound.Inc(1)
+			}
+
+			log.WithField("watch_id", watchID).
+				WithError(err).
+				Warn("failed to stop job client")
+
+			return nil, err
+		}
+
+		return &svc.CancelResponse{}, nil
+	}
+
 	err := yarpcerrors.NotFoundErrorf("invalid watch id")
 	log.WithFields(log.Fields{
 		"watch_id": watchID,
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
@@ -91,15 +92,129 @@ func (suite *WatchServiceHandlerTestSuite) TestWatch_InvalidRequest() {
 	suite.True(yarpcerrors.IsInvalidArgument(err))
 }
 
+// TestJobWatch sets up a job watch client, and verifies the responses
+// are streamed back correctly based on the input, finally the
+// test cancels the watch stream.
 func (suite *WatchServiceHandlerTestSuite) TestJobWatch() {
+	watchID := NewWatchID(ClientTypeJob)
+	jobClient := &JobClient{
+		// do not set buffer size for input to make sure the
+		// tests sends all the events before sending stop
+		// signal
+		Input:    make(chan *JobEvent),
+		Signal:   make(chan StopSignal, 1),
+		Revision: 5,
+	}
+
+	filter := &watch.StatelessJobFilter{
+		JobIds: []*peloton.JobID{{Value: "job-0"}},
+	}
+	suite.processor.EXPECT().NewJobClient(filter).
+		Return(watchID, jobClient, nil)
+	suite.processor.EXPECT().StopJobClient(watchID)
+
+	jobs := []*stateless.JobSummary{
+		{
+			JobId:  &peloton.JobID{Value: "job-0"},
+			Status: &stateless.JobStatus{State: stateless.JobState_JOB_STATE_RUNNING},
+		},
+		{
+			JobId: &peloton.JobID{Value: "job-0"},
+			Status: &stateless.JobStatus{
+				State: stateless.JobState_JOB_STATE_RUNNING,
+				WorkflowStatus: &stateless.WorkflowStatus{
+					State: stateless.WorkflowState_WORKFLOW_STATE_SUCCEEDED,
+				},
+			},
+		},
+	}
+
+	suite.watchServer.EXPECT().
+		Send(&watchsvc.WatchResponse{
+			WatchId:  watchID,
+			Revision: 5,
+		}).
+		Return(nil)
+	for _, j := range jobs {
+		suite.watchServer.EXPECT().
+			Send(&watchsvc.WatchResponse{
+				WatchId:       watchID,
+				Revision:      5,
+				StatelessJobs: []*stateless.JobSummary{j},
+			}).
+			Return(nil)
+	}
+
+	req := &watchsvc.WatchRequest{
+		StatelessJobFilter: filter,
+	}
+
+	go func() {
+		for _, j := range jobs {
+			jobClient.Input <- &JobEvent{Revision: 5, Job: j}
+		}
+		// cancelling job watch
+		jobClient.Signal <- StopSignalCancel
+	}()
+
+	err := suite.handler.Watch(req, suite.watchServer)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsCancelled(err))
+}
+
+// TestJobWatch_MaxClientReached checks Watch will return resource-exhausted
+// error when NewJobClient reached max client.
+func (suite *WatchServiceHandlerTestSuite) TestJobWatch_MaxClientReached() {
+	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+		Return("", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached"))
+
 	req := &watchsvc.WatchRequest{
 		StatelessJobFilter: &watch.StatelessJobFilter{},
 	}
 
-	// job watch is currently unimplemented
 	err := suite.handler.Watch(req, suite.watchServer)
 	suite.Error(err)
-	suite.True(yarpcerrors.IsUnimplemented(err))
+	suite.True(yarpcerrors.IsResourceExhausted(err))
+}
+
+// TestJobWatch_StartRevision checks Watch will return invalid-argument
+// error for a job watch with a start revision, since job watches cannot
+// be resumed from a previous revision.
+func (suite *WatchServiceHandlerTestSuite) TestJobWatch_StartRevision() {
+	req := &watchsvc.WatchRequest{
+		StartRevision:      10,
+		StatelessJobFilter: &watch.StatelessJobFilter{},
+	}
+
+	err := suite.handler.Watch(req, suite.watchServer)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestJobWatch_Overflow checks Watch will return internal error when the
+// job watch is aborted due to event overflow.
+func (suite *WatchServiceHandlerTestSuite) TestJobWatch_Overflow() {
+	watchID := NewWatchID(ClientTypeJob)
+	jobClient := &JobClient{
+		Input:  make(chan *JobEvent),
+		Signal: make(chan StopSignal, 1),
+	}
+	jobClient.Signal <- StopSignalOverflow
+
+	suite.processor.EXPECT().NewJobClient(gomock.Any()).
+		Return(watchID, jobClient, nil)
+	suite.processor.EXPECT().StopJobClient(watchID)
+	suite.watchServer.EXPECT().
+		Send(&watchsvc.WatchResponse{WatchId: watchID}).
+		Return(nil)
+
+	req := &watchsvc.WatchRequest{
+		StatelessJobFilter: &watch.StatelessJobFilter{},
+	}
+
+	err := suite.handler.Watch(req, suite.watchServer)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsInternal(err))
 }
 
 // TestTaskWatch sets up a watch client, and verifies the responses
@@ -111,11 +226,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), gomock.Any()).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -134,11 +249,12 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch() {
 			Pods:    nil,
 		}).
 		Return(nil)
-	for _, p := range pods {
+	for i, p := range pods {
 		suite.watchServer.EXPECT().
 			Send(&watchsvc.WatchResponse{
-				WatchId: watchID,
-				Pods:    []*pod.PodSummary{p},
+				WatchId:  watchID,
+				Revision: uint64(i + 1),
+				Pods:     []*pod.PodSummary{p},
 			}).
 			Return(nil)
 	}
@@ -148,8 +264,8 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch() {
 	}
 
 	go func() {
-		for _, p := range pods {
-			taskClient.Input <- p
+		for i, p := range pods {
+			taskClient.Input <- &PodEvent{Revision: uint64(i + 1), Pod: p}
 		}
 		// cancelling task watch
 		taskClient.Signal <- StopSignalCancel
@@ -163,7 +279,7 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch() {
 // TestTaskWatch_MaxClientReached checks Watch will return resource-exhausted
 // error when NewTaskClient reached max client.
 func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_MaxClientReached() {
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), gomock.Any()).
 		Return("", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached"))
 
 	req := &watchsvc.WatchRequest{
@@ -175,6 +291,24 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_MaxClientReached() {
 	suite.True(yarpcerrors.IsResourceExhausted(err))
 }
 
+// TestTaskWatch_StartRevision tests the start revision of the request is
+// passed to the watch processor, and out-of-range error is returned if the
+// revision is no longer available.
+func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_StartRevision() {
+	filter := &watch.PodFilter{}
+	suite.processor.EXPECT().NewTaskClient(filter, uint64(10)).
+		Return("", nil, yarpcerrors.OutOfRangeErrorf("start revision out of range"))
+
+	req := &watchsvc.WatchRequest{
+		StartRevision: 10,
+		PodFilter:     filter,
+	}
+
+	err := suite.handler.Watch(req, suite.watchServer)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+}
+
 // TestTaskWatch_InitSendError tests for error case of iniitial response.
 func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_InitSendError() {
 	watchID := NewWatchID(ClientTypeTask)
@@ -182,11 +316,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_InitSendError() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), gomock.Any()).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -217,11 +351,11 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_SendError() {
 		// do not set buffer size for input to make sure the
 		// tests sends all the events before sending stop
 		// signal
-		Input:  make(chan *pod.PodSummary),
+		Input:  make(chan *PodEvent),
 		Signal: make(chan StopSignal, 1),
 	}
 
-	suite.processor.EXPECT().NewTaskClient(gomock.Any()).
+	suite.processor.EXPECT().NewTaskClient(gomock.Any(), gomock.Any()).
 		Return(watchID, taskClient, nil)
 	suite.processor.EXPECT().StopTaskClient(watchID)
 
@@ -252,7 +386,7 @@ func (suite *WatchServiceHandlerTestSuite) TestTaskWatch_SendError() {
 	}
 
 	go func() {
-		taskClient.Input <- p
+		taskClient.Input <- &PodEvent{Pod: p}
 		taskClient.Signal <- StopSignalCancel
 	}()
 
@@ -293,6 +427,37 @@ func (suite *WatchServiceHandlerTestSuite) TestCancel_NotFoundTask() {
 	suite.True(yarpcerrors.IsNotFound(err))
 }
 
+// TestCancel_Job tests Cancel request for a job watch is proxied to
+// watch processor correctly.
+func (suite *WatchServiceHandlerTestSuite) TestCancel_Job() {
+	watchID := NewWatchID(ClientTypeJob)
+
+	suite.processor.EXPECT().StopJobClient(watchID).Return(nil)
+
+	resp, err := suite.handler.Cancel(suite.ctx, &watchsvc.CancelRequest{
+		WatchId: watchID,
+	})
+	suite.NotNil(resp)
+	suite.NoError(err)
+}
+
+// TestCancel_NotFoundJob tests Cancel response returns not-found error, when
+// an invalid job watch-id is passed in.
+func (suite *WatchServiceHandlerTestSuite) TestCancel_NotFoundJob() {
+	watchID := NewWatchID(ClientTypeJob)
+
+	suite.processor.EXPECT().
+		StopJobClient(watchID).
+		Return(yarpcerrors.NotFoundErrorf("watch_id %s not exist for job watch client", watchID))
+
+	resp, err := suite.handler.Cancel(suite.ctx, &watchsvc.CancelRequest{
+		WatchId: watchID,
+	})
+	suite.Nil(resp)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
 // TestCancel_InvalidWatchID tests Cancel response returns not-found error,
 // when an invalid watch id (without proper prefix) is passed in.
 func (suite *WatchServiceHandlerTestSuite) TestCancel_InvalidWatchID() {
@@ -20,6 +20,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	v1peloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/uber/peloton/pkg/common/util"
@@ -53,7 +54,44 @@ func (l WatchListener) JobRuntimeChanged(
 	jobType job.JobType,
 	runtime *job.RuntimeInfo,
 ) {
-	// TODO(kevinxu): to be implemented
+	// for now watch api only supports stateless
+	if jobType != job.JobType_SERVICE {
+		log.Debug("skip JobRuntimeChanged due to not being service type job")
+		return
+	}
+
+	if jobID == nil {
+		log.Debug("skip JobRuntimeChanged due to jobID being nil")
+		return
+	}
+
+	if runtime == nil {
+		log.Debug("skip JobRuntimeChanged due to runtime being nil")
+		return
+	}
+
+	l.processor.NotifyJobChange(jobID, runtime)
+}
+
+// UpdateRuntimeChanged is invoked when the state or progress of a job
+// update (workflow) is updated in cache and persistent store. The job
+// type is not known here, workflow changes of jobs which are not
+// stateless are dropped by the processor.
+func (l WatchListener) UpdateRuntimeChanged(
+	jobID *v0peloton.JobID,
+	updateInfo *models.UpdateModel,
+) {
+	if jobID == nil {
+		log.Debug("skip UpdateRuntimeChanged due to jobID being nil")
+		return
+	}
+
+	if updateInfo == nil {
+		log.Debug("skip UpdateRuntimeChanged due to updateInfo being nil")
+		return
+	}
+
+	l.processor.NotifyWorkflowChange(jobID, updateInfo)
 }
 
 // TaskRuntimeChanged is invoked when the runtime for a task is updated
@@ -20,6 +20,8 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	watchmocks "github.com/uber/peloton/pkg/jobmgr/watchsvc/mocks"
 
@@ -103,6 +105,63 @@ func (suite *WatchListenerTestSuite) TestTaskRuntimeChanged_NilFields() {
 	)
 }
 
+// TestJobRuntimeChanged checks WatchProcessor.NotifyJobChange() is called
+// when JobRuntimeChanged is called on listener
+func (suite *WatchListenerTestSuite) TestJobRuntimeChanged() {
+	jobID := &v0peloton.JobID{Value: "test-job-1"}
+	runtime := &job.RuntimeInfo{State: job.JobState_RUNNING}
+
+	suite.processor.EXPECT().
+		NotifyJobChange(jobID, runtime).
+		Times(1)
+
+	suite.listener.JobRuntimeChanged(jobID, job.JobType_SERVICE, runtime)
+}
+
+// TestJobRuntimeChanged_NonServiceType checks
+// WatchProcessor.NotifyJobChange() is not called when not service type
+// event or nil fields are passed in.
+func (suite *WatchListenerTestSuite) TestJobRuntimeChanged_NonServiceType() {
+	// do not expect calls to processor.NotifyJobChange
+
+	suite.listener.JobRuntimeChanged(
+		&v0peloton.JobID{Value: "test-job-1"},
+		job.JobType_BATCH,
+		&job.RuntimeInfo{},
+	)
+
+	suite.listener.JobRuntimeChanged(
+		nil,
+		job.JobType_SERVICE,
+		&job.RuntimeInfo{},
+	)
+
+	suite.listener.JobRuntimeChanged(
+		&v0peloton.JobID{Value: "test-job-1"},
+		job.JobType_SERVICE,
+		nil,
+	)
+}
+
+// TestUpdateRuntimeChanged checks WatchProcessor.NotifyWorkflowChange()
+// is called when UpdateRuntimeChanged is called on listener, and is not
+// called when nil fields are passed in.
+func (suite *WatchListenerTestSuite) TestUpdateRuntimeChanged() {
+	jobID := &v0peloton.JobID{Value: "test-job-1"}
+	updateInfo := &models.UpdateModel{
+		UpdateID: &v0peloton.UpdateID{Value: "test-update-1"},
+		State:    update.State_ROLLING_FORWARD,
+	}
+
+	suite.processor.EXPECT().
+		NotifyWorkflowChange(jobID, updateInfo).
+		Times(1)
+
+	suite.listener.UpdateRuntimeChanged(jobID, updateInfo)
+	suite.listener.UpdateRuntimeChanged(nil, updateInfo)
+	suite.listener.UpdateRuntimeChanged(jobID, nil)
+}
+
 func TestWatchListener(t *testing.T) {
 	suite.Run(t, &WatchListenerTestSuite{})
 }
@@ -23,6 +23,14 @@ type Metrics struct {
 	WatchPodCancel   tally.Counter
 	WatchPodOverflow tally.Counter
 
+	// Number of pod events replayed from history to new watches
+	WatchPodReplayed tally.Counter
+	// Number of watches rejected due to start revision out of range
+	WatchPodOutOfRange tally.Counter
+
+	WatchJobCancel   tally.Counter
+	WatchJobOverflow tally.Counter
+
 	CancelNotFound tally.Counter
 
 	// Time takes to acquire lock in watch processor
@@ -36,6 +44,12 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		WatchPodCancel:   subScope.Counter("watch_pod_cancel"),
 		WatchPodOverflow: subScope.Counter("watch_pod_overflow"),
 
+		WatchPodReplayed:   subScope.Counter("watch_pod_replayed"),
+		WatchPodOutOfRange: subScope.Counter("watch_pod_out_of_range"),
+
+		WatchJobCancel:   subScope.Counter("watch_job_cancel"),
+		WatchJobOverflow: subScope.Counter("watch_job_overflow"),
+
 		CancelNotFound: subScope.Counter("cancel_not_found"),
 
 		ProcessorLockDuration: subScope.Timer("processor_lock_duration"),
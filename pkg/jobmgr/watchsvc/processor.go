@@ -17,14 +17,21 @@ package watchsvc
 import (
 	"fmt"
 	"sync"
+	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 
+	"github.com/uber/peloton/pkg/common/cirbuf"
 	"github.com/uber/peloton/pkg/common/util"
+	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/pborman/uuid"
 	log "github.com/sirupsen/logrus"
 	"github.com/uber-go/tally"
@@ -76,10 +83,16 @@ func (t ClientType) String() string {
 // client lifecycle, and task / job event fan-out.
 type WatchProcessor interface {
 	// NewTaskClient creates a new watch client for task event changes.
+	// If startRevision is non-zero, the events since that revision
+	// which are still in the history are replayed to the client first.
 	// Returns the watch id and a new instance of TaskClient.
-	NewTaskClient(filter *watch.PodFilter) (string, *TaskClient, error)
+	NewTaskClient(
+		filter *watch.PodFilter,
+		startRevision uint64,
+	) (string, *TaskClient, error)
 
-	// StopTaskClients stops all the task clients on leadership change.
+	// StopTaskClients stops all the task clients and clears the event
+	// history on leadership change.
 	StopTaskClients()
 
 	// StopTaskClient stops a task watch client. Returns "not-found" error
@@ -89,6 +102,30 @@ type WatchProcessor interface {
 	// NotifyTaskChange receives pod event, and notifies all the clients
 	// which are interested in the pod.
 	NotifyTaskChange(pod *pod.PodSummary, podLabels []*peloton.Label)
+
+	// NewJobClient creates a new watch client for stateless job and
+	// workflow changes. Returns the watch id and a new instance of
+	// JobClient.
+	NewJobClient(
+		filter *watch.StatelessJobFilter,
+	) (string, *JobClient, error)
+
+	// StopJobClients stops all the job clients and drops the known
+	// job states on leadership change.
+	StopJobClients()
+
+	// StopJobClient stops a job watch client. Returns "not-found" error
+	// if the corresponding watch client is not found.
+	StopJobClient(watchID string) error
+
+	// NotifyJobChange receives the runtime of a stateless job, and
+	// notifies all the clients which are interested in the job.
+	NotifyJobChange(jobID *peloton.JobID, runtime *job.RuntimeInfo)
+
+	// NotifyWorkflowChange receives the state and progress of a job
+	// workflow, and notifies all the clients which are interested in
+	// the job.
+	NotifyWorkflowChange(jobID *peloton.JobID, updateInfo *models.UpdateModel)
 }
 
 // watchProcessor is an implementation of WatchProcessor interface.
@@ -99,6 +136,18 @@ type watchProcessor struct {
 	taskClients map[string]*TaskClient
 	jobClients  map[string]*JobClient
 	metrics     *Metrics
+
+	// Last known runtime and workflow of the stateless jobs, keyed by
+	// job id, used to build the job status sent to job watch clients
+	// since runtime and workflow changes are received separately.
+	jobs map[string]*jobState
+
+	// Bounded history of the most recent pod events, used to serve
+	// watches with a start revision. The revision of an event is
+	// baseRevision plus its sequence id in the history.
+	maxHistory   int
+	history      *cirbuf.CircularBuffer
+	baseRevision uint64
 }
 
 var processor *watchProcessor
@@ -107,15 +156,40 @@ var onceInitWatchProcessor sync.Once
 // TaskClient represents a client which interested in task event changes.
 type TaskClient struct {
 	Filter *watch.PodFilter
-	Input  chan *pod.PodSummary
+	Input  chan *PodEvent
 	Signal chan StopSignal
+	// Revision of the processor when the client was created
+	Revision uint64
+}
+
+// PodEvent is a pod change along with the revision assigned to it
+// by the watch processor.
+type PodEvent struct {
+	Revision uint64
+	Pod      *pod.PodSummary
+	Labels   []*peloton.Label
 }
 
 // JobClient represents a client which interested in job event changes.
 type JobClient struct {
 	Filter *watch.StatelessJobFilter
-	Input  chan *stateless.JobSummary
+	Input  chan *JobEvent
 	Signal chan StopSignal
+	// Revision of the processor when the client was created
+	Revision uint64
+}
+
+// JobEvent is a stateless job change along with the revision of the
+// watch processor when the change was received.
+type JobEvent struct {
+	Revision uint64
+	Job      *stateless.JobSummary
+}
+
+// jobState is the last known runtime and workflow of a stateless job.
+type jobState struct {
+	runtime    *job.RuntimeInfo
+	updateInfo *models.UpdateModel
 }
 
 // newWatchProcessor should only be used in unit tests.
@@ -125,13 +199,17 @@ func newWatchProcessor(
 	parent tally.Scope,
 ) *watchProcessor {
 	cfg.normalize()
-	return &watchProcessor{
+	p := &watchProcessor{
 		bufferSize:  cfg.BufferSize,
 		maxClient:   cfg.MaxClient,
 		taskClients: make(map[string]*TaskClient),
 		jobClients:  make(map[string]*JobClient),
 		metrics:     NewMetrics(parent),
+		jobs:        make(map[string]*jobState),
+		maxHistory:  cfg.MaxHistory,
 	}
+	p.resetHistory()
+	return p
 }
 
 // InitWatchProcessor initializes WatchProcessor singleton.
@@ -156,8 +234,14 @@ func NewWatchID(clientType ClientType) string {
 }
 
 // NewTaskClient creates a new watch client for task event changes.
+// If startRevision is non-zero, the events since that revision which are
+// still in the history are replayed to the client first. Returns
+// "out-of-range" error if the history does not go back to startRevision.
 // Returns the watch id and a new instance of TaskClient.
-func (p *watchProcessor) NewTaskClient(filter *watch.PodFilter) (string, *TaskClient, error) {
+func (p *watchProcessor) NewTaskClient(
+	filter *watch.PodFilter,
+	startRevision uint64,
+) (string, *TaskClient, error) {
 	sw := p.metrics.ProcessorLockDuration.Start()
 	p.Lock()
 	defer p.Unlock()
@@ -167,17 +251,96 @@ func (p *watchProcessor) NewTaskClient(filter *watch.PodFilter) (string, *TaskCl
 		return "", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached")
 	}
 
+	var replay []*PodEvent
+	if startRevision != 0 {
+		events, err := p.getHistory(startRevision)
+		if err != nil {
+			return "", nil, err
+		}
+		for _, e := range events {
+			if matchPodFilter(filter, e.Pod, e.Labels) {
+				replay = append(replay, e)
+			}
+		}
+		p.metrics.WatchPodReplayed.Inc(int64(len(replay)))
+	}
+
 	watchID := NewWatchID(ClientTypeTask)
-	p.taskClients[watchID] = &TaskClient{
-		Input: make(chan *pod.PodSummary, p.bufferSize),
+	c := &TaskClient{
+		// Make room for the replayed events so that the client is not
+		// aborted due to overflow before it starts receiving events
+		Input: make(chan *PodEvent, p.bufferSize+len(replay)),
 		// Make buffer size 1 so that sender is not blocked when sending
 		// the Signal
-		Signal: make(chan StopSignal, 1),
-		Filter: filter,
+		Signal:   make(chan StopSignal, 1),
+		Filter:   filter,
+		Revision: p.currentRevision(),
+	}
+	for _, e := range replay {
+		c.Input <- e
+	}
+	p.taskClients[watchID] = c
+
+	log.WithFields(log.Fields{
+		"watch_id":       watchID,
+		"start_revision": startRevision,
+		"replayed":       len(replay),
+	}).Info("task watch client created")
+	return watchID, c, nil
+}
+
+// getHistory returns the events in the history from startRevision
+// onwards, in revision order.
+func (p *watchProcessor) getHistory(startRevision uint64) ([]*PodEvent, error) {
+	head, tail := p.history.GetRange()
+	if startRevision < p.baseRevision+tail ||
+		startRevision > p.baseRevision+head {
+		p.metrics.WatchPodOutOfRange.Inc(1)
+		return nil, yarpcerrors.OutOfRangeErrorf(
+			"start revision %d is not in the range [%d, %d]",
+			startRevision, p.baseRevision+tail, p.baseRevision+head)
+	}
+
+	items, err := p.history.GetItemsByRange(startRevision-p.baseRevision, head)
+	if err != nil {
+		return nil, yarpcerrors.InternalErrorf(
+			"failed to read history: %v", err)
+	}
+
+	var events []*PodEvent
+	for _, item := range items {
+		events = append(events, item.Value.(*PodEvent))
+	}
+	return events, nil
+}
+
+// addHistory assigns the next revision to a pod event and appends it to
+// the history, evicting the oldest event if the history is full.
+func (p *watchProcessor) addHistory(
+	pod *pod.PodSummary,
+	podLabels []*peloton.Label,
+) *PodEvent {
+	head, tail := p.history.GetRange()
+	if int(head-tail) >= p.history.Capacity() {
+		p.history.MoveTail(tail + 1)
 	}
 
-	log.WithField("watch_id", watchID).Info("task watch client created")
-	return watchID, p.taskClients[watchID], nil
+	e := &PodEvent{
+		Revision: p.baseRevision + head,
+		Pod:      pod,
+		Labels:   podLabels,
+	}
+	p.history.AddItem(e)
+	return e
+}
+
+// resetHistory drops all events in the history. Revisions are based on
+// the current time so that they keep increasing across resets and job
+// manager leader changes, and a start revision from an earlier history
+// is never mistaken for one in the current history.
+func (p *watchProcessor) resetHistory() {
+	p.history = cirbuf.NewCircularBuffer(p.maxHistory)
+	p.baseRevision = uint64(time.Now().UnixNano())
 }
 
 // StopTaskClients stops all the task clients on job manager leader change
@@ -188,6 +351,10 @@ func (p *watchProcessor) StopTaskClients() {
 	for watchID := range p.taskClients {
 		p.stopTaskClient(watchID, StopSignalCancel)
 	}
+
+	// Events are not received while not being the leader, so the
+	// history can no longer be used to resume watches.
+	p.resetHistory()
 }
 
 // StopTaskClient stops a task watch client. Returns "not-found" error
@@ -232,74 +399,277 @@ func (p *watchProcessor) NotifyTaskChange(
 	defer p.Unlock()
 	sw.Stop()
 
+	e := p.addHistory(pod, podLabels)
+
 	for watchID, c := range p.taskClients {
-		filterCheckPass := true
+		if !matchPodFilter(c.Filter, pod, podLabels) {
+			continue
+		}
+
+		select {
+		case c.Input <- e:
+		default:
+			log.WithField("watch_id", watchID).
+				Warn("event overflow for task watch client")
+			p.stopTaskClient(watchID, StopSignalOverflow)
+		}
+	}
+}
 
-		if c.Filter != nil {
+// NewJobClient creates a new watch client for stateless job and workflow
+// changes. Returns the watch id and a new instance of JobClient.
+func (p *watchProcessor) NewJobClient(
+	filter *watch.StatelessJobFilter,
+) (string, *JobClient, error) {
+	sw := p.metrics.ProcessorLockDuration.Start()
+	p.Lock()
+	defer p.Unlock()
+	sw.Stop()
 
-			// Check the job ID filter
-			if c.Filter.GetJobId() != nil {
-				jobID, _, err := util.ParseTaskID(pod.GetPodName().GetValue())
-				if err != nil {
-					// Cannot parse podName to match the jobID, assume that
-					// filter does not match.
-					filterCheckPass = false
-				}
+	if len(p.jobClients) >= p.maxClient {
+		return "", nil, yarpcerrors.ResourceExhaustedErrorf("max client reached")
+	}
 
-				if jobID != c.Filter.GetJobId().GetValue() {
-					// job id filter did not match
-					filterCheckPass = false
-				}
+	watchID := NewWatchID(ClientTypeJob)
+	p.jobClients[watchID] = &JobClient{
+		Input: make(chan *JobEvent, p.bufferSize),
+		// Make buffer size 1 so that sender is not blocked when sending
+		// the Signal
+		Signal:   make(chan StopSignal, 1),
+		Filter:   filter,
+		Revision: p.currentRevision(),
+	}
 
-				// check the podname filter next
-				if filterCheckPass == true && len(c.Filter.GetPodNames()) > 0 {
-					found := false
-					for _, podName := range c.Filter.GetPodNames() {
-						if podName.GetValue() == pod.GetPodName().GetValue() {
-							found = true
-							break
-						}
-					}
-					if found == false {
-						// pod name filter did not match
-						filterCheckPass = false
-					}
-				}
-			}
+	log.WithField("watch_id", watchID).Info("job watch client created")
+	return watchID, p.jobClients[watchID], nil
+}
 
-			if filterCheckPass == false {
-				continue
-			}
+// currentRevision returns the revision of the most recent pod event.
+func (p *watchProcessor) currentRevision() uint64 {
+	head, _ := p.history.GetRange()
+	return p.baseRevision + head - 1
+}
 
-			// Check the pod label filter next
-			for _, labelFilter := range c.Filter.GetLabels() {
-				found := false
-				for _, labelPod := range podLabels {
-					if labelFilter.GetKey() == labelPod.GetKey() &&
-						labelFilter.GetValue() == labelPod.GetValue() {
-						found = true
-						break
-					}
-				}
+// StopJobClients stops all the job clients on job manager leader change
+func (p *watchProcessor) StopJobClients() {
+	p.Lock()
+	defer p.Unlock()
 
-				if found == false {
-					// label filter did not match
-					filterCheckPass = false
-					break
-				}
-			}
-		}
+	for watchID := range p.jobClients {
+		p.stopJobClient(watchID, StopSignalCancel)
+	}
+
+	// Changes are not received while not being the leader, so the job
+	// states may be stale after the next leadership change.
+	p.jobs = make(map[string]*jobState)
+}
+
+// StopJobClient stops a job watch client. Returns "not-found" error
+// if the corresponding watch client is not found.
+func (p *watchProcessor) StopJobClient(watchID string) error {
+	sw := p.metrics.ProcessorLockDuration.Start()
+	p.Lock()
+	defer p.Unlock()
+	sw.Stop()
 
-		if filterCheckPass == false {
+	return p.stopJobClient(watchID, StopSignalCancel)
+}
+
+func (p *watchProcessor) stopJobClient(
+	watchID string,
+	Signal StopSignal,
+) error {
+	c, ok := p.jobClients[watchID]
+	if !ok {
+		return yarpcerrors.NotFoundErrorf(
+			"watch_id %s not exist for job watch client", watchID)
+	}
+
+	log.WithFields(log.Fields{
+		"watch_id": watchID,
+		"signal":   Signal,
+	}).Info("stopping job watch client")
+
+	c.Signal <- Signal
+	delete(p.jobClients, watchID)
+
+	return nil
+}
+
+// NotifyJobChange receives the runtime of a stateless job, and notifies
+// all the clients which are interested in the job.
+func (p *watchProcessor) NotifyJobChange(
+	jobID *peloton.JobID,
+	runtime *job.RuntimeInfo,
+) {
+	sw := p.metrics.ProcessorLockDuration.Start()
+	p.Lock()
+	defer p.Unlock()
+	sw.Stop()
+
+	s, ok := p.jobs[jobID.GetValue()]
+	if !ok {
+		s = &jobState{}
+		p.jobs[jobID.GetValue()] = s
+	}
+
+	s.runtime = runtime
+	if s.updateInfo.GetUpdateID().GetValue() !=
+		runtime.GetUpdateID().GetValue() {
+		// the workflow is replaced by a new one whose state
+		// will be received separately
+		s.updateInfo = nil
+	}
+
+	// A terminated job is not tracked until it is started again, which
+	// bounds the number of job states to the number of active jobs.
+	if util.IsPelotonJobStateTerminal(runtime.GetState()) {
+		delete(p.jobs, jobID.GetValue())
+	}
+
+	p.notifyJobClients(jobID, s)
+}
+
+// NotifyWorkflowChange receives the state and progress of a job workflow,
+// and notifies all the clients which are interested in the job.
+func (p *watchProcessor) NotifyWorkflowChange(
+	jobID *peloton.JobID,
+	updateInfo *models.UpdateModel,
+) {
+	sw := p.metrics.ProcessorLockDuration.Start()
+	p.Lock()
+	defer p.Unlock()
+	sw.Stop()
+
+	s, ok := p.jobs[jobID.GetValue()]
+	if !ok {
+		// Either not a stateless job, or the runtime of the job has not
+		// been received since the job manager became leader, in which
+		// case the workflow version cannot be determined.
+		log.WithField("job_id", jobID.GetValue()).
+			Debug("skip workflow change due to unknown job runtime")
+		return
+	}
+
+	// Workflow progress is written periodically even if it has not
+	// changed, only notify the clients about actual changes.
+	if proto.Equal(s.updateInfo, updateInfo) {
+		return
+	}
+
+	s.updateInfo = updateInfo
+	p.notifyJobClients(jobID, s)
+}
+
+// notifyJobClients sends the job status to all the clients which are
+// interested in the job. It must be called with lock held.
+func (p *watchProcessor) notifyJobClients(
+	jobID *peloton.JobID,
+	s *jobState,
+) {
+	if len(p.jobClients) == 0 {
+		return
+	}
+
+	e := &JobEvent{
+		Revision: p.currentRevision(),
+		Job: &stateless.JobSummary{
+			JobId: &v1alphapeloton.JobID{Value: jobID.GetValue()},
+			Status: handlerutil.ConvertRuntimeInfoToJobStatus(
+				s.runtime,
+				s.updateInfo,
+			),
+		},
+	}
+
+	for watchID, c := range p.jobClients {
+		if !matchJobFilter(c.Filter, jobID) {
 			continue
 		}
 
 		select {
-		case c.Input <- pod:
+		case c.Input <- e:
 		default:
 			log.WithField("watch_id", watchID).
-				Warn("event overflow for task watch client")
-			p.stopTaskClient(watchID, StopSignalOverflow)
+				Warn("event overflow for job watch client")
+			p.stopJobClient(watchID, StopSignalOverflow)
+		}
+	}
+}
+
+// matchJobFilter returns true if the job passes the filter of a job
+// watch client.
+func matchJobFilter(
+	filter *watch.StatelessJobFilter,
+	jobID *peloton.JobID,
+) bool {
+	if len(filter.GetJobIds()) == 0 {
+		return true
+	}
+
+	for _, id := range filter.GetJobIds() {
+		if id.GetValue() == jobID.GetValue() {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPodFilter returns true if the pod passes the filter of
+// a task watch client.
+func matchPodFilter(
+	filter *watch.PodFilter,
+	pod *pod.PodSummary,
+	podLabels []*peloton.Label) bool {
+	if filter == nil {
+		return true
+	}
+
+	// Check the job ID filter
+	if filter.GetJobId() != nil {
+		jobID, _, err := util.ParseTaskID(pod.GetPodName().GetValue())
+		if err != nil {
+			// Cannot parse podName to match the jobID, assume that
+			// filter does not match.
+			return false
+		}
+
+		if jobID != filter.GetJobId().GetValue() {
+			// job id filter did not match
+			return false
+		}
+
+		// check the podname filter next
+		if len(filter.GetPodNames()) > 0 {
+			found := false
+			for _, podName := range filter.GetPodNames() {
+				if podName.GetValue() == pod.GetPodName().GetValue() {
+					found = true
+					break
+				}
+			}
+			if !found {
+				// pod name filter did not match
+				return false
+			}
+		}
+	}
+
+	// Check the pod label filter next
+	for _, labelFilter := range filter.GetLabels() {
+		found := false
+		for _, labelPod := range podLabels {
+			if labelFilter.GetKey() == labelPod.GetKey() &&
+				labelFilter.GetValue() == labelPod.GetValue() {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			// label filter did not match
+			return false
 		}
 	}
+	return true
 }
@@ -21,13 +21,18 @@ import (
 	"testing"
 	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
 	v0peloton "github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/update"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
 	"github.com/uber/peloton/.gen/peloton/api/v1alpha/watch"
+	"github.com/uber/peloton/.gen/peloton/private/models"
 
 	handlerutil "github.com/uber/peloton/pkg/jobmgr/util/handler"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
@@ -75,7 +80,7 @@ func (suite *WatchProcessorTestSuite) TestInitWatchProcessor() {
 
 // TestTaskClient tests basic setup and teardown of task watch client
 func (suite *WatchProcessorTestSuite) TestTaskClient() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -105,7 +110,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClient() {
 // TestTaskClient_StopNonexistentClient tests an error will be thrown if
 // tearing down a client with unknown watch id.
 func (suite *WatchProcessorTestSuite) TestTaskClient_StopNonexistentClient() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -117,12 +122,12 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_StopNonexistentClient() {
 
 // Test stop all clients on losing leadership
 func (suite *WatchProcessorTestSuite) TestTaskClient_StopAllClients() {
-	watchID1, c, err := suite.processor.NewTaskClient(nil)
+	watchID1, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID1)
 	suite.NotNil(c)
 
-	watchID2, c, err := suite.processor.NewTaskClient(nil)
+	watchID2, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID2)
 	suite.NotNil(c)
@@ -138,7 +143,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_StopAllClients() {
 // creating a new client if max number of clients is reached.
 func (suite *WatchProcessorTestSuite) TestTaskClient_MaxClientReached() {
 	for i := 0; i < 3; i++ {
-		watchID, c, err := suite.processor.NewTaskClient(nil)
+		watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 		if i < 2 {
 			suite.NoError(err)
 			suite.NotEmpty(watchID)
@@ -154,7 +159,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClient_MaxClientReached() {
 // sent to the client and the client will be closed if the client buffer is
 // overflown.
 func (suite *WatchProcessorTestSuite) TestTaskClient_EventOverflow() {
-	watchID, c, err := suite.processor.NewTaskClient(nil)
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -197,7 +202,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodFilter() {
 	wg.Add(1)
 	received := 0
 
-	watchID, c, err := suite.processor.NewTaskClient(filter)
+	watchID, c, err := suite.processor.NewTaskClient(filter, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -256,7 +261,7 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodLabelFilter() {
 	wg.Add(1)
 	received := 0
 
-	watchID, c, err := suite.processor.NewTaskClient(filter)
+	watchID, c, err := suite.processor.NewTaskClient(filter, 0)
 	suite.NoError(err)
 	suite.NotEmpty(watchID)
 	suite.NotNil(c)
@@ -298,3 +303,262 @@ func (suite *WatchProcessorTestSuite) TestTaskClientPodLabelFilter() {
 	suite.Equal(2, received)
 	mutex.Unlock()
 }
+
+// TestTaskClient_StartRevision tests that the events since the start revision
+// are replayed to a new client, and out-of-range error is returned if the
+// start revision is not in the history.
+func (suite *WatchProcessorTestSuite) TestTaskClient_StartRevision() {
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
+	suite.NoError(err)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+	first := c.Revision + 1
+
+	for i := 0; i < 3; i++ {
+		suite.processor.NotifyTaskChange(&pod.PodSummary{
+			PodName: &peloton.PodName{
+				Value: fmt.Sprintf("%s-%d", suite.jobID, i),
+			},
+		}, nil)
+	}
+
+	// resume after the first event
+	watchID, c, err = suite.processor.NewTaskClient(nil, first+1)
+	suite.NoError(err)
+	suite.Len(c.Input, 2)
+	for i := 1; i < 3; i++ {
+		e := <-c.Input
+		suite.Equal(first+uint64(i), e.Revision)
+		suite.Equal(
+			fmt.Sprintf("%s-%d", suite.jobID, i),
+			e.Pod.GetPodName().GetValue())
+	}
+	suite.Equal(first+2, c.Revision)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+
+	// only events which pass the filter are replayed
+	watchID, c, err = suite.processor.NewTaskClient(&watch.PodFilter{
+		JobId: suite.jobID,
+		PodNames: []*peloton.PodName{
+			{Value: fmt.Sprintf("%s-%d", suite.jobID, 0)},
+		},
+	}, first)
+	suite.NoError(err)
+	suite.Len(c.Input, 1)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+
+	// already caught up
+	watchID, c, err = suite.processor.NewTaskClient(nil, first+3)
+	suite.NoError(err)
+	suite.Len(c.Input, 0)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+
+	// revision in the future
+	_, _, err = suite.processor.NewTaskClient(nil, first+4)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+
+	// history is cleared on leader change
+	suite.processor.StopTaskClients()
+	_, _, err = suite.processor.NewTaskClient(nil, first+1)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+}
+
+// TestTaskClient_StartRevisionEvicted tests that out-of-range error is
+// returned if the start revision has been evicted from the history.
+func (suite *WatchProcessorTestSuite) TestTaskClient_StartRevisionEvicted() {
+	suite.config.MaxHistory = 2
+	suite.processor = newWatchProcessor(suite.config, suite.testScope)
+
+	watchID, c, err := suite.processor.NewTaskClient(nil, 0)
+	suite.NoError(err)
+	suite.NoError(suite.processor.StopTaskClient(watchID))
+	first := c.Revision + 1
+
+	for i := 0; i < 3; i++ {
+		suite.processor.NotifyTaskChange(&pod.PodSummary{}, nil)
+	}
+
+	_, _, err = suite.processor.NewTaskClient(nil, first)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsOutOfRange(err))
+
+	watchID, c, err = suite.processor.NewTaskClient(nil, first+1)
+	suite.NoError(err)
+	suite.Len(c.Input, 2)
+}
+
+// TestJobClient tests basic setup and teardown of job watch client
+func (suite *WatchProcessorTestSuite) TestJobClient() {
+	watchID, c, err := suite.processor.NewJobClient(nil)
+	suite.NoError(err)
+	suite.NotEmpty(watchID)
+	suite.NotNil(c)
+
+	err = suite.processor.StopJobClient(watchID)
+	suite.NoError(err)
+	suite.Equal(StopSignalCancel, <-c.Signal)
+
+	// already stopped
+	err = suite.processor.StopJobClient(watchID)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestJobClient_MaxClientReached tests an error will be thrown when
+// creating a new job client if max number of clients is reached.
+func (suite *WatchProcessorTestSuite) TestJobClient_MaxClientReached() {
+	for i := 0; i < 2; i++ {
+		_, _, err := suite.processor.NewJobClient(nil)
+		suite.NoError(err)
+	}
+
+	_, _, err := suite.processor.NewJobClient(nil)
+	suite.Error(err)
+	suite.True(yarpcerrors.IsResourceExhausted(err))
+}
+
+// TestJobClient_StopAllClients tests all job clients are stopped, and the
+// job states are dropped on losing leadership
+func (suite *WatchProcessorTestSuite) TestJobClient_StopAllClients() {
+	jobID := &v0peloton.JobID{Value: suite.jobID.GetValue()}
+	suite.processor.NotifyJobChange(jobID, &job.RuntimeInfo{
+		State: job.JobState_RUNNING,
+	})
+
+	watchID, c, err := suite.processor.NewJobClient(nil)
+	suite.NoError(err)
+
+	suite.processor.StopJobClients()
+	suite.Equal(StopSignalCancel, <-c.Signal)
+	suite.Error(suite.processor.StopJobClient(watchID))
+
+	// workflow changes are dropped until the job runtime is known again
+	_, c, err = suite.processor.NewJobClient(nil)
+	suite.NoError(err)
+	suite.processor.NotifyWorkflowChange(jobID, &models.UpdateModel{
+		State: update.State_ROLLING_FORWARD,
+	})
+	suite.Len(c.Input, 0)
+}
+
+// TestJobClient_EventOverflow tests that a "overflow" stop Signal will be
+// sent to the job client and the client will be closed if the client
+// buffer is overflown.
+func (suite *WatchProcessorTestSuite) TestJobClient_EventOverflow() {
+	watchID, c, err := suite.processor.NewJobClient(nil)
+	suite.NoError(err)
+
+	jobID := &v0peloton.JobID{Value: suite.jobID.GetValue()}
+	for i := 0; i < 10; i++ {
+		suite.processor.NotifyJobChange(jobID, &job.RuntimeInfo{})
+	}
+	suite.Len(c.Signal, 0)
+
+	suite.processor.NotifyJobChange(jobID, &job.RuntimeInfo{})
+	suite.Equal(StopSignalOverflow, <-c.Signal)
+	suite.Error(suite.processor.StopJobClient(watchID))
+}
+
+// TestJobClientJobFilter tests that job clients only receive the changes
+// of the jobs in the filter
+func (suite *WatchProcessorTestSuite) TestJobClientJobFilter() {
+	_, c1, err := suite.processor.NewJobClient(&watch.StatelessJobFilter{
+		JobIds: []*peloton.JobID{suite.jobID},
+	})
+	suite.NoError(err)
+	_, c2, err := suite.processor.NewJobClient(&watch.StatelessJobFilter{})
+	suite.NoError(err)
+
+	suite.processor.NotifyJobChange(
+		&v0peloton.JobID{Value: suite.jobID.GetValue()},
+		&job.RuntimeInfo{State: job.JobState_RUNNING},
+	)
+	suite.processor.NotifyJobChange(
+		&v0peloton.JobID{Value: uuid.NewRandom().String()},
+		&job.RuntimeInfo{State: job.JobState_RUNNING},
+	)
+
+	suite.Len(c1.Input, 1)
+	e := <-c1.Input
+	suite.Equal(suite.jobID.GetValue(), e.Job.GetJobId().GetValue())
+	suite.Equal(
+		stateless.JobState_JOB_STATE_RUNNING,
+		e.Job.GetStatus().GetState())
+	suite.Len(c2.Input, 2)
+}
+
+// TestJobClientWorkflowChange tests that workflow changes are sent to job
+// clients along with the last known job runtime, and that unchanged
+// workflow progress is not sent again
+func (suite *WatchProcessorTestSuite) TestJobClientWorkflowChange() {
+	_, c, err := suite.processor.NewJobClient(nil)
+	suite.NoError(err)
+
+	jobID := &v0peloton.JobID{Value: suite.jobID.GetValue()}
+	updateID := &v0peloton.UpdateID{Value: uuid.NewRandom().String()}
+
+	// workflow change of a job whose runtime is not known is dropped
+	suite.processor.NotifyWorkflowChange(jobID, &models.UpdateModel{
+		UpdateID: updateID,
+		State:    update.State_INITIALIZED,
+	})
+	suite.Len(c.Input, 0)
+
+	suite.processor.NotifyJobChange(jobID, &job.RuntimeInfo{
+		State:                job.JobState_RUNNING,
+		UpdateID:             updateID,
+		ConfigurationVersion: 2,
+		DesiredStateVersion:  1,
+		WorkflowVersion:      3,
+	})
+	e := <-c.Input
+	suite.Nil(e.Job.GetStatus().GetWorkflowStatus())
+
+	updateInfo := &models.UpdateModel{
+		UpdateID:             updateID,
+		Type:                 models.WorkflowType_UPDATE,
+		State:                update.State_ROLLING_FORWARD,
+		JobConfigVersion:     2,
+		PrevJobConfigVersion: 1,
+		InstancesTotal:       3,
+		InstancesDone:        1,
+	}
+	suite.processor.NotifyWorkflowChange(jobID, updateInfo)
+	e = <-c.Input
+	workflow := e.Job.GetStatus().GetWorkflowStatus()
+	suite.Equal(
+		stateless.WorkflowState_WORKFLOW_STATE_ROLLING_FORWARD,
+		workflow.GetState())
+	suite.Equal(uint32(1), workflow.GetNumInstancesCompleted())
+	suite.Equal(uint32(2), workflow.GetNumInstancesRemaining())
+	suite.Equal("2-1-3", workflow.GetVersion().GetValue())
+	suite.Equal("1-1-3", workflow.GetPrevVersion().GetValue())
+
+	// progress is not changed
+	suite.processor.NotifyWorkflowChange(jobID, proto.Clone(updateInfo).(*models.UpdateModel))
+	suite.Len(c.Input, 0)
+
+	// the workflow is kept on runtime change of the same workflow
+	suite.processor.NotifyJobChange(jobID, &job.RuntimeInfo{
+		State:    job.JobState_RUNNING,
+		UpdateID: updateID,
+	})
+	e = <-c.Input
+	suite.NotNil(e.Job.GetStatus().GetWorkflowStatus())
+
+	// the workflow is dropped once the job is terminated
+	suite.processor.NotifyJobChange(jobID, &job.RuntimeInfo{
+		State:    job.JobState_KILLED,
+		UpdateID: updateID,
+	})
+	e = <-c.Input
+	suite.Equal(
+		stateless.JobState_JOB_STATE_KILLED,
+		e.Job.GetStatus().GetState())
+	suite.processor.NotifyWorkflowChange(jobID, &models.UpdateModel{
+		UpdateID: updateID,
+		State:    update.State_ABORTED,
+	})
+	suite.Len(c.Input, 0)
+}
@@ -0,0 +1,503 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	updatesvc "github.com/uber/peloton/.gen/peloton/api/v0/update/svc"
+	volumesvc "github.com/uber/peloton/.gen/peloton/api/v0/volume/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	v1alphahostsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/host/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+	v1alpharespool "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool"
+	v1alpharespoolsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool/svc"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common/util"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/encoding/protobuf"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_statelessJobService = "peloton.api.v1alpha.job.stateless.svc.JobService::"
+	_podService          = "peloton.api.v1alpha.pod.svc.PodService::"
+	_respoolService      = "peloton.api.v1alpha.respool.svc.ResourcePoolService::"
+	_jobManager          = "peloton.api.v0.job.JobManager::"
+	_taskManager         = "peloton.api.v0.task.TaskManager::"
+	_updateService       = "peloton.api.v0.update.svc.UpdateService::"
+	_volumeService       = "peloton.api.v0.volume.svc.VolumeService::"
+	_respoolManager      = "peloton.api.v0.respool.ResourceManager::"
+	_hostService         = "peloton.api.v0.host.svc.HostService::"
+	_v1alphaHostService  = "peloton.api.v1alpha.host.svc.HostService::"
+	_cronService         = "peloton.api.v0.cron.svc.CronService::"
+	_dagService          = "peloton.api.v0.dag.svc.DagService::"
+
+	// _auditResultOK is the result recorded for calls which succeeded
+	_auditResultOK = "OK"
+
+	// _auditWriteTimeout is the timeout to write an audit record
+	_auditWriteTimeout = 2 * time.Second
+)
+
+// _auditedProcedures maps the mutating procedures of Peloton APIs to a
+// constructor of their request message. Calls to these procedures are
+// recorded in the audit log.
+var _auditedProcedures = map[string]func() proto.Message{
+	_statelessJobService + "CreateJob":          func() proto.Message { return &statelesssvc.CreateJobRequest{} },
+	_statelessJobService + "ReplaceJob":         func() proto.Message { return &statelesssvc.ReplaceJobRequest{} },
+	_statelessJobService + "PatchJob":           func() proto.Message { return &statelesssvc.PatchJobRequest{} },
+	_statelessJobService + "RestartJob":         func() proto.Message { return &statelesssvc.RestartJobRequest{} },
+	_statelessJobService + "PauseJobWorkflow":   func() proto.Message { return &statelesssvc.PauseJobWorkflowRequest{} },
+	_statelessJobService + "ResumeJobWorkflow":  func() proto.Message { return &statelesssvc.ResumeJobWorkflowRequest{} },
+	_statelessJobService + "AbortJobWorkflow":   func() proto.Message { return &statelesssvc.AbortJobWorkflowRequest{} },
+	_statelessJobService + "StartJob":           func() proto.Message { return &statelesssvc.StartJobRequest{} },
+	_statelessJobService + "StopJob":            func() proto.Message { return &statelesssvc.StopJobRequest{} },
+	_statelessJobService + "DeleteJob":          func() proto.Message { return &statelesssvc.DeleteJobRequest{} },
+	_podService + "StartPod":                    func() proto.Message { return &podsvc.StartPodRequest{} },
+	_podService + "StopPod":                     func() proto.Message { return &podsvc.StopPodRequest{} },
+	_podService + "RestartPod":                  func() proto.Message { return &podsvc.RestartPodRequest{} },
+	_podService + "DeletePodEvents":             func() proto.Message { return &podsvc.DeletePodEventsRequest{} },
+	_respoolService + "CreateResourcePool":      func() proto.Message { return &v1alpharespoolsvc.CreateResourcePoolRequest{} },
+	_respoolService + "UpdateResourcePool":      func() proto.Message { return &v1alpharespoolsvc.UpdateResourcePoolRequest{} },
+	_respoolService + "DeleteResourcePool":      func() proto.Message { return &v1alpharespoolsvc.DeleteResourcePoolRequest{} },
+	_jobManager + "Create":                      func() proto.Message { return &job.CreateRequest{} },
+	_jobManager + "Update":                      func() proto.Message { return &job.UpdateRequest{} },
+	_jobManager + "Delete":                      func() proto.Message { return &job.DeleteRequest{} },
+	_jobManager + "Restart":                     func() proto.Message { return &job.RestartRequest{} },
+	_jobManager + "Start":                       func() proto.Message { return &job.StartRequest{} },
+	_jobManager + "Stop":                        func() proto.Message { return &job.StopRequest{} },
+	_taskManager + "Start":                      func() proto.Message { return &task.StartRequest{} },
+	_taskManager + "Stop":                       func() proto.Message { return &task.StopRequest{} },
+	_taskManager + "Restart":                    func() proto.Message { return &task.RestartRequest{} },
+	_updateService + "CreateUpdate":             func() proto.Message { return &updatesvc.CreateUpdateRequest{} },
+	_updateService + "PauseUpdate":              func() proto.Message { return &updatesvc.PauseUpdateRequest{} },
+	_updateService + "ResumeUpdate":             func() proto.Message { return &updatesvc.ResumeUpdateRequest{} },
+	_updateService + "RollbackUpdate":           func() proto.Message { return &updatesvc.RollbackUpdateRequest{} },
+	_updateService + "AbortUpdate":              func() proto.Message { return &updatesvc.AbortUpdateRequest{} },
+	_volumeService + "DeleteVolume":             func() proto.Message { return &volumesvc.DeleteVolumeRequest{} },
+	_respoolManager + "CreateResourcePool":      func() proto.Message { return &respool.CreateRequest{} },
+	_respoolManager + "UpdateResourcePool":      func() proto.Message { return &respool.UpdateRequest{} },
+	_respoolManager + "DeleteResourcePool":      func() proto.Message { return &respool.DeleteRequest{} },
+	_hostService + "StartMaintenance":           func() proto.Message { return &svc.StartMaintenanceRequest{} },
+	_hostService + "CompleteMaintenance":        func() proto.Message { return &svc.CompleteMaintenanceRequest{} },
+	_hostService + "ScheduleMaintenance":        func() proto.Message { return &svc.ScheduleMaintenanceRequest{} },
+	_hostService + "CancelMaintenanceWindow":    func() proto.Message { return &svc.CancelMaintenanceWindowRequest{} },
+	_v1alphaHostService + "StartMaintenance":    func() proto.Message { return &v1alphahostsvc.StartMaintenanceRequest{} },
+	_v1alphaHostService + "CompleteMaintenance": func() proto.Message { return &v1alphahostsvc.CompleteMaintenanceRequest{} },
+	_cronService + "CreateCronJob":              func() proto.Message { return &cronsvc.CreateCronJobRequest{} },
+	_cronService + "ReplaceCronJob":             func() proto.Message { return &cronsvc.ReplaceCronJobRequest{} },
+	_cronService + "DeleteCronJob":              func() proto.Message { return &cronsvc.DeleteCronJobRequest{} },
+	_cronService + "StartCronJob":               func() proto.Message { return &cronsvc.StartCronJobRequest{} },
+	_dagService + "SubmitDag":                   func() proto.Message { return &dagsvc.SubmitDagRequest{} },
+	_dagService + "CancelDag":                   func() proto.Message { return &dagsvc.CancelDagRequest{} },
+}
+
+// _v0ErrorResponses maps the audited procedures of v0 APIs which return
+// errors in the response body, instead of as a yarpc error, to a
+// constructor of their response message.
+var _v0ErrorResponses = map[string]func() proto.Message{
+	_jobManager + "Create":                 func() proto.Message { return &job.CreateResponse{} },
+	_jobManager + "Update":                 func() proto.Message { return &job.UpdateResponse{} },
+	_jobManager + "Delete":                 func() proto.Message { return &job.DeleteResponse{} },
+	_taskManager + "Start":                 func() proto.Message { return &task.StartResponse{} },
+	_taskManager + "Stop":                  func() proto.Message { return &task.StopResponse{} },
+	_taskManager + "Restart":               func() proto.Message { return &task.RestartResponse{} },
+	_respoolManager + "CreateResourcePool": func() proto.Message { return &respool.CreateResponse{} },
+	_respoolManager + "UpdateResourcePool": func() proto.Message { return &respool.UpdateResponse{} },
+	_respoolManager + "DeleteResourcePool": func() proto.Message { return &respool.DeleteResponse{} },
+}
+
+// AuditInboundMiddleware is the inbound middleware which records the calls
+// to mutating procedures of Peloton APIs in the audit log. It must be
+// invoked after AuthInboundMiddleware, so that the caller is known.
+// Recording is best effort, a call does not fail if its record cannot
+// be written.
+type AuditInboundMiddleware struct {
+	// component is the name of the Peloton component handling the calls
+	component   string
+	auditLogOps ormobjects.AuditLogOps
+
+	// now returns the current time, overridden in tests
+	now func() time.Time
+}
+
+// NewAuditInboundMiddleware returns AuditInboundMiddleware which records
+// the calls handled by the component
+func NewAuditInboundMiddleware(
+	component string,
+	auditLogOps ormobjects.AuditLogOps,
+) *AuditInboundMiddleware {
+	return &AuditInboundMiddleware{
+		component:   component,
+		auditLogOps: auditLogOps,
+		now:         time.Now,
+	}
+}
+
+// Handle invokes the underlying handler and records the call
+// if the procedure is mutating
+func (m *AuditInboundMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
+	newRequest, ok := _auditedProcedures[req.Procedure]
+	if !ok {
+		return h.Handle(ctx, req, resw)
+	}
+
+	body, err := bufferBody(req)
+	if err != nil {
+		return err
+	}
+
+	// buffer the response of v0 procedures to find the errors in it
+	var recorder *responseRecorder
+	if _, ok := _v0ErrorResponses[req.Procedure]; ok && resw != nil {
+		recorder = &responseRecorder{ResponseWriter: resw}
+		resw = recorder
+	}
+
+	start := m.now()
+	err = h.Handle(ctx, req, resw)
+	m.record(ctx, req, newRequest(), body, recorder.Bytes(), start, err)
+	return err
+}
+
+// HandleOneway invokes the underlying handler and records the call
+// if the procedure is mutating
+func (m *AuditInboundMiddleware) HandleOneway(ctx context.Context, req *transport.Request, h transport.OnewayHandler) error {
+	newRequest, ok := _auditedProcedures[req.Procedure]
+	if !ok {
+		return h.HandleOneway(ctx, req)
+	}
+
+	body, err := bufferBody(req)
+	if err != nil {
+		return err
+	}
+
+	start := m.now()
+	err = h.HandleOneway(ctx, req)
+	m.record(ctx, req, newRequest(), body, nil, start, err)
+	return err
+}
+
+// record writes the audit record of a call
+func (m *AuditInboundMiddleware) record(
+	ctx context.Context,
+	req *transport.Request,
+	request proto.Message,
+	body []byte,
+	responseBody []byte,
+	start time.Time,
+	handlerErr error,
+) {
+	record := &audit.AuditRecord{
+		Time:      start.UTC().Format(time.RFC3339Nano),
+		Component: m.component,
+		Procedure: req.Procedure,
+		Result:    _auditResultOK,
+		LatencyMs: uint64(m.now().Sub(start) / time.Millisecond),
+	}
+
+	if user := auth.UserFromContext(ctx); user != nil {
+		record.Username = user.GetUsername()
+	}
+
+	if handlerErr != nil {
+		status := yarpcerrors.FromError(handlerErr)
+		record.Result = status.Code().String()
+		record.Message = status.Message()
+	} else if newResponse, ok := _v0ErrorResponses[req.Procedure]; ok {
+		response := newResponse()
+		if err := UnmarshalRequest(req.Encoding, responseBody, response); err != nil {
+			record.Result = yarpcerrors.CodeUnknown.String()
+			record.Message = "failed to decode response"
+		} else if responseErr := v0ResponseError(response); responseErr != nil {
+			record.Result = yarpcerrors.CodeUnknown.String()
+			record.Message = proto.CompactTextString(responseErr)
+		}
+	}
+
+	if err := UnmarshalRequest(req.Encoding, body, request); err != nil {
+		log.WithError(err).
+			WithField("procedure", req.Procedure).
+			Debug("failed to decode request for audit log")
+	} else {
+		var jobID string
+		jobID, record.Summary = summarize(request)
+		if len(jobID) != 0 {
+			record.JobId = &v1alphapeloton.JobID{Value: jobID}
+		}
+	}
+
+	// the call may have used up the deadline of its context
+	writeCtx, cancel := context.WithTimeout(context.Background(), _auditWriteTimeout)
+	defer cancel()
+
+	if err := m.auditLogOps.Create(writeCtx, record); err != nil {
+		log.WithError(err).
+			WithFields(log.Fields{
+				"procedure": record.GetProcedure(),
+				"username":  record.GetUsername(),
+				"summary":   record.GetSummary(),
+				"result":    record.GetResult(),
+			}).
+			Warn("failed to write audit record")
+	}
+}
+
+// v0ResponseError returns the error in the response of a v0 procedure,
+// or nil if the call succeeded
+func v0ResponseError(response proto.Message) proto.Message {
+	switch r := response.(type) {
+	case *job.CreateResponse:
+		if r.GetError() != nil {
+			return r.GetError()
+		}
+	case *job.UpdateResponse:
+		if r.GetError() != nil {
+			return r.GetError()
+		}
+	case *job.DeleteResponse:
+		if r.GetError() != nil {
+			return r.GetError()
+		}
+	case *task.StartResponse:
+		if r.GetError() != nil {
+			return r.GetError()
+		}
+	case *task.StopResponse:
+		if r.GetError() != nil {
+			return r.GetError()
+		}
+	case *task.RestartResponse:
+		if r.GetNotFound() != nil || r.GetOutOfRange() != nil {
+			return r
+		}
+	case *respool.CreateResponse:
+		if r.GetError() != nil {
+			return r.GetError()
+		}
+	case *respool.UpdateResponse:
+		if r.GetError() != nil {
+			return r.GetError()
+		}
+	case *respool.DeleteResponse:
+		if r.GetError() != nil {
+			return r.GetError()
+		}
+	}
+	return nil
+}
+
+// summarize returns the job targeted by a request, and a summary of the
+// identifiers of the entities the request acts on. Only identifiers are
+// recorded, so that specs and secrets in requests never end up in the
+// audit log.
+func summarize(request proto.Message) (string, string) {
+	var jobID string
+	var fields []string
+	add := func(key string, value string) {
+		if len(value) != 0 {
+			fields = append(fields, key+"="+value)
+		}
+	}
+
+	if r, ok := request.(interface {
+		GetJobId() *v1alphapeloton.JobID
+	}); ok {
+		jobID = r.GetJobId().GetValue()
+	}
+	if r, ok := request.(interface{ GetJobId() *peloton.JobID }); ok {
+		jobID = r.GetJobId().GetValue()
+	}
+	if r, ok := request.(interface{ GetId() *peloton.JobID }); ok {
+		jobID = r.GetId().GetValue()
+	}
+	if r, ok := request.(interface {
+		GetPodName() *v1alphapeloton.PodName
+	}); ok {
+		podName := r.GetPodName().GetValue()
+		if id, _, err := util.ParseTaskID(podName); err == nil {
+			jobID = id
+		}
+		add("pod_name", podName)
+	}
+	add("job_id", jobID)
+
+	if r, ok := request.(interface{ GetSpec() *stateless.JobSpec }); ok {
+		add("job_name", r.GetSpec().GetName())
+		add("respool_id", r.GetSpec().GetRespoolId().GetValue())
+	}
+	if r, ok := request.(interface{ GetConfig() *job.JobConfig }); ok {
+		add("job_name", r.GetConfig().GetName())
+		add("respool_id", r.GetConfig().GetRespoolID().GetValue())
+	}
+	if r, ok := request.(interface {
+		GetVersion() *v1alphapeloton.EntityVersion
+	}); ok {
+		add("version", r.GetVersion().GetValue())
+	}
+	if r, ok := request.(interface {
+		GetRanges() []*task.InstanceRange
+	}); ok {
+		var ranges []string
+		for _, instanceRange := range r.GetRanges() {
+			ranges = append(ranges, fmt.Sprintf(
+				"%d-%d", instanceRange.GetFrom(), instanceRange.GetTo()))
+		}
+		add("instance_ranges", strings.Join(ranges, ","))
+	}
+	if r, ok := request.(interface{ GetUpdateId() *peloton.UpdateID }); ok {
+		add("update_id", r.GetUpdateId().GetValue())
+	}
+	if r, ok := request.(interface{ GetId() *peloton.VolumeID }); ok {
+		add("volume_id", r.GetId().GetValue())
+	}
+	if r, ok := request.(interface {
+		GetId() *peloton.ResourcePoolID
+	}); ok {
+		add("respool_id", r.GetId().GetValue())
+	}
+	if r, ok := request.(interface {
+		GetPath() *respool.ResourcePoolPath
+	}); ok {
+		add("respool_path", r.GetPath().GetValue())
+	}
+	if r, ok := request.(interface {
+		GetConfig() *respool.ResourcePoolConfig
+	}); ok {
+		add("respool_name", r.GetConfig().GetName())
+		add("respool_parent", r.GetConfig().GetParent().GetValue())
+	}
+	if r, ok := request.(interface {
+		GetRespoolId() *v1alphapeloton.ResourcePoolID
+	}); ok {
+		add("respool_id", r.GetRespoolId().GetValue())
+	}
+	if r, ok := request.(interface {
+		GetSpec() *v1alpharespool.ResourcePoolSpec
+	}); ok {
+		add("respool_name", r.GetSpec().GetName())
+		add("respool_parent", r.GetSpec().GetParent().GetValue())
+	}
+	if r, ok := request.(interface{ GetHostnames() []string }); ok {
+		add("hostnames", strings.Join(r.GetHostnames(), ","))
+	}
+	if r, ok := request.(interface{ GetWindowId() string }); ok {
+		add("window_id", r.GetWindowId())
+	}
+	if r, ok := request.(interface{ GetConfig() *cron.CronJobConfig }); ok {
+		add("cron_job", r.GetConfig().GetName())
+		add("job_name", r.GetConfig().GetJobConfig().GetName())
+		add("respool_id", r.GetConfig().GetJobConfig().GetRespoolID().GetValue())
+	}
+	switch r := request.(type) {
+	case *task.DeletePodEventsRequest:
+		add("instance_id", fmt.Sprint(r.GetInstanceId()))
+		add("run_id", fmt.Sprint(r.GetRunId()))
+	case *cronsvc.DeleteCronJobRequest:
+		add("cron_job", r.GetName())
+	case *cronsvc.StartCronJobRequest:
+		add("cron_job", r.GetName())
+	case *dagsvc.SubmitDagRequest:
+		add("dag_name", r.GetSpec().GetName())
+		if len(r.GetSpec().GetNodes()) > 0 {
+			add("respool_id",
+				r.GetSpec().GetNodes()[0].GetJobConfig().GetRespoolID().GetValue())
+		}
+	case *dagsvc.CancelDagRequest:
+		add("dag_id", r.GetId().GetValue())
+	}
+
+	return jobID, strings.Join(fields, " ")
+}
+
+// responseRecorder is a transport.ResponseWriter which keeps a copy of
+// the response body written by the handler
+type responseRecorder struct {
+	transport.ResponseWriter
+
+	body bytes.Buffer
+}
+
+// Write writes the response body and keeps a copy of it
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
+
+// Bytes returns the response body written so far
+func (r *responseRecorder) Bytes() []byte {
+	if r == nil {
+		return nil
+	}
+	return r.body.Bytes()
+}
+
+// bufferBody reads the body of a request, which can only be read once,
+// and replaces it for the underlying handler
+func bufferBody(req *transport.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, yarpcerrors.InternalErrorf(
+			"failed to read request body: %v", err)
+	}
+	req.Body = bytes.NewReader(body)
+	return body, nil
+}
+
+// UnmarshalRequest decodes a request body with the protobuf or json encoding
+func UnmarshalRequest(
+	encoding transport.Encoding,
+	body []byte,
+	request proto.Message,
+) error {
+	switch encoding {
+	case protobuf.Encoding:
+		return proto.Unmarshal(body, request)
+	case protobuf.JSONEncoding:
+		unmarshaler := &jsonpb.Unmarshaler{AllowUnknownFields: true}
+		return unmarshaler.Unmarshal(bytes.NewReader(body), request)
+	}
+
+	return errors.Errorf("unsupported encoding %s", encoding)
+}
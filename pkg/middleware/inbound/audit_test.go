@@ -0,0 +1,411 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	cronsvc "github.com/uber/peloton/.gen/peloton/api/v0/cron/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	dagsvc "github.com/uber/peloton/.gen/peloton/api/v0/dag/svc"
+	pberrors "github.com/uber/peloton/.gen/peloton/api/v0/errors"
+	hostsvc "github.com/uber/peloton/.gen/peloton/api/v0/host/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless"
+	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+	v1alpharespool "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool"
+	v1alpharespoolsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool/svc"
+
+	"github.com/uber/peloton/pkg/auth"
+	auth_mocks "github.com/uber/peloton/pkg/auth/mocks"
+	objectmocks "github.com/uber/peloton/pkg/storage/objects/mocks"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/api/transport/transporttest"
+	"go.uber.org/yarpc/encoding/protobuf"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type AuditInboundMiddlewareSuite struct {
+	suite.Suite
+
+	ctrl        *gomock.Controller
+	auditLogOps *objectmocks.MockAuditLogOps
+	u           *auth_mocks.MockUser
+	m           *AuditInboundMiddleware
+
+	start time.Time
+	jobID string
+}
+
+func (suite *AuditInboundMiddlewareSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.auditLogOps = objectmocks.NewMockAuditLogOps(suite.ctrl)
+	suite.u = auth_mocks.NewMockUser(suite.ctrl)
+	suite.m = NewAuditInboundMiddleware("peloton-jobmgr", suite.auditLogOps)
+
+	// each call to now advances the time by 10ms
+	suite.start = time.Date(2019, 1, 2, 1, 0, 0, 0, time.UTC)
+	now := suite.start
+	suite.m.now = func() time.Time {
+		t := now
+		now = now.Add(10 * time.Millisecond)
+		return t
+	}
+
+	suite.jobID = uuid.New()
+}
+
+func (suite *AuditInboundMiddlewareSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestAuditInboundMiddlewareSuite(t *testing.T) {
+	suite.Run(t, &AuditInboundMiddlewareSuite{})
+}
+
+// newRequest returns a protobuf encoded request to stop the job
+func (suite *AuditInboundMiddlewareSuite) newRequest() *transport.Request {
+	body, err := proto.Marshal(&statelesssvc.StopJobRequest{
+		JobId:   &v1alphapeloton.JobID{Value: suite.jobID},
+		Version: &v1alphapeloton.EntityVersion{Value: "1-0-0"},
+	})
+	suite.NoError(err)
+
+	return &transport.Request{
+		Service:   "peloton.api.v1alpha.job.stateless.svc.JobService",
+		Procedure: _statelessJobService + "StopJob",
+		Encoding:  protobuf.Encoding,
+		Body:      bytes.NewReader(body),
+	}
+}
+
+// expectedRecord returns the record of a call to stop the job
+func (suite *AuditInboundMiddlewareSuite) expectedRecord() *audit.AuditRecord {
+	return &audit.AuditRecord{
+		Time:      suite.start.Format(time.RFC3339Nano),
+		Component: "peloton-jobmgr",
+		Username:  "user1",
+		Procedure: _statelessJobService + "StopJob",
+		JobId:     &v1alphapeloton.JobID{Value: suite.jobID},
+		Summary:   "job_id=" + suite.jobID + " version=1-0-0",
+		Result:    _auditResultOK,
+		LatencyMs: 10,
+	}
+}
+
+// TestHandleSuccess tests recording a successful call
+func (suite *AuditInboundMiddlewareSuite) TestHandleSuccess() {
+	ctx := auth.ContextWithUser(context.Background(), suite.u)
+	suite.u.EXPECT().GetUsername().Return("user1")
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *transport.Request, _ transport.ResponseWriter) {
+			// the body is still available to the handler
+			body, err := ioutil.ReadAll(req.Body)
+			suite.NoError(err)
+			suite.NotEmpty(body)
+		}).
+		Return(nil)
+	suite.auditLogOps.EXPECT().
+		Create(gomock.Any(), suite.expectedRecord()).
+		Return(nil)
+
+	suite.NoError(suite.m.Handle(ctx, suite.newRequest(), nil, h))
+}
+
+// TestHandleFailure tests recording a failed call
+func (suite *AuditInboundMiddlewareSuite) TestHandleFailure() {
+	ctx := auth.ContextWithUser(context.Background(), suite.u)
+	suite.u.EXPECT().GetUsername().Return("user1")
+
+	expected := suite.expectedRecord()
+	expected.Result = yarpcerrors.CodeNotFound.String()
+	expected.Message = "job not found"
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(yarpcerrors.NotFoundErrorf("job not found"))
+	suite.auditLogOps.EXPECT().Create(gomock.Any(), expected).Return(nil)
+
+	err := suite.m.Handle(ctx, suite.newRequest(), nil, h)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestHandleWriteFailure tests that a call does not fail if its
+// record cannot be written
+func (suite *AuditInboundMiddlewareSuite) TestHandleWriteFailure() {
+	expected := suite.expectedRecord()
+	expected.Username = ""
+
+	h := transporttest.NewMockOnewayHandler(suite.ctrl)
+	h.EXPECT().HandleOneway(gomock.Any(), gomock.Any()).Return(nil)
+	suite.auditLogOps.EXPECT().
+		Create(gomock.Any(), expected).
+		Return(errors.New("test error"))
+
+	suite.NoError(suite.m.HandleOneway(
+		context.Background(), suite.newRequest(), h))
+}
+
+// TestHandleJSONEncoding tests recording a json encoded call
+func (suite *AuditInboundMiddlewareSuite) TestHandleJSONEncoding() {
+	marshaler := &jsonpb.Marshaler{}
+	body, err := marshaler.MarshalToString(&podsvc.StopPodRequest{
+		PodName: &v1alphapeloton.PodName{Value: suite.jobID + "-1"},
+	})
+	suite.NoError(err)
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.auditLogOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, record *audit.AuditRecord) {
+			suite.Equal(suite.jobID, record.GetJobId().GetValue())
+			suite.Equal(
+				"pod_name="+suite.jobID+"-1 job_id="+suite.jobID,
+				record.GetSummary())
+		}).
+		Return(nil)
+
+	suite.NoError(suite.m.Handle(context.Background(), &transport.Request{
+		Procedure: _podService + "StopPod",
+		Encoding:  protobuf.JSONEncoding,
+		Body:      bytes.NewReader([]byte(body)),
+	}, nil, h))
+}
+
+// TestHandleV0ResponseError tests recording a call to a v0 procedure
+// which returned an error in the response body
+func (suite *AuditInboundMiddlewareSuite) TestHandleV0ResponseError() {
+	body, err := proto.Marshal(&job.DeleteRequest{
+		Id: &peloton.JobID{Value: suite.jobID},
+	})
+	suite.NoError(err)
+
+	response, err := proto.Marshal(&job.DeleteResponse{
+		Error: &job.DeleteResponse_Error{
+			NotFound: &pberrors.JobNotFound{Message: "job not found"},
+		},
+	})
+	suite.NoError(err)
+
+	resw := &transporttest.FakeResponseWriter{}
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ *transport.Request, resw transport.ResponseWriter) {
+			_, err := resw.Write(response)
+			suite.NoError(err)
+		}).
+		Return(nil)
+	suite.auditLogOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, record *audit.AuditRecord) {
+			suite.Equal(yarpcerrors.CodeUnknown.String(), record.GetResult())
+			suite.Contains(record.GetMessage(), "job not found")
+		}).
+		Return(nil)
+
+	suite.NoError(suite.m.Handle(context.Background(), &transport.Request{
+		Procedure: _jobManager + "Delete",
+		Encoding:  protobuf.Encoding,
+		Body:      bytes.NewReader(body),
+	}, resw, h))
+
+	// the response is still written to the underlying writer
+	suite.Equal(response, resw.Body.Bytes())
+}
+
+// TestHandleV0ResponseSuccess tests recording a call to a v0 procedure
+// which returned no error in the response body
+func (suite *AuditInboundMiddlewareSuite) TestHandleV0ResponseSuccess() {
+	body, err := proto.Marshal(&task.StopRequest{
+		JobId: &peloton.JobID{Value: suite.jobID},
+	})
+	suite.NoError(err)
+
+	response, err := proto.Marshal(&task.StopResponse{
+		StoppedInstanceIds: []uint32{0, 1},
+	})
+	suite.NoError(err)
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ *transport.Request, resw transport.ResponseWriter) {
+			_, err := resw.Write(response)
+			suite.NoError(err)
+		}).
+		Return(nil)
+	suite.auditLogOps.EXPECT().
+		Create(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, record *audit.AuditRecord) {
+			suite.Equal(_auditResultOK, record.GetResult())
+			suite.Empty(record.GetMessage())
+		}).
+		Return(nil)
+
+	suite.NoError(suite.m.Handle(context.Background(), &transport.Request{
+		Procedure: _taskManager + "Stop",
+		Encoding:  protobuf.Encoding,
+		Body:      bytes.NewReader(body),
+	}, &transporttest.FakeResponseWriter{}, h))
+}
+
+// TestHandleNotAudited tests that calls to procedures which are not
+// mutating are not recorded
+func (suite *AuditInboundMiddlewareSuite) TestHandleNotAudited() {
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	suite.NoError(suite.m.Handle(context.Background(), &transport.Request{
+		Procedure: _statelessJobService + "GetJob",
+	}, nil, h))
+}
+
+// TestSummarize tests the summary of requests only contains
+// the identifiers of the entities they act on
+func (suite *AuditInboundMiddlewareSuite) TestSummarize() {
+	tests := []struct {
+		request proto.Message
+		jobID   string
+		summary string
+	}{
+		{
+			request: &statelesssvc.CreateJobRequest{
+				Spec: &stateless.JobSpec{
+					Name:      "job1",
+					RespoolId: &v1alphapeloton.ResourcePoolID{Value: "respool1"},
+				},
+			},
+			summary: "job_name=job1 respool_id=respool1",
+		},
+		{
+			request: &task.StopRequest{
+				JobId:  &peloton.JobID{Value: suite.jobID},
+				Ranges: []*task.InstanceRange{{From: 0, To: 2}, {From: 5, To: 6}},
+			},
+			jobID:   suite.jobID,
+			summary: "job_id=" + suite.jobID + " instance_ranges=0-2,5-6",
+		},
+		{
+			request: &respool.DeleteRequest{
+				Path: &respool.ResourcePoolPath{Value: "/team1"},
+			},
+			summary: "respool_path=/team1",
+		},
+		{
+			request: &respool.UpdateRequest{
+				Id: &peloton.ResourcePoolID{Value: "respool1"},
+				Config: &respool.ResourcePoolConfig{
+					Name:   "pool1",
+					Parent: &peloton.ResourcePoolID{Value: "root"},
+				},
+			},
+			summary: "respool_id=respool1 respool_name=pool1 respool_parent=root",
+		},
+		{
+			request: &v1alpharespoolsvc.CreateResourcePoolRequest{
+				Spec: &v1alpharespool.ResourcePoolSpec{
+					Name:   "pool1",
+					Parent: &v1alphapeloton.ResourcePoolID{Value: "root"},
+				},
+			},
+			summary: "respool_name=pool1 respool_parent=root",
+		},
+		{
+			request: &v1alpharespoolsvc.DeleteResourcePoolRequest{
+				RespoolId: &v1alphapeloton.ResourcePoolID{Value: "respool1"},
+			},
+			summary: "respool_id=respool1",
+		},
+		{
+			request: &task.DeletePodEventsRequest{
+				JobId:      &peloton.JobID{Value: suite.jobID},
+				InstanceId: 1,
+				RunId:      2,
+			},
+			jobID:   suite.jobID,
+			summary: "job_id=" + suite.jobID + " instance_id=1 run_id=2",
+		},
+		{
+			request: &hostsvc.ScheduleMaintenanceRequest{
+				Hostnames: []string{"host1", "host2"},
+				Deadline:  "2019-01-01T12:00:00Z",
+			},
+			summary: "hostnames=host1,host2",
+		},
+		{
+			request: &cronsvc.CreateCronJobRequest{
+				Config: &cron.CronJobConfig{
+					Name:     "nightly",
+					Schedule: "@daily",
+					JobConfig: &job.JobConfig{
+						Name:      "job1",
+						RespoolID: &peloton.ResourcePoolID{Value: "respool1"},
+					},
+				},
+			},
+			summary: "cron_job=nightly job_name=job1 respool_id=respool1",
+		},
+		{
+			request: &cronsvc.StartCronJobRequest{Name: "nightly"},
+			summary: "cron_job=nightly",
+		},
+		{
+			request: &dagsvc.SubmitDagRequest{
+				Spec: &dag.DagSpec{
+					Name: "etl",
+					Nodes: []*dag.Node{
+						{
+							Name: "extract",
+							JobConfig: &job.JobConfig{
+								RespoolID: &peloton.ResourcePoolID{Value: "respool1"},
+							},
+						},
+					},
+				},
+			},
+			summary: "dag_name=etl respool_id=respool1",
+		},
+		{
+			request: &dagsvc.CancelDagRequest{Id: &dag.DagID{Value: "dag1"}},
+			summary: "dag_id=dag1",
+		},
+	}
+
+	for _, test := range tests {
+		jobID, summary := summarize(test.request)
+		suite.Equal(test.jobID, jobID)
+		suite.Equal(test.summary, summary)
+	}
+}
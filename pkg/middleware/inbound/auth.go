@@ -15,7 +15,9 @@
 package inbound
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -34,14 +36,43 @@ const (
 	_usernameHeaderKey = "username"
 )
 
+// ResourceResolver resolves the resources targeted by a request,
+// so that users can be authorized on the resources in addition to
+// the procedure
+type ResourceResolver interface {
+	// Resolve returns the resources targeted by the procedure called with
+	// the encoded request body, or nil if the procedure is read-only and
+	// may be called on any resource. The user must be permitted on all
+	// of them. An error is returned for procedures which the resolver
+	// does not know, so that they are denied by default.
+	Resolve(
+		ctx context.Context,
+		procedure string,
+		encoding transport.Encoding,
+		body []byte,
+	) ([]*auth.Resource, error)
+}
+
 // AuthInboundMiddleware is the inbound middleware for auth
 type AuthInboundMiddleware struct {
 	auth.SecurityManager
+
+	// resolver is used to authorize users restricted to a subset of
+	// resources, it is optional and only set by components which
+	// own resources
+	resolver ResourceResolver
+}
+
+// SetResourceResolver sets the resolver used to authorize users which are
+// restricted to a subset of resources. It must be called before the
+// dispatcher is started.
+func (m *AuthInboundMiddleware) SetResourceResolver(resolver ResourceResolver) {
+	m.resolver = resolver
 }
 
 // Handle authenticates user and invokes the underlying handler
 func (m *AuthInboundMiddleware) Handle(ctx context.Context, req *transport.Request, resw transport.ResponseWriter, h transport.UnaryHandler) error {
-	permitted, err := m.isPermitted(req.Headers, req.Service, req.Procedure)
+	user, permitted, err := m.isRequestPermitted(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -50,12 +81,15 @@ func (m *AuthInboundMiddleware) Handle(ctx context.Context, req *transport.Reque
 		return yarpcerrors.PermissionDeniedErrorf(permissionDeniedErrorStr, req.Procedure, req.Service)
 	}
 
+	if user != nil {
+		ctx = auth.ContextWithUser(ctx, user)
+	}
 	return h.Handle(ctx, req, resw)
 }
 
 // HandleOneway authenticates user and invokes the underlying handler
 func (m *AuthInboundMiddleware) HandleOneway(ctx context.Context, req *transport.Request, h transport.OnewayHandler) error {
-	permitted, err := m.isPermitted(req.Headers, req.Service, req.Procedure)
+	user, permitted, err := m.isRequestPermitted(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -64,15 +98,20 @@ func (m *AuthInboundMiddleware) HandleOneway(ctx context.Context, req *transport
 		return yarpcerrors.PermissionDeniedErrorf(permissionDeniedErrorStr, req.Procedure, req.Service)
 	}
 
+	if user != nil {
+		ctx = auth.ContextWithUser(ctx, user)
+	}
 	return h.HandleOneway(ctx, req)
 }
 
-// HandleStream authenticates user and invokes the underlying handler
+// HandleStream authenticates user and invokes the underlying handler.
+// Streaming procedures are only authorized by procedure name, because
+// the request body is not available before the handler is invoked.
 func (m *AuthInboundMiddleware) HandleStream(s *transport.ServerStream, h transport.StreamHandler) error {
 	service := s.Request().Meta.Service
 	procedure := s.Request().Meta.Procedure
 
-	permitted, err := m.isPermitted(s.Request().Meta.Headers, service, procedure)
+	_, permitted, err := m.isPermitted(s.Request().Meta.Headers, service, procedure)
 	if err != nil {
 		return err
 	}
@@ -84,12 +123,55 @@ func (m *AuthInboundMiddleware) HandleStream(s *transport.ServerStream, h transp
 	return h.HandleStream(s)
 }
 
-func (m *AuthInboundMiddleware) isPermitted(headers transport.Headers, service string, procedure string) (bool, error) {
+// isRequestPermitted checks if the user is permitted to call the
+// procedure of a unary or oneway request, and if the user is restricted
+// to a subset of resources, whether it is permitted on the resource
+// targeted by the request. The user is nil if the service is not
+// authenticated.
+func (m *AuthInboundMiddleware) isRequestPermitted(ctx context.Context, req *transport.Request) (auth.User, bool, error) {
+	user, permitted, err := m.isPermitted(req.Headers, req.Service, req.Procedure)
+	if err != nil || !permitted {
+		return user, permitted, err
+	}
+
+	if user == nil || m.resolver == nil || !user.IsResourceScoped() {
+		return user, true, nil
+	}
+
+	// the body can only be read once, so it is buffered
+	// and replaced for the underlying handler
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return user, false, yarpcerrors.InternalErrorf(
+			"failed to read request body: %v", err)
+	}
+	req.Body = bytes.NewReader(body)
+
+	resources, err := m.resolver.Resolve(ctx, req.Procedure, req.Encoding, body)
+	if err != nil {
+		return user, false, err
+	}
+
+	if len(resources) == 0 {
+		return user, user.IsPermittedOnResource(req.Procedure, nil), nil
+	}
+
+	for _, resource := range resources {
+		if !user.IsPermittedOnResource(req.Procedure, resource) {
+			return user, false, nil
+		}
+	}
+	return user, true, nil
+}
+
+// isPermitted authenticates the user and checks if it is permitted to call
+// the procedure. The user is nil if the service is not authenticated.
+func (m *AuthInboundMiddleware) isPermitted(headers transport.Headers, service string, procedure string) (auth.User, bool, error) {
 	// check the service name and authenticate only peloton services.
 	// Other services such as Mesos callback (service name: Scheduler)
 	// cannot be authenticated by peloton auth mechanism for now.
 	if !strings.HasPrefix(service, _pelotonServicePrefix) {
-		return true, nil
+		return nil, true, nil
 	}
 
 	username, _ := headers.Get(_usernameHeaderKey)
@@ -100,10 +182,10 @@ func (m *AuthInboundMiddleware) isPermitted(headers transport.Headers, service s
 
 	user, err := m.Authenticate(headers)
 	if err != nil {
-		return false, err
+		return nil, false, err
 	}
 
-	return user.IsPermitted(procedure), nil
+	return user, user.IsPermitted(procedure), nil
 }
 
 // NewAuthInboundMiddleware returns AuthInboundMiddleware with auth check
@@ -15,9 +15,12 @@
 package inbound
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
 	"testing"
 
+	"github.com/uber/peloton/pkg/auth"
 	auth_mocks "github.com/uber/peloton/pkg/auth/mocks"
 
 	"github.com/golang/mock/gomock"
@@ -55,7 +58,12 @@ func (suite *AuthInboundMiddlewareSuite) TestHandleSuccess() {
 	h := transporttest.NewMockUnaryHandler(suite.ctrl)
 	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
 	suite.u.EXPECT().IsPermitted(gomock.Any()).Return(true)
-	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(ctx context.Context, _ *transport.Request, _ transport.ResponseWriter) {
+			// the authenticated user is available to the handler
+			suite.Equal(suite.u, auth.UserFromContext(ctx))
+		}).
+		Return(nil)
 	suite.NoError(suite.m.Handle(context.Background(), suite.r, nil, h))
 }
 
@@ -166,6 +174,136 @@ func (suite *AuthInboundMiddlewareSuite) TestHandleStreamHandlerFail() {
 	suite.Error(suite.m.HandleStream(ss, h))
 }
 
+func (suite *AuthInboundMiddlewareSuite) TestHandleResourceScopedUser() {
+	resolver := &testResourceResolver{
+		resources: []*auth.Resource{{RespoolPath: "/team1"}},
+	}
+	suite.m.SetResourceResolver(resolver)
+	suite.r.Procedure = "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob"
+	suite.r.Encoding = "proto"
+	suite.r.Body = bytes.NewReader([]byte("body"))
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
+	suite.u.EXPECT().IsPermitted(suite.r.Procedure).Return(true)
+	suite.u.EXPECT().IsResourceScoped().Return(true)
+	suite.u.EXPECT().
+		IsPermittedOnResource(suite.r.Procedure, resolver.resources[0]).
+		Return(true)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, req *transport.Request, _ transport.ResponseWriter) {
+			// the body is still available to the handler
+			body, err := ioutil.ReadAll(req.Body)
+			suite.NoError(err)
+			suite.Equal([]byte("body"), body)
+		}).
+		Return(nil)
+	suite.NoError(suite.m.Handle(context.Background(), suite.r, nil, h))
+	suite.Equal(suite.r.Procedure, resolver.procedure)
+	suite.Equal([]byte("body"), resolver.body)
+}
+
+func (suite *AuthInboundMiddlewareSuite) TestHandleResourceScopedUserNotPermitted() {
+	resolver := &testResourceResolver{
+		resources: []*auth.Resource{{RespoolPath: "/team2"}},
+	}
+	suite.m.SetResourceResolver(resolver)
+	suite.r.Body = bytes.NewReader([]byte("body"))
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
+	suite.u.EXPECT().IsPermitted(gomock.Any()).Return(true)
+	suite.u.EXPECT().IsResourceScoped().Return(true)
+	suite.u.EXPECT().IsPermittedOnResource(gomock.Any(), resolver.resources[0]).Return(false)
+	suite.Error(suite.m.Handle(context.Background(), suite.r, nil, h))
+}
+
+func (suite *AuthInboundMiddlewareSuite) TestHandleResourceScopedUserMultipleResources() {
+	resolver := &testResourceResolver{
+		resources: []*auth.Resource{
+			{RespoolPath: "/team1"},
+			{RespoolPath: "/team2"},
+		},
+	}
+	suite.m.SetResourceResolver(resolver)
+	suite.r.Body = bytes.NewReader([]byte("body"))
+
+	// the user must be permitted on every resolved resource
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
+	suite.u.EXPECT().IsPermitted(gomock.Any()).Return(true)
+	suite.u.EXPECT().IsResourceScoped().Return(true)
+	gomock.InOrder(
+		suite.u.EXPECT().
+			IsPermittedOnResource(gomock.Any(), resolver.resources[0]).
+			Return(true),
+		suite.u.EXPECT().
+			IsPermittedOnResource(gomock.Any(), resolver.resources[1]).
+			Return(false),
+	)
+	suite.Error(suite.m.Handle(context.Background(), suite.r, nil, h))
+}
+
+func (suite *AuthInboundMiddlewareSuite) TestHandleResourceScopedUserNoResource() {
+	resolver := &testResourceResolver{}
+	suite.m.SetResourceResolver(resolver)
+	suite.r.Body = bytes.NewReader([]byte("body"))
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
+	suite.u.EXPECT().IsPermitted(gomock.Any()).Return(true)
+	suite.u.EXPECT().IsResourceScoped().Return(true)
+	suite.u.EXPECT().IsPermittedOnResource(gomock.Any(), nil).Return(true)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.NoError(suite.m.Handle(context.Background(), suite.r, nil, h))
+}
+
+func (suite *AuthInboundMiddlewareSuite) TestHandleResourceResolveFail() {
+	suite.m.SetResourceResolver(&testResourceResolver{
+		err: errors.New("test error"),
+	})
+	suite.r.Body = bytes.NewReader([]byte("body"))
+
+	h := transporttest.NewMockOnewayHandler(suite.ctrl)
+	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
+	suite.u.EXPECT().IsPermitted(gomock.Any()).Return(true)
+	suite.u.EXPECT().IsResourceScoped().Return(true)
+	suite.Error(suite.m.HandleOneway(context.Background(), suite.r, h))
+}
+
+func (suite *AuthInboundMiddlewareSuite) TestHandleNotResourceScopedUser() {
+	resolver := &testResourceResolver{}
+	suite.m.SetResourceResolver(resolver)
+
+	h := transporttest.NewMockUnaryHandler(suite.ctrl)
+	suite.s.EXPECT().Authenticate(gomock.Any()).Return(suite.u, nil)
+	suite.u.EXPECT().IsPermitted(gomock.Any()).Return(true)
+	suite.u.EXPECT().IsResourceScoped().Return(false)
+	h.EXPECT().Handle(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	suite.NoError(suite.m.Handle(context.Background(), suite.r, nil, h))
+	// request is not resolved for users without resource restriction
+	suite.Empty(resolver.procedure)
+}
+
+type testResourceResolver struct {
+	resources []*auth.Resource
+	err       error
+
+	procedure string
+	body      []byte
+}
+
+func (r *testResourceResolver) Resolve(
+	ctx context.Context,
+	procedure string,
+	encoding transport.Encoding,
+	body []byte,
+) ([]*auth.Resource, error) {
+	r.procedure = procedure
+	r.body = body
+	return r.resources, r.err
+}
+
 func TestAuthInboundMiddlewareSuite(t *testing.T) {
 	suite.Run(t, &AuthInboundMiddlewareSuite{})
 }
@@ -0,0 +1,69 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"context"
+
+	"go.uber.org/yarpc/api/middleware"
+	"go.uber.org/yarpc/api/transport"
+)
+
+// unaryChain invokes the unary inbound middleware in order,
+// the last one invoking the handler
+type unaryChain []middleware.UnaryInbound
+
+// UnaryChain combines unary inbound middleware into one, so that
+// several of them can be installed on a dispatcher. The first
+// middleware is invoked first.
+func UnaryChain(mws ...middleware.UnaryInbound) middleware.UnaryInbound {
+	return unaryChain(mws)
+}
+
+// Handle invokes the middleware in order and then the handler
+func (c unaryChain) Handle(
+	ctx context.Context,
+	req *transport.Request,
+	resw transport.ResponseWriter,
+	h transport.UnaryHandler,
+) error {
+	for i := len(c) - 1; i >= 0; i-- {
+		h = middleware.ApplyUnaryInbound(h, c[i])
+	}
+	return h.Handle(ctx, req, resw)
+}
+
+// onewayChain invokes the oneway inbound middleware in order,
+// the last one invoking the handler
+type onewayChain []middleware.OnewayInbound
+
+// OnewayChain combines oneway inbound middleware into one, so that
+// several of them can be installed on a dispatcher. The first
+// middleware is invoked first.
+func OnewayChain(mws ...middleware.OnewayInbound) middleware.OnewayInbound {
+	return onewayChain(mws)
+}
+
+// HandleOneway invokes the middleware in order and then the handler
+func (c onewayChain) HandleOneway(
+	ctx context.Context,
+	req *transport.Request,
+	h transport.OnewayHandler,
+) error {
+	for i := len(c) - 1; i >= 0; i-- {
+		h = middleware.ApplyOnewayInbound(h, c[i])
+	}
+	return h.HandleOneway(ctx, req)
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package inbound
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/yarpc/api/middleware"
+	"go.uber.org/yarpc/api/transport"
+)
+
+// recordingMiddleware appends its name to calls when invoked
+func recordingMiddleware(name string, calls *[]string) (
+	middleware.UnaryInboundFunc,
+	middleware.OnewayInboundFunc,
+) {
+	unary := func(
+		ctx context.Context,
+		req *transport.Request,
+		resw transport.ResponseWriter,
+		h transport.UnaryHandler,
+	) error {
+		*calls = append(*calls, name)
+		return h.Handle(ctx, req, resw)
+	}
+	oneway := func(
+		ctx context.Context,
+		req *transport.Request,
+		h transport.OnewayHandler,
+	) error {
+		*calls = append(*calls, name)
+		return h.HandleOneway(ctx, req)
+	}
+	return unary, oneway
+}
+
+func TestUnaryChain(t *testing.T) {
+	var calls []string
+	unary1, _ := recordingMiddleware("m1", &calls)
+	unary2, _ := recordingMiddleware("m2", &calls)
+
+	h := transport.UnaryHandler(unaryHandlerFunc(func() {
+		calls = append(calls, "handler")
+	}))
+	assert.NoError(t, UnaryChain(unary1, unary2).
+		Handle(context.Background(), &transport.Request{}, nil, h))
+	assert.Equal(t, []string{"m1", "m2", "handler"}, calls)
+}
+
+func TestOnewayChain(t *testing.T) {
+	var calls []string
+	_, oneway1 := recordingMiddleware("m1", &calls)
+	_, oneway2 := recordingMiddleware("m2", &calls)
+
+	h := transport.OnewayHandler(onewayHandlerFunc(func() {
+		calls = append(calls, "handler")
+	}))
+	assert.NoError(t, OnewayChain(oneway1, oneway2).
+		HandleOneway(context.Background(), &transport.Request{}, h))
+	assert.Equal(t, []string{"m1", "m2", "handler"}, calls)
+}
+
+type unaryHandlerFunc func()
+
+func (f unaryHandlerFunc) Handle(
+	context.Context,
+	*transport.Request,
+	transport.ResponseWriter,
+) error {
+	f()
+	return nil
+}
+
+type onewayHandlerFunc func()
+
+func (f onewayHandlerFunc) HandleOneway(
+	context.Context,
+	*transport.Request,
+) error {
+	f()
+	return nil
+}
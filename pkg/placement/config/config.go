@@ -100,6 +100,28 @@ type PlacementConfig struct {
 	// MaxDesiredHostPlacementDuration is the max time duration to try to
 	// place a task on the desired host.
 	MaxDesiredHostPlacementDuration time.Duration `yaml:"max_desired_host_placement_duration"`
+
+	// Defrag is the config of the background defragmenter.
+	Defrag DefragConfig `yaml:"defrag"`
+}
+
+// DefragConfig is the config of the background defragmenter which moves
+// running tasks to free whole hosts for large or GPU tasks.
+type DefragConfig struct {
+	// Enabled determines if the defragmenter is running on the leader
+	// of the placement engines.
+	Enabled bool `yaml:"enabled"`
+
+	// Interval is the time between two defragmentation rounds.
+	Interval time.Duration `yaml:"interval"`
+
+	// MaxHostsPerRound is the maximal number of hosts which are freed in
+	// one defragmentation round.
+	MaxHostsPerRound int `yaml:"max_hosts_per_round"`
+
+	// MoveTimeout is the time during which a moved task is counted against
+	// the availability budget of its job.
+	MoveTimeout time.Duration `yaml:"move_timeout"`
 }
 
 // MaxRoundsConfig is the config of the maximal number of successful rounds
@@ -0,0 +1,676 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defrag
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/query"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/common/async"
+	"github.com/uber/peloton/pkg/common/leader"
+	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/hosts"
+	tally_metrics "github.com/uber/peloton/pkg/placement/metrics"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/orderings"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/placement"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// _defaultInterval is the time between two rounds if no interval is
+	// configured.
+	_defaultInterval = 5 * time.Minute
+	// _defaultMoveTimeout is the time a move is tracked if no move timeout
+	// is configured.
+	_defaultMoveTimeout = 10 * time.Minute
+	// _timeout is the timeout for a single call to another component.
+	_timeout = 10 * time.Second
+	// _podQueryLimit is the number of pods queried at once to find the
+	// unavailable instances of a job.
+	_podQueryLimit = 1000
+)
+
+// Move describes a running task which is restarted by the defragmenter to
+// free the host it is running on.
+type Move struct {
+	// TaskID is the id of the moved task.
+	TaskID string
+	// JobID is the id of the job of the moved task.
+	JobID string
+	// SourceHost is the host the task is moved away from.
+	SourceHost string
+	// TargetHost is the host the task fitted on when the move was planned.
+	// The task is placed again by the placement engine, so this is a hint.
+	TargetHost string
+	// Deadline is the time until which the task is counted against the
+	// availability budget of its job. The task itself carries the source
+	// host, such that every placement engine keeps it away from that host.
+	Deadline time.Time
+}
+
+// Defragmenter periodically computes relocation ranks for the running tasks
+// and restarts the tasks of hosts which can be freed entirely, such that the
+// freed hosts become available for large or GPU tasks.
+type Defragmenter interface {
+	// Defragmenter only runs on the leader of the defragmenter election
+	// role, such that a single defragmenter tracks the moves in progress.
+	leader.Nomination
+
+	// Defragment runs a single defragmentation round and returns the moves
+	// which were started.
+	Defragment(ctx context.Context) ([]*Move, error)
+}
+
+// defragmenter is the struct which implements Defragmenter interface
+type defragmenter struct {
+	lock sync.Mutex
+	// ID of the placement engine in the leader election
+	id string
+	// Placement config for the defragmenter
+	config *config.PlacementConfig
+	// Placement engine metrics
+	metrics *tally_metrics.Metrics
+	// hostService for getting the hosts and the tasks running on them
+	hostService hosts.Service
+	// podClient for restarting the moved tasks in job manager
+	podClient podsvc.PodServiceYARPCClient
+	// jobClient for getting the unavailable instances of jobs
+	jobClient statelesssvc.JobServiceYARPCClient
+	// resmgrClient for getting the tasks waiting for placement
+	resmgrClient resmgrsvc.ResourceManagerServiceYARPCClient
+	// relocator for computing the relocation ranks of the running tasks
+	relocator algorithms.Relocator
+	// daemon object for making defragmenter a daemon process
+	daemon async.Daemon
+	// moves in progress indexed by taskID
+	moves map[string]*Move
+}
+
+// NewDefragmenter creates a new defragmenter which frees hosts by moving
+// their tasks to other hosts the tasks fit on.
+func NewDefragmenter(
+	id string,
+	metrics *tally_metrics.Metrics,
+	cfg *config.PlacementConfig,
+	hostsService hosts.Service,
+	podClient podsvc.PodServiceYARPCClient,
+	jobClient statelesssvc.JobServiceYARPCClient,
+	resmgrClient resmgrsvc.ResourceManagerServiceYARPCClient,
+	relocator algorithms.Relocator) Defragmenter {
+	defragmenter := &defragmenter{
+		id:           id,
+		config:       cfg,
+		metrics:      metrics,
+		hostService:  hostsService,
+		podClient:    podClient,
+		jobClient:    jobClient,
+		resmgrClient: resmgrClient,
+		relocator:    relocator,
+		moves:        make(map[string]*Move),
+	}
+	defragmenter.daemon = async.NewDaemon(
+		"Placement Engine Defragmenter", defragmenter)
+	return defragmenter
+}
+
+// GainedLeadershipCallback starts the defragmentation rounds when the
+// placement engine becomes the leader.
+func (d *defragmenter) GainedLeadershipCallback() error {
+	log.Info("Gained leadership, starting defragmenter")
+	d.daemon.Start()
+	return nil
+}
+
+// LostLeadershipCallback stops the defragmentation rounds and forgets the
+// moves in progress. The new leader finds the moved tasks among the
+// unavailable instances of their jobs in job manager.
+func (d *defragmenter) LostLeadershipCallback() error {
+	log.Info("Lost leadership, stopping defragmenter")
+	d.daemon.Stop()
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.moves = make(map[string]*Move)
+	return nil
+}
+
+// ShutDownCallback stops the defragmentation rounds.
+func (d *defragmenter) ShutDownCallback() error {
+	log.Info("Shutting down defragmenter")
+	d.daemon.Stop()
+	return nil
+}
+
+// GetID returns the ID of the placement engine in the leader election.
+func (d *defragmenter) GetID() string {
+	return d.id
+}
+
+// Run method implements runnable from daemon
+func (d *defragmenter) Run(ctx context.Context) error {
+	interval := d.config.Defrag.Interval
+	if interval <= 0 {
+		interval = _defaultInterval
+	}
+	timer := time.NewTimer(interval)
+	for {
+		select {
+		case <-ctx.Done():
+			if !timer.Stop() {
+				<-timer.C
+			}
+			return ctx.Err()
+		case <-timer.C:
+		}
+		if _, err := d.Defragment(ctx); err != nil {
+			log.WithError(err).Info("defragmentation round failed")
+		}
+		timer.Reset(interval)
+	}
+}
+
+// candidate is a host which might be freed by moving all its tasks.
+type candidate struct {
+	group *placement.Group
+	tasks []*resmgr.Task
+	gpu   bool
+}
+
+// Defragment does following steps
+//  1. Get all hosts and the tasks of all types running on them
+//  2. Stop if every task waiting for placement fits on some host
+//  3. Rank the tasks by the number of hosts which pack them better
+//  4. Pick the hosts of which all tasks are better off elsewhere, GPU
+//     hosts first and then the hosts with the fewest tasks
+//  5. Restart the tasks of the picked hosts within the availability
+//     budget of their jobs
+func (d *defragmenter) Defragment(ctx context.Context) ([]*Move, error) {
+	// the task type is unknown to get the tasks of all types
+	hostList, err := d.hostService.GetHosts(
+		ctx,
+		&resmgr.Task{Type: resmgr.TaskType_UNKNOWN},
+		&hostsvc.HostFilter{})
+	if err != nil {
+		d.metrics.DefragRunFail.Inc(1)
+		return nil, err
+	}
+
+	now := time.Now()
+	groups, candidates, ranks := makeModel(hostList)
+	scopeSet := placement.NewScopeSet(groups)
+
+	unfit, err := d.hasUnfitDemand(ctx, groups, scopeSet)
+	if err != nil {
+		d.metrics.DefragRunFail.Inc(1)
+		return nil, err
+	}
+	if !unfit {
+		log.Debug("all tasks waiting for placement fit on a host, " +
+			"skipping defragmentation")
+		d.metrics.DefragRun.Inc(1)
+		return nil, nil
+	}
+
+	d.relocator.Relocate(ranks, groups, scopeSet)
+
+	candidates = d.filterCandidates(candidates, ranks)
+	budgets := map[string]int{}
+	maxHosts := d.config.Defrag.MaxHostsPerRound
+	if maxHosts <= 0 {
+		maxHosts = 1
+	}
+	targets := map[string]bool{}
+	freed := 0
+	var moves []*Move
+	for _, c := range candidates {
+		if freed >= maxHosts {
+			break
+		}
+		// Hosts receiving tasks in this round are not freed.
+		if targets[c.group.Name] {
+			continue
+		}
+		if err := d.addBudgets(ctx, c.tasks, budgets, now); err != nil {
+			log.WithError(err).
+				WithField("host", c.group.Name).
+				Info("failed to get availability budgets of jobs on host")
+			continue
+		}
+		if hostMoves := planHost(c, groups, scopeSet, targets, budgets); len(hostMoves) > 0 {
+			moves = append(moves, hostMoves...)
+			freed++
+		}
+	}
+
+	started := d.startMoves(ctx, moves, now)
+	d.metrics.DefragRun.Inc(1)
+	d.metrics.DefragHostFreed.Inc(int64(d.hostsFreed(started)))
+	return started, nil
+}
+
+// hasUnfitDemand returns if any task waiting for placement does not fit
+// on any host, which is the demand freeing a host can serve.
+func (d *defragmenter) hasUnfitDemand(
+	ctx context.Context,
+	groups []*placement.Group,
+	scopeSet *placement.ScopeSet) (bool, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, _timeout)
+	defer cancelFunc()
+
+	resp, err := d.resmgrClient.GetActiveTasks(
+		ctx,
+		&resmgrsvc.GetActiveTasksRequest{
+			States: []string{
+				task.TaskState_READY.String(),
+				task.TaskState_PLACING.String(),
+			},
+			IncludeTasks: true,
+		})
+	if err != nil {
+		return false, err
+	}
+	if resp.GetError() != nil {
+		return false, errors.New(resp.GetError().GetMessage())
+	}
+
+	for _, entries := range resp.GetTasksByState() {
+		for _, entry := range entries.GetTaskEntry() {
+			entity := mimir.TaskToEntity(entry.GetTask(), false)
+			if findTarget(entity, nil, groups, scopeSet, true) == nil {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// addBudgets adds the number of tasks which can be moved for the jobs of
+// the tasks which are not in the budgets yet. It is the maximum number of
+// unavailable instances of the job minus the instances which are already
+// unavailable.
+func (d *defragmenter) addBudgets(
+	ctx context.Context,
+	tasks []*resmgr.Task,
+	budgets map[string]int,
+	now time.Time) error {
+	for _, t := range tasks {
+		jobID := t.GetJobId().GetValue()
+		if _, ok := budgets[jobID]; ok {
+			continue
+		}
+		unavailable, err := d.unavailableInstances(ctx, jobID, now)
+		if err != nil {
+			return err
+		}
+		// Moves are voluntary, so jobs without an SLA still only
+		// get one task moved at a time.
+		budget := int(t.GetMaximumUnavailableInstances())
+		if budget == 0 {
+			budget = 1
+		}
+		budgets[jobID] = budget - unavailable
+	}
+	return nil
+}
+
+// unavailableInstances returns the number of instances of the job which
+// are killed, being updated, unhealthy or otherwise not running, plus the
+// moves of the job which job manager does not report as restarted yet.
+func (d *defragmenter) unavailableInstances(
+	ctx context.Context,
+	jobID string,
+	now time.Time) (int, error) {
+	unavailable := 0
+	var running []*pod.PodStatus
+	var offset uint32
+	for {
+		pods, total, err := d.queryPods(ctx, jobID, offset)
+		if err != nil {
+			return 0, err
+		}
+		for _, p := range pods {
+			if isUnavailable(p.GetStatus()) {
+				unavailable++
+				continue
+			}
+			running = append(running, p.GetStatus())
+		}
+		offset += uint32(len(pods))
+		if len(pods) == 0 || offset >= total {
+			break
+		}
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for taskID, move := range d.moves {
+		if now.After(move.Deadline) {
+			delete(d.moves, taskID)
+			continue
+		}
+		if move.JobID != jobID {
+			continue
+		}
+		// the restart of the task is not visible in job manager yet
+		// if the run of the task on the source host is still running
+		for _, status := range running {
+			if strings.HasPrefix(status.GetPodId().GetValue(), taskID+"-") &&
+				status.GetHost() == move.SourceHost {
+				unavailable++
+				break
+			}
+		}
+	}
+	return unavailable, nil
+}
+
+// queryPods returns a page of the pods of the job starting at the offset,
+// and the total number of pods of the job.
+func (d *defragmenter) queryPods(
+	ctx context.Context,
+	jobID string,
+	offset uint32) ([]*pod.PodInfo, uint32, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, _timeout)
+	defer cancelFunc()
+
+	resp, err := d.jobClient.QueryPods(ctx, &statelesssvc.QueryPodsRequest{
+		JobId: &v1alphapeloton.JobID{Value: jobID},
+		Spec: &pod.QuerySpec{
+			Pagination: &query.PaginationSpec{
+				Offset: offset,
+				Limit:  _podQueryLimit,
+			},
+		},
+		SummaryOnly: true,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.GetPods(), resp.GetPagination().GetTotal(), nil
+}
+
+// isUnavailable returns if a pod counts against the availability budget of
+// its job, which are the pods which are not running, being killed, being
+// updated or failing their health check. Completed batch pods do not count.
+func isUnavailable(status *pod.PodStatus) bool {
+	if status.GetState() == pod.PodState_POD_STATE_SUCCEEDED {
+		return false
+	}
+	if status.GetState() != pod.PodState_POD_STATE_RUNNING ||
+		status.GetDesiredState() == pod.PodState_POD_STATE_KILLED {
+		return true
+	}
+	if status.GetVersion().GetValue() !=
+		status.GetDesiredVersion().GetValue() {
+		return true
+	}
+	for _, container := range status.GetContainersStatus() {
+		if container.GetHealthy().GetState() ==
+			pod.HealthState_HEALTH_STATE_UNHEALTHY {
+			return true
+		}
+	}
+	return false
+}
+
+// filterCandidates returns the candidates of which all tasks have a better
+// host to run on and none of which are being moved already, sorted with
+// GPU hosts first and then by the number of tasks.
+func (d *defragmenter) filterCandidates(
+	candidates []*candidate,
+	ranks []*placement.RelocationRank) []*candidate {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	movable := map[string]bool{}
+	for _, rank := range ranks {
+		_, moving := d.moves[rank.Entity.Name]
+		movable[rank.Entity.Name] = rank.Rank > 0 && !moving
+	}
+
+	var result []*candidate
+	for _, c := range candidates {
+		if len(c.tasks) == 0 {
+			continue
+		}
+		ok := true
+		for _, t := range c.tasks {
+			if !movable[t.GetId().GetValue()] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			result = append(result, c)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].gpu != result[j].gpu {
+			return result[i].gpu
+		}
+		return len(result[i].tasks) < len(result[j].tasks)
+	})
+	return result
+}
+
+// hostsFreed returns the number of distinct source hosts of the moves.
+func (d *defragmenter) hostsFreed(moves []*Move) int {
+	sources := map[string]bool{}
+	for _, move := range moves {
+		sources[move.SourceHost] = true
+	}
+	return len(sources)
+}
+
+// startMoves restarts the tasks of the moves in job manager and returns
+// the moves which were started.
+func (d *defragmenter) startMoves(
+	ctx context.Context,
+	moves []*Move,
+	now time.Time) []*Move {
+	timeout := d.config.Defrag.MoveTimeout
+	if timeout <= 0 {
+		timeout = _defaultMoveTimeout
+	}
+
+	var started []*Move
+	for _, move := range moves {
+		move.Deadline = now.Add(timeout)
+		// Track the move before restarting, such that the task is not
+		// moved again by a concurrent round.
+		d.lock.Lock()
+		d.moves[move.TaskID] = move
+		d.lock.Unlock()
+
+		if err := d.restart(ctx, move); err != nil {
+			log.WithError(err).
+				WithFields(log.Fields{
+					"task_id":     move.TaskID,
+					"source_host": move.SourceHost,
+				}).Info("failed to move task")
+			d.lock.Lock()
+			delete(d.moves, move.TaskID)
+			d.lock.Unlock()
+			d.metrics.DefragTaskMoveFail.Inc(1)
+			continue
+		}
+		log.WithFields(log.Fields{
+			"task_id":     move.TaskID,
+			"source_host": move.SourceHost,
+			"target_host": move.TargetHost,
+		}).Info("moving task to defragment host")
+		d.metrics.DefragTaskMove.Inc(1)
+		started = append(started, move)
+	}
+	return started
+}
+
+// restart restarts the task of the move in job manager.
+func (d *defragmenter) restart(ctx context.Context, move *Move) error {
+	ctx, cancelFunc := context.WithTimeout(ctx, _timeout)
+	defer cancelFunc()
+
+	_, err := d.podClient.RestartPod(ctx, &podsvc.RestartPodRequest{
+		PodName:   &v1alphapeloton.PodName{Value: move.TaskID},
+		AvoidHost: move.SourceHost,
+	})
+	return err
+}
+
+// makeModel converts the hosts into mimir groups and the tasks running on
+// them into entities, which prefer the hosts with the least free resources
+// and without GPUs.
+func makeModel(hostList []*models.Host) (
+	[]*placement.Group, []*candidate, []*placement.RelocationRank) {
+	packing := orderings.Concatenate(
+		orderings.Metric(orderings.GroupSource, mimir.GPUAvailable),
+		orderings.Metric(orderings.GroupSource, mimir.CPUFree),
+		orderings.Metric(orderings.GroupSource, mimir.MemoryFree),
+	)
+
+	var groups []*placement.Group
+	var candidates []*candidate
+	var ranks []*placement.RelocationRank
+	for _, host := range hostList {
+		group := mimir.OfferToGroup(&hostsvc.HostOffer{
+			Hostname:   host.GetHost().GetHostname(),
+			AgentId:    host.GetHost().GetAgentId(),
+			Resources:  host.GetHost().GetResources(),
+			Attributes: host.GetHost().GetAttributes(),
+		})
+		for _, t := range host.GetTasks() {
+			entity := mimir.TaskToEntity(t, false)
+			entity.Ordering = packing
+			group.Entities.Add(entity)
+			ranks = append(ranks, placement.NewRelocationRank(entity, group))
+		}
+		group.Update()
+		groups = append(groups, group)
+		candidates = append(candidates, &candidate{
+			group: group,
+			tasks: host.GetTasks(),
+			gpu:   group.Metrics.Get(mimir.GPUAvailable) > 0,
+		})
+	}
+	return groups, candidates, ranks
+}
+
+// planHost finds a target host for every task of the candidate, such that
+// the candidate host is freed entirely. The planned tasks are moved to
+// their targets in the model and the budgets of their jobs are reduced.
+// If the host can not be freed the model and the budgets are left
+// unchanged and no moves are returned.
+func planHost(
+	c *candidate,
+	groups []*placement.Group,
+	scopeSet *placement.ScopeSet,
+	targets map[string]bool,
+	budgets map[string]int) []*Move {
+	type planned struct {
+		entity *placement.Entity
+		target *placement.Group
+	}
+	var plan []planned
+	rollback := func() {
+		for _, p := range plan {
+			p.target.Entities.Remove(p.entity)
+			p.target.Update()
+			c.group.Entities.Add(p.entity)
+		}
+		c.group.Update()
+	}
+
+	used := map[string]int{}
+	for _, t := range c.tasks {
+		jobID := t.GetJobId().GetValue()
+		if budgets[jobID]-used[jobID] <= 0 {
+			rollback()
+			return nil
+		}
+		entity := c.group.Entities[t.GetId().GetValue()]
+		target := findTarget(entity, c.group, groups, scopeSet, false)
+		if target == nil {
+			rollback()
+			return nil
+		}
+		c.group.Entities.Remove(entity)
+		target.Entities.Add(entity)
+		target.Update()
+		plan = append(plan, planned{entity: entity, target: target})
+		used[jobID]++
+	}
+	c.group.Update()
+
+	var moves []*Move
+	for i, p := range plan {
+		targets[p.target.Name] = true
+		moves = append(moves, &Move{
+			TaskID:     c.tasks[i].GetId().GetValue(),
+			JobID:      c.tasks[i].GetJobId().GetValue(),
+			SourceHost: c.group.Name,
+			TargetHost: p.target.Name,
+		})
+	}
+	for jobID, count := range used {
+		budgets[jobID] -= count
+	}
+	return moves
+}
+
+// findTarget returns the best group other than the source group on which
+// the entity fits, or nil if there is none. Free groups are only considered
+// if allowed.
+func findTarget(
+	entity *placement.Entity,
+	source *placement.Group,
+	groups []*placement.Group,
+	scopeSet *placement.ScopeSet,
+	allowFree bool) *placement.Group {
+	var best *placement.Group
+	var bestTuple []float64
+	transcript := placement.NewTranscript(entity.Name)
+	for _, group := range groups {
+		// Moving tasks to a free host would only fragment another host.
+		if group == source || (!allowFree && len(group.Entities) == 0) {
+			continue
+		}
+		if !entity.Requirement.Passed(group, scopeSet, entity, transcript) {
+			continue
+		}
+		tuple := entity.Ordering.Tuple(group, scopeSet, entity)
+		if best == nil || placement.Less(tuple, bestTuple) {
+			best, bestTuple = group, tuple
+		}
+	}
+	return best
+}
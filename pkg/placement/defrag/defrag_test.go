@@ -0,0 +1,475 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package defrag
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	statelesssvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc"
+	jobmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/job/stateless/svc/mocks"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/pod"
+	podsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc"
+	podmocks "github.com/uber/peloton/.gen/peloton/api/v1alpha/pod/svc/mocks"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/query"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+	resmocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
+
+	"github.com/uber/peloton/pkg/placement/config"
+	hosts_mock "github.com/uber/peloton/pkg/placement/hosts/mocks"
+	"github.com/uber/peloton/pkg/placement/metrics"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type DefragmenterTestSuite struct {
+	suite.Suite
+	mockCtrl     *gomock.Controller
+	hostService  *hosts_mock.MockService
+	podClient    *podmocks.MockPodServiceYARPCClient
+	jobClient    *jobmocks.MockJobServiceYARPCClient
+	resmgrClient *resmocks.MockResourceManagerServiceYARPCClient
+	defragmenter *defragmenter
+}
+
+func (suite *DefragmenterTestSuite) SetupTest() {
+	suite.mockCtrl = gomock.NewController(suite.T())
+	suite.hostService = hosts_mock.NewMockService(suite.mockCtrl)
+	suite.podClient = podmocks.NewMockPodServiceYARPCClient(suite.mockCtrl)
+	suite.jobClient = jobmocks.NewMockJobServiceYARPCClient(suite.mockCtrl)
+	suite.resmgrClient = resmocks.NewMockResourceManagerServiceYARPCClient(suite.mockCtrl)
+	cfg := &config.PlacementConfig{
+		TaskType: resmgr.TaskType_STATELESS,
+		Defrag: config.DefragConfig{
+			Enabled:          true,
+			MaxHostsPerRound: 1,
+			MoveTimeout:      time.Minute,
+		},
+	}
+	suite.defragmenter = NewDefragmenter(
+		"placement-1",
+		metrics.NewMetrics(tally.NoopScope),
+		cfg,
+		suite.hostService,
+		suite.podClient,
+		suite.jobClient,
+		suite.resmgrClient,
+		algorithms.NewRelocator(0, 0),
+	).(*defragmenter)
+}
+
+func (suite *DefragmenterTestSuite) TearDownTest() {
+	suite.mockCtrl.Finish()
+}
+
+func TestDefragmenter(t *testing.T) {
+	suite.Run(t, new(DefragmenterTestSuite))
+}
+
+func createHost(hostname string, tasks ...*resmgr.Task) *models.Host {
+	cpuName := "cpus"
+	memoryName := "mem"
+	cpuValue := 10.0
+	memoryValue := 1024.0
+	return models.NewHosts(&hostsvc.HostInfo{
+		Hostname: hostname,
+		Resources: []*mesos_v1.Resource{
+			{
+				Name:   &cpuName,
+				Scalar: &mesos_v1.Value_Scalar{Value: &cpuValue},
+			},
+			{
+				Name:   &memoryName,
+				Scalar: &mesos_v1.Value_Scalar{Value: &memoryValue},
+			},
+		},
+	}, tasks)
+}
+
+func createTask(jobID string, instance string, cpu float64, budget uint32) *resmgr.Task {
+	return &resmgr.Task{
+		Id:    &peloton.TaskID{Value: jobID + "-" + instance},
+		JobId: &peloton.JobID{Value: jobID},
+		Type:  resmgr.TaskType_STATELESS,
+		Resource: &task.ResourceConfig{
+			CpuLimit:   cpu,
+			MemLimitMb: 100,
+		},
+		MaximumUnavailableInstances: budget,
+	}
+}
+
+// expectPendingTasks stubs the tasks waiting for placement in resmgr.
+func (suite *DefragmenterTestSuite) expectPendingTasks(tasks ...*resmgr.Task) {
+	var entries []*resmgrsvc.GetActiveTasksResponse_TaskEntry
+	for _, t := range tasks {
+		entries = append(entries, &resmgrsvc.GetActiveTasksResponse_TaskEntry{
+			TaskID: t.GetId().GetValue(),
+			Task:   t,
+		})
+	}
+	suite.resmgrClient.EXPECT().
+		GetActiveTasks(gomock.Any(), &resmgrsvc.GetActiveTasksRequest{
+			States: []string{
+				task.TaskState_READY.String(),
+				task.TaskState_PLACING.String(),
+			},
+			IncludeTasks: true,
+		}).
+		Return(&resmgrsvc.GetActiveTasksResponse{
+			TasksByState: map[string]*resmgrsvc.GetActiveTasksResponse_TaskEntries{
+				task.TaskState_READY.String(): {TaskEntry: entries},
+			},
+		}, nil).
+		AnyTimes()
+}
+
+// expectGPUDemand stubs a GPU task waiting for placement, which does not
+// fit on any of the test hosts.
+func (suite *DefragmenterTestSuite) expectGPUDemand() {
+	gpuTask := createTask("gpu", "1", 1, 1)
+	gpuTask.Resource.GpuLimit = 1
+	suite.expectPendingTasks(gpuTask)
+}
+
+// expectPods stubs the pods of a job in job manager.
+func (suite *DefragmenterTestSuite) expectPods(jobID string, pods ...*pod.PodInfo) {
+	suite.jobClient.EXPECT().
+		QueryPods(gomock.Any(), &statelesssvc.QueryPodsRequest{
+			JobId: &v1alphapeloton.JobID{Value: jobID},
+			Spec: &pod.QuerySpec{
+				Pagination: &query.PaginationSpec{
+					Offset: 0,
+					Limit:  _podQueryLimit,
+				},
+			},
+			SummaryOnly: true,
+		}).
+		Return(&statelesssvc.QueryPodsResponse{
+			Pods:       pods,
+			Pagination: &query.Pagination{Total: uint32(len(pods))},
+		}, nil).
+		AnyTimes()
+}
+
+// createPod creates a running pod of a job on a host.
+func createPod(jobID string, instance string, host string) *pod.PodInfo {
+	return &pod.PodInfo{
+		Status: &pod.PodStatus{
+			State:        pod.PodState_POD_STATE_RUNNING,
+			DesiredState: pod.PodState_POD_STATE_RUNNING,
+			PodId:        &v1alphapeloton.PodID{Value: jobID + "-" + instance + "-1"},
+			Host:         host,
+		},
+	}
+}
+
+func (suite *DefragmenterTestSuite) expectRestart(
+	sourceHost string,
+	podNames ...string) {
+	for _, podName := range podNames {
+		suite.podClient.EXPECT().
+			RestartPod(gomock.Any(), &podsvc.RestartPodRequest{
+				PodName:   &v1alphapeloton.PodName{Value: podName},
+				AvoidHost: sourceHost,
+			}).
+			Return(&podsvc.RestartPodResponse{}, nil)
+	}
+}
+
+// TestDefragmentFreesHost tests that the tasks of a lightly loaded host are
+// moved to the most packed host they fit on
+func (suite *DefragmenterTestSuite) TestDefragmentFreesHost() {
+	hosts := []*models.Host{
+		createHost("host-1", createTask("a", "1", 2, 1)),
+		createHost("host-2", createTask("b", "1", 4, 1), createTask("b", "2", 4, 1)),
+		createHost("host-3"),
+	}
+	suite.hostService.EXPECT().
+		GetHosts(
+			gomock.Any(),
+			&resmgr.Task{Type: resmgr.TaskType_UNKNOWN},
+			gomock.Any()).
+		Return(hosts, nil).
+		Times(2)
+	suite.expectGPUDemand()
+	suite.expectPods("a", createPod("a", "1", "host-1"))
+	suite.expectPods("b")
+	suite.expectRestart("host-1", "a-1")
+
+	moves, err := suite.defragmenter.Defragment(context.Background())
+	suite.NoError(err)
+	suite.Len(moves, 1)
+	suite.Equal("a-1", moves[0].TaskID)
+	suite.Equal("a", moves[0].JobID)
+	suite.Equal("host-1", moves[0].SourceHost)
+	suite.Equal("host-2", moves[0].TargetHost)
+
+	// a task being moved is not moved again
+	moves, err = suite.defragmenter.Defragment(context.Background())
+	suite.NoError(err)
+	suite.Empty(moves)
+}
+
+// TestDefragmentRespectsBudget tests that a host is not freed if its tasks
+// can not all be moved within the availability budget of their job
+func (suite *DefragmenterTestSuite) TestDefragmentRespectsBudget() {
+	hosts := []*models.Host{
+		createHost("host-1", createTask("a", "1", 1, 1), createTask("a", "2", 1, 1)),
+		createHost("host-2", createTask("b", "1", 4, 1), createTask("b", "2", 4, 1)),
+	}
+	suite.hostService.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(hosts, nil)
+	suite.expectGPUDemand()
+	suite.expectPods("a")
+	suite.expectPods("b")
+
+	moves, err := suite.defragmenter.Defragment(context.Background())
+	suite.NoError(err)
+	suite.Empty(moves)
+}
+
+// TestDefragmentWithinBudget tests that all tasks of a host are moved if
+// the availability budget of their job allows it
+func (suite *DefragmenterTestSuite) TestDefragmentWithinBudget() {
+	hosts := []*models.Host{
+		createHost("host-1", createTask("a", "1", 1, 2), createTask("a", "2", 1, 2)),
+		createHost("host-2", createTask("b", "1", 4, 1), createTask("b", "2", 4, 1)),
+	}
+	suite.hostService.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(hosts, nil)
+	suite.expectGPUDemand()
+	suite.expectPods("a",
+		createPod("a", "1", "host-1"),
+		createPod("a", "2", "host-1"))
+	suite.expectPods("b")
+	suite.expectRestart("host-1", "a-1", "a-2")
+
+	moves, err := suite.defragmenter.Defragment(context.Background())
+	suite.NoError(err)
+	suite.Len(moves, 2)
+	for _, move := range moves {
+		suite.Equal("host-2", move.TargetHost)
+	}
+}
+
+// TestDefragmentUnavailableInstances tests that the instances of a job which
+// are already unavailable are subtracted from its availability budget
+func (suite *DefragmenterTestSuite) TestDefragmentUnavailableInstances() {
+	unhealthy := createPod("a", "3", "host-3")
+	unhealthy.Status.ContainersStatus = []*pod.ContainerStatus{{
+		Healthy: &pod.HealthStatus{State: pod.HealthState_HEALTH_STATE_UNHEALTHY},
+	}}
+	updating := createPod("a", "4", "host-3")
+	updating.Status.Version = &v1alphapeloton.EntityVersion{Value: "1-0-0"}
+	updating.Status.DesiredVersion = &v1alphapeloton.EntityVersion{Value: "2-0-0"}
+	killed := createPod("a", "5", "")
+	killed.Status.State = pod.PodState_POD_STATE_KILLED
+	killed.Status.DesiredState = pod.PodState_POD_STATE_KILLED
+
+	hosts := []*models.Host{
+		createHost("host-1", createTask("a", "1", 1, 4), createTask("a", "2", 1, 4)),
+		createHost("host-2", createTask("b", "1", 4, 1), createTask("b", "2", 4, 1)),
+	}
+	suite.hostService.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(hosts, nil)
+	suite.expectGPUDemand()
+	suite.expectPods("a",
+		createPod("a", "1", "host-1"),
+		createPod("a", "2", "host-1"),
+		unhealthy,
+		updating,
+		killed)
+	suite.expectPods("b")
+
+	// 3 of the 4 instances allowed to be unavailable are unavailable
+	// already, so only one of the two tasks on host-1 can be moved
+	moves, err := suite.defragmenter.Defragment(context.Background())
+	suite.NoError(err)
+	suite.Empty(moves)
+}
+
+// TestDefragmentWithoutUnfitDemand tests that no host is freed if all tasks
+// waiting for placement fit on some host
+func (suite *DefragmenterTestSuite) TestDefragmentWithoutUnfitDemand() {
+	hosts := []*models.Host{
+		createHost("host-1", createTask("a", "1", 2, 1)),
+		createHost("host-2", createTask("b", "1", 4, 1), createTask("b", "2", 4, 1)),
+	}
+	suite.hostService.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(hosts, nil)
+	suite.expectPendingTasks(createTask("c", "1", 6, 1))
+
+	moves, err := suite.defragmenter.Defragment(context.Background())
+	suite.NoError(err)
+	suite.Empty(moves)
+}
+
+// TestDefragmentGetPendingTasksFailure tests that an error getting the
+// tasks waiting for placement fails the round
+func (suite *DefragmenterTestSuite) TestDefragmentGetPendingTasksFailure() {
+	suite.hostService.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]*models.Host{createHost("host-1")}, nil)
+	suite.resmgrClient.EXPECT().
+		GetActiveTasks(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("error"))
+
+	moves, err := suite.defragmenter.Defragment(context.Background())
+	suite.Error(err)
+	suite.Nil(moves)
+}
+
+// TestDefragmentQueryPodsFailure tests that a host is not freed if the
+// availability budget of its jobs can not be computed
+func (suite *DefragmenterTestSuite) TestDefragmentQueryPodsFailure() {
+	hosts := []*models.Host{
+		createHost("host-1", createTask("a", "1", 2, 1)),
+		createHost("host-2", createTask("b", "1", 4, 1)),
+	}
+	suite.hostService.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(hosts, nil)
+	suite.expectGPUDemand()
+	suite.jobClient.EXPECT().
+		QueryPods(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("error")).
+		AnyTimes()
+
+	moves, err := suite.defragmenter.Defragment(context.Background())
+	suite.NoError(err)
+	suite.Empty(moves)
+}
+
+// TestUnavailableInstancesCountsMoves tests that a move is counted against
+// the budget of its job until job manager reports the task as restarted
+func (suite *DefragmenterTestSuite) TestUnavailableInstancesCountsMoves() {
+	now := time.Now()
+	suite.defragmenter.moves["a-1"] = &Move{
+		TaskID:     "a-1",
+		JobID:      "a",
+		SourceHost: "host-1",
+		Deadline:   now.Add(time.Minute),
+	}
+	suite.defragmenter.moves["a-2"] = &Move{
+		TaskID:     "a-2",
+		JobID:      "a",
+		SourceHost: "host-1",
+		Deadline:   now.Add(time.Minute),
+	}
+	suite.defragmenter.moves["a-3"] = &Move{
+		TaskID:     "a-3",
+		JobID:      "a",
+		SourceHost: "host-1",
+		Deadline:   now.Add(-time.Minute),
+	}
+	suite.expectPods("a",
+		// not restarted yet
+		createPod("a", "1", "host-1"),
+		// restarted on another host
+		createPod("a", "2", "host-2"),
+		createPod("a", "3", "host-1"))
+
+	unavailable, err := suite.defragmenter.unavailableInstances(
+		context.Background(), "a", now)
+	suite.NoError(err)
+	suite.Equal(1, unavailable)
+	suite.NotContains(suite.defragmenter.moves, "a-3")
+}
+
+// TestLeadershipCallbacks tests that the defragmenter forgets its moves
+// when losing leadership
+func (suite *DefragmenterTestSuite) TestLeadershipCallbacks() {
+	suite.Equal("placement-1", suite.defragmenter.GetID())
+
+	suite.NoError(suite.defragmenter.GainedLeadershipCallback())
+	suite.defragmenter.lock.Lock()
+	suite.defragmenter.moves["a-1"] = &Move{TaskID: "a-1"}
+	suite.defragmenter.lock.Unlock()
+
+	suite.NoError(suite.defragmenter.LostLeadershipCallback())
+	suite.Empty(suite.defragmenter.moves)
+	suite.NoError(suite.defragmenter.ShutDownCallback())
+}
+
+// TestDefragmentNoRoom tests that a host is not freed if its tasks do not
+// fit on any other host running tasks
+func (suite *DefragmenterTestSuite) TestDefragmentNoRoom() {
+	hosts := []*models.Host{
+		createHost("host-1", createTask("a", "1", 4, 1)),
+		createHost("host-2", createTask("b", "1", 8, 1)),
+	}
+	suite.hostService.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(hosts, nil)
+	suite.expectGPUDemand()
+	suite.expectPods("a")
+	suite.expectPods("b")
+
+	moves, err := suite.defragmenter.Defragment(context.Background())
+	suite.NoError(err)
+	suite.Empty(moves)
+}
+
+// TestDefragmentGetHostsFailure tests that an error getting the hosts fails
+// the round
+func (suite *DefragmenterTestSuite) TestDefragmentGetHostsFailure() {
+	suite.hostService.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("error"))
+
+	moves, err := suite.defragmenter.Defragment(context.Background())
+	suite.Error(err)
+	suite.Nil(moves)
+}
+
+// TestDefragmentRestartFailure tests that a move is dropped if the task
+// can not be restarted
+func (suite *DefragmenterTestSuite) TestDefragmentRestartFailure() {
+	hosts := []*models.Host{
+		createHost("host-1", createTask("a", "1", 2, 1)),
+		createHost("host-2", createTask("b", "1", 4, 1)),
+	}
+	suite.hostService.EXPECT().
+		GetHosts(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(hosts, nil)
+	suite.expectGPUDemand()
+	suite.expectPods("a")
+	suite.expectPods("b")
+	suite.podClient.EXPECT().
+		RestartPod(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("error"))
+
+	moves, err := suite.defragmenter.Defragment(context.Background())
+	suite.NoError(err)
+	suite.Empty(moves)
+	suite.Empty(suite.defragmenter.moves)
+}
@@ -25,9 +25,11 @@ import (
 	"github.com/uber/peloton/pkg/placement/plugins"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/pkg/common/async"
+	"github.com/uber/peloton/pkg/common/constraints"
 	"github.com/uber/peloton/pkg/common/queue"
 	"github.com/uber/peloton/pkg/placement/config"
 	"github.com/uber/peloton/pkg/placement/hosts"
@@ -153,6 +155,12 @@ func (e *engine) Place(ctx context.Context) time.Duration {
 		return _noTasksTimeoutPenalty
 	}
 
+	// keep tasks moved away from a host off that host
+	avoidHosts(assignments)
+
+	// count the running tasks of jobs with spread constraints
+	e.fillSpreadHosts(ctx, assignments)
+
 	// process revocable assignments
 	e.processAssignments(
 		ctx,
@@ -177,6 +185,86 @@ func (e *engine) Place(ctx context.Context) time.Duration {
 	return time.Duration(0)
 }
 
+// fillSpreadHosts sets the hosts running tasks of the job on the tasks
+// with spread constraints, so that the strategies count all the tasks
+// of the job and not only those on the hosts with offers. If the hosts
+// cannot be fetched the tasks are spread over the offered hosts only.
+func (e *engine) fillSpreadHosts(
+	ctx context.Context,
+	assignments []*models.Assignment) {
+	jobTasks := make(map[string][]*models.Task)
+	for _, assignment := range assignments {
+		task := assignment.GetTask()
+		if len(constraints.GetSpreadConstraints(
+			task.GetTask().GetConstraint())) == 0 {
+			continue
+		}
+		jobID := task.GetTask().GetJobId().GetValue()
+		jobTasks[jobID] = append(jobTasks[jobID], task)
+	}
+
+	for jobID, jobTaskList := range jobTasks {
+		hostnames, err := e.taskService.GetHostnames(ctx, jobID)
+		if err != nil {
+			log.WithField("job_id", jobID).
+				WithError(err).
+				Warn("failed to get the hosts of the tasks of the job")
+			continue
+		}
+		hosts, err := e.hostsService.GetHostsByHostnames(
+			ctx, e.config.TaskType, hostnames)
+		if err != nil {
+			log.WithField("job_id", jobID).
+				WithField("hostnames", hostnames).
+				WithError(err).
+				Warn("failed to get the hosts of the tasks of the job")
+			continue
+		}
+		for _, task := range jobTaskList {
+			task.SetSpreadHosts(hosts)
+		}
+	}
+}
+
+// avoidHosts adds a constraint to the tasks which are moved away from a
+// host, e.g. by the defragmenter, such that they are not placed on that
+// host again.
+func avoidHosts(assignments []*models.Assignment) {
+	for _, assignment := range assignments {
+		t := assignment.GetTask().GetTask()
+		if len(t.GetAvoidHost()) == 0 {
+			continue
+		}
+		t.Constraint = avoidHost(t.GetConstraint(), t.GetAvoidHost())
+	}
+}
+
+// avoidHost returns the constraint combined with a constraint which
+// rejects the given host.
+func avoidHost(constraint *task.Constraint, hostname string) *task.Constraint {
+	avoid := &task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind:      task.LabelConstraint_HOST,
+			Condition: task.LabelConstraint_CONDITION_EQUAL,
+			Label: &peloton.Label{
+				Key:   constraints.HostNameKey,
+				Value: hostname,
+			},
+			Requirement: 0,
+		},
+	}
+	if constraint == nil {
+		return avoid
+	}
+	return &task.Constraint{
+		Type: task.Constraint_AND_CONSTRAINT,
+		AndConstraint: &task.AndConstraint{
+			Constraints: []*task.Constraint{constraint, avoid},
+		},
+	}
+}
+
 // processAssignments processes assignments by creating correct host filters and
 // then finding host to place them on.
 func (e *engine) processAssignments(
@@ -23,11 +23,14 @@ import (
 
 	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 
 	"github.com/uber/peloton/pkg/common/async"
+	"github.com/uber/peloton/pkg/common/constraints"
 	"github.com/uber/peloton/pkg/placement/config"
+	hosts_mock "github.com/uber/peloton/pkg/placement/hosts/mocks"
 	"github.com/uber/peloton/pkg/placement/metrics"
 	"github.com/uber/peloton/pkg/placement/models"
 	offers_mock "github.com/uber/peloton/pkg/placement/offers/mocks"
@@ -182,6 +185,38 @@ func TestEnginePlaceMultipleTasks(t *testing.T) {
 	assert.Equal(t, 0, failed)
 }
 
+func TestEngineAvoidHosts(t *testing.T) {
+	deadline := time.Now().Add(time.Second)
+	moved := testutil.SetupAssignment(deadline, 1)
+	other := testutil.SetupAssignment(deadline, 1)
+	moved.GetTask().GetTask().AvoidHost = "host-1"
+	movedConstraint := moved.GetTask().GetTask().GetConstraint()
+	otherConstraint := other.GetTask().GetTask().GetConstraint()
+
+	avoidHosts([]*models.Assignment{moved, other})
+
+	constraint := moved.GetTask().GetTask().GetConstraint()
+	assert.Equal(t, task.Constraint_AND_CONSTRAINT, constraint.GetType())
+	subConstraints := constraint.GetAndConstraint().GetConstraints()
+	assert.Len(t, subConstraints, 2)
+	assert.Equal(t, movedConstraint, subConstraints[0])
+	assert.Equal(t, constraints.HostNameKey,
+		subConstraints[1].GetLabelConstraint().GetLabel().GetKey())
+	assert.Equal(t, "host-1",
+		subConstraints[1].GetLabelConstraint().GetLabel().GetValue())
+	assert.Equal(t, uint32(0),
+		subConstraints[1].GetLabelConstraint().GetRequirement())
+
+	assert.Equal(t, otherConstraint, other.GetTask().GetTask().GetConstraint())
+}
+
+func TestEngineAvoidHostWithoutConstraint(t *testing.T) {
+	constraint := avoidHost(nil, "host-1")
+	assert.Equal(t, task.Constraint_LABEL_CONSTRAINT, constraint.GetType())
+	assert.Equal(t, task.LabelConstraint_HOST, constraint.GetLabelConstraint().GetKind())
+	assert.Equal(t, "host-1", constraint.GetLabelConstraint().GetLabel().GetValue())
+}
+
 func TestEnginePlaceSubsetOfTasksDueToInsufficientResources(t *testing.T) {
 	ctrl, engine, mockOfferService, mockTaskService, _ := setupEngine(t)
 	defer ctrl.Finish()
@@ -712,3 +747,50 @@ func TestProcessCompletedReservations(t *testing.T) {
 	err = engine.processCompletedReservations(context.Background())
 	assert.NoError(t, err)
 }
+
+// TestEngineFillSpreadHosts tests that the hosts running tasks of the job
+// are set on the tasks with spread constraints only.
+func TestEngineFillSpreadHosts(t *testing.T) {
+	ctrl, engine, _, mockTaskService, _ := setupEngine(t)
+	defer ctrl.Finish()
+	mockHostsService := hosts_mock.NewMockService(ctrl)
+	engine.hostsService = mockHostsService
+
+	deadline := time.Now().Add(time.Second)
+	spread := testutil.SetupAssignment(deadline, 1)
+	spread.GetTask().GetTask().JobId = &peloton.JobID{Value: "job1"}
+	spread.GetTask().GetTask().Constraint = &task.Constraint{
+		Type: task.Constraint_SPREAD_CONSTRAINT,
+		SpreadConstraint: &task.SpreadConstraint{
+			HostAttribute: "rack",
+			MaxSkew:       1,
+		},
+	}
+	failed := testutil.SetupAssignment(deadline, 1)
+	failed.GetTask().GetTask().JobId = &peloton.JobID{Value: "job2"}
+	failed.GetTask().GetTask().Constraint = spread.GetTask().GetTask().Constraint
+	other := testutil.SetupAssignment(deadline, 1)
+
+	hosts := []*models.Host{
+		models.NewHosts(&hostsvc.HostInfo{Hostname: "hostname1"}, nil),
+	}
+	mockTaskService.EXPECT().
+		GetHostnames(gomock.Any(), "job1").
+		Return([]string{"hostname1"}, nil)
+	mockHostsService.EXPECT().
+		GetHostsByHostnames(
+			gomock.Any(),
+			resmgr.TaskType_BATCH,
+			[]string{"hostname1"}).
+		Return(hosts, nil)
+	mockTaskService.EXPECT().
+		GetHostnames(gomock.Any(), "job2").
+		Return(nil, errors.New("error"))
+
+	engine.fillSpreadHosts(
+		context.Background(),
+		[]*models.Assignment{spread, failed, other})
+	assert.Equal(t, hosts, spread.GetTask().GetSpreadHosts())
+	assert.Empty(t, failed.GetTask().GetSpreadHosts())
+	assert.Empty(t, other.GetTask().GetSpreadHosts())
+}
@@ -19,9 +19,12 @@ import (
 	"errors"
 	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+	"github.com/uber/peloton/pkg/common/constraints"
 	"github.com/uber/peloton/pkg/placement/metrics"
 	"github.com/uber/peloton/pkg/placement/models"
 
@@ -45,6 +48,10 @@ type Service interface {
 	// GetHosts fetches a batch of hosts from the host manager matching filter.
 	GetHosts(ctx context.Context, task *resmgr.Task, filter *hostsvc.HostFilter) (hosts []*models.Host, err error)
 
+	// GetHostsByHostnames fetches the hosts with the given hostnames,
+	// with the tasks of the task type running on them, from the host manager.
+	GetHostsByHostnames(ctx context.Context, taskType resmgr.TaskType, hostnames []string) (hosts []*models.Host, err error)
+
 	// ReserveHost Makes reservation for the host in hostmanager.
 	ReserveHost(ctx context.Context, host []*models.Host, task *resmgr.Task) (err error)
 
@@ -113,6 +120,49 @@ func (s *service) GetHosts(
 	return s.fillTasksInHost(res.GetHosts(), hostTasksMap), nil
 }
 
+// GetHostsByHostnames fetches the hosts with the given hostnames, whether
+// or not they have offers, along with the tasks running on them.
+func (s *service) GetHostsByHostnames(
+	ctx context.Context,
+	taskType resmgr.TaskType,
+	hostnames []string) ([]*models.Host, error) {
+	if len(hostnames) == 0 {
+		return nil, nil
+	}
+	return s.GetHosts(
+		ctx,
+		&resmgr.Task{Type: taskType},
+		&hostsvc.HostFilter{
+			SchedulingConstraint: hostnamesConstraint(hostnames),
+		})
+}
+
+// hostnamesConstraint returns a constraint which matches the hosts
+// with any of the given hostnames.
+func hostnamesConstraint(hostnames []string) *task.Constraint {
+	orConstraint := &task.OrConstraint{}
+	for _, hostname := range hostnames {
+		orConstraint.Constraints = append(
+			orConstraint.Constraints,
+			&task.Constraint{
+				Type: task.Constraint_LABEL_CONSTRAINT,
+				LabelConstraint: &task.LabelConstraint{
+					Kind:      task.LabelConstraint_HOST,
+					Condition: task.LabelConstraint_CONDITION_EQUAL,
+					Label: &peloton.Label{
+						Key:   constraints.HostNameKey,
+						Value: hostname,
+					},
+					Requirement: 1,
+				},
+			})
+	}
+	return &task.Constraint{
+		Type:         task.Constraint_OR_CONSTRAINT,
+		OrConstraint: orConstraint,
+	}
+}
+
 // fillTasksInHost creates host Info into placement hosts.
 // One key notion is to add already running tasks on this host
 // such that placement can take care of task-task affinity.
@@ -122,6 +122,58 @@ func (suite *ServiceTestSuite) TestHostsService_AcquireHosts() {
 	suite.Equal(1, len(hostsRet[0].Tasks))
 }
 
+// TestHostsService_GetHostsByHostnames tests fetching hosts by their
+// hostnames, with the tasks running on them
+func (suite *ServiceTestSuite) TestHostsService_GetHostsByHostnames() {
+	defer suite.mockCtrl.Finish()
+
+	hostsRet, err := suite.hostService.GetHostsByHostnames(
+		context.Background(), resmgr.TaskType_BATCH, nil)
+	suite.NoError(err)
+	suite.Empty(hostsRet)
+
+	task := createResMgrTask()
+	gomock.InOrder(
+		suite.hostMgrClient.EXPECT().
+			GetHosts(
+				gomock.Any(),
+				getHostRequest(&hostsvc.HostFilter{
+					SchedulingConstraint: hostnamesConstraint(
+						[]string{_hostname}),
+				}),
+			).Return(&hostsvc.GetHostsResponse{
+			Hosts: []*hostsvc.HostInfo{
+				{
+					Hostname: _hostname,
+				},
+			},
+		}, nil),
+		suite.resmgrClient.EXPECT().
+			GetTasksByHosts(gomock.Any(),
+				&resmgrsvc.GetTasksByHostsRequest{
+					Type:      resmgr.TaskType_BATCH,
+					Hostnames: []string{_hostname},
+				},
+			).Return(
+			&resmgrsvc.GetTasksByHostsResponse{
+				HostTasksMap: map[string]*resmgrsvc.TaskList{
+					_hostname: {
+						Tasks: []*resmgr.Task{
+							task,
+						},
+					},
+				},
+			}, nil),
+	)
+
+	hostsRet, err = suite.hostService.GetHostsByHostnames(
+		context.Background(), resmgr.TaskType_BATCH, []string{_hostname})
+	suite.NoError(err)
+	suite.Equal(1, len(hostsRet))
+	suite.Equal(_hostname, hostsRet[0].GetHost().GetHostname())
+	suite.Equal([]*resmgr.Task{task}, hostsRet[0].GetTasks())
+}
+
 // TestHostsService_ReserveHosts tests the ReserveHosts call
 func (suite *ServiceTestSuite) TestHostsService_ReserveHosts() {
 	defer suite.mockCtrl.Finish()
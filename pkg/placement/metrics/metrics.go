@@ -89,6 +89,26 @@ type Metrics struct {
 	// HostGetFail indicates the number of times the scheduler requested
 	// an Host and it failed
 	HostGetFail tally.Counter
+
+	// Defragmenter Metrics
+
+	// DefragRun counts the number of successful defragmentation rounds
+	DefragRun tally.Counter
+
+	// DefragRunFail counts the number of failed defragmentation rounds
+	DefragRunFail tally.Counter
+
+	// DefragHostFreed counts the number of hosts the defragmenter started
+	// to free
+	DefragHostFreed tally.Counter
+
+	// DefragTaskMove counts the number of tasks restarted by the
+	// defragmenter
+	DefragTaskMove tally.Counter
+
+	// DefragTaskMoveFail counts the number of tasks the defragmenter
+	// failed to restart
+	DefragTaskMoveFail tally.Counter
 }
 
 // NewMetrics returns a new Metrics struct with all metrics initialized and
@@ -98,6 +118,7 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	offerScope := scope.SubScope("offer")
 	hostScope := scope.SubScope("host")
 	placementScope := scope.SubScope("placement")
+	defragScope := scope.SubScope("defrag")
 
 	taskSuccessScope := taskScope.Tagged(map[string]string{"result": "success"})
 	taskFailScope := taskScope.Tagged(map[string]string{"result": "fail"})
@@ -112,6 +133,9 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	placementFailScope := placementScope.Tagged(map[string]string{"result": "fail"})
 	placementTimeScope := placementScope.Tagged(map[string]string{"type": "timer"})
 
+	defragSuccessScope := defragScope.Tagged(map[string]string{"result": "success"})
+	defragFailScope := defragScope.Tagged(map[string]string{"result": "fail"})
+
 	return &Metrics{
 		Running:      scope.Gauge("running"),
 		OfferStarved: scope.Counter("offer_starved"),
@@ -136,5 +160,11 @@ func NewMetrics(scope tally.Scope) *Metrics {
 
 		HostGet:     HostSuccessScope.Counter("get"),
 		HostGetFail: HostFailScope.Counter("get"),
+
+		DefragRun:          defragSuccessScope.Counter("run"),
+		DefragRunFail:      defragFailScope.Counter("run"),
+		DefragHostFreed:    defragSuccessScope.Counter("host_freed"),
+		DefragTaskMove:     defragSuccessScope.Counter("task_move"),
+		DefragTaskMoveFail: defragFailScope.Counter("task_move"),
 	}
 }
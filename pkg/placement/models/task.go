@@ -52,6 +52,10 @@ type Task struct {
 	// PlacementDeadline when the task should successfully placed
 	// on the desired host or have failed to do so.
 	PlacementDeadline time.Time `json:"placement_deadline"`
+	// SpreadHosts are the hosts running tasks of the job of the task,
+	// whether or not they have offers, used to count the tasks of the
+	// job on each host attribute value for spread constraints.
+	SpreadHosts []*Host `json:"spread_hosts"`
 	// data is used by placement strategies to transfer state between calls to the
 	// place once method.
 	data interface{}
@@ -108,6 +112,16 @@ func (task *Task) SetRounds(rounds int) {
 	task.Rounds = rounds
 }
 
+// GetSpreadHosts returns the hosts running tasks of the job of the task.
+func (task *Task) GetSpreadHosts() []*Host {
+	return task.SpreadHosts
+}
+
+// SetSpreadHosts sets the hosts running tasks of the job of the task.
+func (task *Task) SetSpreadHosts(hosts []*Host) {
+	task.SpreadHosts = hosts
+}
+
 // SetData will set the data transfer object on the task.
 func (task *Task) SetData(data interface{}) {
 	task.lock.Lock()
@@ -61,6 +61,15 @@ func TestTask_Task(t *testing.T) {
 	assert.Equal(t, "task1", task.GetTask().GetName())
 }
 
+func TestTask_SpreadHosts(t *testing.T) {
+	_, _, _, task := setupTaskVariables()
+	assert.Nil(t, task.GetSpreadHosts())
+
+	hosts := []*Host{NewHosts(nil, []*resmgr.Task{{Name: "task1"}})}
+	task.SetSpreadHosts(hosts)
+	assert.Equal(t, hosts, task.GetSpreadHosts())
+}
+
 func TestTask_DataAndSetData(t *testing.T) {
 	_, _, _, task := setupTaskVariables()
 	assert.Nil(t, task.Data())
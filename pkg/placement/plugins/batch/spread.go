@@ -0,0 +1,153 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package batch
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/pkg/common/constraints"
+	"github.com/uber/peloton/pkg/placement/models"
+)
+
+// spreadKey identifies the tasks of a job spread over a host attribute.
+type spreadKey struct {
+	jobID     string
+	attribute string
+}
+
+// spreadCounts tracks the number of tasks of a job on each value of a host
+// attribute, for the spread constraints of the tasks being placed. The
+// running tasks of the job are counted on all hosts running them, including
+// hosts without offers in the current round, and tasks placed in the current
+// round are added as they are placed.
+type spreadCounts map[spreadKey]map[string]int
+
+// newSpreadCounts creates the spread counts of the spread constraints of the
+// unassigned tasks from the hosts running tasks of their jobs. The tasks
+// running on the offered hosts are not used, as the batch strategy does not
+// fetch them, but the attribute values of the offered hosts are included
+// with a count of zero if no task of the job runs on them.
+func newSpreadCounts(
+	unassigned []*models.Assignment,
+	hosts []*models.HostOffers) spreadCounts {
+	counts := spreadCounts{}
+	for _, assignment := range unassigned {
+		task := assignment.GetTask().GetTask()
+		for _, spread := range constraints.GetSpreadConstraints(task.GetConstraint()) {
+			key := spreadKey{
+				jobID:     task.GetJobId().GetValue(),
+				attribute: spread.GetHostAttribute(),
+			}
+			if _, ok := counts[key]; ok {
+				continue
+			}
+			values := map[string]int{}
+			for _, host := range hosts {
+				value, ok := hostAttributeValue(
+					host.GetOffer().GetAttributes(), key.attribute)
+				if !ok {
+					continue
+				}
+				if _, ok := values[value]; !ok {
+					values[value] = 0
+				}
+			}
+			for _, host := range assignment.GetTask().GetSpreadHosts() {
+				value, ok := hostAttributeValue(
+					host.GetHost().GetAttributes(), key.attribute)
+				if !ok {
+					continue
+				}
+				for _, hostTask := range host.GetTasks() {
+					if hostTask.GetJobId().GetValue() == key.jobID {
+						values[value]++
+					}
+				}
+			}
+			counts[key] = values
+		}
+	}
+	return counts
+}
+
+// fits returns true if placing the task on the host keeps the number of
+// tasks of the job within the max skew for all spread constraints of the task.
+func (counts spreadCounts) fits(
+	task *resmgr.Task,
+	host *models.HostOffers) bool {
+	for _, spread := range constraints.GetSpreadConstraints(task.GetConstraint()) {
+		value, ok := hostAttributeValue(
+			host.GetOffer().GetAttributes(), spread.GetHostAttribute())
+		if !ok {
+			return false
+		}
+		values := counts[spreadKey{
+			jobID:     task.GetJobId().GetValue(),
+			attribute: spread.GetHostAttribute(),
+		}]
+		min := math.MaxInt32
+		for _, count := range values {
+			if count < min {
+				min = count
+			}
+		}
+		if values[value]+1-min > int(constraints.GetMaxSkew(spread)) {
+			return false
+		}
+	}
+	return true
+}
+
+// add records that the task has been placed on the host.
+func (counts spreadCounts) add(
+	task *resmgr.Task,
+	host *models.HostOffers) {
+	for _, spread := range constraints.GetSpreadConstraints(task.GetConstraint()) {
+		value, ok := hostAttributeValue(
+			host.GetOffer().GetAttributes(), spread.GetHostAttribute())
+		if !ok {
+			continue
+		}
+		key := spreadKey{
+			jobID:     task.GetJobId().GetValue(),
+			attribute: spread.GetHostAttribute(),
+		}
+		if values, ok := counts[key]; ok {
+			values[value]++
+		}
+	}
+}
+
+// hostAttributeValue returns the value of a text or scalar attribute
+// among the attributes of a host.
+func hostAttributeValue(
+	attributes []*mesos_v1.Attribute,
+	name string) (string, bool) {
+	for _, attribute := range attributes {
+		if attribute.GetName() != name {
+			continue
+		}
+		switch attribute.GetType() {
+		case mesos_v1.Value_TEXT:
+			return attribute.GetText().GetValue(), true
+		case mesos_v1.Value_SCALAR:
+			return fmt.Sprintf("%v", attribute.GetScalar().GetValue()), true
+		}
+	}
+	return "", false
+}
@@ -35,13 +35,27 @@ type batch struct{}
 
 // PlaceOnce is an implementation of the placement.Strategy interface.
 func (batch *batch) PlaceOnce(unassigned []*models.Assignment, hosts []*models.HostOffers) {
+	spread := newSpreadCounts(unassigned, hosts)
+	remains := make([]*hostRemain, 0, len(hosts))
 	for _, host := range hosts {
-		log.WithFields(log.Fields{
-			"unassigned": unassigned,
-			"hosts":      hosts,
-		}).Debug("PlaceOnce batch strategy called")
+		remains = append(remains, batch.newHostRemain(host))
+	}
+
+	for {
+		before := len(unassigned)
+		for _, remain := range remains {
+			log.WithFields(log.Fields{
+				"unassigned": unassigned,
+				"hosts":      hosts,
+			}).Debug("PlaceOnce batch strategy called")
 
-		unassigned = batch.fillOffer(host, unassigned)
+			unassigned = batch.fillOffer(remain, unassigned, spread)
+		}
+		// Tasks skipped because of a spread constraint may fit on a host
+		// visited earlier once the other attribute values have caught up.
+		if len(unassigned) == 0 || len(unassigned) == before {
+			break
+		}
 	}
 
 	log.WithFields(log.Fields{
@@ -51,6 +65,21 @@ func (batch *batch) PlaceOnce(unassigned []*models.Assignment, hosts []*models.H
 	}).Info("PlaceOnce batch strategy returned")
 }
 
+// hostRemain tracks the resources of a host which are not yet assigned.
+type hostRemain struct {
+	host      *models.HostOffers
+	resources scalar.Resources
+	ports     uint64
+}
+
+func (batch *batch) newHostRemain(host *models.HostOffers) *hostRemain {
+	return &hostRemain{
+		host:      host,
+		resources: scalar.FromMesosResources(host.GetOffer().GetResources()),
+		ports:     batch.availablePorts(host.GetOffer().GetResources()),
+	}
+}
+
 func (batch *batch) availablePorts(resources []*mesos_v1.Resource) uint64 {
 	var ports uint64
 	for _, resource := range resources {
@@ -64,38 +93,50 @@ func (batch *batch) availablePorts(resources []*mesos_v1.Resource) uint64 {
 	return ports
 }
 
-// fillOffer assigns in sequence as many tasks as possible to the given offers in a host,
-// and returns a list of tasks not assigned to that host.
-
-func (batch *batch) fillOffer(host *models.HostOffers, unassigned []*models.Assignment) []*models.Assignment {
-	remainPorts := batch.availablePorts(host.GetOffer().GetResources())
-	remain := scalar.FromMesosResources(host.GetOffer().GetResources())
+// fillOffer assigns in sequence as many tasks as possible to the remaining resources of a host,
+// and returns a list of tasks not assigned to that host. Tasks which would violate a spread
+// constraint on the host are skipped.
+func (batch *batch) fillOffer(
+	remain *hostRemain,
+	unassigned []*models.Assignment,
+	spread spreadCounts) []*models.Assignment {
+	var skipped []*models.Assignment
 	for i, placement := range unassigned {
 		resmgrTask := placement.GetTask().GetTask()
+		if !spread.fits(resmgrTask, remain.host) {
+			log.WithFields(log.Fields{
+				"resmgr_task": resmgrTask,
+				"hostname":    remain.host.GetOffer().GetHostname(),
+			}).Debug("Spread constraint not satisfied.")
+			skipped = append(skipped, placement)
+			continue
+		}
+
 		usedPorts := uint64(resmgrTask.GetNumPorts())
-		if usedPorts > remainPorts {
+		if usedPorts > remain.ports {
 			log.WithFields(log.Fields{
 				"resmgr_task":         resmgrTask,
-				"num_available_ports": remainPorts,
+				"num_available_ports": remain.ports,
 			}).Debug("Insufficient ports resources.")
-			return unassigned[i:]
+			return append(skipped, unassigned[i:]...)
 		}
 
 		usage := scalar.FromResourceConfig(placement.GetTask().GetTask().GetResource())
-		trySubtract, ok := remain.TrySubtract(usage)
+		trySubtract, ok := remain.resources.TrySubtract(usage)
 		if !ok {
 			log.WithFields(log.Fields{
-				"remain": remain,
+				"remain": remain.resources,
 				"usage":  usage,
 			}).Debug("Insufficient resources remain")
-			return unassigned[i:]
+			return append(skipped, unassigned[i:]...)
 		}
 
-		remainPorts -= usedPorts
-		remain = trySubtract
-		placement.SetHost(host)
+		remain.ports -= usedPorts
+		remain.resources = trySubtract
+		spread.add(resmgrTask, remain.host)
+		placement.SetHost(remain.host)
 	}
-	return nil
+	return skipped
 }
 
 func (batch *batch) getHostFilter(assignment *models.Assignment) *hostsvc.HostFilter {
@@ -19,6 +19,11 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/pkg/placement/models"
 	"github.com/uber/peloton/pkg/placement/testutil"
 )
@@ -59,6 +64,128 @@ func TestBatchPlaceOneFreeHost(t *testing.T) {
 	assert.Equal(t, offers[0], assignments[1].GetHost())
 }
 
+// setupSpreadAssignments creates assignments of tasks of the same job
+// which are spread over racks.
+func setupSpreadAssignments(count int) []*models.Assignment {
+	var assignments []*models.Assignment
+	for i := 0; i < count; i++ {
+		assignment := testutil.SetupAssignment(time.Now().Add(10*time.Second), 1)
+		assignment.GetTask().GetTask().JobId = &peloton.JobID{Value: "job"}
+		assignment.GetTask().GetTask().Resource.CpuLimit = 5
+		assignment.GetTask().GetTask().Constraint = &task.Constraint{
+			Type: task.Constraint_SPREAD_CONSTRAINT,
+			SpreadConstraint: &task.SpreadConstraint{
+				HostAttribute: "rack",
+				MaxSkew:       1,
+			},
+		}
+		assignments = append(assignments, assignment)
+	}
+	return assignments
+}
+
+// rackAttributes returns the attributes of a host in the given rack.
+func rackAttributes(rack string) []*mesos_v1.Attribute {
+	if len(rack) == 0 {
+		return nil
+	}
+	name := "rack"
+	textType := mesos_v1.Value_TEXT
+	return []*mesos_v1.Attribute{
+		{
+			Name: &name,
+			Type: &textType,
+			Text: &mesos_v1.Value_Text{Value: &rack},
+		},
+	}
+}
+
+// setupRackHostOffers creates a host offer in the given rack.
+func setupRackHostOffers(hostname, rack string) *models.HostOffers {
+	host := testutil.SetupHostOffers()
+	host.Offer.Hostname = hostname
+	host.Offer.Attributes = rackAttributes(rack)
+	return host
+}
+
+// setupRackHost creates a host in the given rack running the
+// given number of tasks of the job.
+func setupRackHost(hostname, rack string, tasks int) *models.Host {
+	var hostTasks []*resmgr.Task
+	for i := 0; i < tasks; i++ {
+		hostTasks = append(hostTasks, &resmgr.Task{
+			JobId: &peloton.JobID{Value: "job"},
+		})
+	}
+	hostTasks = append(hostTasks, &resmgr.Task{
+		JobId: &peloton.JobID{Value: "other-job"},
+	})
+	return models.NewHosts(&hostsvc.HostInfo{
+		Hostname:   hostname,
+		Attributes: rackAttributes(rack),
+	}, hostTasks)
+}
+
+// setSpreadHosts sets the hosts running tasks of the job on the assignments.
+func setSpreadHosts(assignments []*models.Assignment, hosts ...*models.Host) {
+	for _, assignment := range assignments {
+		assignment.GetTask().SetSpreadHosts(hosts)
+	}
+}
+
+func TestBatchPlaceSpread(t *testing.T) {
+	assignments := setupSpreadAssignments(4)
+	offers := []*models.HostOffers{
+		setupRackHostOffers("host1", "rack1"),
+		setupRackHostOffers("host2", "rack2"),
+		setupRackHostOffers("host3", ""),
+	}
+	strategy := New()
+	strategy.PlaceOnce(assignments, offers)
+
+	assert.Equal(t, offers[0], assignments[0].GetHost())
+	assert.Equal(t, offers[1], assignments[1].GetHost())
+	assert.Equal(t, offers[1], assignments[2].GetHost())
+	assert.Equal(t, offers[0], assignments[3].GetHost())
+}
+
+func TestBatchPlaceSpreadWithRunningTasks(t *testing.T) {
+	assignments := setupSpreadAssignments(2)
+	offers := []*models.HostOffers{
+		setupRackHostOffers("host1", "rack1"),
+		setupRackHostOffers("host2", "rack2"),
+	}
+	setSpreadHosts(assignments, setupRackHost("host1", "rack1", 2))
+	strategy := New()
+	strategy.PlaceOnce(assignments, offers)
+
+	assert.Equal(t, offers[1], assignments[0].GetHost())
+	assert.Equal(t, offers[1], assignments[1].GetHost())
+}
+
+// TestBatchPlaceSpreadWithRunningTasksOnHostsWithoutOffers tests that the
+// tasks of the job running on hosts without offers, such as full hosts,
+// are counted for spread constraints.
+func TestBatchPlaceSpreadWithRunningTasksOnHostsWithoutOffers(t *testing.T) {
+	assignments := setupSpreadAssignments(2)
+	offers := []*models.HostOffers{
+		setupRackHostOffers("host1", "rack1"),
+		setupRackHostOffers("host2", "rack2"),
+	}
+	// rack2 is running tasks of the job on a full host without offers,
+	// and the tasks running on offered hosts are not fetched
+	setSpreadHosts(
+		assignments,
+		setupRackHost("host3", "rack2", 2),
+		setupRackHost("host4", "rack1", 1),
+	)
+	strategy := New()
+	strategy.PlaceOnce(assignments, offers)
+
+	assert.Equal(t, offers[0], assignments[0].GetHost())
+	assert.Equal(t, offers[0], assignments[1].GetHost())
+}
+
 func TestBatchFiltersWithResources(t *testing.T) {
 	assignments := []*models.Assignment{
 		testutil.SetupAssignment(time.Now().Add(10*time.Second), 1),
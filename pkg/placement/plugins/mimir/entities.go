@@ -22,6 +22,7 @@ import (
 	"github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/pkg/common/constraints"
 	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/labels"
 	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/metrics"
 	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/orderings"
@@ -36,6 +37,9 @@ func TaskToEntity(task *resmgr.Task, isLaunched bool) *placement.Entity {
 		addMetrics(task, entity.Metrics)
 	}
 	addRelations(task.GetLabels(), entity.Relations)
+	if len(task.GetJobId().GetValue()) != 0 {
+		entity.Relations.Add(labels.NewLabel(JobID, task.GetJobId().GetValue()))
+	}
 
 	var order []placement.Ordering
 	// if the task has a desired host, add the host as the highest priority when picking group
@@ -43,6 +47,16 @@ func TaskToEntity(task *resmgr.Task, isLaunched bool) *placement.Entity {
 		order = append(order, orderings.Negate(orderings.Label(nil, labels.NewLabel(HostName, task.DesiredHost))))
 	}
 
+	// prefer the host attribute values with the fewest tasks of the job for spread constraints
+	for _, spread := range constraints.GetSpreadConstraints(task.GetConstraint()) {
+		order = append(order, newSpreadOrdering(task.GetJobId().GetValue(), spread))
+	}
+
+	// prefer the hosts with the highest weight of matching placement preferences
+	if preference := makePreferenceOrdering(task.GetPlacementPreferences()); preference != nil {
+		order = append(order, preference)
+	}
+
 	order = append(order,
 		orderings.Negate(orderings.Metric(orderings.GroupSource, DiskFree)),
 		orderings.Negate(orderings.Metric(orderings.GroupSource, MemoryFree)),
@@ -53,7 +67,7 @@ func TaskToEntity(task *resmgr.Task, isLaunched bool) *placement.Entity {
 	entity.Ordering = orderings.Concatenate(order...)
 
 	var req []placement.Requirement
-	req = append(req, makeAffinityRequirements(task.GetConstraint()))
+	req = append(req, makeAffinityRequirements(task.GetJobId().GetValue(), task.GetConstraint()))
 	req = append(req, makeMetricRequirements(task)...)
 	entity.Requirement = requirements.NewAndRequirement(req...)
 	return entity
@@ -78,7 +92,7 @@ func makeLabel(key, value string) *labels.Label {
 	return labels.NewLabel(append(strings.Split(key, "."), value)...)
 }
 
-func makeAffinityRequirements(constraint *task.Constraint) placement.Requirement {
+func makeAffinityRequirements(jobID string, constraint *task.Constraint) placement.Requirement {
 	switch constraint.GetType() {
 	case task.Constraint_LABEL_CONSTRAINT:
 		kind := constraint.GetLabelConstraint().GetKind()
@@ -101,17 +115,23 @@ func makeAffinityRequirements(constraint *task.Constraint) placement.Requirement
 	case task.Constraint_AND_CONSTRAINT:
 		var subRequirements []placement.Requirement
 		for _, subConstraint := range constraint.GetAndConstraint().GetConstraints() {
-			subRequirement := makeAffinityRequirements(subConstraint)
+			subRequirement := makeAffinityRequirements(jobID, subConstraint)
 			subRequirements = append(subRequirements, subRequirement)
 		}
 		return requirements.NewAndRequirement(subRequirements...)
 	case task.Constraint_OR_CONSTRAINT:
 		var subRequirements []placement.Requirement
 		for _, subConstraint := range constraint.GetOrConstraint().GetConstraints() {
-			subRequirement := makeAffinityRequirements(subConstraint)
+			subRequirement := makeAffinityRequirements(jobID, subConstraint)
 			subRequirements = append(subRequirements, subRequirement)
 		}
 		return requirements.NewOrRequirement(subRequirements...)
+	case task.Constraint_SPREAD_CONSTRAINT:
+		if len(constraint.GetSpreadConstraint().GetHostAttribute()) == 0 {
+			log.Warn("spread constraint without host attribute")
+			return requirements.NewAndRequirement()
+		}
+		return newSpreadRequirement(jobID, constraint.GetSpreadConstraint())
 	default:
 		if constraint != nil {
 			log.WithField("type", constraint.GetType()).
@@ -4,4 +4,8 @@ const (
 	// HostName represents the hostname label used
 	// internally by placement engine
 	HostName = "peloton.placementengine.hostname"
+
+	// JobID represents the job id relation label used
+	// internally by placement engine
+	JobID = "peloton.placementengine.job_id"
 )
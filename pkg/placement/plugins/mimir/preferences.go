@@ -0,0 +1,56 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mimir
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/labels"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/orderings"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/placement"
+)
+
+// makePreferenceOrdering creates an ordering which prefers the groups with the
+// highest sum of the weights of the placement preferences they match. It
+// returns nil if the task has no valid placement preferences.
+func makePreferenceOrdering(preferences []*task.PlacementPreference) placement.Ordering {
+	var weighted []placement.Ordering
+	for _, preference := range preferences {
+		var match placement.Ordering
+		switch preference.GetKind() {
+		case task.PlacementPreference_HOST:
+			match = orderings.Label(nil, makeLabel(
+				preference.GetLabel().GetKey(), preference.GetLabel().GetValue()))
+		case task.PlacementPreference_TASK:
+			match = orderings.Relation(nil, makeLabel(
+				preference.GetLabel().GetKey(), preference.GetLabel().GetValue()))
+		case task.PlacementPreference_JOB:
+			match = orderings.Relation(nil, labels.NewLabel(
+				JobID, preference.GetJobId().GetValue()))
+		default:
+			log.WithField("kind", preference.GetKind()).
+				Warn("unknown placement preference kind")
+			continue
+		}
+		weighted = append(weighted, orderings.Multiply(
+			orderings.Constant(preference.GetWeight()), match))
+	}
+	if len(weighted) == 0 {
+		return nil
+	}
+	// groups with lower tuples are preferred, so negate the total weight
+	return orderings.Negate(orderings.Sum(weighted...))
+}
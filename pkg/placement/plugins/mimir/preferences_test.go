@@ -0,0 +1,74 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mimir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/labels"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/placement"
+)
+
+func TestMakePreferenceOrdering(t *testing.T) {
+	group1 := placement.NewGroup("host1")
+	group1.Labels.Add(makeLabel("zone", "zone1"))
+	group1.Relations.Add(makeLabel("app", "cache"))
+	group2 := placement.NewGroup("host2")
+	group2.Labels.Add(makeLabel("zone", "zone2"))
+	group2.Relations.Add(labels.NewLabel(JobID, "noisy-job"))
+	group2.Relations.Add(labels.NewLabel(JobID, "noisy-job"))
+	scopeSet := placement.NewScopeSet([]*placement.Group{group1, group2})
+
+	ordering := makePreferenceOrdering([]*task.PlacementPreference{
+		{
+			Kind:   task.PlacementPreference_HOST,
+			Label:  &peloton.Label{Key: "zone", Value: "zone2"},
+			Weight: 3,
+		},
+		{
+			Kind:   task.PlacementPreference_TASK,
+			Label:  &peloton.Label{Key: "app", Value: "cache"},
+			Weight: 0.5,
+		},
+		{
+			Kind:   task.PlacementPreference_JOB,
+			JobId:  &peloton.JobID{Value: "noisy-job"},
+			Weight: -2,
+		},
+		{
+			Kind:   task.PlacementPreference_UNKNOWN,
+			Weight: 100,
+		},
+	})
+	assert.NotNil(t, ordering)
+	assert.Equal(t, []float64{-0.5}, ordering.Tuple(group1, scopeSet, nil))
+	// zone2 is preferred, but two co-located tasks of the noisy job
+	// outweigh the preference
+	assert.Equal(t, []float64{1}, ordering.Tuple(group2, scopeSet, nil))
+	assert.True(t, placement.Less(
+		ordering.Tuple(group1, scopeSet, nil),
+		ordering.Tuple(group2, scopeSet, nil)))
+}
+
+func TestMakePreferenceOrderingNoPreferences(t *testing.T) {
+	assert.Nil(t, makePreferenceOrdering(nil))
+	assert.Nil(t, makePreferenceOrdering([]*task.PlacementPreference{
+		{Kind: task.PlacementPreference_UNKNOWN, Weight: 1},
+	}))
+}
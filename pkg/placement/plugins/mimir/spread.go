@@ -0,0 +1,124 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mimir
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/common/constraints"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/labels"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/placement"
+)
+
+// spreadDomainCounts returns the number of occurrences of the relation for
+// each value of the host attribute matched by the attribute pattern, over all
+// groups in the scope set. Attribute values of groups without any occurrences
+// are included with a count of zero.
+func spreadDomainCounts(
+	scopeSet *placement.ScopeSet,
+	attribute, relation *labels.Label) map[string]int {
+	counts := map[string]int{}
+	for _, group := range scopeSet.ScopeGroups() {
+		occurrences := group.Relations.Count(relation)
+		for _, domain := range group.Labels.Find(attribute) {
+			counts[domain.String()] += occurrences
+		}
+	}
+	return counts
+}
+
+// spreadRequirement requires that placing the entity on a group keeps the
+// number of tasks of the job on each value of a host attribute within the max
+// skew of the least loaded value. Groups without the attribute never pass.
+type spreadRequirement struct {
+	attribute *labels.Label
+	relation  *labels.Label
+	maxSkew   int
+}
+
+func newSpreadRequirement(
+	jobID string,
+	spread *task.SpreadConstraint) *spreadRequirement {
+	return &spreadRequirement{
+		attribute: makeLabel(spread.GetHostAttribute(), "*"),
+		relation:  labels.NewLabel(JobID, jobID),
+		maxSkew:   int(constraints.GetMaxSkew(spread)),
+	}
+}
+
+// Passed checks if the requirement is fulfilled by the given group within the scope groups.
+func (requirement *spreadRequirement) Passed(group *placement.Group, scopeSet *placement.ScopeSet,
+	entity *placement.Entity, transcript *placement.Transcript) bool {
+	domains := group.Labels.Find(requirement.attribute)
+	if len(domains) == 0 {
+		transcript.IncFailed()
+		return false
+	}
+
+	counts := spreadDomainCounts(scopeSet, requirement.attribute, requirement.relation)
+	min := math.MaxInt32
+	for _, count := range counts {
+		if count < min {
+			min = count
+		}
+	}
+	for _, domain := range domains {
+		if counts[domain.String()]+1-min > requirement.maxSkew {
+			transcript.IncFailed()
+			return false
+		}
+	}
+	transcript.IncPassed()
+	return true
+}
+
+func (requirement *spreadRequirement) String() string {
+	return fmt.Sprintf("requires that the occurrences of the relation %v over the values of %v have a skew of at most %v",
+		requirement.relation, requirement.attribute, requirement.maxSkew)
+}
+
+// Composite returns false as the requirement is not composite and the name of the requirement type.
+func (requirement *spreadRequirement) Composite() (bool, string) {
+	return false, "spread"
+}
+
+// spreadOrdering orders groups by the number of tasks of the job on the value
+// of the host attribute of the group, so the least loaded value is preferred.
+type spreadOrdering struct {
+	attribute *labels.Label
+	relation  *labels.Label
+}
+
+func newSpreadOrdering(
+	jobID string,
+	spread *task.SpreadConstraint) *spreadOrdering {
+	return &spreadOrdering{
+		attribute: makeLabel(spread.GetHostAttribute(), "*"),
+		relation:  labels.NewLabel(JobID, jobID),
+	}
+}
+
+// Tuple returns a tuple of floats created from the group, scope groups and the entity.
+func (ordering *spreadOrdering) Tuple(group *placement.Group, scopeSet *placement.ScopeSet,
+	entity *placement.Entity) []float64 {
+	counts := spreadDomainCounts(scopeSet, ordering.attribute, ordering.relation)
+	occurrences := 0
+	for _, domain := range group.Labels.Find(ordering.attribute) {
+		occurrences += counts[domain.String()]
+	}
+	return []float64{float64(occurrences)}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mimir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/labels"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/model/placement"
+)
+
+const _spreadJobID = "job-1"
+
+// setupSpreadGroup creates a group in the given rack running the given
+// number of tasks of the job.
+func setupSpreadGroup(name, rack string, tasks int) *placement.Group {
+	group := placement.NewGroup(name)
+	if len(rack) != 0 {
+		group.Labels.Add(makeLabel("rack", rack))
+	}
+	group.Labels.Add(labels.NewLabel(HostName, name))
+	for i := 0; i < tasks; i++ {
+		group.Relations.Add(labels.NewLabel(JobID, _spreadJobID))
+	}
+	group.Relations.Add(labels.NewLabel(JobID, "other-job"))
+	return group
+}
+
+func TestSpreadRequirement_Passed(t *testing.T) {
+	group1 := setupSpreadGroup("host1", "rack1", 2)
+	group2 := setupSpreadGroup("host2", "rack1", 0)
+	group3 := setupSpreadGroup("host3", "rack2", 1)
+	group4 := setupSpreadGroup("host4", "", 0)
+	scopeSet := placement.NewScopeSet(
+		[]*placement.Group{group1, group2, group3, group4})
+
+	requirement := newSpreadRequirement(_spreadJobID, &task.SpreadConstraint{
+		HostAttribute: "rack",
+	})
+	transcript := placement.NewTranscript("transcript")
+
+	// rack1 already has 2 tasks and rack2 has 1
+	assert.False(t, requirement.Passed(group1, scopeSet, nil, transcript))
+	assert.False(t, requirement.Passed(group2, scopeSet, nil, transcript))
+	assert.True(t, requirement.Passed(group3, scopeSet, nil, transcript))
+	// hosts without the attribute are not eligible
+	assert.False(t, requirement.Passed(group4, scopeSet, nil, transcript))
+	assert.Equal(t, 1, transcript.GroupsPassed)
+	assert.Equal(t, 3, transcript.GroupsFailed)
+
+	requirement = newSpreadRequirement(_spreadJobID, &task.SpreadConstraint{
+		HostAttribute: "rack",
+		MaxSkew:       2,
+	})
+	assert.True(t, requirement.Passed(group2, scopeSet, nil, transcript))
+}
+
+func TestSpreadRequirement_String_and_Composite(t *testing.T) {
+	requirement := newSpreadRequirement(_spreadJobID, &task.SpreadConstraint{
+		HostAttribute: "rack",
+		MaxSkew:       2,
+	})
+	assert.Equal(t, "requires that the occurrences of the relation "+
+		"peloton.placementengine.job_id.job-1 over the values of rack.* have a skew of at most 2",
+		requirement.String())
+	composite, name := requirement.Composite()
+	assert.False(t, composite)
+	assert.Equal(t, "spread", name)
+}
+
+func TestSpreadOrdering_Tuple(t *testing.T) {
+	group1 := setupSpreadGroup("host1", "rack1", 2)
+	group2 := setupSpreadGroup("host2", "rack1", 0)
+	group3 := setupSpreadGroup("host3", "rack2", 1)
+	scopeSet := placement.NewScopeSet(
+		[]*placement.Group{group1, group2, group3})
+
+	ordering := newSpreadOrdering(_spreadJobID, &task.SpreadConstraint{
+		HostAttribute: "rack",
+	})
+	assert.Equal(t, []float64{2}, ordering.Tuple(group1, scopeSet, nil))
+	assert.Equal(t, []float64{2}, ordering.Tuple(group2, scopeSet, nil))
+	assert.Equal(t, []float64{1}, ordering.Tuple(group3, scopeSet, nil))
+}
+
+func TestMakeAffinityRequirementsSpread(t *testing.T) {
+	requirement := makeAffinityRequirements(_spreadJobID, &task.Constraint{
+		Type: task.Constraint_SPREAD_CONSTRAINT,
+		SpreadConstraint: &task.SpreadConstraint{
+			HostAttribute: "rack",
+			MaxSkew:       3,
+		},
+	})
+	spread, ok := requirement.(*spreadRequirement)
+	assert.True(t, ok)
+	assert.Equal(t, makeLabel("rack", "*"), spread.attribute)
+	assert.Equal(t, labels.NewLabel(JobID, _spreadJobID), spread.relation)
+	assert.Equal(t, 3, spread.maxSkew)
+}
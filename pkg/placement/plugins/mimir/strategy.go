@@ -100,6 +100,47 @@ func (mimir *mimir) convertHosts(hosts []*models.HostOffers) ([]*placement.Group
 	return groups, groupsToHosts
 }
 
+// scopeGroups returns the groups of the hosts with offers, along with groups
+// of the hosts running tasks of the jobs being placed which have no offers.
+// The latter are only part of the scope of the placement, so that spread
+// constraints count the tasks of the jobs running on them, but tasks are
+// never placed on them. The tasks running on the hosts with offers are
+// counted from the offers, which requires fetching the tasks on offers.
+func (mimir *mimir) scopeGroups(
+	pelotonAssignments []*models.Assignment,
+	groups []*placement.Group,
+	groupsToHosts map[*placement.Group]*models.HostOffers) []*placement.Group {
+	scope := make([]*placement.Group, 0, len(groups))
+	scope = append(scope, groups...)
+
+	hostnames := make(map[string]struct{}, len(groups))
+	for _, host := range groupsToHosts {
+		hostnames[host.GetOffer().GetHostname()] = struct{}{}
+	}
+	for _, p := range pelotonAssignments {
+		for _, host := range p.GetTask().GetSpreadHosts() {
+			hostname := host.GetHost().GetHostname()
+			if _, ok := hostnames[hostname]; ok {
+				continue
+			}
+			hostnames[hostname] = struct{}{}
+
+			group := OfferToGroup(&hostsvc.HostOffer{
+				Hostname:   hostname,
+				Attributes: host.GetHost().GetAttributes(),
+			})
+			entities := placement.Entities{}
+			for _, task := range host.GetTasks() {
+				entities.Add(TaskToEntity(task, true))
+			}
+			group.Entities = entities
+			group.Update()
+			scope = append(scope, group)
+		}
+	}
+	return scope
+}
+
 func (mimir *mimir) updateAssignments(
 	assignments []*placement.Assignment,
 	entitiesToAssignments map[*placement.Entity]*models.Assignment,
@@ -122,7 +163,8 @@ func (mimir *mimir) PlaceOnce(
 	hosts []*models.HostOffers) {
 	assignments, entitiesToAssignments := mimir.convertAssignments(pelotonAssignments)
 	groups, groupsToHosts := mimir.convertHosts(hosts)
-	scopeSet := placement.NewScopeSet(groups)
+	scopeSet := placement.NewScopeSet(mimir.scopeGroups(
+		pelotonAssignments, groups, groupsToHosts))
 
 	log.WithFields(log.Fields{
 		"peloton_assignments": pelotonAssignments,
@@ -21,6 +21,7 @@ import (
 	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 
 	"github.com/uber/peloton/pkg/placement/config"
@@ -160,6 +161,111 @@ func TestMimirPlaceIgnoreDesiredHostWhenNoEnoughResource(t *testing.T) {
 	assert.Equal(t, hostWithEnoughResources, assignments[0].GetHost())
 }
 
+// TestMimirPlacePreferHostWithPlacementPreference tests that the task
+// would be placed on the host matching its placement preference even if
+// it has less resources, and that the preference does not block placement.
+func TestMimirPlacePreferHostWithPlacementPreference(t *testing.T) {
+	assignments := []*models.Assignment{
+		testutil.SetupAssignment(time.Now().Add(10*time.Second), 1),
+	}
+	assignments[0].Task.Task.PlacementPreferences = []*task.PlacementPreference{
+		{
+			Kind:   task.PlacementPreference_HOST,
+			Label:  &peloton.Label{Key: "attribute", Value: "preferred"},
+			Weight: 1,
+		},
+	}
+
+	hostWithEnoughResources := testutil.SetupHostOffers()
+	hostWithEnoughResources.Offer.Hostname = "hostname1"
+
+	preferredHost := testutil.SetupHostOffers()
+	for _, resource := range preferredHost.Offer.Resources {
+		if resource.GetScalar() != nil {
+			value := resource.GetScalar().GetValue() - 1.0
+			resource.Scalar = &mesos_v1.Value_Scalar{
+				Value: &value,
+			}
+		}
+	}
+	preferredHost.Offer.Hostname = "hostname2"
+	preferred := "preferred"
+	preferredHost.Offer.Attributes[0].Text = &mesos_v1.Value_Text{
+		Value: &preferred,
+	}
+
+	strategy := setupStrategy()
+	strategy.PlaceOnce(assignments, []*models.HostOffers{
+		hostWithEnoughResources, preferredHost,
+	})
+	assert.Equal(t, preferredHost, assignments[0].GetHost())
+
+	// without the preferred host the task is still placed
+	assignments[0].SetHost(nil)
+	strategy.PlaceOnce(assignments, []*models.HostOffers{
+		hostWithEnoughResources,
+	})
+	assert.Equal(t, hostWithEnoughResources, assignments[0].GetHost())
+}
+
+// TestMimirPlaceSpreadWithRunningTasksOnHostsWithoutOffers tests that the
+// tasks of the job running on hosts without offers, such as full hosts,
+// are counted for spread constraints.
+func TestMimirPlaceSpreadWithRunningTasksOnHostsWithoutOffers(t *testing.T) {
+	assignments := []*models.Assignment{
+		testutil.SetupAssignment(time.Now().Add(10*time.Second), 1),
+	}
+	assignments[0].Task.Task.JobId = &peloton.JobID{Value: _spreadJobID}
+	assignments[0].Task.Task.Constraint = &task.Constraint{
+		Type: task.Constraint_SPREAD_CONSTRAINT,
+		SpreadConstraint: &task.SpreadConstraint{
+			HostAttribute: "attribute",
+			MaxSkew:       1,
+		},
+	}
+
+	rack1, rack2 := "rack1", "rack2"
+	scarceHost := testutil.SetupHostOffers()
+	for _, resource := range scarceHost.Offer.Resources {
+		if resource.GetScalar() != nil {
+			value := resource.GetScalar().GetValue() - 1.0
+			resource.Scalar = &mesos_v1.Value_Scalar{
+				Value: &value,
+			}
+		}
+	}
+	scarceHost.Offer.Hostname = "hostname1"
+	scarceHost.Offer.Attributes = scarceHost.Offer.Attributes[:1]
+	scarceHost.Offer.Attributes[0].Text = &mesos_v1.Value_Text{Value: &rack1}
+
+	host := testutil.SetupHostOffers()
+	host.Offer.Hostname = "hostname2"
+	host.Offer.Attributes = host.Offer.Attributes[:1]
+	host.Offer.Attributes[0].Text = &mesos_v1.Value_Text{Value: &rack2}
+
+	// a full host without offers in rack2 is running tasks of the job
+	name := "attribute"
+	textType := mesos_v1.Value_TEXT
+	fullHost := models.NewHosts(&hostsvc.HostInfo{
+		Hostname: "hostname3",
+		Attributes: []*mesos_v1.Attribute{
+			{
+				Name: &name,
+				Type: &textType,
+				Text: &mesos_v1.Value_Text{Value: &rack2},
+			},
+		},
+	}, []*resmgr.Task{
+		{JobId: &peloton.JobID{Value: _spreadJobID}},
+		{JobId: &peloton.JobID{Value: _spreadJobID}},
+	})
+	assignments[0].GetTask().SetSpreadHosts([]*models.Host{fullHost})
+
+	strategy := setupStrategy()
+	strategy.PlaceOnce(assignments, []*models.HostOffers{scarceHost, host})
+	assert.Equal(t, scarceHost, assignments[0].GetHost())
+}
+
 func TestMimirFilters(t *testing.T) {
 	strategy := setupStrategy()
 
@@ -0,0 +1,192 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/generation"
+)
+
+// GeneratorConfig describes the synthetic snapshots created by Generate.
+type GeneratorConfig struct {
+	// Hosts is the number of hosts to generate.
+	Hosts int
+	// Gangs is the number of pending gangs to generate.
+	Gangs int
+	// TaskType is the type of the generated tasks.
+	TaskType resmgr.TaskType
+
+	// HostCPU, HostMemMb, HostDiskMb and HostGPU are the distributions of
+	// the capacity of the generated hosts.
+	HostCPU    generation.Distribution
+	HostMemMb  generation.Distribution
+	HostDiskMb generation.Distribution
+	HostGPU    generation.Distribution
+	// Utilization is the distribution of the fraction of the capacity of
+	// each host which is used by tasks already running on it.
+	Utilization generation.Distribution
+
+	// GangSize is the distribution of the number of tasks in a gang.
+	GangSize generation.Distribution
+	// TaskCPU, TaskMemMb, TaskDiskMb and TaskGPU are the distributions of
+	// the resources of the generated tasks.
+	TaskCPU    generation.Distribution
+	TaskMemMb  generation.Distribution
+	TaskDiskMb generation.Distribution
+	TaskGPU    generation.Distribution
+}
+
+// NewGeneratorConfig returns a generator config for the given number of
+// hosts and gangs, which resembles a cluster of mostly CPU hosts and a
+// few GPU hosts.
+func NewGeneratorConfig(hosts, gangs int, taskType resmgr.TaskType) *GeneratorConfig {
+	return &GeneratorConfig{
+		Hosts:       hosts,
+		Gangs:       gangs,
+		TaskType:    taskType,
+		HostCPU:     generation.NewConstant(48),
+		HostMemMb:   generation.NewConstant(256 * 1024),
+		HostDiskMb:  generation.NewConstant(1024 * 1024),
+		HostGPU:     generation.NewDiscrete(map[float64]float64{0: 0.9, 8: 0.1}),
+		Utilization: generation.NewUniformDiscrete(0, 0.25, 0.5, 0.75),
+		GangSize:    generation.NewDiscrete(map[float64]float64{1: 0.8, 4: 0.2}),
+		TaskCPU:     generation.NewUniformDiscrete(1, 2, 4, 8, 16),
+		TaskMemMb:   generation.NewUniformDiscrete(1024, 4096, 8192, 32768),
+		TaskDiskMb:  generation.NewUniformDiscrete(1024, 10240),
+		TaskGPU:     generation.NewDiscrete(map[float64]float64{0: 0.95, 1: 0.05}),
+	}
+}
+
+// generator draws values from the distributions of a config, advancing the
+// generation time for every value, such that the same seed always gives
+// the same snapshot.
+type generator struct {
+	random generation.Random
+	gtime  time.Duration
+}
+
+func (g *generator) value(distribution generation.Distribution) float64 {
+	g.gtime++
+	return math.Max(0, distribution.Value(g.random, g.gtime))
+}
+
+// Generate creates a synthetic snapshot from the config using the given
+// source of randomness.
+func Generate(config *GeneratorConfig, random generation.Random) *Snapshot {
+	g := &generator{random: random}
+	snapshot := &Snapshot{}
+
+	for i := 0; i < config.Hosts; i++ {
+		hostname := fmt.Sprintf("host-%d", i)
+		capacity := &task.ResourceConfig{
+			CpuLimit:    g.value(config.HostCPU),
+			MemLimitMb:  g.value(config.HostMemMb),
+			DiskLimitMb: g.value(config.HostDiskMb),
+			GpuLimit:    g.value(config.HostGPU),
+		}
+		utilization := math.Min(1, g.value(config.Utilization))
+		used := &task.ResourceConfig{
+			CpuLimit:    capacity.CpuLimit * utilization,
+			MemLimitMb:  capacity.MemLimitMb * utilization,
+			DiskLimitMb: capacity.DiskLimitMb * utilization,
+			GpuLimit:    math.Floor(capacity.GpuLimit * utilization),
+		}
+		host := &Host{
+			Offer: &hostsvc.HostOffer{
+				Hostname:  hostname,
+				AgentId:   &mesos_v1.AgentID{Value: &hostname},
+				Resources: makeResources(subtract(capacity, used)),
+			},
+		}
+		if utilization > 0 {
+			host.Tasks = append(host.Tasks, &resmgr.Task{
+				Id:       &peloton.TaskID{Value: fmt.Sprintf("running-%d-0", i)},
+				JobId:    &peloton.JobID{Value: fmt.Sprintf("running-%d", i)},
+				Type:     config.TaskType,
+				Hostname: hostname,
+				Resource: used,
+			})
+		}
+		snapshot.Hosts = append(snapshot.Hosts, host)
+	}
+
+	for i := 0; i < config.Gangs; i++ {
+		jobID := fmt.Sprintf("job-%d", i)
+		resource := &task.ResourceConfig{
+			CpuLimit:    g.value(config.TaskCPU),
+			MemLimitMb:  g.value(config.TaskMemMb),
+			DiskLimitMb: g.value(config.TaskDiskMb),
+			GpuLimit:    g.value(config.TaskGPU),
+		}
+		size := int(math.Max(1, g.value(config.GangSize)))
+		gang := &resmgrsvc.Gang{}
+		for instance := 0; instance < size; instance++ {
+			gang.Tasks = append(gang.Tasks, &resmgr.Task{
+				Name:         fmt.Sprintf("%s-%d", jobID, instance),
+				Id:           &peloton.TaskID{Value: fmt.Sprintf("%s-%d", jobID, instance)},
+				JobId:        &peloton.JobID{Value: jobID},
+				Type:         config.TaskType,
+				Resource:     resource,
+				MinInstances: uint32(size),
+			})
+		}
+		snapshot.Gangs = append(snapshot.Gangs, gang)
+	}
+	return snapshot
+}
+
+// makeResources converts a resource config into mesos scalar resources.
+func makeResources(resource *task.ResourceConfig) []*mesos_v1.Resource {
+	var result []*mesos_v1.Resource
+	for _, r := range []struct {
+		name  string
+		value float64
+	}{
+		{"cpus", resource.GetCpuLimit()},
+		{"mem", resource.GetMemLimitMb()},
+		{"disk", resource.GetDiskLimitMb()},
+		{"gpus", resource.GetGpuLimit()},
+	} {
+		if r.value < util.ResourceEpsilon {
+			continue
+		}
+		result = append(result, util.NewMesosResourceBuilder().
+			WithName(r.name).
+			WithValue(r.value).
+			Build())
+	}
+	return result
+}
+
+// subtract returns the resources of r1 minus the resources of r2.
+func subtract(r1, r2 *task.ResourceConfig) *task.ResourceConfig {
+	return &task.ResourceConfig{
+		CpuLimit:    r1.GetCpuLimit() - r2.GetCpuLimit(),
+		MemLimitMb:  r1.GetMemLimitMb() - r2.GetMemLimitMb(),
+		DiskLimitMb: r1.GetDiskLimitMb() - r2.GetDiskLimitMb(),
+		GpuLimit:    r1.GetGpuLimit() - r2.GetGpuLimit(),
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/generation"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	config := NewGeneratorConfig(10, 20, resmgr.TaskType_BATCH)
+	snapshot := Generate(config, generation.NewRandom(42))
+
+	assert.Len(t, snapshot.Hosts, 10)
+	assert.Len(t, snapshot.Gangs, 20)
+	for _, host := range snapshot.Hosts {
+		free := scalar.FromMesosResources(host.Offer.GetResources())
+		assert.True(t, free.GetCPU() <= 48)
+		for _, running := range host.Tasks {
+			assert.Equal(t, host.Offer.GetHostname(), running.GetHostname())
+			assert.InDelta(t, 48, free.GetCPU()+running.GetResource().GetCpuLimit(), 0.001)
+		}
+	}
+	for _, gang := range snapshot.Gangs {
+		assert.NotEmpty(t, gang.GetTasks())
+		for _, pending := range gang.GetTasks() {
+			assert.Equal(t, resmgr.TaskType_BATCH, pending.GetType())
+			assert.Equal(t, uint32(len(gang.GetTasks())), pending.GetMinInstances())
+		}
+	}
+
+	// the same seed gives the same snapshot
+	assert.Equal(t, snapshot, Generate(config, generation.NewRandom(42)))
+}
+
+func TestGenerateWithoutUtilization(t *testing.T) {
+	config := NewGeneratorConfig(3, 0, resmgr.TaskType_BATCH)
+	config.Utilization = generation.NewConstant(0)
+	snapshot := Generate(config, generation.NewRandom(1))
+
+	for _, host := range snapshot.Hosts {
+		assert.Empty(t, host.Tasks)
+	}
+	assert.Empty(t, snapshot.Gangs)
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"sort"
+	"time"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+
+	"github.com/uber/peloton/pkg/common/constraints"
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/placement/models"
+	"github.com/uber/peloton/pkg/placement/plugins"
+
+	"github.com/gogo/protobuf/proto"
+)
+
+const (
+	// _noConstraint is the failure key of tasks without a constraint.
+	_noConstraint = "none"
+	// _deadline is the placement deadline of the simulated tasks, which
+	// is never reached since the simulation does not wait.
+	_deadline = time.Hour
+)
+
+// _hostEvaluator evaluates scheduling constraints like the host manager.
+var _hostEvaluator = constraints.NewEvaluator(task.LabelConstraint_HOST)
+
+// Report summarizes the outcome of a simulation.
+type Report struct {
+	// Tasks is the number of tasks pending placement.
+	Tasks int `json:"tasks"`
+	// Placed is the number of tasks which were placed.
+	Placed int `json:"placed"`
+	// PlacementRate is the fraction of the tasks which were placed.
+	PlacementRate float64 `json:"placement_rate"`
+	// Rounds is the number of placement rounds which were run.
+	Rounds int `json:"rounds"`
+	// Hosts is the number of hosts.
+	Hosts int `json:"hosts"`
+	// FreeHosts is the number of hosts without tasks after the placement.
+	FreeHosts int `json:"free_hosts"`
+	// Fragmentation is the fraction of the free cpu after the placement
+	// which is on hosts running tasks, and so can not be used by tasks
+	// which need a whole host.
+	Fragmentation float64 `json:"fragmentation"`
+	// Failures is the number of tasks which were not placed by their
+	// constraint. Tasks without a constraint are counted as "none".
+	Failures map[string]int `json:"failures"`
+	// Placements maps the id of each placed task to its host.
+	Placements map[string]string `json:"placements"`
+}
+
+// Simulate places the pending gangs of the snapshot on its hosts using the
+// strategy, the same way the placement engine would with the host manager
+// filtering the hosts. A round places all the unplaced tasks once, and the
+// simulation stops after maxRounds rounds or when a round places no task.
+// The snapshot is not modified.
+func Simulate(
+	strategy plugins.Strategy,
+	snapshot *Snapshot,
+	maxRounds int) *Report {
+	hosts := makeHosts(snapshot)
+	unassigned := makeAssignments(snapshot)
+	report := &Report{
+		Tasks:      len(unassigned),
+		Hosts:      len(hosts),
+		Failures:   map[string]int{},
+		Placements: map[string]string{},
+	}
+
+	for report.Rounds < maxRounds && len(unassigned) > 0 {
+		report.Rounds++
+		placed := placeRound(strategy, unassigned, hosts)
+		var remaining []*models.Assignment
+		for _, assignment := range unassigned {
+			if assignment.GetHost() == nil {
+				remaining = append(remaining, assignment)
+				continue
+			}
+			report.Placements[assignment.GetTask().GetTask().GetId().GetValue()] =
+				assignment.GetHost().GetOffer().GetHostname()
+		}
+		unassigned = remaining
+		if placed == 0 {
+			break
+		}
+	}
+
+	report.Placed = len(report.Placements)
+	if report.Tasks > 0 {
+		report.PlacementRate = float64(report.Placed) / float64(report.Tasks)
+	}
+	for _, assignment := range unassigned {
+		report.Failures[failureKey(assignment.GetTask().GetTask())]++
+	}
+	var freeCPU, fragmentedCPU float64
+	for _, host := range hosts {
+		cpu := scalar.FromMesosResources(host.GetOffer().GetResources()).GetCPU()
+		freeCPU += cpu
+		if len(host.GetTasks()) == 0 {
+			report.FreeHosts++
+			continue
+		}
+		fragmentedCPU += cpu
+	}
+	if freeCPU > 0 {
+		report.Fragmentation = fragmentedCPU / freeCPU
+	}
+	return report
+}
+
+// placeRound runs the strategy once for each host filter of the unassigned
+// tasks and returns the number of tasks placed.
+func placeRound(
+	strategy plugins.Strategy,
+	unassigned []*models.Assignment,
+	hosts []*models.HostOffers) int {
+	filters := strategy.Filters(unassigned)
+	// Place the batches in a deterministic order.
+	ordered := make([]*hostsvc.HostFilter, 0, len(filters))
+	for filter := range filters {
+		ordered = append(ordered, filter)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].String() < ordered[j].String()
+	})
+
+	placed := 0
+	for _, filter := range ordered {
+		batch := filters[filter]
+		strategy.PlaceOnce(batch, filterHosts(hosts, filter))
+		for _, assignment := range batch {
+			host := assignment.GetHost()
+			if host == nil {
+				continue
+			}
+			launch(host, assignment.GetTask().GetTask())
+			placed++
+		}
+	}
+	return placed
+}
+
+// filterHosts returns the hosts matching the filter, like the host manager
+// would when acquiring hosts for the filter.
+func filterHosts(
+	hosts []*models.HostOffers,
+	filter *hostsvc.HostFilter) []*models.HostOffers {
+	maxHosts := int(filter.GetQuantity().GetMaxHosts())
+	minimum := scalar.FromResourceConfig(
+		filter.GetResourceConstraint().GetMinimum())
+	numPorts := uint64(filter.GetResourceConstraint().GetNumPorts())
+
+	var result []*models.HostOffers
+	for _, host := range hosts {
+		if maxHosts > 0 && len(result) >= maxHosts {
+			break
+		}
+		resources := host.GetOffer().GetResources()
+		if !scalar.FromMesosResources(resources).Contains(minimum) ||
+			countPorts(resources) < numPorts {
+			continue
+		}
+		if constraint := filter.GetSchedulingConstraint(); constraint != nil {
+			labelValues := constraints.GetHostLabelValues(
+				host.GetOffer().GetHostname(),
+				host.GetOffer().GetAttributes())
+			match, err := _hostEvaluator.Evaluate(constraint, labelValues)
+			if err != nil || match == constraints.EvaluateResultMismatch {
+				continue
+			}
+		}
+		result = append(result, host)
+	}
+	return result
+}
+
+// launch removes the resources of the task from the offer of the host and
+// adds the task to the tasks running on the host.
+func launch(host *models.HostOffers, t *resmgr.Task) {
+	demand := map[string]float64{
+		"cpus":  t.GetResource().GetCpuLimit(),
+		"mem":   t.GetResource().GetMemLimitMb(),
+		"disk":  t.GetResource().GetDiskLimitMb(),
+		"gpus":  t.GetResource().GetGpuLimit(),
+		"ports": float64(t.GetNumPorts()),
+	}
+	for _, resource := range host.GetOffer().GetResources() {
+		name := resource.GetName()
+		if demand[name] <= 0 {
+			continue
+		}
+		if name == "ports" {
+			if resource.GetRanges() == nil {
+				continue
+			}
+			demand[name] -= float64(takePorts(resource, uint64(demand[name])))
+			continue
+		}
+		if resource.GetScalar() == nil {
+			continue
+		}
+		value := resource.GetScalar().GetValue()
+		used := demand[name]
+		if used > value {
+			used = value
+		}
+		value -= used
+		resource.Scalar.Value = &value
+		demand[name] -= used
+	}
+	host.Tasks = append(host.Tasks, t)
+	// Drop the state the strategy keeps for the host, as it is stale now.
+	host.SetData(nil)
+}
+
+// countPorts returns the number of ports in the port resources.
+func countPorts(resources []*mesos_v1.Resource) uint64 {
+	result := uint64(0)
+	for _, resource := range resources {
+		if resource.GetName() != "ports" {
+			continue
+		}
+		for _, r := range resource.GetRanges().GetRange() {
+			result += r.GetEnd() - r.GetBegin() + 1
+		}
+	}
+	return result
+}
+
+// takePorts removes up to n ports from the start of the port ranges of the
+// resource and returns the number of ports removed.
+func takePorts(resource *mesos_v1.Resource, n uint64) uint64 {
+	taken := uint64(0)
+	var remaining []*mesos_v1.Value_Range
+	for _, r := range resource.GetRanges().GetRange() {
+		begin, end := r.GetBegin(), r.GetEnd()
+		if taken < n {
+			take := end - begin + 1
+			if take > n-taken {
+				take = n - taken
+			}
+			taken += take
+			begin += take
+		}
+		if begin <= end {
+			b, e := begin, end
+			remaining = append(remaining, &mesos_v1.Value_Range{Begin: &b, End: &e})
+		}
+	}
+	resource.Ranges.Range = remaining
+	return taken
+}
+
+// makeHosts copies the hosts of the snapshot into placement hosts.
+func makeHosts(snapshot *Snapshot) []*models.HostOffers {
+	now := time.Now()
+	hosts := make([]*models.HostOffers, 0, len(snapshot.Hosts))
+	for _, host := range snapshot.Hosts {
+		offer := proto.Clone(host.Offer).(*hostsvc.HostOffer)
+		tasks := append([]*resmgr.Task(nil), host.Tasks...)
+		hosts = append(hosts, models.NewHostOffers(offer, tasks, now))
+	}
+	return hosts
+}
+
+// makeAssignments creates an assignment for each task of the pending gangs
+// of the snapshot.
+func makeAssignments(snapshot *Snapshot) []*models.Assignment {
+	deadline := time.Now().Add(_deadline)
+	var assignments []*models.Assignment
+	for _, gang := range snapshot.Gangs {
+		for _, t := range gang.GetTasks() {
+			assignments = append(assignments, models.NewAssignment(
+				models.NewTask(gang, t, deadline, deadline, 0)))
+		}
+	}
+	return assignments
+}
+
+// failureKey returns the key under which an unplaced task is reported.
+func failureKey(t *resmgr.Task) string {
+	if t.GetConstraint() == nil {
+		return _noConstraint
+	}
+	return t.GetConstraint().String()
+}
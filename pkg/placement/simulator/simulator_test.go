@@ -0,0 +1,205 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"fmt"
+	"testing"
+
+	mesos_v1 "github.com/uber/peloton/.gen/mesos/v1"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/hostmgr/scalar"
+	"github.com/uber/peloton/pkg/placement/config"
+	"github.com/uber/peloton/pkg/placement/plugins/batch"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir"
+	"github.com/uber/peloton/pkg/placement/plugins/mimir/lib/algorithms"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupHost(hostname string, cpu float64) *Host {
+	return &Host{
+		Offer: &hostsvc.HostOffer{
+			Hostname: hostname,
+			Resources: makeResources(&task.ResourceConfig{
+				CpuLimit:   cpu,
+				MemLimitMb: 16 * 1024,
+			}),
+		},
+	}
+}
+
+func setupGang(jobID string, tasks int, cpu float64) *resmgrsvc.Gang {
+	gang := &resmgrsvc.Gang{}
+	for i := 0; i < tasks; i++ {
+		gang.Tasks = append(gang.Tasks, &resmgr.Task{
+			Name:  jobID,
+			Id:    &peloton.TaskID{Value: fmt.Sprintf("%s-%d", jobID, i)},
+			JobId: &peloton.JobID{Value: jobID},
+			Type:  resmgr.TaskType_BATCH,
+			Resource: &task.ResourceConfig{
+				CpuLimit:   cpu,
+				MemLimitMb: 1024,
+			},
+		})
+	}
+	return gang
+}
+
+func setupSnapshot() *Snapshot {
+	unknownHost := setupGang("unknown-host", 1, 1)
+	unknownHost.Tasks[0].Constraint = &task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind:      task.LabelConstraint_HOST,
+			Condition: task.LabelConstraint_CONDITION_EQUAL,
+			Label: &peloton.Label{
+				Key:   "hostname",
+				Value: "host-9",
+			},
+			Requirement: 1,
+		},
+	}
+	return &Snapshot{
+		Hosts: []*Host{
+			setupHost("host-1", 8),
+			setupHost("host-2", 8),
+			setupHost("host-3", 8),
+		},
+		Gangs: []*resmgrsvc.Gang{
+			setupGang("small", 3, 4),
+			setupGang("large", 1, 16),
+			unknownHost,
+		},
+	}
+}
+
+func TestSimulateBatch(t *testing.T) {
+	snapshot := setupSnapshot()
+	report := Simulate(batch.New(), snapshot, 3)
+
+	assert.Equal(t, 5, report.Tasks)
+	assert.Equal(t, 3, report.Placed)
+	assert.InDelta(t, 0.6, report.PlacementRate, 0.001)
+	assert.Equal(t, 3, report.Hosts)
+	assert.Len(t, report.Placements, 3)
+	assert.Equal(t, 2, len(report.Failures))
+	assert.Equal(t, 1, report.Failures[_noConstraint])
+
+	// 12 cpus are free, of which the ones on used hosts are fragmented
+	freeHosts := 0
+	for _, host := range []string{"host-1", "host-2", "host-3"} {
+		used := false
+		for _, placed := range report.Placements {
+			used = used || placed == host
+		}
+		if !used {
+			freeHosts++
+		}
+	}
+	assert.Equal(t, freeHosts, report.FreeHosts)
+	assert.InDelta(t,
+		float64(12-8*freeHosts)/12.0, report.Fragmentation, 0.001)
+
+	// the snapshot is not modified
+	for _, host := range snapshot.Hosts {
+		assert.Empty(t, host.Tasks)
+		assert.Equal(t, 8.0,
+			scalar.FromMesosResources(host.Offer.GetResources()).GetCPU())
+	}
+}
+
+func TestSimulateMimir(t *testing.T) {
+	strategy := mimir.New(
+		algorithms.NewPlacer(1, 100),
+		&config.PlacementConfig{
+			TaskType:          resmgr.TaskType_BATCH,
+			OfferDequeueLimit: 10,
+		})
+	report := Simulate(strategy, setupSnapshot(), 3)
+
+	assert.Equal(t, 5, report.Tasks)
+	assert.Equal(t, 3, report.Placed)
+	assert.Equal(t, 1, report.Failures[_noConstraint])
+}
+
+func TestSimulateNoRounds(t *testing.T) {
+	report := Simulate(batch.New(), setupSnapshot(), 0)
+	assert.Equal(t, 0, report.Rounds)
+	assert.Equal(t, 0, report.Placed)
+	assert.Equal(t, 4, report.Failures[_noConstraint])
+}
+
+func TestFilterHosts(t *testing.T) {
+	hosts := makeHosts(setupSnapshot())
+
+	filter := &hostsvc.HostFilter{
+		ResourceConstraint: &hostsvc.ResourceConstraint{
+			Minimum: &task.ResourceConfig{CpuLimit: 4},
+		},
+		Quantity: &hostsvc.QuantityControl{MaxHosts: 2},
+	}
+	assert.Len(t, filterHosts(hosts, filter), 2)
+
+	filter.ResourceConstraint.Minimum.CpuLimit = 10
+	assert.Empty(t, filterHosts(hosts, filter))
+
+	filter.ResourceConstraint.Minimum.CpuLimit = 1
+	filter.SchedulingConstraint = &task.Constraint{
+		Type: task.Constraint_LABEL_CONSTRAINT,
+		LabelConstraint: &task.LabelConstraint{
+			Kind:      task.LabelConstraint_HOST,
+			Condition: task.LabelConstraint_CONDITION_EQUAL,
+			Label: &peloton.Label{
+				Key:   "hostname",
+				Value: "host-3",
+			},
+			Requirement: 1,
+		},
+	}
+	result := filterHosts(hosts, filter)
+	assert.Len(t, result, 1)
+	assert.Equal(t, "host-3", result[0].GetOffer().GetHostname())
+}
+
+func TestLaunch(t *testing.T) {
+	begin, end := uint64(31000), uint64(31004)
+	portsName := "ports"
+	host := makeHosts(&Snapshot{Hosts: []*Host{setupHost("host-1", 8)}})[0]
+	host.Offer.Resources = append(host.Offer.Resources, &mesos_v1.Resource{
+		Name: &portsName,
+		Ranges: &mesos_v1.Value_Ranges{
+			Range: []*mesos_v1.Value_Range{{Begin: &begin, End: &end}},
+		},
+	})
+	host.SetData("stale")
+
+	launch(host, &resmgr.Task{
+		Resource: &task.ResourceConfig{CpuLimit: 3, MemLimitMb: 1024},
+		NumPorts: 2,
+	})
+
+	resources := scalar.FromMesosResources(host.GetOffer().GetResources())
+	assert.Equal(t, 5.0, resources.GetCPU())
+	assert.Equal(t, 15.0*1024, resources.GetMem())
+	assert.Equal(t, uint64(3), countPorts(host.GetOffer().GetResources()))
+	assert.Len(t, host.GetTasks(), 1)
+	assert.Nil(t, host.Data())
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/pkg/errors"
+)
+
+// Snapshot is a snapshot of the hosts of a cluster, the tasks running on
+// them and the gangs pending placement.
+type Snapshot struct {
+	// Hosts of the cluster.
+	Hosts []*Host `json:"hosts"`
+	// Gangs pending placement, in the order they are placed.
+	Gangs []*resmgrsvc.Gang `json:"gangs"`
+}
+
+// Host is a host of a snapshot.
+type Host struct {
+	// Offer contains the free resources and the attributes of the host.
+	Offer *hostsvc.HostOffer `json:"offer"`
+	// Tasks running on the host.
+	Tasks []*resmgr.Task `json:"tasks"`
+}
+
+// LoadSnapshot reads a snapshot from a JSON file.
+func LoadSnapshot(path string) (*Snapshot, error) {
+	buffer, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read snapshot")
+	}
+	snapshot := &Snapshot{}
+	if err := json.Unmarshal(buffer, snapshot); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal snapshot")
+	}
+	return snapshot, nil
+}
+
+// Save writes the snapshot to a JSON file.
+func (s *Snapshot) Save(path string) error {
+	buffer, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal snapshot")
+	}
+	return ioutil.WriteFile(path, buffer, 0644)
+}
+
+// NumTasks returns the number of tasks pending placement.
+func (s *Snapshot) NumTasks() int {
+	result := 0
+	for _, gang := range s.Gangs {
+		result += len(gang.GetTasks())
+	}
+	return result
+}
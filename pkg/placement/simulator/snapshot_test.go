@@ -0,0 +1,55 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simulator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "snapshot")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "snapshot.json")
+
+	snapshot := setupSnapshot()
+	require.NoError(t, snapshot.Save(path))
+
+	loaded, err := LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, loaded)
+	assert.Equal(t, 5, loaded.NumTasks())
+}
+
+func TestLoadSnapshotErrors(t *testing.T) {
+	_, err := LoadSnapshot("/does/not/exist")
+	assert.Error(t, err)
+
+	file, err := ioutil.TempFile("", "snapshot")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("not json")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	_, err = LoadSnapshot(file.Name())
+	assert.Error(t, err)
+}
@@ -20,6 +20,7 @@ import (
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 	"github.com/uber/peloton/pkg/placement/config"
@@ -34,6 +35,16 @@ const (
 	_failedToSetPlacements = "failed to set placements"
 )
 
+// _activeStates are the states of the tasks which are placed on a host
+// and may be running on it.
+var _activeStates = []string{
+	task.TaskState_PLACED.String(),
+	task.TaskState_LAUNCHING.String(),
+	task.TaskState_LAUNCHED.String(),
+	task.TaskState_STARTING.String(),
+	task.TaskState_RUNNING.String(),
+}
+
 // Service will manage gangs/tasks and placements used by any placement strategy.
 type Service interface {
 	// Dequeue fetches some tasks from the service.
@@ -45,6 +56,9 @@ type Service interface {
 		successFullPlacements []*resmgr.Placement,
 		failedAssignments []*models.Assignment,
 	)
+
+	// GetHostnames returns the hosts of the active tasks of a job.
+	GetHostnames(ctx context.Context, jobID string) (hostnames []string, err error)
 }
 
 // NewService will create a new task service.
@@ -235,3 +249,43 @@ func (s *service) createTasks(gang *resmgrsvc.Gang, now time.Time) []*models.Tas
 	}
 	return tasks
 }
+
+// GetHostnames returns the hosts of the tasks of the job which
+// the resource manager tracks as placed, launched or running.
+func (s *service) GetHostnames(
+	ctx context.Context,
+	jobID string) ([]string, error) {
+	ctx, cancelFunc := context.WithTimeout(ctx, _timeout)
+	defer cancelFunc()
+
+	response, err := s.resourceManager.GetActiveTasks(
+		ctx,
+		&resmgrsvc.GetActiveTasksRequest{
+			JobID:        jobID,
+			States:       _activeStates,
+			IncludeTasks: true,
+		})
+	if err != nil {
+		return nil, err
+	}
+	if response.GetError() != nil {
+		return nil, errors.New(response.GetError().GetMessage())
+	}
+
+	seen := make(map[string]struct{})
+	var hostnames []string
+	for _, entries := range response.GetTasksByState() {
+		for _, entry := range entries.GetTaskEntry() {
+			hostname := entry.GetTask().GetHostname()
+			if hostname == "" {
+				continue
+			}
+			if _, ok := seen[hostname]; ok {
+				continue
+			}
+			seen[hostname] = struct{}{}
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	return hostnames, nil
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/uber-go/tally"
 	"github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 	resource_mocks "github.com/uber/peloton/.gen/peloton/private/resmgrsvc/mocks"
@@ -218,3 +219,51 @@ func TestTaskService_SetPlacements(t *testing.T) {
 	)
 	service.SetPlacements(ctx, placements, nil)
 }
+
+func TestTaskService_GetHostnames(t *testing.T) {
+	service, mockResourceManager, ctrl := setupService(t)
+	defer ctrl.Finish()
+
+	ctx := context.Background()
+	request := &resmgrsvc.GetActiveTasksRequest{
+		JobID:        "job",
+		States:       _activeStates,
+		IncludeTasks: true,
+	}
+
+	mockResourceManager.EXPECT().
+		GetActiveTasks(gomock.Any(), request).
+		Return(nil, errors.New("get active tasks request failed"))
+	hostnames, err := service.GetHostnames(ctx, "job")
+	assert.Error(t, err)
+	assert.Nil(t, hostnames)
+
+	mockResourceManager.EXPECT().
+		GetActiveTasks(gomock.Any(), request).
+		Return(&resmgrsvc.GetActiveTasksResponse{
+			Error: &resmgrsvc.GetActiveTasksResponse_Error{
+				Message: "failed",
+			},
+		}, nil)
+	hostnames, err = service.GetHostnames(ctx, "job")
+	assert.Error(t, err)
+	assert.Nil(t, hostnames)
+
+	// tasks without a host are skipped and hosts are returned once
+	mockResourceManager.EXPECT().
+		GetActiveTasks(gomock.Any(), request).
+		Return(&resmgrsvc.GetActiveTasksResponse{
+			TasksByState: map[string]*resmgrsvc.GetActiveTasksResponse_TaskEntries{
+				task.TaskState_RUNNING.String(): {
+					TaskEntry: []*resmgrsvc.GetActiveTasksResponse_TaskEntry{
+						{Task: &resmgr.Task{Hostname: "hostname1"}},
+						{Task: &resmgr.Task{Hostname: "hostname1"}},
+						{Task: &resmgr.Task{}},
+					},
+				},
+			},
+		}, nil)
+	hostnames, err = service.GetHostnames(ctx, "job")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hostname1"}, hostnames)
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	v1alpharespoolsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool/svc"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/middleware/inbound"
+	res "github.com/uber/peloton/pkg/resmgr/respool"
+
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	_respoolManager = "peloton.api.v0.respool.ResourceManager::"
+	_respoolService = "peloton.api.v1alpha.respool.svc.ResourcePoolService::"
+)
+
+// _requests maps the procedures which act on a resource pool to a
+// constructor of their request message. Every mutating procedure of
+// the resource pool APIs must be listed.
+var _requests = map[string]func() proto.Message{
+	_respoolManager + "CreateResourcePool": func() proto.Message { return &respool.CreateRequest{} },
+	_respoolManager + "UpdateResourcePool": func() proto.Message { return &respool.UpdateRequest{} },
+	_respoolManager + "DeleteResourcePool": func() proto.Message { return &respool.DeleteRequest{} },
+	_respoolService + "CreateResourcePool": func() proto.Message { return &v1alpharespoolsvc.CreateResourcePoolRequest{} },
+	_respoolService + "UpdateResourcePool": func() proto.Message { return &v1alpharespoolsvc.UpdateResourcePoolRequest{} },
+	_respoolService + "DeleteResourcePool": func() proto.Message { return &v1alpharespoolsvc.DeleteResourcePoolRequest{} },
+}
+
+// _unscopedProcedures are the read-only procedures of the resource
+// pool APIs, which users restricted to a subset of resources may call
+// on any resource pool. Procedures which are neither listed here nor
+// in _requests, such as the internal resource manager APIs, are
+// denied to such users.
+var _unscopedProcedures = map[string]struct{}{
+	_respoolManager + "GetResourcePool":      {},
+	_respoolManager + "LookupResourcePoolID": {},
+	_respoolManager + "Query":                {},
+	_respoolService + "GetResourcePool":      {},
+	_respoolService + "LookupResourcePoolID": {},
+	_respoolService + "QueryResourcePools":   {},
+}
+
+// resolver resolves the resource pool targeted by a resource
+// pool request into the resource used for authorization
+type resolver struct {
+	tree res.Tree
+}
+
+// NewResourceResolver returns a resolver for the resource pools
+// targeted by resource pool requests
+func NewResourceResolver(tree res.Tree) inbound.ResourceResolver {
+	return &resolver{tree: tree}
+}
+
+// Resolve returns the resources of the resource pools targeted by
+// the request, or nil for read-only procedures. Procedures which are
+// not known to the resolver are denied.
+func (r *resolver) Resolve(
+	ctx context.Context,
+	procedure string,
+	encoding transport.Encoding,
+	body []byte,
+) ([]*auth.Resource, error) {
+	newRequest, ok := _requests[procedure]
+	if !ok {
+		if _, ok := _unscopedProcedures[procedure]; ok {
+			return nil, nil
+		}
+		return nil, yarpcerrors.PermissionDeniedErrorf(
+			"%s cannot be authorized on a resource", procedure)
+	}
+
+	request := newRequest()
+	if err := inbound.UnmarshalRequest(encoding, body, request); err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"failed to decode request of %s: %v", procedure, err)
+	}
+
+	paths, err := r.resolvePaths(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []*auth.Resource
+	for _, path := range paths {
+		resources = append(resources, &auth.Resource{RespoolPath: path})
+	}
+	return resources, nil
+}
+
+// resolvePaths returns the paths of the resource pools targeted by a
+// request. A resource pool being created is resolved into the path it
+// is created at, and a resource pool being updated into both its
+// current path and the path it is moved to.
+func (r *resolver) resolvePaths(request proto.Message) ([]string, error) {
+	switch req := request.(type) {
+	case *respool.CreateRequest:
+		path, err := r.getChildPath(
+			req.GetConfig().GetParent(), req.GetConfig().GetName())
+		if err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	case *respool.UpdateRequest:
+		return r.getUpdatePaths(
+			req.GetId(),
+			req.GetConfig().GetParent(),
+			req.GetConfig().GetName(),
+		)
+	case *respool.DeleteRequest:
+		return []string{req.GetPath().GetValue()}, nil
+	case *v1alpharespoolsvc.CreateResourcePoolRequest:
+		path, err := r.getChildPath(
+			toV0RespoolID(req.GetSpec().GetParent()), req.GetSpec().GetName())
+		if err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	case *v1alpharespoolsvc.UpdateResourcePoolRequest:
+		return r.getUpdatePaths(
+			toV0RespoolID(req.GetRespoolId()),
+			toV0RespoolID(req.GetSpec().GetParent()),
+			req.GetSpec().GetName(),
+		)
+	case *v1alpharespoolsvc.DeleteResourcePoolRequest:
+		path, err := r.getPath(toV0RespoolID(req.GetRespoolId()))
+		if err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	}
+
+	return nil, yarpcerrors.InternalErrorf(
+		"cannot get resource pool from request %s", proto.MessageName(request))
+}
+
+// getUpdatePaths returns the current path of a resource pool being
+// updated and the path it has after the update
+func (r *resolver) getUpdatePaths(
+	id *peloton.ResourcePoolID,
+	parentID *peloton.ResourcePoolID,
+	name string,
+) ([]string, error) {
+	current, err := r.getPath(id)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := r.getChildPath(parentID, name)
+	if err != nil {
+		return nil, err
+	}
+	return []string{current, updated}, nil
+}
+
+// getPath returns the path of a resource pool
+func (r *resolver) getPath(id *peloton.ResourcePoolID) (string, error) {
+	pool, err := r.tree.Get(id)
+	if err != nil {
+		return "", yarpcerrors.NotFoundErrorf(
+			"resource pool:%s not found", id.GetValue())
+	}
+	return pool.GetPath(), nil
+}
+
+// getChildPath returns the path of the resource pool named
+// name under the parent resource pool
+func (r *resolver) getChildPath(
+	parentID *peloton.ResourcePoolID,
+	name string,
+) (string, error) {
+	parentPath, err := r.getPath(parentID)
+	if err != nil {
+		return "", err
+	}
+	if parentPath == res.ResourcePoolPathDelimiter {
+		return res.ResourcePoolPathDelimiter + name, nil
+	}
+	return parentPath + res.ResourcePoolPathDelimiter + name, nil
+}
+
+// toV0RespoolID converts a v1alpha resource pool id to v0
+func toV0RespoolID(id *v1alphapeloton.ResourcePoolID) *peloton.ResourcePoolID {
+	if id == nil {
+		return nil
+	}
+	return &peloton.ResourcePoolID{Value: id.GetValue()}
+}
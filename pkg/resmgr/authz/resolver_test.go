@@ -0,0 +1,249 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authz
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	v1alphapeloton "github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	v1alpharespool "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool"
+	v1alpharespoolsvc "github.com/uber/peloton/.gen/peloton/api/v1alpha/respool/svc"
+
+	"github.com/uber/peloton/pkg/auth"
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/resmgr/respool/mocks"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/api/transport"
+	"go.uber.org/yarpc/encoding/protobuf"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+type ResolverTestSuite struct {
+	suite.Suite
+
+	ctrl     *gomock.Controller
+	tree     *mocks.MockTree
+	resolver *resolver
+
+	rootID   *peloton.ResourcePoolID
+	parentID *peloton.ResourcePoolID
+	poolID   *peloton.ResourcePoolID
+}
+
+func (suite *ResolverTestSuite) SetupTest() {
+	suite.ctrl = gomock.NewController(suite.T())
+	suite.tree = mocks.NewMockTree(suite.ctrl)
+	suite.resolver = NewResourceResolver(suite.tree).(*resolver)
+
+	suite.rootID = &peloton.ResourcePoolID{Value: common.RootResPoolID}
+	suite.parentID = &peloton.ResourcePoolID{Value: "team1"}
+	suite.poolID = &peloton.ResourcePoolID{Value: "pool1"}
+}
+
+func (suite *ResolverTestSuite) TearDownTest() {
+	suite.ctrl.Finish()
+}
+
+func TestResolver(t *testing.T) {
+	suite.Run(t, new(ResolverTestSuite))
+}
+
+func (suite *ResolverTestSuite) marshal(request proto.Message) []byte {
+	body, err := proto.Marshal(request)
+	suite.NoError(err)
+	return body
+}
+
+// expectPath sets up the tree to return a resource pool with the path
+func (suite *ResolverTestSuite) expectPath(
+	id *peloton.ResourcePoolID,
+	path string,
+) {
+	pool := mocks.NewMockResPool(suite.ctrl)
+	pool.EXPECT().GetPath().Return(path)
+	suite.tree.EXPECT().Get(id).Return(pool, nil)
+}
+
+func (suite *ResolverTestSuite) resolve(
+	procedure string,
+	request proto.Message,
+) ([]*auth.Resource, error) {
+	return suite.resolver.Resolve(
+		context.Background(),
+		procedure,
+		protobuf.Encoding,
+		suite.marshal(request),
+	)
+}
+
+// TestResolveCreate tests resolving a resource pool being created
+// into the path it is created at
+func (suite *ResolverTestSuite) TestResolveCreate() {
+	suite.expectPath(suite.parentID, "/team1")
+	resources, err := suite.resolve(
+		_respoolManager+"CreateResourcePool",
+		&respool.CreateRequest{
+			Config: &respool.ResourcePoolConfig{
+				Name:   "pool1",
+				Parent: suite.parentID,
+			},
+		},
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{{RespoolPath: "/team1/pool1"}}, resources)
+
+	// resource pool created under root
+	suite.expectPath(suite.rootID, "/")
+	resources, err = suite.resolve(
+		_respoolService+"CreateResourcePool",
+		&v1alpharespoolsvc.CreateResourcePoolRequest{
+			Spec: &v1alpharespool.ResourcePoolSpec{
+				Name:   "team2",
+				Parent: &v1alphapeloton.ResourcePoolID{Value: common.RootResPoolID},
+			},
+		},
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{{RespoolPath: "/team2"}}, resources)
+}
+
+// TestResolveUpdate tests resolving a resource pool being updated
+// into its current path and the path it has after the update
+func (suite *ResolverTestSuite) TestResolveUpdate() {
+	otherParentID := &peloton.ResourcePoolID{Value: "team2"}
+
+	suite.expectPath(suite.poolID, "/team1/pool1")
+	suite.expectPath(otherParentID, "/team2")
+	resources, err := suite.resolve(
+		_respoolManager+"UpdateResourcePool",
+		&respool.UpdateRequest{
+			Id: suite.poolID,
+			Config: &respool.ResourcePoolConfig{
+				Name:   "pool1",
+				Parent: otherParentID,
+			},
+		},
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{
+		{RespoolPath: "/team1/pool1"},
+		{RespoolPath: "/team2/pool1"},
+	}, resources)
+
+	suite.expectPath(suite.poolID, "/team1/pool1")
+	suite.expectPath(suite.parentID, "/team1")
+	resources, err = suite.resolve(
+		_respoolService+"UpdateResourcePool",
+		&v1alpharespoolsvc.UpdateResourcePoolRequest{
+			RespoolId: &v1alphapeloton.ResourcePoolID{Value: suite.poolID.GetValue()},
+			Spec: &v1alpharespool.ResourcePoolSpec{
+				Name:   "pool1",
+				Parent: &v1alphapeloton.ResourcePoolID{Value: suite.parentID.GetValue()},
+			},
+		},
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{
+		{RespoolPath: "/team1/pool1"},
+		{RespoolPath: "/team1/pool1"},
+	}, resources)
+}
+
+// TestResolveDelete tests resolving a resource pool being deleted
+func (suite *ResolverTestSuite) TestResolveDelete() {
+	resources, err := suite.resolve(
+		_respoolManager+"DeleteResourcePool",
+		&respool.DeleteRequest{
+			Path: &respool.ResourcePoolPath{Value: "/team1/pool1"},
+		},
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{{RespoolPath: "/team1/pool1"}}, resources)
+
+	suite.expectPath(suite.poolID, "/team1/pool1")
+	resources, err = suite.resolve(
+		_respoolService+"DeleteResourcePool",
+		&v1alpharespoolsvc.DeleteResourcePoolRequest{
+			RespoolId: &v1alphapeloton.ResourcePoolID{Value: suite.poolID.GetValue()},
+		},
+	)
+	suite.NoError(err)
+	suite.Equal([]*auth.Resource{{RespoolPath: "/team1/pool1"}}, resources)
+}
+
+// TestResolveNotFound tests resolving a resource pool which
+// is not in the tree
+func (suite *ResolverTestSuite) TestResolveNotFound() {
+	suite.tree.EXPECT().Get(suite.parentID).Return(nil, errors.New("not found"))
+	_, err := suite.resolve(
+		_respoolManager+"CreateResourcePool",
+		&respool.CreateRequest{
+			Config: &respool.ResourcePoolConfig{
+				Name:   "pool1",
+				Parent: suite.parentID,
+			},
+		},
+	)
+	suite.True(yarpcerrors.IsNotFound(err))
+}
+
+// TestResolveNoResource tests that read-only procedures are not
+// resolved, and that other procedures are denied
+func (suite *ResolverTestSuite) TestResolveNoResource() {
+	resources, err := suite.resolver.Resolve(
+		context.Background(),
+		_respoolManager+"Query",
+		protobuf.Encoding,
+		nil,
+	)
+	suite.NoError(err)
+	suite.Nil(resources)
+
+	_, err = suite.resolver.Resolve(
+		context.Background(),
+		"peloton.private.resmgr.ResourceManagerService::KillTasks",
+		protobuf.Encoding,
+		nil,
+	)
+	suite.True(yarpcerrors.IsPermissionDenied(err))
+}
+
+// TestAllProceduresResolvable walks every procedure of the resource
+// pool APIs, and checks that it either has a resolver or is a
+// read-only procedure, so that no mutating procedure bypasses
+// resource-scoped authorization
+func (suite *ResolverTestSuite) TestAllProceduresResolvable() {
+	var procedures []transport.Procedure
+	procedures = append(procedures,
+		respool.BuildResourceManagerYARPCProcedures(nil)...)
+	procedures = append(procedures,
+		v1alpharespoolsvc.BuildResourcePoolServiceYARPCProcedures(nil)...)
+
+	for _, procedure := range procedures {
+		_, resolved := _requests[procedure.Name]
+		_, unscoped := _unscopedProcedures[procedure.Name]
+		suite.True(resolved || unscoped,
+			"procedure %s has no resolver", procedure.Name)
+		suite.False(resolved && unscoped,
+			"procedure %s is both resolved and unscoped", procedure.Name)
+	}
+}
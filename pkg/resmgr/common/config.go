@@ -30,6 +30,10 @@ type PreemptionConfig struct {
 	// If the value exceeds this number then the preemption logic will kick
 	// in to reduce the allocation.
 	SustainedOverAllocationCount int `yaml:"sustained_over_allocation_count"`
+
+	// Ranker is the name of the ranker which picks the tasks to evict from
+	// a resource pool, "state_priority_runtime" (default) or "cost_aware".
+	Ranker string `yaml:"ranker"`
 }
 
 // RecoveryConfig is the container for recovery related config
@@ -148,8 +148,16 @@ func (c *Calculator) calculateEntitlement(ctx context.Context) error {
 		return err
 	}
 
+	return c.calculateEntitlementForTree(ctx, rootResPool)
+}
+
+// calculateEntitlementForTree calculates and sets the entitlement for all
+// the resource pools of the hierarchy rooted at the given resource pool
+func (c *Calculator) calculateEntitlementForTree(
+	ctx context.Context,
+	rootResPool respool.ResPool) error {
 	// Updating cluster capacity
-	if err = c.updateClusterCapacity(ctx, rootResPool); err != nil {
+	if err := c.updateClusterCapacity(ctx, rootResPool); err != nil {
 		return err
 	}
 	// Invoking the demand calculation
@@ -0,0 +1,130 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entitlement
+
+import (
+	"context"
+	"sort"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pb_res "github.com/uber/peloton/.gen/peloton/api/v0/respool"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/resmgr/respool"
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+)
+
+// Diff is the entitlement of a resource pool with the current
+// resource pool configs and with the proposed ones.
+type Diff struct {
+	// ID of the resource pool
+	ID string
+	// Path of the resource pool
+	Path string
+	// Entitlement with the current resource pool configs
+	Current *scalar.Resources
+	// Entitlement with the proposed resource pool configs
+	Simulated *scalar.Resources
+}
+
+// Simulate runs the entitlement calculation as a dry-run on copies of the
+// resource pool tree, with the current resource pool configs and with the
+// proposed ones keyed by resource pool ID. Both calculations use the
+// current demand, allocation and cluster capacity, so that the difference
+// in entitlement is only due to the proposed configs. The entitlement of
+// the live resource pools is not changed. Returns the entitlement diffs of
+// all the resource pools ordered by path.
+func (c *Calculator) Simulate(
+	ctx context.Context,
+	configs map[string]*pb_res.ResourcePoolConfig,
+) ([]*Diff, error) {
+	rootResPool, err := c.resPoolTree.Get(&peloton.ResourcePoolID{
+		Value: common.RootResPoolID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := c.simulateEntitlement(ctx, rootResPool, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	simulated, err := c.simulateEntitlement(ctx, rootResPool, configs)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []*Diff
+	for id, resPool := range simulated {
+		diff := &Diff{
+			ID:        id,
+			Path:      resPool.GetPath(),
+			Current:   &scalar.Resources{},
+			Simulated: resPool.GetEntitlement(),
+		}
+		if currentResPool, ok := current[id]; ok {
+			diff.Current = currentResPool.GetEntitlement()
+		}
+		diffs = append(diffs, diff)
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+	return diffs, nil
+}
+
+// simulateEntitlement calculates the entitlement on a copy of the resource
+// pool tree with the given configs, and returns the copied resource pools
+// keyed by resource pool ID.
+func (c *Calculator) simulateEntitlement(
+	ctx context.Context,
+	rootResPool respool.ResPool,
+	configs map[string]*pb_res.ResourcePoolConfig,
+) (map[string]respool.ResPool, error) {
+	root, err := respool.CopyTree(rootResPool, configs)
+	if err != nil {
+		return nil, err
+	}
+
+	// A separate calculator is used so that the cluster capacity of the
+	// running calculator is not updated by the simulation.
+	simulator := &Calculator{
+		resPoolTree:          c.resPoolTree,
+		hostMgrClient:        c.hostMgrClient,
+		clusterCapacity:      make(map[string]float64),
+		clusterSlackCapacity: make(map[string]float64),
+		metrics:              c.metrics,
+	}
+	if err := simulator.calculateEntitlementForTree(ctx, root); err != nil {
+		return nil, err
+	}
+
+	resPools := make(map[string]respool.ResPool)
+	collectResPools(root, resPools)
+	return resPools, nil
+}
+
+// collectResPools adds the resource pool and all its descendants to the map
+// keyed by resource pool ID
+func collectResPools(
+	resPool respool.ResPool,
+	resPools map[string]respool.ResPool,
+) {
+	resPools[resPool.ID()] = resPool
+	for e := resPool.Children().Front(); e != nil; e = e.Next() {
+		collectResPools(e.Value.(respool.ResPool), resPools)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package entitlement
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pb_respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
+	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
+	host_mocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
+
+	"github.com/uber/peloton/pkg/common"
+	"github.com/uber/peloton/pkg/resmgr/respool"
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+
+	"github.com/golang/mock/gomock"
+)
+
+// TestSimulate tests that the simulated entitlement reflects the proposed
+// static reservation, and the live resource pools are not changed
+func (s *EntitlementCalculatorTestSuite) TestSimulate() {
+	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().
+		ClusterCapacity(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.ClusterCapacityResponse{
+			PhysicalResources:      s.createClusterCapacity(),
+			PhysicalSlackResources: s.createSlackClusterCapacity(),
+		}, nil).
+		AnyTimes()
+	s.calculator.hostMgrClient = mockHostMgr
+	s.calculator.resPoolTree = s.resTree
+
+	resPool11, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool11"})
+	s.NoError(err)
+	resPool11.AddToDemand(&scalar.Resources{
+		CPU:    20,
+		MEMORY: 200,
+		DISK:   2000,
+		GPU:    0,
+	})
+	s.NoError(s.calculator.calculateEntitlement(context.Background()))
+
+	entitlements := make(map[string]*scalar.Resources)
+	for e := s.resTree.GetAllNodes(false).Front(); e != nil; e = e.Next() {
+		n := e.Value.(respool.ResPool)
+		entitlements[n.ID()] = n.GetEntitlement()
+	}
+
+	// respool12 has no demand, a static reservation makes it entitled
+	// to the reserved cpus at the expense of its sibling.
+	config := s.getResPools()["respool12"]
+	config.Resources = s.getResourceConfig()
+	config.Resources[0].Reservation = 40
+	config.Resources[0].Type = pb_respool.ReservationType_STATIC
+
+	diffs, err := s.calculator.Simulate(
+		context.Background(),
+		map[string]*pb_respool.ResourcePoolConfig{"respool12": config},
+	)
+	s.NoError(err)
+	s.Len(diffs, len(entitlements))
+
+	simulated := make(map[string]*scalar.Resources)
+	for i, diff := range diffs {
+		if i > 0 {
+			s.True(diffs[i-1].Path < diff.Path)
+		}
+		s.Equal(entitlements[diff.ID], diff.Current)
+		simulated[diff.ID] = diff.Simulated
+	}
+	s.Equal("/", diffs[0].Path)
+	s.Equal(float64(40), simulated["respool12"].Get(common.CPU))
+	s.True(simulated["respool11"].Get(common.CPU) <
+		entitlements["respool11"].Get(common.CPU))
+	s.Equal(entitlements["respool2"], simulated["respool2"])
+
+	// the live resource pools are not changed
+	for e := s.resTree.GetAllNodes(false).Front(); e != nil; e = e.Next() {
+		n := e.Value.(respool.ResPool)
+		s.Equal(entitlements[n.ID()], n.GetEntitlement())
+	}
+	resPool12, err := s.resTree.Get(&peloton.ResourcePoolID{Value: "respool12"})
+	s.NoError(err)
+	s.Equal(float64(10), resPool12.Resources()[common.CPU].GetReservation())
+}
+
+// TestSimulateClusterCapacityError tests that the error to get the cluster
+// capacity is returned
+func (s *EntitlementCalculatorTestSuite) TestSimulateClusterCapacityError() {
+	mockHostMgr := host_mocks.NewMockInternalHostServiceYARPCClient(s.mockCtrl)
+	mockHostMgr.EXPECT().
+		ClusterCapacity(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("capacity error"))
+	s.calculator.hostMgrClient = mockHostMgr
+	s.calculator.resPoolTree = s.resTree
+
+	_, err := s.calculator.Simulate(context.Background(), nil)
+	s.Error(err)
+}
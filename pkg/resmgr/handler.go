@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	pb_respool "github.com/uber/peloton/.gen/peloton/api/v0/respool"
 	t "github.com/uber/peloton/.gen/peloton/api/v0/task"
 	pb_eventstream "github.com/uber/peloton/.gen/peloton/private/eventstream"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
@@ -34,6 +35,7 @@ import (
 	"github.com/uber/peloton/pkg/common/queue"
 	"github.com/uber/peloton/pkg/common/statemachine"
 	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/resmgr/entitlement"
 	"github.com/uber/peloton/pkg/resmgr/preemption"
 	r_queue "github.com/uber/peloton/pkg/resmgr/queue"
 	"github.com/uber/peloton/pkg/resmgr/respool"
@@ -88,6 +90,8 @@ type ServiceHandler struct {
 	rmTracker rmtask.Tracker
 	// in-memory resource pool tree
 	resPoolTree respool.Tree
+	// entitlement calculator to simulate the entitlement
+	calculator *entitlement.Calculator
 
 	hostmgrClient hostsvc.InternalHostServiceYARPCClient
 }
@@ -100,6 +104,7 @@ func NewServiceHandler(
 	tree respool.Tree,
 	preemptionQueue preemption.Queue,
 	hostmgrClient hostsvc.InternalHostServiceYARPCClient,
+	calculator *entitlement.Calculator,
 	conf Config) *ServiceHandler {
 
 	var maxOffset uint64
@@ -123,6 +128,7 @@ func NewServiceHandler(
 			_eventStreamBufferSize,
 			parent.SubScope("resmgr")),
 		hostmgrClient: hostmgrClient,
+		calculator:    calculator,
 	}
 
 	return handler
@@ -821,6 +827,7 @@ func (h *ServiceHandler) acknowledgeEvent(offset uint64) {
 }
 
 func (h *ServiceHandler) fillTaskEntry(task *rmtask.RMTask,
+	includeTask bool,
 ) *resmgrsvc.GetActiveTasksResponse_TaskEntry {
 	rmTaskState := task.GetCurrentState()
 	taskEntry := &resmgrsvc.GetActiveTasksResponse_TaskEntry{
@@ -829,6 +836,9 @@ func (h *ServiceHandler) fillTaskEntry(task *rmtask.RMTask,
 		Reason:         rmTaskState.Reason,
 		LastUpdateTime: rmTaskState.LastUpdateTime.String(),
 	}
+	if includeTask {
+		taskEntry.Task = task.Task()
+	}
 	return taskEntry
 }
 
@@ -848,7 +858,7 @@ func (h *ServiceHandler) GetActiveTasks(
 	)
 	for state, tasks := range taskStateMap {
 		for _, task := range tasks {
-			taskEntry := h.fillTaskEntry(task)
+			taskEntry := h.fillTaskEntry(task, req.GetIncludeTasks())
 			if _, ok := taskStates[state]; !ok {
 				var taskList resmgrsvc.GetActiveTasksResponse_TaskEntries
 				taskStates[state] = &taskList
@@ -942,6 +952,191 @@ func (h *ServiceHandler) GetPendingTasks(
 	}, nil
 }
 
+// SimulateEntitlement runs the entitlement calculation as a dry-run with
+// the proposed resource pool configs, and returns the current and simulated
+// entitlement of all the resource pools.
+func (h *ServiceHandler) SimulateEntitlement(
+	ctx context.Context,
+	req *resmgrsvc.SimulateEntitlementRequest,
+) (*resmgrsvc.SimulateEntitlementResponse, error) {
+	log.WithField("request", req).Info("SimulateEntitlement called")
+
+	// Only the proposed config itself is validated, since the reservations
+	// of the parent and siblings may be changed by the same request.
+	validator, err := respool.NewResourcePoolConfigValidator(nil)
+	if err != nil {
+		return &resmgrsvc.SimulateEntitlementResponse{},
+			status.Errorf(codes.Internal,
+				"failed to initialize config validator, err:%s", err.Error())
+	}
+	validator.Register([]respool.ResourcePoolConfigValidatorFunc{
+		respool.ValidateResourcePool,
+		respool.ValidateControllerLimit,
+	})
+
+	configs := make(map[string]*pb_respool.ResourcePoolConfig)
+	for _, change := range req.GetChanges() {
+		respoolID := change.GetRespoolID()
+		if respoolID == nil {
+			return &resmgrsvc.SimulateEntitlementResponse{},
+				status.Errorf(codes.InvalidArgument,
+					"resource pool ID can't be nil")
+		}
+
+		node, err := h.resPoolTree.Get(respoolID)
+		if err != nil {
+			return &resmgrsvc.SimulateEntitlementResponse{},
+				status.Errorf(codes.NotFound,
+					"resource pool ID not found:%s", respoolID)
+		}
+
+		if _, ok := configs[respoolID.GetValue()]; ok {
+			return &resmgrsvc.SimulateEntitlementResponse{},
+				status.Errorf(codes.InvalidArgument,
+					"duplicate config for resource pool:%s", respoolID)
+		}
+
+		if err := validator.Validate(respool.ResourcePoolConfigData{
+			ID:                 respoolID,
+			ResourcePoolConfig: change.GetConfig(),
+		}); err != nil {
+			return &resmgrsvc.SimulateEntitlementResponse{},
+				status.Errorf(codes.InvalidArgument,
+					"invalid config for resource pool:%s, err:%s",
+					respoolID, err.Error())
+		}
+
+		if change.GetConfig().GetParent().GetValue() != node.Parent().ID() {
+			return &resmgrsvc.SimulateEntitlementResponse{},
+				status.Errorf(codes.InvalidArgument,
+					"parent of resource pool:%s can't be changed", respoolID)
+		}
+
+		configs[respoolID.GetValue()] = change.GetConfig()
+	}
+
+	diffs, err := h.calculator.Simulate(ctx, configs)
+	if err != nil {
+		return &resmgrsvc.SimulateEntitlementResponse{},
+			status.Errorf(codes.Internal,
+				"failed to simulate entitlement, err:%s", err.Error())
+	}
+
+	var entitlements []*resmgrsvc.SimulateEntitlementResponse_ResourcePoolEntitlement
+	for _, diff := range diffs {
+		var resources []*resmgrsvc.SimulateEntitlementResponse_ResourceEntitlement
+		for _, kind := range []string{
+			common.CPU,
+			common.GPU,
+			common.MEMORY,
+			common.DISK} {
+			resources = append(resources,
+				&resmgrsvc.SimulateEntitlementResponse_ResourceEntitlement{
+					Kind:      kind,
+					Current:   diff.Current.Get(kind),
+					Simulated: diff.Simulated.Get(kind),
+				})
+		}
+		entitlements = append(entitlements,
+			&resmgrsvc.SimulateEntitlementResponse_ResourcePoolEntitlement{
+				RespoolID: &peloton.ResourcePoolID{Value: diff.ID},
+				Path:      diff.Path,
+				Resources: resources,
+			})
+	}
+
+	return &resmgrsvc.SimulateEntitlementResponse{
+		Entitlements: entitlements,
+	}, nil
+}
+
+// GetPendingReason returns why the given tasks are not placed yet, from the
+// last admission decision of their resource pool and their last failed
+// placement.
+func (h *ServiceHandler) GetPendingReason(
+	ctx context.Context,
+	req *resmgrsvc.GetPendingReasonRequest,
+) (*resmgrsvc.GetPendingReasonResponse, error) {
+	log.WithField("request", req).Debug("GetPendingReason called")
+
+	if len(req.GetTaskIDs()) == 0 {
+		return &resmgrsvc.GetPendingReasonResponse{},
+			status.Errorf(codes.InvalidArgument, "no tasks are provided")
+	}
+
+	resp := &resmgrsvc.GetPendingReasonResponse{}
+	for _, taskID := range req.GetTaskIDs() {
+		rmTask := h.rmTracker.GetTask(taskID)
+		if rmTask == nil {
+			resp.NotFound = append(resp.NotFound, taskID)
+			continue
+		}
+		resp.Reasons = append(resp.Reasons, newPendingReason(taskID, rmTask))
+	}
+
+	log.WithField("response", resp).Debug("GetPendingReason returned")
+	return resp, nil
+}
+
+// newPendingReason returns the pending reason of the rm task
+func newPendingReason(
+	taskID *peloton.TaskID,
+	rmTask *rmtask.RMTask,
+) *resmgrsvc.GetPendingReasonResponse_PendingReason {
+	currentState := rmTask.GetCurrentState().State
+	reason := &resmgrsvc.GetPendingReasonResponse_PendingReason{
+		TaskID:      taskID,
+		State:       currentState,
+		RespoolPath: rmTask.Respool().GetPath(),
+	}
+
+	var decision *respool.AdmissionDecision
+	if currentState == t.TaskState_PENDING {
+		// the admission decision is only relevant while the task waits
+		// for admission
+		decision = rmTask.Respool().GetAdmissionDecision(taskID)
+	}
+	if decision != nil {
+		reason.Queue = decision.Queue.String()
+		reason.AdmissionCheck = decision.Check
+		reason.AdmissionMessage = decision.Message
+		reason.AdmissionTime = decision.Time.UTC().Format(time.RFC3339)
+	}
+
+	failure := rmTask.GetPlacementFailure()
+	if failure != nil {
+		reason.PlacementMessage = failure.Reason
+		reason.PlacementTime = failure.Time.UTC().Format(time.RFC3339)
+	}
+
+	switch currentState {
+	case t.TaskState_PENDING:
+		if decision != nil {
+			reason.Message = fmt.Sprintf(
+				"waiting for admission in the %s queue of resource pool %s, "+
+					"rejected by the %s check: %s",
+				reason.Queue, reason.RespoolPath,
+				decision.Check, decision.Message)
+		} else {
+			reason.Message = fmt.Sprintf(
+				"waiting for admission behind other gangs in resource pool %s",
+				reason.RespoolPath)
+		}
+	case t.TaskState_READY, t.TaskState_PLACING:
+		reason.Message = "admitted, waiting for placement"
+	default:
+		reason.Message = fmt.Sprintf(
+			"task is not pending, it is in state %s", currentState)
+		return reason
+	}
+
+	if failure != nil {
+		reason.Message += fmt.Sprintf(
+			", last placement failed: %s", failure.Reason)
+	}
+	return reason
+}
+
 func (h *ServiceHandler) getPendingGangs(node respool.ResPool,
 	limit uint32) (map[respool.QueueType][]*resmgrsvc.Gang,
 	error) {
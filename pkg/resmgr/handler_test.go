@@ -37,6 +37,7 @@ import (
 	"github.com/uber/peloton/pkg/common/queue"
 	"github.com/uber/peloton/pkg/common/statemachine"
 	rc "github.com/uber/peloton/pkg/resmgr/common"
+	"github.com/uber/peloton/pkg/resmgr/entitlement"
 	"github.com/uber/peloton/pkg/resmgr/preemption/mocks"
 	"github.com/uber/peloton/pkg/resmgr/respool"
 	rm "github.com/uber/peloton/pkg/resmgr/respool/mocks"
@@ -52,6 +53,8 @@ import (
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
 	"go.uber.org/yarpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 const (
@@ -175,6 +178,7 @@ func (s *HandlerTestSuite) TestNewServiceHandler() {
 		s.resTree,
 		mockPreemptionQueue,
 		mockHostmgrClient,
+		nil,
 		Config{})
 	s.NotNil(handler)
 
@@ -1120,8 +1124,21 @@ func (s *HandlerTestSuite) TestGetActiveTasks() {
 	totalTasks := 0
 	for _, tasks := range res.GetTasksByState() {
 		totalTasks += len(tasks.GetTaskEntry())
+		for _, entry := range tasks.GetTaskEntry() {
+			s.Nil(entry.GetTask())
+		}
 	}
 	s.Equal(54, totalTasks)
+
+	// the tasks are only included on request
+	req = &resmgrsvc.GetActiveTasksRequest{IncludeTasks: true}
+	res, err = s.handler.GetActiveTasks(context.Background(), req)
+	s.NoError(err)
+	for _, tasks := range res.GetTasksByState() {
+		for _, entry := range tasks.GetTaskEntry() {
+			s.Equal(entry.GetTaskID(), entry.GetTask().GetId().GetValue())
+		}
+	}
 }
 
 func (s *HandlerTestSuite) TestGetPreemptibleTasks() {
@@ -1355,6 +1372,131 @@ func (s *HandlerTestSuite) TestRequeueInvalidatedTasks() {
 	s.EqualValues(rmtask.GetCurrentState().State, task.TaskState_READY)
 }
 
+func (s *HandlerTestSuite) TestSimulateEntitlement() {
+	s.mockHostmgrClient.EXPECT().
+		ClusterCapacity(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.ClusterCapacityResponse{
+			PhysicalResources: []*hostsvc.Resource{
+				{Kind: common.CPU, Capacity: 1000},
+				{Kind: common.MEMORY, Capacity: 1000},
+				{Kind: common.DISK, Capacity: 1000},
+				{Kind: common.GPU, Capacity: 10},
+			},
+		}, nil).
+		Times(2)
+	s.handler.calculator = entitlement.NewCalculator(
+		time.Second,
+		tally.NoopScope,
+		s.mockHostmgrClient,
+		s.resTree,
+	)
+
+	config := s.getResPools()["respool11"]
+	config.Resources[0].Reservation = 200
+	config.Resources[0].Type = pb_respool.ReservationType_STATIC
+
+	resp, err := s.handler.SimulateEntitlement(
+		s.context,
+		&resmgrsvc.SimulateEntitlementRequest{
+			Changes: []*resmgrsvc.SimulateEntitlementRequest_ResourcePoolConfigChange{
+				{
+					RespoolID: &peloton.ResourcePoolID{Value: "respool11"},
+					Config:    config,
+				},
+			},
+		})
+	s.NoError(err)
+	s.Len(resp.GetEntitlements(), len(s.getResPools()))
+
+	for _, e := range resp.GetEntitlements() {
+		s.Len(e.GetResources(), 4)
+		if e.GetRespoolID().GetValue() != "respool11" {
+			continue
+		}
+		s.Equal("/respool1/respool11", e.GetPath())
+		for _, r := range e.GetResources() {
+			if r.GetKind() == common.CPU {
+				s.True(r.GetSimulated() >= 200)
+				s.True(r.GetSimulated() > r.GetCurrent())
+			}
+		}
+	}
+}
+
+func (s *HandlerTestSuite) TestSimulateEntitlementInvalidRequest() {
+	validConfig := s.getResPools()["respool11"]
+	invalidConfig := s.getResPools()["respool11"]
+	invalidConfig.Resources[0].Reservation = 2000
+	parentConfig := s.getResPools()["respool11"]
+	parentConfig.Parent = &peloton.ResourcePoolID{Value: "respool2"}
+
+	tt := []struct {
+		msg     string
+		changes []*resmgrsvc.SimulateEntitlementRequest_ResourcePoolConfigChange
+		code    codes.Code
+	}{
+		{
+			msg: "nil resource pool ID",
+			changes: []*resmgrsvc.SimulateEntitlementRequest_ResourcePoolConfigChange{
+				{Config: validConfig},
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			msg: "resource pool not found",
+			changes: []*resmgrsvc.SimulateEntitlementRequest_ResourcePoolConfigChange{
+				{
+					RespoolID: &peloton.ResourcePoolID{Value: "respool-none"},
+					Config:    validConfig,
+				},
+			},
+			code: codes.NotFound,
+		},
+		{
+			msg: "reservation exceeds limit",
+			changes: []*resmgrsvc.SimulateEntitlementRequest_ResourcePoolConfigChange{
+				{
+					RespoolID: &peloton.ResourcePoolID{Value: "respool11"},
+					Config:    invalidConfig,
+				},
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			msg: "parent changed",
+			changes: []*resmgrsvc.SimulateEntitlementRequest_ResourcePoolConfigChange{
+				{
+					RespoolID: &peloton.ResourcePoolID{Value: "respool11"},
+					Config:    parentConfig,
+				},
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			msg: "duplicate resource pool",
+			changes: []*resmgrsvc.SimulateEntitlementRequest_ResourcePoolConfigChange{
+				{
+					RespoolID: &peloton.ResourcePoolID{Value: "respool11"},
+					Config:    validConfig,
+				},
+				{
+					RespoolID: &peloton.ResourcePoolID{Value: "respool11"},
+					Config:    validConfig,
+				},
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, t := range tt {
+		_, err := s.handler.SimulateEntitlement(
+			s.context,
+			&resmgrsvc.SimulateEntitlementRequest{Changes: t.changes})
+		s.Error(err, t.msg)
+		s.Equal(t.code, status.Code(err), t.msg)
+	}
+}
+
 func (s *HandlerTestSuite) TestGetPendingTasks() {
 	respoolID := &peloton.ResourcePoolID{Value: "respool3"}
 	limit := uint32(1)
@@ -1449,6 +1591,81 @@ func (s *HandlerTestSuite) TestGetPendingTasks() {
 // Test helpers
 // -----------------
 
+func (s *HandlerTestSuite) TestGetPendingReason() {
+	tracker := task_mocks.NewMockTracker(s.ctrl)
+	s.handler.rmTracker = tracker
+	defer func() {
+		s.handler.rmTracker = rm_task.GetTracker()
+	}()
+
+	mockResPool := rm.NewMockResPool(s.ctrl)
+	mockResPool.EXPECT().GetPath().Return("/respool1").AnyTimes()
+
+	createTask := func(
+		taskID *peloton.TaskID,
+		states []task.TaskState) *rm_task.RMTask {
+		rmTask, err := rm_task.CreateRMTask(
+			tally.NoopScope,
+			&resmgr.Task{Id: taskID},
+			nil,
+			mockResPool,
+			tasktestutil.CreateTaskConfig())
+		s.NoError(err)
+		tasktestutil.ValidateStateTransitions(rmTask, states)
+		return rmTask
+	}
+
+	pendingID := &peloton.TaskID{Value: "job1-1"}
+	readyID := &peloton.TaskID{Value: "job1-2"}
+	unknownID := &peloton.TaskID{Value: "job1-3"}
+	tracker.EXPECT().GetTask(pendingID).Return(
+		createTask(pendingID, []task.TaskState{task.TaskState_PENDING}))
+	tracker.EXPECT().GetTask(readyID).Return(
+		createTask(readyID, []task.TaskState{
+			task.TaskState_PENDING,
+			task.TaskState_READY}))
+	tracker.EXPECT().GetTask(unknownID).Return(nil)
+
+	now := time.Now()
+	mockResPool.EXPECT().GetAdmissionDecision(pendingID).
+		Return(&respool.AdmissionDecision{
+			Queue:   respool.PendingQueue,
+			Check:   respool.AdmissionCheckEntitlement,
+			Message: "allocation exceeds entitlement",
+			Time:    now,
+		})
+
+	resp, err := s.handler.GetPendingReason(
+		s.context,
+		&resmgrsvc.GetPendingReasonRequest{
+			TaskIDs: []*peloton.TaskID{pendingID, readyID, unknownID},
+		})
+	s.NoError(err)
+	s.Equal([]*peloton.TaskID{unknownID}, resp.GetNotFound())
+	s.Len(resp.GetReasons(), 2)
+
+	pending := resp.GetReasons()[0]
+	s.Equal(pendingID, pending.GetTaskID())
+	s.Equal(task.TaskState_PENDING, pending.GetState())
+	s.Equal("/respool1", pending.GetRespoolPath())
+	s.Equal("pending", pending.GetQueue())
+	s.Equal(respool.AdmissionCheckEntitlement, pending.GetAdmissionCheck())
+	s.Equal(now.UTC().Format(time.RFC3339), pending.GetAdmissionTime())
+	s.Contains(pending.GetMessage(), "allocation exceeds entitlement")
+
+	ready := resp.GetReasons()[1]
+	s.Equal(task.TaskState_READY, ready.GetState())
+	s.Empty(ready.GetAdmissionCheck())
+	s.Empty(ready.GetPlacementMessage())
+	s.Equal("admitted, waiting for placement", ready.GetMessage())
+
+	// at least one task is required
+	_, err = s.handler.GetPendingReason(
+		s.context,
+		&resmgrsvc.GetPendingReasonRequest{})
+	s.Equal(codes.InvalidArgument, status.Code(err))
+}
+
 func (s *HandlerTestSuite) getEntitlement() *scalar.Resources {
 	return &scalar.Resources{
 		CPU:    100,
@@ -16,8 +16,11 @@ package host
 
 import (
 	"context"
+	"strings"
 	"time"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 
@@ -51,6 +54,12 @@ type Drainer struct {
 	preemptionQueue preemption.Queue    // Preemption Queue
 	lifecycle       lifecycle.LifeCycle // Lifecycle manager
 	drainingHosts   stringset.StringSet // Set of hosts currently being drained
+
+	// Running tasks which have been handed over to the preemption queue
+	// for host maintenance, keyed by peloton task ID with the mesos task ID
+	// of the evicted run as value. These count as unavailable for the job
+	// until the replacement run shows up in the tracker.
+	evictions map[string]string
 }
 
 // NewDrainer creates a new Drainer
@@ -69,6 +78,7 @@ func NewDrainer(
 		drainerPeriod:   drainerPeriod,
 		lifecycle:       lifecycle.NewLifeCycle(),
 		drainingHosts:   stringset.New(),
+		evictions:       make(map[string]string),
 	}
 }
 
@@ -156,6 +166,7 @@ func (d *Drainer) drainHosts() error {
 	}
 	// Get all tasks on the DRAINING hosts
 	tasksByHost := d.rmTracker.TasksByHosts(drainingHosts, resmgr.TaskType_UNKNOWN)
+	budgets := d.getUnavailabilityBudgets(tasksByHost)
 	var drainedHosts []string
 	for _, host := range drainingHosts {
 		if _, ok := tasksByHost[host]; !ok {
@@ -163,15 +174,22 @@ func (d *Drainer) drainHosts() error {
 			continue
 		}
 
+		tasks := d.filterTasksWithinSLA(tasksByHost[host], budgets)
+		if len(tasks) == 0 {
+			continue
+		}
+
 		err := d.preemptionQueue.EnqueueTasks(
-			tasksByHost[host],
+			tasks,
 			resmgr.PreemptionReason_PREEMPTION_REASON_HOST_MAINTENANCE)
 		if err != nil {
 			log.WithField("host", host).
 				WithError(err).
 				Error("Failed to enqueue some tasks")
 			errs = multierror.Append(errs, err)
+			continue
 		}
+		d.recordEvictions(tasks)
 	}
 	if len(drainedHosts) != 0 {
 		err := d.markHostsDrained(drainedHosts)
@@ -184,6 +202,124 @@ func (d *Drainer) drainHosts() error {
 	return errs
 }
 
+// getUnavailabilityBudgets returns, for every job with a task on the given
+// hosts, the number of additional instances which can be made unavailable
+// without violating the maximum unavailable instances of the job SLA.
+// Jobs without such a limit are not present in the result.
+func (d *Drainer) getUnavailabilityBudgets(
+	tasksByHost map[string][]*rmtask.RMTask) map[string]uint32 {
+	limits := make(map[string]uint32)
+	for _, tasks := range tasksByHost {
+		for _, t := range tasks {
+			limit := t.Task().GetMaximumUnavailableInstances()
+			if limit == 0 {
+				continue
+			}
+			limits[t.Task().GetJobId().GetValue()] = limit
+		}
+	}
+
+	budgets := make(map[string]uint32)
+	for jobID, limit := range limits {
+		unavailable := d.getUnavailableInstances(jobID)
+		if unavailable >= limit {
+			budgets[jobID] = 0
+			continue
+		}
+		budgets[jobID] = limit - unavailable
+	}
+	return budgets
+}
+
+// getUnavailableInstances returns the number of instances of the job which
+// are either not running or are being evicted by the drainer.
+func (d *Drainer) getUnavailableInstances(jobID string) uint32 {
+	var unavailable uint32
+	running := make(map[string]*rmtask.RMTask)
+	for state, tasks := range d.rmTracker.GetActiveTasks(jobID, "", nil) {
+		for _, t := range tasks {
+			if state != task.TaskState_RUNNING.String() {
+				unavailable++
+				continue
+			}
+			running[t.Task().GetId().GetValue()] = t
+		}
+	}
+	jobGone := unavailable == 0 && len(running) == 0
+
+	for taskID, mesosTaskID := range d.evictions {
+		if !strings.HasPrefix(taskID, jobID+"-") {
+			continue
+		}
+		if jobGone {
+			delete(d.evictions, taskID)
+			continue
+		}
+
+		current := d.rmTracker.GetTask(&peloton.TaskID{Value: taskID})
+		switch {
+		case current == nil:
+			// The evicted run has been killed and the replacement
+			// has not been enqueued yet.
+			unavailable++
+		case current.Task().GetTaskId().GetValue() != mesosTaskID:
+			// The replacement run is in the tracker and has
+			// already been accounted for above.
+			delete(d.evictions, taskID)
+		case running[taskID] != nil:
+			// The evicted run has not been killed yet.
+			unavailable++
+		}
+	}
+	return unavailable
+}
+
+// filterTasksWithinSLA returns the tasks which can be evicted without
+// exceeding the unavailability budget of their job, and consumes the budget
+// for them. Tasks which are not running are always returned since evicting
+// them does not change the availability of the job.
+func (d *Drainer) filterTasksWithinSLA(
+	tasks []*rmtask.RMTask,
+	budgets map[string]uint32) []*rmtask.RMTask {
+	var result []*rmtask.RMTask
+	for _, t := range tasks {
+		taskID := t.Task().GetId().GetValue()
+		jobID := t.Task().GetJobId().GetValue()
+		budget, ok := budgets[jobID]
+		if !ok ||
+			t.GetCurrentState().State != task.TaskState_RUNNING ||
+			d.evictions[taskID] == t.Task().GetTaskId().GetValue() {
+			result = append(result, t)
+			continue
+		}
+
+		if budget == 0 {
+			log.WithFields(log.Fields{
+				"job_id":  jobID,
+				"task_id": taskID,
+			}).Debug("Deferring eviction of task to honor job SLA")
+			d.metrics.HostDrainTasksDeferred.Inc(1)
+			continue
+		}
+		budgets[jobID] = budget - 1
+		result = append(result, t)
+	}
+	return result
+}
+
+// recordEvictions remembers the running tasks which have been handed over
+// to the preemption queue.
+func (d *Drainer) recordEvictions(tasks []*rmtask.RMTask) {
+	for _, t := range tasks {
+		if t.GetCurrentState().State != task.TaskState_RUNNING ||
+			t.Task().GetMaximumUnavailableInstances() == 0 {
+			continue
+		}
+		d.evictions[t.Task().GetId().GetValue()] =
+			t.Task().GetTaskId().GetValue()
+	}
+}
+
 func (d *Drainer) markHostsDrained(hosts []string) error {
 	err := backoff.Retry(
 		func() error {
@@ -21,6 +21,7 @@ import (
 
 	"github.com/uber/peloton/.gen/mesos/v1"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc"
 	host_mocks "github.com/uber/peloton/.gen/peloton/private/hostmgr/hostsvc/mocks"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
@@ -78,10 +79,12 @@ func (suite *DrainerTestSuite) SetupTest() {
 	suite.drainer = Drainer{
 		drainerPeriod:   drainerPeriod,
 		hostMgrClient:   suite.mockHostmgr,
+		metrics:         NewMetrics(tally.NoopScope),
 		preemptionQueue: suite.preemptor,
 		rmTracker:       suite.tracker,
 		lifecycle:       lifecycle.NewLifeCycle(),
 		drainingHosts:   stringset.New(),
+		evictions:       make(map[string]string),
 	}
 
 	jobID := uuid.New()
@@ -225,3 +228,133 @@ func (suite *DrainerTestSuite) TestDrainCycle_MarkHostsDrained() {
 	err := suite.drainer.performDrainCycle()
 	suite.NoError(err)
 }
+
+// addRunningTasks adds instances of a job with the given SLA to the tracker
+// on the given host and moves them to RUNNING.
+func (suite *DrainerTestSuite) addRunningTasks(
+	jobID string,
+	host string,
+	instances int,
+	maxUnavailable uint32) {
+	for i := 0; i < instances; i++ {
+		taskID := &peloton.TaskID{Value: fmt.Sprintf("%s-%d", jobID, i)}
+		mesosTaskID := fmt.Sprintf("%s-%d-1", jobID, i)
+		suite.addTaskToTracker(&resmgr.Task{
+			Name:                        taskID.GetValue(),
+			JobId:                       &peloton.JobID{Value: jobID},
+			Id:                          taskID,
+			TaskId:                      &mesos_v1.TaskID{Value: &mesosTaskID},
+			Hostname:                    host,
+			MaximumUnavailableInstances: maxUnavailable,
+		})
+		suite.NoError(suite.tracker.GetTask(taskID).
+			TransitTo(task.TaskState_RUNNING.String()))
+	}
+}
+
+func (suite *DrainerTestSuite) TestDrainCycle_HonorsMaximumUnavailableInstances() {
+	suite.tracker.Clear()
+	jobID := uuid.New()
+	suite.addRunningTasks(jobID, hostname, 3, 1)
+
+	var enqueued []*rm_task.RMTask
+	suite.preemptor.EXPECT().
+		EnqueueTasks(gomock.Any(), gomock.Any()).
+		Do(func(tasks []*rm_task.RMTask, _ resmgr.PreemptionReason) {
+			enqueued = tasks
+		}).
+		Return(nil).Times(2)
+	suite.mockHostmgr.EXPECT().
+		GetDrainingHosts(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetDrainingHostsResponse{
+			Hostnames: []string{hostname},
+		}, nil).Times(2)
+
+	// Only one instance can be unavailable at a time
+	suite.NoError(suite.drainer.performDrainCycle())
+	suite.Len(enqueued, 1)
+	evicted := enqueued[0].Task().GetId().GetValue()
+	suite.Len(suite.drainer.evictions, 1)
+
+	// The evicted instance is still running, so no other instance
+	// should be evicted in the next cycle
+	suite.NoError(suite.drainer.performDrainCycle())
+	suite.Len(enqueued, 1)
+	suite.Equal(evicted, enqueued[0].Task().GetId().GetValue())
+	suite.Equal(uint32(1), suite.drainer.getUnavailableInstances(jobID))
+
+	// The evicted run is killed and removed from the tracker
+	suite.tracker.DeleteTask(&peloton.TaskID{Value: evicted})
+	suite.Equal(uint32(1), suite.drainer.getUnavailableInstances(jobID))
+
+	// The replacement is enqueued with a new run, and is pending
+	mesosTaskID := evicted + "-2"
+	suite.addTaskToTracker(&resmgr.Task{
+		Name:                        evicted,
+		JobId:                       &peloton.JobID{Value: jobID},
+		Id:                          &peloton.TaskID{Value: evicted},
+		TaskId:                      &mesos_v1.TaskID{Value: &mesosTaskID},
+		MaximumUnavailableInstances: 1,
+	})
+	suite.Equal(uint32(1), suite.drainer.getUnavailableInstances(jobID))
+	suite.Empty(suite.drainer.evictions)
+
+	// The replacement is running, so another instance can be evicted
+	suite.NoError(suite.tracker.GetTask(&peloton.TaskID{Value: evicted}).
+		TransitTo(task.TaskState_RUNNING.String()))
+	suite.Equal(uint32(0), suite.drainer.getUnavailableInstances(jobID))
+}
+
+func (suite *DrainerTestSuite) TestDrainCycle_EnqueueErrorNotEvicted() {
+	suite.tracker.Clear()
+	jobID := uuid.New()
+	suite.addRunningTasks(jobID, hostname, 3, 1)
+
+	var enqueued []*rm_task.RMTask
+	gomock.InOrder(
+		suite.preemptor.EXPECT().
+			EnqueueTasks(gomock.Any(), gomock.Any()).
+			Return(fmt.Errorf("fake Enqueue error")),
+		suite.preemptor.EXPECT().
+			EnqueueTasks(gomock.Any(), gomock.Any()).
+			Do(func(tasks []*rm_task.RMTask, _ resmgr.PreemptionReason) {
+				enqueued = tasks
+			}).
+			Return(nil),
+	)
+	suite.mockHostmgr.EXPECT().
+		GetDrainingHosts(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetDrainingHostsResponse{
+			Hostnames: []string{hostname},
+		}, nil).Times(2)
+
+	// The failed enqueue should not count against the job SLA
+	suite.Error(suite.drainer.performDrainCycle())
+	suite.Empty(suite.drainer.evictions)
+	suite.Equal(uint32(0), suite.drainer.getUnavailableInstances(jobID))
+
+	// The next cycle should retry the eviction
+	suite.NoError(suite.drainer.performDrainCycle())
+	suite.Len(enqueued, 1)
+	suite.Len(suite.drainer.evictions, 1)
+}
+
+func (suite *DrainerTestSuite) TestDrainCycle_NoSLALimit() {
+	suite.tracker.Clear()
+	suite.addRunningTasks(uuid.New(), hostname, 3, 0)
+
+	suite.preemptor.EXPECT().
+		EnqueueTasks(gomock.Any(), gomock.Any()).
+		Do(func(tasks []*rm_task.RMTask, _ resmgr.PreemptionReason) {
+			suite.Len(tasks, 3)
+		}).
+		Return(nil)
+	suite.mockHostmgr.EXPECT().
+		GetDrainingHosts(gomock.Any(), gomock.Any()).
+		Return(&hostsvc.GetDrainingHostsResponse{
+			Hostnames: []string{hostname},
+		}, nil)
+
+	suite.NoError(suite.drainer.performDrainCycle())
+	suite.Empty(suite.drainer.evictions)
+}
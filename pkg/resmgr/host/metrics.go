@@ -20,6 +20,9 @@ import "github.com/uber-go/tally"
 type Metrics struct {
 	HostDrainSuccess tally.Counter
 	HostDrainFail    tally.Counter
+
+	// Number of running task evictions deferred to honor job SLA
+	HostDrainTasksDeferred tally.Counter
 }
 
 // NewMetrics returns a new instance of host.Metrics.
@@ -29,5 +32,7 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	return &Metrics{
 		HostDrainSuccess: hostSuccessScope.Counter("host_drain"),
 		HostDrainFail:    hostFailScope.Counter("host_drain"),
+
+		HostDrainTasksDeferred: scope.Counter("host_drain_tasks_deferred"),
 	}
 }
@@ -0,0 +1,174 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preemption
+
+import (
+	"sort"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+	rm_task "github.com/uber/peloton/pkg/resmgr/task"
+)
+
+// costAwareRanker sorts the tasks to minimize the work which is lost by
+// evicting them. The wasted work of a running task is how long it has been
+// running multiplied by its CPU limit, so recently started and small tasks
+// are evicted first. It sorts the tasks in the following order
+// * Task State : READY > PLACING > RUNNING
+// * If task state is the same it sorts on the task Priority
+// * If the priority is the same it sorts on the wasted work of the task
+//
+// Running tasks of the same priority are then spread across jobs so that a
+// second instance of a job is only evicted after one instance of every other
+// job of that priority, and tasks of jobs which already have instances being
+// preempted are evicted last.
+type costAwareRanker struct {
+	tracker rm_task.Tracker
+	// now returns the current time, overridden in tests
+	now func() time.Time
+}
+
+// newCostAwareRanker returns a new instance of the costAwareRanker
+func newCostAwareRanker(tracker rm_task.Tracker) ranker {
+	return &costAwareRanker{
+		tracker: tracker,
+		now:     time.Now,
+	}
+}
+
+// GetTasksToEvict returns the tasks in the order in which they should be
+// evicted from the resource pool such that the cumulative resources of
+// those tasks >= requiredResources
+func (r *costAwareRanker) GetTasksToEvict(
+	respoolID string,
+	slackResourcesToFree, nonSlackResourcesToFree *scalar.Resources) []*rm_task.RMTask {
+
+	// get all active tasks for this resource pool
+	stateTaskMap := r.tracker.GetActiveTasks("", respoolID, nil)
+
+	// instances of a job selected for eviction, starting with the tasks
+	// which are already being preempted
+	evicted := make(map[string]int)
+	for _, t := range stateTaskMap[task.TaskState_PREEMPTING.String()] {
+		evicted[t.Task().GetJobId().GetValue()]++
+	}
+
+	revocableTasksToEvict := r.selectTasks(
+		slackResourcesToFree, stateTaskMap, filterRevocableTasks, evicted)
+	nonRevocTasksToEvict := r.selectTasks(
+		nonSlackResourcesToFree, stateTaskMap, filterNonRevocableTasks, evicted)
+	return append(revocableTasksToEvict, nonRevocTasksToEvict...)
+}
+
+// selectTasks returns the tasks selected by the filter to evict in ranked
+// order, until their resources satisfy resourcesLimit. Instances of a job
+// selected at a lower priority count towards the evictions of the job at a
+// higher priority, the selected instances are added to evicted.
+func (r *costAwareRanker) selectTasks(
+	resourcesLimit *scalar.Resources,
+	stateTaskMap map[string][]*rm_task.RMTask,
+	filter func([]*rm_task.RMTask) []*rm_task.RMTask,
+	evicted map[string]int) []*rm_task.RMTask {
+
+	sorter := taskSorter{
+		cmpFuncs: []cmpFunc{
+			priorityCmp,
+			r.wastedWorkCmp,
+		},
+	}
+	selector := newTaskSelector(resourcesLimit)
+
+	var tasksToEvict []*rm_task.RMTask
+	for _, taskState := range taskStatesPreemptionOrder {
+		tasksInState := filter(stateTaskMap[taskState.String()])
+		sorter.Sort(tasksInState)
+		// tasks which are not running are not doing any work yet
+		if taskState != task.TaskState_RUNNING {
+			tasksToEvict = append(
+				tasksToEvict, selector.selectTasks(tasksInState)...)
+			continue
+		}
+		for _, band := range priorityBands(tasksInState) {
+			spreadAcrossJobs(band, evicted)
+			selected := selector.selectTasks(band)
+			for _, t := range selected {
+				evicted[t.Task().GetJobId().GetValue()]++
+			}
+			tasksToEvict = append(tasksToEvict, selected...)
+		}
+	}
+	return tasksToEvict
+}
+
+// priorityBands splits the tasks sorted by priority into the consecutive
+// runs of tasks with the same priority
+func priorityBands(tasks []*rm_task.RMTask) [][]*rm_task.RMTask {
+	var bands [][]*rm_task.RMTask
+	start := 0
+	for i := 1; i <= len(tasks); i++ {
+		if i == len(tasks) ||
+			tasks[i].Task().GetPriority() != tasks[start].Task().GetPriority() {
+			bands = append(bands, tasks[start:i])
+			start = i
+		}
+	}
+	return bands
+}
+
+// wastedWork returns the work which is lost if the task is evicted
+func (r *costAwareRanker) wastedWork(t *rm_task.RMTask) float64 {
+	if t.GetCurrentState().State != task.TaskState_RUNNING {
+		return 0
+	}
+	runTime := r.now().Sub(t.RunTimeStats().StartTime)
+	if runTime < 0 {
+		return 0
+	}
+	return runTime.Seconds() * t.Task().GetResource().GetCpuLimit()
+}
+
+// wastedWorkCmp compares tasks based on the work lost by evicting them
+func (r *costAwareRanker) wastedWorkCmp(t1, t2 *rm_task.RMTask) int {
+	w1, w2 := r.wastedWork(t1), r.wastedWork(t2)
+	switch {
+	case w1 < w2:
+		return -1
+	case w1 > w2:
+		return 1
+	}
+	return 0
+}
+
+// spreadAcrossJobs reorders the ranked running tasks of one priority in
+// place so that the n-th instance of a job is evicted after the (n-1)-th
+// instance of all other jobs, given the instances of the jobs evicted
+// before the tasks.
+func spreadAcrossJobs(
+	tasks []*rm_task.RMTask,
+	evicted map[string]int) {
+	next := make(map[string]int)
+	rounds := make(map[*rm_task.RMTask]int, len(tasks))
+	for _, t := range tasks {
+		jobID := t.Task().GetJobId().GetValue()
+		rounds[t] = evicted[jobID] + next[jobID]
+		next[jobID]++
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return rounds[tasks[i]] < rounds[tasks[j]]
+	})
+}
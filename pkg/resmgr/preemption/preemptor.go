@@ -97,7 +97,12 @@ func NewPreemptor(
 	cfg *common.PreemptionConfig,
 	tracker task.Tracker,
 	resTree respool.Tree,
-) *Preemptor {
+) (*Preemptor, error) {
+
+	r, err := newRanker(cfg.Ranker, tracker)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Preemptor{
 		lifeCycle:                    lifecycle.NewLifeCycle(),
@@ -112,11 +117,11 @@ func NewPreemptor(
 			reflect.TypeOf(resmgr.PreemptionCandidate{}),
 			maxPreemptionQueueSize,
 		),
-		ranker:  newStatePriorityRuntimeRanker(tracker),
+		ranker:  r,
 		tracker: tracker,
 		scope:   parent.SubScope("preemption"),
 		m:       make(map[string]*Metrics),
-	}
+	}, nil
 }
 
 // returns per resource pool tagged metrics
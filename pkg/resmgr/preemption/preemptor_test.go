@@ -838,7 +838,7 @@ func (suite *PreemptorTestSuite) TestPreemptorEnqueue() {
 }
 
 func (suite *PreemptorTestSuite) TestNewPreemptor() {
-	p := NewPreemptor(tally.NoopScope, &res_common.PreemptionConfig{
+	p, err := NewPreemptor(tally.NoopScope, &res_common.PreemptionConfig{
 		Enabled:                      true,
 		TaskPreemptionPeriod:         100 * time.Hour,
 		SustainedOverAllocationCount: 100,
@@ -846,7 +846,26 @@ func (suite *PreemptorTestSuite) TestNewPreemptor() {
 		suite.tracker,
 		suite.getResourceTree(),
 	)
+	suite.NoError(err)
 	suite.NotNil(p)
+	suite.IsType(&statePriorityRuntimeRanker{}, p.ranker)
+
+	p, err = NewPreemptor(tally.NoopScope, &res_common.PreemptionConfig{
+		Ranker: CostAwareRanker,
+	},
+		suite.tracker,
+		suite.getResourceTree(),
+	)
+	suite.NoError(err)
+	suite.IsType(&costAwareRanker{}, p.ranker)
+
+	_, err = NewPreemptor(tally.NoopScope, &res_common.PreemptionConfig{
+		Ranker: "unknown",
+	},
+		suite.tracker,
+		suite.getResourceTree(),
+	)
+	suite.Error(err)
 }
 
 func (suite *PreemptorTestSuite) TestPreemptionQueueDuplicateTasks() {
@@ -22,6 +22,7 @@ import (
 	"github.com/uber/peloton/pkg/resmgr/scalar"
 	rm_task "github.com/uber/peloton/pkg/resmgr/task"
 
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -44,6 +45,34 @@ type ranker interface {
 		nonSlackResourcesToFree *scalar.Resources) []*rm_task.RMTask
 }
 
+const (
+	// StatePriorityRuntimeRanker is the name of the default ranker which
+	// sorts tasks by state, priority and start time
+	StatePriorityRuntimeRanker = "state_priority_runtime"
+	// CostAwareRanker is the name of the ranker which minimizes the work
+	// lost by evicting tasks
+	CostAwareRanker = "cost_aware"
+)
+
+// rankers maps the ranker names to their constructors
+var rankers = map[string]func(tracker rm_task.Tracker) ranker{
+	StatePriorityRuntimeRanker: newStatePriorityRuntimeRanker,
+	CostAwareRanker:            newCostAwareRanker,
+}
+
+// newRanker returns the ranker with the given name, the default ranker is
+// returned if the name is empty
+func newRanker(name string, tracker rm_task.Tracker) (ranker, error) {
+	if name == "" {
+		name = StatePriorityRuntimeRanker
+	}
+	newFunc, ok := rankers[name]
+	if !ok {
+		return nil, errors.Errorf("unknown preemption ranker %q", name)
+	}
+	return newFunc(tracker), nil
+}
+
 // statePriorityRuntimeRanker sorts the tasks in the following order
 // * Task State : READY > PLACING > RUNNING
 // * If task state is the same it sorts on the task Priority
@@ -151,12 +180,33 @@ func filterNonRevocableTasks(
 // This method assumes the list of tasks supplied is already sorted in the preferred order
 func filterTasks(
 	resourcesLimit *scalar.Resources,
+	allTasks []*rm_task.RMTask) []*rm_task.RMTask {
+	return newTaskSelector(resourcesLimit).selectTasks(allTasks)
+}
+
+// taskSelector selects tasks to evict until their cumulative resources
+// satisfy the resourcesLimit
+type taskSelector struct {
+	resourcesLimit       *scalar.Resources
+	resourceRunningCount *scalar.Resources
+}
+
+func newTaskSelector(resourcesLimit *scalar.Resources) *taskSelector {
+	return &taskSelector{
+		resourcesLimit:       resourcesLimit,
+		resourceRunningCount: scalar.ZeroResource,
+	}
+}
+
+// selectTasks returns the tasks selected for eviction, in order, from the
+// tasks sorted in the preferred order. It can be called several times to
+// select from consecutive lists of tasks.
+func (s *taskSelector) selectTasks(
 	allTasks []*rm_task.RMTask) []*rm_task.RMTask {
 	var tasksToEvict []*rm_task.RMTask
-	resourceRunningCount := scalar.ZeroResource
 	for _, task := range allTasks {
 		// Check how many resource we need to free
-		resourceToFree := resourcesLimit.Subtract(resourceRunningCount)
+		resourceToFree := s.resourcesLimit.Subtract(s.resourceRunningCount)
 		if resourceToFree.Equal(scalar.ZeroResource) {
 			// we have enough tasks
 			break
@@ -165,15 +215,15 @@ func filterTasks(
 		taskResources := scalar.ConvertToResmgrResource(task.Task().Resource)
 
 		// check if the task resource helps in satisfying resourceToFree
-		newResourceToFree := resourcesLimit.Subtract(taskResources)
-		if newResourceToFree.Equal(resourcesLimit) {
+		newResourceToFree := s.resourcesLimit.Subtract(taskResources)
+		if newResourceToFree.Equal(s.resourcesLimit) {
 			// this task doesn't help with meeting the resourcesLimit
 			continue
 		}
 		// we can add more tasks
 		tasksToEvict = append(tasksToEvict, task)
 		// Add the task resource to the running count
-		resourceRunningCount = resourceRunningCount.Add(taskResources)
+		s.resourceRunningCount = s.resourceRunningCount.Add(taskResources)
 	}
 	return tasksToEvict
 }
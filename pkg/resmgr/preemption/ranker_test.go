@@ -455,3 +455,196 @@ func (suite *RankerTestSuite) TestStatePriorityRuntimeRanker_FilterNonPreemptibl
 		}
 	}
 }
+
+// addRunningTask adds a running task of a job which started runTime ago
+func (suite *RankerTestSuite) addRunningTask(
+	tid string,
+	jid string,
+	cpu float64,
+	runTime time.Duration) {
+	suite.addRunningTaskWithPriority(tid, jid, 0, cpu, runTime)
+}
+
+// addRunningTaskWithPriority adds a running task of a job with a priority
+// which started runTime ago
+func (suite *RankerTestSuite) addRunningTaskWithPriority(
+	tid string,
+	jid string,
+	priority uint32,
+	cpu float64,
+	runTime time.Duration) {
+	suite.addTaskToTracker(&resmgr.Task{
+		Name:     tid,
+		Priority: priority,
+		JobId:    &peloton.JobID{Value: jid},
+		Id:       &peloton.TaskID{Value: tid},
+		Hostname: "hostname",
+		Resource: &task.ResourceConfig{
+			CpuLimit:    cpu,
+			DiskLimitMb: 9,
+			GpuLimit:    0,
+			MemLimitMb:  100,
+		},
+		Preemptible: true,
+	})
+	taskID := &peloton.TaskID{Value: tid}
+	suite.transitToRunning(taskID)
+	suite.tracker.GetTask(taskID).UpdateStartTime(time.Now().Add(-runTime))
+}
+
+// evictedTaskIDs returns the IDs of the tasks to evict
+func evictedTaskIDs(tasks []*rm_task.RMTask) []string {
+	var ids []string
+	for _, t := range tasks {
+		ids = append(ids, t.Task().GetId().GetValue())
+	}
+	return ids
+}
+
+func (suite *RankerTestSuite) TestCostAwareRanker_GetTasksToEvict() {
+	suite.addTasks()
+
+	// READY and PLACING tasks are evicted first, running tasks of the same
+	// priority are evicted in the order of their start times
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		&scalar.Resources{
+			CPU:    10,
+			MEMORY: 1000,
+			GPU:    0,
+			DISK:   100,
+		})
+	suite.Equal([]string{
+		"job1-0", "job1-1", "job1-2",
+		"job1-10", "job1-11", "job1-12",
+		"job1-3", "job1-4", "job1-5",
+		"job1-8", "job1-7", "job1-6",
+	}, evictedTaskIDs(tasksToEvict))
+}
+
+func (suite *RankerTestSuite) TestCostAwareRanker_WastedWork() {
+	// task which ran for a long time on a small CPU is cheaper to evict
+	// than a task which ran for a shorter time on many CPUs
+	suite.addRunningTask("j1-t1", "j1", 0.1, time.Hour)
+	suite.addRunningTask("j2-t1", "j2", 10, 10*time.Minute)
+	suite.addRunningTask("j3-t1", "j3", 1, time.Minute)
+
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		&scalar.Resources{
+			CPU:    20,
+			MEMORY: 1000,
+			GPU:    0,
+			DISK:   100,
+		})
+	suite.Equal(
+		[]string{"j3-t1", "j1-t1", "j2-t1"},
+		evictedTaskIDs(tasksToEvict))
+}
+
+func (suite *RankerTestSuite) TestCostAwareRanker_SpreadAcrossJobs() {
+	suite.addRunningTask("j1-t1", "j1", 1, 10*time.Second)
+	suite.addRunningTask("j1-t2", "j1", 1, 20*time.Second)
+	suite.addRunningTask("j1-t3", "j1", 1, 30*time.Second)
+	suite.addRunningTask("j2-t1", "j2", 1, time.Minute)
+	suite.addRunningTask("j3-t1", "j3", 1, 5*time.Second)
+	suite.addRunningTask("j3-t2", "j3", 1, time.Hour)
+
+	// an instance of j3 is already being preempted
+	suite.addRunningTask("j3-t3", "j3", 1, time.Hour)
+	tasktestutil.ValidateStateTransitions(
+		suite.tracker.GetTask(&peloton.TaskID{Value: "j3-t3"}),
+		[]task.TaskState{task.TaskState_PREEMPTING})
+
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		&scalar.Resources{
+			CPU:    6,
+			MEMORY: 1000,
+			GPU:    0,
+			DISK:   100,
+		})
+	suite.Equal(
+		[]string{"j1-t1", "j2-t1", "j3-t1", "j1-t2", "j1-t3", "j3-t2"},
+		evictedTaskIDs(tasksToEvict))
+
+	// only the first instances are evicted if they free enough resources
+	tasksToEvict = ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		&scalar.Resources{
+			CPU:    2,
+			MEMORY: 200,
+			GPU:    0,
+			DISK:   18,
+		})
+	suite.Equal([]string{"j1-t1", "j2-t1"}, evictedTaskIDs(tasksToEvict))
+}
+
+func (suite *RankerTestSuite) TestCostAwareRanker_SpreadWithinPriority() {
+	suite.addRunningTaskWithPriority("j1-t1", "j1", 0, 1, 10*time.Second)
+	suite.addRunningTaskWithPriority("j1-t2", "j1", 0, 1, 20*time.Second)
+	suite.addRunningTaskWithPriority("j2-t1", "j2", 1, 1, time.Second)
+	suite.addRunningTaskWithPriority("j2-t2", "j2", 1, 1, 2*time.Second)
+	suite.addRunningTaskWithPriority("j3-t1", "j3", 1, 1, time.Minute)
+
+	// all low priority instances are evicted before the high priority
+	// ones, which are spread across the high priority jobs
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		&scalar.Resources{
+			CPU:    5,
+			MEMORY: 1000,
+			GPU:    0,
+			DISK:   100,
+		})
+	suite.Equal(
+		[]string{"j1-t1", "j1-t2", "j2-t1", "j3-t1", "j2-t2"},
+		evictedTaskIDs(tasksToEvict))
+}
+
+func (suite *RankerTestSuite) TestCostAwareRanker_CountSelectedTasks() {
+	// j1 has a low priority instance which does not free any of the
+	// required resources, so it is not selected for eviction
+	suite.addRunningTaskWithPriority("j1-t1", "j1", 0, 0, time.Second)
+	suite.addRunningTaskWithPriority("j1-t2", "j1", 1, 1, time.Second)
+	suite.addRunningTaskWithPriority("j2-t1", "j2", 1, 1, time.Minute)
+	suite.addRunningTaskWithPriority("j3-t1", "j3", 1, 1, time.Hour)
+	suite.addRunningTaskWithPriority("j3-t2", "j3", 1, 1, 2*time.Hour)
+
+	// there are more candidates than needed to free the resources, and
+	// only the instances selected for eviction count towards the
+	// evictions of their job
+	ranker := newCostAwareRanker(suite.tracker)
+	tasksToEvict := ranker.GetTasksToEvict(
+		"respool-1",
+		scalar.ZeroResource,
+		&scalar.Resources{
+			CPU:    2,
+			MEMORY: 0,
+			GPU:    0,
+			DISK:   0,
+		})
+	suite.Equal([]string{"j1-t2", "j2-t1"}, evictedTaskIDs(tasksToEvict))
+}
+
+func (suite *RankerTestSuite) TestNewRanker() {
+	r, err := newRanker("", suite.tracker)
+	suite.NoError(err)
+	suite.IsType(&statePriorityRuntimeRanker{}, r)
+
+	r, err = newRanker(CostAwareRanker, suite.tracker)
+	suite.NoError(err)
+	suite.IsType(&costAwareRanker{}, r)
+
+	_, err = newRanker("unknown", suite.tracker)
+	suite.Error(err)
+}
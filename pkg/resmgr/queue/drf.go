@@ -0,0 +1,353 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Shares holds the allocation of a resource pool, from which the DRFQueues
+// of the pool compute the dominant resource shares of its owners and jobs.
+// The resource pool sets it whenever its allocation changes.
+type Shares struct {
+	sync.RWMutex
+
+	allocation *scalar.Allocation
+}
+
+// NewShares returns the shares of a resource pool without allocation
+func NewShares() *Shares {
+	return &Shares{allocation: scalar.NewAllocation()}
+}
+
+// Set sets the allocation of the resource pool. The allocation must not be
+// modified afterwards.
+func (s *Shares) Set(allocation *scalar.Allocation) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.allocation = allocation
+}
+
+// get returns the allocation of the resource pool
+func (s *Shares) get() *scalar.Allocation {
+	s.RLock()
+	defer s.RUnlock()
+
+	return s.allocation
+}
+
+// DRFQueue is a queue which removes gangs in the order of their priority,
+// and among gangs of the same priority it picks the gang of the owner, or
+// of the job if it has no owner, with the lowest dominant resource share.
+// The share is computed from the resources allocated to the owner or job
+// in the resource pool, so a large job which was enqueued first does not
+// starve the other jobs of the same priority. Gangs of an owner or job are
+// removed in FIFO order.
+type DRFQueue struct {
+	sync.RWMutex
+
+	// limit is the maximum number of gangs in the queue
+	limit int64
+	// size is the number of gangs in the queue
+	size int64
+	// sequence is incremented for every enqueued gang and used to break
+	// ties between owners and jobs with the same share in FIFO order
+	sequence uint64
+
+	// levels maps a priority to the gangs, by share key, of that priority
+	levels map[int]map[string]*list.List
+	// shares is the allocation of the resource pool of the queue
+	shares *Shares
+}
+
+// drfItem is a gang in the DRFQueue
+type drfItem struct {
+	gang     *resmgrsvc.Gang
+	sequence uint64
+}
+
+// NewDRFQueue initializes the DRF queue and returns the pointer
+func NewDRFQueue(limit int64, shares *Shares) *DRFQueue {
+	return &DRFQueue{
+		limit:  limit,
+		levels: make(map[int]map[string]*list.List),
+		shares: shares,
+	}
+}
+
+// gangShareKey returns the owner, or the job, of a gang. Tasks of a gang
+// belong to one job.
+func gangShareKey(gang *resmgrsvc.Gang) string {
+	return scalar.ShareKey(gang.GetTasks()[0])
+}
+
+// gangPriority returns the priority of a gang
+func gangPriority(gang *resmgrsvc.Gang) int {
+	return int(gang.GetTasks()[0].GetPriority())
+}
+
+// Enqueue queues a gang (task list gang) based on its priority and its
+// owner or job
+func (q *DRFQueue) Enqueue(gang *resmgrsvc.Gang) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if (gang == nil) || (len(gang.Tasks) == 0) {
+		return errors.New("enqueue of empty list")
+	}
+	if q.size >= q.limit {
+		return fmt.Errorf("list size limit reached")
+	}
+
+	priority := gangPriority(gang)
+	key := gangShareKey(gang)
+
+	keys, ok := q.levels[priority]
+	if !ok {
+		keys = make(map[string]*list.List)
+		q.levels[priority] = keys
+	}
+	gangs, ok := keys[key]
+	if !ok {
+		gangs = list.New()
+		keys[key] = gangs
+	}
+
+	q.sequence++
+	gangs.PushBack(&drfItem{gang: gang, sequence: q.sequence})
+	q.size++
+	return nil
+}
+
+// Dequeue dequeues the gang (task list gang) with the highest priority
+// whose owner or job has the lowest dominant resource share
+func (q *DRFQueue) Dequeue() (*resmgrsvc.Gang, error) {
+	q.Lock()
+	defer q.Unlock()
+
+	allocation := q.shares.get()
+	share := func(key string) float64 {
+		return dominantShare(
+			allocation.GetByShareKey(key),
+			allocation.GetByType(scalar.TotalAllocation))
+	}
+
+	for _, priority := range q.priorities() {
+		heads := q.heads(priority)
+		key := pickKey(heads, share)
+		if key == "" {
+			continue
+		}
+		item := heads[key].Value.(*drfItem)
+		q.remove(priority, key, heads[key])
+		return item.gang, nil
+	}
+	return nil, ErrorQueueEmpty("dequeue failed, queue is empty")
+}
+
+// Peek peeks the limit number of gangs in the order they would be
+// dequeued, assuming every peeked gang is allocated. It will return an
+// `ErrorQueueEmpty` if there is no gangs in the queue.
+func (q *DRFQueue) Peek(limit uint32) ([]*resmgrsvc.Gang, error) {
+	q.RLock()
+	defer q.RUnlock()
+
+	var items []*resmgrsvc.Gang
+
+	// simulate allocating the peeked gangs on top of the allocation
+	allocation := q.shares.get()
+	usage := make(map[string]*scalar.Resources)
+	total := allocation.GetByType(scalar.TotalAllocation)
+	share := func(key string) float64 {
+		if res, ok := usage[key]; ok {
+			return dominantShare(res, total)
+		}
+		return dominantShare(allocation.GetByShareKey(key), total)
+	}
+
+	for _, priority := range q.priorities() {
+		heads := q.heads(priority)
+		for uint32(len(items)) < limit {
+			key := pickKey(heads, share)
+			if key == "" {
+				break
+			}
+			item := heads[key].Value.(*drfItem)
+			items = append(items, item.gang)
+			heads[key] = heads[key].Next()
+
+			res := scalar.GetGangResources(item.gang)
+			if _, ok := usage[key]; !ok {
+				usage[key] = allocation.GetByShareKey(key)
+			}
+			usage[key] = usage[key].Add(res)
+			total = total.Add(res)
+		}
+	}
+
+	if len(items) == 0 {
+		return items, ErrorQueueEmpty("peek failed, queue is empty")
+	}
+	return items, nil
+}
+
+// Remove removes the item from the queue
+func (q *DRFQueue) Remove(gang *resmgrsvc.Gang) error {
+	q.Lock()
+	defer q.Unlock()
+
+	if gang == nil || len(gang.Tasks) <= 0 {
+		return errors.New("removal of empty list")
+	}
+
+	priority := gangPriority(gang)
+	key := gangShareKey(gang)
+	log.WithFields(log.Fields{
+		"share_key": key,
+		"priority":  priority,
+	}).Debug("Trying to remove")
+
+	if gangs, ok := q.levels[priority][key]; ok {
+		for e := gangs.Front(); e != nil; e = e.Next() {
+			if e.Value.(*drfItem).gang == gang {
+				q.remove(priority, key, e)
+				return nil
+			}
+		}
+	}
+	return ErrorQueueEmpty(fmt.Sprintf("No items found in queue %s", gang))
+}
+
+// Len returns the length of the queue for specified priority
+func (q *DRFQueue) Len(priority int) int {
+	q.RLock()
+	defer q.RUnlock()
+
+	count := 0
+	for _, gangs := range q.levels[priority] {
+		count += gangs.Len()
+	}
+	return count
+}
+
+// Size returns the number of elements in the DRFQueue
+func (q *DRFQueue) Size() int {
+	q.RLock()
+	defer q.RUnlock()
+
+	return int(q.size)
+}
+
+// Share returns the dominant resource share of an owner or job
+func (q *DRFQueue) Share(key string) float64 {
+	allocation := q.shares.get()
+	return dominantShare(
+		allocation.GetByShareKey(key),
+		allocation.GetByType(scalar.TotalAllocation))
+}
+
+// priorities returns the priorities with gangs in descending order
+func (q *DRFQueue) priorities() []int {
+	priorities := make([]int, 0, len(q.levels))
+	for priority := range q.levels {
+		priorities = append(priorities, priority)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+	return priorities
+}
+
+// heads returns the first gang of every owner or job of a priority
+func (q *DRFQueue) heads(priority int) map[string]*list.Element {
+	keys := q.levels[priority]
+	heads := make(map[string]*list.Element, len(keys))
+	for key, gangs := range keys {
+		heads[key] = gangs.Front()
+	}
+	return heads
+}
+
+// remove removes a gang from the queue
+func (q *DRFQueue) remove(priority int, key string, e *list.Element) {
+	gangs := q.levels[priority][key]
+	gangs.Remove(e)
+	if gangs.Len() == 0 {
+		delete(q.levels[priority], key)
+		if len(q.levels[priority]) == 0 {
+			delete(q.levels, priority)
+		}
+	}
+	q.size--
+}
+
+// pickKey returns the owner or job with the lowest dominant resource share
+// among those which have a gang at the given head elements. Ties are broken
+// by picking the gang which was enqueued first.
+func pickKey(
+	heads map[string]*list.Element,
+	share func(key string) float64,
+) string {
+	var picked string
+	var pickedShare float64
+	var pickedSequence uint64
+
+	for key, e := range heads {
+		if e == nil {
+			continue
+		}
+		keyShare := share(key)
+		sequence := e.Value.(*drfItem).sequence
+		if picked == "" ||
+			keyShare < pickedShare ||
+			keyShare == pickedShare && sequence < pickedSequence {
+			picked = key
+			pickedShare = keyShare
+			pickedSequence = sequence
+		}
+	}
+	return picked
+}
+
+// dominantShare returns the largest share of the usage of any resource
+// kind in the total
+func dominantShare(usage, total *scalar.Resources) float64 {
+	if usage == nil || total == nil {
+		return 0
+	}
+
+	var share float64
+	for _, shares := range [][2]float64{
+		{usage.GetCPU(), total.GetCPU()},
+		{usage.GetMem(), total.GetMem()},
+		{usage.GetDisk(), total.GetDisk()},
+		{usage.GetGPU(), total.GetGPU()},
+	} {
+		if shares[1] > 0 {
+			share = math.Max(share, shares[0]/shares[1])
+		}
+	}
+	return share
+}
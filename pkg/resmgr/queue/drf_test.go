@@ -0,0 +1,230 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/task"
+	"github.com/uber/peloton/.gen/peloton/private/resmgr"
+	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type DRFQueueTestSuite struct {
+	suite.Suite
+	shares *Shares
+	q      *DRFQueue
+}
+
+func (suite *DRFQueueTestSuite) SetupTest() {
+	suite.shares = NewShares()
+	suite.q = NewDRFQueue(math.MaxInt64, suite.shares)
+}
+
+func TestDRFQueue(t *testing.T) {
+	suite.Run(t, new(DRFQueueTestSuite))
+}
+
+// createGang creates a single task gang of a job with the given priority
+// and resources
+func createGang(
+	jobID string,
+	instance int,
+	priority uint32,
+	cpu float64,
+	mem float64,
+) *resmgrsvc.Gang {
+	t := CreateResmgrTask(
+		&peloton.JobID{Value: jobID},
+		&peloton.TaskID{Value: fmt.Sprintf("%s-%d", jobID, instance)},
+		priority)
+	t.Resource = &task.ResourceConfig{CpuLimit: cpu, MemLimitMb: mem}
+	return &resmgrsvc.Gang{Tasks: []*resmgr.Task{t}}
+}
+
+// createOwnedGang creates a single task gang of a job of an owner
+func createOwnedGang(owner string, jobID string, instance int) *resmgrsvc.Gang {
+	gang := createGang(jobID, instance, 1, 1, 100)
+	gang.GetTasks()[0].Owner = owner
+	return gang
+}
+
+// allocate adds the resources of the gang to the allocation of the pool,
+// as the resource pool does when it admits the gang
+func (suite *DRFQueueTestSuite) allocate(gang *resmgrsvc.Gang) {
+	suite.shares.Set(
+		suite.shares.get().Add(scalar.GetGangAllocation(gang)))
+}
+
+// dequeueJobs dequeues and allocates count gangs and returns their jobs
+func (suite *DRFQueueTestSuite) dequeueJobs(count int) []string {
+	var jobs []string
+	for i := 0; i < count; i++ {
+		gang, err := suite.q.Dequeue()
+		suite.NoError(err)
+		suite.allocate(gang)
+		jobs = append(jobs, gang.GetTasks()[0].GetJobId().GetValue())
+	}
+	return jobs
+}
+
+// TestLargeJobDoesNotStarveOthers tests that gangs of a job enqueued later
+// are interleaved with the gangs of a large job of the same priority
+func (suite *DRFQueueTestSuite) TestLargeJobDoesNotStarveOthers() {
+	for i := 0; i < 10; i++ {
+		suite.NoError(suite.q.Enqueue(createGang("large", i, 1, 1, 100)))
+	}
+	for i := 0; i < 2; i++ {
+		suite.NoError(suite.q.Enqueue(createGang("small", i, 1, 1, 100)))
+	}
+	suite.Equal(12, suite.q.Size())
+
+	suite.Equal(
+		[]string{"large", "small", "large", "small", "large"},
+		suite.dequeueJobs(5))
+	suite.Equal(7, suite.q.Size())
+}
+
+// TestAllocatedJobWaits tests that the share of a job is computed from its
+// allocation, also if it has no other gangs waiting in the queue
+func (suite *DRFQueueTestSuite) TestAllocatedJobWaits() {
+	suite.allocate(createGang("job1", 0, 1, 4, 100))
+
+	suite.NoError(suite.q.Enqueue(createGang("job1", 1, 1, 1, 100)))
+	suite.NoError(suite.q.Enqueue(createGang("job2", 0, 1, 1, 100)))
+
+	suite.Equal(1.0, suite.q.Share("job1"))
+	suite.Equal([]string{"job2", "job1"}, suite.dequeueJobs(2))
+}
+
+// TestOwnerShares tests that the jobs of an owner share the allocation of
+// the owner, and that jobs without an owner have their own share
+func (suite *DRFQueueTestSuite) TestOwnerShares() {
+	suite.NoError(suite.q.Enqueue(createOwnedGang("owner1", "job1", 0)))
+	suite.NoError(suite.q.Enqueue(createOwnedGang("owner1", "job2", 0)))
+	suite.NoError(suite.q.Enqueue(createOwnedGang("", "job3", 0)))
+	suite.NoError(suite.q.Enqueue(createOwnedGang("", "job3", 1)))
+	suite.NoError(suite.q.Enqueue(createOwnedGang("owner1", "job1", 1)))
+
+	suite.Equal(
+		[]string{"job1", "job3", "job2", "job3", "job1"},
+		suite.dequeueJobs(5))
+	suite.InDelta(0.6, suite.q.Share("owner1"), 0.001)
+	suite.InDelta(0.4, suite.q.Share("job3"), 0.001)
+	suite.Equal(0.0, suite.q.Share("job1"))
+}
+
+// TestDominantResource tests that jobs are ordered by the share of their
+// dominant resource
+func (suite *DRFQueueTestSuite) TestDominantResource() {
+	// job1 is memory heavy and job2 is cpu heavy
+	for i := 0; i < 3; i++ {
+		suite.NoError(suite.q.Enqueue(createGang("job1", i, 1, 1, 400)))
+		suite.NoError(suite.q.Enqueue(createGang("job2", i, 1, 4, 100)))
+	}
+
+	suite.Equal([]string{"job1", "job2"}, suite.dequeueJobs(2))
+	// both jobs have a dominant share of 0.8
+	suite.InDelta(0.8, suite.q.Share("job1"), 0.001)
+	suite.InDelta(0.8, suite.q.Share("job2"), 0.001)
+
+	// ties are broken in FIFO order
+	suite.Equal([]string{"job1", "job2"}, suite.dequeueJobs(2))
+}
+
+// TestPriority tests that higher priority gangs are dequeued first
+// regardless of the share of their job
+func (suite *DRFQueueTestSuite) TestPriority() {
+	suite.NoError(suite.q.Enqueue(createGang("job1", 0, 1, 1, 100)))
+	suite.NoError(suite.q.Enqueue(createGang("job2", 0, 1, 1, 100)))
+	suite.NoError(suite.q.Enqueue(createGang("job1", 1, 2, 1, 100)))
+	suite.NoError(suite.q.Enqueue(createGang("job1", 2, 2, 1, 100)))
+
+	suite.Equal(2, suite.q.Len(2))
+	suite.Equal(
+		[]string{"job1", "job1", "job2", "job1"},
+		suite.dequeueJobs(4))
+
+	_, err := suite.q.Dequeue()
+	suite.IsType(ErrorQueueEmpty(""), err)
+}
+
+// TestPeek tests that peek returns the gangs in dequeue order without
+// changing the queue
+func (suite *DRFQueueTestSuite) TestPeek() {
+	_, err := suite.q.Peek(1)
+	suite.IsType(ErrorQueueEmpty(""), err)
+
+	for i := 0; i < 3; i++ {
+		suite.NoError(suite.q.Enqueue(createGang("job1", i, 1, 1, 100)))
+	}
+	suite.NoError(suite.q.Enqueue(createGang("job2", 0, 1, 1, 100)))
+	suite.NoError(suite.q.Enqueue(createGang("job3", 0, 0, 1, 100)))
+
+	gangs, err := suite.q.Peek(10)
+	suite.NoError(err)
+	suite.Len(gangs, 5)
+	var jobs []string
+	for _, gang := range gangs {
+		jobs = append(jobs, gang.GetTasks()[0].GetJobId().GetValue())
+	}
+	suite.Equal([]string{"job1", "job2", "job1", "job1", "job3"}, jobs)
+	suite.Equal(5, suite.q.Size())
+	suite.Equal(0.0, suite.q.Share("job1"))
+
+	gangs, err = suite.q.Peek(2)
+	suite.NoError(err)
+	suite.Len(gangs, 2)
+
+	suite.Equal(jobs, suite.dequeueJobs(5))
+}
+
+// TestRemove tests removing gangs from the queue
+func (suite *DRFQueueTestSuite) TestRemove() {
+	gang1 := createGang("job1", 0, 1, 1, 100)
+	gang2 := createGang("job1", 1, 1, 1, 100)
+	suite.NoError(suite.q.Enqueue(gang1))
+	suite.NoError(suite.q.Enqueue(gang2))
+	suite.NoError(suite.q.Enqueue(createGang("job2", 0, 1, 1, 100)))
+
+	suite.NoError(suite.q.Remove(gang2))
+	suite.Equal(2, suite.q.Size())
+
+	suite.NoError(suite.q.Remove(gang1))
+	suite.Equal(1, suite.q.Size())
+	suite.Equal([]string{"job2"}, suite.dequeueJobs(1))
+
+	suite.IsType(ErrorQueueEmpty(""), suite.q.Remove(gang1))
+	suite.Error(suite.q.Remove(nil))
+}
+
+// TestEnqueueErrors tests that empty gangs and gangs over the limit are
+// rejected
+func (suite *DRFQueueTestSuite) TestEnqueueErrors() {
+	q := NewDRFQueue(1, NewShares())
+	suite.Error(q.Enqueue(nil))
+	suite.Error(q.Enqueue(&resmgrsvc.Gang{}))
+	suite.NoError(q.Enqueue(createGang("job1", 0, 1, 1, 100)))
+	suite.EqualError(
+		q.Enqueue(createGang("job1", 1, 1, 1, 100)),
+		"list size limit reached")
+}
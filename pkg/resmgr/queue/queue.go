@@ -39,12 +39,19 @@ type Queue interface {
 	Size() int
 }
 
-// CreateQueue is factory method to create the specified queue
-func CreateQueue(policy respool.SchedulingPolicy, limit int64) (Queue, error) {
+// CreateQueue is factory method to create the specified queue. The shares
+// hold the allocation of the resource pool of the queue, which is used by
+// the dominant resource fairness policy.
+func CreateQueue(
+	policy respool.SchedulingPolicy,
+	limit int64,
+	shares *Shares) (Queue, error) {
 	// Factory method to create specific queue object based on policy
 	switch policy {
 	case respool.SchedulingPolicy_PriorityFIFO:
 		return NewPriorityQueue(limit), nil
+	case respool.SchedulingPolicy_DominantResourceFairness:
+		return NewDRFQueue(limit, shares), nil
 	default:
 		//if type is invalid, return an error
 		return nil, errors.New("invalid queue type")
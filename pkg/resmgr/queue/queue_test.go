@@ -33,14 +33,19 @@ func TestQueue(t *testing.T) {
 
 // TestCreateQueue tests the Create Queue
 func (suite *QueueTestSuite) TestCreateQueueSuccess() {
-	q, err := CreateQueue(respool.SchedulingPolicy_PriorityFIFO, 100)
+	q, err := CreateQueue(respool.SchedulingPolicy_PriorityFIFO, 100, NewShares())
 	suite.NoError(err)
 	suite.NotNil(q)
+
+	q, err = CreateQueue(
+		respool.SchedulingPolicy_DominantResourceFairness, 100, NewShares())
+	suite.NoError(err)
+	suite.IsType(&DRFQueue{}, q)
 }
 
 // TestCreateQueue tests the Create Queue
 func (suite *QueueTestSuite) TestCreateQueueError() {
-	q, err := CreateQueue(2, 100)
+	q, err := CreateQueue(100, 100, NewShares())
 	suite.Nil(q)
 	suite.Error(err)
 	suite.EqualError(err, "invalid queue type")
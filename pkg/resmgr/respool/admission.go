@@ -15,6 +15,9 @@
 package respool
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
 
@@ -70,11 +73,43 @@ func (qt QueueType) String() string {
 	return "undefined"
 }
 
-// returns true if the gang can be admitted to the pool
-type admitter func(gang *resmgrsvc.Gang, pool *resPool) bool
+const (
+	// AdmissionCheckEntitlement is the check of the gang resources against
+	// the entitlement of the resource pool
+	AdmissionCheckEntitlement = "entitlement"
+	// AdmissionCheckControllerLimit is the check of the controller gang
+	// resources against the controller limit of the resource pool
+	AdmissionCheckControllerLimit = "controller_limit"
+	// AdmissionCheckReservation is the check of the non-preemptible gang
+	// resources against the reservation of the resource pool
+	AdmissionCheckReservation = "reservation"
+)
+
+// AdmissionDecision is the last admission decision for a gang which
+// could not be admitted to the resource pool.
+type AdmissionDecision struct {
+	// The queue in which the gang is waiting for admission
+	Queue QueueType
+	// The admission check which rejected the gang
+	Check string
+	// The explanation of the decision
+	Message string
+	// The time of the decision
+	Time time.Time
+}
+
+// returns true if the gang can be admitted to the pool, otherwise false and
+// the reason why it can't be admitted
+type admitter func(gang *resmgrsvc.Gang, pool *resPool) (bool, string)
+
+// admissionCheck is a named admitter
+type admissionCheck struct {
+	name     string
+	admitter admitter
+}
 
 // returns true iff there's enough resources in the pool to admit the gang
-func entitlementAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
+func entitlementAdmitter(gang *resmgrsvc.Gang, pool *resPool) (bool, string) {
 	var currentAllocation, currentEntitlement *scalar.Resources
 	if !isRevocable(gang) {
 		currentEntitlement = pool.nonSlackEntitlement
@@ -93,23 +128,28 @@ func entitlementAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
 		"resources_required": neededResources,
 	}).Debug("checking entitlement")
 
-	return currentAllocation.
+	if currentAllocation.
 		Add(neededResources).
-		LessThanOrEqual(currentEntitlement)
+		LessThanOrEqual(currentEntitlement) {
+		return true, ""
+	}
+	return false, fmt.Sprintf(
+		"allocation %s plus required %s exceeds entitlement %s",
+		currentAllocation, neededResources, currentEntitlement)
 }
 
 // returns true if a controller gang can be admitted to the pool
-func controllerAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
+func controllerAdmitter(gang *resmgrsvc.Gang, pool *resPool) (bool, string) {
 	// ignore check on admission for non-controller tasks,
 	// and revocable tasks (can not be of controller type)
 	if !isController(gang) || isRevocable(gang) {
-		return true
+		return true, ""
 	}
 
 	if pool.controllerLimit == nil {
 		log.WithField("respool_id", pool.id).
 			Debug("resource pool doesn't have a controller limit")
-		return true
+		return true, ""
 	}
 
 	// check controller limit and allocation
@@ -124,9 +164,14 @@ func controllerAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
 		"resources_required": neededResources,
 	}).Debug("checking controller limit")
 
-	return controllerAllocation.
+	if controllerAllocation.
 		Add(neededResources).
-		LessThanOrEqual(controllerLimit)
+		LessThanOrEqual(controllerLimit) {
+		return true, ""
+	}
+	return false, fmt.Sprintf(
+		"controller allocation %s plus required %s exceeds controller limit %s",
+		controllerAllocation, neededResources, controllerLimit)
 }
 
 // For admission of non preemptible gangs there are 2 approaches:
@@ -136,14 +181,14 @@ func controllerAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
 //    (higher priority allocation) > reservation
 // Peloton takes approach 1 by checking the total allocation of all
 // non-preemptible gangs and the resource pool reservation.
-func reservationAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
+func reservationAdmitter(gang *resmgrsvc.Gang, pool *resPool) (bool, string) {
 	if !pool.isPreemptionEnabled() ||
 		isPreemptible(gang) ||
 		isRevocable(gang) {
 		// don't need to check reservation if
 		// 1. preemption is disabled or
 		// 2. its a preemptible job
-		return true
+		return true, ""
 	}
 
 	npAllocation := pool.allocation.GetByType(scalar.NonPreemptibleAllocation)
@@ -157,21 +202,26 @@ func reservationAdmitter(gang *resmgrsvc.Gang, pool *resPool) bool {
 		"resources_required":    neededResources,
 	}).Debug("checking reservation")
 
-	return npAllocation.
+	if npAllocation.
 		Add(neededResources).
-		LessThanOrEqual(reservation)
+		LessThanOrEqual(reservation) {
+		return true, ""
+	}
+	return false, fmt.Sprintf(
+		"non-preemptible allocation %s plus required %s exceeds reservation %s",
+		npAllocation, neededResources, reservation)
 }
 
 type admissionController struct {
-	admitters []admitter
+	checks []admissionCheck
 }
 
 // the global admission controller for all resource pool
 var admission = admissionController{
-	admitters: []admitter{
-		entitlementAdmitter,
-		controllerAdmitter,
-		reservationAdmitter,
+	checks: []admissionCheck{
+		{name: AdmissionCheckEntitlement, admitter: entitlementAdmitter},
+		{name: AdmissionCheckControllerLimit, admitter: controllerAdmitter},
+		{name: AdmissionCheckReservation, admitter: reservationAdmitter},
 	},
 }
 
@@ -194,7 +244,10 @@ func (ac admissionController) TryAdmit(
 		return errGangInvalid
 	}
 
-	if admitted := ac.canAdmit(gang, pool); !admitted {
+	if decision := ac.canAdmit(gang, pool, qt); decision != nil {
+		// keep the decision for the tasks of the gang, the queue is
+		// updated if the gang is moved to a different queue
+		setAdmissionDecision(pool, gang, decision)
 		if qt == PendingQueue {
 			// If a gang can't be admitted from the pending queue to the resource
 			// pool, then if:
@@ -211,6 +264,7 @@ func (ac admissionController) TryAdmit(
 				if err != nil {
 					return err
 				}
+				decision.Queue = NonPreemptibleQueue
 				return errSkipNonPreemptibleGang
 			}
 			if isController(gang) {
@@ -218,6 +272,7 @@ func (ac admissionController) TryAdmit(
 				if err != nil {
 					return err
 				}
+				decision.Queue = ControllerQueue
 				return errSkipControllerGang
 			}
 			if isRevocable(gang) {
@@ -225,6 +280,7 @@ func (ac admissionController) TryAdmit(
 				if err != nil {
 					return err
 				}
+				decision.Queue = RevocableQueue
 				return errSkipRevocableGang
 			}
 		}
@@ -242,8 +298,9 @@ func (ac admissionController) TryAdmit(
 		log.Error("failed to remove gang after successful admit")
 		return err
 	}
+	clearAdmissionDecision(pool, gang)
 
-	pool.allocation = pool.allocation.Add(scalar.GetGangAllocation(gang))
+	pool.setAllocation(pool.allocation.Add(scalar.GetGangAllocation(gang)))
 	return nil
 }
 
@@ -303,6 +360,7 @@ func (ac admissionController) validateGang(
 		} else {
 			// the task is invalid so we mark the gang as invalid
 			delete(pool.invalidTasks, task.Id.Value)
+			delete(pool.admissionDecisions, task.Id.Value)
 			isGangValid = false
 		}
 	}
@@ -361,20 +419,46 @@ func (ac admissionController) validateGang(
 	return false, nil
 }
 
-// returns true if gang can be admitted to the pool
+// returns nil if gang can be admitted to the pool, otherwise the decision
+// of the first admission check which rejected the gang
 func (ac admissionController) canAdmit(
 	gang *resmgrsvc.Gang,
-	pool *resPool) bool {
+	pool *resPool,
+	qt QueueType) *AdmissionDecision {
 
 	// loop through the admitters
-	for _, admitter := range ac.admitters {
-		if !admitter(gang, pool) {
+	for _, check := range ac.checks {
+		if ok, msg := check.admitter(gang, pool); !ok {
 			// bail out fast
-			return false
+			return &AdmissionDecision{
+				Queue:   qt,
+				Check:   check.name,
+				Message: msg,
+				Time:    time.Now(),
+			}
 		}
 	}
 	// all admitters can admit
-	return true
+	return nil
+}
+
+// setAdmissionDecision keeps the admission decision for all the tasks
+// of the gang
+func setAdmissionDecision(
+	pool *resPool,
+	gang *resmgrsvc.Gang,
+	decision *AdmissionDecision) {
+	for _, task := range gang.GetTasks() {
+		pool.admissionDecisions[task.GetId().GetValue()] = decision
+	}
+}
+
+// clearAdmissionDecision removes the admission decision of all the tasks
+// of the gang
+func clearAdmissionDecision(pool *resPool, gang *resmgrsvc.Gang) {
+	for _, task := range gang.GetTasks() {
+		delete(pool.admissionDecisions, task.GetId().GetValue())
+	}
 }
 
 // removeGangFromQueue removes a gang from a queue (pending/np/controller/revocable)
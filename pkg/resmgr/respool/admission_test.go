@@ -707,3 +707,48 @@ func assertAdmittedSuccessfully(s *ResPoolSuite, task *resmgr.Task, resPool *res
 	s.Equal(0, resPool.controllerQueue.Size())
 	s.Equal(0, resPool.npQueue.Size())
 }
+
+func (s *ResPoolSuite) TestAdmissionDecision() {
+	poolConfig := &respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    respool.SchedulingPolicy_PriorityFIFO,
+		ControllerLimit: &respool.ControllerLimit{
+			MaxPercent: 10,
+		},
+	}
+	rp := s.respoolWithConfig(poolConfig)
+	resPool, ok := rp.(*resPool)
+	s.True(ok)
+
+	task := s.getTasks()[0]
+	task.Controller = true
+	gang := makeTaskGang(task)
+	s.NoError(resPool.EnqueueGang(gang))
+	s.Nil(resPool.GetAdmissionDecision(task.Id))
+
+	// the entitlement is checked first
+	err := admission.TryAdmit(gang, resPool, PendingQueue)
+	s.Equal(errSkipControllerGang, err)
+	decision := resPool.GetAdmissionDecision(task.Id)
+	s.NotNil(decision)
+	s.Equal(AdmissionCheckEntitlement, decision.Check)
+	s.Equal(ControllerQueue, decision.Queue)
+	s.Contains(decision.Message, "exceeds entitlement")
+
+	// then the controller limit
+	resPool.SetNonSlackEntitlement(s.getEntitlement())
+	resPool.controllerLimit = scalar.ZeroResource
+	err = admission.TryAdmit(gang, resPool, ControllerQueue)
+	s.Equal(errResourcePoolFull, err)
+	decision = resPool.GetAdmissionDecision(task.Id)
+	s.Equal(AdmissionCheckControllerLimit, decision.Check)
+	s.Equal(ControllerQueue, decision.Queue)
+	s.Contains(decision.Message, "exceeds controller limit")
+
+	// the decision is removed once the gang is admitted
+	resPool.controllerLimit = s.getEntitlement()
+	s.NoError(admission.TryAdmit(gang, resPool, ControllerQueue))
+	s.Nil(resPool.GetAdmissionDecision(task.Id))
+}
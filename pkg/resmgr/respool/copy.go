@@ -0,0 +1,104 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package respool
+
+import (
+	"container/list"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/respool"
+
+	"github.com/uber/peloton/pkg/resmgr/scalar"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+	"github.com/uber-go/tally"
+)
+
+// CopyTree returns a deep copy of the resource pool hierarchy rooted at
+// the given resource pool, with the resource pool configs keyed by resource
+// pool ID replaced. The demand, allocation and entitlement of the resource
+// pools are copied, while the queued gangs are not. The copy is detached
+// from the tree, so that it can be used to simulate the entitlement
+// calculation without affecting the live resource pools.
+func CopyTree(
+	root ResPool,
+	configs map[string]*respool.ResourcePoolConfig,
+) (ResPool, error) {
+	return copyResPool(root, nil, configs)
+}
+
+// copyResPool copies a resource pool and its children recursively
+func copyResPool(
+	n ResPool,
+	parent ResPool,
+	configs map[string]*respool.ResourcePoolConfig,
+) (ResPool, error) {
+	src, ok := n.(*resPool)
+	if !ok {
+		return nil, errors.Errorf(
+			"unexpected resource pool type %T", n)
+	}
+
+	src.RLock()
+	config, ok := configs[src.id]
+	if !ok {
+		config = src.poolConfig
+	}
+	preemptionCfg := src.preemptionCfg
+	allocation := scalar.NewAllocation().Add(src.allocation)
+	demand := src.demand.Clone()
+	slackDemand := src.slackDemand.Clone()
+	entitlement := src.entitlement.Clone()
+	nonSlackEntitlement := src.nonSlackEntitlement.Clone()
+	slackEntitlement := src.slackEntitlement.Clone()
+	var children []ResPool
+	for child := src.children.Front(); child != nil; child = child.Next() {
+		children = append(children, child.Value.(ResPool))
+	}
+	src.RUnlock()
+
+	// The config is cloned since the entitlement calculation updates
+	// the resources of the root resource pool config in place.
+	c, err := NewRespool(
+		tally.NoopScope,
+		src.id,
+		parent,
+		proto.Clone(config).(*respool.ResourcePoolConfig),
+		preemptionCfg,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := c.(*resPool)
+	dst.setAllocation(allocation)
+	dst.demand = demand
+	dst.slackDemand = slackDemand
+	dst.entitlement = entitlement
+	dst.nonSlackEntitlement = nonSlackEntitlement
+	dst.slackEntitlement = slackEntitlement
+
+	childList := list.New()
+	for _, child := range children {
+		copied, err := copyResPool(child, dst, configs)
+		if err != nil {
+			return nil, err
+		}
+		childList.PushBack(copied)
+	}
+	dst.SetChildren(childList)
+
+	return dst, nil
+}
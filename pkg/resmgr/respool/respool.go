@@ -159,6 +159,10 @@ type ResPool interface {
 	// discarded asynchronously which scheduling.
 	AddInvalidTask(task *peloton.TaskID)
 
+	// GetAdmissionDecision returns the last admission decision for a task
+	// which could not be admitted, nil if there is none.
+	GetAdmissionDecision(task *peloton.TaskID) *AdmissionDecision
+
 	// UpdateResourceMetrics updates metrics for this resource pool
 	// on each entitlement cycle calculation (15s)
 	UpdateResourceMetrics()
@@ -180,6 +184,9 @@ type resPool struct {
 
 	// Tracks the allocation across different task dimensions
 	allocation *scalar.Allocation
+	// The allocation shared with the queues, which use it to order the
+	// gangs of the owners and jobs of the pool
+	shares *queue.Shares
 
 	// Tracks the max resources this resource pool can use in a given
 	// entitlement cycle
@@ -205,6 +212,8 @@ type resPool struct {
 	// the reserved resources of this pool
 	reservation *scalar.Resources
 
+	// the scheduling policy of the queues
+	policy respool.SchedulingPolicy
 	// queue containing gangs waiting to be admitted into the resource pool.
 	// queue semantics is defined by the SchedulingPolicy
 	pendingQueue queue.Queue
@@ -240,6 +249,10 @@ type resPool struct {
 	// set of invalid tasks which will be discarded during admission control.
 	invalidTasks map[string]bool
 
+	// last admission decision of the tasks which could not be admitted,
+	// keyed by task id.
+	admissionDecisions map[string]*AdmissionDecision
+
 	metrics *Metrics
 }
 
@@ -256,22 +269,23 @@ func NewRespool(
 			"ResourcePoolConfig is nil", id)
 	}
 
-	pq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
+	shares := queue.NewShares()
+	pq, err := queue.CreateQueue(config.Policy, math.MaxInt64, shares)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
 	}
 
-	cq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
+	cq, err := queue.CreateQueue(config.Policy, math.MaxInt64, shares)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
 	}
 
-	nq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
+	nq, err := queue.CreateQueue(config.Policy, math.MaxInt64, shares)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error creating resource pool %s", id)
 	}
 
-	rq, err := queue.CreateQueue(config.Policy, math.MaxInt64)
+	rq, err := queue.CreateQueue(config.Policy, math.MaxInt64, shares)
 	if err != nil {
 		return nil, errors.Wrapf(err, "error creating revocable queue %s", id)
 	}
@@ -282,11 +296,13 @@ func NewRespool(
 		parent:              parent,
 		resourceConfigs:     make(map[string]*respool.ResourceConfig),
 		poolConfig:          config,
+		policy:              config.Policy,
 		pendingQueue:        pq,
 		controllerQueue:     cq,
 		npQueue:             nq,
 		revocableQueue:      rq,
 		allocation:          scalar.NewAllocation(),
+		shares:              shares,
 		entitlement:         &scalar.Resources{},
 		nonSlackEntitlement: &scalar.Resources{},
 		slackEntitlement:    &scalar.Resources{},
@@ -295,6 +311,7 @@ func NewRespool(
 		slackLimit:          &scalar.Resources{},
 		reservation:         &scalar.Resources{},
 		invalidTasks:        make(map[string]bool),
+		admissionDecisions:  make(map[string]*AdmissionDecision),
 		preemptionCfg:       preemptionConfig,
 	}
 	pool.path = pool.calculatePath()
@@ -701,6 +718,74 @@ func (n *resPool) initialize(cfg *respool.ResourcePoolConfig) {
 	n.initControllerLimit(cfg)
 	n.initSlackLimit(cfg)
 	n.initReservation(cfg)
+	n.initQueues(cfg)
+}
+
+// initQueues recreates the queues when the scheduling policy changes and
+// moves the gangs waiting in the old queues to the new ones.
+// NB: The function calling initQueues should acquire the lock
+func (n *resPool) initQueues(cfg *respool.ResourcePoolConfig) {
+	if cfg.GetPolicy() == n.policy {
+		return
+	}
+
+	queueTypes := []QueueType{
+		PendingQueue,
+		ControllerQueue,
+		NonPreemptibleQueue,
+		RevocableQueue,
+	}
+	queues := make(map[QueueType]queue.Queue, len(queueTypes))
+	for _, qt := range queueTypes {
+		q, err := queue.CreateQueue(cfg.GetPolicy(), math.MaxInt64, n.shares)
+		if err != nil {
+			log.WithFields(log.Fields{
+				"respool_id": n.id,
+				"policy":     cfg.GetPolicy(),
+			}).WithError(err).Error("failed to create queues for the policy")
+			return
+		}
+		queues[qt] = q
+	}
+
+	for _, qt := range queueTypes {
+		if err := moveGangs(n.queue(qt), queues[qt]); err != nil {
+			log.WithFields(log.Fields{
+				"respool_id": n.id,
+				"queue":      qt,
+			}).WithError(err).Error("failed to move gangs to the new queue")
+		}
+	}
+
+	n.pendingQueue = queues[PendingQueue]
+	n.controllerQueue = queues[ControllerQueue]
+	n.npQueue = queues[NonPreemptibleQueue]
+	n.revocableQueue = queues[RevocableQueue]
+	n.policy = cfg.GetPolicy()
+}
+
+// moveGangs moves the gangs of one queue to another in dequeue order.
+func moveGangs(from queue.Queue, to queue.Queue) error {
+	for {
+		gang, err := from.Dequeue()
+		if err != nil {
+			if _, ok := err.(queue.ErrorQueueEmpty); ok {
+				return nil
+			}
+			return err
+		}
+		if err := to.Enqueue(gang); err != nil {
+			return err
+		}
+	}
+}
+
+// setAllocation sets the allocation of the pool and shares it with the
+// queues.
+// NB: The function calling setAllocation should acquire the lock
+func (n *resPool) setAllocation(allocation *scalar.Allocation) {
+	n.allocation = allocation
+	n.shares.Set(allocation)
 }
 
 // initializes the reserved resources
@@ -901,7 +986,7 @@ func (n *resPool) SubtractFromAllocation(allocation *scalar.Allocation) error {
 	if newAllocation == nil {
 		return errors.Errorf("couldn't update the resources")
 	}
-	n.allocation = newAllocation
+	n.setAllocation(newAllocation)
 
 	log.WithFields(log.Fields{
 		"respool_id": n.ID(),
@@ -960,7 +1045,7 @@ func (n *resPool) AddToAllocation(allocation *scalar.Allocation) error {
 	n.Lock()
 	defer n.Unlock()
 
-	n.allocation = n.allocation.Add(allocation)
+	n.setAllocation(n.allocation.Add(allocation))
 
 	log.WithFields(log.Fields{
 		"respool_id": n.ID(),
@@ -1128,6 +1213,20 @@ func (n *resPool) AddInvalidTask(task *peloton.TaskID) {
 	n.invalidTasks[task.Value] = true
 }
 
+// GetAdmissionDecision returns the last admission decision for a task
+// which could not be admitted, nil if there is none.
+func (n *resPool) GetAdmissionDecision(task *peloton.TaskID) *AdmissionDecision {
+	n.RLock()
+	defer n.RUnlock()
+	decision, ok := n.admissionDecisions[task.GetValue()]
+	if !ok {
+		return nil
+	}
+	// return a copy since the queue can be updated on the next admission
+	result := *decision
+	return &result
+}
+
 // PeekGangs returns a list of gangs from the queue based on the queue type.
 func (n *resPool) PeekGangs(qt QueueType, limit uint32) ([]*resmgrsvc.Gang,
 	error) {
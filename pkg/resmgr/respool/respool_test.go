@@ -1057,6 +1057,40 @@ func (s *ResPoolSuite) TestSetResourcePoolConfig() {
 	s.Equal(expectedResourcesMap, respool.Resources())
 }
 
+// TestSetResourcePoolConfigPolicy tests that the queues are recreated
+// when the scheduling policy changes, and keep their gangs
+func (s *ResPoolSuite) TestSetResourcePoolConfigPolicy() {
+	respool := s.createTestResourcePool()
+	for _, t := range s.getTasks() {
+		s.NoError(respool.EnqueueGang(makeTaskGang(t)))
+	}
+
+	poolConfig := &pb_respool.ResourcePoolConfig{
+		Name:      _testResPoolName,
+		Parent:    &_rootResPoolID,
+		Resources: s.getResources(),
+		Policy:    pb_respool.SchedulingPolicy_DominantResourceFairness,
+	}
+	respool.SetResourcePoolConfig(poolConfig)
+
+	resPool, ok := respool.(*resPool)
+	s.True(ok)
+	drfQueue, ok := resPool.pendingQueue.(*queue.DRFQueue)
+	s.True(ok)
+	s.Equal(len(s.getTasks()), drfQueue.Size())
+	s.Equal(2, drfQueue.Len(2))
+	s.Equal(1, drfQueue.Len(1))
+	s.Equal(1, drfQueue.Len(0))
+	s.IsType(&queue.DRFQueue{}, resPool.controllerQueue)
+	s.IsType(&queue.DRFQueue{}, resPool.npQueue)
+	s.IsType(&queue.DRFQueue{}, resPool.revocableQueue)
+
+	// the queues share the allocation of the pool
+	t := s.getTasks()[0]
+	s.NoError(respool.AddToAllocation(scalar.GetTaskAllocation(t)))
+	s.Equal(1.0, drfQueue.Share(scalar.ShareKey(t)))
+}
+
 func (s *ResPoolSuite) TestToResourcePoolInfo() {
 	respoolNode := s.createTestResourcePool()
 	info := respoolNode.ToResourcePoolInfo()
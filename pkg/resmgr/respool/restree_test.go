@@ -34,6 +34,7 @@ import (
 	"github.com/uber/peloton/pkg/resmgr/scalar"
 	store_mocks "github.com/uber/peloton/pkg/storage/mocks"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/suite"
 	"github.com/uber-go/tally"
@@ -467,6 +468,66 @@ func (s *resTreeTestSuite) TestDelete() {
 	s.Equal(3, resourceTree.GetAllNodes(true).Len())
 }
 
+// TestCopyTree tests that the copy of a resource pool hierarchy has the
+// proposed configs, and is detached from the live resource pools
+func (s *resTreeTestSuite) TestCopyTree() {
+	root, err := s.resourceTree.Get(
+		&peloton.ResourcePoolID{Value: common.RootResPoolID})
+	s.NoError(err)
+	respool11, err := s.resourceTree.Get(
+		&peloton.ResourcePoolID{Value: "respool11"})
+	s.NoError(err)
+
+	demand := &scalar.Resources{CPU: 10, MEMORY: 100}
+	s.NoError(respool11.AddToDemand(demand))
+	respool11.SetEntitlement(s.getEntitlement())
+
+	config := proto.Clone(s.getResPools()["respool12"]).(*respool.ResourcePoolConfig)
+	config.Resources[0].Reservation = 200
+	config.Resources[0].Type = respool.ReservationType_STATIC
+
+	copied, err := CopyTree(root, map[string]*respool.ResourcePoolConfig{
+		"respool12": config,
+	})
+	s.NoError(err)
+	s.Equal(common.RootResPoolID, copied.ID())
+	s.Equal(3, copied.Children().Len())
+
+	nodes := make(map[string]ResPool)
+	var collect func(n ResPool)
+	collect = func(n ResPool) {
+		nodes[n.ID()] = n
+		for e := n.Children().Front(); e != nil; e = e.Next() {
+			collect(e.Value.(ResPool))
+		}
+	}
+	collect(copied)
+	s.Len(nodes, s.resourceTree.GetAllNodes(false).Len())
+
+	copied11 := nodes["respool11"]
+	s.Equal("/respool1/respool11", copied11.GetPath())
+	s.Equal("respool1", copied11.Parent().ID())
+	s.Equal(demand, copied11.GetDemand())
+	s.Equal(s.getEntitlement(), copied11.GetEntitlement())
+
+	copied12 := nodes["respool12"]
+	s.Equal(float64(200), copied12.Resources()[common.CPU].GetReservation())
+	s.Equal(
+		respool.ReservationType_STATIC,
+		copied12.Resources()[common.CPU].GetType())
+
+	// changes to the copy do not affect the live resource pools
+	copied11.SetEntitlement(&scalar.Resources{CPU: 1})
+	s.NoError(copied11.AddToDemand(demand))
+	s.Equal(s.getEntitlement(), respool11.GetEntitlement())
+	s.Equal(demand, respool11.GetDemand())
+
+	respool12, err := s.resourceTree.Get(
+		&peloton.ResourcePoolID{Value: "respool12"})
+	s.NoError(err)
+	s.Equal(float64(100), respool12.Resources()[common.CPU].GetReservation())
+}
+
 func TestPelotonResPool(t *testing.T) {
 	suite.Run(t, new(resTreeTestSuite))
 }
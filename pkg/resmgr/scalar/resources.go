@@ -51,6 +51,9 @@ const (
 // Allocation is the container to track allocation across different dimensions
 type Allocation struct {
 	Value map[AllocationType]*Resources
+	// Shares tracks the total allocation by share key, which is the owner
+	// of the tasks or their job if they have no owner.
+	Shares map[string]*Resources
 }
 
 // NewAllocation returns a new Allocation
@@ -63,12 +66,26 @@ func (a *Allocation) GetByType(allocationType AllocationType) *Resources {
 	return a.Value[allocationType]
 }
 
+// GetByShareKey returns the allocation of a share key
+func (a *Allocation) GetByShareKey(key string) *Resources {
+	if res, ok := a.Shares[key]; ok {
+		return res
+	}
+	return ZeroResource
+}
+
 // Add adds one allocation to another
 func (a *Allocation) Add(other *Allocation) *Allocation {
 	result := initializeZeroAlloc()
 	for t, v := range a.Value {
 		result.Value[t] = v.Add(other.Value[t])
 	}
+	for key, v := range a.Shares {
+		result.Shares[key] = v
+	}
+	for key, v := range other.Shares {
+		result.Shares[key] = result.GetByShareKey(key).Add(v)
+	}
 	return result
 }
 
@@ -78,13 +95,25 @@ func (a *Allocation) Subtract(other *Allocation) *Allocation {
 	for t, v := range a.Value {
 		result.Value[t] = v.Subtract(other.Value[t])
 	}
+	for key, v := range a.Shares {
+		result.Shares[key] = v
+	}
+	for key, v := range other.Shares {
+		res := result.GetByShareKey(key).Subtract(v)
+		if res.Equal(ZeroResource) {
+			delete(result.Shares, key)
+			continue
+		}
+		result.Shares[key] = res
+	}
 	return result
 }
 
 // initializeZeroAlloc initializes a zero alloc
 func initializeZeroAlloc() *Allocation {
 	alloc := &Allocation{
-		Value: make(map[AllocationType]*Resources),
+		Value:  make(map[AllocationType]*Resources),
+		Shares: make(map[string]*Resources),
 	}
 
 	alloc.Value[TotalAllocation] = ZeroResource
@@ -135,10 +164,20 @@ func GetTaskAllocation(rmTask *resmgr.Task) *Allocation {
 
 	// every task account for total allocation
 	alloc.Value[TotalAllocation] = taskResource
+	alloc.Shares[ShareKey(rmTask)] = taskResource
 
 	return alloc
 }
 
+// ShareKey returns the key by which the allocation of a task is shared,
+// which is the owner of the task or its job if it has no owner.
+func ShareKey(rmTask *resmgr.Task) string {
+	if owner := rmTask.GetOwner(); owner != "" {
+		return owner
+	}
+	return rmTask.GetJobId().GetValue()
+}
+
 // ZeroResource represents the minimum Value of a resource
 var ZeroResource = &Resources{
 	CPU:    float64(0),
@@ -17,6 +17,7 @@ package scalar
 import (
 	"testing"
 
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/resmgr"
 	"github.com/uber/peloton/.gen/peloton/private/resmgrsvc"
@@ -409,3 +410,31 @@ func TestGetGangAllocation(t *testing.T) {
 	})
 	assertEqual(t, &Resources{1.0, 1.0, 1.0, 1.0}, res.GetByType(TotalAllocation))
 }
+
+func TestAllocationShares(t *testing.T) {
+	newTask := func(jobID, owner string) *resmgr.Task {
+		return &resmgr.Task{
+			JobId: &peloton.JobID{Value: jobID},
+			Owner: owner,
+			Resource: &task.ResourceConfig{
+				CpuLimit:   1,
+				MemLimitMb: 1,
+			},
+		}
+	}
+	owned := GetTaskAllocation(newTask("job1", "owner1"))
+	unowned := GetTaskAllocation(newTask("job2", ""))
+
+	alloc := NewAllocation().Add(owned).Add(owned).Add(unowned)
+	assertEqual(t, &Resources{2.0, 2.0, 0, 0}, alloc.GetByShareKey("owner1"))
+	assertEqual(t, &Resources{1.0, 1.0, 0, 0}, alloc.GetByShareKey("job2"))
+	assert.Equal(t, ZeroResource, alloc.GetByShareKey("job1"))
+
+	alloc = alloc.Subtract(owned).Subtract(unowned)
+	assertEqual(t, &Resources{1.0, 1.0, 0, 0}, alloc.GetByShareKey("owner1"))
+	assert.NotContains(t, alloc.Shares, "job2")
+
+	// the allocation being added to is not modified
+	assert.Len(t, owned.Shares, 1)
+	assertEqual(t, &Resources{1.0, 1.0, 0, 0}, owned.GetByShareKey("owner1"))
+}
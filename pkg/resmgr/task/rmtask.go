@@ -60,6 +60,15 @@ type RMTaskState struct {
 	LastUpdateTime time.Time
 }
 
+// PlacementFailure is the last failed placement of the rm task
+type PlacementFailure struct {
+	// The reason returned by the placement engine, for mimir this is the
+	// transcript of the requirements which passed and failed
+	Reason string
+	// The time the placement failure was returned
+	Time time.Time
+}
+
 // RMTask is the wrapper around resmgr.task for state machine
 type RMTask struct {
 	mu sync.Mutex // Mutex for synchronization
@@ -75,6 +84,9 @@ type RMTask struct {
 
 	runTimeStats *RunTimeStats // run time stats for resmgr task
 
+	// last failed placement of the task
+	placementFailure *PlacementFailure
+
 	// observes the state transitions of the rm task
 	transitionObserver TransitionObserver
 }
@@ -370,6 +382,14 @@ func (rmTask *RMTask) UpdateStartTime(startTime time.Time) {
 	rmTask.runTimeStats.StartTime = startTime
 }
 
+// GetPlacementFailure returns the last failed placement of the task, nil if
+// the placement of the task never failed
+func (rmTask *RMTask) GetPlacementFailure() *PlacementFailure {
+	rmTask.mu.Lock()
+	defer rmTask.mu.Unlock()
+	return rmTask.placementFailure
+}
+
 // AddBackoff adds the backoff to the RMtask based on backoff policy
 func (rmTask *RMTask) AddBackoff() error {
 	rmTask.mu.Lock()
@@ -420,6 +440,11 @@ func (rmTask *RMTask) RequeueUnPlaced(reason string) error {
 		return errUnplacedTaskInWrongState
 	}
 
+	rmTask.placementFailure = &PlacementFailure{
+		Reason: reason,
+		Time:   time.Now(),
+	}
+
 	// If task is in PLACING state we need to determine which STATE it will
 	// transition to based on retry attempts
 
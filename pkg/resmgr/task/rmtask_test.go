@@ -727,8 +727,12 @@ func (s *RMTaskTestSuite) TestRMTaskRequeueUnPlacedTaskInPlacingToReady() {
 		)
 		rmTask.stateMachine = sm
 		s.NoError(err)
+		s.Nil(rmTask.GetPlacementFailure())
 
-		return rmTask.RequeueUnPlaced("")
+		err = rmTask.RequeueUnPlaced("no hosts")
+		// the placement failure is kept for the pending reason
+		s.Equal("no hosts", rmTask.GetPlacementFailure().Reason)
+		return err
 	}
 
 	mockNode := mocks.NewMockResPool(s.ctrl)
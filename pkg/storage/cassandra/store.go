@@ -2831,6 +2831,39 @@ func (s *Store) AddJobUpdateEvent(
 	return nil
 }
 
+// AddJobUpdateCanaryEvent adds an update state change event for a job,
+// which records the result of the canary phase of the update
+func (s *Store) AddJobUpdateCanaryEvent(
+	ctx context.Context,
+	updateID *peloton.UpdateID,
+	updateType models.WorkflowType,
+	updateState update.State,
+	canaryState update.CanaryState,
+) error {
+	queryBuilder := s.DataStore.NewQuery()
+	stmt := queryBuilder.Insert(jobUpdateEvents).
+		Columns(
+			"update_id",
+			"type",
+			"state",
+			"canary_state",
+			"create_time").
+		Values(
+			updateID.GetValue(),
+			updateType.String(),
+			updateState.String(),
+			canaryState.String(),
+			qb.UUID{UUID: gocql.UUIDFromTime(time.Now())})
+	err := s.applyStatement(ctx, stmt, updateID.GetValue())
+	if err != nil {
+		s.metrics.UpdateMetrics.JobUpdateEventAddFail.Inc(1)
+		return err
+	}
+
+	s.metrics.UpdateMetrics.JobUpdateEventAdd.Inc(1)
+	return nil
+}
+
 // GetJobUpdateEvents gets update state change events for a job
 // in descending create timestamp order
 func (s *Store) GetJobUpdateEvents(
@@ -2856,6 +2889,12 @@ func (s *Store) GetJobUpdateEvents(
 			Timestamp: value["create_time"].(qb.UUID).Time().Format(time.RFC3339),
 		}
 
+		// canary state is only set on events recording the canary result
+		if canaryState, ok := value["canary_state"].(string); ok {
+			workflowEvent.CanaryState = stateless.CanaryState(
+				update.CanaryState_value[canaryState])
+		}
+
 		workflowEvents = append(workflowEvents, workflowEvent)
 	}
 
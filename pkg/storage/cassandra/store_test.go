@@ -2772,9 +2772,30 @@ func (suite *CassandraStoreTestSuite) TestModifyUpdate() {
 	suite.Equal(2, len(jobUpdateEvents))
 	suite.Equal(stateless.WorkflowState_WORKFLOW_STATE_ROLLING_BACKWARD,
 		jobUpdateEvents[0].GetState())
+	suite.Equal(stateless.CanaryState_CANARY_STATE_INVALID,
+		jobUpdateEvents[0].GetCanaryState())
 	suite.Equal(stateless.WorkflowState_WORKFLOW_STATE_INITIALIZED,
 		jobUpdateEvents[1].GetState())
 
+	// add canary failed event to job update events
+	suite.NoError(store.AddJobUpdateCanaryEvent(
+		context.Background(),
+		updateID,
+		models.WorkflowType_UPDATE,
+		update.State_ROLLING_BACKWARD,
+		update.CanaryState_CANARY_STATE_FAILED,
+	))
+
+	jobUpdateEvents, err = store.GetJobUpdateEvents(
+		context.Background(),
+		updateID)
+	suite.NoError(err)
+	suite.Equal(3, len(jobUpdateEvents))
+	suite.Equal(stateless.WorkflowState_WORKFLOW_STATE_ROLLING_BACKWARD,
+		jobUpdateEvents[0].GetState())
+	suite.Equal(stateless.CanaryState_CANARY_STATE_FAILED,
+		jobUpdateEvents[0].GetCanaryState())
+
 	// delete update
 	suite.NoError(store.DeleteUpdate(
 		context.Background(),
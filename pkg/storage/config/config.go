@@ -16,6 +16,17 @@ package config
 
 import (
 	"github.com/uber/peloton/pkg/storage/cassandra"
+	"github.com/uber/peloton/pkg/storage/connectors/sql"
+)
+
+const (
+	// ORMBackendCassandra stores the ORM objects in Cassandra
+	ORMBackendCassandra = "cassandra"
+	// ORMBackendSQL stores the ORM objects in a PostgreSQL or SQLite database
+	ORMBackendSQL = "sql"
+	// ORMBackendMemory keeps the ORM objects in memory, for tests and
+	// local development clusters only
+	ORMBackendMemory = "memory"
 )
 
 // Config contains the different DB config values for each
@@ -25,4 +36,10 @@ type Config struct {
 	UseCassandra       bool             `yaml:"use_cassandra"`
 	AutoMigrate        bool             `yaml:"auto_migrate"`
 	DbWriteConcurrency int              `yaml:"db_write_concurrency"`
+	// ORMBackend is the backend of the ORM objects, cassandra if not set
+	ORMBackend string `yaml:"orm_backend"`
+	// SQL is the database config of the sql ORM backend. The postgres
+	// driver is linked into every binary by the stores package, the
+	// sqlite3 driver only into the binaries which import it.
+	SQL sql.Config `yaml:"sql"`
 }
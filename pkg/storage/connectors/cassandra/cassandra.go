@@ -402,6 +402,28 @@ func (c *cassandraConnector) Update(
 	row []base.Column,
 	keyCols []base.Column,
 ) error {
+	return c.update(ctx, e, row, nil, keyCols)
+}
+
+// UpdateIf updates an existing row in DB if the conditions are met. Uses
+// CAS write.
+func (c *cassandraConnector) UpdateIf(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	conditions []base.Column,
+	keyCols []base.Column,
+) error {
+	return c.update(ctx, e, row, conditions, keyCols)
+}
+
+func (c *cassandraConnector) update(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	conditions []base.Column,
+	keyCols []base.Column,
+) error {
 
 	// split keyCols into a list of names and values to compose query stmt using
 	// names and use values in the session query call, so the order needs to be
@@ -413,11 +435,16 @@ func (c *cassandraConnector) Update(
 	// maintained.
 	colNames, colValues := splitColumnNameValue(row)
 
+	// split conditions the same way, a CAS write is used if there are any
+	condNames, condValues := splitColumnNameValue(conditions)
+	casWrite := len(conditions) > 0
+
 	// Prepare update statement
 	stmt, err := UpdateStmt(
 		Table(e.Name),
 		Updates(colNames),
 		Conditions(keyColNames),
+		IfConditions(condNames),
 	)
 
 	if err != nil {
@@ -426,14 +453,26 @@ func (c *cassandraConnector) Update(
 
 	// list of values to be supplied in the query
 	updateVals := append(colValues, keyColValues...)
+	updateVals = append(updateVals, condValues...)
 
 	q := c.Session.Query(
 		stmt, updateVals...).WithContext(ctx)
 	defer c.sendLatency(ctx, "execute_latency", time.Duration(q.Latency()))
 
-	if err := q.Exec(); err != nil {
-		c.metrics.ExecuteFail.Inc(1)
-		return err
+	if casWrite {
+		applied, err := q.MapScanCAS(map[string]interface{}{})
+		if err != nil {
+			c.metrics.ExecuteFail.Inc(1)
+			return err
+		}
+		if !applied {
+			return yarpcerrors.AbortedErrorf("update conditions not met")
+		}
+	} else {
+		if err := q.Exec(); err != nil {
+			c.metrics.ExecuteFail.Inc(1)
+			return err
+		}
 	}
 
 	c.metrics.ExecuteSuccess.Inc(1)
@@ -259,6 +259,54 @@ func (suite *CassandraConnSuite) TestCreateUpdateGet() {
 	suite.Equal(err.Error(), "PRIMARY KEY part id found in SET part")
 }
 
+// TestUpdateIf tests the conditional update operation
+func (suite *CassandraConnSuite) TestUpdateIf() {
+	// Definition stores schema information about an Object
+	obj := &base.Definition{
+		Name: testTableName1,
+		Key: &base.PrimaryKey{
+			PartitionKeys: []string{"id"},
+		},
+		// Column name to data type mapping of the object
+		ColumnToType: map[string]reflect.Type{
+			"id":   reflect.TypeOf(1),
+			"data": reflect.TypeOf("data"),
+			"name": reflect.TypeOf("name"),
+		},
+	}
+	// create the test row in C*
+	err := connector.Create(context.Background(), obj, testRow)
+	suite.NoError(err)
+
+	// the update is not applied if the current value of the row doesn't
+	// match the condition
+	err = connector.UpdateIf(
+		context.Background(),
+		obj,
+		[]base.Column{{Name: "name", Value: "test-update"}},
+		[]base.Column{{Name: "name", Value: "not-test"}},
+		keyRow)
+	suite.True(yarpcerrors.IsAborted(err))
+
+	err = connector.UpdateIf(
+		context.Background(),
+		obj,
+		[]base.Column{{Name: "name", Value: "test-update"}},
+		[]base.Column{{Name: "name", Value: "test"}},
+		keyRow)
+	suite.NoError(err)
+
+	// read the row from C* test table for given keys
+	row, err := connector.Get(context.Background(), obj, keyRow)
+	suite.NoError(err)
+	for _, col := range row {
+		if col.Name == "name" {
+			name := col.Value.(*string)
+			suite.Equal("test-update", *name)
+		}
+	}
+}
+
 // TestCreateGetAll tests the GetAll operation
 func (suite *CassandraConnSuite) TestCreateGetAll() {
 	// Definition stores schema information about an Object
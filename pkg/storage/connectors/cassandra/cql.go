@@ -35,6 +35,8 @@ const (
 	updates = "Updates"
 	// ifNotExist is used to indicate CAS write in the insert query
 	ifNotExist = "IfNotExist"
+	// ifConditions is used to indicate CAS write in the update query
+	ifConditions = "IfConditions"
 
 	// insertTemplate is used to construct an insert query
 	insertTemplate = `INSERT INTO {{.Table}} ({{ColumnFunc .Columns ", "}})` +
@@ -50,7 +52,8 @@ const (
 
 	// updateTemplate is used to construct update query
 	updateTemplate = `UPDATE {{.Table}} SET {{ConditionsFunc .Updates ", "}}` +
-		`{{WhereFunc .Conditions}}{{ConditionsFunc .Conditions " AND "}};`
+		`{{WhereFunc .Conditions}}{{ConditionsFunc .Conditions " AND "}}` +
+		`{{IfFunc .IfConditions}}{{ConditionsFunc .IfConditions " AND "}};`
 )
 
 var (
@@ -61,6 +64,7 @@ var (
 		"ConditionsFunc": conditionsFunc,
 		"WhereFunc":      whereFunc,
 		"ExistsFunc":     existsFunc,
+		"IfFunc":         ifFunc,
 	}
 
 	// insert CQL query template implementation
@@ -111,6 +115,14 @@ func existsFunc(exists bool) string {
 	return ""
 }
 
+// ifFunc adds an if clause to the update query
+func ifFunc(conds []string) string {
+	if len(conds) > 0 {
+		return " IF "
+	}
+	return ""
+}
+
 // Option to compose a cql statement
 type Option map[string]interface{}
 
@@ -163,6 +175,13 @@ func IfNotExist(v interface{}) OptFunc {
 	}
 }
 
+// IfConditions sets the `if` clause to the cql statement
+func IfConditions(v interface{}) OptFunc {
+	return func(opt Option) {
+		opt[ifConditions] = v
+	}
+}
+
 // InsertStmt creates insert statement
 func InsertStmt(opts ...OptFunc) (string, error) {
 	var bb bytes.Buffer
@@ -0,0 +1,609 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+
+	"github.com/gocql/gocql"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// row is the in-memory representation of a table row, keyed by column name
+type row map[string]interface{}
+
+// partition holds the rows of a partition sorted by clustering keys
+type partition []row
+
+type memoryConnector struct {
+	// implements orm.Connector interface
+	orm.Connector
+
+	sync.RWMutex
+	// map of table name -> partition key -> partition
+	tables map[string]map[string]partition
+}
+
+// NewMemoryConnector initializes an in-memory Connector. Rows are kept in
+// process memory and are lost when the process exits. It follows the
+// semantics of the Cassandra connector so that the storage objects can be
+// used unchanged:
+//   - Create and Update are upserts, CreateIfNotExists returns an
+//     "already-exists" error for an existing row.
+//   - Get returns gocql.ErrNotFound if the row does not exist.
+//   - GetAll and GetAllIter return the rows of a partition in clustering key
+//     order.
+//   - time.Time values are stored in UTC with millisecond precision.
+func NewMemoryConnector() orm.Connector {
+	return &memoryConnector{
+		tables: make(map[string]map[string]partition),
+	}
+}
+
+// ensure that implementation (memoryConnector) satisfies the interface
+var _ orm.Connector = (*memoryConnector)(nil)
+
+// CreateIfNotExists creates a new row if it already doesn't exist.
+func (c *memoryConnector) CreateIfNotExists(
+	ctx context.Context,
+	e *base.Definition,
+	values []base.Column,
+) error {
+	return c.create(ctx, e, values, true)
+}
+
+// Create creates a new row, overwriting the columns of an existing row.
+func (c *memoryConnector) Create(
+	ctx context.Context,
+	e *base.Definition,
+	values []base.Column,
+) error {
+	return c.create(ctx, e, values, false)
+}
+
+func (c *memoryConnector) create(
+	ctx context.Context,
+	e *base.Definition,
+	values []base.Column,
+	ifNotExists bool,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r := newRow(values)
+	pk, err := getPartitionKey(e, r)
+	if err != nil {
+		return err
+	}
+	if err := checkClusteringKeys(e, r); err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if ifNotExists {
+		if _, found := c.getPartition(e, pk).search(e, r); found {
+			return yarpcerrors.AlreadyExistsErrorf("item already exists")
+		}
+	}
+	c.upsert(e, pk, r, r)
+	return nil
+}
+
+// Get fetches a row by primary key.
+func (c *memoryConnector) Get(
+	ctx context.Context,
+	e *base.Definition,
+	keys []base.Column,
+) ([]base.Column, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err := c.selectRows(e, keys)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		// keep the error returned by the Cassandra connector, since
+		// callers check for it
+		return nil, gocql.ErrNotFound
+	}
+	return rows[0], nil
+}
+
+// GetAll fetches all rows by partition key.
+func (c *memoryConnector) GetAll(
+	ctx context.Context,
+	e *base.Definition,
+	keys []base.Column,
+) (rows [][]base.Column, err error) {
+	iter, err := c.GetAllIter(ctx, e, keys)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+	for {
+		row, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			return rows, nil
+		}
+		rows = append(rows, row)
+	}
+}
+
+// GetAllIter gives an iterator to fetch all rows by partition key. The
+// iterator works on a snapshot of the partition taken when it is created.
+func (c *memoryConnector) GetAllIter(
+	ctx context.Context,
+	e *base.Definition,
+	keys []base.Column,
+) (orm.Iterator, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err := c.selectRows(e, keys)
+	if err != nil {
+		return nil, err
+	}
+	return &memoryIterator{rows: rows}, nil
+}
+
+// Update updates the columns of a row, creating it if it doesn't exist.
+func (c *memoryConnector) Update(
+	ctx context.Context,
+	e *base.Definition,
+	values []base.Column,
+	keys []base.Column,
+) error {
+	return c.update(ctx, e, values, nil, keys)
+}
+
+// UpdateIf updates the columns of an existing row if its current values
+// match the conditions.
+func (c *memoryConnector) UpdateIf(
+	ctx context.Context,
+	e *base.Definition,
+	values []base.Column,
+	conditions []base.Column,
+	keys []base.Column,
+) error {
+	return c.update(ctx, e, values, conditions, keys)
+}
+
+func (c *memoryConnector) update(
+	ctx context.Context,
+	e *base.Definition,
+	values []base.Column,
+	conditions []base.Column,
+	keys []base.Column,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	k := newRow(keys)
+	pk, err := getPartitionKey(e, k)
+	if err != nil {
+		return err
+	}
+	if err := checkClusteringKeys(e, k); err != nil {
+		return err
+	}
+
+	// primary key columns cannot be updated
+	r := newRow(values)
+	for name := range k {
+		delete(r, name)
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if len(conditions) > 0 {
+		p := c.getPartition(e, pk)
+		i, found := p.search(e, k)
+		if !found || !p[i].matches(newRow(conditions)) {
+			return yarpcerrors.AbortedErrorf("update conditions not met")
+		}
+	}
+	c.upsert(e, pk, k, r)
+	return nil
+}
+
+// Delete deletes the rows matching the keys.
+func (c *memoryConnector) Delete(
+	ctx context.Context,
+	e *base.Definition,
+	keys []base.Column,
+) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	k := newRow(keys)
+	pk, err := getPartitionKey(e, k)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	p := c.getPartition(e, pk)
+	var remaining partition
+	for _, r := range p {
+		if !r.matches(k) {
+			remaining = append(remaining, r)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(c.tables[e.Name], pk)
+		return nil
+	}
+	c.tables[e.Name][pk] = remaining
+	return nil
+}
+
+// getPartition returns the partition of a table. Must be called with the
+// lock held.
+func (c *memoryConnector) getPartition(
+	e *base.Definition,
+	pk string,
+) partition {
+	return c.tables[e.Name][pk]
+}
+
+// upsert writes the columns of r into the row identified by key, inserting
+// the row in clustering key order if it doesn't exist. Must be called with
+// the write lock held.
+func (c *memoryConnector) upsert(
+	e *base.Definition,
+	pk string,
+	key row,
+	r row,
+) {
+	table, ok := c.tables[e.Name]
+	if !ok {
+		table = make(map[string]partition)
+		c.tables[e.Name] = table
+	}
+
+	p := table[pk]
+	i, found := p.search(e, key)
+	if !found {
+		inserted := make(row)
+		for name, value := range key {
+			inserted[name] = value
+		}
+		p = append(p, nil)
+		copy(p[i+1:], p[i:])
+		p[i] = inserted
+	}
+	for name, value := range r {
+		p[i][name] = value
+	}
+	table[pk] = p
+}
+
+// selectRows returns the rows of a partition which match the keys, as
+// lists of columns to be read for the object.
+func (c *memoryConnector) selectRows(
+	e *base.Definition,
+	keys []base.Column,
+) ([][]base.Column, error) {
+	k := newRow(keys)
+	pk, err := getPartitionKey(e, k)
+	if err != nil {
+		return nil, err
+	}
+
+	c.RLock()
+	defer c.RUnlock()
+
+	var rows [][]base.Column
+	for _, r := range c.getPartition(e, pk) {
+		if r.matches(k) {
+			rows = append(rows, r.toColumns(e))
+		}
+	}
+	return rows, nil
+}
+
+// search returns the position of the row with the same clustering keys as
+// r in the partition, or the position where it should be inserted.
+func (p partition) search(e *base.Definition, r row) (int, bool) {
+	i := sort.Search(len(p), func(i int) bool {
+		return compareClusteringKeys(e, p[i], r) >= 0
+	})
+	return i, i < len(p) && compareClusteringKeys(e, p[i], r) == 0
+}
+
+// matches returns true if the row has the same value for all given columns.
+func (r row) matches(k row) bool {
+	for name, value := range k {
+		if compareValues(r[name], value) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// toColumns translates a row into the list of columns to be read for the
+// object. Columns which were never written are returned with a nil value.
+func (r row) toColumns(e *base.Definition) []base.Column {
+	columns := make([]base.Column, 0, len(e.ColumnToType))
+	for _, name := range e.GetColumnsToRead() {
+		columns = append(columns, base.Column{
+			Name:  name,
+			Value: copyValue(r[name]),
+		})
+	}
+	return columns
+}
+
+// newRow builds a row from a list of columns.
+func newRow(columns []base.Column) row {
+	r := make(row, len(columns))
+	for _, column := range columns {
+		r[column.Name] = normalizeValue(column.Value)
+	}
+	return r
+}
+
+// normalizeValue converts a value to the form in which it is stored.
+func normalizeValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Truncate(time.Millisecond)
+	default:
+		return copyValue(value)
+	}
+}
+
+// copyValue copies byte slices so that callers cannot modify stored rows.
+func copyValue(value interface{}) interface{} {
+	if v, ok := value.([]byte); ok && v != nil {
+		return append([]byte{}, v...)
+	}
+	return value
+}
+
+// getPartitionKey returns the string representation of the partition key of
+// the row. Returns an error if any partition key column is missing.
+func getPartitionKey(e *base.Definition, r row) (string, error) {
+	var parts []string
+	for _, name := range e.Key.PartitionKeys {
+		value, ok := r[name]
+		if !ok {
+			return "", yarpcerrors.InvalidArgumentErrorf(
+				"missing partition key %s for table %s", name, e.Name)
+		}
+		parts = append(parts, keyString(value))
+	}
+	return strings.Join(parts, "\x00"), nil
+}
+
+// checkClusteringKeys returns an error if any clustering key column is
+// missing in the row.
+func checkClusteringKeys(e *base.Definition, r row) error {
+	for _, ck := range e.Key.ClusteringKeys {
+		if _, ok := r[ck.Name]; !ok {
+			return yarpcerrors.InvalidArgumentErrorf(
+				"missing clustering key %s for table %s", ck.Name, e.Name)
+		}
+	}
+	return nil
+}
+
+// keyString returns a string representation of a key column value which is
+// the same for equal values of different integer types.
+func keyString(value interface{}) string {
+	switch v := value.(type) {
+	case time.Time:
+		return fmt.Sprint(v.UnixNano())
+	case gocql.UUID:
+		return v.String()
+	case []byte:
+		return fmt.Sprintf("%x", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareClusteringKeys compares two rows in clustering key order.
+func compareClusteringKeys(e *base.Definition, a, b row) int {
+	for _, ck := range e.Key.ClusteringKeys {
+		result := compareValues(a[ck.Name], b[ck.Name])
+		if ck.Descending {
+			result = -result
+		}
+		if result != 0 {
+			return result
+		}
+	}
+	return 0
+}
+
+// compareValues compares two column values the way Cassandra orders them,
+// returning -1, 0 or 1. Time UUIDs are ordered by time first.
+func compareValues(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	switch av := a.(type) {
+	case string:
+		if bv, ok := b.(string); ok {
+			return strings.Compare(av, bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			default:
+				return 1
+			}
+		}
+	case []byte:
+		if bv, ok := b.([]byte); ok {
+			return bytes.Compare(av, bv)
+		}
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return compareInts(av.UnixNano(), bv.UnixNano())
+		}
+	case gocql.UUID:
+		if bv, ok := b.(gocql.UUID); ok {
+			if av.Version() == 1 && bv.Version() == 1 {
+				if result := compareInts(
+					av.Time().UnixNano(), bv.Time().UnixNano()); result != 0 {
+					return result
+				}
+			}
+			return bytes.Compare(av.Bytes(), bv.Bytes())
+		}
+	}
+
+	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if isInt(ra) && isInt(rb) {
+		return compareIntValues(ra, rb)
+	}
+	if isFloat(ra) && isFloat(rb) {
+		switch {
+		case ra.Float() < rb.Float():
+			return -1
+		case ra.Float() > rb.Float():
+			return 1
+		default:
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isFloat(v reflect.Value) bool {
+	return v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+func isUnsigned(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// compareIntValues compares two values of possibly different integer types.
+func compareIntValues(a, b reflect.Value) int {
+	switch {
+	case isUnsigned(a) && isUnsigned(b):
+		return compareUints(a.Uint(), b.Uint())
+	case isUnsigned(a):
+		if b.Int() < 0 {
+			return 1
+		}
+		return compareUints(a.Uint(), uint64(b.Int()))
+	case isUnsigned(b):
+		if a.Int() < 0 {
+			return -1
+		}
+		return compareUints(uint64(a.Int()), b.Uint())
+	default:
+		return compareInts(a.Int(), b.Int())
+	}
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// memoryIterator implements interface Iterator for the in-memory connector
+type memoryIterator struct {
+	rows [][]base.Column
+}
+
+// ensure that implementation (memoryIterator) satisfies the interface
+var _ orm.Iterator = (*memoryIterator)(nil)
+
+// Next returns the next row, or nil once all rows have been returned.
+func (iter *memoryIterator) Next() ([]base.Column, error) {
+	if len(iter.rows) == 0 {
+		return nil, nil
+	}
+	row := iter.rows[0]
+	iter.rows = iter.rows[1:]
+	return row, nil
+}
+
+// Close releases the rows held by the iterator.
+func (iter *memoryIterator) Close() {
+	iter.rows = nil
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// testTable has partition key "id" and clustering key "ck", in
+// descending order as generated by the orm object parser
+var testTable = &base.Definition{
+	Name: "test_table",
+	Key: &base.PrimaryKey{
+		PartitionKeys: []string{"id"},
+		ClusteringKeys: []*base.ClusteringKey{
+			{Name: "ck", Descending: true},
+		},
+	},
+	ColumnToType: map[string]reflect.Type{
+		"id":   reflect.TypeOf(uint64(0)),
+		"ck":   reflect.TypeOf(uint64(0)),
+		"data": reflect.TypeOf(""),
+		"time": reflect.TypeOf(time.Time{}),
+	},
+}
+
+type MemoryConnectorTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	connector *memoryConnector
+}
+
+func (suite *MemoryConnectorTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	suite.connector = NewMemoryConnector().(*memoryConnector)
+}
+
+func TestMemoryConnector(t *testing.T) {
+	suite.Run(t, new(MemoryConnectorTestSuite))
+}
+
+func testRow(id, ck uint64, data string) []base.Column {
+	return []base.Column{
+		{Name: "id", Value: id},
+		{Name: "ck", Value: ck},
+		{Name: "data", Value: data},
+	}
+}
+
+func keyRow(id, ck uint64) []base.Column {
+	return []base.Column{
+		{Name: "id", Value: id},
+		{Name: "ck", Value: ck},
+	}
+}
+
+// columnValue returns the value of a column in the row
+func columnValue(row []base.Column, name string) interface{} {
+	for _, column := range row {
+		if column.Name == name {
+			return column.Value
+		}
+	}
+	return nil
+}
+
+// TestCreateGet tests creating a row and reading it back
+func (suite *MemoryConnectorTestSuite) TestCreateGet() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(1, 10, "data10")))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Len(row, len(testTable.ColumnToType))
+	suite.Equal("data10", columnValue(row, "data"))
+	suite.Nil(columnValue(row, "time"))
+
+	// Create overwrites an existing row
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(1, 10, "data10-new")))
+	row, err = suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Equal("data10-new", columnValue(row, "data"))
+
+	// keys of a different integer type match the same row
+	row, err = suite.connector.Get(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: 1},
+		{Name: "ck", Value: uint32(10)},
+	})
+	suite.NoError(err)
+	suite.Equal("data10-new", columnValue(row, "data"))
+}
+
+// TestGetNotFound tests that gocql.ErrNotFound is returned for a missing row
+func (suite *MemoryConnectorTestSuite) TestGetNotFound() {
+	_, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.Equal(gocql.ErrNotFound, err)
+}
+
+// TestCreateIfNotExists tests that an existing row is not overwritten
+func (suite *MemoryConnectorTestSuite) TestCreateIfNotExists() {
+	suite.NoError(suite.connector.CreateIfNotExists(
+		suite.ctx, testTable, testRow(1, 10, "data10")))
+
+	err := suite.connector.CreateIfNotExists(
+		suite.ctx, testTable, testRow(1, 10, "data10-new"))
+	suite.True(yarpcerrors.IsAlreadyExists(err))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Equal("data10", columnValue(row, "data"))
+}
+
+// TestMissingKey tests that an error is returned if a key is missing
+func (suite *MemoryConnectorTestSuite) TestMissingKey() {
+	err := suite.connector.Create(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(1)},
+	})
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	_, err = suite.connector.GetAll(suite.ctx, testTable, nil)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestGetAll tests that rows of a partition are returned in clustering
+// key order
+func (suite *MemoryConnectorTestSuite) TestGetAll() {
+	for _, ck := range []uint64{20, 10, 30} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx, testTable, testRow(1, ck, "data")))
+	}
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(2, 40, "data")))
+
+	rows, err := suite.connector.GetAll(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(1)},
+	})
+	suite.NoError(err)
+	suite.Len(rows, 3)
+	for i, ck := range []uint64{30, 20, 10} {
+		suite.Equal(ck, columnValue(rows[i], "ck"))
+	}
+
+	rows, err = suite.connector.GetAll(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(3)},
+	})
+	suite.NoError(err)
+	suite.Empty(rows)
+}
+
+// TestGetAllIter tests iterating over the rows of a partition
+func (suite *MemoryConnectorTestSuite) TestGetAllIter() {
+	for _, ck := range []uint64{10, 20} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx, testTable, testRow(1, ck, "data")))
+	}
+
+	iter, err := suite.connector.GetAllIter(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(1)},
+	})
+	suite.NoError(err)
+	defer iter.Close()
+
+	// rows written after the iterator is created are not returned
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(1, 30, "data")))
+
+	for _, ck := range []uint64{20, 10} {
+		row, err := iter.Next()
+		suite.NoError(err)
+		suite.Equal(ck, columnValue(row, "ck"))
+	}
+	row, err := iter.Next()
+	suite.NoError(err)
+	suite.Nil(row)
+}
+
+// TestUpdate tests updating selected columns of a row
+func (suite *MemoryConnectorTestSuite) TestUpdate() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(1, 10, "data10")))
+
+	now := time.Now()
+	suite.NoError(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "time", Value: now}},
+		keyRow(1, 10)))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Equal("data10", columnValue(row, "data"))
+	suite.Equal(
+		now.UTC().Truncate(time.Millisecond),
+		columnValue(row, "time"))
+
+	// update creates the row if it doesn't exist
+	suite.NoError(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "data", Value: "data20"}},
+		keyRow(1, 20)))
+	row, err = suite.connector.Get(suite.ctx, testTable, keyRow(1, 20))
+	suite.NoError(err)
+	suite.Equal("data20", columnValue(row, "data"))
+}
+
+// TestUpdateIf tests updating columns of a row only if their current
+// values match the conditions
+func (suite *MemoryConnectorTestSuite) TestUpdateIf() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(1, 10, "data10")))
+
+	err := suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "data", Value: "data11"}},
+		[]base.Column{{Name: "data", Value: "data12"}},
+		keyRow(1, 10))
+	suite.True(yarpcerrors.IsAborted(err))
+
+	suite.NoError(suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "data", Value: "data11"}},
+		[]base.Column{{Name: "data", Value: "data10"}},
+		keyRow(1, 10)))
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Equal("data11", columnValue(row, "data"))
+
+	// a missing row never meets the conditions
+	err = suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "data", Value: "data20"}},
+		[]base.Column{{Name: "data", Value: nil}},
+		keyRow(1, 20))
+	suite.True(yarpcerrors.IsAborted(err))
+	_, err = suite.connector.Get(suite.ctx, testTable, keyRow(1, 20))
+	suite.Equal(gocql.ErrNotFound, err)
+}
+
+// TestDelete tests deleting rows by primary key and by partition key
+func (suite *MemoryConnectorTestSuite) TestDelete() {
+	for _, ck := range []uint64{10, 20, 30} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx, testTable, testRow(1, ck, "data")))
+	}
+
+	suite.NoError(suite.connector.Delete(suite.ctx, testTable, keyRow(1, 20)))
+	_, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 20))
+	suite.Equal(gocql.ErrNotFound, err)
+
+	// deleting a missing row is a no-op
+	suite.NoError(suite.connector.Delete(suite.ctx, testTable, keyRow(1, 20)))
+
+	suite.NoError(suite.connector.Delete(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(1)},
+	}))
+	rows, err := suite.connector.GetAll(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(1)},
+	})
+	suite.NoError(err)
+	suite.Empty(rows)
+	suite.Empty(suite.connector.tables[testTable.Name])
+}
+
+// TestBytesAreCopied tests that stored byte slices cannot be modified by
+// the caller
+func (suite *MemoryConnectorTestSuite) TestBytesAreCopied() {
+	data := []byte("data")
+	suite.NoError(suite.connector.Create(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(1)},
+		{Name: "ck", Value: uint64(10)},
+		{Name: "data", Value: data},
+	}))
+	data[0] = 'x'
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Equal([]byte("data"), columnValue(row, "data"))
+}
+
+// TestCompareTimeUUIDs tests that time UUIDs are ordered by time
+func (suite *MemoryConnectorTestSuite) TestCompareTimeUUIDs() {
+	now := time.Now()
+	earlier := gocql.UUIDFromTime(now)
+	later := gocql.UUIDFromTime(now.Add(time.Second))
+	suite.Equal(-1, compareValues(earlier, later))
+	suite.Equal(1, compareValues(later, earlier))
+	suite.Equal(0, compareValues(earlier, earlier))
+}
+
+// TestContextCancelled tests that operations fail on a cancelled context
+func (suite *MemoryConnectorTestSuite) TestContextCancelled() {
+	ctx, cancel := context.WithCancel(suite.ctx)
+	cancel()
+	suite.Error(suite.connector.Create(ctx, testTable, testRow(1, 10, "data")))
+	_, err := suite.connector.Get(ctx, testTable, keyRow(1, 10))
+	suite.Error(err)
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+)
+
+// CreateTableStmt generates the DDL statement which creates the table of the
+// given object definition for the given driver. The primary key of the
+// table is composed of the partition keys followed by the clustering keys.
+func CreateTableStmt(driver string, e *base.Definition) (string, error) {
+	d, err := (&Config{Driver: driver}).getDialect()
+	if err != nil {
+		return "", err
+	}
+	return d.createTableStmt(e)
+}
+
+func (d *dialect) createTableStmt(e *base.Definition) (string, error) {
+	// sort the columns so that the generated DDL is deterministic
+	colNames := e.GetColumnsToRead()
+	sort.Strings(colNames)
+
+	defs := make([]string, 0, len(colNames)+1)
+	for _, name := range colNames {
+		colType, err := d.columnType(e.ColumnToType[name])
+		if err != nil {
+			return "", err
+		}
+		defs = append(defs, quote(name)+" "+colType)
+	}
+	defs = append(defs, "PRIMARY KEY ("+
+		strings.Join(quoteAll(primaryKeyColumns(e)), ", ")+")")
+
+	return "CREATE TABLE IF NOT EXISTS " + quote(e.Name) + " (" +
+		strings.Join(defs, ", ") + ");", nil
+}
+
+// primaryKeyColumns returns the partition keys followed by the clustering keys
+// of an object definition
+func primaryKeyColumns(e *base.Definition) []string {
+	keys := append([]string{}, e.Key.PartitionKeys...)
+	for _, ck := range e.Key.ClusteringKeys {
+		keys = append(keys, ck.Name)
+	}
+	return keys
+}
+
+// quote quotes a table or column name
+func quote(name string) string {
+	return strconv.Quote(name)
+}
+
+// quoteAll quotes a list of table or column names
+func quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = quote(name)
+	}
+	return quoted
+}
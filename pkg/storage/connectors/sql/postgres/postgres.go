@@ -0,0 +1,23 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package postgres registers the PostgreSQL database/sql driver used by the
+// SQL connector. Binaries which use a PostgreSQL backed store import it
+// for its side effect.
+package postgres
+
+import (
+	// register the postgres database/sql driver
+	_ "github.com/lib/pq"
+)
@@ -0,0 +1,552 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/uber/peloton/pkg/storage/cassandra/impl"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	"github.com/uber/peloton/pkg/storage/orm"
+
+	"github.com/gocql/gocql"
+	log "github.com/sirupsen/logrus"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const useCasWrite = true
+
+type sqlConnector struct {
+	// implements orm.Connector interface
+	orm.Connector
+	// db is the database handle created for this connector
+	db *dbsql.DB
+	// dialect has the driver specific parts of the generated statements
+	dialect *dialect
+	// metrics are the storage specific metrics
+	metrics impl.Metrics
+	// scope is the storage scope for metrics
+	scope tally.Scope
+}
+
+// NewSQLConnector initializes a SQL Connector. Tables of the given object
+// definitions are created if they do not exist yet.
+func NewSQLConnector(
+	config *Config,
+	scope tally.Scope,
+	defs ...*base.Definition,
+) (orm.Connector, error) {
+	d, err := config.getDialect()
+	if err != nil {
+		return nil, err
+	}
+	config.normalize()
+
+	db, err := dbsql.Open(config.Driver, config.DataSource)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(config.MaxOpenConns)
+
+	for _, e := range defs {
+		stmt, err := d.createTableStmt(e)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			log.WithError(err).
+				WithField("table", e.Name).
+				Error("failed to create table")
+			db.Close()
+			return nil, err
+		}
+	}
+
+	storeScope := scope.Tagged(map[string]string{"store": config.Driver})
+
+	return &sqlConnector{
+		db:      db,
+		dialect: d,
+		metrics: impl.NewMetrics(storeScope),
+		scope:   storeScope,
+	}, nil
+}
+
+// ensure that implementation (sqlConnector) satisfies the interface
+var _ orm.Connector = (*sqlConnector)(nil)
+
+// _uuidByteOrder reorders the bytes of a time UUID so that the time_hi,
+// time_mid and time_low fields come first. Comparing the resulting byte
+// strings then orders time UUIDs by time, like Cassandra does.
+var _uuidByteOrder = [16]int{6, 7, 4, 5, 0, 1, 2, 3, 8, 9, 10, 11, 12, 13, 14, 15}
+
+func encodeUUID(u gocql.UUID) []byte {
+	b := make([]byte, len(u))
+	for i, j := range _uuidByteOrder {
+		b[i] = u[j]
+	}
+	return b
+}
+
+func decodeUUID(b []byte) (gocql.UUID, error) {
+	var u gocql.UUID
+	if len(b) != len(u) {
+		return u, yarpcerrors.InternalErrorf(
+			"invalid stored uuid of length %d", len(b))
+	}
+	for i, j := range _uuidByteOrder {
+		u[j] = b[i]
+	}
+	return u, nil
+}
+
+// toDriverValue converts a column value to a type which is understood by all
+// supported database/sql drivers
+func toDriverValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case gocql.UUID:
+		return encodeUUID(v), nil
+	case time.Time:
+		return v.UTC(), nil
+	case []byte:
+		return v, nil
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		// SQL has no unsigned types, so unsigned values are stored as
+		// int64 and must fit in it to be read back unchanged
+		if rv.Uint() > math.MaxInt64 {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"unsigned value %d overflows the integer column", rv.Uint())
+		}
+		return int64(rv.Uint()), nil
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	}
+	return value, nil
+}
+
+// splitColumnNameValue is used to return list of column names and list of
+// their corresponding driver values. Order is very important in this lists
+// as they will be used separately when constructing the SQL statement.
+func splitColumnNameValue(row []base.Column) (
+	colNames []string, colValues []interface{}, err error) {
+	for _, column := range row {
+		value, err := toDriverValue(column.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		colNames = append(colNames, column.Name)
+		colValues = append(colValues, value)
+	}
+	return colNames, colValues, nil
+}
+
+// buildResultRow is used to allocate memory for the row to be populated by
+// a SQL read operation based on what object fields are being read
+func buildResultRow(e *base.Definition, columns []string) []interface{} {
+	results := make([]interface{}, len(columns))
+
+	for i, column := range columns {
+		typ := e.ColumnToType[column]
+
+		switch {
+		case typ == timeType:
+			var value *time.Time
+			results[i] = &value
+			continue
+		case typ == uuidType:
+			var value *[]byte
+			results[i] = &value
+			continue
+		}
+
+		switch typ.Kind() {
+		case reflect.String:
+			var value *string
+			results[i] = &value
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+			reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+			reflect.Uint32, reflect.Uint64:
+			var value *int64
+			results[i] = &value
+		case reflect.Bool:
+			var value *bool
+			results[i] = &value
+		case reflect.Slice:
+			var value *[]byte
+			results[i] = &value
+		default:
+			// This should only happen if we start using a new type without
+			// adding to the translation layer
+			log.WithFields(log.Fields{"type": typ.Kind(), "column": column}).
+				Infof("type not found")
+			var value interface{}
+			results[i] = &value
+		}
+	}
+
+	return results
+}
+
+// getRowFromResult translates a row read from the database into a list of
+// base.Column to be interpreted by base store client
+func getRowFromResult(
+	e *base.Definition, columnNames []string, columnVals []interface{},
+) ([]base.Column, error) {
+
+	row := make([]base.Column, 0, len(columnNames))
+
+	for i, columnName := range columnNames {
+		column := base.Column{
+			Name: columnName,
+		}
+
+		switch rv := columnVals[i].(type) {
+		case **int64:
+			column.Value = *rv
+		case **string:
+			column.Value = *rv
+		case **time.Time:
+			column.Value = *rv
+		case **bool:
+			column.Value = *rv
+		case **[]byte:
+			if e.ColumnToType[columnName] != uuidType {
+				column.Value = *rv
+				break
+			}
+			// time UUIDs are stored in their time ordered encoding
+			var value *gocql.UUID
+			if *rv != nil {
+				u, err := decodeUUID(**rv)
+				if err != nil {
+					return nil, err
+				}
+				value = &u
+			}
+			column.Value = value
+		case *interface{}:
+			column.Value = *rv
+		}
+		row = append(row, column)
+	}
+	return row, nil
+}
+
+func (c *sqlConnector) sendLatency(name string, start time.Time) {
+	c.scope.Timer(name).Record(time.Since(start))
+}
+
+// exec executes a statement which does not return rows
+func (c *sqlConnector) exec(
+	ctx context.Context,
+	stmt string,
+	args ...interface{},
+) (dbsql.Result, error) {
+	defer c.sendLatency("execute_latency", time.Now())
+
+	result, err := c.db.ExecContext(ctx, stmt, args...)
+	if err != nil {
+		c.metrics.ExecuteFail.Inc(1)
+		return nil, err
+	}
+	c.metrics.ExecuteSuccess.Inc(1)
+	return result, nil
+}
+
+// CreateIfNotExists creates a new row in DB if it already doesn't exist.
+func (c *sqlConnector) CreateIfNotExists(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+) error {
+	return c.create(ctx, e, row, useCasWrite)
+}
+
+// Create creates a new row in DB, overwriting an existing row with the same
+// primary key.
+func (c *sqlConnector) Create(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+) error {
+	return c.create(ctx, e, row, !useCasWrite)
+}
+
+func (c *sqlConnector) create(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	casWrite bool,
+) error {
+	colNames, colValues, err := splitColumnNameValue(row)
+	if err != nil {
+		return err
+	}
+	stmt := c.dialect.insertStmt(e, colNames, casWrite)
+
+	result, err := c.exec(ctx, stmt, colValues...)
+	if err != nil {
+		return err
+	}
+
+	if casWrite {
+		applied, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if applied == 0 {
+			return yarpcerrors.AlreadyExistsErrorf("item already exists")
+		}
+	}
+	return nil
+}
+
+// query runs a select statement using base object and key columns
+func (c *sqlConnector) query(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+	colNamesToRead []string,
+) (*dbsql.Rows, error) {
+	defer c.sendLatency("execute_latency", time.Now())
+
+	keyColNames, keyColValues, err := splitColumnNameValue(keyCols)
+	if err != nil {
+		return nil, err
+	}
+	stmt := c.dialect.selectStmt(e, colNamesToRead, keyColNames)
+
+	rows, err := c.db.QueryContext(ctx, stmt, keyColValues...)
+	if err != nil {
+		c.metrics.ExecuteFail.Inc(1)
+		return nil, err
+	}
+	return rows, nil
+}
+
+// Get fetches a record from DB using primary keys. gocql.ErrNotFound is
+// returned if the row does not exist to keep the semantics of the
+// Cassandra connector.
+func (c *sqlConnector) Get(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) ([]base.Column, error) {
+	iter, err := c.GetAllIter(ctx, e, keyCols)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	row, err := iter.Next()
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return nil, gocql.ErrNotFound
+	}
+	return row, nil
+}
+
+// GetAll fetches all rows from DB using partition keys
+func (c *sqlConnector) GetAll(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) ([][]base.Column, error) {
+	iter, err := c.GetAllIter(ctx, e, keyCols)
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var rows [][]base.Column
+	for {
+		row, err := iter.Next()
+		if err != nil {
+			return nil, err
+		}
+		if row == nil {
+			return rows, nil
+		}
+		rows = append(rows, row)
+	}
+}
+
+// GetAllIter gives an iterator to fetch all rows from DB
+func (c *sqlConnector) GetAllIter(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) (orm.Iterator, error) {
+	colNamesToRead := e.GetColumnsToRead()
+
+	rows, err := c.query(ctx, e, keyCols, colNamesToRead)
+	if err != nil {
+		return nil, err
+	}
+	return newIterator(e, colNamesToRead, &c.metrics, rows), nil
+}
+
+// Update updates an existing row in DB. Like in Cassandra, the row is
+// created if it does not exist.
+func (c *sqlConnector) Update(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	keyCols []base.Column,
+) error {
+	cols := make([]base.Column, 0, len(keyCols)+len(row))
+	cols = append(append(cols, keyCols...), row...)
+	colNames, colValues, err := splitColumnNameValue(cols)
+	if err != nil {
+		return err
+	}
+	stmt := c.dialect.insertStmt(e, colNames, !useCasWrite)
+
+	_, err = c.exec(ctx, stmt, colValues...)
+	return err
+}
+
+// UpdateIf updates an existing row in DB if the current values of its
+// columns match the conditions.
+func (c *sqlConnector) UpdateIf(
+	ctx context.Context,
+	e *base.Definition,
+	row []base.Column,
+	conditions []base.Column,
+	keyCols []base.Column,
+) error {
+	colNames, colValues, err := splitColumnNameValue(row)
+	if err != nil {
+		return err
+	}
+	keyColNames, keyColValues, err := splitColumnNameValue(keyCols)
+	if err != nil {
+		return err
+	}
+
+	var nonNullConds []base.Column
+	var nullCondNames []string
+	for _, cond := range conditions {
+		if cond.Value == nil {
+			nullCondNames = append(nullCondNames, cond.Name)
+		} else {
+			nonNullConds = append(nonNullConds, cond)
+		}
+	}
+	condNames, condValues, err := splitColumnNameValue(nonNullConds)
+	if err != nil {
+		return err
+	}
+	stmt := c.dialect.updateStmt(
+		e, colNames, keyColNames, condNames, nullCondNames)
+
+	args := append(append(colValues, keyColValues...), condValues...)
+	result, err := c.exec(ctx, stmt, args...)
+	if err != nil {
+		return err
+	}
+
+	applied, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if applied == 0 {
+		return yarpcerrors.AbortedErrorf("update conditions not met")
+	}
+	return nil
+}
+
+// Delete deletes a record from DB using primary keys
+func (c *sqlConnector) Delete(
+	ctx context.Context,
+	e *base.Definition,
+	keyCols []base.Column,
+) error {
+	keyColNames, keyColValues, err := splitColumnNameValue(keyCols)
+	if err != nil {
+		return err
+	}
+	stmt := c.dialect.deleteStmt(e, keyColNames)
+
+	_, err = c.exec(ctx, stmt, keyColValues...)
+	return err
+}
+
+// sqlIterator implements interface Iterator for SQL databases
+type sqlIterator struct {
+	rows           *dbsql.Rows
+	tableDef       *base.Definition
+	colNamesToRead []string
+	metrics        *impl.Metrics
+}
+
+// ensure that implementation (sqlIterator) satisfies the interface
+var _ orm.Iterator = (*sqlIterator)(nil)
+
+func newIterator(
+	e *base.Definition,
+	cols []string,
+	m *impl.Metrics,
+	rows *dbsql.Rows,
+) *sqlIterator {
+	return &sqlIterator{
+		rows:           rows,
+		tableDef:       e,
+		metrics:        m,
+		colNamesToRead: cols,
+	}
+}
+
+func (iter *sqlIterator) Close() {
+	iter.rows.Close()
+}
+
+func (iter *sqlIterator) Next() ([]base.Column, error) {
+	if iter.rows.Next() {
+		result := buildResultRow(iter.tableDef, iter.colNamesToRead)
+		if err := iter.rows.Scan(result...); err != nil {
+			iter.metrics.ExecuteFail.Inc(1)
+			return nil, err
+		}
+		return getRowFromResult(iter.tableDef, iter.colNamesToRead, result)
+	}
+	// Either end-of-results or error
+	if err := iter.rows.Err(); err != nil {
+		iter.metrics.ExecuteFail.Inc(1)
+		return nil, err
+	}
+	iter.metrics.ExecuteSuccess.Inc(1)
+	return nil, nil
+}
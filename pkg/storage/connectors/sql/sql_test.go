@@ -0,0 +1,376 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	// register the sqlite3 driver used by the tests
+	_ "github.com/uber/peloton/pkg/storage/connectors/sql/sqlite"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+// testTable has partition key "id" and clustering key "ck", in
+// descending order as generated by the orm object parser
+var testTable = &base.Definition{
+	Name: "test_table",
+	Key: &base.PrimaryKey{
+		PartitionKeys: []string{"id"},
+		ClusteringKeys: []*base.ClusteringKey{
+			{Name: "ck", Descending: true},
+		},
+	},
+	ColumnToType: map[string]reflect.Type{
+		"id":   reflect.TypeOf(uint64(0)),
+		"ck":   reflect.TypeOf(uint64(0)),
+		"data": reflect.TypeOf(""),
+		"time": reflect.TypeOf(time.Time{}),
+	},
+}
+
+// uuidTable uses a time UUID as clustering key
+var uuidTable = &base.Definition{
+	Name: "uuid_table",
+	Key: &base.PrimaryKey{
+		PartitionKeys: []string{"id"},
+		ClusteringKeys: []*base.ClusteringKey{
+			{Name: "uid", Descending: true},
+		},
+	},
+	ColumnToType: map[string]reflect.Type{
+		"id":    reflect.TypeOf(""),
+		"uid":   reflect.TypeOf(gocql.UUID{}),
+		"valid": reflect.TypeOf(false),
+		"blob":  reflect.TypeOf([]byte{}),
+	},
+}
+
+type SQLConnectorTestSuite struct {
+	suite.Suite
+	ctx       context.Context
+	connector *sqlConnector
+}
+
+func (suite *SQLConnectorTestSuite) SetupTest() {
+	suite.ctx = context.Background()
+	// every connection to an in-memory SQLite database opens a new
+	// database, so restrict the connector to a single connection
+	connector, err := NewSQLConnector(
+		&Config{
+			Driver:       DriverSQLite,
+			DataSource:   ":memory:",
+			MaxOpenConns: 1,
+		},
+		tally.NoopScope,
+		testTable,
+		uuidTable,
+	)
+	suite.NoError(err)
+	suite.connector = connector.(*sqlConnector)
+}
+
+func (suite *SQLConnectorTestSuite) TearDownTest() {
+	suite.connector.db.Close()
+}
+
+func TestSQLConnector(t *testing.T) {
+	suite.Run(t, new(SQLConnectorTestSuite))
+}
+
+func testRow(id, ck uint64, data string) []base.Column {
+	return []base.Column{
+		{Name: "id", Value: id},
+		{Name: "ck", Value: ck},
+		{Name: "data", Value: data},
+	}
+}
+
+func keyRow(id, ck uint64) []base.Column {
+	return []base.Column{
+		{Name: "id", Value: id},
+		{Name: "ck", Value: ck},
+	}
+}
+
+// columnValue returns the dereferenced value of a column in the row
+func columnValue(row []base.Column, name string) interface{} {
+	for _, column := range row {
+		if column.Name == name {
+			v := reflect.ValueOf(column.Value)
+			if v.Kind() != reflect.Ptr {
+				return column.Value
+			}
+			if v.IsNil() {
+				return nil
+			}
+			return v.Elem().Interface()
+		}
+	}
+	return nil
+}
+
+// TestCreateTableStmt tests the generated DDL for both drivers
+func (suite *SQLConnectorTestSuite) TestCreateTableStmt() {
+	stmt, err := CreateTableStmt(DriverPostgres, uuidTable)
+	suite.NoError(err)
+	suite.Equal(`CREATE TABLE IF NOT EXISTS "uuid_table" (`+
+		`"blob" BYTEA, "id" TEXT, "uid" BYTEA, "valid" BOOLEAN, `+
+		`PRIMARY KEY ("id", "uid"));`, stmt)
+
+	stmt, err = CreateTableStmt(DriverSQLite, testTable)
+	suite.NoError(err)
+	suite.Equal(`CREATE TABLE IF NOT EXISTS "test_table" (`+
+		`"ck" INTEGER, "data" TEXT, "id" INTEGER, "time" TIMESTAMP, `+
+		`PRIMARY KEY ("id", "ck"));`, stmt)
+
+	_, err = CreateTableStmt("mysql", testTable)
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+}
+
+// TestInsertStmt tests the generated upsert statements
+func (suite *SQLConnectorTestSuite) TestInsertStmt() {
+	d := dialects[DriverPostgres]
+	suite.Equal(`INSERT INTO "test_table" ("id", "ck", "data") `+
+		`VALUES ($1, $2, $3) ON CONFLICT ("id", "ck") `+
+		`DO UPDATE SET "data" = excluded."data";`,
+		d.insertStmt(testTable, []string{"id", "ck", "data"}, false))
+	suite.Equal(`INSERT INTO "test_table" ("id", "ck", "data") `+
+		`VALUES ($1, $2, $3) ON CONFLICT ("id", "ck") DO NOTHING;`,
+		d.insertStmt(testTable, []string{"id", "ck", "data"}, true))
+}
+
+// TestUpdateStmt tests the generated conditional update statements
+func (suite *SQLConnectorTestSuite) TestUpdateStmt() {
+	d := dialects[DriverPostgres]
+	suite.Equal(`UPDATE "test_table" SET "data" = $1 `+
+		`WHERE "id" = $2 AND "ck" = $3 AND "data" = $4 AND "time" IS NULL;`,
+		d.updateStmt(testTable, []string{"data"}, []string{"id", "ck"},
+			[]string{"data"}, []string{"time"}))
+}
+
+// TestCreateGet tests creating a row and reading it back
+func (suite *SQLConnectorTestSuite) TestCreateGet() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(1, 10, "data10")))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Len(row, len(testTable.ColumnToType))
+	suite.Equal("data10", columnValue(row, "data"))
+	suite.Equal(int64(10), columnValue(row, "ck"))
+	suite.Nil(columnValue(row, "time"))
+
+	// Create overwrites an existing row
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(1, 10, "data10-new")))
+	row, err = suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Equal("data10-new", columnValue(row, "data"))
+}
+
+// TestGetNotFound tests that gocql.ErrNotFound is returned for a missing row
+func (suite *SQLConnectorTestSuite) TestGetNotFound() {
+	_, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.Equal(gocql.ErrNotFound, err)
+}
+
+// TestCreateIfNotExists tests that an existing row is not overwritten
+func (suite *SQLConnectorTestSuite) TestCreateIfNotExists() {
+	suite.NoError(suite.connector.CreateIfNotExists(
+		suite.ctx, testTable, testRow(1, 10, "data10")))
+
+	err := suite.connector.CreateIfNotExists(
+		suite.ctx, testTable, testRow(1, 10, "data10-new"))
+	suite.True(yarpcerrors.IsAlreadyExists(err))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Equal("data10", columnValue(row, "data"))
+}
+
+// TestGetAll tests that rows of a partition are returned in clustering
+// key order
+func (suite *SQLConnectorTestSuite) TestGetAll() {
+	for _, ck := range []uint64{20, 10, 30} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx, testTable, testRow(1, ck, "data")))
+	}
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(2, 40, "data")))
+
+	rows, err := suite.connector.GetAll(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(1)},
+	})
+	suite.NoError(err)
+	suite.Len(rows, 3)
+	for i, ck := range []int64{30, 20, 10} {
+		suite.Equal(ck, columnValue(rows[i], "ck"))
+	}
+
+	rows, err = suite.connector.GetAll(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(3)},
+	})
+	suite.NoError(err)
+	suite.Empty(rows)
+}
+
+// TestUpdate tests updating selected columns of a row
+func (suite *SQLConnectorTestSuite) TestUpdate() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(1, 10, "data10")))
+
+	now := time.Now()
+	suite.NoError(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "time", Value: now}},
+		keyRow(1, 10)))
+
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Equal("data10", columnValue(row, "data"))
+	suite.True(now.Equal(columnValue(row, "time").(time.Time)))
+
+	// update creates the row if it doesn't exist
+	suite.NoError(suite.connector.Update(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "data", Value: "data20"}},
+		keyRow(1, 20)))
+	row, err = suite.connector.Get(suite.ctx, testTable, keyRow(1, 20))
+	suite.NoError(err)
+	suite.Equal("data20", columnValue(row, "data"))
+}
+
+// TestUpdateIf tests updating columns of a row only if their current
+// values match the conditions
+func (suite *SQLConnectorTestSuite) TestUpdateIf() {
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(1, 10, "data10")))
+
+	err := suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "data", Value: "data11"}},
+		[]base.Column{{Name: "data", Value: "data12"}},
+		keyRow(1, 10))
+	suite.True(yarpcerrors.IsAborted(err))
+
+	suite.NoError(suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "data", Value: "data11"}},
+		[]base.Column{{Name: "data", Value: "data10"}},
+		keyRow(1, 10)))
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 10))
+	suite.NoError(err)
+	suite.Equal("data11", columnValue(row, "data"))
+
+	// a missing row never meets the conditions
+	err = suite.connector.UpdateIf(
+		suite.ctx,
+		testTable,
+		[]base.Column{{Name: "data", Value: "data20"}},
+		[]base.Column{{Name: "time", Value: nil}},
+		keyRow(1, 20))
+	suite.True(yarpcerrors.IsAborted(err))
+	_, err = suite.connector.Get(suite.ctx, testTable, keyRow(1, 20))
+	suite.Equal(gocql.ErrNotFound, err)
+}
+
+// TestDelete tests deleting rows by primary key and by partition key
+func (suite *SQLConnectorTestSuite) TestDelete() {
+	for _, ck := range []uint64{10, 20, 30} {
+		suite.NoError(suite.connector.Create(
+			suite.ctx, testTable, testRow(1, ck, "data")))
+	}
+
+	suite.NoError(suite.connector.Delete(suite.ctx, testTable, keyRow(1, 20)))
+	_, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, 20))
+	suite.Equal(gocql.ErrNotFound, err)
+
+	suite.NoError(suite.connector.Delete(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(1)},
+	}))
+	rows, err := suite.connector.GetAll(suite.ctx, testTable, []base.Column{
+		{Name: "id", Value: uint64(1)},
+	})
+	suite.NoError(err)
+	suite.Empty(rows)
+}
+
+// TestTimeUUIDOrder tests that time UUID clustering keys are ordered by
+// time and read back unchanged
+func (suite *SQLConnectorTestSuite) TestTimeUUIDOrder() {
+	now := time.Now()
+	var uuids []gocql.UUID
+	for i := 0; i < 3; i++ {
+		u := gocql.UUIDFromTime(now.Add(time.Duration(i) * time.Hour))
+		uuids = append(uuids, u)
+		suite.NoError(suite.connector.Create(
+			suite.ctx, uuidTable, []base.Column{
+				{Name: "id", Value: "job"},
+				{Name: "uid", Value: u},
+				{Name: "valid", Value: true},
+				{Name: "blob", Value: []byte("blob")},
+			}))
+	}
+
+	rows, err := suite.connector.GetAll(suite.ctx, uuidTable, []base.Column{
+		{Name: "id", Value: "job"},
+	})
+	suite.NoError(err)
+	suite.Len(rows, 3)
+	for i, row := range rows {
+		suite.Equal(uuids[2-i], columnValue(row, "uid"))
+		suite.Equal(true, columnValue(row, "valid"))
+		suite.Equal([]byte("blob"), columnValue(row, "blob"))
+	}
+}
+
+// TestUnsignedOverflow tests that unsigned values which don't fit in the
+// integer columns are rejected instead of being stored as negative values
+func (suite *SQLConnectorTestSuite) TestUnsignedOverflow() {
+	err := suite.connector.Create(
+		suite.ctx, testTable, testRow(1, math.MaxUint64, "data"))
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	_, err = suite.connector.Get(suite.ctx, testTable, keyRow(1, math.MaxUint64))
+	suite.True(yarpcerrors.IsInvalidArgument(err))
+
+	suite.NoError(suite.connector.Create(
+		suite.ctx, testTable, testRow(1, math.MaxInt64, "data")))
+	row, err := suite.connector.Get(suite.ctx, testTable, keyRow(1, math.MaxInt64))
+	suite.NoError(err)
+	suite.Equal(int64(math.MaxInt64), columnValue(row, "ck"))
+}
+
+// TestContextCancelled tests that operations fail on a cancelled context
+func (suite *SQLConnectorTestSuite) TestContextCancelled() {
+	ctx, cancel := context.WithCancel(suite.ctx)
+	cancel()
+	suite.Error(suite.connector.Create(ctx, testTable, testRow(1, 10, "data")))
+	_, err := suite.connector.Get(ctx, testTable, keyRow(1, 10))
+	suite.Error(err)
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sql
+
+import (
+	"strings"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+)
+
+// placeholders returns count bind parameters starting at offset
+func (d *dialect) placeholders(offset, count int) []string {
+	p := make([]string, count)
+	for i := range p {
+		p[i] = d.placeholder(offset + i)
+	}
+	return p
+}
+
+// conditions returns the `col = ?` conditions for the given columns with
+// bind parameters starting at offset
+func (d *dialect) conditions(offset int, colNames []string) string {
+	conds := make([]string, len(colNames))
+	for i, name := range colNames {
+		conds[i] = quote(name) + " = " + d.placeholder(offset+i)
+	}
+	return strings.Join(conds, " AND ")
+}
+
+// insertStmt creates an insert statement. Both PostgreSQL and SQLite support
+// the ON CONFLICT clause which is used to provide the upsert semantics of
+// Cassandra writes. If ifNotExist is set, conflicting rows are left
+// unchanged, otherwise the given non key columns are overwritten.
+func (d *dialect) insertStmt(
+	e *base.Definition,
+	colNames []string,
+	ifNotExist bool,
+) string {
+	keys := primaryKeyColumns(e)
+	isKey := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		isKey[k] = true
+	}
+
+	var updates []string
+	for _, name := range colNames {
+		if !isKey[name] {
+			updates = append(updates,
+				quote(name)+" = excluded."+quote(name))
+		}
+	}
+
+	onConflict := " ON CONFLICT (" + strings.Join(quoteAll(keys), ", ") + ")"
+	if ifNotExist || len(updates) == 0 {
+		onConflict += " DO NOTHING"
+	} else {
+		onConflict += " DO UPDATE SET " + strings.Join(updates, ", ")
+	}
+
+	return "INSERT INTO " + quote(e.Name) +
+		" (" + strings.Join(quoteAll(colNames), ", ") + ")" +
+		" VALUES (" + strings.Join(d.placeholders(0, len(colNames)), ", ") +
+		")" + onConflict + ";"
+}
+
+// updateStmt creates a statement which updates the given columns of an
+// existing row only if the columns of the conditions have the expected
+// values. Columns expected to be NULL are matched with IS NULL, as NULL is
+// never equal to a bind parameter.
+func (d *dialect) updateStmt(
+	e *base.Definition,
+	colNames []string,
+	keyColNames []string,
+	condNames []string,
+	nullCondNames []string,
+) string {
+	updates := make([]string, len(colNames))
+	for i, name := range colNames {
+		updates[i] = quote(name) + " = " + d.placeholder(i)
+	}
+
+	conds := []string{d.conditions(len(colNames), keyColNames)}
+	if len(condNames) > 0 {
+		conds = append(conds,
+			d.conditions(len(colNames)+len(keyColNames), condNames))
+	}
+	for _, name := range nullCondNames {
+		conds = append(conds, quote(name)+" IS NULL")
+	}
+
+	return "UPDATE " + quote(e.Name) +
+		" SET " + strings.Join(updates, ", ") +
+		" WHERE " + strings.Join(conds, " AND ") + ";"
+}
+
+// selectStmt creates a select statement. Rows are ordered by the clustering
+// keys of the table like they would be in a Cassandra partition.
+func (d *dialect) selectStmt(
+	e *base.Definition,
+	colNames []string,
+	keyColNames []string,
+) string {
+	stmt := "SELECT " + strings.Join(quoteAll(colNames), ", ") +
+		" FROM " + quote(e.Name)
+	if len(keyColNames) > 0 {
+		stmt += " WHERE " + d.conditions(0, keyColNames)
+	}
+
+	if len(e.Key.ClusteringKeys) > 0 {
+		order := make([]string, len(e.Key.ClusteringKeys))
+		for i, ck := range e.Key.ClusteringKeys {
+			order[i] = quote(ck.Name)
+			if ck.Descending {
+				order[i] += " DESC"
+			} else {
+				order[i] += " ASC"
+			}
+		}
+		stmt += " ORDER BY " + strings.Join(order, ", ")
+	}
+	return stmt + ";"
+}
+
+// deleteStmt creates a delete statement
+func (d *dialect) deleteStmt(e *base.Definition, keyColNames []string) string {
+	return "DELETE FROM " + quote(e.Name) +
+		" WHERE " + d.conditions(0, keyColNames) + ";"
+}
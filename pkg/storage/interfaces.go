@@ -228,6 +228,16 @@ type UpdateStore interface {
 		updateState update.State,
 	) error
 
+	// AddJobUpdateCanaryEvent adds an update state change event for a job,
+	// which records the result of the canary phase of the update
+	AddJobUpdateCanaryEvent(
+		ctx context.Context,
+		updateID *peloton.UpdateID,
+		updateType models.WorkflowType,
+		updateState update.State,
+		canaryState update.CanaryState,
+	) error
+
 	// GetJobUpdateEvents gets update state events for a job
 	// in descending create timestamp order
 	GetJobUpdateEvents(
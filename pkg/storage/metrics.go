@@ -86,14 +86,18 @@ type JobMetrics struct {
 // OrmJobMetrics tracks counters for job related tables accessed through ORM layer
 type OrmJobMetrics struct {
 	// job_index
-	JobIndexCreate     tally.Counter
-	JobIndexCreateFail tally.Counter
-	JobIndexGet        tally.Counter
-	JobIndexGetFail    tally.Counter
-	JobIndexUpdate     tally.Counter
-	JobIndexUpdateFail tally.Counter
-	JobIndexDelete     tally.Counter
-	JobIndexDeleteFail tally.Counter
+	JobIndexCreate       tally.Counter
+	JobIndexCreateFail   tally.Counter
+	JobIndexGet          tally.Counter
+	JobIndexGetFail      tally.Counter
+	JobIndexUpdate       tally.Counter
+	JobIndexUpdateFail   tally.Counter
+	JobIndexDelete       tally.Counter
+	JobIndexDeleteFail   tally.Counter
+	JobIndexQuery        tally.Counter
+	JobIndexQueryFail    tally.Counter
+	JobIndexBackfill     tally.Counter
+	JobIndexBackfillFail tally.Counter
 
 	// job_name_to_id
 	JobNameToIDCreate     tally.Counter
@@ -118,6 +122,8 @@ type OrmJobMetrics struct {
 	SecretInfoUpdateFail tally.Counter
 	SecretInfoDelete     tally.Counter
 	SecretInfoDeleteFail tally.Counter
+	SecretInfoRotate     tally.Counter
+	SecretInfoRotateFail tally.Counter
 }
 
 // TaskMetrics is a struct for tracking all the task related counters in the storage layer
@@ -296,6 +302,60 @@ type OrmTaskMetrics struct {
 	PodEventsGetFail tally.Counter
 }
 
+// OrmHostMetrics tracks counters for host related tables
+type OrmHostMetrics struct {
+	MaintenanceWindowCreate     tally.Counter
+	MaintenanceWindowCreateFail tally.Counter
+	MaintenanceWindowUpdate     tally.Counter
+	MaintenanceWindowUpdateFail tally.Counter
+	MaintenanceWindowGetAll     tally.Counter
+	MaintenanceWindowGetAllFail tally.Counter
+	MaintenanceWindowDelete     tally.Counter
+	MaintenanceWindowDeleteFail tally.Counter
+	MaintenanceWindowPurge      tally.Counter
+	MaintenanceWindowPurgeFail  tally.Counter
+}
+
+// OrmAuditMetrics tracks counters for audit related tables
+type OrmAuditMetrics struct {
+	AuditLogCreate     tally.Counter
+	AuditLogCreateFail tally.Counter
+	AuditLogQuery      tally.Counter
+	AuditLogQueryFail  tally.Counter
+}
+
+// OrmCronMetrics tracks counters for cron job related tables
+type OrmCronMetrics struct {
+	CronJobCreate        tally.Counter
+	CronJobCreateFail    tally.Counter
+	CronJobUpdate        tally.Counter
+	CronJobUpdateFail    tally.Counter
+	CronJobGet           tally.Counter
+	CronJobGetFail       tally.Counter
+	CronJobGetAll        tally.Counter
+	CronJobGetAllFail    tally.Counter
+	CronJobDelete        tally.Counter
+	CronJobDeleteFail    tally.Counter
+	CronJobRunCreate     tally.Counter
+	CronJobRunCreateFail tally.Counter
+	CronJobRunGetAll     tally.Counter
+	CronJobRunGetAllFail tally.Counter
+}
+
+// OrmDagMetrics tracks counters for the DAG of batch jobs table
+type OrmDagMetrics struct {
+	DagCreate              tally.Counter
+	DagCreateFail          tally.Counter
+	DagUpdate              tally.Counter
+	DagUpdateFail          tally.Counter
+	DagUpdateGoalState     tally.Counter
+	DagUpdateGoalStateFail tally.Counter
+	DagGet                 tally.Counter
+	DagGetFail             tally.Counter
+	DagGetAll              tally.Counter
+	DagGetAllFail          tally.Counter
+}
+
 // Metrics is a struct for tracking all the general purpose counters that have relevance to the storage
 // layer, i.e. how many jobs and tasks were created/deleted in the storage layer
 type Metrics struct {
@@ -309,6 +369,10 @@ type Metrics struct {
 	WorkflowMetrics       *WorkflowMetrics
 	OrmJobMetrics         *OrmJobMetrics
 	OrmTaskMetrics        *OrmTaskMetrics
+	OrmHostMetrics        *OrmHostMetrics
+	OrmAuditMetrics       *OrmAuditMetrics
+	OrmCronMetrics        *OrmCronMetrics
+	OrmDagMetrics         *OrmDagMetrics
 }
 
 // NewMetrics returns a new Metrics struct, with all metrics initialized and rooted at the given tally.Scope
@@ -576,15 +640,49 @@ func NewMetrics(scope tally.Scope) *Metrics {
 	secretInfoFailScope := secretInfoScope.Tagged(
 		map[string]string{"result": "fail"})
 
+	maintenanceWindowScope := ormScope.SubScope("maintenance_windows")
+	maintenanceWindowSuccessScope := maintenanceWindowScope.Tagged(
+		map[string]string{"result": "success"})
+	maintenanceWindowFailScope := maintenanceWindowScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	auditLogScope := ormScope.SubScope("audit_log")
+	auditLogSuccessScope := auditLogScope.Tagged(
+		map[string]string{"result": "success"})
+	auditLogFailScope := auditLogScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	cronJobScope := ormScope.SubScope("cron_jobs")
+	cronJobSuccessScope := cronJobScope.Tagged(
+		map[string]string{"result": "success"})
+	cronJobFailScope := cronJobScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	cronJobRunScope := ormScope.SubScope("cron_job_runs")
+	cronJobRunSuccessScope := cronJobRunScope.Tagged(
+		map[string]string{"result": "success"})
+	cronJobRunFailScope := cronJobRunScope.Tagged(
+		map[string]string{"result": "fail"})
+
+	dagScope := ormScope.SubScope("dags")
+	dagSuccessScope := dagScope.Tagged(
+		map[string]string{"result": "success"})
+	dagFailScope := dagScope.Tagged(
+		map[string]string{"result": "fail"})
+
 	ormJobMetrics := &OrmJobMetrics{
-		JobIndexCreate:     jobIndexSuccessScope.Counter("create"),
-		JobIndexCreateFail: jobIndexFailScope.Counter("create"),
-		JobIndexGet:        jobIndexSuccessScope.Counter("get"),
-		JobIndexGetFail:    jobIndexFailScope.Counter("get"),
-		JobIndexUpdate:     jobIndexSuccessScope.Counter("update"),
-		JobIndexUpdateFail: jobIndexFailScope.Counter("update"),
-		JobIndexDelete:     jobIndexSuccessScope.Counter("delete"),
-		JobIndexDeleteFail: jobIndexFailScope.Counter("delete"),
+		JobIndexCreate:       jobIndexSuccessScope.Counter("create"),
+		JobIndexCreateFail:   jobIndexFailScope.Counter("create"),
+		JobIndexGet:          jobIndexSuccessScope.Counter("get"),
+		JobIndexGetFail:      jobIndexFailScope.Counter("get"),
+		JobIndexUpdate:       jobIndexSuccessScope.Counter("update"),
+		JobIndexUpdateFail:   jobIndexFailScope.Counter("update"),
+		JobIndexDelete:       jobIndexSuccessScope.Counter("delete"),
+		JobIndexDeleteFail:   jobIndexFailScope.Counter("delete"),
+		JobIndexQuery:        jobIndexSuccessScope.Counter("query"),
+		JobIndexQueryFail:    jobIndexFailScope.Counter("query"),
+		JobIndexBackfill:     jobIndexSuccessScope.Counter("backfill"),
+		JobIndexBackfillFail: jobIndexFailScope.Counter("backfill"),
 
 		JobNameToIDCreate:     jobNameToIDSuccessScope.Counter("create"),
 		JobNameToIDCreateFail: jobNameToIDFailScope.Counter("create"),
@@ -606,6 +704,8 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		SecretInfoUpdateFail: secretInfoFailScope.Counter("update"),
 		SecretInfoDelete:     secretInfoSuccessScope.Counter("delete"),
 		SecretInfoDeleteFail: secretInfoFailScope.Counter("delete"),
+		SecretInfoRotate:     secretInfoSuccessScope.Counter("rotate"),
+		SecretInfoRotateFail: secretInfoFailScope.Counter("rotate"),
 	}
 
 	ormTaskMetrics := &OrmTaskMetrics{
@@ -615,6 +715,56 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		PodEventsGetFail: podEventsFailScope.Counter("get"),
 	}
 
+	ormHostMetrics := &OrmHostMetrics{
+		MaintenanceWindowCreate:     maintenanceWindowSuccessScope.Counter("create"),
+		MaintenanceWindowCreateFail: maintenanceWindowFailScope.Counter("create"),
+		MaintenanceWindowUpdate:     maintenanceWindowSuccessScope.Counter("update"),
+		MaintenanceWindowUpdateFail: maintenanceWindowFailScope.Counter("update"),
+		MaintenanceWindowGetAll:     maintenanceWindowSuccessScope.Counter("get_all"),
+		MaintenanceWindowGetAllFail: maintenanceWindowFailScope.Counter("get_all"),
+		MaintenanceWindowDelete:     maintenanceWindowSuccessScope.Counter("delete"),
+		MaintenanceWindowDeleteFail: maintenanceWindowFailScope.Counter("delete"),
+		MaintenanceWindowPurge:      maintenanceWindowSuccessScope.Counter("purge"),
+		MaintenanceWindowPurgeFail:  maintenanceWindowFailScope.Counter("purge"),
+	}
+
+	ormAuditMetrics := &OrmAuditMetrics{
+		AuditLogCreate:     auditLogSuccessScope.Counter("create"),
+		AuditLogCreateFail: auditLogFailScope.Counter("create"),
+		AuditLogQuery:      auditLogSuccessScope.Counter("query"),
+		AuditLogQueryFail:  auditLogFailScope.Counter("query"),
+	}
+
+	ormCronMetrics := &OrmCronMetrics{
+		CronJobCreate:        cronJobSuccessScope.Counter("create"),
+		CronJobCreateFail:    cronJobFailScope.Counter("create"),
+		CronJobUpdate:        cronJobSuccessScope.Counter("update"),
+		CronJobUpdateFail:    cronJobFailScope.Counter("update"),
+		CronJobGet:           cronJobSuccessScope.Counter("get"),
+		CronJobGetFail:       cronJobFailScope.Counter("get"),
+		CronJobGetAll:        cronJobSuccessScope.Counter("get_all"),
+		CronJobGetAllFail:    cronJobFailScope.Counter("get_all"),
+		CronJobDelete:        cronJobSuccessScope.Counter("delete"),
+		CronJobDeleteFail:    cronJobFailScope.Counter("delete"),
+		CronJobRunCreate:     cronJobRunSuccessScope.Counter("create"),
+		CronJobRunCreateFail: cronJobRunFailScope.Counter("create"),
+		CronJobRunGetAll:     cronJobRunSuccessScope.Counter("get_all"),
+		CronJobRunGetAllFail: cronJobRunFailScope.Counter("get_all"),
+	}
+
+	ormDagMetrics := &OrmDagMetrics{
+		DagCreate:              dagSuccessScope.Counter("create"),
+		DagCreateFail:          dagFailScope.Counter("create"),
+		DagUpdate:              dagSuccessScope.Counter("update"),
+		DagUpdateFail:          dagFailScope.Counter("update"),
+		DagUpdateGoalState:     dagSuccessScope.Counter("update_goal_state"),
+		DagUpdateGoalStateFail: dagFailScope.Counter("update_goal_state"),
+		DagGet:                 dagSuccessScope.Counter("get"),
+		DagGetFail:             dagFailScope.Counter("get"),
+		DagGetAll:              dagSuccessScope.Counter("get_all"),
+		DagGetAllFail:          dagFailScope.Counter("get_all"),
+	}
+
 	metrics := &Metrics{
 		JobMetrics:            jobMetrics,
 		TaskMetrics:           taskMetrics,
@@ -626,6 +776,10 @@ func NewMetrics(scope tally.Scope) *Metrics {
 		WorkflowMetrics:       workflowMetrics,
 		OrmJobMetrics:         ormJobMetrics,
 		OrmTaskMetrics:        ormTaskMetrics,
+		OrmHostMetrics:        ormHostMetrics,
+		OrmAuditMetrics:       ormAuditMetrics,
+		OrmCronMetrics:        ormCronMetrics,
+		OrmDagMetrics:         ormDagMetrics,
 	}
 
 	return metrics
@@ -0,0 +1,223 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+)
+
+// auditLogDayFormat is the format of the day partition of audit_log table
+const auditLogDayFormat = "2006-01-02"
+
+// init adds an AuditLogObject instance to the global list of storage objects
+func init() {
+	Objs = append(Objs, &AuditLogObject{})
+}
+
+// AuditLogObject corresponds to a row in audit_log table.
+type AuditLogObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=audit_log, primaryKey=((day), event_time)"`
+
+	// UTC day the call was received, the partition of the record
+	Day string `column:"name=day"`
+	// Time the call was received
+	EventTime gocql.UUID `column:"name=event_time"`
+	// Component which handled the call
+	Component string `column:"name=component"`
+	// Name of the user who made the call
+	Username string `column:"name=username"`
+	// Procedure which was called
+	Procedure string `column:"name=procedure"`
+	// JobID of the job targeted by the call
+	JobID string `column:"name=job_id"`
+	// Summary of the request
+	Summary string `column:"name=summary"`
+	// Result of the call
+	Result string `column:"name=result"`
+	// Error message of the call
+	Message string `column:"name=message"`
+	// Time taken to handle the call in milliseconds
+	LatencyMs uint64 `column:"name=latency_ms"`
+}
+
+// AuditLogFilter selects the records returned by AuditLogOps.Query
+type AuditLogFilter struct {
+	// Records received in [From, To] are returned
+	From time.Time
+	To   time.Time
+	// Only records of this job are returned if set
+	JobID string
+	// Only records of this user are returned if set
+	Username string
+	// Maximum number of records returned, unlimited if 0
+	Limit uint32
+}
+
+// AuditLogOps provides methods for manipulating audit_log table.
+type AuditLogOps interface {
+	// Create inserts a row in the table.
+	Create(ctx context.Context, record *audit.AuditRecord) error
+
+	// Query retrieves the rows matching the filter from the table,
+	// most recent first.
+	Query(
+		ctx context.Context,
+		filter *AuditLogFilter,
+	) ([]*audit.AuditRecord, error)
+}
+
+// ensure that default implementation (auditLogOps) satisfies the interface
+var _ AuditLogOps = (*auditLogOps)(nil)
+
+// auditLogOps implements AuditLogOps using a particular Store
+type auditLogOps struct {
+	store *Store
+}
+
+// NewAuditLogOps constructs an AuditLogOps object for provided Store.
+func NewAuditLogOps(s *Store) AuditLogOps {
+	return &auditLogOps{store: s}
+}
+
+// newAuditLogObject creates an AuditLogObject from an audit record
+func newAuditLogObject(record *audit.AuditRecord) (*AuditLogObject, error) {
+	eventTime, err := time.Parse(time.RFC3339Nano, record.GetTime())
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse audit record time")
+	}
+
+	return &AuditLogObject{
+		Day:       eventTime.UTC().Format(auditLogDayFormat),
+		EventTime: gocql.UUIDFromTime(eventTime),
+		Component: record.GetComponent(),
+		Username:  record.GetUsername(),
+		Procedure: record.GetProcedure(),
+		JobID:     record.GetJobId().GetValue(),
+		Summary:   record.GetSummary(),
+		Result:    record.GetResult(),
+		Message:   record.GetMessage(),
+		LatencyMs: record.GetLatencyMs(),
+	}, nil
+}
+
+func (a *AuditLogObject) toRecord() *audit.AuditRecord {
+	record := &audit.AuditRecord{
+		Time:      a.EventTime.Time().UTC().Format(time.RFC3339Nano),
+		Component: a.Component,
+		Username:  a.Username,
+		Procedure: a.Procedure,
+		Summary:   a.Summary,
+		Result:    a.Result,
+		Message:   a.Message,
+		LatencyMs: a.LatencyMs,
+	}
+	if len(a.JobID) != 0 {
+		record.JobId = &peloton.JobID{Value: a.JobID}
+	}
+	return record
+}
+
+// auditLogDay returns the start of the UTC day of a time
+func auditLogDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// matches returns if the object is selected by the filter,
+// ignoring its time range
+func (a *AuditLogObject) matches(filter *AuditLogFilter) bool {
+	if len(filter.JobID) != 0 && filter.JobID != a.JobID {
+		return false
+	}
+	if len(filter.Username) != 0 && filter.Username != a.Username {
+		return false
+	}
+	return true
+}
+
+// Create creates an AuditLogObject in db
+func (d *auditLogOps) Create(
+	ctx context.Context,
+	record *audit.AuditRecord,
+) error {
+	obj, err := newAuditLogObject(record)
+	if err != nil {
+		d.store.metrics.OrmAuditMetrics.AuditLogCreateFail.Inc(1)
+		return err
+	}
+
+	if err = d.store.oClient.Create(ctx, obj); err != nil {
+		d.store.metrics.OrmAuditMetrics.AuditLogCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmAuditMetrics.AuditLogCreate.Inc(1)
+	return nil
+}
+
+// Query reads the day partitions covering the time range of the filter
+// from db, starting with the most recent one, and returns the matching
+// records
+func (d *auditLogOps) Query(
+	ctx context.Context,
+	filter *AuditLogFilter,
+) ([]*audit.AuditRecord, error) {
+	firstDay := auditLogDay(filter.From)
+
+	var records []*audit.AuditRecord
+	for day := auditLogDay(filter.To); !day.Before(firstDay); day = day.AddDate(0, 0, -1) {
+		objs, err := d.store.oClient.GetAll(ctx, &AuditLogObject{
+			Day: day.Format(auditLogDayFormat),
+		})
+		if err != nil {
+			d.store.metrics.OrmAuditMetrics.AuditLogQueryFail.Inc(1)
+			return nil, err
+		}
+
+		for _, obj := range objs {
+			auditLogObj := obj.(*AuditLogObject)
+			eventTime := auditLogObj.EventTime.Time()
+			if eventTime.After(filter.To) {
+				continue
+			}
+			// rows are ordered most recent first
+			if eventTime.Before(filter.From) {
+				break
+			}
+			if !auditLogObj.matches(filter) {
+				continue
+			}
+
+			records = append(records, auditLogObj.toRecord())
+			if filter.Limit != 0 && uint32(len(records)) >= filter.Limit {
+				d.store.metrics.OrmAuditMetrics.AuditLogQuery.Inc(1)
+				return records, nil
+			}
+		}
+	}
+
+	d.store.metrics.OrmAuditMetrics.AuditLogQuery.Inc(1)
+	return records, nil
+}
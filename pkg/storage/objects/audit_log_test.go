@@ -0,0 +1,155 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/audit"
+	"github.com/uber/peloton/.gen/peloton/api/v1alpha/peloton"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type AuditLogObjectTestSuite struct {
+	suite.Suite
+	now time.Time
+}
+
+func (s *AuditLogObjectTestSuite) SetupTest() {
+	s.now = time.Date(2019, 1, 2, 1, 0, 0, 0, time.UTC)
+}
+
+func TestAuditLogObjectSuite(t *testing.T) {
+	suite.Run(t, new(AuditLogObjectTestSuite))
+}
+
+// newRecord returns an audit record of a call received at the
+// given offset from now
+func (s *AuditLogObjectTestSuite) newRecord(
+	offset time.Duration,
+	username string,
+	jobID string,
+) *audit.AuditRecord {
+	record := &audit.AuditRecord{
+		Time:      s.now.Add(offset).Format(time.RFC3339Nano),
+		Component: "peloton-jobmgr",
+		Username:  username,
+		Procedure: "peloton.api.v1alpha.job.stateless.svc.JobService::StopJob",
+		Summary:   "job_id=" + jobID,
+		Result:    "OK",
+		LatencyMs: 10,
+	}
+	if len(jobID) != 0 {
+		record.JobId = &peloton.JobID{Value: jobID}
+	}
+	return record
+}
+
+// TestCreateQuery tests querying audit records across day partitions
+// in the in-memory store
+func (s *AuditLogObjectTestSuite) TestCreateQuery() {
+	store, err := NewMemoryStore(tally.NoopScope)
+	s.NoError(err)
+	ops := NewAuditLogOps(store)
+	ctx := context.Background()
+
+	job1 := uuid.New()
+	job2 := uuid.New()
+
+	// records of the previous day, the same day and the next day
+	records := []*audit.AuditRecord{
+		s.newRecord(-2*time.Hour, "user1", job1),
+		s.newRecord(-time.Minute, "user2", job2),
+		s.newRecord(0, "user1", job2),
+		s.newRecord(time.Minute, "user1", ""),
+		s.newRecord(24*time.Hour, "user1", job1),
+	}
+	for _, record := range records {
+		s.NoError(ops.Create(ctx, record))
+	}
+
+	result, err := ops.Query(ctx, &AuditLogFilter{
+		From: s.now.Add(-3 * time.Hour),
+		To:   s.now.Add(time.Hour),
+	})
+	s.NoError(err)
+	s.Equal([]*audit.AuditRecord{
+		records[3], records[2], records[1], records[0],
+	}, result)
+
+	result, err = ops.Query(ctx, &AuditLogFilter{
+		From:     s.now.Add(-3 * time.Hour),
+		To:       s.now.Add(48 * time.Hour),
+		Username: "user1",
+		JobID:    job1,
+	})
+	s.NoError(err)
+	s.Equal([]*audit.AuditRecord{records[4], records[0]}, result)
+
+	result, err = ops.Query(ctx, &AuditLogFilter{
+		From:  s.now.Add(-3 * time.Hour),
+		To:    s.now,
+		Limit: 2,
+	})
+	s.NoError(err)
+	s.Equal([]*audit.AuditRecord{records[2], records[1]}, result)
+
+	result, err = ops.Query(ctx, &AuditLogFilter{
+		From: s.now.Add(2 * time.Hour),
+		To:   s.now.Add(3 * time.Hour),
+	})
+	s.NoError(err)
+	s.Empty(result)
+}
+
+// TestCreateInvalidTime tests that records with an invalid time
+// are not created
+func (s *AuditLogObjectTestSuite) TestCreateInvalidTime() {
+	store, err := NewMemoryStore(tally.NoopScope)
+	s.NoError(err)
+	ops := NewAuditLogOps(store)
+
+	s.Error(ops.Create(context.Background(), &audit.AuditRecord{
+		Time: "yesterday",
+	}))
+}
+
+// TestAuditLogOpsFail tests failure cases due to ORM Client errors
+func (s *AuditLogObjectTestSuite) TestAuditLogOpsFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	ops := NewAuditLogOps(mockStore)
+	ctx := context.Background()
+
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+
+	s.EqualError(ops.Create(ctx, s.newRecord(0, "user1", "")), "create failed")
+	_, err := ops.Query(ctx, &AuditLogFilter{From: s.now, To: s.now})
+	s.EqualError(err, "getall failed")
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// cronJobBucket is the partition all the cron jobs are stored in.
+// There are only a few hundred cron jobs at most.
+const cronJobBucket = 0
+
+// init adds a CronJobObject instance to the global list of storage objects
+func init() {
+	Objs = append(Objs, &CronJobObject{})
+}
+
+// CronJobObject corresponds to a row in cron_jobs table.
+type CronJobObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=cron_jobs, primaryKey=((bucket), name)"`
+
+	// Partition of the cron job, always cronJobBucket
+	Bucket int `column:"name=bucket"`
+	// Name of the cron job
+	Name string `column:"name=name"`
+	// Serialized cron job info
+	Info []byte `column:"name=info"`
+	// Last time the cron job was written
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// CronJobOps provides methods for manipulating cron_jobs table.
+type CronJobOps interface {
+	// Create inserts a row in the table.
+	Create(ctx context.Context, info *cron.CronJobInfo) error
+
+	// Update overwrites a row in the table.
+	Update(ctx context.Context, info *cron.CronJobInfo) error
+
+	// Get retrieves a row from the table.
+	Get(ctx context.Context, name string) (*cron.CronJobInfo, error)
+
+	// GetAll retrieves all the rows from the table.
+	GetAll(ctx context.Context) ([]*cron.CronJobInfo, error)
+
+	// Delete removes a row from the table.
+	Delete(ctx context.Context, name string) error
+}
+
+// ensure that default implementation (cronJobOps) satisfies the interface
+var _ CronJobOps = (*cronJobOps)(nil)
+
+// cronJobOps implements CronJobOps using a particular Store
+type cronJobOps struct {
+	store *Store
+}
+
+// NewCronJobOps constructs a CronJobOps object for provided Store.
+func NewCronJobOps(s *Store) CronJobOps {
+	return &cronJobOps{store: s}
+}
+
+// newCronJobObject creates a CronJobObject from a cron job info
+func newCronJobObject(info *cron.CronJobInfo) (*CronJobObject, error) {
+	buffer, err := proto.Marshal(info)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to marshal cron job info")
+	}
+	return &CronJobObject{
+		Bucket:     cronJobBucket,
+		Name:       info.GetConfig().GetName(),
+		Info:       buffer,
+		UpdateTime: time.Now().UTC(),
+	}, nil
+}
+
+func (c *CronJobObject) toInfo() (*cron.CronJobInfo, error) {
+	info := &cron.CronJobInfo{}
+	err := proto.Unmarshal(c.Info, info)
+	return info, err
+}
+
+// Create creates a CronJobObject in db
+func (d *cronJobOps) Create(
+	ctx context.Context,
+	info *cron.CronJobInfo,
+) error {
+	obj, err := newCronJobObject(info)
+	if err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobCreateFail.Inc(1)
+		return err
+	}
+
+	if err = d.store.oClient.CreateIfNotExists(ctx, obj); err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmCronMetrics.CronJobCreate.Inc(1)
+	return nil
+}
+
+// Update updates a CronJobObject in db
+func (d *cronJobOps) Update(
+	ctx context.Context,
+	info *cron.CronJobInfo,
+) error {
+	obj, err := newCronJobObject(info)
+	if err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobUpdateFail.Inc(1)
+		return err
+	}
+
+	if err = d.store.oClient.Update(
+		ctx, obj, "Info", "UpdateTime"); err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobUpdateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmCronMetrics.CronJobUpdate.Inc(1)
+	return nil
+}
+
+// Get gets a CronJobObject from db
+func (d *cronJobOps) Get(
+	ctx context.Context,
+	name string,
+) (*cron.CronJobInfo, error) {
+	obj := &CronJobObject{
+		Bucket: cronJobBucket,
+		Name:   name,
+	}
+
+	if err := d.store.oClient.Get(ctx, obj); err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobGetFail.Inc(1)
+		return nil, err
+	}
+
+	info, err := obj.toInfo()
+	if err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobGetFail.Inc(1)
+		return nil, errors.Wrap(err, "Failed to unmarshal cron job info")
+	}
+
+	d.store.metrics.OrmCronMetrics.CronJobGet.Inc(1)
+	return info, nil
+}
+
+// GetAll gets all the cron jobs from db
+func (d *cronJobOps) GetAll(
+	ctx context.Context,
+) ([]*cron.CronJobInfo, error) {
+	objs, err := d.store.oClient.GetAll(ctx, &CronJobObject{
+		Bucket: cronJobBucket,
+	})
+	if err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	var infos []*cron.CronJobInfo
+	for _, obj := range objs {
+		info, err := obj.(*CronJobObject).toInfo()
+		if err != nil {
+			d.store.metrics.OrmCronMetrics.CronJobGetAllFail.Inc(1)
+			return nil, errors.Wrap(err, "Failed to unmarshal cron job info")
+		}
+		infos = append(infos, info)
+	}
+
+	d.store.metrics.OrmCronMetrics.CronJobGetAll.Inc(1)
+	return infos, nil
+}
+
+// Delete deletes a CronJobObject from db
+func (d *cronJobOps) Delete(
+	ctx context.Context,
+	name string,
+) error {
+	obj := &CronJobObject{
+		Bucket: cronJobBucket,
+		Name:   name,
+	}
+
+	if err := d.store.oClient.Delete(ctx, obj); err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobDeleteFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmCronMetrics.CronJobDelete.Inc(1)
+	return nil
+}
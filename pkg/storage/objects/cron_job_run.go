@@ -0,0 +1,159 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gocql/gocql"
+	"github.com/pkg/errors"
+)
+
+// init adds a CronJobRunObject instance to the global list of storage objects
+func init() {
+	Objs = append(Objs, &CronJobRunObject{})
+}
+
+// CronJobRunObject corresponds to a row in cron_job_runs table.
+type CronJobRunObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=cron_job_runs, primaryKey=((name), run_time)"`
+
+	// Name of the cron job
+	Name string `column:"name=name"`
+	// Time the run was triggered
+	RunTime gocql.UUID `column:"name=run_time"`
+	// Reason the run was triggered
+	Trigger string `column:"name=trigger"`
+	// Outcome of the run
+	Result string `column:"name=result"`
+	// JobID of the batch job created for the run
+	JobID string `column:"name=job_id"`
+	// Details on why the run was skipped or failed
+	Message string `column:"name=message"`
+}
+
+// CronJobRunOps provides methods for manipulating cron_job_runs table.
+type CronJobRunOps interface {
+	// Create inserts a row in the table.
+	Create(ctx context.Context, name string, run *cron.CronRun) error
+
+	// GetAll retrieves the rows of a cron job from the table, most
+	// recent first. All the rows are returned if limit is 0.
+	GetAll(
+		ctx context.Context,
+		name string,
+		limit uint32,
+	) ([]*cron.CronRun, error)
+}
+
+// ensure that default implementation (cronJobRunOps) satisfies the interface
+var _ CronJobRunOps = (*cronJobRunOps)(nil)
+
+// cronJobRunOps implements CronJobRunOps using a particular Store
+type cronJobRunOps struct {
+	store *Store
+}
+
+// NewCronJobRunOps constructs a CronJobRunOps object for provided Store.
+func NewCronJobRunOps(s *Store) CronJobRunOps {
+	return &cronJobRunOps{store: s}
+}
+
+// newCronJobRunObject creates a CronJobRunObject from a run of a cron job
+func newCronJobRunObject(
+	name string,
+	run *cron.CronRun,
+) (*CronJobRunObject, error) {
+	runTime, err := time.Parse(time.RFC3339Nano, run.GetTime())
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse cron run time")
+	}
+
+	return &CronJobRunObject{
+		Name:    name,
+		RunTime: gocql.UUIDFromTime(runTime),
+		Trigger: run.GetTrigger().String(),
+		Result:  run.GetResult().String(),
+		JobID:   run.GetJobId().GetValue(),
+		Message: run.GetMessage(),
+	}, nil
+}
+
+func (c *CronJobRunObject) toRun() *cron.CronRun {
+	run := &cron.CronRun{
+		Time:    c.RunTime.Time().UTC().Format(time.RFC3339Nano),
+		Trigger: cron.RunTrigger(cron.RunTrigger_value[c.Trigger]),
+		Result:  cron.RunResult(cron.RunResult_value[c.Result]),
+		Message: c.Message,
+	}
+	if len(c.JobID) != 0 {
+		run.JobId = &peloton.JobID{Value: c.JobID}
+	}
+	return run
+}
+
+// Create creates a CronJobRunObject in db
+func (d *cronJobRunOps) Create(
+	ctx context.Context,
+	name string,
+	run *cron.CronRun,
+) error {
+	obj, err := newCronJobRunObject(name, run)
+	if err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobRunCreateFail.Inc(1)
+		return err
+	}
+
+	if err = d.store.oClient.Create(ctx, obj); err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobRunCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmCronMetrics.CronJobRunCreate.Inc(1)
+	return nil
+}
+
+// GetAll gets the most recent runs of a cron job from db
+func (d *cronJobRunOps) GetAll(
+	ctx context.Context,
+	name string,
+	limit uint32,
+) ([]*cron.CronRun, error) {
+	objs, err := d.store.oClient.GetAll(ctx, &CronJobRunObject{
+		Name: name,
+	})
+	if err != nil {
+		d.store.metrics.OrmCronMetrics.CronJobRunGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	var runs []*cron.CronRun
+	for _, obj := range objs {
+		runs = append(runs, obj.(*CronJobRunObject).toRun())
+		if limit != 0 && uint32(len(runs)) >= limit {
+			break
+		}
+	}
+
+	d.store.metrics.OrmCronMetrics.CronJobRunGetAll.Inc(1)
+	return runs, nil
+}
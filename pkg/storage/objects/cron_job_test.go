@@ -0,0 +1,175 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/cron"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type CronJobObjectTestSuite struct {
+	suite.Suite
+	info *cron.CronJobInfo
+}
+
+func (s *CronJobObjectTestSuite) SetupTest() {
+	s.info = &cron.CronJobInfo{
+		Config: &cron.CronJobConfig{
+			Name:            "nightly",
+			Schedule:        "0 2 * * *",
+			CollisionPolicy: cron.CollisionPolicy_KILL_PREVIOUS,
+			JobConfig: &job.JobConfig{
+				Name:          "nightly",
+				Type:          job.JobType_BATCH,
+				InstanceCount: 10,
+			},
+		},
+		CreateTime: "2019-01-01T10:00:00Z",
+	}
+}
+
+func TestCronJobObjectSuite(t *testing.T) {
+	suite.Run(t, new(CronJobObjectTestSuite))
+}
+
+// TestCreateUpdateGetDelete tests the lifecycle of a cron job
+// in the in-memory store
+func (s *CronJobObjectTestSuite) TestCreateUpdateGetDelete() {
+	store, err := NewMemoryStore(tally.NoopScope)
+	s.NoError(err)
+	ops := NewCronJobOps(store)
+	ctx := context.Background()
+
+	s.NoError(ops.Create(ctx, s.info))
+
+	// cron job names are unique
+	s.Error(ops.Create(ctx, s.info))
+
+	info, err := ops.Get(ctx, "nightly")
+	s.NoError(err)
+	s.Equal(s.info, info)
+
+	s.info.LastScheduleTime = "2019-01-02T02:00:00Z"
+	s.info.ActiveJobs = []*peloton.JobID{{Value: uuid.New()}}
+	s.NoError(ops.Update(ctx, s.info))
+
+	infos, err := ops.GetAll(ctx)
+	s.NoError(err)
+	s.Equal([]*cron.CronJobInfo{s.info}, infos)
+
+	s.NoError(ops.Delete(ctx, "nightly"))
+
+	_, err = ops.Get(ctx, "nightly")
+	s.Equal(gocql.ErrNotFound, err)
+	infos, err = ops.GetAll(ctx)
+	s.NoError(err)
+	s.Empty(infos)
+}
+
+// TestRunCreateGetAll tests recording the runs of a cron job
+// in the in-memory store
+func (s *CronJobObjectTestSuite) TestRunCreateGetAll() {
+	store, err := NewMemoryStore(tally.NoopScope)
+	s.NoError(err)
+	ops := NewCronJobRunOps(store)
+	ctx := context.Background()
+
+	now := time.Date(2019, 1, 2, 2, 0, 0, 0, time.UTC)
+	var runs []*cron.CronRun
+	for i := 0; i < 3; i++ {
+		run := &cron.CronRun{
+			Time:    now.Add(time.Duration(i) * time.Hour).Format(time.RFC3339Nano),
+			Trigger: cron.RunTrigger_SCHEDULE,
+			Result:  cron.RunResult_CREATED,
+			JobId:   &peloton.JobID{Value: uuid.New()},
+		}
+		s.NoError(ops.Create(ctx, "nightly", run))
+		runs = append([]*cron.CronRun{run}, runs...)
+	}
+	skipped := &cron.CronRun{
+		Time:    now.Add(3 * time.Hour).Format(time.RFC3339Nano),
+		Trigger: cron.RunTrigger_MANUAL,
+		Result:  cron.RunResult_SKIPPED,
+		Message: "previous run is active",
+	}
+	s.NoError(ops.Create(ctx, "nightly", skipped))
+	runs = append([]*cron.CronRun{skipped}, runs...)
+
+	// runs of other cron jobs are not returned
+	s.NoError(ops.Create(ctx, "hourly", runs[1]))
+
+	result, err := ops.GetAll(ctx, "nightly", 0)
+	s.NoError(err)
+	s.Equal(runs, result)
+
+	result, err = ops.GetAll(ctx, "nightly", 2)
+	s.NoError(err)
+	s.Equal(runs[:2], result)
+
+	// a run with an invalid time cannot be recorded
+	s.Error(ops.Create(ctx, "nightly", &cron.CronRun{Time: "now"}))
+}
+
+// TestCronJobOpsFail tests failure cases due to ORM Client errors
+func (s *CronJobObjectTestSuite) TestCronJobOpsFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	ops := NewCronJobOps(mockStore)
+	runOps := NewCronJobRunOps(mockStore)
+	ctx := context.Background()
+
+	mockClient.EXPECT().CreateIfNotExists(gomock.Any(), gomock.Any()).
+		Return(errors.New("createifnotexists failed"))
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("update failed"))
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(errors.New("get failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed")).Times(2)
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+
+	s.EqualError(ops.Create(ctx, s.info), "createifnotexists failed")
+	s.EqualError(ops.Update(ctx, s.info), "update failed")
+	_, err := ops.Get(ctx, "nightly")
+	s.EqualError(err, "get failed")
+	_, err = ops.GetAll(ctx)
+	s.EqualError(err, "getall failed")
+	s.EqualError(ops.Delete(ctx, "nightly"), "delete failed")
+
+	s.EqualError(runOps.Create(ctx, "nightly", &cron.CronRun{
+		Time: "2019-01-02T02:00:00Z",
+	}), "create failed")
+	_, err = runOps.GetAll(ctx, "nightly", 0)
+	s.EqualError(err, "getall failed")
+}
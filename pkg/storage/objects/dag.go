@@ -0,0 +1,234 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// dagBucket is the partition all the DAGs are stored in. DAGs expire
+// after 30 days, so there are only a few thousand of them at most.
+const dagBucket = 0
+
+// init adds a DagObject instance to the global list of storage objects
+func init() {
+	Objs = append(Objs, &DagObject{})
+}
+
+// DagObject corresponds to a row in dags table.
+type DagObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=dags, primaryKey=((bucket), dag_id)"`
+
+	// Partition of the DAG, always dagBucket
+	Bucket int `column:"name=bucket"`
+	// ID of the DAG
+	DagID string `column:"name=dag_id"`
+	// Serialized DAG info, without the goal state
+	Info []byte `column:"name=info"`
+	// Goal state of the DAG
+	GoalState string `column:"name=goal_state"`
+	// Last time the DAG info was written
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// DagOps provides methods for manipulating dags table.
+type DagOps interface {
+	// Create inserts a row in the table.
+	Create(ctx context.Context, info *dag.DagInfo) error
+
+	// Update overwrites the info of a row in the table,
+	// leaving its goal state unchanged.
+	Update(ctx context.Context, info *dag.DagInfo) error
+
+	// UpdateGoalState overwrites the goal state of a row in the table.
+	UpdateGoalState(
+		ctx context.Context,
+		id *dag.DagID,
+		goalState dag.DagState,
+	) error
+
+	// Get retrieves a row from the table.
+	Get(ctx context.Context, id *dag.DagID) (*dag.DagInfo, error)
+
+	// GetAll retrieves all the rows from the table.
+	GetAll(ctx context.Context) ([]*dag.DagInfo, error)
+}
+
+// ensure that default implementation (dagOps) satisfies the interface
+var _ DagOps = (*dagOps)(nil)
+
+// dagOps implements DagOps using a particular Store
+type dagOps struct {
+	store *Store
+}
+
+// NewDagOps constructs a DagOps object for provided Store.
+func NewDagOps(s *Store) DagOps {
+	return &dagOps{store: s}
+}
+
+// newDagObject creates a DagObject from a DAG info
+func newDagObject(info *dag.DagInfo) (*DagObject, error) {
+	// the goal state is stored in its own column
+	info = proto.Clone(info).(*dag.DagInfo)
+	goalState := info.GetGoalState()
+	info.GoalState = dag.DagState_DAG_UNKNOWN
+
+	buffer, err := proto.Marshal(info)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to marshal DAG info")
+	}
+	return &DagObject{
+		Bucket:     dagBucket,
+		DagID:      info.GetId().GetValue(),
+		Info:       buffer,
+		GoalState:  goalState.String(),
+		UpdateTime: time.Now().UTC(),
+	}, nil
+}
+
+func (d *DagObject) toInfo() (*dag.DagInfo, error) {
+	info := &dag.DagInfo{}
+	if err := proto.Unmarshal(d.Info, info); err != nil {
+		return nil, err
+	}
+	info.GoalState = dag.DagState(dag.DagState_value[d.GoalState])
+	if info.GoalState == dag.DagState_DAG_UNKNOWN {
+		info.GoalState = dag.DagState_DAG_SUCCEEDED
+	}
+	return info, nil
+}
+
+// Create creates a DagObject in db
+func (d *dagOps) Create(
+	ctx context.Context,
+	info *dag.DagInfo,
+) error {
+	obj, err := newDagObject(info)
+	if err != nil {
+		d.store.metrics.OrmDagMetrics.DagCreateFail.Inc(1)
+		return err
+	}
+
+	if err = d.store.oClient.CreateIfNotExists(ctx, obj); err != nil {
+		d.store.metrics.OrmDagMetrics.DagCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmDagMetrics.DagCreate.Inc(1)
+	return nil
+}
+
+// Update updates the info of a DagObject in db
+func (d *dagOps) Update(
+	ctx context.Context,
+	info *dag.DagInfo,
+) error {
+	obj, err := newDagObject(info)
+	if err != nil {
+		d.store.metrics.OrmDagMetrics.DagUpdateFail.Inc(1)
+		return err
+	}
+
+	if err = d.store.oClient.Update(
+		ctx, obj, "Info", "UpdateTime"); err != nil {
+		d.store.metrics.OrmDagMetrics.DagUpdateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmDagMetrics.DagUpdate.Inc(1)
+	return nil
+}
+
+// UpdateGoalState updates the goal state of a DagObject in db
+func (d *dagOps) UpdateGoalState(
+	ctx context.Context,
+	id *dag.DagID,
+	goalState dag.DagState,
+) error {
+	obj := &DagObject{
+		Bucket:    dagBucket,
+		DagID:     id.GetValue(),
+		GoalState: goalState.String(),
+	}
+
+	if err := d.store.oClient.Update(ctx, obj, "GoalState"); err != nil {
+		d.store.metrics.OrmDagMetrics.DagUpdateGoalStateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmDagMetrics.DagUpdateGoalState.Inc(1)
+	return nil
+}
+
+// Get gets a DagObject from db
+func (d *dagOps) Get(
+	ctx context.Context,
+	id *dag.DagID,
+) (*dag.DagInfo, error) {
+	obj := &DagObject{
+		Bucket: dagBucket,
+		DagID:  id.GetValue(),
+	}
+
+	if err := d.store.oClient.Get(ctx, obj); err != nil {
+		d.store.metrics.OrmDagMetrics.DagGetFail.Inc(1)
+		return nil, err
+	}
+
+	info, err := obj.toInfo()
+	if err != nil {
+		d.store.metrics.OrmDagMetrics.DagGetFail.Inc(1)
+		return nil, errors.Wrap(err, "Failed to unmarshal DAG info")
+	}
+
+	d.store.metrics.OrmDagMetrics.DagGet.Inc(1)
+	return info, nil
+}
+
+// GetAll gets all the DAGs from db
+func (d *dagOps) GetAll(
+	ctx context.Context,
+) ([]*dag.DagInfo, error) {
+	objs, err := d.store.oClient.GetAll(ctx, &DagObject{
+		Bucket: dagBucket,
+	})
+	if err != nil {
+		d.store.metrics.OrmDagMetrics.DagGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	var infos []*dag.DagInfo
+	for _, obj := range objs {
+		info, err := obj.(*DagObject).toInfo()
+		if err != nil {
+			d.store.metrics.OrmDagMetrics.DagGetAllFail.Inc(1)
+			return nil, errors.Wrap(err, "Failed to unmarshal DAG info")
+		}
+		infos = append(infos, info)
+	}
+
+	d.store.metrics.OrmDagMetrics.DagGetAll.Inc(1)
+	return infos, nil
+}
@@ -0,0 +1,140 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/dag"
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type DagObjectTestSuite struct {
+	suite.Suite
+	info *dag.DagInfo
+}
+
+func (s *DagObjectTestSuite) SetupTest() {
+	s.info = &dag.DagInfo{
+		Id: &dag.DagID{Value: uuid.New()},
+		Spec: &dag.DagSpec{
+			Name: "etl",
+			Nodes: []*dag.Node{
+				{
+					Name:      "extract",
+					JobConfig: &job.JobConfig{Type: job.JobType_BATCH},
+				},
+				{
+					Name:      "load",
+					JobConfig: &job.JobConfig{Type: job.JobType_BATCH},
+				},
+			},
+			Edges: []*dag.Edge{{From: "extract", To: "load"}},
+		},
+		State:     dag.DagState_DAG_RUNNING,
+		GoalState: dag.DagState_DAG_SUCCEEDED,
+		Nodes: []*dag.NodeStatus{
+			{
+				Name:  "extract",
+				State: dag.NodeState_NODE_RUNNING,
+				JobId: &peloton.JobID{Value: uuid.New()},
+			},
+			{
+				Name:  "load",
+				State: dag.NodeState_NODE_PENDING,
+			},
+		},
+		CreationTime: "2019-01-01T10:00:00Z",
+	}
+}
+
+func TestDagObjectSuite(t *testing.T) {
+	suite.Run(t, new(DagObjectTestSuite))
+}
+
+// TestCreateUpdateGet tests the lifecycle of a DAG in the in-memory store
+func (s *DagObjectTestSuite) TestCreateUpdateGet() {
+	store, err := NewMemoryStore(tally.NoopScope)
+	s.NoError(err)
+	ops := NewDagOps(store)
+	ctx := context.Background()
+
+	s.NoError(ops.Create(ctx, s.info))
+
+	// DAG IDs are unique
+	s.Error(ops.Create(ctx, s.info))
+
+	info, err := ops.Get(ctx, s.info.GetId())
+	s.NoError(err)
+	s.Equal(s.info, info)
+
+	// the goal state is not overwritten by updates of the status
+	s.NoError(ops.UpdateGoalState(
+		ctx, s.info.GetId(), dag.DagState_DAG_CANCELED))
+	s.info.Nodes[0].State = dag.NodeState_NODE_SUCCEEDED
+	s.NoError(ops.Update(ctx, s.info))
+
+	info, err = ops.Get(ctx, s.info.GetId())
+	s.NoError(err)
+	s.Equal(dag.DagState_DAG_CANCELED, info.GetGoalState())
+	s.Equal(dag.NodeState_NODE_SUCCEEDED, info.GetNodes()[0].GetState())
+
+	infos, err := ops.GetAll(ctx)
+	s.NoError(err)
+	s.Len(infos, 1)
+	s.Equal(info, infos[0])
+
+	_, err = ops.Get(ctx, &dag.DagID{Value: uuid.New()})
+	s.Equal(gocql.ErrNotFound, err)
+}
+
+// TestDagOpsFail tests failure cases due to ORM Client errors
+func (s *DagObjectTestSuite) TestDagOpsFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	ops := NewDagOps(mockStore)
+	ctx := context.Background()
+
+	mockClient.EXPECT().CreateIfNotExists(gomock.Any(), gomock.Any()).
+		Return(errors.New("createifnotexists failed"))
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("update failed")).Times(2)
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(errors.New("get failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+
+	s.EqualError(ops.Create(ctx, s.info), "createifnotexists failed")
+	s.EqualError(ops.Update(ctx, s.info), "update failed")
+	s.EqualError(ops.UpdateGoalState(
+		ctx, s.info.GetId(), dag.DagState_DAG_CANCELED), "update failed")
+	_, err := ops.Get(ctx, s.info.GetId())
+	s.EqualError(err, "get failed")
+	_, err = ops.GetAll(ctx)
+	s.EqualError(err, "getall failed")
+}
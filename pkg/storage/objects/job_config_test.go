@@ -25,12 +25,16 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/.gen/peloton/api/v0/task"
 	"github.com/uber/peloton/.gen/peloton/private/models"
+	"github.com/uber/peloton/pkg/storage/connectors/sql"
+	// register the sqlite3 driver used by the SQL store test
+	_ "github.com/uber/peloton/pkg/storage/connectors/sql/sqlite"
 	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
 
 	"github.com/golang/mock/gomock"
 	"github.com/golang/protobuf/proto"
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
 )
 
 type JobConfigObjectTestSuite struct {
@@ -75,6 +79,35 @@ func (s *JobConfigObjectTestSuite) TestCreateGetDeleteJobConfig() {
 
 }
 
+// TestCreateGetDeleteJobConfigMemoryStore tests creating/deleting
+// JobConfigObject in the in-memory store
+func (s *JobConfigObjectTestSuite) TestCreateGetDeleteJobConfigMemoryStore() {
+	store, err := NewMemoryStore(tally.NoopScope)
+	s.NoError(err)
+	jobConfigOps := NewJobConfigOps(store)
+	ctx := context.Background()
+
+	version := uint64(1)
+
+	err = jobConfigOps.Create(ctx, s.jobID, s.config, s.configAddOn, version)
+	s.NoError(err)
+
+	// config versions cannot be overwritten
+	err = jobConfigOps.Create(ctx, s.jobID, s.config, s.configAddOn, version)
+	s.Error(err)
+
+	config, configAddOn, err := jobConfigOps.Get(ctx, s.jobID, version)
+	s.NoError(err)
+	s.Equal(config, s.config)
+	s.Equal(configAddOn, s.configAddOn)
+
+	err = jobConfigOps.Delete(ctx, s.jobID, version)
+	s.NoError(err)
+
+	_, _, err = jobConfigOps.Get(ctx, s.jobID, version)
+	s.Equal(err, gocql.ErrNotFound)
+}
+
 // TestCreateGetDeleteJobConfigFail tests failure cases due to ORM Client errors
 func (s *JobConfigObjectTestSuite) TestCreateGetDeleteJobConfigFail() {
 	ctrl := gomock.NewController(s.T())
@@ -159,3 +192,39 @@ func (s *JobConfigObjectTestSuite) buildConfig() {
 	s.NoError(err)
 	s.configAddOnMarshaled = buf
 }
+
+// TestCreateGetDeleteJobConfigSQLStore tests creating/deleting
+// JobConfigObject in a SQLite backed store
+func (s *JobConfigObjectTestSuite) TestCreateGetDeleteJobConfigSQLStore() {
+	store, err := NewSQLStore(
+		&sql.Config{
+			Driver:       sql.DriverSQLite,
+			DataSource:   ":memory:",
+			MaxOpenConns: 1,
+		},
+		tally.NoopScope,
+	)
+	s.NoError(err)
+	jobConfigOps := NewJobConfigOps(store)
+	ctx := context.Background()
+
+	version := uint64(1)
+
+	err = jobConfigOps.Create(ctx, s.jobID, s.config, s.configAddOn, version)
+	s.NoError(err)
+
+	// config versions cannot be overwritten
+	err = jobConfigOps.Create(ctx, s.jobID, s.config, s.configAddOn, version)
+	s.Error(err)
+
+	config, configAddOn, err := jobConfigOps.Get(ctx, s.jobID, version)
+	s.NoError(err)
+	s.Equal(config, s.config)
+	s.Equal(configAddOn, s.configAddOn)
+
+	err = jobConfigOps.Delete(ctx, s.jobID, version)
+	s.NoError(err)
+
+	_, _, err = jobConfigOps.Get(ctx, s.jobID, version)
+	s.Equal(err, gocql.ErrNotFound)
+}
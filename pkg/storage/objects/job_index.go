@@ -23,6 +23,7 @@ import (
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
 	"github.com/uber/peloton/pkg/storage/objects/base"
 
+	"github.com/gocql/gocql"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
@@ -287,6 +288,17 @@ func (d *jobIndexOps) Create(
 		return err
 	}
 
+	if err = createJobIndexByTime(
+		ctx, d.store, id, jobIndexCreationTime, obj.CreationTime); err != nil {
+		d.store.metrics.OrmJobMetrics.JobIndexCreateFail.Inc(1)
+		return err
+	}
+	if err = createJobIndexByTime(
+		ctx, d.store, id, jobIndexCompletionTime, obj.CompletionTime); err != nil {
+		d.store.metrics.OrmJobMetrics.JobIndexCreateFail.Inc(1)
+		return err
+	}
+
 	d.store.metrics.OrmJobMetrics.JobIndexCreate.Inc(1)
 	return nil
 }
@@ -361,11 +373,20 @@ func (d *jobIndexOps) Update(
 		return err
 	}
 
+	// jobs are indexed by creation time when created, and by
+	// completion time once they reach a terminal state
+	if err = createJobIndexByTime(
+		ctx, d.store, id, jobIndexCompletionTime, obj.CompletionTime); err != nil {
+		d.store.metrics.OrmJobMetrics.JobIndexUpdateFail.Inc(1)
+		return err
+	}
+
 	d.store.metrics.OrmJobMetrics.JobIndexUpdate.Inc(1)
 	return nil
 }
 
-// Delete deletes a JobIndexObject from db
+// Delete deletes a JobIndexObject from db, along with the rows
+// indexing the job in job_index_by_time
 func (d *jobIndexOps) Delete(
 	ctx context.Context,
 	id *peloton.JobID,
@@ -373,6 +394,23 @@ func (d *jobIndexOps) Delete(
 	jobIndexObject := &JobIndexObject{
 		JobID: id.GetValue(),
 	}
+	if err := d.store.oClient.Get(ctx, jobIndexObject); err != nil {
+		if err != gocql.ErrNotFound {
+			d.store.metrics.OrmJobMetrics.JobIndexDeleteFail.Inc(1)
+			return err
+		}
+	}
+	if err := deleteJobIndexByTime(ctx, d.store, id,
+		jobIndexCreationTime, jobIndexObject.CreationTime); err != nil {
+		d.store.metrics.OrmJobMetrics.JobIndexDeleteFail.Inc(1)
+		return err
+	}
+	if err := deleteJobIndexByTime(ctx, d.store, id,
+		jobIndexCompletionTime, jobIndexObject.CompletionTime); err != nil {
+		d.store.metrics.OrmJobMetrics.JobIndexDeleteFail.Inc(1)
+		return err
+	}
+
 	if err := d.store.oClient.Delete(ctx, jobIndexObject); err != nil {
 		d.store.metrics.OrmJobMetrics.JobIndexDeleteFail.Inc(1)
 		return err
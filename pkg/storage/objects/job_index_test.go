@@ -286,6 +286,9 @@ func (s *JobIndexObjectTestSuite) TestJobIndexOpsClientFail() {
 		Return(errors.New("create failed"))
 	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
 		Return(errors.New("get failed")).Times(2)
+	// the job_index row deleted is not found, the job is not indexed
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(gocql.ErrNotFound)
 	mockClient.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(errors.New("update failed"))
 	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
@@ -0,0 +1,748 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/query"
+
+	"github.com/uber/peloton/pkg/common/concurrency"
+	"github.com/uber/peloton/pkg/common/util"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gocql/gocql"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/yarpc/yarpcerrors"
+)
+
+const (
+	// jobIndexDayFormat is the format of the day partitions
+	// of job_index_by_time table
+	jobIndexDayFormat = "2006-01-02"
+
+	// time fields of the jobs indexed in job_index_by_time table
+	jobIndexCreationTime   = "creation_time"
+	jobIndexCompletionTime = "completion_time"
+
+	// activeJobsShardID is the only partition of active_jobs table
+	activeJobsShardID = 0
+
+	// default pagination of the job queries
+	_defaultJobQueryLimit    uint32 = 10
+	_defaultJobQueryMaxLimit uint32 = 100
+
+	// _jobQueryMaxLookback is how far back terminal jobs are looked up
+	// by creation time when a query has no time range. Active jobs are
+	// always looked up, whenever they were created.
+	_jobQueryMaxLookback = 366 * 24 * time.Hour
+	// _jobQueryJitter is added to the end of the default time range to
+	// account for jobs that have just been created
+	_jobQueryJitter = 30 * time.Second
+	// _jobQueryWorkers is the number of go routines reading the
+	// job_index rows of the jobs looked up by a query
+	_jobQueryWorkers = 25
+)
+
+// _jobIndexSorters compares the job_index rows by the properties
+// the jobs can be ordered by in a query
+var _jobIndexSorters = map[string]func(a, b *JobIndexObject) bool{
+	"name":            func(a, b *JobIndexObject) bool { return a.Name < b.Name },
+	"owner":           func(a, b *JobIndexObject) bool { return a.Owner < b.Owner },
+	"job_type":        func(a, b *JobIndexObject) bool { return a.JobType < b.JobType },
+	"respool_id":      func(a, b *JobIndexObject) bool { return a.RespoolID < b.RespoolID },
+	"instance_count":  func(a, b *JobIndexObject) bool { return a.InstanceCount < b.InstanceCount },
+	"state":           func(a, b *JobIndexObject) bool { return a.State < b.State },
+	"creation_time":   func(a, b *JobIndexObject) bool { return a.CreationTime.Before(b.CreationTime) },
+	"start_time":      func(a, b *JobIndexObject) bool { return a.StartTime.Before(b.StartTime) },
+	"completion_time": func(a, b *JobIndexObject) bool { return a.CompletionTime.Before(b.CompletionTime) },
+	"update_time":     func(a, b *JobIndexObject) bool { return a.UpdateTime.Before(b.UpdateTime) },
+}
+
+// init adds the objects of the job query tables to the global
+// list of storage objects
+func init() {
+	Objs = append(Objs, &JobIndexByTimeObject{}, &ActiveJobsObject{})
+}
+
+// JobIndexByTimeObject corresponds to a row in job_index_by_time table,
+// which indexes the jobs by the day they were created and completed in.
+type JobIndexByTimeObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=job_index_by_time, primaryKey=((time_field,day), job_id)"`
+
+	// Time field of the job the row is indexed by
+	TimeField string `column:"name=time_field"`
+	// UTC day of the time field
+	Day string `column:"name=day"`
+	// JobID of the job
+	JobID string `column:"name=job_id"`
+}
+
+// ActiveJobsObject corresponds to a row in active_jobs table.
+type ActiveJobsObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=active_jobs, primaryKey=((shard_id), job_id)"`
+
+	// Partition of the job, always activeJobsShardID
+	ShardID int `column:"name=shard_id"`
+	// JobID of the job
+	JobID string `column:"name=job_id"`
+}
+
+// JobQueryOps provides methods for querying jobs from job_index table,
+// without the Lucene index of the table.
+type JobQueryOps interface {
+	// Query returns the jobs of the resource pool, if any, matching
+	// the query spec, along with the number of matching jobs up to the
+	// max limit of the spec. Only the summaries of the jobs are returned
+	// if summaryOnly is set.
+	Query(
+		ctx context.Context,
+		respoolID *peloton.ResourcePoolID,
+		spec *job.QuerySpec,
+		summaryOnly bool,
+	) ([]*job.JobInfo, []*job.JobSummary, uint32, error)
+
+	// Backfill indexes the jobs in job_index_by_time table by the times
+	// of their job_index rows, for the jobs created before the table was
+	// added. Jobs missing from job_index are skipped.
+	Backfill(ctx context.Context, jobIDs []peloton.JobID) error
+}
+
+// ensure that default implementation (jobQueryOps) satisfies the interface
+var _ JobQueryOps = (*jobQueryOps)(nil)
+
+// jobQueryOps implements JobQueryOps using a particular Store
+type jobQueryOps struct {
+	store *Store
+	// now returns the current time, overridden in tests
+	now func() time.Time
+}
+
+// NewJobQueryOps constructs a JobQueryOps object for provided Store.
+func NewJobQueryOps(s *Store) JobQueryOps {
+	return &jobQueryOps{store: s, now: time.Now}
+}
+
+// jobQueryFilter selects the jobs returned by a query
+type jobQueryFilter struct {
+	respoolID string
+	owner     string
+	name      string
+	labels    []*peloton.Label
+	keywords  []string
+	states    map[string]bool
+
+	// terminal is set if the query is for any terminal
+	// state, and active for any non-terminal state
+	terminal bool
+	active   bool
+
+	creationTime   *timeRange
+	completionTime *timeRange
+}
+
+// timeRange is an inclusive range of time
+type timeRange struct {
+	min time.Time
+	max time.Time
+}
+
+// contains returns true if the time is in the range
+func (r *timeRange) contains(t time.Time) bool {
+	return !t.Before(r.min) && !t.After(r.max)
+}
+
+// days returns the start of the UTC days covered by the range,
+// most recent first
+func (r *timeRange) days() []time.Time {
+	var days []time.Time
+	first := r.min.UTC().Truncate(24 * time.Hour)
+	for day := r.max.UTC().Truncate(24 * time.Hour); !day.Before(first); day = day.AddDate(0, 0, -1) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// newTimeRange converts the time range of a query spec
+func newTimeRange(r *peloton.TimeRange) (*timeRange, error) {
+	if r == nil {
+		return nil, nil
+	}
+	min, err := ptypes.Timestamp(r.GetMin())
+	if err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"invalid time range: %v", err)
+	}
+	max, err := ptypes.Timestamp(r.GetMax())
+	if err != nil {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"invalid time range: %v", err)
+	}
+	if max.Before(min) {
+		return nil, yarpcerrors.InvalidArgumentErrorf(
+			"invalid time range: %v is before %v", max, min)
+	}
+	return &timeRange{min: min, max: max}, nil
+}
+
+// newJobQueryFilter creates the filter of a query spec
+func newJobQueryFilter(
+	respoolID *peloton.ResourcePoolID,
+	spec *job.QuerySpec,
+) (*jobQueryFilter, error) {
+	filter := &jobQueryFilter{
+		respoolID: respoolID.GetValue(),
+		owner:     spec.GetOwner(),
+		name:      spec.GetName(),
+		labels:    spec.GetLabels(),
+	}
+
+	for _, word := range spec.GetKeywords() {
+		filter.keywords = append(filter.keywords, strings.ToLower(word))
+	}
+
+	if len(spec.GetJobStates()) == 0 {
+		filter.terminal = true
+		filter.active = true
+	} else {
+		filter.states = make(map[string]bool)
+		for _, state := range spec.GetJobStates() {
+			filter.states[state.String()] = true
+			if util.IsPelotonJobStateTerminal(state) {
+				filter.terminal = true
+			} else {
+				filter.active = true
+			}
+		}
+	}
+
+	var err error
+	if filter.creationTime, err = newTimeRange(
+		spec.GetCreationTimeRange()); err != nil {
+		return nil, err
+	}
+	if filter.completionTime, err = newTimeRange(
+		spec.GetCompletionTimeRange()); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// matches returns true if the job_index row matches the filter
+func (f *jobQueryFilter) matches(obj *JobIndexObject) bool {
+	if len(f.respoolID) != 0 && obj.RespoolID != f.respoolID {
+		return false
+	}
+	if len(f.owner) != 0 && obj.Owner != f.owner {
+		return false
+	}
+	if len(f.name) != 0 && !strings.Contains(obj.Name, f.name) {
+		return false
+	}
+	if f.states != nil && !f.states[obj.State] {
+		return false
+	}
+	if f.creationTime != nil && !f.creationTime.contains(obj.CreationTime) {
+		return false
+	}
+	if f.completionTime != nil &&
+		!f.completionTime.contains(obj.CompletionTime) {
+		return false
+	}
+
+	if len(f.labels) != 0 {
+		var labels []*peloton.Label
+		if err := json.Unmarshal([]byte(obj.Labels), &labels); err != nil {
+			return false
+		}
+		for _, label := range f.labels {
+			if !hasLabel(labels, label) {
+				return false
+			}
+		}
+	}
+
+	config := strings.ToLower(obj.Config)
+	for _, word := range f.keywords {
+		if !strings.Contains(config, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasLabel returns true if the label is in the list. Labels without
+// key match any label with the same value.
+func hasLabel(labels []*peloton.Label, label *peloton.Label) bool {
+	for _, l := range labels {
+		if l.GetValue() == label.GetValue() &&
+			(len(label.GetKey()) == 0 || l.GetKey() == label.GetKey()) {
+			return true
+		}
+	}
+	return false
+}
+
+// newJobIndexSorter returns the ordering of the job_index rows for
+// the order by list of a query spec, by creation time descending
+// by default
+func newJobIndexSorter(
+	orderBy []*query.OrderBy,
+) (func(a, b *JobIndexObject) bool, error) {
+	if len(orderBy) == 0 {
+		orderBy = []*query.OrderBy{
+			{
+				Order:    query.OrderBy_DESC,
+				Property: &query.PropertyPath{Value: jobIndexCreationTime},
+			},
+		}
+	}
+
+	type sorter struct {
+		less    func(a, b *JobIndexObject) bool
+		reverse bool
+	}
+	var sorters []sorter
+	for _, order := range orderBy {
+		less, ok := _jobIndexSorters[order.GetProperty().GetValue()]
+		if !ok {
+			return nil, yarpcerrors.InvalidArgumentErrorf(
+				"jobs cannot be ordered by %s",
+				order.GetProperty().GetValue())
+		}
+		sorters = append(sorters, sorter{
+			less:    less,
+			reverse: order.GetOrder() == query.OrderBy_DESC,
+		})
+	}
+
+	return func(a, b *JobIndexObject) bool {
+		for _, s := range sorters {
+			if s.less(a, b) {
+				return !s.reverse
+			}
+			if s.less(b, a) {
+				return s.reverse
+			}
+		}
+		return false
+	}, nil
+}
+
+// isNewestFirst returns true if the jobs are ordered by creation time,
+// most recent first, which is the default order
+func isNewestFirst(orderBy []*query.OrderBy) bool {
+	if len(orderBy) == 0 {
+		return true
+	}
+	return orderBy[0].GetProperty().GetValue() == jobIndexCreationTime &&
+		orderBy[0].GetOrder() == query.OrderBy_DESC
+}
+
+// Query reads the job_index rows of the jobs indexed in the time range
+// of the query, or of the active jobs and the jobs created in the last
+// year if there is no time range, and returns the matching ones. If the
+// jobs are ordered by creation time, most recent first, the days are read
+// from the most recent one until the max limit of the query is reached.
+// Otherwise all the days are read before the matching jobs are sorted.
+func (d *jobQueryOps) Query(
+	ctx context.Context,
+	respoolID *peloton.ResourcePoolID,
+	spec *job.QuerySpec,
+	summaryOnly bool,
+) ([]*job.JobInfo, []*job.JobSummary, uint32, error) {
+	if spec == nil {
+		return nil, nil, 0, nil
+	}
+
+	filter, err := newJobQueryFilter(respoolID, spec)
+	if err != nil {
+		d.store.metrics.OrmJobMetrics.JobIndexQueryFail.Inc(1)
+		return nil, nil, 0, err
+	}
+	less, err := newJobIndexSorter(spec.GetPagination().GetOrderBy())
+	if err != nil {
+		d.store.metrics.OrmJobMetrics.JobIndexQueryFail.Inc(1)
+		return nil, nil, 0, err
+	}
+
+	maxLimit := _defaultJobQueryMaxLimit
+	if spec.GetPagination().GetMaxLimit() != 0 {
+		maxLimit = spec.GetPagination().GetMaxLimit()
+	}
+
+	// only the most recent days need to be read if the jobs
+	// are ordered by creation time, most recent first
+	stopAt := 0
+	if isNewestFirst(spec.GetPagination().GetOrderBy()) {
+		stopAt = int(maxLimit)
+	}
+	matches, err := d.getMatches(ctx, filter, stopAt)
+	if err != nil {
+		d.store.metrics.OrmJobMetrics.JobIndexQueryFail.Inc(1)
+		return nil, nil, 0, err
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return less(matches[i], matches[j])
+	})
+
+	if uint32(len(matches)) > maxLimit {
+		matches = matches[:maxLimit]
+	}
+	total := uint32(len(matches))
+
+	// Apply offset and limit.
+	begin := spec.GetPagination().GetOffset()
+	if begin > total {
+		begin = total
+	}
+	matches = matches[begin:]
+
+	end := _defaultJobQueryLimit
+	if limit := spec.GetPagination().GetLimit(); limit > 0 {
+		end = limit
+	}
+	if end > uint32(len(matches)) {
+		end = uint32(len(matches))
+	}
+	matches = matches[:end]
+
+	var infos []*job.JobInfo
+	var summaries []*job.JobSummary
+	for _, obj := range matches {
+		if summaryOnly {
+			summary, err := obj.ToJobSummary()
+			if err != nil {
+				d.store.metrics.OrmJobMetrics.JobIndexQueryFail.Inc(1)
+				return nil, nil, 0, err
+			}
+			summaries = append(summaries, summary)
+			continue
+		}
+
+		info, err := obj.toJobInfo()
+		if err != nil {
+			d.store.metrics.OrmJobMetrics.JobIndexQueryFail.Inc(1)
+			return nil, nil, 0, err
+		}
+		infos = append(infos, info)
+	}
+
+	d.store.metrics.OrmJobMetrics.JobIndexQuery.Inc(1)
+	return infos, summaries, total, nil
+}
+
+// jobMatches collects the job_index rows of the jobs matching a filter
+type jobMatches struct {
+	filter  *jobQueryFilter
+	seen    map[string]struct{}
+	matches []*JobIndexObject
+}
+
+// getMatches returns the job_index rows of the jobs matching the filter.
+// The day partitions are read from the most recent one. If maxLimit is
+// not 0, no more days are read once maxLimit matching jobs were created
+// after the days left, all of them are read otherwise.
+func (d *jobQueryOps) getMatches(
+	ctx context.Context,
+	filter *jobQueryFilter,
+	maxLimit int,
+) ([]*JobIndexObject, error) {
+	m := &jobMatches{
+		filter: filter,
+		seen:   make(map[string]struct{}),
+	}
+
+	switch {
+	case filter.creationTime != nil:
+		if err := d.addIndexedJobs(
+			ctx, m, jobIndexCreationTime, filter.creationTime, maxLimit); err != nil {
+			return nil, err
+		}
+	case filter.completionTime != nil:
+		if err := d.addIndexedJobs(
+			ctx, m, jobIndexCompletionTime, filter.completionTime, maxLimit); err != nil {
+			return nil, err
+		}
+	default:
+		if filter.active {
+			ids, err := d.getActiveJobIDs(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if err := d.addJobs(ctx, m, ids); err != nil {
+				return nil, err
+			}
+		}
+		if filter.terminal {
+			now := d.now()
+			defaultRange := &timeRange{
+				min: now.Add(-_jobQueryMaxLookback),
+				max: now.Add(_jobQueryJitter),
+			}
+			if err := d.addIndexedJobs(
+				ctx, m, jobIndexCreationTime, defaultRange, maxLimit); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m.matches, nil
+}
+
+// addIndexedJobs adds the matching jobs indexed by the time field in
+// the day partitions covering the time range, from the most recent
+// day. If maxLimit is not 0 and the jobs are indexed by creation time,
+// it stops once maxLimit matching jobs, including the active jobs
+// added before, were created after the days left to read.
+func (d *jobQueryOps) addIndexedJobs(
+	ctx context.Context,
+	m *jobMatches,
+	timeField string,
+	r *timeRange,
+	maxLimit int,
+) error {
+	for _, day := range r.days() {
+		if maxLimit != 0 && timeField == jobIndexCreationTime &&
+			m.countCreatedSince(day.Add(24*time.Hour)) >= maxLimit {
+			return nil
+		}
+		objs, err := d.store.oClient.GetAll(ctx, &JobIndexByTimeObject{
+			TimeField: timeField,
+			Day:       day.Format(jobIndexDayFormat),
+		})
+		if err != nil {
+			return err
+		}
+		ids := make([]string, 0, len(objs))
+		for _, obj := range objs {
+			ids = append(ids, obj.(*JobIndexByTimeObject).JobID)
+		}
+		if err := d.addJobs(ctx, m, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// countCreatedSince returns the number of matching jobs created at or
+// after t
+func (m *jobMatches) countCreatedSince(t time.Time) int {
+	var count int
+	for _, obj := range m.matches {
+		if !obj.CreationTime.Before(t) {
+			count++
+		}
+	}
+	return count
+}
+
+// addJobs reads the job_index rows of the jobs which were not read yet,
+// and adds the matching ones
+func (d *jobQueryOps) addJobs(
+	ctx context.Context,
+	m *jobMatches,
+	ids []string,
+) error {
+	var jobIDs []string
+	for _, id := range ids {
+		if _, ok := m.seen[id]; ok {
+			continue
+		}
+		m.seen[id] = struct{}{}
+		jobIDs = append(jobIDs, id)
+	}
+
+	objs, err := d.getJobIndexObjects(ctx, jobIDs)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if m.filter.matches(obj) {
+			m.matches = append(m.matches, obj)
+		}
+	}
+	return nil
+}
+
+// getActiveJobIDs returns the IDs of the active jobs
+func (d *jobQueryOps) getActiveJobIDs(ctx context.Context) ([]string, error) {
+	objs, err := d.store.oClient.GetAll(ctx, &ActiveJobsObject{
+		ShardID: activeJobsShardID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		ids = append(ids, obj.(*ActiveJobsObject).JobID)
+	}
+	return ids, nil
+}
+
+// getJobIndexObjects reads the job_index rows of the jobs. Jobs which
+// were deleted since they were indexed are skipped.
+func (d *jobQueryOps) getJobIndexObjects(
+	ctx context.Context,
+	jobIDs []string,
+) ([]*JobIndexObject, error) {
+	var inputs []interface{}
+	for _, id := range jobIDs {
+		inputs = append(inputs, id)
+	}
+
+	f := func(ctx context.Context, input interface{}) (interface{}, error) {
+		obj := &JobIndexObject{JobID: input.(string)}
+		if err := d.store.oClient.Get(ctx, obj); err != nil {
+			if err == gocql.ErrNotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+		// the name of the jobs created before it was added to
+		// job_index is not set, these jobs cannot be queried
+		if len(obj.Name) == 0 {
+			log.WithField("job_id", obj.JobID).
+				Debug("skip job without name in job_index")
+			return nil, nil
+		}
+		return obj, nil
+	}
+
+	outputs, err := concurrency.Map(
+		ctx,
+		concurrency.MapperFunc(f),
+		inputs,
+		_jobQueryWorkers)
+	if err != nil {
+		return nil, err
+	}
+
+	objs := make([]*JobIndexObject, 0, len(outputs))
+	for _, o := range outputs {
+		objs = append(objs, o.(*JobIndexObject))
+	}
+	return objs, nil
+}
+
+// Backfill indexes the jobs in job_index_by_time table by the creation
+// and completion times of their job_index rows. Rows are upserted, so
+// jobs which are already indexed are left as is.
+func (d *jobQueryOps) Backfill(
+	ctx context.Context,
+	jobIDs []peloton.JobID,
+) error {
+	var inputs []interface{}
+	for _, id := range jobIDs {
+		inputs = append(inputs, id.GetValue())
+	}
+
+	f := func(ctx context.Context, input interface{}) (interface{}, error) {
+		id := &peloton.JobID{Value: input.(string)}
+		obj := &JobIndexObject{JobID: id.GetValue()}
+		if err := d.store.oClient.Get(ctx, obj); err != nil {
+			if err == gocql.ErrNotFound {
+				return nil, nil
+			}
+			return nil, err
+		}
+		if err := createJobIndexByTime(
+			ctx, d.store, id, jobIndexCreationTime, obj.CreationTime); err != nil {
+			return nil, err
+		}
+		return nil, createJobIndexByTime(
+			ctx, d.store, id, jobIndexCompletionTime, obj.CompletionTime)
+	}
+
+	if _, err := concurrency.Map(
+		ctx,
+		concurrency.MapperFunc(f),
+		inputs,
+		_jobQueryWorkers); err != nil {
+		d.store.metrics.OrmJobMetrics.JobIndexBackfillFail.Inc(1)
+		return err
+	}
+	d.store.metrics.OrmJobMetrics.JobIndexBackfill.Inc(int64(len(inputs)))
+	return nil
+}
+
+// toJobInfo generates a JobInfo from the JobIndexObject. The instance
+// config of the job is not stored in job_index and is not set.
+func (j *JobIndexObject) toJobInfo() (*job.JobInfo, error) {
+	info := &job.JobInfo{
+		Id: &peloton.JobID{Value: j.JobID},
+	}
+	if err := json.Unmarshal([]byte(j.Config), &info.Config); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal jobConfig")
+	}
+	if err := json.Unmarshal(
+		[]byte(j.RuntimeInfo), &info.Runtime); err != nil {
+		return nil, errors.Wrap(err, "Failed to unmarshal job runtime")
+	}
+	return info, nil
+}
+
+// newJobIndexByTimeObject creates the job_index_by_time row of a job
+// for the time field
+func newJobIndexByTimeObject(
+	id *peloton.JobID,
+	timeField string,
+	t time.Time,
+) *JobIndexByTimeObject {
+	return &JobIndexByTimeObject{
+		TimeField: timeField,
+		Day:       t.UTC().Format(jobIndexDayFormat),
+		JobID:     id.GetValue(),
+	}
+}
+
+// createJobIndexByTime indexes a job in job_index_by_time table by the
+// time field, if the time is set
+func createJobIndexByTime(
+	ctx context.Context,
+	store *Store,
+	id *peloton.JobID,
+	timeField string,
+	t time.Time,
+) error {
+	if t.IsZero() {
+		return nil
+	}
+	return store.oClient.Create(ctx, newJobIndexByTimeObject(id, timeField, t))
+}
+
+// deleteJobIndexByTime removes a job from job_index_by_time table for
+// the time field, if the time is set
+func deleteJobIndexByTime(
+	ctx context.Context,
+	store *Store,
+	id *peloton.JobID,
+	timeField string,
+	t time.Time,
+) error {
+	if t.IsZero() {
+		return nil
+	}
+	return store.oClient.Delete(ctx, newJobIndexByTimeObject(id, timeField, t))
+}
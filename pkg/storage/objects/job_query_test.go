@@ -0,0 +1,531 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/uber/peloton/.gen/peloton/api/v0/job"
+	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/.gen/peloton/api/v0/query"
+	"github.com/uber/peloton/pkg/storage/objects/base"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type JobQueryTestSuite struct {
+	suite.Suite
+	store    *Store
+	queryOps *jobQueryOps
+	now      time.Time
+
+	// recent running batch job
+	etlJob *peloton.JobID
+	// batch job which succeeded yesterday
+	reportJob *peloton.JobID
+	// running stateless job created a month ago
+	webJob *peloton.JobID
+	// batch job killed 19 days ago
+	killedJob *peloton.JobID
+	// job deleted after it was created
+	deletedJob *peloton.JobID
+}
+
+func (s *JobQueryTestSuite) SetupTest() {
+	var err error
+	s.store, err = NewMemoryStore(tally.NoopScope)
+	s.NoError(err)
+	s.now = time.Date(2019, 6, 15, 12, 0, 0, 0, time.UTC)
+	s.queryOps = &jobQueryOps{
+		store: s.store,
+		now:   func() time.Time { return s.now },
+	}
+
+	s.etlJob = s.createJob(
+		"spark-etl", "team1", "pool1", job.JobType_BATCH,
+		job.JobState_RUNNING, s.now.Add(-time.Hour), time.Time{}, true)
+	s.reportJob = s.createJob(
+		"spark-report", "team2", "pool2", job.JobType_BATCH,
+		job.JobState_SUCCEEDED, s.now.AddDate(0, 0, -2),
+		s.now.AddDate(0, 0, -1), false)
+	s.webJob = s.createJob(
+		"web", "team1", "pool1", job.JobType_SERVICE,
+		job.JobState_RUNNING, s.now.AddDate(0, 0, -30), time.Time{}, true)
+	s.killedJob = s.createJob(
+		"cleanup", "team1", "pool1", job.JobType_BATCH,
+		job.JobState_KILLED, s.now.AddDate(0, 0, -20),
+		s.now.AddDate(0, 0, -19), false)
+
+	// jobs deleted from job_index are skipped
+	s.deletedJob = s.createJob(
+		"deleted", "team1", "pool1", job.JobType_BATCH,
+		job.JobState_RUNNING, s.now.Add(-time.Hour), time.Time{}, true)
+	s.NoError(NewJobIndexOps(s.store).Delete(context.Background(), s.deletedJob))
+}
+
+func TestJobQuerySuite(t *testing.T) {
+	suite.Run(t, new(JobQueryTestSuite))
+}
+
+// createJob creates the job_index rows of a job, and
+// adds the job to active_jobs if it is active
+func (s *JobQueryTestSuite) createJob(
+	name, owner, respool string,
+	jobType job.JobType,
+	state job.JobState,
+	creationTime, completionTime time.Time,
+	active bool,
+) *peloton.JobID {
+	ctx := context.Background()
+	id := &peloton.JobID{Value: uuid.New()}
+	config := &job.JobConfig{
+		Name:        name,
+		Type:        jobType,
+		OwningTeam:  owner,
+		Description: "query test job " + name,
+		RespoolID:   &peloton.ResourcePoolID{Value: respool},
+		Labels: []*peloton.Label{
+			{Key: "team", Value: owner},
+			{Key: "job", Value: name},
+		},
+		InstanceCount: 2,
+	}
+	runtime := &job.RuntimeInfo{
+		State:        job.JobState_INITIALIZED,
+		CreationTime: creationTime.Format(time.RFC3339Nano),
+	}
+	indexOps := NewJobIndexOps(s.store)
+	s.NoError(indexOps.Create(ctx, id, config, runtime, nil))
+
+	runtime.State = state
+	if !completionTime.IsZero() {
+		runtime.CompletionTime = completionTime.Format(time.RFC3339Nano)
+	}
+	s.NoError(indexOps.Update(ctx, id, nil, runtime))
+
+	if active {
+		s.NoError(s.store.oClient.Create(ctx, &ActiveJobsObject{
+			ShardID: activeJobsShardID,
+			JobID:   id.GetValue(),
+		}))
+	}
+	return id
+}
+
+// timeRange returns the time range of a query spec
+func (s *JobQueryTestSuite) timeRange(min, max time.Time) *peloton.TimeRange {
+	minProto, err := ptypes.TimestampProto(min)
+	s.NoError(err)
+	maxProto, err := ptypes.TimestampProto(max)
+	s.NoError(err)
+	return &peloton.TimeRange{Min: minProto, Max: maxProto}
+}
+
+// query runs a query and returns the IDs of the jobs
+// in their order, along with the total
+func (s *JobQueryTestSuite) query(
+	respoolID *peloton.ResourcePoolID,
+	spec *job.QuerySpec,
+) ([]*peloton.JobID, uint32) {
+	_, summaries, total, err := s.queryOps.Query(
+		context.Background(), respoolID, spec, true)
+	s.NoError(err)
+
+	var ids []*peloton.JobID
+	for _, summary := range summaries {
+		ids = append(ids, summary.GetId())
+	}
+	return ids, total
+}
+
+// TestQueryDefault tests that queries without time range return the
+// active jobs and the jobs created in the last year, most recent first
+func (s *JobQueryTestSuite) TestQueryDefault() {
+	ids, total := s.query(nil, &job.QuerySpec{})
+	s.Equal([]*peloton.JobID{s.etlJob, s.reportJob, s.killedJob, s.webJob}, ids)
+	s.Equal(uint32(4), total)
+
+	// jobs in active states are looked up from active jobs only
+	ids, _ = s.query(nil, &job.QuerySpec{
+		JobStates: []job.JobState{job.JobState_RUNNING},
+	})
+	s.Equal([]*peloton.JobID{s.etlJob, s.webJob}, ids)
+
+	// jobs in terminal states are looked up by creation time
+	ids, _ = s.query(nil, &job.QuerySpec{
+		JobStates: []job.JobState{
+			job.JobState_SUCCEEDED,
+			job.JobState_KILLED,
+		},
+	})
+	s.Equal([]*peloton.JobID{s.reportJob, s.killedJob}, ids)
+
+	// jobs created more than a year ago are not looked up
+	s.createJob(
+		"old", "team1", "pool1", job.JobType_BATCH,
+		job.JobState_SUCCEEDED, s.now.AddDate(-2, 0, 0),
+		s.now.AddDate(-2, 0, 0), false)
+	ids, _ = s.query(nil, &job.QuerySpec{
+		JobStates: []job.JobState{job.JobState_SUCCEEDED},
+	})
+	s.Equal([]*peloton.JobID{s.reportJob}, ids)
+
+	// nil spec returns no jobs
+	ids, total = s.query(nil, nil)
+	s.Empty(ids)
+	s.Zero(total)
+}
+
+// TestQueryFilters tests the filters of a query spec
+func (s *JobQueryTestSuite) TestQueryFilters() {
+	tests := []struct {
+		msg       string
+		respoolID *peloton.ResourcePoolID
+		spec      *job.QuerySpec
+		expected  []*peloton.JobID
+	}{
+		{
+			msg:       "resource pool",
+			respoolID: &peloton.ResourcePoolID{Value: "pool1"},
+			spec:      &job.QuerySpec{},
+			expected:  []*peloton.JobID{s.etlJob, s.killedJob, s.webJob},
+		},
+		{
+			msg:      "owner",
+			spec:     &job.QuerySpec{Owner: "team2"},
+			expected: []*peloton.JobID{s.reportJob},
+		},
+		{
+			msg:      "name",
+			spec:     &job.QuerySpec{Name: "spark"},
+			expected: []*peloton.JobID{s.etlJob, s.reportJob},
+		},
+		{
+			msg: "labels",
+			spec: &job.QuerySpec{
+				Labels: []*peloton.Label{
+					{Key: "team", Value: "team1"},
+					{Value: "spark-etl"},
+				},
+			},
+			expected: []*peloton.JobID{s.etlJob},
+		},
+		{
+			msg: "label of another key",
+			spec: &job.QuerySpec{
+				Labels: []*peloton.Label{{Key: "job", Value: "team1"}},
+			},
+		},
+		{
+			msg:      "keywords",
+			spec:     &job.QuerySpec{Keywords: []string{"Query", "REPORT"}},
+			expected: []*peloton.JobID{s.reportJob},
+		},
+		{
+			msg: "creation time",
+			spec: &job.QuerySpec{
+				CreationTimeRange: s.timeRange(
+					s.now.AddDate(0, 0, -21), s.now.AddDate(0, 0, -3)),
+			},
+			expected: []*peloton.JobID{s.killedJob},
+		},
+		{
+			msg: "completion time",
+			spec: &job.QuerySpec{
+				JobStates: []job.JobState{
+					job.JobState_SUCCEEDED,
+					job.JobState_FAILED,
+					job.JobState_KILLED,
+				},
+				CompletionTimeRange: s.timeRange(
+					s.now.AddDate(0, 0, -30), s.now.AddDate(0, 0, -1)),
+			},
+			expected: []*peloton.JobID{s.reportJob, s.killedJob},
+		},
+		{
+			msg: "creation and completion time",
+			spec: &job.QuerySpec{
+				CreationTimeRange: s.timeRange(
+					s.now.AddDate(0, 0, -30), s.now),
+				CompletionTimeRange: s.timeRange(
+					s.now.AddDate(0, 0, -19).Add(-time.Hour),
+					s.now.AddDate(0, 0, -19).Add(time.Hour)),
+			},
+			expected: []*peloton.JobID{s.killedJob},
+		},
+	}
+
+	for _, test := range tests {
+		ids, total := s.query(test.respoolID, test.spec)
+		s.Equal(test.expected, ids, test.msg)
+		s.Equal(uint32(len(test.expected)), total, test.msg)
+	}
+}
+
+// TestQueryPagination tests the sorting and pagination of the jobs
+func (s *JobQueryTestSuite) TestQueryPagination() {
+	byName := []*query.OrderBy{
+		{
+			Order:    query.OrderBy_ASC,
+			Property: &query.PropertyPath{Value: "name"},
+		},
+	}
+
+	ids, total := s.query(nil, &job.QuerySpec{
+		Pagination: &query.PaginationSpec{OrderBy: byName},
+	})
+	s.Equal([]*peloton.JobID{s.killedJob, s.etlJob, s.reportJob, s.webJob}, ids)
+	s.Equal(uint32(4), total)
+
+	ids, total = s.query(nil, &job.QuerySpec{
+		Pagination: &query.PaginationSpec{
+			Offset:  1,
+			Limit:   1,
+			OrderBy: byName,
+		},
+	})
+	s.Equal([]*peloton.JobID{s.etlJob}, ids)
+	s.Equal(uint32(4), total)
+
+	// jobs beyond the max limit are not counted, and all the jobs are
+	// sorted before the max limit is applied
+	ids, total = s.query(nil, &job.QuerySpec{
+		Pagination: &query.PaginationSpec{
+			Offset:   1,
+			MaxLimit: 2,
+			OrderBy:  byName,
+		},
+	})
+	s.Equal([]*peloton.JobID{s.etlJob}, ids)
+	s.Equal(uint32(2), total)
+
+	// ties are broken by the next order
+	ids, _ = s.query(nil, &job.QuerySpec{
+		Pagination: &query.PaginationSpec{
+			OrderBy: []*query.OrderBy{
+				{
+					Order:    query.OrderBy_DESC,
+					Property: &query.PropertyPath{Value: "owner"},
+				},
+				{
+					Order:    query.OrderBy_ASC,
+					Property: &query.PropertyPath{Value: "creation_time"},
+				},
+			},
+		},
+	})
+	s.Equal([]*peloton.JobID{s.reportJob, s.webJob, s.killedJob, s.etlJob}, ids)
+
+	// offset beyond the total
+	ids, total = s.query(nil, &job.QuerySpec{
+		Pagination: &query.PaginationSpec{Offset: 10},
+	})
+	s.Empty(ids)
+	s.Equal(uint32(4), total)
+}
+
+// TestQueryPagesDays tests that the days of a time range longer than a
+// year are read from the most recent one until the max limit is reached
+func (s *JobQueryTestSuite) TestQueryPagesDays() {
+	oldJob := s.createJob(
+		"old", "team1", "pool1", job.JobType_BATCH,
+		job.JobState_SUCCEEDED, s.now.AddDate(-2, 0, 0),
+		s.now.AddDate(-2, 0, 0), false)
+	creationTimeRange := s.timeRange(s.now.AddDate(-3, 0, 0), s.now)
+
+	ids, total := s.query(nil, &job.QuerySpec{
+		CreationTimeRange: creationTimeRange,
+	})
+	s.Equal([]*peloton.JobID{
+		s.etlJob, s.reportJob, s.killedJob, s.webJob, oldJob}, ids)
+	s.Equal(uint32(5), total)
+
+	ids, total = s.query(nil, &job.QuerySpec{
+		CreationTimeRange: creationTimeRange,
+		Pagination:        &query.PaginationSpec{MaxLimit: 2},
+	})
+	s.Equal([]*peloton.JobID{s.etlJob, s.reportJob}, ids)
+	s.Equal(uint32(2), total)
+}
+
+// TestQueryPagesDaysAscending tests that all the days of the time range
+// are read if the jobs are not ordered by creation time, most recent
+// first
+func (s *JobQueryTestSuite) TestQueryPagesDaysAscending() {
+	oldJob := s.createJob(
+		"old", "team1", "pool1", job.JobType_BATCH,
+		job.JobState_SUCCEEDED, s.now.AddDate(-2, 0, 0),
+		s.now.AddDate(-2, 0, 0), false)
+
+	ids, total := s.query(nil, &job.QuerySpec{
+		CreationTimeRange: s.timeRange(s.now.AddDate(-3, 0, 0), s.now),
+		Pagination: &query.PaginationSpec{
+			MaxLimit: 2,
+			OrderBy: []*query.OrderBy{
+				{
+					Order:    query.OrderBy_ASC,
+					Property: &query.PropertyPath{Value: "creation_time"},
+				},
+			},
+		},
+	})
+	s.Equal([]*peloton.JobID{oldJob, s.webJob}, ids)
+	s.Equal(uint32(2), total)
+}
+
+// TestQueryActiveJobsOverMaxLimit tests that recent terminal jobs are
+// looked up when more active jobs than the max limit match
+func (s *JobQueryTestSuite) TestQueryActiveJobsOverMaxLimit() {
+	s.createJob(
+		"service1", "team1", "pool1", job.JobType_SERVICE,
+		job.JobState_RUNNING, s.now.AddDate(0, 0, -60), time.Time{}, true)
+	s.createJob(
+		"service2", "team1", "pool1", job.JobType_SERVICE,
+		job.JobState_RUNNING, s.now.AddDate(0, 0, -60), time.Time{}, true)
+
+	ids, total := s.query(nil, &job.QuerySpec{
+		Pagination: &query.PaginationSpec{MaxLimit: 3},
+	})
+	s.Equal([]*peloton.JobID{s.etlJob, s.reportJob, s.killedJob}, ids)
+	s.Equal(uint32(3), total)
+}
+
+// TestBackfill tests indexing the jobs created before
+// job_index_by_time table was added
+func (s *JobQueryTestSuite) TestBackfill() {
+	ctx := context.Background()
+	creationDay := s.now.AddDate(0, 0, -20)
+	completionDay := s.now.AddDate(0, 0, -19)
+
+	// the job was not indexed by time when it was created
+	s.NoError(s.store.oClient.Delete(ctx, newJobIndexByTimeObject(
+		s.killedJob, jobIndexCreationTime, creationDay)))
+	s.NoError(s.store.oClient.Delete(ctx, newJobIndexByTimeObject(
+		s.killedJob, jobIndexCompletionTime, completionDay)))
+	ids, _ := s.query(nil, &job.QuerySpec{
+		JobStates: []job.JobState{job.JobState_KILLED},
+	})
+	s.Empty(ids)
+
+	// the deleted job is skipped
+	s.NoError(s.queryOps.Backfill(ctx, []peloton.JobID{
+		*s.killedJob,
+		*s.deletedJob,
+	}))
+
+	ids, _ = s.query(nil, &job.QuerySpec{
+		JobStates: []job.JobState{job.JobState_KILLED},
+	})
+	s.Equal([]*peloton.JobID{s.killedJob}, ids)
+	ids, _ = s.query(nil, &job.QuerySpec{
+		JobStates: []job.JobState{job.JobState_KILLED},
+		CompletionTimeRange: s.timeRange(
+			completionDay.Add(-time.Hour), completionDay.Add(time.Hour)),
+	})
+	s.Equal([]*peloton.JobID{s.killedJob}, ids)
+}
+
+// TestDeleteJobIndexByTime tests that deleting a job removes the rows
+// indexing it by time
+func (s *JobQueryTestSuite) TestDeleteJobIndexByTime() {
+	objs, err := s.store.oClient.GetAll(
+		context.Background(),
+		newJobIndexByTimeObject(
+			s.deletedJob, jobIndexCreationTime, s.now.Add(-time.Hour)))
+	s.NoError(err)
+	for _, obj := range objs {
+		s.NotEqual(s.deletedJob.GetValue(), obj.(*JobIndexByTimeObject).JobID)
+	}
+	s.NotEmpty(objs)
+}
+
+// TestQueryJobInfo tests querying the configs and runtimes of jobs
+func (s *JobQueryTestSuite) TestQueryJobInfo() {
+	infos, summaries, total, err := s.queryOps.Query(
+		context.Background(),
+		nil,
+		&job.QuerySpec{Owner: "team2"},
+		false,
+	)
+	s.NoError(err)
+	s.Nil(summaries)
+	s.Equal(uint32(1), total)
+	s.Len(infos, 1)
+	s.Equal(s.reportJob, infos[0].GetId())
+	s.Equal("spark-report", infos[0].GetConfig().GetName())
+	s.Equal(job.JobState_SUCCEEDED, infos[0].GetRuntime().GetState())
+}
+
+// TestQueryInvalidSpec tests queries with an invalid spec
+func (s *JobQueryTestSuite) TestQueryInvalidSpec() {
+	specs := []*job.QuerySpec{
+		{
+			Pagination: &query.PaginationSpec{
+				OrderBy: []*query.OrderBy{
+					{Property: &query.PropertyPath{Value: "labels"}},
+				},
+			},
+		},
+		{
+			CreationTimeRange: s.timeRange(s.now, s.now.Add(-time.Hour)),
+		},
+		{
+			CompletionTimeRange: &peloton.TimeRange{},
+		},
+	}
+
+	for _, spec := range specs {
+		_, _, _, err := s.queryOps.Query(
+			context.Background(), nil, spec, true)
+		s.Error(err)
+	}
+}
+
+// TestQueryClientFail tests failure cases due to ORM Client errors
+func (s *JobQueryTestSuite) TestQueryClientFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: s.store.metrics}
+	queryOps := NewJobQueryOps(mockStore)
+	ctx := context.Background()
+
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed"))
+	_, _, _, err := queryOps.Query(ctx, nil, &job.QuerySpec{
+		JobStates: []job.JobState{job.JobState_RUNNING},
+	}, true)
+	s.EqualError(err, "getall failed")
+
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return([]base.Object{
+			&ActiveJobsObject{JobID: uuid.New()},
+		}, nil)
+	mockClient.EXPECT().Get(gomock.Any(), gomock.Any()).
+		Return(errors.New("get failed"))
+	_, _, _, err = queryOps.Query(ctx, nil, &job.QuerySpec{
+		JobStates: []job.JobState{job.JobState_RUNNING},
+	}, true)
+	s.EqualError(err, "get failed")
+}
@@ -0,0 +1,312 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"time"
+
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+
+	"github.com/uber/peloton/pkg/storage/objects/base"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// _maintenanceWindowStates are the states of maintenance windows. The
+// windows of each state are stored in a separate partition.
+var _maintenanceWindowStates = []hpb.MaintenanceWindowState{
+	hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+	hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS,
+	hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_COMPLETED,
+}
+
+// init adds a MaintenanceWindowObject instance to the global list of
+// storage objects
+func init() {
+	Objs = append(Objs, &MaintenanceWindowObject{})
+}
+
+// MaintenanceWindowObject corresponds to a row in maintenance_windows table.
+type MaintenanceWindowObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=maintenance_windows, primaryKey=((state), window_id)"`
+
+	// State of the window, which is also its partition
+	State string `column:"name=state"`
+	// ID of the window
+	WindowID string `column:"name=window_id"`
+	// Serialized maintenance window
+	Window []byte `column:"name=window"`
+	// Last time the window was written
+	UpdateTime time.Time `column:"name=update_time"`
+}
+
+// MaintenanceWindowOps provides methods for manipulating
+// maintenance_windows table.
+type MaintenanceWindowOps interface {
+	// Create inserts a row in the table.
+	Create(ctx context.Context, window *hpb.MaintenanceWindow) error
+
+	// Update overwrites a row in the table. A window whose state changed
+	// from prevState is moved to the partition of its new state.
+	Update(
+		ctx context.Context,
+		window *hpb.MaintenanceWindow,
+		prevState hpb.MaintenanceWindowState,
+	) error
+
+	// GetByState retrieves the rows of the windows in the given state.
+	GetByState(
+		ctx context.Context,
+		state hpb.MaintenanceWindowState,
+	) ([]*hpb.MaintenanceWindow, error)
+
+	// GetAll retrieves all the rows from the table.
+	GetAll(ctx context.Context) ([]*hpb.MaintenanceWindow, error)
+
+	// Delete removes a row from the table.
+	Delete(ctx context.Context, window *hpb.MaintenanceWindow) error
+
+	// PurgeCompleted removes the rows of the completed windows which
+	// were last written before the given time, and returns the number
+	// of rows removed.
+	PurgeCompleted(ctx context.Context, before time.Time) (int, error)
+}
+
+// ensure that default implementation (maintenanceWindowOps) satisfies
+// the interface
+var _ MaintenanceWindowOps = (*maintenanceWindowOps)(nil)
+
+// maintenanceWindowOps implements MaintenanceWindowOps using a
+// particular Store
+type maintenanceWindowOps struct {
+	store *Store
+}
+
+// NewMaintenanceWindowOps constructs a MaintenanceWindowOps object for
+// provided Store.
+func NewMaintenanceWindowOps(s *Store) MaintenanceWindowOps {
+	return &maintenanceWindowOps{store: s}
+}
+
+// newMaintenanceWindowObject creates a MaintenanceWindowObject from
+// a maintenance window
+func newMaintenanceWindowObject(
+	window *hpb.MaintenanceWindow,
+) (*MaintenanceWindowObject, error) {
+	buffer, err := proto.Marshal(window)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to marshal maintenance window")
+	}
+	return &MaintenanceWindowObject{
+		State:      window.GetState().String(),
+		WindowID:   window.GetId(),
+		Window:     buffer,
+		UpdateTime: time.Now().UTC(),
+	}, nil
+}
+
+func (m *MaintenanceWindowObject) toWindow() (*hpb.MaintenanceWindow, error) {
+	window := &hpb.MaintenanceWindow{}
+	err := proto.Unmarshal(m.Window, window)
+	return window, err
+}
+
+// Create creates a MaintenanceWindowObject in db
+func (d *maintenanceWindowOps) Create(
+	ctx context.Context,
+	window *hpb.MaintenanceWindow,
+) error {
+	obj, err := newMaintenanceWindowObject(window)
+	if err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowCreateFail.Inc(1)
+		return err
+	}
+
+	if err = d.store.oClient.CreateIfNotExists(ctx, obj); err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowCreateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmHostMetrics.MaintenanceWindowCreate.Inc(1)
+	return nil
+}
+
+// Update updates a MaintenanceWindowObject in db
+func (d *maintenanceWindowOps) Update(
+	ctx context.Context,
+	window *hpb.MaintenanceWindow,
+	prevState hpb.MaintenanceWindowState,
+) error {
+	obj, err := newMaintenanceWindowObject(window)
+	if err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowUpdateFail.Inc(1)
+		return err
+	}
+
+	if window.GetState() == prevState {
+		err = d.store.oClient.Update(ctx, obj, "Window", "UpdateTime")
+	} else {
+		// Write the window to the partition of its new state before
+		// removing it from the previous one, so that a failed move is
+		// retried instead of losing the window.
+		err = d.store.oClient.Create(ctx, obj)
+		if err == nil {
+			err = d.store.oClient.Delete(ctx, &MaintenanceWindowObject{
+				State:    prevState.String(),
+				WindowID: window.GetId(),
+			})
+		}
+	}
+	if err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowUpdateFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmHostMetrics.MaintenanceWindowUpdate.Inc(1)
+	return nil
+}
+
+// GetByState gets the maintenance windows in the given state from db
+func (d *maintenanceWindowOps) GetByState(
+	ctx context.Context,
+	state hpb.MaintenanceWindowState,
+) ([]*hpb.MaintenanceWindow, error) {
+	objs, err := d.getByState(ctx, state)
+	if err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	windows, err := toWindows(objs)
+	if err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	d.store.metrics.OrmHostMetrics.MaintenanceWindowGetAll.Inc(1)
+	return windows, nil
+}
+
+// GetAll gets all the maintenance windows from db
+func (d *maintenanceWindowOps) GetAll(
+	ctx context.Context,
+) ([]*hpb.MaintenanceWindow, error) {
+	var objs []*MaintenanceWindowObject
+	for _, state := range _maintenanceWindowStates {
+		stateObjs, err := d.getByState(ctx, state)
+		if err != nil {
+			d.store.metrics.OrmHostMetrics.MaintenanceWindowGetAllFail.Inc(1)
+			return nil, err
+		}
+		objs = append(objs, stateObjs...)
+	}
+
+	windows, err := toWindows(objs)
+	if err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowGetAllFail.Inc(1)
+		return nil, err
+	}
+
+	d.store.metrics.OrmHostMetrics.MaintenanceWindowGetAll.Inc(1)
+	return windows, nil
+}
+
+// Delete deletes a MaintenanceWindowObject from db
+func (d *maintenanceWindowOps) Delete(
+	ctx context.Context,
+	window *hpb.MaintenanceWindow,
+) error {
+	obj := &MaintenanceWindowObject{
+		State:    window.GetState().String(),
+		WindowID: window.GetId(),
+	}
+
+	if err := d.store.oClient.Delete(ctx, obj); err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowDeleteFail.Inc(1)
+		return err
+	}
+
+	d.store.metrics.OrmHostMetrics.MaintenanceWindowDelete.Inc(1)
+	return nil
+}
+
+// PurgeCompleted deletes the completed MaintenanceWindowObjects which
+// were last written before the given time from db
+func (d *maintenanceWindowOps) PurgeCompleted(
+	ctx context.Context,
+	before time.Time,
+) (int, error) {
+	objs, err := d.getByState(
+		ctx,
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_COMPLETED)
+	if err != nil {
+		d.store.metrics.OrmHostMetrics.MaintenanceWindowPurgeFail.Inc(1)
+		return 0, err
+	}
+
+	purged := 0
+	for _, obj := range objs {
+		if !obj.UpdateTime.Before(before) {
+			continue
+		}
+		if err := d.store.oClient.Delete(ctx, obj); err != nil {
+			d.store.metrics.OrmHostMetrics.MaintenanceWindowPurgeFail.Inc(1)
+			return purged, err
+		}
+		purged++
+	}
+
+	d.store.metrics.OrmHostMetrics.MaintenanceWindowPurge.Inc(int64(purged))
+	return purged, nil
+}
+
+// getByState gets the MaintenanceWindowObjects in the partition of
+// the given state from db
+func (d *maintenanceWindowOps) getByState(
+	ctx context.Context,
+	state hpb.MaintenanceWindowState,
+) ([]*MaintenanceWindowObject, error) {
+	objs, err := d.store.oClient.GetAll(ctx, &MaintenanceWindowObject{
+		State: state.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*MaintenanceWindowObject
+	for _, obj := range objs {
+		result = append(result, obj.(*MaintenanceWindowObject))
+	}
+	return result, nil
+}
+
+// toWindows unmarshals the maintenance windows of MaintenanceWindowObjects
+func toWindows(
+	objs []*MaintenanceWindowObject,
+) ([]*hpb.MaintenanceWindow, error) {
+	var windows []*hpb.MaintenanceWindow
+	for _, obj := range objs {
+		window, err := obj.toWindow()
+		if err != nil {
+			return nil, errors.Wrap(err,
+				"Failed to unmarshal maintenance window")
+		}
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
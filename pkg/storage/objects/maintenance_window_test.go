@@ -0,0 +1,169 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package objects
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	hpb "github.com/uber/peloton/.gen/peloton/api/v0/host"
+	ormmocks "github.com/uber/peloton/pkg/storage/orm/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+)
+
+type MaintenanceWindowObjectTestSuite struct {
+	suite.Suite
+	window *hpb.MaintenanceWindow
+}
+
+func (s *MaintenanceWindowObjectTestSuite) SetupTest() {
+	s.window = &hpb.MaintenanceWindow{
+		Id:        uuid.New(),
+		Hostnames: []string{"host1", "host2"},
+		StartTime: "2019-01-01T10:00:00Z",
+		Deadline:  "2019-01-01T12:00:00Z",
+		State:     hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED,
+	}
+}
+
+func TestMaintenanceWindowObjectSuite(t *testing.T) {
+	suite.Run(t, new(MaintenanceWindowObjectTestSuite))
+}
+
+// TestCreateUpdateGetAllDelete tests the lifecycle of a maintenance window
+// in the in-memory store
+func (s *MaintenanceWindowObjectTestSuite) TestCreateUpdateGetAllDelete() {
+	store, err := NewMemoryStore(tally.NoopScope)
+	s.NoError(err)
+	ops := NewMaintenanceWindowOps(store)
+	ctx := context.Background()
+
+	s.NoError(ops.Create(ctx, s.window))
+
+	// window IDs are unique
+	s.Error(ops.Create(ctx, s.window))
+
+	windows, err := ops.GetAll(ctx)
+	s.NoError(err)
+	s.Equal([]*hpb.MaintenanceWindow{s.window}, windows)
+
+	windows, err = ops.GetByState(ctx,
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED)
+	s.NoError(err)
+	s.Equal([]*hpb.MaintenanceWindow{s.window}, windows)
+
+	// the window moves to the partition of its new state
+	s.window.State = hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS
+	s.window.OverdueHosts = []string{"host2"}
+	s.NoError(ops.Update(ctx, s.window,
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED))
+
+	windows, err = ops.GetByState(ctx,
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED)
+	s.NoError(err)
+	s.Empty(windows)
+
+	windows, err = ops.GetByState(ctx,
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS)
+	s.NoError(err)
+	s.Equal([]*hpb.MaintenanceWindow{s.window}, windows)
+
+	// the window is updated in place within a state
+	s.window.OverdueHosts = nil
+	s.NoError(ops.Update(ctx, s.window,
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS))
+
+	windows, err = ops.GetAll(ctx)
+	s.NoError(err)
+	s.Equal([]*hpb.MaintenanceWindow{s.window}, windows)
+
+	s.NoError(ops.Delete(ctx, s.window))
+
+	windows, err = ops.GetAll(ctx)
+	s.NoError(err)
+	s.Empty(windows)
+}
+
+// TestPurgeCompleted tests that only the completed maintenance windows
+// written before the given time are purged
+func (s *MaintenanceWindowObjectTestSuite) TestPurgeCompleted() {
+	store, err := NewMemoryStore(tally.NoopScope)
+	s.NoError(err)
+	ops := NewMaintenanceWindowOps(store)
+	ctx := context.Background()
+
+	completed := &hpb.MaintenanceWindow{
+		Id:    uuid.New(),
+		State: hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_COMPLETED,
+	}
+	s.NoError(ops.Create(ctx, s.window))
+	s.NoError(ops.Create(ctx, completed))
+
+	// the completed window was written after the given time
+	purged, err := ops.PurgeCompleted(ctx, time.Now().Add(-time.Hour))
+	s.NoError(err)
+	s.Equal(0, purged)
+
+	purged, err = ops.PurgeCompleted(ctx, time.Now().Add(time.Hour))
+	s.NoError(err)
+	s.Equal(1, purged)
+
+	windows, err := ops.GetAll(ctx)
+	s.NoError(err)
+	s.Equal([]*hpb.MaintenanceWindow{s.window}, windows)
+}
+
+// TestMaintenanceWindowOpsFail tests failure cases due to ORM Client errors
+func (s *MaintenanceWindowObjectTestSuite) TestMaintenanceWindowOpsFail() {
+	ctrl := gomock.NewController(s.T())
+	defer ctrl.Finish()
+
+	mockClient := ormmocks.NewMockClient(ctrl)
+	mockStore := &Store{oClient: mockClient, metrics: testStore.metrics}
+	ops := NewMaintenanceWindowOps(mockStore)
+	ctx := context.Background()
+	scheduled := hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_SCHEDULED
+
+	mockClient.EXPECT().CreateIfNotExists(gomock.Any(), gomock.Any()).
+		Return(errors.New("createifnotexists failed"))
+	mockClient.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(errors.New("update failed"))
+	mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
+		Return(errors.New("create failed"))
+	mockClient.EXPECT().GetAll(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("getall failed")).
+		Times(3)
+	mockClient.EXPECT().Delete(gomock.Any(), gomock.Any()).
+		Return(errors.New("delete failed"))
+
+	s.EqualError(ops.Create(ctx, s.window), "createifnotexists failed")
+	s.EqualError(ops.Update(ctx, s.window, scheduled), "update failed")
+	s.EqualError(ops.Update(ctx, s.window,
+		hpb.MaintenanceWindowState_MAINTENANCE_WINDOW_STATE_IN_PROGRESS),
+		"create failed")
+	_, err := ops.GetByState(ctx, scheduled)
+	s.EqualError(err, "getall failed")
+	_, err = ops.GetAll(ctx)
+	s.EqualError(err, "getall failed")
+	_, err = ops.PurgeCompleted(ctx, time.Now())
+	s.EqualError(err, "getall failed")
+	s.EqualError(ops.Delete(ctx, s.window), "delete failed")
+}
@@ -16,10 +16,13 @@ package objects
 
 import (
 	"context"
+	"encoding/base64"
 	"time"
 
+	"github.com/gocql/gocql"
 	"github.com/pkg/errors"
 	"github.com/uber/peloton/.gen/peloton/api/v0/peloton"
+	"github.com/uber/peloton/pkg/common/keyring"
 	"github.com/uber/peloton/pkg/storage/objects/base"
 )
 
@@ -33,7 +36,7 @@ const (
 
 // Init to add the secret object instance to the global list of storage objects
 func init() {
-	Objs = append(Objs, &SecretInfoObject{})
+	Objs = append(Objs, &SecretInfoObject{}, &SecretKeyVersionObject{})
 }
 
 // SecretInfoObject corresponds to a peloton secret. All fields should be exported.
@@ -49,7 +52,8 @@ type SecretInfoObject struct {
 	JobID string `column:"name=job_id"`
 	// Container mount path of this secret
 	Path string `column:"name=path"`
-	// Secret Data (base64 encoded string)
+	// Secret Data (base64 encoded string). It is encrypted with the data key
+	// of the secret, unless KeyVersion is 0.
 	Data string `column:"name=data"`
 	// Creation time of the secret
 	CreationTime time.Time `column:"name=creation_time"`
@@ -57,6 +61,23 @@ type SecretInfoObject struct {
 	Version int64 `column:"name=version"`
 	// This flag indicates that the secret is valid or invalid
 	Valid bool `column:"name=valid"`
+	// Version of the master key which wraps the data key of the secret,
+	// 0 for secrets which were stored unencrypted
+	KeyVersion int64 `column:"name=key_version"`
+	// Data key of the secret wrapped by the master key (base64 encoded)
+	DataKey string `column:"name=data_key"`
+}
+
+// SecretKeyVersionObject corresponds to a row in secret_info_by_key_version
+// table, which indexes the encrypted secrets by the version of the master
+// key which wraps their data key.
+type SecretKeyVersionObject struct {
+	// DB specific annotations
+	base.Object `cassandra:"name=secret_info_by_key_version, primaryKey=((key_version), secret_id)"`
+	// Version of the master key
+	KeyVersion int64 `column:"name=key_version"`
+	// SecretID of the secret
+	SecretID string `column:"name=secret_id"`
 }
 
 // SecretInfoOps provides methods for manipulating secret table.
@@ -86,16 +107,37 @@ type SecretInfoOps interface {
 		ctx context.Context,
 		secretID string,
 	) error
+
+	// GetSecretIDsByKeyVersion returns the IDs of the secrets whose data
+	// key is wrapped by the master key of the given version.
+	GetSecretIDsByKeyVersion(
+		ctx context.Context,
+		keyVersion uint32,
+	) ([]string, error)
+
+	// RotateSecret wraps the data key of the secret with the current master
+	// key, and encrypts the secret if it was stored unencrypted. It returns
+	// false if the secret is already wrapped by the current master key, and
+	// an Aborted error if the secret was updated while it was rotated.
+	RotateSecret(
+		ctx context.Context,
+		secretID string,
+	) (bool, error)
 }
 
 // secretInfoOps implements SecretInfoOps interface using a particular Store.
 type secretInfoOps struct {
 	store *Store
+	// keyring to encrypt the secrets with, secrets are stored
+	// unencrypted if it is nil
+	keyring *keyring.Keyring
 }
 
-// NewSecretInfoOps constructs a SecretInfoOps object for provided Store.
-func NewSecretInfoOps(s *Store) SecretInfoOps {
-	return &secretInfoOps{store: s}
+// NewSecretInfoOps constructs a SecretInfoOps object for provided Store
+// and keyring. The secret data is encrypted with the keyring before
+// being written, and is never decrypted by SecretInfoOps.
+func NewSecretInfoOps(s *Store, k *keyring.Keyring) SecretInfoOps {
+	return &secretInfoOps{store: s, keyring: k}
 }
 
 // ensure that default implementation (secretInfoOps) satisfies the interface
@@ -119,6 +161,32 @@ func newSecretObject(
 	return secretInfoObj, nil
 }
 
+// ToEnvelope returns the encrypted secret data along with its wrapped
+// data key.
+func (s *SecretInfoObject) ToEnvelope() (*keyring.Envelope, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(s.Data)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode secret data")
+	}
+	dataKey, err := base64.StdEncoding.DecodeString(s.DataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode data key")
+	}
+	return &keyring.Envelope{
+		KeyVersion: uint32(s.KeyVersion),
+		DataKey:    dataKey,
+		Ciphertext: ciphertext,
+	}, nil
+}
+
+// setEnvelope sets the secret data to the encrypted secret data of the
+// envelope.
+func (s *SecretInfoObject) setEnvelope(e *keyring.Envelope) {
+	s.KeyVersion = int64(e.KeyVersion)
+	s.DataKey = base64.StdEncoding.EncodeToString(e.DataKey)
+	s.Data = base64.StdEncoding.EncodeToString(e.Ciphertext)
+}
+
 // ToProto returns the unmarshaled *peloton.Secret
 func (s *SecretInfoObject) ToProto() *peloton.Secret {
 	return &peloton.Secret{
@@ -142,10 +210,18 @@ func (s *secretInfoOps) CreateSecret(
 		s.store.metrics.OrmJobMetrics.SecretInfoCreateFail.Inc(1)
 		return errors.Wrap(err, "Failed to construct SecretInfoObject")
 	}
+	if err = s.encrypt(obj); err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoCreateFail.Inc(1)
+		return err
+	}
 	if err = s.store.oClient.Create(ctx, obj); err != nil {
 		s.store.metrics.OrmJobMetrics.SecretInfoCreateFail.Inc(1)
 		return err
 	}
+	if err = s.createKeyVersionIndex(ctx, obj); err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoCreateFail.Inc(1)
+		return err
+	}
 	s.store.metrics.OrmJobMetrics.SecretInfoCreate.Inc(1)
 	return nil
 }
@@ -177,8 +253,11 @@ func (s *secretInfoOps) UpdateSecretData(
 		Valid:    true,
 		Data:     secretString,
 	}
-	fieldToUpdate := []string{"Data"}
-	if err := s.store.oClient.Update(ctx, secretInfoObject, fieldToUpdate...); err != nil {
+	if err := s.encrypt(secretInfoObject); err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoUpdateFail.Inc(1)
+		return err
+	}
+	if err := s.updateEnvelope(ctx, secretInfoObject); err != nil {
 		s.store.metrics.OrmJobMetrics.SecretInfoUpdateFail.Inc(1)
 		return err
 	}
@@ -195,10 +274,249 @@ func (s *secretInfoOps) DeleteSecret(
 		SecretID: secretID,
 		Valid:    true,
 	}
+	keyVersion, err := s.getKeyVersion(ctx, secretID)
+	if err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoDeleteFail.Inc(1)
+		return err
+	}
 	if err := s.store.oClient.Delete(ctx, secretInfoObject); err != nil {
 		s.store.metrics.OrmJobMetrics.SecretInfoDeleteFail.Inc(1)
 		return err
 	}
+	if err := s.deleteKeyVersionIndex(ctx, keyVersion, secretID); err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoDeleteFail.Inc(1)
+		return err
+	}
 	s.store.metrics.OrmJobMetrics.SecretInfoDelete.Inc(1)
 	return nil
 }
+
+// GetSecretIDsByKeyVersion gets the IDs of the secrets wrapped by the
+// master key of the given version from db
+func (s *secretInfoOps) GetSecretIDsByKeyVersion(
+	ctx context.Context,
+	keyVersion uint32,
+) ([]string, error) {
+	objs, err := s.store.oClient.GetAll(ctx, &SecretKeyVersionObject{
+		KeyVersion: int64(keyVersion),
+	})
+	if err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoGetFail.Inc(1)
+		return nil, err
+	}
+	s.store.metrics.OrmJobMetrics.SecretInfoGet.Inc(1)
+
+	var secretIDs []string
+	for _, obj := range objs {
+		secretIDs = append(secretIDs, obj.(*SecretKeyVersionObject).SecretID)
+	}
+	return secretIDs, nil
+}
+
+// RotateSecret wraps the data key of a secret in db with the current
+// master key
+func (s *secretInfoOps) RotateSecret(
+	ctx context.Context,
+	secretID string,
+) (bool, error) {
+	if s.keyring == nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoRotateFail.Inc(1)
+		return false, errors.New("no keyring to rotate secrets with")
+	}
+
+	secretInfoObject, err := s.GetSecret(ctx, secretID)
+	if err == gocql.ErrNotFound {
+		// remove the index entries left by a failed delete
+		if err := s.cleanKeyVersionIndex(ctx, secretID, 0); err != nil {
+			s.store.metrics.OrmJobMetrics.SecretInfoRotateFail.Inc(1)
+			return false, err
+		}
+		s.store.metrics.OrmJobMetrics.SecretInfoRotateFail.Inc(1)
+		return false, gocql.ErrNotFound
+	}
+	if err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoRotateFail.Inc(1)
+		return false, err
+	}
+	return s.rotate(ctx, secretInfoObject)
+}
+
+// rotate wraps the data key of a secret object read from db with the
+// current master key. The secret is written only if its data has not been
+// updated since it was read, otherwise an Aborted error is returned.
+// The key version index of the secret is repaired along the way, so that
+// rotating again completes a rotation which failed after the secret was
+// written.
+func (s *secretInfoOps) rotate(
+	ctx context.Context,
+	secretInfoObject *SecretInfoObject,
+) (bool, error) {
+	if secretInfoObject.KeyVersion == int64(s.keyring.CurrentVersion()) {
+		if err := s.indexKeyVersion(ctx, secretInfoObject); err != nil {
+			s.store.metrics.OrmJobMetrics.SecretInfoRotateFail.Inc(1)
+			return false, err
+		}
+		return false, nil
+	}
+
+	prev := *secretInfoObject
+	var err error
+	if secretInfoObject.KeyVersion == 0 {
+		err = s.encrypt(secretInfoObject)
+	} else {
+		err = s.rewrap(secretInfoObject)
+	}
+	if err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoRotateFail.Inc(1)
+		return false, err
+	}
+
+	if err := s.store.oClient.UpdateIf(
+		ctx,
+		secretInfoObject,
+		&prev,
+		"Data", "KeyVersion", "DataKey",
+	); err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoRotateFail.Inc(1)
+		return false, err
+	}
+	if err := s.indexKeyVersion(ctx, secretInfoObject); err != nil {
+		s.store.metrics.OrmJobMetrics.SecretInfoRotateFail.Inc(1)
+		return false, err
+	}
+	s.store.metrics.OrmJobMetrics.SecretInfoRotate.Inc(1)
+	return true, nil
+}
+
+// encrypt encrypts the unencrypted data of the secret object with the
+// keyring, if any.
+func (s *secretInfoOps) encrypt(obj *SecretInfoObject) error {
+	if s.keyring == nil {
+		return nil
+	}
+	e, err := s.keyring.Encrypt(obj.SecretID, []byte(obj.Data))
+	if err != nil {
+		return errors.Wrap(err, "failed to encrypt secret")
+	}
+	obj.setEnvelope(e)
+	return nil
+}
+
+// rewrap wraps the data key of the secret object with the current
+// master key.
+func (s *secretInfoOps) rewrap(obj *SecretInfoObject) error {
+	e, err := obj.ToEnvelope()
+	if err != nil {
+		return err
+	}
+	if e, err = s.keyring.Rewrap(obj.SecretID, e); err != nil {
+		return errors.Wrap(err, "failed to rewrap data key")
+	}
+	obj.setEnvelope(e)
+	return nil
+}
+
+// updateEnvelope writes the encrypted data and the data key of the secret
+// object, and then moves the secret to the index of its new key version.
+// A failure after the secret is written leaves it in the index of its
+// previous key version, where the next rotation finds it.
+func (s *secretInfoOps) updateEnvelope(
+	ctx context.Context,
+	obj *SecretInfoObject,
+) error {
+	prevKeyVersion, err := s.getKeyVersion(ctx, obj.SecretID)
+	if err != nil {
+		return err
+	}
+	if err := s.store.oClient.Update(
+		ctx, obj, "Data", "KeyVersion", "DataKey"); err != nil {
+		return err
+	}
+	if err := s.createKeyVersionIndex(ctx, obj); err != nil {
+		return err
+	}
+	if prevKeyVersion == obj.KeyVersion {
+		return nil
+	}
+	return s.deleteKeyVersionIndex(ctx, prevKeyVersion, obj.SecretID)
+}
+
+// indexKeyVersion adds an encrypted secret object to the index of its key
+// version, and removes it from the index of the other key versions of the
+// keyring. It can be repeated safely.
+func (s *secretInfoOps) indexKeyVersion(
+	ctx context.Context,
+	obj *SecretInfoObject,
+) error {
+	if err := s.createKeyVersionIndex(ctx, obj); err != nil {
+		return err
+	}
+	return s.cleanKeyVersionIndex(ctx, obj.SecretID, obj.KeyVersion)
+}
+
+// createKeyVersionIndex adds an encrypted secret object to the index of
+// its key version. Adding a secret which is already indexed is a no-op.
+func (s *secretInfoOps) createKeyVersionIndex(
+	ctx context.Context,
+	obj *SecretInfoObject,
+) error {
+	if obj.KeyVersion == 0 {
+		return nil
+	}
+	return s.store.oClient.Create(ctx, &SecretKeyVersionObject{
+		KeyVersion: obj.KeyVersion,
+		SecretID:   obj.SecretID,
+	})
+}
+
+// cleanKeyVersionIndex removes a secret from the index of every key
+// version of the keyring except keepVersion.
+func (s *secretInfoOps) cleanKeyVersionIndex(
+	ctx context.Context,
+	secretID string,
+	keepVersion int64,
+) error {
+	for _, version := range s.keyring.Versions() {
+		if int64(version) == keepVersion {
+			continue
+		}
+		if err := s.deleteKeyVersionIndex(
+			ctx, int64(version), secretID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteKeyVersionIndex removes a secret from the index of a key version.
+// Removing a secret which is not indexed is a no-op.
+func (s *secretInfoOps) deleteKeyVersionIndex(
+	ctx context.Context,
+	keyVersion int64,
+	secretID string,
+) error {
+	if keyVersion == 0 {
+		return nil
+	}
+	return s.store.oClient.Delete(ctx, &SecretKeyVersionObject{
+		KeyVersion: keyVersion,
+		SecretID:   secretID,
+	})
+}
+
+// getKeyVersion returns the key version of a secret in db, or 0 if the
+// secret does not exist. Every ORM connector returns gocql.ErrNotFound
+// for a row which does not exist.
+func (s *secretInfoOps) getKeyVersion(
+	ctx context.Context,
+	secretID string,
+) (int64, error) {
+	obj := &SecretInfoObject{SecretID: secretID, Valid: true}
+	if err := s.store.oClient.Get(ctx, obj); err != nil {
+		if err == gocql.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return obj.KeyVersion, nil
+}
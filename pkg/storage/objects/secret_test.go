@@ -15,14 +15,19 @@
 package objects
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"testing"
 	"time"
 
+	"github.com/uber/peloton/pkg/common/keyring"
+
 	"github.com/gocql/gocql"
 	"github.com/pborman/uuid"
 	"github.com/stretchr/testify/suite"
+	"github.com/uber-go/tally"
+	"go.uber.org/yarpc/yarpcerrors"
 )
 
 type SecretInfoObjectTestSuite struct {
@@ -38,7 +43,7 @@ func TestSecretObjectSuite(t *testing.T) {
 
 // TestSecretInfoOps tests SecretObject CRUD operations.
 func (suite *SecretInfoObjectTestSuite) TestSecretInfoOps() {
-	db := NewSecretInfoOps(testStore)
+	db := NewSecretInfoOps(testStore, nil)
 	ctx := context.Background()
 
 	jobID := uuid.New()
@@ -84,3 +89,227 @@ func (suite *SecretInfoObjectTestSuite) TestSecretInfoOps() {
 	suite.Error(err)
 	suite.Equal(err, gocql.ErrNotFound)
 }
+
+// newTestKeyring creates a keyring with master keys of versions 1 to
+// currentVersion.
+func (suite *SecretInfoObjectTestSuite) newTestKeyring(
+	currentVersion uint32) *keyring.Keyring {
+	keys := make(map[uint32][]byte)
+	for v := uint32(1); v <= currentVersion; v++ {
+		keys[v] = bytes.Repeat([]byte{byte(v)}, 32)
+	}
+	k, err := keyring.New(currentVersion, keys)
+	suite.NoError(err)
+	return k
+}
+
+// TestSecretInfoOpsEncrypted tests that secrets are encrypted at rest
+// when a keyring is provided.
+func (suite *SecretInfoObjectTestSuite) TestSecretInfoOpsEncrypted() {
+	k := suite.newTestKeyring(1)
+	db := NewSecretInfoOps(testStore, k)
+	ctx := context.Background()
+
+	secretID := uuid.New()
+	testSecretByteStr := base64.StdEncoding.
+		EncodeToString([]byte("some secrets"))
+
+	err := db.CreateSecret(
+		ctx, uuid.New(), time.Now().UTC(), secretID, testSecretByteStr, "path")
+	suite.NoError(err)
+
+	secretInfoObj, err := db.GetSecret(ctx, secretID)
+	suite.NoError(err)
+	suite.Equal(int64(1), secretInfoObj.KeyVersion)
+	suite.NotEqual(testSecretByteStr, secretInfoObj.Data)
+	envelope, err := secretInfoObj.ToEnvelope()
+	suite.NoError(err)
+	data, err := k.Decrypt(secretID, envelope)
+	suite.NoError(err)
+	suite.Equal(testSecretByteStr, string(data))
+
+	secretIDs, err := db.GetSecretIDsByKeyVersion(ctx, 1)
+	suite.NoError(err)
+	suite.Contains(secretIDs, secretID)
+
+	// Update encrypts the new secret data.
+	testUpdatedSecretByteStr := base64.StdEncoding.
+		EncodeToString([]byte("new secret"))
+	err = db.UpdateSecretData(ctx, secretID, testUpdatedSecretByteStr)
+	suite.NoError(err)
+	secretInfoObj, err = db.GetSecret(ctx, secretID)
+	suite.NoError(err)
+	envelope, err = secretInfoObj.ToEnvelope()
+	suite.NoError(err)
+	data, err = k.Decrypt(secretID, envelope)
+	suite.NoError(err)
+	suite.Equal(testUpdatedSecretByteStr, string(data))
+
+	// Delete removes the secret from the key version index.
+	err = db.DeleteSecret(ctx, secretID)
+	suite.NoError(err)
+	secretIDs, err = db.GetSecretIDsByKeyVersion(ctx, 1)
+	suite.NoError(err)
+	suite.NotContains(secretIDs, secretID)
+}
+
+// TestRotateSecret tests rotating the master key of secrets.
+func (suite *SecretInfoObjectTestSuite) TestRotateSecret() {
+	ctx := context.Background()
+	testSecretByteStr := base64.StdEncoding.
+		EncodeToString([]byte("some secrets"))
+
+	// a secret stored unencrypted, and a secret wrapped by version 1
+	plainSecretID := uuid.New()
+	err := NewSecretInfoOps(testStore, nil).CreateSecret(
+		ctx, uuid.New(), time.Now().UTC(), plainSecretID, testSecretByteStr, "path")
+	suite.NoError(err)
+	encryptedSecretID := uuid.New()
+	err = NewSecretInfoOps(testStore, suite.newTestKeyring(1)).CreateSecret(
+		ctx, uuid.New(), time.Now().UTC(), encryptedSecretID, testSecretByteStr, "path")
+	suite.NoError(err)
+
+	k := suite.newTestKeyring(2)
+	db := NewSecretInfoOps(testStore, k)
+	for _, secretID := range []string{plainSecretID, encryptedSecretID} {
+		rotated, err := db.RotateSecret(ctx, secretID)
+		suite.NoError(err)
+		suite.True(rotated)
+
+		secretInfoObj, err := db.GetSecret(ctx, secretID)
+		suite.NoError(err)
+		suite.Equal(int64(2), secretInfoObj.KeyVersion)
+		envelope, err := secretInfoObj.ToEnvelope()
+		suite.NoError(err)
+		data, err := k.Decrypt(secretID, envelope)
+		suite.NoError(err)
+		suite.Equal(testSecretByteStr, string(data))
+
+		// the secret is already wrapped by the current master key
+		rotated, err = db.RotateSecret(ctx, secretID)
+		suite.NoError(err)
+		suite.False(rotated)
+	}
+
+	secretIDs, err := db.GetSecretIDsByKeyVersion(ctx, 1)
+	suite.NoError(err)
+	suite.NotContains(secretIDs, encryptedSecretID)
+	secretIDs, err = db.GetSecretIDsByKeyVersion(ctx, 2)
+	suite.NoError(err)
+	suite.Contains(secretIDs, plainSecretID)
+	suite.Contains(secretIDs, encryptedSecretID)
+
+	// secrets cannot be rotated without a keyring
+	_, err = NewSecretInfoOps(testStore, nil).RotateSecret(ctx, plainSecretID)
+	suite.Error(err)
+
+	for _, secretID := range []string{plainSecretID, encryptedSecretID} {
+		suite.NoError(db.DeleteSecret(ctx, secretID))
+	}
+}
+
+// TestRotateSecretConflict tests that a rotation does not overwrite a
+// secret updated after it was read.
+func (suite *SecretInfoObjectTestSuite) TestRotateSecretConflict() {
+	ctx := context.Background()
+	testSecretByteStr := base64.StdEncoding.
+		EncodeToString([]byte("some secrets"))
+	newSecretByteStr := base64.StdEncoding.
+		EncodeToString([]byte("new secrets"))
+
+	secretID := uuid.New()
+	err := NewSecretInfoOps(testStore, suite.newTestKeyring(1)).CreateSecret(
+		ctx, uuid.New(), time.Now().UTC(), secretID, testSecretByteStr, "path")
+	suite.NoError(err)
+
+	k := suite.newTestKeyring(2)
+	db := NewSecretInfoOps(testStore, k).(*secretInfoOps)
+	secretInfoObj, err := db.GetSecret(ctx, secretID)
+	suite.NoError(err)
+
+	// the secret is updated by a job update while it is rotated
+	suite.NoError(db.UpdateSecretData(ctx, secretID, newSecretByteStr))
+
+	_, err = db.rotate(ctx, secretInfoObj)
+	suite.True(yarpcerrors.IsAborted(err))
+
+	// the secret keeps the data of the update
+	secretInfoObj, err = db.GetSecret(ctx, secretID)
+	suite.NoError(err)
+	suite.Equal(int64(2), secretInfoObj.KeyVersion)
+	envelope, err := secretInfoObj.ToEnvelope()
+	suite.NoError(err)
+	data, err := k.Decrypt(secretID, envelope)
+	suite.NoError(err)
+	suite.Equal(newSecretByteStr, string(data))
+
+	secretIDs, err := db.GetSecretIDsByKeyVersion(ctx, 1)
+	suite.NoError(err)
+	suite.NotContains(secretIDs, secretID)
+	secretIDs, err = db.GetSecretIDsByKeyVersion(ctx, 2)
+	suite.NoError(err)
+	suite.Contains(secretIDs, secretID)
+
+	suite.NoError(db.DeleteSecret(ctx, secretID))
+}
+
+// TestRotateSecretNotFound tests that rotating a deleted secret returns
+// gocql.ErrNotFound, which the in-memory connector also returns for a
+// missing row, and removes the secret from the key version index.
+func (suite *SecretInfoObjectTestSuite) TestRotateSecretNotFound() {
+	ctx := context.Background()
+	store, err := NewMemoryStore(tally.NoopScope)
+	suite.NoError(err)
+
+	// a key version index entry left by a failed delete
+	secretID := uuid.New()
+	suite.NoError(store.oClient.Create(ctx, &SecretKeyVersionObject{
+		KeyVersion: 1,
+		SecretID:   secretID,
+	}))
+
+	db := NewSecretInfoOps(store, suite.newTestKeyring(2))
+	_, err = db.RotateSecret(ctx, secretID)
+	suite.Equal(gocql.ErrNotFound, err)
+
+	secretIDs, err := db.GetSecretIDsByKeyVersion(ctx, 1)
+	suite.NoError(err)
+	suite.NotContains(secretIDs, secretID)
+
+	// deleting a secret which does not exist is a no-op
+	suite.NoError(db.DeleteSecret(ctx, secretID))
+}
+
+// TestRotateSecretRepairsIndex tests that rotating a secret already
+// wrapped by the current master key moves it to the index of its key
+// version, when a previous rotation failed after writing the secret.
+func (suite *SecretInfoObjectTestSuite) TestRotateSecretRepairsIndex() {
+	ctx := context.Background()
+	store, err := NewMemoryStore(tally.NoopScope)
+	suite.NoError(err)
+	testSecretByteStr := base64.StdEncoding.
+		EncodeToString([]byte("some secrets"))
+
+	secretID := uuid.New()
+	err = NewSecretInfoOps(store, suite.newTestKeyring(2)).CreateSecret(
+		ctx, uuid.New(), time.Now().UTC(), secretID, testSecretByteStr, "path")
+	suite.NoError(err)
+
+	// the index entry of the previous key version is left behind
+	suite.NoError(store.oClient.Create(ctx, &SecretKeyVersionObject{
+		KeyVersion: 1,
+		SecretID:   secretID,
+	}))
+
+	db := NewSecretInfoOps(store, suite.newTestKeyring(2))
+	rotated, err := db.RotateSecret(ctx, secretID)
+	suite.NoError(err)
+	suite.False(rotated)
+
+	secretIDs, err := db.GetSecretIDsByKeyVersion(ctx, 1)
+	suite.NoError(err)
+	suite.NotContains(secretIDs, secretID)
+	secretIDs, err = db.GetSecretIDsByKeyVersion(ctx, 2)
+	suite.NoError(err)
+	suite.Contains(secretIDs, secretID)
+}
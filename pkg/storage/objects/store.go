@@ -18,6 +18,8 @@ import (
 	pelotonstore "github.com/uber/peloton/pkg/storage"
 	"github.com/uber/peloton/pkg/storage/cassandra"
 	escassandra "github.com/uber/peloton/pkg/storage/connectors/cassandra"
+	"github.com/uber/peloton/pkg/storage/connectors/memory"
+	"github.com/uber/peloton/pkg/storage/connectors/sql"
 	"github.com/uber/peloton/pkg/storage/objects/base"
 	"github.com/uber/peloton/pkg/storage/orm"
 
@@ -55,3 +57,42 @@ func NewCassandraStore(
 		metrics: pelotonstore.NewMetrics(scope),
 	}, nil
 }
+
+// NewMemoryStore creates a new storage client which keeps all objects in
+// memory. It is meant for tests and local development clusters.
+func NewMemoryStore(scope tally.Scope) (*Store, error) {
+	oclient, err := orm.NewClient(memory.NewMemoryConnector(), Objs...)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		oClient: oclient,
+		metrics: pelotonstore.NewMetrics(scope),
+	}, nil
+}
+
+// NewSQLStore creates a new storage client backed by a PostgreSQL or SQLite
+// database. Tables of all storage objects are created if they don't exist.
+func NewSQLStore(config *sql.Config, scope tally.Scope) (*Store, error) {
+	objectIndex, err := orm.BuildObjectIndex(Objs)
+	if err != nil {
+		return nil, err
+	}
+	defs := make([]*base.Definition, 0, len(objectIndex))
+	for _, table := range objectIndex {
+		defs = append(defs, &table.Definition)
+	}
+
+	connector, err := sql.NewSQLConnector(config, scope, defs...)
+	if err != nil {
+		return nil, err
+	}
+	oclient, err := orm.NewClient(connector, Objs...)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{
+		oClient: oclient,
+		metrics: pelotonstore.NewMetrics(scope),
+	}, nil
+}
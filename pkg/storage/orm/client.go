@@ -30,7 +30,8 @@ type Client interface {
 	CreateIfNotExists(ctx context.Context, e base.Object) error
 	// Create creates the storage object in the database
 	Create(ctx context.Context, e base.Object) error
-	// Get gets the storage object from the database
+	// Get gets the storage object from the database. It returns
+	// gocql.ErrNotFound if the object does not exist.
 	Get(ctx context.Context, e base.Object) error
 	// GetAll gets all the storage objects for the partition key from the
 	// database
@@ -44,6 +45,16 @@ type Client interface {
 	// the caller. If not specified, all fields in the object will be updated
 	// to the DB
 	Update(ctx context.Context, e base.Object, fieldsToUpdate ...string) error
+	// UpdateIf updates the storage object in the database only if the
+	// values of the fields to be updated in the database are still those
+	// of prev, the object as it was read by the caller. It returns an
+	// Aborted error if the object has been modified concurrently.
+	UpdateIf(
+		ctx context.Context,
+		e base.Object,
+		prev base.Object,
+		fieldsToUpdate ...string,
+	) error
 	// Delete deletes the storage object from the database
 	Delete(ctx context.Context, e base.Object) error
 }
@@ -198,6 +209,37 @@ func (c *client) Update(
 	return c.connector.Update(ctx, &table.Definition, row, keyRow)
 }
 
+// UpdateIf updates the storage object in the database if the fields to be
+// updated have not been modified since prev was read
+func (c *client) UpdateIf(
+	ctx context.Context,
+	e base.Object,
+	prev base.Object,
+	fieldsToUpdate ...string,
+) error {
+	// lookup if a table exists for this object, return error if not found
+	table, err := c.getTable(e)
+	if err != nil {
+		return err
+	}
+	if reflect.TypeOf(prev) != reflect.TypeOf(e) {
+		return yarpcerrors.InvalidArgumentErrorf(
+			"previous object is a %T, not a %T", prev, e)
+	}
+
+	// translate the storage object into a row (list of column), and the
+	// previous object into the expected values of the same columns
+	row := table.GetRowFromObject(e, fieldsToUpdate...)
+	conditions := table.GetRowFromObject(prev, fieldsToUpdate...)
+
+	// build a primary key row from storage object
+	keyRow := table.GetKeyRowFromObject(e)
+
+	// Tell the connector to update a row in the DB using this row
+	return c.connector.UpdateIf(
+		ctx, &table.Definition, row, conditions, keyRow)
+}
+
 // Delete deletes the storage object in the database
 func (c *client) Delete(ctx context.Context, e base.Object) error {
 	// lookup if a table exists for this object, return error if not found
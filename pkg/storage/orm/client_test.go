@@ -315,6 +315,41 @@ func (suite *ORMTestSuite) TestClientUpdate() {
 	suite.Error(err)
 }
 
+// TestClientUpdateIf tests client conditional update operation on valid
+// and invalid entities
+func (suite *ORMTestSuite) TestClientUpdateIf() {
+	defer suite.ctrl.Finish()
+	conn := ormmocks.NewMockConnector(suite.ctrl)
+
+	conn.EXPECT().UpdateIf(
+		suite.ctx, gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, _ *base.Definition,
+			row []base.Column, conditions []base.Column,
+			keyRow []base.Column) {
+			suite.Equal("data", row[0].Name)
+			suite.Equal("newdata", row[0].Value)
+			suite.Equal("data", conditions[0].Name)
+			suite.Equal("testdata", conditions[0].Value)
+			suite.Equal("id", keyRow[0].Name)
+			suite.Equal(uint64(1), keyRow[0].Value)
+		}).Return(nil)
+
+	client, err := orm.NewClient(conn, &ValidObject{})
+	suite.NoError(err)
+
+	// Update Data field in testValidObject if it is still "testdata"
+	newObject := *testValidObject
+	newObject.Data = "newdata"
+	err = client.UpdateIf(suite.ctx, &newObject, testValidObject, "Data")
+	suite.NoError(err)
+
+	err = client.UpdateIf(suite.ctx, &newObject, &InvalidObject1{}, "Data")
+	suite.Error(err)
+
+	err = client.UpdateIf(suite.ctx, &InvalidObject1{}, &InvalidObject1{})
+	suite.Error(err)
+}
+
 // TestClientDelete tests client delete operation on valid and invalid entities
 func (suite *ORMTestSuite) TestClientDelete() {
 	defer suite.ctrl.Finish()
@@ -33,7 +33,8 @@ type Connector interface {
 	// Create creates a row in the DB for the base object
 	Create(ctx context.Context, e *base.Definition, values []base.Column) error
 
-	// Get fetches a row by primary key of base object
+	// Get fetches a row by primary key of base object. Every connector
+	// returns gocql.ErrNotFound if the row does not exist.
 	Get(
 		ctx context.Context,
 		e *base.Definition,
@@ -61,6 +62,17 @@ type Connector interface {
 		keys []base.Column,
 	) error
 
+	// UpdateIf updates a row in the DB for the base object only if the
+	// current values of its columns match the conditions. It returns an
+	// Aborted error if the conditions are not met.
+	UpdateIf(
+		ctx context.Context,
+		e *base.Definition,
+		values []base.Column,
+		conditions []base.Column,
+		keys []base.Column,
+	) error
+
 	// Delete deletes a row from the DB for the base object
 	Delete(ctx context.Context, e *base.Definition, keys []base.Column) error
 }
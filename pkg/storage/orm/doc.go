@@ -36,6 +36,8 @@ peloton. There are three major components of this layer:
 
   * Connector - is the interface mapping directly to the API exposed by the
              client and should be implemented by different storage connectors.
-             Peloton currently has a cassandra implementation of the connector
-             and we can extend this to other DBs.
+             Peloton currently has a cassandra implementation of the connector,
+             a SQL implementation for PostgreSQL and SQLite, and an in-memory
+             implementation for tests and local development, and we can extend
+             this to other DBs.
 */
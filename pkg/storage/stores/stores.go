@@ -20,6 +20,10 @@ import (
 	"github.com/uber/peloton/pkg/storage"
 	"github.com/uber/peloton/pkg/storage/cassandra"
 	storage_config "github.com/uber/peloton/pkg/storage/config"
+	// register the postgres driver of the sql ORM backend, so that
+	// every binary which creates an ORM store can use it
+	_ "github.com/uber/peloton/pkg/storage/connectors/sql/postgres"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
 )
 
 // MustCreateStore creates a generic store that is needed by peloton
@@ -41,3 +45,29 @@ func MustCreateStore(
 	}
 	return store
 }
+
+// MustCreateORMStore creates the store of the ORM objects in the configured
+// backend and exits if the store can't be created
+func MustCreateORMStore(
+	cfg *storage_config.Config, rootScope tally.Scope) *ormobjects.Store {
+	var store *ormobjects.Store
+	var err error
+	switch cfg.ORMBackend {
+	case "", storage_config.ORMBackendCassandra:
+		store, err = ormobjects.NewCassandraStore(&cfg.Cassandra, rootScope)
+	case storage_config.ORMBackendSQL:
+		log.WithField("sql_driver", cfg.SQL.Driver).Info("SQL Config")
+		store, err = ormobjects.NewSQLStore(&cfg.SQL, rootScope)
+	case storage_config.ORMBackendMemory:
+		log.Warn("ORM objects are kept in memory and lost on restart")
+		store, err = ormobjects.NewMemoryStore(rootScope)
+	default:
+		log.Fatalf("Unknown ORM backend %q", cfg.ORMBackend)
+	}
+	if err != nil {
+		log.WithError(err).
+			WithField("orm_backend", cfg.ORMBackend).
+			Fatal("Failed to create ORM store")
+	}
+	return store
+}
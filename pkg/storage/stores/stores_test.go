@@ -0,0 +1,72 @@
+// Copyright (c) 2019 Uber Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stores
+
+import (
+	"context"
+	dbsql "database/sql"
+	"testing"
+	"time"
+
+	storage_config "github.com/uber/peloton/pkg/storage/config"
+	"github.com/uber/peloton/pkg/storage/connectors/sql"
+	// register the sqlite3 driver used by the SQL store test
+	_ "github.com/uber/peloton/pkg/storage/connectors/sql/sqlite"
+	ormobjects "github.com/uber/peloton/pkg/storage/objects"
+
+	"github.com/gocql/gocql"
+	"github.com/pborman/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/uber-go/tally"
+)
+
+// TestPostgresDriverRegistered tests that the postgres driver of the sql
+// ORM backend is registered for every binary which creates an ORM store
+func TestPostgresDriverRegistered(t *testing.T) {
+	assert.Contains(t, dbsql.Drivers(), sql.DriverPostgres)
+}
+
+// TestMustCreateORMStoreSQL tests creating an ORM store in the sql backend
+func TestMustCreateORMStoreSQL(t *testing.T) {
+	store := MustCreateORMStore(&storage_config.Config{
+		ORMBackend: storage_config.ORMBackendSQL,
+		SQL: sql.Config{
+			Driver:       sql.DriverSQLite,
+			DataSource:   ":memory:",
+			MaxOpenConns: 1,
+		},
+	}, tally.NoopScope)
+	assert.NotNil(t, store)
+
+	secretInfoOps := ormobjects.NewSecretInfoOps(store, nil)
+	ctx := context.Background()
+	secretID := uuid.New()
+	assert.NoError(t, secretInfoOps.CreateSecret(
+		ctx, uuid.New(), time.Now().UTC(), secretID, "data", "path"))
+	secretInfoObj, err := secretInfoOps.GetSecret(ctx, secretID)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", secretInfoObj.Data)
+
+	_, err = secretInfoOps.GetSecret(ctx, uuid.New())
+	assert.Equal(t, gocql.ErrNotFound, err)
+}
+
+// TestMustCreateORMStoreMemory tests creating an ORM store in memory
+func TestMustCreateORMStoreMemory(t *testing.T) {
+	store := MustCreateORMStore(&storage_config.Config{
+		ORMBackend: storage_config.ORMBackendMemory,
+	}, tally.NoopScope)
+	assert.NotNil(t, store)
+}